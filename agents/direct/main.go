@@ -1,21 +1,27 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"reflect"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
 	"github.com/go-chi/chi/v5"
 
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
 	"github.com/plexusone/agent-team-stats/pkg/config"
 	"github.com/plexusone/agent-team-stats/pkg/direct"
+	"github.com/plexusone/agent-team-stats/pkg/export"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
 	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/ratelimit"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
 )
 
 // DirectAgent provides HTTP API for direct LLM search
@@ -43,16 +49,66 @@ func NewDirectAgent(cfg *config.Config, logger *slog.Logger) (*DirectAgent, erro
 type DirectSearchInput struct {
 	Body struct {
 		Topic         string `json:"topic" minLength:"1" maxLength:"500" example:"climate change" doc:"Topic to search for statistics"`
-		MinStats      int    `json:"min_stats,omitempty" minimum:"1" maximum:"100" default:"10" example:"10" doc:"Minimum number of statistics to find"`
+		MinStats      int    `json:"min_stats,omitempty" minimum:"1" maximum:"100" example:"10" doc:"Minimum number of statistics to find (defaults to DEFAULT_MIN_VERIFIED_STATS)"`
 		VerifyWithWeb bool   `json:"verify_with_web,omitempty" default:"false" example:"false" doc:"If true, verifies LLM claims with verification agent (requires verification agent running on port 8002)"`
 	}
 }
 
+// DirectSearchQueryInput is DirectSearchInput as query parameters, for
+// browsers, curl one-liners, and spreadsheet imports that can only send a GET
+type DirectSearchQueryInput struct {
+	Topic         string `query:"topic" required:"true" minLength:"1" maxLength:"500" example:"climate change" doc:"Topic to search for statistics"`
+	MinStats      int    `query:"min_stats" minimum:"1" maximum:"100" example:"10" doc:"Minimum number of statistics to find (defaults to DEFAULT_MIN_VERIFIED_STATS)"`
+	VerifyWithWeb bool   `query:"verify_with_web" default:"false" example:"false" doc:"If true, verifies LLM claims with verification agent (requires verification agent running on port 8002)"`
+	Format        string `query:"format" enum:"json,csv" default:"json" doc:"Response format: json, or csv with one row per statistic for spreadsheet imports"`
+}
+
+// DirectSearchQueryOutput is the GET search's output: the response as
+// JSON, or its statistics as CSV
+type DirectSearchQueryOutput struct {
+	ContentType string `header:"Content-Type"`
+	Body        any
+}
+
 // DirectSearchOutput represents the output from direct search
 type DirectSearchOutput struct {
 	Body *models.OrchestrationResponse
 }
 
+// HonestyOutput represents the per-provider honesty scores
+type HonestyOutput struct {
+	Body struct {
+		Providers []models.ProviderHonesty `json:"providers" doc:"Honesty totals per provider and model, most honest first"`
+	}
+}
+
+// search runs a direct search and logs its outcome
+func (da *DirectAgent) search(ctx context.Context, topic string, minStats int, verify bool) (*DirectSearchOutput, error) {
+	// Set defaults
+	if minStats == 0 {
+		minStats = da.cfg.Defaults.MinVerifiedStats
+	}
+
+	da.logger.Info("processing request",
+		"topic", topic,
+		"min_stats", minStats,
+		"verify", verify)
+
+	// Call direct search service
+	resp, err := da.directSvc.SearchStatisticsWithVerification(ctx, topic, minStats, verify)
+	if err != nil {
+		da.logger.Error("search failed", "error", err)
+		return nil, huma.Error500InternalServerError(fmt.Sprintf("Search failed: %v", err))
+	}
+	tenant.Charge(ctx, resp.CostSummary)
+
+	da.logger.Info("search completed",
+		"verified", resp.VerifiedCount,
+		"partial", resp.Partial)
+
+	return &DirectSearchOutput{Body: resp}, nil
+}
+
 // ErrorOutput represents an error response
 type ErrorOutput struct {
 	Body struct {
@@ -71,8 +127,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Tenant API keys and quotas, when TENANTS_FILE is set
+	tenants, err := tenant.Load(cfg.TenantsFile)
+	if err != nil {
+		logger.Error("failed to load tenants", "error", err)
+		os.Exit(1)
+	}
+	apiLimiter, err := ratelimit.APIFromConfig(context.Background(), cfg)
+	if err != nil {
+		logger.Error("failed to create rate limiter", "error", err)
+		os.Exit(1)
+	}
+
 	// Create Chi router
 	router := chi.NewMux()
+	router.Use(tenants.Middleware)
+	router.Use(apiLimiter.Middleware)
+	router.Get("/usage", tenants.Handler(logger))
 
 	// Create Huma API
 	api := humachi.New(router, huma.DefaultConfig("Statistics Direct Search API", "1.0.0"))
@@ -81,7 +152,7 @@ func main() {
 	api.OpenAPI().Info.Description = `Direct LLM-based statistics search service.
 
 This service provides two modes:
-1. **Direct Mode** (verify_with_web: false): Fast LLM search that returns statistics with source URLs
+1. **Direct Mode** (verify_with_web: false): Fast LLM search that returns statistics with source URLs; each URL is fetched to check the excerpt exists, and the response carries an honesty score
 2. **Hybrid Mode** (verify_with_web: true): LLM search + web verification for accuracy
 
 The service uses server-side LLM configuration, so clients don't need API keys.`
@@ -93,7 +164,7 @@ The service uses server-side LLM configuration, so clients don't need API keys.`
 
 	// Add server information
 	api.OpenAPI().Servers = []*huma.Server{
-		{URL: "http://localhost:8005", Description: "Local development server"},
+		{URL: cfg.HTTPURL(8005).String(), Description: "Local development server"},
 	}
 
 	// Register the search operation
@@ -106,34 +177,54 @@ The service uses server-side LLM configuration, so clients don't need API keys.`
 		Tags:          []string{"Statistics"},
 		DefaultStatus: http.StatusOK,
 	}, func(ctx context.Context, input *DirectSearchInput) (*DirectSearchOutput, error) {
-		// Set defaults
-		minStats := input.Body.MinStats
-		if minStats == 0 {
-			minStats = 10
-		}
+		return directAgent.search(ctx, input.Body.Topic, input.Body.MinStats, input.Body.VerifyWithWeb)
+	})
 
-		directAgent.logger.Info("processing request",
-			"topic", input.Body.Topic,
-			"min_stats", minStats,
-			"verify", input.Body.VerifyWithWeb)
-
-		// Call direct search service
-		resp, err := directAgent.directSvc.SearchStatisticsWithVerification(
-			ctx,
-			input.Body.Topic,
-			minStats,
-			input.Body.VerifyWithWeb,
-		)
+	// Register the same search for GET requests with query parameters
+	huma.Register(api, huma.Operation{
+		OperationID:   "search-statistics-get",
+		Method:        http.MethodGet,
+		Path:          "/search",
+		Summary:       "Search for statistics on a topic (query parameters)",
+		Description:   "Same as the POST operation, with the topic and options as query parameters, for browsers, curl one-liners, and spreadsheet imports; format=csv returns the statistics as CSV",
+		Tags:          []string{"Statistics"},
+		DefaultStatus: http.StatusOK,
+		Responses: map[string]*huma.Response{
+			"200": {
+				Description: "OK",
+				Content: map[string]*huma.MediaType{
+					"application/json": {Schema: api.OpenAPI().Components.Schemas.Schema(reflect.TypeFor[models.OrchestrationResponse](), true, "")},
+					"text/csv":         {Schema: &huma.Schema{Type: huma.TypeString}},
+				},
+			},
+		},
+	}, func(ctx context.Context, input *DirectSearchQueryInput) (*DirectSearchQueryOutput, error) {
+		out, err := directAgent.search(ctx, input.Topic, input.MinStats, input.VerifyWithWeb)
 		if err != nil {
-			directAgent.logger.Error("search failed", "error", err)
-			return nil, huma.Error500InternalServerError(fmt.Sprintf("Search failed: %v", err))
+			return nil, err
 		}
+		if input.Format != string(export.FormatCSV) {
+			return &DirectSearchQueryOutput{Body: out.Body}, nil
+		}
+		var b bytes.Buffer
+		if err := export.Write(&b, out.Body, export.FormatCSV); err != nil {
+			return nil, huma.Error500InternalServerError(fmt.Sprintf("Failed to write CSV: %v", err))
+		}
+		return &DirectSearchQueryOutput{ContentType: export.FormatCSV.ContentType(), Body: b.Bytes()}, nil
+	})
 
-		directAgent.logger.Info("search completed",
-			"verified", resp.VerifiedCount,
-			"partial", resp.Partial)
-
-		return &DirectSearchOutput{Body: resp}, nil
+	// Register the per-provider honesty scores
+	huma.Register(api, huma.Operation{
+		OperationID: "honesty-by-provider",
+		Method:      http.MethodGet,
+		Path:        "/honesty",
+		Summary:     "Honesty scores per provider",
+		Description: "Returns, per LLM provider and model, how many direct-search statistics had resolvable source URLs and excerpts found in their sources since the service started",
+		Tags:        []string{"Statistics"},
+	}, func(ctx context.Context, input *struct{}) (*HonestyOutput, error) {
+		out := &HonestyOutput{}
+		out.Body.Providers = directAgent.directSvc.HonestyByProvider()
+		return out, nil
 	})
 
 	// Add health check endpoint
@@ -162,22 +253,25 @@ The service uses server-side LLM configuration, so clients don't need API keys.`
 		}, nil
 	})
 
+	// Swap in rotated credentials on SIGHUP or config.json changes
+	go config.WatchReload(context.Background(), cfg, logger, directAgent.directSvc.Reload)
+
 	logger.Info("HTTP server starting",
-		"port", 8005,
+		"addr", cfg.ListenAddr(8005),
 		"llm_provider", cfg.LLMProvider,
 		"llm_model", cfg.LLMModel,
-		"docs_url", "http://localhost:8005/docs")
+		"docs_url", cfg.HTTPURL(8005).JoinPath("docs").String())
 
 	// Create HTTP server with timeouts
 	server := &http.Server{
-		Addr:         ":8005",
+		Addr:         cfg.ListenAddr(8005),
 		Handler:      router,
 		ReadTimeout:  60 * time.Second,
 		WriteTimeout: 60 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
-	if err := server.ListenAndServe(); err != nil {
+	if err := agentbase.Serve(context.Background(), server, logger, directAgent.directSvc); err != nil {
 		logger.Error("HTTP server failed", "error", err)
 		os.Exit(1)
 	}
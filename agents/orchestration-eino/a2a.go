@@ -21,8 +21,10 @@ import (
 	"google.golang.org/adk/tool/functiontool"
 	"google.golang.org/genai"
 
+	"github.com/plexusone/agent-team-stats/pkg/a2astream"
 	"github.com/plexusone/agent-team-stats/pkg/models"
 	"github.com/plexusone/agent-team-stats/pkg/orchestration"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
 )
 
 // A2AServer represents the A2A protocol server for the Eino Orchestration Agent.
@@ -32,6 +34,7 @@ type A2AServer struct {
 	einoAgent *orchestration.EinoOrchestrationAgent
 	adkAgent  agent.Agent
 	listener  net.Listener
+	sessions  session.Service
 	baseURL   *url.URL
 	logger    *slog.Logger
 }
@@ -45,15 +48,12 @@ type OrchestrationInput struct {
 }
 
 // NewA2AServer creates a new A2A server for the Eino orchestration agent
-func NewA2AServer(einoAgent *orchestration.EinoOrchestrationAgent, port string, logger *slog.Logger) (*A2AServer, error) {
-	addr := "0.0.0.0:" + port
+func NewA2AServer(einoAgent *orchestration.EinoOrchestrationAgent, addr string, baseURL *url.URL, sessions session.Service, logger *slog.Logger) (*A2AServer, error) {
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
 
-	baseURL := &url.URL{Scheme: "http", Host: listener.Addr().String()}
-
 	// Create the orchestration tool that wraps the Eino graph
 	orchestrateTool, err := functiontool.New(functiontool.Config{
 		Name:        "orchestrate_statistics_workflow",
@@ -83,16 +83,17 @@ func NewA2AServer(einoAgent *orchestration.EinoOrchestrationAgent, port string,
 	}
 
 	// Create ADK agent wrapping the Eino orchestration
+	instruction, err := einoAgent.Prompts().Render(prompts.EinoSystem, nil)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
 	adkAgent, err := llmagent.New(llmagent.Config{
 		Name:        "eino_orchestration_agent",
 		Model:       model,
 		Description: "Orchestrates multi-agent workflow using Eino graph-based orchestration (deterministic)",
-		Instruction: `You are an orchestration agent that coordinates a statistics research workflow.
-When asked to find statistics on a topic:
-1. Use the orchestrate_statistics_workflow tool with the topic
-2. Return the verified statistics from the response
-The workflow is deterministic (graph-based, not LLM-driven).`,
-		Tools: []tool.Tool{orchestrateTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{orchestrateTool},
 	})
 	if err != nil {
 		listener.Close()
@@ -103,6 +104,7 @@ The workflow is deterministic (graph-based, not LLM-driven).`,
 		einoAgent: einoAgent,
 		adkAgent:  adkAgent,
 		listener:  listener,
+		sessions:  sessions,
 		baseURL:   baseURL,
 		logger:    logger,
 	}, nil
@@ -132,12 +134,13 @@ func (s *A2AServer) Start(context.Context) error {
 		RunnerConfig: runner.Config{
 			AppName:        s.adkAgent.Name(),
 			Agent:          s.adkAgent,
-			SessionService: session.InMemoryService(),
+			SessionService: s.sessions,
 		},
 	})
 
-	// Create handlers
-	requestHandler := a2asrv.NewHandler(executor)
+	// Create handlers. Statistics stream to clients as they pass
+	// verification.
+	requestHandler := a2asrv.NewHandler(a2astream.Wrap(executor, s.logger))
 	mux.Handle(agentPath, a2asrv.NewJSONRPCHandler(requestHandler))
 
 	// Health check
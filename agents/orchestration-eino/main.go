@@ -4,11 +4,30 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/plexusone/agent-team-stats/pkg/admission"
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/archive"
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/connectapi"
+	"github.com/plexusone/agent-team-stats/pkg/evidence"
+	"github.com/plexusone/agent-team-stats/pkg/export"
+	"github.com/plexusone/agent-team-stats/pkg/graphqlapi"
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
+	"github.com/plexusone/agent-team-stats/pkg/monitor"
 	"github.com/plexusone/agent-team-stats/pkg/orchestration"
+	"github.com/plexusone/agent-team-stats/pkg/ratelimit"
+	"github.com/plexusone/agent-team-stats/pkg/report"
+	"github.com/plexusone/agent-team-stats/pkg/schemas"
+	"github.com/plexusone/agent-team-stats/pkg/sessionstore"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+	"github.com/plexusone/agent-team-stats/pkg/toolspec"
+	"github.com/plexusone/agent-team-stats/pkg/webui"
+	"github.com/plexusone/agent-team-stats/pkg/wsapi"
 )
 
 func main() {
@@ -19,7 +38,12 @@ func main() {
 	// Start A2A server if enabled (standard protocol for agent interoperability)
 	// Note: Eino uses graph-based orchestration, wrapped in ADK for A2A compatibility
 	if cfg.A2AEnabled {
-		a2aServer, err := NewA2AServer(einoAgent, "9000", logger)
+		sessions, err := sessionstore.New(context.Background(), cfg)
+		if err != nil {
+			logger.Error("failed to create A2A session store", "error", err)
+			os.Exit(1)
+		}
+		a2aServer, err := NewA2AServer(einoAgent, cfg.A2AListenAddr(9000), cfg.A2AURL(9000), sessions, logger)
 		if err != nil {
 			logger.Error("failed to create A2A server", "error", err)
 		} else {
@@ -28,20 +52,109 @@ func main() {
 					logger.Error("A2A server error", "error", err)
 				}
 			}()
-			logger.Info("A2A server started", "port", 9000)
+			logger.Info("A2A server started", "addr", cfg.A2AListenAddr(9000))
 		}
 	}
 
+	// Topic monitoring: periodically re-run subscribed searches
+	topicMonitor, err := monitor.New(cfg, einoAgent.Orchestrate, logger)
+	if err != nil {
+		logger.Error("failed to create topic monitor", "error", err)
+		os.Exit(1)
+	}
+	go topicMonitor.Start(context.Background())
+
+	// Tenant API keys and quotas, when TENANTS_FILE is set
+	tenants, err := tenant.Load(cfg.TenantsFile)
+	if err != nil {
+		logger.Error("failed to load tenants", "error", err)
+		os.Exit(1)
+	}
+	apiLimiter, err := ratelimit.APIFromConfig(context.Background(), cfg)
+	if err != nil {
+		logger.Error("failed to create rate limiter", "error", err)
+		os.Exit(1)
+	}
+
+	// Queue mode: /orchestrate enqueues jobs for the workers of every replica
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	jobs, err := jobqueue.FromConfig(ctx, cfg, einoAgent.Orchestrate, tenants, logger)
+	if err != nil {
+		logger.Error("failed to create job queue", "error", err)
+		os.Exit(1)
+	}
+	if jobs != nil {
+		einoAgent.UseJobQueue(jobs)
+		go jobs.Start(ctx)
+	}
+
+	// Source snapshots for evidence bundles, from the verification agent's
+	// archive, which also stores the artifacts of reproducible runs
+	snapshots, err := archive.New(ctx, cfg)
+	if err != nil {
+		logger.Error("failed to open snapshot archive", "error", err)
+		os.Exit(1)
+	}
+	einoAgent.UseArchive(snapshots)
+
 	// Start HTTP server with timeout (for custom security: SPIFFE, KYA, XAA, and observability)
 	timeout := time.Duration(cfg.HTTPTimeoutSeconds) * time.Second
 	server := &http.Server{
-		Addr:         ":8000",
+		Addr:         cfg.ListenAddr(8000),
+		Handler:      tenants.Middleware(apiLimiter.Middleware(http.DefaultServeMux)),
+		Protocols:    connectapi.Protocols(),
 		ReadTimeout:  timeout,
 		WriteTimeout: timeout,
 		IdleTimeout:  timeout * 2,
 	}
 
-	http.HandleFunc("/orchestrate", einoAgent.HandleOrchestrationRequest)
+	admit := admission.Orchestration(cfg)
+	backend := graphqlapi.Backend{
+		Progress:   einoAgent.Progress(),
+		Statistics: einoAgent.Statistics(),
+		Jobs:       jobs,
+		Admission:  admit,
+		Validate:   einoAgent.ValidateRequest,
+		Run:        einoAgent.Orchestrate,
+	}
+	gql, err := graphqlapi.New(backend, logger)
+	if err != nil {
+		logger.Error("failed to create GraphQL API", "error", err)
+		os.Exit(1)
+	}
+	http.HandleFunc("/orchestrate", admit.Wrap(einoAgent.HandleOrchestrationRequest))
+	http.HandleFunc("/export", export.Handler(logger))
+	http.HandleFunc("/reports", report.Handler(einoAgent.Reports(), logger))
+	http.HandleFunc("/reports/", report.Handler(einoAgent.Reports(), logger))
+	http.HandleFunc("/subscriptions", topicMonitor.HandleSubscriptions)
+	http.HandleFunc("/subscriptions/", topicMonitor.HandleSubscriptions)
+	http.HandleFunc("/schemas", schemas.Handler(logger))
+	http.HandleFunc("/schemas/", schemas.Handler(logger))
+	http.HandleFunc("/usage", tenants.Handler(logger))
+	http.HandleFunc("/jobs/", jobs.Handler(logger))
+	http.HandleFunc("/jobs/{id}/evidence", evidence.JobHandler(jobs, snapshots, einoAgent.Prompts(), logger))
+	http.HandleFunc("/jobs/{id}/report", report.JobHandler(jobs, logger))
+	http.HandleFunc("/runs", einoAgent.Progress().Handler(logger))
+	http.HandleFunc("/runs/", einoAgent.Progress().Handler(logger))
+	http.HandleFunc("/graphql", gql.Handler())
+	http.Handle(connectapi.New(connectapi.Backend(backend), logger).Handler())
+	http.HandleFunc("/ws", wsapi.New(wsapi.Backend{
+		Progress:  backend.Progress,
+		Admission: admit,
+		Validate:  backend.Validate,
+		Run:       backend.Run,
+	}, logger).Handler())
+	http.HandleFunc("/ui", webui.Handler())
+	http.HandleFunc("/ui/", webui.Handler())
+	http.HandleFunc("/statistics/search", einoAgent.Statistics().Handler(logger))
+	http.HandleFunc("/statistics/export", einoAgent.Statistics().ExportHandler(logger))
+	http.HandleFunc("/statistics/changes", einoAgent.Statistics().ChangesHandler(logger))
+	http.HandleFunc("/candidates/import", admit.Wrap(einoAgent.Statistics().ImportHandler(cfg, einoAgent.Verify, logger)))
+	http.HandleFunc("/verify", admit.Wrap(toolspec.VerifyHandler(cfg, einoAgent.Statistics().WatchSources(einoAgent.Verify), logger)))
+	http.HandleFunc(toolspec.SpecPath, toolspec.Handler(cfg, tenants != nil, logger))
+	http.HandleFunc(toolspec.OpenAPIPath, toolspec.Handler(cfg, tenants != nil, logger))
+	http.HandleFunc(toolspec.ManifestPath, toolspec.Handler(cfg, tenants != nil, logger))
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("OK")); err != nil {
@@ -49,10 +162,13 @@ func main() {
 		}
 	})
 
+	// Swap in rotated credentials on SIGHUP or config.json changes
+	go config.WatchReload(context.Background(), cfg, logger, einoAgent.Reload)
+
 	logger.Info("HTTP server starting",
-		"port", 8000,
+		"addr", server.Addr,
 		"mode", "Eino graph-based deterministic")
-	if err := server.ListenAndServe(); err != nil {
+	if err := agentbase.Serve(ctx, server, logger, einoAgent); err != nil {
 		logger.Error("HTTP server failed", "error", err)
 		os.Exit(1)
 	}
@@ -13,6 +13,8 @@ import (
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/server/adka2a"
 	"google.golang.org/adk/session"
+
+	"github.com/plexusone/agent-team-stats/pkg/a2astream"
 )
 
 // A2AServer represents the A2A protocol server for the ADK Orchestration Agent.
@@ -26,23 +28,22 @@ import (
 type A2AServer struct {
 	agent    *OrchestrationAgent
 	listener net.Listener
+	sessions session.Service
 	baseURL  *url.URL
 	logger   *slog.Logger
 }
 
 // NewA2AServer creates a new A2A server for the orchestration agent
-func NewA2AServer(agent *OrchestrationAgent, port string, logger *slog.Logger) (*A2AServer, error) {
-	addr := "0.0.0.0:" + port
+func NewA2AServer(agent *OrchestrationAgent, addr string, baseURL *url.URL, sessions session.Service, logger *slog.Logger) (*A2AServer, error) {
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
 
-	baseURL := &url.URL{Scheme: "http", Host: listener.Addr().String()}
-
 	return &A2AServer{
 		agent:    agent,
 		listener: listener,
+		sessions: sessions,
 		baseURL:  baseURL,
 		logger:   logger,
 	}, nil
@@ -72,12 +73,13 @@ func (s *A2AServer) Start(context.Context) error {
 		RunnerConfig: runner.Config{
 			AppName:        s.agent.adkAgent.Name(),
 			Agent:          s.agent.adkAgent,
-			SessionService: session.InMemoryService(),
+			SessionService: s.sessions,
 		},
 	})
 
-	// Create request handler and JSON-RPC wrapper
-	requestHandler := a2asrv.NewHandler(executor)
+	// Create request handler and JSON-RPC wrapper. Statistics stream to
+	// clients as they pass verification.
+	requestHandler := a2asrv.NewHandler(a2astream.Wrap(executor, s.logger))
 	mux.Handle(agentPath, a2asrv.NewJSONRPCHandler(requestHandler))
 
 	// Add health check endpoint
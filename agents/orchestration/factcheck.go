@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
+)
+
+const (
+	// factCheckMinStats is the number of verified statistics gathered per claim
+	factCheckMinStats = 5
+	// factCheckMaxCandidates is the default candidate budget per claim
+	factCheckMaxCandidates = 20
+	// valueTolerance is the relative difference within which a source value
+	// counts as agreeing with the claimed value
+	valueTolerance = 0.05
+)
+
+// claimNumberPattern finds the first number in a claim, with an optional percent sign
+var claimNumberPattern = regexp.MustCompile(`(-?\d[\d,]*(?:\.\d+)?)\s*(%|percent)?`)
+
+// stanceJudgment is the LLM's judgment of one verified statistic against the claim
+type stanceJudgment struct {
+	Index       int    `json:"index"`  // 1-based position in the statistics list
+	Stance      string `json:"stance"` // supports, contradicts, unrelated
+	Explanation string `json:"explanation"`
+}
+
+// FactCheck extracts the numeric claim, gathers verified statistics on its
+// subject through the normal research → synthesis → verification pipeline, and
+// judges each verified statistic as supporting or contradicting the claim.
+func (oa *OrchestrationAgent) FactCheck(ctx context.Context, req *models.FactCheckRequest) (*models.FactCheckResponse, error) {
+	tracker := usage.NewTracker(string(llm.StagePlanning))
+	resp, err := oa.factCheck(usage.WithTracker(ctx, tracker), req)
+	if err != nil {
+		tenant.Charge(ctx, tracker.Summary())
+		return nil, err
+	}
+	resp.CostSummary = tracker.Summary()
+	return resp, nil
+}
+
+// factCheck is FactCheck with its usage recorded by the tracker in ctx
+func (oa *OrchestrationAgent) factCheck(ctx context.Context, req *models.FactCheckRequest) (*models.FactCheckResponse, error) {
+	parsed := oa.parseClaim(ctx, req.Claim)
+	oa.logger.Info("fact-checking claim", "claim", req.Claim, "query", parsed.Query)
+
+	maxCandidates := req.MaxCandidates
+	if maxCandidates == 0 {
+		maxCandidates = factCheckMaxCandidates
+	}
+
+	orchResp, err := oa.orchestrate(ctx, &models.OrchestrationRequest{
+		Topic:            parsed.Query,
+		MinVerifiedStats: factCheckMinStats,
+		MaxCandidates:    maxCandidates,
+		ReputableOnly:    req.ReputableOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	usage.FromContext(ctx).Merge(orchResp.CostSummary)
+
+	resp := &models.FactCheckResponse{
+		Claim:             req.Claim,
+		Parsed:            parsed,
+		Supporting:        []models.Evidence{},
+		Contradicting:     []models.Evidence{},
+		StatisticsChecked: len(orchResp.Statistics),
+		Timestamp:         time.Now(),
+	}
+
+	if len(orchResp.Statistics) == 0 {
+		resp.Verdict = models.FactCheckInsufficient
+		resp.Explanation = "No verified statistics were found on this subject"
+		return resp, nil
+	}
+
+	judgments, err := oa.judgeEvidence(ctx, req.Claim, parsed, orchResp.Statistics)
+	if err != nil {
+		oa.logger.Warn("failed to judge evidence", "error", err)
+		resp.Verdict = models.FactCheckInsufficient
+		resp.Explanation = fmt.Sprintf("Verified statistics were found but could not be compared with the claim: %v", err)
+		return resp, nil
+	}
+
+	for _, j := range judgments {
+		if j.Index < 1 || j.Index > len(orchResp.Statistics) {
+			continue
+		}
+		stat := orchResp.Statistics[j.Index-1]
+		evidence := models.Evidence{Statistic: stat, Explanation: j.Explanation}
+
+		switch j.Stance {
+		case "supports":
+			// Guard against lenient judgments: support requires agreeing numbers
+			if valuesDisagree(parsed, stat) {
+				evidence.Explanation = fmt.Sprintf("Source states %v %s, claim states %v %s",
+					stat.Value, stat.Unit, *parsed.Value, parsed.Unit)
+				resp.Contradicting = append(resp.Contradicting, evidence)
+				continue
+			}
+			resp.Supporting = append(resp.Supporting, evidence)
+		case "contradicts":
+			resp.Contradicting = append(resp.Contradicting, evidence)
+		}
+	}
+
+	resp.Verdict = models.DecideFactCheckVerdict(len(resp.Supporting), len(resp.Contradicting))
+	resp.Explanation = fmt.Sprintf("%d supporting and %d contradicting of %d verified statistics",
+		len(resp.Supporting), len(resp.Contradicting), len(orchResp.Statistics))
+
+	oa.logger.Info("fact check completed",
+		"verdict", resp.Verdict,
+		"supporting", len(resp.Supporting),
+		"contradicting", len(resp.Contradicting))
+
+	return resp, nil
+}
+
+// parseClaim uses the LLM to extract the subject, value, unit, and period of a
+// claim, falling back to the first number in the text and the claim itself as
+// the search query.
+func (oa *OrchestrationAgent) parseClaim(ctx context.Context, claim string) models.NumericClaim {
+	var response string
+	prompt, err := oa.prompts.Render(prompts.FactCheckParse, prompts.ClaimData{Claim: claim})
+	if err == nil {
+		response, err = oa.generate(ctx, prompt)
+	}
+	if err == nil {
+		var parsed models.NumericClaim
+		if err = json.Unmarshal([]byte(extractJSONSpan(response, '{', '}')), &parsed); err == nil && parsed.Query != "" {
+			return parsed
+		}
+	}
+	oa.logger.Warn("falling back to heuristic claim parsing", "error", err)
+
+	parsed := models.NumericClaim{Subject: claim, Query: claim}
+	if m := claimNumberPattern.FindStringSubmatch(claim); m != nil {
+		if v, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64); err == nil {
+			parsed.Value = &v
+		}
+		if m[2] != "" {
+			parsed.Unit = "%"
+		}
+	}
+	return parsed
+}
+
+// judgeEvidence asks the LLM whether each verified statistic supports,
+// contradicts, or is unrelated to the claim
+func (oa *OrchestrationAgent) judgeEvidence(ctx context.Context, claim string, parsed models.NumericClaim, stats []models.Statistic) ([]stanceJudgment, error) {
+	prompt, err := oa.prompts.Render(prompts.FactCheckJudge, prompts.StanceData{
+		Claim:      claim,
+		Subject:    parsed.Subject,
+		Period:     parsed.Period,
+		Statistics: stats,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := oa.generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var judgments []stanceJudgment
+	if err := json.Unmarshal([]byte(extractJSONSpan(response, '[', ']')), &judgments); err != nil {
+		return nil, fmt.Errorf("failed to parse judgments: %w", err)
+	}
+	return judgments, nil
+}
+
+// generate sends a single-turn prompt to the LLM and returns the response text
+func (oa *OrchestrationAgent) generate(ctx context.Context, prompt string) (string, error) {
+	llmReq := &model.LLMRequest{
+		Contents: genai.Text(prompt),
+	}
+
+	var response string
+	for llmResp, err := range oa.model.GenerateContent(ctx, llmReq, false) {
+		if err != nil {
+			return "", fmt.Errorf("LLM generation failed: %w", err)
+		}
+		if llmResp.Content != nil {
+			for _, part := range llmResp.Content.Parts {
+				response += part.Text
+			}
+		}
+	}
+	return response, nil
+}
+
+// valuesDisagree reports whether a statistic in the claim's unit states a value
+// outside valueTolerance of the claimed value
+func valuesDisagree(parsed models.NumericClaim, stat models.Statistic) bool {
+	if parsed.Value == nil || !strings.EqualFold(strings.TrimSpace(parsed.Unit), strings.TrimSpace(stat.Unit)) {
+		return false
+	}
+	claimed := *parsed.Value
+	diff := math.Abs(float64(stat.Value) - claimed)
+	return diff > valueTolerance*math.Max(math.Abs(claimed), 1e-9)
+}
+
+// extractJSONSpan strips markdown fences and surrounding text from a JSON
+// object or array response
+func extractJSONSpan(response string, openCh, closeCh byte) string {
+	response = strings.TrimSpace(response)
+	start := strings.IndexByte(response, openCh)
+	end := strings.LastIndexByte(response, closeCh)
+	if start == -1 || end < start {
+		return response
+	}
+	return response[start : end+1]
+}
+
+// HandleFactCheckRequest is the HTTP handler for POST /factcheck
+func (oa *OrchestrationAgent) HandleFactCheckRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.FactCheckRequest
+	if err := migrate.Decode(r.Body, &req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Claim) == "" {
+		http.Error(w, "claim is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := oa.FactCheck(r.Context(), &req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Fact check failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	tenant.Charge(r.Context(), resp.CostSummary)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		oa.logger.Error("failed to encode fact check response", "error", err)
+	}
+}
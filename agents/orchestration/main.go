@@ -3,30 +3,82 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 
+	"github.com/plexusone/agent-team-stats/pkg/admission"
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/compare"
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/conflict"
+	"github.com/plexusone/agent-team-stats/pkg/connectapi"
+	"github.com/plexusone/agent-team-stats/pkg/domainyield"
+	"github.com/plexusone/agent-team-stats/pkg/dryrun"
+	"github.com/plexusone/agent-team-stats/pkg/evidence"
+	"github.com/plexusone/agent-team-stats/pkg/export"
+	"github.com/plexusone/agent-team-stats/pkg/graphqlapi"
 	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
 	"github.com/plexusone/agent-team-stats/pkg/llm"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
 	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/monitor"
+	"github.com/plexusone/agent-team-stats/pkg/postprocess"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/ratelimit"
+	"github.com/plexusone/agent-team-stats/pkg/refine"
+	"github.com/plexusone/agent-team-stats/pkg/report"
+	"github.com/plexusone/agent-team-stats/pkg/repro"
+	"github.com/plexusone/agent-team-stats/pkg/safety"
+	"github.com/plexusone/agent-team-stats/pkg/schemas"
+	"github.com/plexusone/agent-team-stats/pkg/semdedup"
+	"github.com/plexusone/agent-team-stats/pkg/series"
+	"github.com/plexusone/agent-team-stats/pkg/sessionstore"
+	"github.com/plexusone/agent-team-stats/pkg/sourcelist"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+	"github.com/plexusone/agent-team-stats/pkg/timing"
+	"github.com/plexusone/agent-team-stats/pkg/toolspec"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
+	"github.com/plexusone/agent-team-stats/pkg/webui"
+	"github.com/plexusone/agent-team-stats/pkg/wsapi"
 )
 
 // OrchestrationAgent uses ADK to coordinate research and verification agents
 type OrchestrationAgent struct {
-	cfg      *config.Config
-	client   *http.Client
-	adkAgent agent.Agent
-	logger   *slog.Logger
+	cfg          *config.Config
+	client       *http.Client
+	adkAgent     agent.Agent
+	model        model.LLM
+	modelFactory *llm.ModelFactory
+	sessions     *refine.Store
+	reports      *report.Store      // Nil unless REPORT_DIR is set
+	yields       *domainyield.Store // Nil unless DOMAIN_YIELD_FILE is set
+	statistics   *statstore.Store   // Nil unless STATS_STORE_FILE is set
+	jobs         *jobqueue.Runner   // Nil unless JOB_QUEUE is set
+	archive      archive.Store      // Stores the artifacts of reproducible runs; nil unless ARCHIVE_BACKEND is set
+	planner      *dryrun.Planner
+	progress     *progress.Hub
+	dedup        *semdedup.Deduper
+	hook         *postprocess.Hook // Transforms the final statistics; nil unless POSTPROCESS_* is set
+	safety       *safety.Filter    // Screens topics and verified statistics; nil unless SAFETY_RULES_FILE is set
+	prompts      *prompts.Set
+	logger       *slog.Logger
 }
 
 // OrchestrationInput defines input for orchestration tool
@@ -48,18 +100,66 @@ func NewOrchestrationAgent(cfg *config.Config, logger *slog.Logger) (*Orchestrat
 
 	// Create model using factory
 	modelFactory := llm.NewModelFactory(ctx, cfg)
-	model, err := modelFactory.CreateModel(ctx)
+	llmModel, err := modelFactory.CreateModelFor(ctx, llm.StagePlanning)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
-	logger.Info("agent initialized", "provider", modelFactory.GetProviderInfo())
+	logger.Info("agent initialized", "provider", modelFactory.GetStageInfo(llm.StagePlanning))
+
+	dedup, err := semdedup.FromConfig(ctx, cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create semantic deduper: %w", err)
+	}
+
+	promptSet, err := prompts.FromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	if cfg.PromptsDir != "" {
+		logger.Info("prompt overrides loaded", "dir", cfg.PromptsDir, "versions", promptSet.Versions())
+	}
+
+	// An unusable report directory disables saving reports, as in the Eino
+	// orchestrator, rather than keeping the agent from starting
+	reports, err := report.NewStore(cfg)
+	if err != nil {
+		logger.Warn("verification reports disabled", "error", err)
+	}
+	yields, err := domainyield.NewStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open domain yield: %w", err)
+	}
+	statistics, err := statstore.NewStore(ctx, cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open statistics store: %w", err)
+	}
+	safetyFilter, err := safety.FromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load safety rules: %w", err)
+	}
+	hook, err := postprocess.New(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up post-processing hook: %w", err)
+	}
 
 	oa := &OrchestrationAgent{
-		cfg:    cfg,
-		client: &http.Client{Timeout: 60 * time.Second},
-		logger: logger,
+		cfg:          cfg,
+		client:       &http.Client{Timeout: 60 * time.Second},
+		model:        llmModel,
+		modelFactory: modelFactory,
+		sessions:     refine.NewStore(refine.DefaultTTL),
+		progress:     progress.NewHub(),
+		reports:      reports,
+		yields:       yields,
+		statistics:   statistics,
+		hook:         hook,
+		safety:       safetyFilter,
+		dedup:        dedup,
+		prompts:      promptSet,
+		logger:       logger,
 	}
+	oa.planner = dryrun.New(cfg, promptSet, oa.callResearchAgent)
 
 	// Create orchestration tool
 	orchestrationTool, err := functiontool.New(functiontool.Config{
@@ -71,23 +171,16 @@ func NewOrchestrationAgent(cfg *config.Config, logger *slog.Logger) (*Orchestrat
 	}
 
 	// Create ADK agent
+	instruction, err := promptSet.Render(prompts.OrchestrationSystem, nil)
+	if err != nil {
+		return nil, err
+	}
 	adkAgent, err := llmagent.New(llmagent.Config{
 		Name:        "statistics_orchestration_agent",
-		Model:       model,
+		Model:       llmModel,
 		Description: "Orchestrates multi-agent workflow to find and verify statistics",
-		Instruction: `You are a statistics orchestration agent. Your job is to:
-1. Coordinate the research agent to find candidate statistics
-2. Send candidates to the verification agent for validation
-3. Retry if needed to meet the target number of verified statistics
-4. Return a final set of verified statistics with sources
-
-Workflow:
-- Request statistics from research agent based on topic
-- Send candidates to verification agent
-- Collect verified statistics
-- If target not met and retries available, request more candidates
-- Build final response with all verified statistics`,
-		Tools: []tool.Tool{orchestrationTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{orchestrationTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ADK agent: %w", err)
@@ -124,74 +217,135 @@ func (oa *OrchestrationAgent) orchestrationToolHandler(ctx tool.Context, input O
 	}, nil
 }
 
-// orchestrate coordinates the workflow to find verified statistics
-func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+// orchestrate coordinates the workflow to find verified statistics. The
+// configured defaults fill in what the request leaves unset, whichever
+// path it came by.
+func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.OrchestrationRequest) (_ *models.OrchestrationResponse, err error) {
+	oa.cfg.ApplyDefaults(req)
 	var allCandidates []models.CandidateStatistic
 	var verifiedStatistics []models.Statistic
+	var rejected []models.VerificationResult
+	var sources []models.SourceFingerprint
 	totalVerified := 0
 	totalFailed := 0
 	maxRetries := 3
 	retry := 0
+	offset := 0 // Search results already processed, so retries see new URLs
+	query := "" // Relaxed search query once research had to broaden the topic
+	noSources := false
+	tracker := usage.NewTracker(string(llm.StagePlanning))
+	ctx = usage.WithTracker(ctx, tracker)
+	defer tenant.ChargeFailed(ctx, tracker.Summary, &err)
+	timer := timing.New()
+
+	// Listed sources replace research: every one is read, a page per pass,
+	// whether or not the target is reached first
+	listed := sourcelist.Results(req.Sources)
+	if len(listed) > 0 {
+		if err := sourcelist.Store(ctx, oa.archive, req.Sources); err != nil {
+			return nil, err
+		}
+		maxRetries += sourcelist.Passes(listed, req.MaxPages) - 1
+	}
+
+	ctx, run := oa.progress.Begin(ctx, req.Topic, req.MinVerifiedStats)
 
-	for retry < maxRetries && totalVerified < req.MinVerifiedStats {
-		// Calculate how many more candidates we need
-		candidatesNeeded := req.MinVerifiedStats - totalVerified
-		if candidatesNeeded < 5 {
-			candidatesNeeded = 5 // Always request at least 5 for buffer
+	for retry < maxRetries && (len(listed) > 0 || totalVerified < req.MinVerifiedStats) {
+		if retry > 0 {
+			timer.Retry()
 		}
 
-		// Don't exceed max candidates
+		// Gather a buffer of candidates for the statistics still needed,
+		// as some will fail verification, without exceeding max candidates
+		statsNeeded := req.MinVerifiedStats - totalVerified
 		candidatesLeft := req.MaxCandidates - len(allCandidates)
 		if candidatesLeft <= 0 {
 			oa.logger.Info("reached maximum candidates limit", "max", req.MaxCandidates)
 			break
 		}
-		if candidatesNeeded > candidatesLeft {
-			candidatesNeeded = candidatesLeft
-		}
+		candidatesNeeded := min(req.Budget(statsNeeded), candidatesLeft)
+
+		var searchResults []models.SearchResult
+		nextOffset := 0
+		if len(listed) > 0 {
+			// Step 1: Take the next page of the listed sources, keeping every
+			// candidate they hold within max candidates
+			searchResults, nextOffset = sourcelist.Page(listed, offset, req.MaxPages)
+			statsNeeded, candidatesNeeded = max(statsNeeded, 0), candidatesLeft
+			oa.logger.Info("reading listed sources",
+				"count", len(searchResults),
+				"offset", offset,
+				"listed", len(listed))
+		} else {
+			// Step 1: Request sources from research agent, one per page the
+			// synthesis agent will read
+			researchReq := &models.ResearchRequest{
+				Topic:         req.Topic,
+				MinStatistics: statsNeeded,
+				MaxStatistics: req.MaxPages,
+				ReputableOnly: req.ReputableOnly,
+				Offset:        offset,
+				Query:         query,
+				Reproducible:  req.Reproducible,
+			}
 
-		// Step 1: Request sources from research agent
-		researchReq := &models.ResearchRequest{
-			Topic:         req.Topic,
-			MinStatistics: candidatesNeeded,
-			MaxStatistics: candidatesNeeded + 5,
-			ReputableOnly: req.ReputableOnly,
-		}
+			oa.logger.Info("requesting sources from research agent",
+				"needed", statsNeeded,
+				"candidates", candidatesNeeded,
+				"offset", offset,
+				"attempt", retry+1,
+				"max_retries", maxRetries)
+
+			run.Stage(progress.StageResearch, retry+1)
+			searchStart := time.Now()
+			researchResp, err := oa.callResearchAgent(ctx, researchReq)
+			timer.Since(timing.Search, searchStart)
+			if err != nil {
+				oa.logger.Warn("research agent failed", "error", err)
+				retry++
+				continue
+			}
 
-		oa.logger.Info("requesting sources from research agent",
-			"needed", candidatesNeeded,
-			"attempt", retry+1,
-			"max_retries", maxRetries)
+			// Convert candidates to search results (research agent returns placeholder candidates now)
+			searchResults = make([]models.SearchResult, 0, len(researchResp.Candidates))
+			for _, cand := range researchResp.Candidates {
+				searchResults = append(searchResults, models.SearchResult{
+					URL:     cand.SourceURL,
+					Title:   cand.Name,
+					Snippet: cand.Excerpt,
+					Domain:  cand.Source,
+				})
+			}
 
-		researchResp, err := oa.callResearchAgent(ctx, researchReq)
-		if err != nil {
-			oa.logger.Warn("research agent failed", "error", err)
-			retry++
-			continue
+			oa.logger.Info("received sources from research agent", "count", len(searchResults))
+			if researchResp.Query != "" {
+				oa.logger.Info("research relaxed the search query", "query", researchResp.Query)
+				query = researchResp.Query
+			}
+			nextOffset = researchResp.NextOffset
 		}
 
-		// Convert candidates to search results (research agent returns placeholder candidates now)
-		searchResults := make([]models.SearchResult, 0, len(researchResp.Candidates))
-		for _, cand := range researchResp.Candidates {
-			searchResults = append(searchResults, models.SearchResult{
-				URL:     cand.SourceURL,
-				Title:   cand.Name,
-				Snippet: cand.Excerpt,
-				Domain:  cand.Source,
-			})
+		// Searching the same query again finds nothing new
+		if len(searchResults) == 0 {
+			noSources = offset == 0
+			oa.logger.Warn("search found no sources", "topic", req.Topic, "offset", offset)
+			break
 		}
 
-		oa.logger.Info("received sources from research agent", "count", len(searchResults))
-
 		// Step 2: Send sources to synthesis agent to extract statistics
 		synthesisReq := &models.SynthesisRequest{
 			Topic:         req.Topic,
 			SearchResults: searchResults,
-			MinStatistics: candidatesNeeded,
-			MaxStatistics: candidatesNeeded + 5,
+			MinStatistics: statsNeeded,
+			MaxStatistics: candidatesNeeded,
+			Model:         req.SynthesisOverride(),
+			Generation:    req.Generation,
+			Sampling:      req.Sampling(),
+			StageLimits:   req.StageLimits,
 		}
 
 		oa.logger.Info("sending sources to synthesis agent", "count", len(searchResults))
+		run.Stage(progress.StageSynthesis, retry+1)
 
 		synthesisResp, err := oa.callSynthesisAgent(ctx, synthesisReq)
 		if err != nil {
@@ -200,15 +354,30 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 			continue
 		}
 
+		tracker.Merge(synthesisResp.Usage)
+		timer.Merge(synthesisResp.Timings)
 		oa.logger.Info("synthesis extracted candidates", "count", len(synthesisResp.Candidates))
-		allCandidates = append(allCandidates, synthesisResp.Candidates...)
+
+		// Drop the kinds of statistic the request excludes before paying to verify them
+		candidates, excluded := req.TypeFilter.Filter(synthesisResp.Candidates)
+		if excluded > 0 {
+			oa.logger.Info("excluded candidates by statistic type", "excluded", excluded, "kept", len(candidates))
+		}
+		allCandidates = append(allCandidates, candidates...)
+		run.Candidates(len(candidates))
 
 		// Step 3: Send candidates to verification agent
 		verifyReq := &models.VerificationRequest{
-			Candidates: synthesisResp.Candidates,
+			Candidates: candidates,
+			Model:      req.VerificationOverride(),
+			Generation: req.Generation,
+			Sampling:   req.Sampling(),
+
+			PermissiveLicenseOnly: req.PermissiveLicenseOnly,
 		}
 
 		oa.logger.Info("sending candidates to verification agent", "count", len(verifyReq.Candidates))
+		run.Stage(progress.StageVerification, retry+1)
 
 		verifyResp, err := oa.callVerificationAgent(ctx, verifyReq)
 		if err != nil {
@@ -217,17 +386,25 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 			continue
 		}
 
+		tracker.Merge(verifyResp.Usage)
+		timer.Merge(verifyResp.Timings)
+		sources = append(sources, verifyResp.Sources...)
+		if blocked := oa.safety.Screen(verifyResp); blocked > 0 {
+			oa.logger.Info("statistics blocked by safety filter", "count", blocked)
+		}
 		oa.logger.Info("verification complete",
 			"verified", verifyResp.Verified,
 			"failed", verifyResp.Failed)
 
-		// Step 3: Collect verified statistics
+		// Step 3: Collect verified statistics; the run's progress already
+		// has each of them, from the verification stream
 		for _, result := range verifyResp.Results {
 			if result.Verified {
 				verifiedStatistics = append(verifiedStatistics, *result.Statistic)
 				totalVerified++
 			} else {
 				totalFailed++
+				rejected = append(rejected, result)
 				oa.logger.Debug("statistic failed verification",
 					"name", result.Statistic.Name,
 					"reason", result.Reason)
@@ -239,25 +416,65 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 			"target", req.MinVerifiedStats)
 
 		// Check if we have enough verified statistics to stop gathering more
-		if totalVerified >= req.MinVerifiedStats {
+		if totalVerified >= req.MinVerifiedStats && len(listed) == 0 {
 			oa.logger.Info("minimum target reached",
 				"verified", totalVerified)
 			break
 		}
 
+		// Continue with the next page of search results; a failed attempt
+		// above retries the same page
+		if nextOffset == 0 {
+			oa.logger.Info("search results exhausted", "offset", offset)
+			break
+		}
+		offset = nextOffset
+
 		retry++
 	}
 
+	// Fold statistics that several sources report into one, keeping the
+	// others as corroboration
+	verifiedStatistics, merged := oa.dedup.Dedupe(ctx, verifiedStatistics)
+
+	// Apply the operator's transform to the final statistics
+	verifiedStatistics, err = oa.hook.Apply(ctx, req.Topic, verifiedStatistics)
+	if err != nil {
+		run.Finish("", err)
+		return nil, err
+	}
+	// Count the statistics returned: merged duplicates are not counted
+	// twice, and the hook may drop or add some
+	totalVerified = len(verifiedStatistics)
+
+	// Flag statistics that give different values for the same metric
+	conflicts := conflict.Detect(verifiedStatistics)
+
 	// Build final response with ALL verified statistics (not limited to MinVerifiedStats)
 	response := &models.OrchestrationResponse{
-		Topic:           req.Topic,
-		Statistics:      verifiedStatistics, // Returns ALL verified statistics found
-		TotalCandidates: len(allCandidates),
-		VerifiedCount:   totalVerified,
-		FailedCount:     totalFailed,
-		Timestamp:       time.Now(),
+		Topic:            req.Topic,
+		Statistics:       verifiedStatistics, // Returns ALL verified statistics found
+		TotalCandidates:  len(allCandidates),
+		VerifiedCount:    totalVerified,
+		FailedCount:      totalFailed,
+		Timestamp:        time.Now(),
+		CostSummary:      tracker.Summary(),
+		Timings:          timer.Timings(),
+		DuplicatesMerged: merged,
+		Conflicts:        conflicts,
+		Series:           series.Assemble(verifiedStatistics),
+		Rejected:         rejected,
+		Sources:          sources,
+		Status:           models.RunStatus(totalVerified, req.MinVerifiedStats, noSources),
+		Partial:          totalVerified < req.MinVerifiedStats,
+		TargetCount:      req.MinVerifiedStats,
+		Query:            query,
 	}
 
+	// Withdraw streamed statistics the result does not hold
+	run.Reconcile(verifiedStatistics)
+	run.Finish(response.Status, nil)
+
 	if totalVerified < req.MinVerifiedStats {
 		oa.logger.Warn("below target",
 			"verified", totalVerified,
@@ -275,9 +492,12 @@ func (oa *OrchestrationAgent) orchestrate(ctx context.Context, req *models.Orche
 func (oa *OrchestrationAgent) callResearchAgent(ctx context.Context, req *models.ResearchRequest) (*models.ResearchResponse, error) {
 	var resp models.ResearchResponse
 	url := fmt.Sprintf("%s/research", oa.cfg.ResearchAgentURL)
+	repro.FromContext(ctx).Record(ctx, "research-request", req)
 	if err := httpclient.PostJSON(ctx, oa.client, url, req, &resp); err != nil {
 		return nil, err
 	}
+	repro.FromContext(ctx).Record(ctx, "research-response", &resp)
+	usage.FromContext(ctx).AddSearches(resp.SearchCalls)
 	return &resp, nil
 }
 
@@ -285,25 +505,104 @@ func (oa *OrchestrationAgent) callResearchAgent(ctx context.Context, req *models
 func (oa *OrchestrationAgent) callSynthesisAgent(ctx context.Context, req *models.SynthesisRequest) (*models.SynthesisResponse, error) {
 	var resp models.SynthesisResponse
 	url := fmt.Sprintf("%s/synthesize", oa.cfg.SynthesisAgentURL)
+	repro.FromContext(ctx).Record(ctx, "synthesis-request", req)
 	if err := httpclient.PostJSON(ctx, oa.client, url, req, &resp); err != nil {
 		return nil, err
 	}
+	repro.FromContext(ctx).Record(ctx, "synthesis-response", &resp)
 	return &resp, nil
 }
 
-// callVerificationAgent calls the verification agent via HTTP
+// callVerificationAgent calls the verification agent via HTTP, reporting
+// each result to the run's progress as soon as the agent streams it, so
+// statistics reach watchers the moment they pass verification. The results
+// of a call that fails are retracted.
 func (oa *OrchestrationAgent) callVerificationAgent(ctx context.Context, req *models.VerificationRequest) (*models.VerificationResponse, error) {
-	var resp models.VerificationResponse
 	url := fmt.Sprintf("%s/verify", oa.cfg.VerificationAgentURL)
-	if err := httpclient.PostJSON(ctx, oa.client, url, req, &resp); err != nil {
+	repro.FromContext(ctx).Record(ctx, "verification-request", req)
+	var onResult func(models.VerificationResult)
+	run := progress.FromContext(ctx)
+	var streamed []models.Statistic
+	failed := 0
+	if run != nil {
+		onResult = func(result models.VerificationResult) {
+			oa.safety.ScreenResult(&result)
+			run.Result(result)
+			if result.Verified && result.Statistic != nil {
+				streamed = append(streamed, *result.Statistic)
+			} else {
+				failed++
+			}
+		}
+	}
+	resp, err := httpclient.PostVerification(ctx, oa.client, url, req, onResult)
+	if err != nil {
+		// The pass failed, so nothing it streamed is part of the run
+		run.Retract(streamed, failed)
 		return nil, err
 	}
-	return &resp, nil
+	repro.FromContext(ctx).Record(ctx, "verification-response", resp)
+	return resp, nil
 }
 
-// Orchestrate is the public method for orchestrating the workflow
+// Orchestrate is the public method for orchestrating the workflow. Requests
+// with Compare set run one orchestration per entity and align the results;
+// requests with DryRun set only return the plan. Reproducible requests
+// record their intermediate artifacts. The configured defaults fill in what
+// the request leaves unset, as in the Eino orchestrator.
 func (oa *OrchestrationAgent) Orchestrate(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
-	return oa.orchestrate(ctx, req)
+	oa.cfg.ApplyDefaults(req)
+	if err := llm.ValidateRequest(oa.cfg, req); err != nil {
+		return nil, err
+	}
+	flags, err := oa.safety.ScreenRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		return oa.planner.Plan(ctx, req)
+	}
+	if req.Reproducible {
+		ctx = repro.Begin(ctx, req, oa.archive, oa.logger)
+	}
+	var resp *models.OrchestrationResponse
+	if len(req.Compare) > 0 {
+		resp, err = compare.Run(ctx, req, oa.orchestrate)
+	} else {
+		resp, err = oa.orchestrate(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.SafetyFlags = flags
+	if req.IncludeSummary {
+		oa.summarize(ctx, resp)
+	}
+	resp.Reproducibility = repro.FromContext(ctx).Reproducibility(req.Sampling())
+	oa.reports.Attach(ctx, resp, oa.logger)
+	if err := oa.yields.Record(resp); err != nil {
+		oa.logger.Warn("failed to record domain yield", "error", err)
+	}
+	if err := oa.statistics.Record(ctx, resp); err != nil {
+		oa.logger.Warn("failed to store verified statistics", "error", err)
+	}
+	return resp, nil
+}
+
+// validateRequest applies the configured defaults to an orchestration
+// request and checks it before it is run or queued
+func (oa *OrchestrationAgent) validateRequest(req *models.OrchestrationRequest) error {
+	oa.cfg.ApplyDefaults(req)
+	if err := llm.ValidateRequest(oa.cfg, req); err != nil {
+		return err
+	}
+	if _, err := oa.safety.ScreenRequest(req); err != nil {
+		return err
+	}
+	if req.HasDocuments() && oa.archive == nil {
+		return sourcelist.ErrNoArchive
+	}
+	return nil
 }
 
 // HandleOrchestrationRequest is the HTTP handler for orchestration requests.
@@ -314,18 +613,49 @@ func (oa *OrchestrationAgent) HandleOrchestrationRequest(w http.ResponseWriter,
 		return
 	}
 
+	offset, limit, err := models.ParsePage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var req models.OrchestrationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := migrate.Decode(http.MaxBytesReader(w, r.Body, models.MaxRequestBytes), &req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), status)
 		return
 	}
 
-	// Set defaults
-	if req.MinVerifiedStats == 0 {
-		req.MinVerifiedStats = 10
+	if err := oa.validateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	if req.MaxCandidates == 0 {
-		req.MaxCandidates = 30
+
+	// A client asking for server-sent events gets each statistic the moment
+	// it passes verification, and then the result. The run stays on this
+	// replica, in queue mode too.
+	if progress.WantsEvents(r) {
+		oa.progress.ServeRun(w, r, func(ctx context.Context) (any, error) {
+			resp, err := oa.runJob(ctx, &req)
+			if err != nil {
+				return nil, err
+			}
+			tenant.Charge(ctx, resp.CostSummary)
+			resp.Paginate(offset, limit)
+			return resp, nil
+		}, oa.logger)
+		return
+	}
+
+	// In queue mode a worker runs the request; the client polls /jobs/{id}.
+	// A dry run only searches, so it is answered directly.
+	if oa.jobs != nil && !req.DryRun {
+		oa.jobs.Accept(w, r, &req)
+		return
 	}
 
 	resp, err := oa.Orchestrate(r.Context(), &req)
@@ -333,6 +663,12 @@ func (oa *OrchestrationAgent) HandleOrchestrationRequest(w http.ResponseWriter,
 		http.Error(w, fmt.Sprintf("Orchestration failed: %v", err), http.StatusInternalServerError)
 		return
 	}
+	tenant.Charge(r.Context(), resp.CostSummary)
+
+	// Keep the results so the client can narrow them via POST /refine
+	if !req.DryRun {
+		resp.SessionID = oa.sessions.Create(req, resp.Statistics).ID
+	}
 
 	// Check for claims format request via query parameter
 	format := r.URL.Query().Get("format")
@@ -345,13 +681,25 @@ func (oa *OrchestrationAgent) HandleOrchestrationRequest(w http.ResponseWriter,
 		return
 	}
 
-	// Default: return original format
+	// Default: return original format, one page of it if requested
+	resp.Paginate(offset, limit)
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		oa.logger.Error("failed to encode response", "error", err)
 	}
 }
 
+// runJob runs a queued, streamed, or GraphQL orchestration request,
+// keeping its results for POST /refine on the replica that ran it
+func (oa *OrchestrationAgent) runJob(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+	resp, err := oa.Orchestrate(ctx, req)
+	if err != nil || req.DryRun {
+		return resp, err
+	}
+	resp.SessionID = oa.sessions.Create(*req, resp.Statistics).ID
+	return resp, nil
+}
+
 func main() {
 	logger := logging.NewAgentLogger("orchestration")
 	cfg := config.LoadConfig()
@@ -364,7 +712,12 @@ func main() {
 
 	// Start A2A server if enabled (standard protocol for agent interoperability)
 	if cfg.A2AEnabled {
-		a2aServer, err := NewA2AServer(orchestrationAgent, "9000", logger)
+		sessions, err := sessionstore.New(context.Background(), cfg)
+		if err != nil {
+			logger.Error("failed to create A2A session store", "error", err)
+			os.Exit(1)
+		}
+		a2aServer, err := NewA2AServer(orchestrationAgent, cfg.A2AListenAddr(9000), cfg.A2AURL(9000), sessions, logger)
 		if err != nil {
 			logger.Error("failed to create A2A server", "error", err)
 		} else {
@@ -373,19 +726,114 @@ func main() {
 					logger.Error("A2A server error", "error", err)
 				}
 			}()
-			logger.Info("A2A server started", "port", 9000)
+			logger.Info("A2A server started", "addr", cfg.A2AListenAddr(9000))
 		}
 	}
 
+	// Topic monitoring: periodically re-run subscribed searches
+	topicMonitor, err := monitor.New(cfg, orchestrationAgent.Orchestrate, logger)
+	if err != nil {
+		logger.Error("failed to create topic monitor", "error", err)
+		os.Exit(1)
+	}
+	go topicMonitor.Start(context.Background())
+
+	// Swap in rotated credentials on SIGHUP or config.json changes
+	go config.WatchReload(context.Background(), cfg, logger, orchestrationAgent.modelFactory.Reload)
+
+	// Tenant API keys and quotas, when TENANTS_FILE is set
+	tenants, err := tenant.Load(cfg.TenantsFile)
+	if err != nil {
+		logger.Error("failed to load tenants", "error", err)
+		os.Exit(1)
+	}
+	apiLimiter, err := ratelimit.APIFromConfig(context.Background(), cfg)
+	if err != nil {
+		logger.Error("failed to create rate limiter", "error", err)
+		os.Exit(1)
+	}
+
+	// Queue mode: /orchestrate enqueues jobs for the workers of every replica
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	orchestrationAgent.jobs, err = jobqueue.FromConfig(ctx, cfg, orchestrationAgent.runJob, tenants, logger)
+	if err != nil {
+		logger.Error("failed to create job queue", "error", err)
+		os.Exit(1)
+	}
+	if orchestrationAgent.jobs != nil {
+		go orchestrationAgent.jobs.Start(ctx)
+	}
+
+	// Source snapshots for evidence bundles, from the verification agent's
+	// archive, which also stores the artifacts of reproducible runs
+	snapshots, err := archive.New(ctx, cfg)
+	if err != nil {
+		logger.Error("failed to open snapshot archive", "error", err)
+		os.Exit(1)
+	}
+	orchestrationAgent.archive = snapshots
+
 	// Start HTTP server with timeout (for custom security: SPIFFE, KYA, XAA, and observability)
 	server := &http.Server{
-		Addr:         ":8000",
+		Addr:         cfg.ListenAddr(8000),
+		Handler:      tenants.Middleware(apiLimiter.Middleware(http.DefaultServeMux)),
+		Protocols:    connectapi.Protocols(),
 		ReadTimeout:  60 * time.Second,
 		WriteTimeout: 60 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
-	http.HandleFunc("/orchestrate", orchestrationAgent.HandleOrchestrationRequest)
+	// Orchestration and fact checks share one admission limit
+	admit := admission.Orchestration(cfg)
+	backend := graphqlapi.Backend{
+		Progress:   orchestrationAgent.progress,
+		Statistics: orchestrationAgent.statistics,
+		Jobs:       orchestrationAgent.jobs,
+		Admission:  admit,
+		Validate:   orchestrationAgent.validateRequest,
+		Run:        orchestrationAgent.runJob,
+	}
+	gql, err := graphqlapi.New(backend, logger)
+	if err != nil {
+		logger.Error("failed to create GraphQL API", "error", err)
+		os.Exit(1)
+	}
+	http.HandleFunc("/orchestrate", admit.Wrap(orchestrationAgent.HandleOrchestrationRequest))
+	http.HandleFunc("/factcheck", admit.Wrap(orchestrationAgent.HandleFactCheckRequest))
+	http.HandleFunc("/refine", orchestrationAgent.HandleRefineRequest)
+	http.HandleFunc("/sessions/", orchestrationAgent.HandleSessionStatistics)
+	http.HandleFunc("/export", export.Handler(logger))
+	http.HandleFunc("/reports", report.Handler(orchestrationAgent.reports, logger))
+	http.HandleFunc("/reports/", report.Handler(orchestrationAgent.reports, logger))
+	http.HandleFunc("/subscriptions", topicMonitor.HandleSubscriptions)
+	http.HandleFunc("/subscriptions/", topicMonitor.HandleSubscriptions)
+	http.HandleFunc("/schemas", schemas.Handler(logger))
+	http.HandleFunc("/schemas/", schemas.Handler(logger))
+	http.HandleFunc("/usage", tenants.Handler(logger))
+	http.HandleFunc("/jobs/", orchestrationAgent.jobs.Handler(logger))
+	http.HandleFunc("/jobs/{id}/evidence", evidence.JobHandler(orchestrationAgent.jobs, snapshots, orchestrationAgent.prompts, logger))
+	http.HandleFunc("/jobs/{id}/report", report.JobHandler(orchestrationAgent.jobs, logger))
+	http.HandleFunc("/runs", orchestrationAgent.progress.Handler(logger))
+	http.HandleFunc("/runs/", orchestrationAgent.progress.Handler(logger))
+	http.HandleFunc("/graphql", gql.Handler())
+	http.Handle(connectapi.New(connectapi.Backend(backend), logger).Handler())
+	http.HandleFunc("/ws", wsapi.New(wsapi.Backend{
+		Progress:  backend.Progress,
+		Admission: admit,
+		Validate:  backend.Validate,
+		Run:       backend.Run,
+	}, logger).Handler())
+	http.HandleFunc("/ui", webui.Handler())
+	http.HandleFunc("/ui/", webui.Handler())
+	http.HandleFunc("/statistics/search", orchestrationAgent.statistics.Handler(logger))
+	http.HandleFunc("/statistics/export", orchestrationAgent.statistics.ExportHandler(logger))
+	http.HandleFunc("/statistics/changes", orchestrationAgent.statistics.ChangesHandler(logger))
+	http.HandleFunc("/candidates/import", admit.Wrap(orchestrationAgent.statistics.ImportHandler(cfg, orchestrationAgent.callVerificationAgent, logger)))
+	http.HandleFunc("/verify", admit.Wrap(toolspec.VerifyHandler(cfg, orchestrationAgent.statistics.WatchSources(orchestrationAgent.callVerificationAgent), logger)))
+	http.HandleFunc(toolspec.SpecPath, toolspec.Handler(cfg, tenants != nil, logger))
+	http.HandleFunc(toolspec.OpenAPIPath, toolspec.Handler(cfg, tenants != nil, logger))
+	http.HandleFunc(toolspec.ManifestPath, toolspec.Handler(cfg, tenants != nil, logger))
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("OK")); err != nil {
@@ -394,9 +842,9 @@ func main() {
 	})
 
 	logger.Info("HTTP server starting",
-		"port", 8000,
+		"addr", server.Addr,
 		"mode", "dual (HTTP + A2A)")
-	if err := server.ListenAndServe(); err != nil {
+	if err := agentbase.Serve(ctx, server, logger, orchestrationAgent.modelFactory); err != nil {
 		logger.Error("HTTP server failed", "error", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+)
+
+// TestOrchestrateAppliesDefaults drives a request as the topic monitor
+// sends it, without the HTTP handler's defaults, and checks that synthesis
+// is asked for the buffered candidate budget rather than the bare target.
+func TestOrchestrateAppliesDefaults(t *testing.T) {
+	var synthesis models.SynthesisRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/research", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, models.ResearchResponse{Candidates: []models.CandidateStatistic{{SourceURL: "https://www.iea.org/ev"}}})
+	})
+	mux.HandleFunc("/synthesize", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&synthesis); err != nil {
+			t.Error(err)
+		}
+		writeJSON(t, w, models.SynthesisResponse{Candidates: []models.CandidateStatistic{
+			{Name: "EV sales share", Value: 18, SourceURL: "https://www.iea.org/ev", Excerpt: "18% of cars sold"},
+			{Name: "EV sales", Value: 14, Unit: "million", SourceURL: "https://www.iea.org/ev", Excerpt: "14 million electric cars"},
+		}})
+	})
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, r *http.Request) {
+		var req models.VerificationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		resp := models.VerificationResponse{}
+		for _, c := range req.Candidates {
+			resp.Results = append(resp.Results, models.VerificationResult{
+				Statistic: &models.Statistic{Name: c.Name, Value: c.Value, Unit: c.Unit, SourceURL: c.SourceURL, Excerpt: c.Excerpt, Verified: true},
+				Verified:  true,
+			})
+		}
+		resp.Verified = len(resp.Results)
+		writeJSON(t, w, resp)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &config.Config{
+		ResearchAgentURL:     srv.URL,
+		SynthesisAgentURL:    srv.URL,
+		VerificationAgentURL: srv.URL,
+		Defaults: config.RequestDefaults{
+			MinVerifiedStats: 10,
+			MaxCandidates:    30,
+			StageLimits:      models.StageLimits{MaxPages: 15, CandidatesPerPageCap: 10, VerificationBufferFactor: 5},
+		},
+	}
+	oa := &OrchestrationAgent{
+		cfg:      cfg,
+		client:   srv.Client(),
+		progress: progress.NewHub(),
+		logger:   slog.New(slog.DiscardHandler),
+	}
+
+	resp, err := oa.Orchestrate(context.Background(), &models.OrchestrationRequest{Topic: "EV sales", MinVerifiedStats: 2})
+	if err != nil {
+		t.Fatalf("Orchestrate() error = %v", err)
+	}
+	if resp.VerifiedCount != 2 {
+		t.Errorf("verified = %d, want 2", resp.VerifiedCount)
+	}
+	// Two statistics needed, five candidates each, within 30 candidates
+	if synthesis.MaxStatistics != 10 {
+		t.Errorf("synthesis asked for %d candidates, want 10", synthesis.MaxStatistics)
+	}
+	if synthesis.StageLimits.MaxPages != 15 || synthesis.StageLimits.CandidatesPerPageCap != 10 {
+		t.Errorf("synthesis stage limits = %+v, want the configured defaults", synthesis.StageLimits)
+	}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
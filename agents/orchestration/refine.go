@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/conflict"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/refine"
+	"github.com/plexusone/agent-team-stats/pkg/series"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+	"github.com/plexusone/agent-team-stats/pkg/timing"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
+)
+
+// Refine applies a follow-up constraint to a previous result. The session's
+// pool of verified statistics is filtered first; only if too few satisfy all
+// constraints does the orchestrator run a new search with a rewritten topic,
+// adding what it finds to the pool for later refinements. Refinements of
+// one session run one at a time, each building on the one before.
+func (oa *OrchestrationAgent) Refine(ctx context.Context, req *models.RefineRequest) (_ *models.OrchestrationResponse, err error) {
+	unlock := oa.sessions.Lock(req.SessionID)
+	defer unlock()
+
+	sess, err := oa.sessions.Get(req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	sess.Constraints = append(sess.Constraints, req.Constraint)
+
+	target := req.MinVerifiedStats
+	if target == 0 {
+		target = sess.Request.MinVerifiedStats
+	}
+
+	tracker := usage.NewTracker(string(llm.StagePlanning))
+	ctx = usage.WithTracker(ctx, tracker)
+	defer tenant.ChargeFailed(ctx, tracker.Summary, &err)
+	timer := timing.New()
+
+	oa.logger.Info("refining results",
+		"session", sess.ID,
+		"constraints", sess.Constraints,
+		"pool", len(sess.Pool))
+
+	kept, err := oa.filterStatistics(ctx, sess.Request.Topic, sess.Constraints, sess.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCandidates, failedCount := 0, 0
+	if len(kept) < target {
+		query := oa.refinedQuery(ctx, sess.Request.Topic, sess.Constraints)
+		oa.logger.Info("pool below target, searching again", "kept", len(kept), "target", target, "query", query)
+
+		followUp := sess.Request
+		followUp.Topic = query
+		followUp.MinVerifiedStats = target - len(kept)
+		more, err := oa.orchestrate(ctx, &followUp)
+		if err != nil {
+			return nil, err
+		}
+		totalCandidates, failedCount = more.TotalCandidates, more.FailedCount
+		tracker.Merge(more.CostSummary)
+		timer.Merge(more.Timings)
+
+		var added []models.Statistic
+		sess.Pool, added = refine.Merge(sess.Pool, more.Statistics)
+		if len(added) > 0 {
+			moreKept, err := oa.filterStatistics(ctx, sess.Request.Topic, sess.Constraints, added)
+			if err != nil {
+				return nil, err
+			}
+			kept = append(kept, moreKept...)
+		}
+	}
+
+	sess.Results = kept
+	oa.sessions.Save(sess)
+
+	conflicts := conflict.Detect(kept)
+
+	resp := &models.OrchestrationResponse{
+		Topic:           sess.Request.Topic,
+		Statistics:      kept,
+		Conflicts:       conflicts,
+		Series:          series.Assemble(kept),
+		TotalCandidates: totalCandidates,
+		VerifiedCount:   len(kept),
+		FailedCount:     failedCount,
+		Timestamp:       time.Now(),
+		Partial:         len(kept) < target,
+		TargetCount:     target,
+		SessionID:       sess.ID,
+		Constraints:     sess.Constraints,
+		CostSummary:     tracker.Summary(),
+		Timings:         timer.Timings(),
+	}
+	if sess.Request.IncludeSummary {
+		oa.summarize(ctx, resp)
+	}
+	return resp, nil
+}
+
+// filterStatistics asks the LLM which statistics satisfy every constraint
+func (oa *OrchestrationAgent) filterStatistics(ctx context.Context, topic string, constraints []string, stats []models.Statistic) ([]models.Statistic, error) {
+	if len(stats) == 0 {
+		return []models.Statistic{}, nil
+	}
+
+	prompt, err := oa.prompts.Render(prompts.RefineFilter, prompts.FilterData{
+		Topic:       topic,
+		Constraints: constraints,
+		Statistics:  stats,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := oa.generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var indices []int
+	if err := json.Unmarshal([]byte(extractJSONSpan(response, '[', ']')), &indices); err != nil {
+		return nil, fmt.Errorf("failed to parse filter response: %w", err)
+	}
+
+	kept := make([]models.Statistic, 0, len(indices))
+	used := make(map[int]bool)
+	for _, i := range indices {
+		if i < 1 || i > len(stats) || used[i] {
+			continue
+		}
+		used[i] = true
+		kept = append(kept, stats[i-1])
+	}
+	return kept, nil
+}
+
+// refinedQuery rewrites the topic into a search query that reflects the
+// constraints, falling back to appending them to the topic
+func (oa *OrchestrationAgent) refinedQuery(ctx context.Context, topic string, constraints []string) string {
+	fallback := topic + " " + strings.Join(constraints, " ")
+
+	prompt, err := oa.prompts.Render(prompts.RefineQuery, prompts.QueryData{Topic: topic, Constraints: constraints})
+	if err != nil {
+		oa.logger.Warn("failed to render query prompt", "error", err)
+		return fallback
+	}
+
+	response, err := oa.generate(ctx, prompt)
+	if err != nil {
+		oa.logger.Warn("failed to rewrite query", "error", err)
+		return fallback
+	}
+	query := strings.Trim(strings.TrimSpace(response), `"'`)
+	if query == "" || strings.Contains(query, "\n") {
+		return fallback
+	}
+	return query
+}
+
+// HandleRefineRequest is the HTTP handler for POST /refine
+func (oa *OrchestrationAgent) HandleRefineRequest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	offset, limit, err := models.ParsePage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req models.RefineRequest
+	if err := migrate.Decode(r.Body, &req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.SessionID == "" || strings.TrimSpace(req.Constraint) == "" {
+		http.Error(w, "session_id and constraint are required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := oa.Refine(r.Context(), &req)
+	if errors.Is(err, refine.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Refinement failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	tenant.Charge(r.Context(), resp.CostSummary)
+	resp.Paginate(offset, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		oa.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+// HandleSessionStatistics is the HTTP handler for
+// GET /sessions/{id}/statistics, which pages through the statistics of a
+// session's latest response, or with ?pool=true every statistic the session
+// has found
+func (oa *OrchestrationAgent) HandleSessionStatistics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/sessions/"), "/statistics")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	offset, limit, err := models.ParsePage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sess, err := oa.sessions.Get(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	stats := sess.Results
+	if r.URL.Query().Get("pool") == "true" {
+		stats = sess.Pool
+	}
+
+	out := models.SessionStatistics{
+		SessionID:   sess.ID,
+		Topic:       sess.Request.Topic,
+		Constraints: sess.Constraints,
+	}
+	out.Statistics, out.Page = models.Paginate(stats, offset, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		oa.logger.Error("failed to encode response", "error", err)
+	}
+}
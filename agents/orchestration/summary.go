@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/narrative"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
+)
+
+// summarize writes the cited summary paragraph of resp, adding its LLM usage
+// to the response's cost, whether or not it succeeds. A failure only leaves
+// the summary out, since the statistics themselves are complete.
+func (oa *OrchestrationAgent) summarize(ctx context.Context, resp *models.OrchestrationResponse) {
+	if len(resp.Statistics) == 0 {
+		return
+	}
+	tracker := usage.NewTracker(string(llm.StagePlanning))
+	summary, err := narrative.Write(usage.WithTracker(ctx, tracker), oa.prompts, oa.generate, resp.Topic, resp.Statistics)
+
+	total := usage.NewTracker("")
+	total.Merge(resp.CostSummary)
+	total.Merge(tracker.Summary())
+	resp.CostSummary = total.Summary()
+
+	if err != nil {
+		oa.logger.Warn("failed to write summary", "error", err)
+		return
+	}
+	summary.Model = oa.modelFactory.GetStageInfo(llm.StagePlanning)
+	resp.Summary = summary
+}
@@ -20,6 +20,8 @@ import (
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
 )
 
 // A2AServer represents the A2A protocol server for the Research Agent.
@@ -30,30 +32,28 @@ type A2AServer struct {
 	agent    *ResearchAgent
 	adkAgent agent.Agent
 	listener net.Listener
+	sessions session.Service
 	baseURL  *url.URL
 	logger   *slog.Logger
 }
 
 // NewA2AServer creates a new A2A server for the research agent
-func NewA2AServer(ra *ResearchAgent, port string, logger *slog.Logger) (*A2AServer, error) {
-	addr := "0.0.0.0:" + port
+func NewA2AServer(ra *ResearchAgent, addr string, baseURL *url.URL, sessions session.Service, logger *slog.Logger) (*A2AServer, error) {
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
 
-	baseURL := &url.URL{Scheme: "http", Host: listener.Addr().String()}
-
 	// Create the research tool that wraps the actual search functionality
 	researchTool, err := functiontool.New(functiontool.Config{
 		Name:        "web_search",
-		Description: "Searches the web for sources related to a topic. Returns URLs and snippets from search results.",
+		Description: "Searches the web for sources related to a topic. Returns URLs and snippets from search results, and a next_offset to pass as offset for more results.",
 	}, func(ctx tool.Context, input ResearchInput) (ResearchOutput, error) {
-		results, err := ra.findSources(ctx, input.Topic, input.NumResults, input.ReputableOnly)
+		results, nextOffset, err := ra.findSources(ctx, input.Topic, input.NumResults, input.Offset, input.ReputableOnly)
 		if err != nil {
 			return ResearchOutput{}, err
 		}
-		return ResearchOutput{SearchResults: results}, nil
+		return ResearchOutput{SearchResults: results, NextOffset: nextOffset}, nil
 	})
 	if err != nil {
 		listener.Close()
@@ -72,15 +72,22 @@ func NewA2AServer(ra *ResearchAgent, port string, logger *slog.Logger) (*A2AServ
 	}
 
 	// Create ADK agent wrapping the search tool
+	promptSet, err := prompts.FromConfig(ra.cfg)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	instruction, err := promptSet.Render(prompts.ResearchSystem, nil)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
 	adkAgent, err := llmagent.New(llmagent.Config{
 		Name:        "research_agent",
 		Model:       model,
 		Description: "Finds relevant web sources for statistics research via search APIs",
-		Instruction: `You are a research agent that finds web sources. When asked to find sources on a topic:
-1. Use the web_search tool with the topic
-2. Return the search results directly
-Do not analyze or summarize - just return the raw search results.`,
-		Tools: []tool.Tool{researchTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{researchTool},
 	})
 	if err != nil {
 		listener.Close()
@@ -91,6 +98,7 @@ Do not analyze or summarize - just return the raw search results.`,
 		agent:    ra,
 		adkAgent: adkAgent,
 		listener: listener,
+		sessions: sessions,
 		baseURL:  baseURL,
 		logger:   logger,
 	}, nil
@@ -120,7 +128,7 @@ func (s *A2AServer) Start(context.Context) error {
 		RunnerConfig: runner.Config{
 			AppName:        s.adkAgent.Name(),
 			Agent:          s.adkAgent,
-			SessionService: session.InMemoryService(),
+			SessionService: s.sessions,
 		},
 	})
 
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+
+	"github.com/plexusone/agent-team-stats/pkg/crawl"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/urlnorm"
+)
+
+// crawlTopResults is how many of the first results are checked for
+// landing pages
+const crawlTopResults = 5
+
+// expandLandingPages inserts the report pages linked from landing pages
+// among the top results right after each landing page, so synthesis reads
+// the reports rather than a list of links to them. Pages already among the
+// results are not repeated.
+func (ra *ResearchAgent) expandLandingPages(ctx context.Context, topic string, results []models.SearchResult) []models.SearchResult {
+	if ra.crawler == nil {
+		return results
+	}
+
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		seen[normalizedURL(r.URL)] = true
+	}
+
+	expanded := make([]models.SearchResult, 0, len(results))
+	for i, result := range results {
+		expanded = append(expanded, result)
+		if i >= crawlTopResults || !crawl.IsLandingPage(result.URL) {
+			continue
+		}
+
+		added := 0
+		for _, page := range ra.crawler.Crawl(ctx, topic, result.URL) {
+			k := normalizedURL(page.URL)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			expanded = append(expanded, models.SearchResult{
+				URL:         page.URL,
+				Title:       page.Title,
+				Domain:      result.Domain,
+				Position:    result.Position,
+				CrawledFrom: result.URL,
+			})
+			added++
+		}
+		if added > 0 {
+			ra.logger.Info("crawled landing page", "url", result.URL, "pages", added)
+		}
+	}
+	return expanded
+}
+
+// normalizedURL returns the normalized form of a URL for comparison, or the
+// URL itself if it cannot be normalized
+func normalizedURL(raw string) string {
+	if key, err := urlnorm.Normalize(raw); err == nil {
+		return key
+	}
+	return raw
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/pagemeta"
+	"github.com/plexusone/agent-team-stats/pkg/search"
+	"github.com/plexusone/agent-team-stats/pkg/urlnorm"
+)
+
+const (
+	// resolveConcurrency bounds parallel canonical URL lookups
+	resolveConcurrency = 8
+	// resolveTimeout bounds a single canonical URL lookup
+	resolveTimeout = 5 * time.Second
+)
+
+// dedupeSources replaces result URLs with their canonical form and drops
+// later results pointing at an article already listed (tracking-parameter
+// variants, AMP pages, and mirrors declaring the same canonical link), so the
+// synthesis stage does not process the same article more than once.
+func (ra *ResearchAgent) dedupeSources(ctx context.Context, results []search.SearchResult) []search.SearchResult {
+	if ra.cfg.CanonicalURLResolution {
+		ra.resolveCanonical(ctx, results)
+	}
+
+	seen := make(map[string]bool, len(results))
+	deduped := make([]search.SearchResult, 0, len(results))
+	for _, result := range results {
+		key, err := urlnorm.Normalize(result.URL)
+		if err != nil {
+			key = result.URL
+		}
+		if seen[key] {
+			ra.logger.Debug("dropping duplicate source", "url", result.URL)
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, result)
+	}
+
+	if dropped := len(results) - len(deduped); dropped > 0 {
+		ra.logger.Info("removed duplicate sources", "dropped", dropped, "remaining", len(deduped))
+	}
+	return deduped
+}
+
+// resolveCanonical follows redirects and canonical links for every result
+// in parallel, updating URLs and domains in place and reading the publisher
+// and publication date the pages declare. Results that cannot be fetched
+// keep their original URL.
+func (ra *ResearchAgent) resolveCanonical(ctx context.Context, results []search.SearchResult) {
+	resolver := urlnorm.NewResolver(ra.client, ra.cfg.FetchIdentity)
+	sem := make(chan struct{}, resolveConcurrency)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(result *search.SearchResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			lookupCtx, cancel := context.WithTimeout(ctx, resolveTimeout)
+			defer cancel()
+
+			canonical, head, err := resolver.ResolveHead(lookupCtx, result.URL)
+			if err != nil {
+				ra.logger.Debug("canonical lookup failed", "url", result.URL, "error", err)
+			}
+			if head != nil {
+				result.Metadata = pagemeta.Parse(head)
+			}
+			if canonical == "" || canonical == result.URL {
+				return
+			}
+			result.URL = canonical
+			if u, err := url.Parse(canonical); err == nil {
+				result.DisplayLink = strings.TrimPrefix(u.Hostname(), "www.")
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+}
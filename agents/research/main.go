@@ -3,17 +3,26 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/crawl"
+	"github.com/plexusone/agent-team-stats/pkg/domainyield"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
 	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/pagemeta"
+	"github.com/plexusone/agent-team-stats/pkg/policy"
 	"github.com/plexusone/agent-team-stats/pkg/search"
+	"github.com/plexusone/agent-team-stats/pkg/sessionstore"
+	"github.com/plexusone/agent-team-stats/pkg/wikicite"
 )
 
 // ResearchAgent finds relevant sources using web search
@@ -23,6 +32,9 @@ type ResearchAgent struct {
 	cfg       *config.Config
 	client    *http.Client
 	searchSvc *search.Service
+	skipList  *domainyield.SkipList
+	crawler   *crawl.Crawler     // Nil when CRAWL_ENABLED is off
+	wiki      *wikicite.Follower // Nil when WIKIPEDIA_CITATIONS is off
 	logger    *slog.Logger
 }
 
@@ -31,11 +43,13 @@ type ResearchInput struct {
 	Topic         string `json:"topic" jsonschema:"description=The topic to research statistics for"`
 	NumResults    int    `json:"num_results" jsonschema:"description=Number of search results to return"`
 	ReputableOnly bool   `json:"reputable_only" jsonschema:"description=Only return reputable sources"`
+	Offset        int    `json:"offset" jsonschema:"description=Number of search results to skip, from next_offset of a previous call"`
 }
 
 // ResearchOutput defines the output from the research tool
 type ResearchOutput struct {
 	SearchResults []models.SearchResult `json:"search_results"`
+	NextOffset    int                   `json:"next_offset"`
 }
 
 // NewResearchAgent creates a new search-focused research agent
@@ -48,74 +62,118 @@ func NewResearchAgent(cfg *config.Config) (*ResearchAgent, error) {
 		return nil, fmt.Errorf("search service required: %w", err)
 	}
 
+	// Domain yield recorded by the orchestrators demotes low-yield domains
+	yields, err := domainyield.NewStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open domain yield: %w", err)
+	}
+	skipList := domainyield.NewSkipList(cfg, yields, logger)
+
 	logger.Info("agent initialized",
 		"search_provider", cfg.SearchProvider,
-		"mode", "search-only")
+		"mode", "search-only",
+		"skip_list", len(cfg.DomainSkipList),
+		"demoted", len(skipList.Demoted()))
 
 	ra := &ResearchAgent{
 		cfg:       cfg,
-		client:    &http.Client{Timeout: 30 * time.Second},
+		client:    &http.Client{Timeout: 30 * time.Second, Transport: httpclient.FetchTransport()},
 		searchSvc: searchSvc,
+		skipList:  skipList,
+		crawler:   crawl.FromConfig(cfg, logger),
+		wiki:      wikicite.FromConfig(cfg, logger),
 		logger:    logger,
 	}
 
 	return ra, nil
 }
 
-// findSources performs web search and returns relevant URLs
-func (ra *ResearchAgent) findSources(ctx context.Context, topic string, numResults int, reputableOnly bool) ([]models.SearchResult, error) {
-	ra.logger.Info("searching for sources", "topic", topic)
+// findSources performs web search starting at a result offset and returns
+// relevant URLs along with the offset of the next page (0 when exhausted)
+func (ra *ResearchAgent) findSources(ctx context.Context, topic string, numResults, offset int, reputableOnly bool) ([]models.SearchResult, int, error) {
+	ra.logger.Info("searching for sources", "topic", topic, "offset", offset)
 
 	if numResults <= 0 {
 		numResults = 10
 	}
 
 	// Perform search
-	searchResp, err := ra.searchSvc.SearchForStatistics(ctx, topic, numResults)
+	searchResp, err := ra.searchSvc.SearchForStatisticsPage(ctx, topic, numResults, offset)
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
+		return nil, 0, fmt.Errorf("search failed: %w", err)
 	}
 
-	ra.logger.Info("search completed", "results", searchResp.Total)
+	ra.logger.Info("search completed", "results", searchResp.Total, "next_offset", searchResp.NextOffset)
+
+	// Canonicalize URLs and drop duplicate articles
+	sources := ra.dedupeSources(ctx, searchResp.Results)
 
 	// Convert search results to our model format
-	results := make([]models.SearchResult, 0, len(searchResp.Results))
-	for i, result := range searchResp.Results {
-		// Filter for reputable sources if requested
-		if reputableOnly && !isReputableSource(result.DisplayLink) {
-			ra.logger.Debug("filtering non-reputable source", "domain", result.DisplayLink)
+	skip := ra.skipList.Matcher(ctx)
+	internal := ra.searchSvc.Internal()
+	results := make([]models.SearchResult, 0, len(sources))
+	for i, result := range sources {
+		// Drop known low-yield domains
+		if reason := skip(result.URL); reason != "" {
+			ra.logger.Debug("skipping low-yield source", "domain", result.DisplayLink, "reason", reason)
 			continue
 		}
 
+		// The page's own date, when it was read, is more precise than the provider's
+		published := result.Published
+		if published.IsZero() {
+			published, _ = pagemeta.ParseDate(result.Date, time.Now())
+		}
+
+		// Keep the sources the operator's policy, and reputable_only when
+		// requested, accept
+		src := policy.NewSource(result.URL, published)
+		src.Internal = internal
+		if !ra.accepts(src, reputableOnly) {
+			continue
+		}
 		results = append(results, models.SearchResult{
-			URL:      result.URL,
-			Title:    result.Title,
-			Snippet:  result.Snippet,
-			Domain:   result.DisplayLink,
-			Position: i + 1,
+			URL:         result.URL,
+			Title:       result.Title,
+			Snippet:     result.Snippet,
+			Domain:      result.DisplayLink,
+			Position:    offset + i + 1,
+			Publisher:   result.Publisher,
+			PublishedAt: published,
+			Internal:    internal,
 		})
 	}
 
+	// Follow landing pages to the reports they link
+	results = ra.expandLandingPages(ctx, topic, results)
+
 	ra.logger.Info("sources found", "count", len(results))
-	return results, nil
+	return results, searchResp.NextOffset, nil
 }
 
-// isReputableSource checks if a domain is from a reputable source
-func isReputableSource(domain string) bool {
-	reputableDomains := []string{
-		".gov", ".edu", // Government and education
-		"who.int", "un.org", "worldbank.org", // International orgs
-		"pewresearch.org", "gallup.com", // Research organizations
-		"nature.com", "science.org", "nejm.org", // Journals
+// accepts reports whether a source passes the source policy and, for
+// reputable_only requests, the built-in reputable list. The list is of
+// public sites, so an organization's own documents all pass it.
+func (ra *ResearchAgent) accepts(src policy.Source, reputableOnly bool) bool {
+	for _, p := range []*policy.Policy{ra.cfg.SourcePolicy, reputable(reputableOnly)} {
+		ok, err := p.Allows(src)
+		if err != nil {
+			ra.logger.Warn("source policy failed", "url", src.URL, "error", err)
+		}
+		if !ok {
+			ra.logger.Debug("filtering source by policy", "domain", src.Domain, "policy", p.String())
+			return false
+		}
 	}
+	return true
+}
 
-	domainLower := strings.ToLower(domain)
-	for _, rep := range reputableDomains {
-		if strings.Contains(domainLower, rep) {
-			return true
-		}
+// reputable returns the built-in reputable policy when requested, or nil
+func reputable(reputableOnly bool) *policy.Policy {
+	if reputableOnly {
+		return policy.Reputable
 	}
-	return false
+	return nil
 }
 
 // Research finds sources for a given topic (returns URLs, not statistics)
@@ -128,12 +186,50 @@ func (ra *ResearchAgent) Research(ctx context.Context, req *models.ResearchReque
 		numResults = 20 // Default
 	}
 
+	query := req.Query
+	if query == "" {
+		query = req.Topic
+	}
+
+	// Learned demotions change from run to run, so a reproducible search
+	// skips only the configured domains
+	if req.Reproducible {
+		ctx = domainyield.WithoutDemotion(ctx)
+	}
+
 	// Find sources
-	searchResults, err := ra.findSources(ctx, req.Topic, numResults, req.ReputableOnly)
+	searchResults, nextOffset, err := ra.findSources(ctx, query, numResults, req.Offset, req.ReputableOnly)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find sources: %w", err)
 	}
 
+	// An obscure or over-specified topic may find nothing; broaden it step
+	// by step before reporting no sources
+	relaxed := ""
+	searchCalls := 1
+	if len(searchResults) == 0 && req.Offset == 0 && req.Query == "" {
+		for _, q := range search.Relax(req.Topic) {
+			ra.logger.Info("no sources found, relaxing query", "query", q)
+			searchCalls++
+			searchResults, nextOffset, err = ra.findSources(ctx, q, numResults, 0, req.ReputableOnly)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find sources: %w", err)
+			}
+			if len(searchResults) > 0 {
+				relaxed = q
+				break
+			}
+		}
+	}
+
+	// The first page also gets the sources the topic's Wikipedia article cites
+	if req.Offset == 0 && ra.wiki != nil {
+		if relaxed != "" {
+			query = relaxed
+		}
+		searchResults = ra.followWikipedia(ctx, query, searchResults, req.ReputableOnly)
+	}
+
 	// Note: We now return SearchResults, which will be analyzed by Synthesis Agent
 	// Convert to old format for backward compatibility (temporary)
 	candidates := make([]models.CandidateStatistic, 0, len(searchResults))
@@ -153,15 +249,32 @@ func (ra *ResearchAgent) Research(ctx context.Context, req *models.ResearchReque
 	}
 
 	response := &models.ResearchResponse{
-		Topic:      req.Topic,
-		Candidates: candidates,
-		Timestamp:  time.Now(),
+		Topic:       req.Topic,
+		Candidates:  candidates,
+		Timestamp:   time.Now(),
+		NextOffset:  nextOffset,
+		Query:       relaxed,
+		SearchCalls: searchCalls,
 	}
 
 	ra.logger.Info("research completed", "sources", len(searchResults))
 	return response, nil
 }
 
+// HandleQuota reports the request, rate-limit, and quota state of each
+// search provider
+func (ra *ResearchAgent) HandleQuota(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ra.searchSvc.Quotas(r.Context())); err != nil {
+		ra.logger.Error("failed to encode response", "error", err)
+	}
+}
+
 // HandleResearchRequest is the HTTP handler for research requests
 func (ra *ResearchAgent) HandleResearchRequest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -170,7 +283,7 @@ func (ra *ResearchAgent) HandleResearchRequest(w http.ResponseWriter, r *http.Re
 	}
 
 	var req models.ResearchRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := migrate.Decode(r.Body, &req); err != nil {
 		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -185,7 +298,12 @@ func (ra *ResearchAgent) HandleResearchRequest(w http.ResponseWriter, r *http.Re
 
 	resp, err := ra.Research(r.Context(), &req)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Research failed: %v", err), http.StatusInternalServerError)
+		// Tell callers the search provider, not the request, is at fault
+		status := http.StatusInternalServerError
+		if errors.Is(err, search.ErrQuotaExhausted) || errors.Is(err, search.ErrRateLimited) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, fmt.Sprintf("Research failed: %v", err), status)
 		return
 	}
 
@@ -208,7 +326,12 @@ func main() {
 	// Start A2A server if enabled (standard protocol for agent interoperability)
 	// Note: Research Agent is Tool-based, but wrapped in ADK for A2A compatibility
 	if cfg.A2AEnabled {
-		a2aServer, err := NewA2AServer(researchAgent, "9001", logger)
+		sessions, err := sessionstore.New(context.Background(), cfg)
+		if err != nil {
+			logger.Error("failed to create A2A session store", "error", err)
+			os.Exit(1)
+		}
+		a2aServer, err := NewA2AServer(researchAgent, cfg.A2AListenAddr(9001), cfg.A2AURL(9001), sessions, logger)
 		if err != nil {
 			logger.Error("failed to create A2A server", "error", err)
 		} else {
@@ -217,19 +340,25 @@ func main() {
 					logger.Error("A2A server error", "error", err)
 				}
 			}()
-			logger.Info("A2A server started", "port", 9001)
+			logger.Info("A2A server started", "addr", cfg.A2AListenAddr(9001))
 		}
 	}
 
+	// Swap in rotated search credentials on SIGHUP or config.json changes
+	go config.WatchReload(context.Background(), cfg, logger, func(_ context.Context, next *config.Config) error {
+		return researchAgent.searchSvc.Reload(next)
+	})
+
 	// Start HTTP server with timeout (for custom security: SPIFFE, KYA, XAA, and observability)
 	server := &http.Server{
-		Addr:         ":8001",
+		Addr:         cfg.ListenAddr(8001),
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
 	http.HandleFunc("/research", researchAgent.HandleResearchRequest)
+	http.HandleFunc("/search/quota", researchAgent.HandleQuota)
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("OK")); err != nil {
@@ -238,10 +367,10 @@ func main() {
 	})
 
 	logger.Info("HTTP server starting",
-		"port", 8001,
+		"addr", server.Addr,
 		"role", "search-based source discovery",
 		"mode", "dual (HTTP + A2A)")
-	if err := server.ListenAndServe(); err != nil {
+	if err := agentbase.Serve(context.Background(), server, logger); err != nil {
 		logger.Error("HTTP server failed", "error", err)
 		os.Exit(1)
 	}
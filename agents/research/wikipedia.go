@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/policy"
+)
+
+// followWikipedia puts the sources cited by the topic's Wikipedia article
+// ahead of the search results, and drops Wikipedia pages from the results
+// once their references are in, so synthesis reads the cited sources rather
+// than the encyclopedia. References are filtered like search results.
+func (ra *ResearchAgent) followWikipedia(ctx context.Context, topic string, results []models.SearchResult, reputableOnly bool) []models.SearchResult {
+	article, refs, err := ra.wiki.References(ctx, topic)
+	if err != nil {
+		ra.logger.Warn("failed to follow Wikipedia citations", "topic", topic, "error", err)
+		return results
+	}
+	if len(refs) == 0 {
+		return results
+	}
+
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		seen[normalizedURL(r.URL)] = true
+	}
+
+	skip := ra.skipList.Matcher(ctx)
+	cited := make([]models.SearchResult, 0, len(refs))
+	for _, ref := range refs {
+		u, err := url.Parse(ref.URL)
+		if err != nil || seen[normalizedURL(ref.URL)] || skip(ref.URL) != "" {
+			continue
+		}
+		domain := strings.TrimPrefix(u.Hostname(), "www.")
+		if !ra.accepts(policy.NewSource(ref.URL, time.Time{}), reputableOnly) {
+			continue
+		}
+		seen[normalizedURL(ref.URL)] = true
+		cited = append(cited, models.SearchResult{
+			URL:         ref.URL,
+			Title:       ref.Title,
+			Snippet:     ref.Statement,
+			Domain:      domain,
+			CrawledFrom: article,
+		})
+	}
+	if len(cited) == 0 {
+		return results
+	}
+	ra.logger.Info("following Wikipedia citations", "article", article, "references", len(cited))
+
+	for _, r := range results {
+		if u, err := url.Parse(r.URL); err != nil || !isWikipedia(u.Hostname()) {
+			cited = append(cited, r)
+		}
+	}
+	return cited
+}
+
+// isWikipedia reports whether host is a Wikipedia site
+func isWikipedia(host string) bool {
+	host = strings.ToLower(host)
+	return host == "wikipedia.org" || strings.HasSuffix(host, ".wikipedia.org")
+}
@@ -19,23 +19,22 @@ import (
 type A2AServer struct {
 	agent    *SynthesisAgent
 	listener net.Listener
+	sessions session.Service
 	baseURL  *url.URL
 	logger   *slog.Logger
 }
 
 // NewA2AServer creates a new A2A server for the synthesis agent
-func NewA2AServer(agent *SynthesisAgent, port string, logger *slog.Logger) (*A2AServer, error) {
-	addr := "0.0.0.0:" + port
+func NewA2AServer(agent *SynthesisAgent, addr string, baseURL *url.URL, sessions session.Service, logger *slog.Logger) (*A2AServer, error) {
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
 
-	baseURL := &url.URL{Scheme: "http", Host: listener.Addr().String()}
-
 	return &A2AServer{
 		agent:    agent,
 		listener: listener,
+		sessions: sessions,
 		baseURL:  baseURL,
 		logger:   logger,
 	}, nil
@@ -65,7 +64,7 @@ func (s *A2AServer) Start(context.Context) error {
 		RunnerConfig: runner.Config{
 			AppName:        s.agent.adkAgent.Name(),
 			Agent:          s.agent.adkAgent,
-			SessionService: session.InMemoryService(),
+			SessionService: s.sessions,
 		},
 	})
 
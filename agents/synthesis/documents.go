@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// uploadMemory is how much of an upload is held in memory; the rest is
+// spooled to temporary files, removed once the request is answered
+const uploadMemory = 32 << 20
+
+// HandleDocumentUpload extracts statistics from files posted as
+// multipart/form-data: a "topic" field and one or more "file" parts (PDF,
+// Word, PowerPoint, HTML, or a data file). Candidates name their file and page. With a
+// snapshot archive the files are kept there, so POST /verify can check the
+// candidates against them; otherwise they are discarded after extraction.
+func (sa *SynthesisAgent) HandleDocumentUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxSourcesBytes+1<<20)
+	if err := r.ParseMultipartForm(uploadMemory); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, fmt.Sprintf("Invalid upload: %v", err), status)
+		return
+	}
+	defer func() {
+		if err := r.MultipartForm.RemoveAll(); err != nil {
+			sa.Logger.Warn("failed to remove uploaded files", "error", err)
+		}
+	}()
+
+	topic := strings.TrimSpace(r.FormValue("topic"))
+	if topic == "" {
+		http.Error(w, "topic is required", http.StatusBadRequest)
+		return
+	}
+	maxStats := 0
+	if v := r.FormValue("max_statistics"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "max_statistics must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		maxStats = n
+	}
+	files := r.MultipartForm.File["file"]
+	switch {
+	case len(files) == 0:
+		http.Error(w, "at least one file part is required", http.StatusBadRequest)
+		return
+	case len(files) > models.MaxSources:
+		http.Error(w, fmt.Sprintf("at most %d files can be uploaded at once", models.MaxSources), http.StatusBadRequest)
+		return
+	}
+
+	results := make([]models.SearchResult, 0, len(files))
+	uploads := make(map[string]*agentbase.Document, len(files))
+	for _, fh := range files {
+		if fh.Size > models.MaxDocumentBytes {
+			http.Error(w, fmt.Sprintf("%s is larger than %d MB", fh.Filename, models.MaxDocumentBytes>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		contentType, ok := uploadType(fh)
+		if !ok {
+			http.Error(w, fmt.Sprintf("%s is not a PDF, Word, PowerPoint, HTML, CSV, JSON, or XLSX file", fh.Filename), http.StatusUnsupportedMediaType)
+			return
+		}
+		body, err := readUpload(fh)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to read %s: %v", fh.Filename, err), http.StatusBadRequest)
+			return
+		}
+
+		url := archive.DocumentURL(archive.Hash(body))
+		if _, ok := uploads[url]; ok {
+			continue // The same file twice
+		}
+		if sa.Documents != nil {
+			if _, err := sa.Documents.Put(r.Context(), archive.NewSnapshot(url, contentType, body)); err != nil {
+				http.Error(w, fmt.Sprintf("Failed to store %s: %v", fh.Filename, err), http.StatusInternalServerError)
+				return
+			}
+		}
+		uploads[url] = agentbase.NewDocument(url, contentType, body)
+		results = append(results, models.SearchResult{URL: url, Title: fh.Filename, Domain: fh.Filename})
+	}
+
+	// Every file is read, however many candidates the first ones hold
+	req := &models.SynthesisRequest{
+		Topic:         topic,
+		SearchResults: results,
+		MaxStatistics: maxStats,
+		StageLimits:   sa.Cfg.FillLimits(models.StageLimits{MaxPages: len(results)}),
+	}
+	sa.Logger.Info("extracting from uploaded documents", "files", len(results), "stored", sa.Documents != nil)
+	resp, err := sa.synthesize(r.Context(), req, uploads)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Synthesis failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		sa.Logger.Error("failed to encode response", "error", err)
+	}
+}
+
+// uploadType returns the media type an uploaded file is stored and read
+// as, from its declared type or its extension. Anything read as HTML must
+// say so, since extraction treats unknown content as a web page.
+func uploadType(fh *multipart.FileHeader) (string, bool) {
+	declared := fh.Header.Get("Content-Type")
+	format := extract.DetectFormat(declared, fh.Filename)
+	if format != extract.FormatHTML {
+		return format.MediaType(), true
+	}
+	mediaType, _, _ := mime.ParseMediaType(declared)
+	switch ext := strings.ToLower(path.Ext(fh.Filename)); {
+	case mediaType == "text/html", mediaType == "application/xhtml+xml":
+		return declared, true
+	case ext == ".html", ext == ".htm":
+		return format.MediaType(), true
+	}
+	return "", false
+}
+
+// readUpload reads an uploaded file from memory or its temporary file
+func readUpload(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, models.MaxDocumentBytes))
+}
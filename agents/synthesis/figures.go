@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/figures"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+// extractFromFigures reads statistics from the charts and infographics on a
+// page with the vision model. Their candidates are marked ocr_derived so
+// verification re-reads the figure instead of searching the page text.
+// Values the page text already states are left to text extraction.
+// Failures are logged and skip the figure.
+func (sa *SynthesisAgent) extractFromFigures(ctx context.Context, topic string, result models.SearchResult, doc *agentbase.Document, found []models.CandidateStatistic) []models.CandidateStatistic {
+	var candidates []models.CandidateStatistic
+	for _, fig := range figures.Find(doc.Body, doc.URL, sa.Cfg.FigureMaxImages) {
+		extractions, err := sa.readFigure(ctx, topic, result.URL, fig)
+		if err != nil {
+			sa.Logger.Debug("failed to read figure", "url", result.URL, "image", fig.URL, "error", err)
+			continue
+		}
+
+		for _, ext := range extractions {
+			inText := func(c models.CandidateStatistic) bool {
+				return c.Value == float32(ext.Value.Value) && strings.EqualFold(c.Unit, ext.Unit)
+			}
+			if slices.ContainsFunc(found, inText) || slices.ContainsFunc(candidates, inText) {
+				continue
+			}
+
+			statType, _ := models.ParseStatisticType(ext.Type)
+			candidates = append(candidates, models.CandidateStatistic{
+				Name:       ext.Name,
+				Value:      float32(ext.Value.Value),
+				Unit:       ext.Unit,
+				Source:     result.Domain,
+				SourceURL:  result.URL,
+				Excerpt:    ext.Excerpt,
+				Type:       statType,
+				OCRDerived: true,
+				ImageURL:   fig.URL,
+			})
+		}
+	}
+
+	if len(candidates) > 0 {
+		sa.Logger.Debug("extracted statistics from figures", "url", result.URL, "candidates", len(candidates))
+	}
+	return candidates
+}
+
+// readFigure fetches a figure and asks the vision model for its statistics
+func (sa *SynthesisAgent) readFigure(ctx context.Context, topic, pageURL string, fig figures.Figure) ([]statExtraction, error) {
+	img, err := sa.FetchDocument(ctx, fig.URL, figures.MaxBytes>>20+1)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, err := sa.Prompts.Render(prompts.SynthesisFigure, prompts.FigureData{
+		Topic:   topic,
+		URL:     pageURL,
+		Alt:     fig.Alt,
+		Caption: fig.Caption,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response, err := figures.Read(ctx, sa.vision, prompt, img.ContentType, img.Body)
+	if err != nil {
+		return nil, err
+	}
+	return sa.parseWithRepair(ctx, response)
+}
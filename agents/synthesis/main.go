@@ -6,10 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
-	"os/signal"
+	"path"
+	"slices"
 	"strings"
-	"syscall"
 	"time"
 
 	"google.golang.org/adk/agent"
@@ -17,18 +18,35 @@ import (
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
-	"google.golang.org/genai"
 
+	"github.com/plexusone/agent-team-stats/pkg/adapters"
 	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/archive"
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
+	"github.com/plexusone/agent-team-stats/pkg/methodology"
 	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/pagemeta"
+	"github.com/plexusone/agent-team-stats/pkg/prioritize"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/sessionstore"
+	"github.com/plexusone/agent-team-stats/pkg/timing"
+	"github.com/plexusone/agent-team-stats/pkg/tokens"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
 )
 
 // SynthesisAgent extracts statistics from webpage content using LLM
 type SynthesisAgent struct {
 	*agentbase.BaseAgent
 	adkAgent agent.Agent
+	adapters *adapters.Registry // Nil when EXTRACTION_ADAPTERS is off
+	vision   model.LLM          // Reads figures; nil when FIGURE_EXTRACTION is off
+	profile  prompts.Profile    // Extraction prompt profile, resolved per model
+	archive  archive.Store      // Shares fetched pages with verification; nil without an archive or CONTENT_SHARING
 }
 
 // SynthesisInput defines input for synthesis tool
@@ -49,15 +67,57 @@ func NewSynthesisAgent(cfg *config.Config, logger *slog.Logger) (*SynthesisAgent
 	ctx := logging.WithLogger(context.Background(), logger)
 
 	// Create base agent with LLM
-	base, err := agentbase.NewBaseAgent(ctx, cfg, 45)
+	base, err := agentbase.NewStageAgent(ctx, cfg, llm.StageSynthesis, 45)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create base agent: %w", err)
 	}
 
 	logger.Info("agent initialized", "provider", base.GetProviderInfo())
 
+	budget, err := base.ModelFactory.Budget(llm.StageSynthesis, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt budget: %w", err)
+	}
+	logger.Info("prompt budget",
+		"context_window", budget.Window,
+		"output_tokens", budget.Output,
+		"page_tokens", cfg.SynthesisPageTokens)
+
+	profile, err := prompts.ParseProfile(cfg.SynthesisPromptProfile)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("extraction prompt",
+		"setting", profile,
+		"profile", profile.Resolve(llm.SmallModel(budget.Provider, budget.Model)))
+
+	// The archive serves the documents sent with requests, and with content
+	// sharing also keeps the pages read for verification
+	store, err := archive.New(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot archive: %w", err)
+	}
+	base.Documents = store
+
 	sa := &SynthesisAgent{
 		BaseAgent: base,
+		profile:   profile,
+	}
+	if cfg.ContentSharing && store != nil {
+		sa.archive = store
+		logger.Info("content sharing enabled", "backend", cfg.ArchiveBackend)
+	}
+	if cfg.ExtractionAdapters {
+		sa.adapters = adapters.Default()
+		logger.Info("extraction adapters enabled", "adapters", sa.adapters.Names())
+	}
+	if cfg.FigureExtraction {
+		// Pages are still read without figures when no vision model is available
+		if sa.vision, err = base.ModelFactory.CreateVisionModel(ctx); err != nil {
+			logger.Warn("figure extraction disabled", "error", err)
+		} else {
+			logger.Info("figure extraction enabled", "provider", base.ModelFactory.GetStageInfo(llm.StageVision), "max_images", cfg.FigureMaxImages)
+		}
 	}
 
 	// Create synthesis tool
@@ -70,31 +130,16 @@ func NewSynthesisAgent(cfg *config.Config, logger *slog.Logger) (*SynthesisAgent
 	}
 
 	// Create ADK agent
+	instruction, err := base.Prompts.Render(prompts.SynthesisSystem, nil)
+	if err != nil {
+		return nil, err
+	}
 	adkAgent, err := llmagent.New(llmagent.Config{
 		Name:        "statistics_synthesis_agent",
 		Model:       base.Model,
 		Description: "Extracts statistics from web content",
-		Instruction: `You are a statistics synthesis agent. Your job is to:
-1. Fetch content from provided URLs
-2. Analyze the content to find numerical statistics
-3. Extract exact values, units, and context
-4. Create verbatim excerpts containing the statistics
-5. Identify the source credibility
-
-When extracting statistics:
-- Look for numerical values with context (percentages, measurements, counts)
-- Extract the exact excerpt containing the statistic (word-for-word)
-- Identify the unit of measurement
-- Verify the source is reputable (academic, government, research)
-- Only extract statistics that are clearly stated with numbers
-
-Reputable sources include:
-- Government agencies (.gov domains)
-- Academic institutions (.edu domains)
-- Research organizations (Pew, Gallup, etc.)
-- International organizations (WHO, UN, World Bank, etc.)
-- Peer-reviewed journals`,
-		Tools: []tool.Tool{synthesisTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{synthesisTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ADK agent: %w", err)
@@ -111,23 +156,16 @@ func (sa *SynthesisAgent) synthesisToolHandler(ctx tool.Context, input Synthesis
 
 	candidates := make([]models.CandidateStatistic, 0)
 
-	// Analyze each search result
-	for i, result := range input.SearchResults {
+	// Analyze each search result, most promising first
+	for i, result := range sa.prioritize(input.SearchResults) {
 		if len(candidates) >= input.MaxStatistics && input.MaxStatistics > 0 {
 			break
 		}
 
 		sa.Logger.Debug("fetching content", "url", result.URL)
 
-		// Fetch webpage content using base agent method
-		content, err := sa.FetchURL(context.Background(), result.URL, 1)
-		if err != nil {
-			sa.Logger.Warn("failed to fetch URL", "url", result.URL, "error", err)
-			continue
-		}
-
-		// Extract statistics from content using LLM
-		stats, err := sa.extractStatisticsWithLLM(context.Background(), input.Topic, result, content)
+		// Fetch and extract statistics (structured parsing or LLM)
+		stats, err := sa.extractFromSource(context.Background(), input.Topic, result, input.MaxStatistics)
 		if err != nil {
 			sa.Logger.Warn("failed to extract statistics", "url", result.URL, "error", err)
 			continue
@@ -153,162 +191,447 @@ func (sa *SynthesisAgent) synthesisToolHandler(ctx tool.Context, input Synthesis
 	}, nil
 }
 
-// extractStatisticsWithLLM uses LLM to intelligently extract statistics from content
-func (sa *SynthesisAgent) extractStatisticsWithLLM(ctx context.Context, topic string, result models.SearchResult, content string) ([]models.CandidateStatistic, error) {
-	// Truncate content if too long (LLMs have token limits)
-	maxContentLen := 30000 // ~8000 tokens - increased from 15000 to capture more statistics
-	if len(content) > maxContentLen {
-		content = content[:maxContentLen]
-	}
-
-	// Create prompt for LLM to extract statistics
-	prompt := fmt.Sprintf(`Analyze the following webpage content and extract ALL numerical statistics related to "%s".
-
-IMPORTANT RULES:
-1. Extract EVERY statistic you find, not just one or two. Be thorough and comprehensive.
-2. The "value" field MUST be the exact number that appears in the excerpt - do not approximate or round
-3. The "excerpt" MUST be a verbatim quote containing the exact number you put in "value"
-4. If the excerpt says "1.5°C", the value must be 1.5, not 1
-5. If you cannot find an exact number in the text, skip that statistic
-
-For each statistic found, provide:
-1. name: A brief descriptive name
-2. value: The EXACT numerical value from the text (as a number, not string)
-3. unit: The unit of measurement (percent, million, billion, degrees Celsius, people, countries, etc.)
-4. excerpt: The verbatim excerpt from the text containing this EXACT statistic (50-200 characters)
-
-Return valid JSON array with this structure:
-[
-  {
-    "name": "Global temperature rise",
-    "value": 1.5,
-    "unit": "degrees Celsius",
-    "excerpt": "limiting global warming to 1.5°C above pre-industrial levels"
-  },
-  {
-    "name": "Survey respondents",
-    "value": 75000,
-    "unit": "people",
-    "excerpt": "Over 75,000 people across 77 countries participated"
-  }
-]
-
-CRITICAL: The value field must match the number in the excerpt exactly. Do not invent numbers.
-
-Extract ALL statistics with clear numerical values. If the page contains 10 statistics, return 10 items in the array.
-Return empty array [] ONLY if absolutely no statistics are found.
-
-Webpage URL: %s
-Domain: %s
-
-Content:
-%s
-
-JSON output with ALL statistics:`, topic, result.URL, result.Domain, content)
-
-	// Call LLM to extract statistics using ADK
-	llmReq := &model.LLMRequest{
-		Contents: genai.Text(prompt),
-	}
-
-	var response string
-	for llmResp, err := range sa.Model.GenerateContent(ctx, llmReq, false) {
-		if err != nil {
-			return nil, fmt.Errorf("LLM generation failed: %w", err)
+// prioritize returns a copy of results ordered by expected statistics yield,
+// so pages likely to hold recent figures from authoritative sources are
+// fetched before the candidate budget runs out
+func (sa *SynthesisAgent) prioritize(results []models.SearchResult) []models.SearchResult {
+	ordered := slices.Clone(results)
+	prioritize.Sort(ordered, time.Now())
+	for i, r := range ordered {
+		sa.Logger.Debug("prioritized source", "rank", i+1, "position", r.Position, "url", r.URL)
+	}
+	return ordered
+}
+
+// extractFromSource fetches a search result and extracts candidate statistics.
+// Data files (CSV, JSON, XLSX) are parsed structurally with row/column provenance;
+// the text of PDF, Word, and PowerPoint documents and everything else is sent
+// to the LLM.
+func (sa *SynthesisAgent) extractFromSource(ctx context.Context, topic string, result models.SearchResult, maxStats int) ([]models.CandidateStatistic, error) {
+	// Only results of internal search are fetched with the site credentials
+	if result.Internal {
+		ctx = httpclient.WithCredentials(ctx)
+	}
+
+	// Known sites are read by their adapter; pages without the expected
+	// structure fall through to generic extraction
+	if a := sa.adapters.Lookup(result.URL); a != nil {
+		candidates, err := a.Extract(ctx, adapters.Request{
+			Topic:  topic,
+			Result: result,
+			Limit:  maxStats,
+			Fetch:  sa.fetch,
+		})
+		switch {
+		case err != nil:
+			sa.Logger.Warn("extraction adapter failed", "adapter", a.Name(), "url", result.URL, "error", err)
+		case len(candidates) > 0:
+			sa.Logger.Debug("extracted with adapter", "adapter", a.Name(), "url", result.URL, "candidates", len(candidates))
+			return withPublication(candidates, result, pagemeta.Metadata{}), nil
+		default:
+			sa.Logger.Debug("adapter found no data", "adapter", a.Name(), "url", result.URL)
 		}
-		// Extract text from response
-		if llmResp.Content != nil && llmResp.Content.Parts != nil {
-			for _, part := range llmResp.Content.Parts {
-				if part.Text != "" {
-					response += part.Text
-				}
-			}
+	}
+
+	doc, err := sa.FetchDocument(ctx, result.URL, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	return sa.extractFromDocument(ctx, topic, result, doc, maxStats)
+}
+
+// extractFromDocument extracts candidate statistics from fetched or uploaded
+// content. Statistics read from a document file are located by its name and,
+// for a PDF, Word, or PowerPoint document, the page showing their excerpt.
+func (sa *SynthesisAgent) extractFromDocument(ctx context.Context, topic string, result models.SearchResult, doc *agentbase.Document, maxStats int) ([]models.CandidateStatistic, error) {
+	format := extract.DetectFormat(doc.ContentType, result.URL)
+	var candidates []models.CandidateStatistic
+	var pages []string
+	var err error
+	switch {
+	case format.IsDocument():
+		pages, candidates, err = sa.extractFromPages(ctx, topic, result, doc, format)
+	case format.IsStructured():
+		candidates, err = sa.extractFromData(topic, result, doc, format, maxStats)
+	default:
+		candidates, err = sa.extractFromPage(ctx, topic, result, doc)
+	}
+
+	if name, ok := documentName(result, format); ok {
+		for i := range candidates {
+			candidates[i].Document = &models.DocumentPage{Filename: name, Page: extract.PageOf(pages, candidates[i].Excerpt)}
 		}
 	}
+	sa.shareContent(ctx, doc, candidates)
+	return withPublication(candidates, result, doc.Metadata), err
+}
+
+// extractFromPage sends a web page to the LLM
+func (sa *SynthesisAgent) extractFromPage(ctx context.Context, topic string, result models.SearchResult, doc *agentbase.Document) ([]models.CandidateStatistic, error) {
+	// Convert <table> elements into structured rows so values in
+	// comparison tables survive alongside the flattened page text
+	tables, err := extract.Parse(extract.FormatHTML, doc.Body)
+	if err != nil {
+		sa.Logger.Debug("failed to parse HTML tables", "url", result.URL, "error", err)
+	}
+	candidates, err := sa.extractStatisticsWithLLM(ctx, topic, result, string(doc.Body), tables)
+	if err == nil && sa.vision != nil {
+		candidates = append(candidates, sa.extractFromFigures(ctx, topic, result, doc, candidates)...)
+	}
+	return sa.checkValues(result.URL, candidates), err
+}
+
+// extractFromPages sends the text of a PDF, Word, or PowerPoint document's
+// pages to the LLM, with the rows of its tables, returning the pages with the
+// candidates
+func (sa *SynthesisAgent) extractFromPages(ctx context.Context, topic string, result models.SearchResult, doc *agentbase.Document, format extract.Format) ([]string, []models.CandidateStatistic, error) {
+	read, err := extract.ReadDocument(format, doc.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	candidates, err := sa.extractStatisticsWithLLM(ctx, topic, result, strings.Join(read.Pages, "\n\n"), read.Tables)
+	sa.Logger.Debug("read document", "url", result.URL, "format", format, "pages", len(read.Pages), "tables", len(read.Tables), "candidates", len(candidates))
+	return read.Pages, sa.checkValues(result.URL, candidates), err
+}
+
+// extractFromData parses a data file, with no LLM
+func (sa *SynthesisAgent) extractFromData(topic string, result models.SearchResult, doc *agentbase.Document, format extract.Format, maxStats int) ([]models.CandidateStatistic, error) {
+	tables, err := extract.Parse(format, doc.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s data: %w", format, err)
+	}
+
+	var candidates []models.CandidateStatistic
+	for _, table := range tables {
+		limit := extract.DefaultMaxCandidates - len(candidates)
+		if maxStats > 0 {
+			limit = maxStats - len(candidates)
+		}
+		if limit <= 0 {
+			break
+		}
+		candidates = append(candidates, table.Candidates(topic, result, limit)...)
+	}
+
+	sa.Logger.Debug("parsed data file",
+		"url", result.URL,
+		"format", format,
+		"tables", len(tables),
+		"candidates", len(candidates))
+	return candidates, nil
+}
+
+// documentName returns the filename of a document file: the name it was
+// sent with, which its search result carries as its domain, or the last
+// segment of its URL for a PDF, Word, or PowerPoint document on the web
+func documentName(result models.SearchResult, format extract.Format) (string, bool) {
+	if _, ok := archive.DocumentHash(result.URL); ok {
+		return result.Domain, true
+	}
+	if !format.IsDocument() {
+		return "", false
+	}
+	u, err := url.Parse(result.URL)
+	if err != nil {
+		return "", false
+	}
+	return path.Base(u.Path), true
+}
+
+// withPublication sets the publisher and publication date of candidates from
+// what their page declares, falling back to the search result's date, and
+// marks those of an internal search result
+func withPublication(candidates []models.CandidateStatistic, result models.SearchResult, meta pagemeta.Metadata) []models.CandidateStatistic {
+	publisher, published := meta.Publisher, meta.Published
+	if publisher == "" {
+		publisher = result.Publisher
+	}
+	if published.IsZero() {
+		published = result.PublishedAt
+	}
+	for i := range candidates {
+		candidates[i].Publisher = publisher
+		candidates[i].PublishedAt = published
+		candidates[i].Internal = result.Internal
+	}
+	return candidates
+}
+
+// fetch retrieves a URL for an extraction adapter
+func (sa *SynthesisAgent) fetch(ctx context.Context, url string) (string, []byte, error) {
+	doc, err := sa.FetchDocument(ctx, url, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	return doc.ContentType, doc.Body, nil
+}
+
+// promptBudget returns the prompt budget of the model extracting for ctx:
+// the run's, or the stage model's for calls made by the ADK agent
+func (sa *SynthesisAgent) promptBudget(ctx context.Context) (tokens.Budget, error) {
+	if b, ok := llm.BudgetFrom(ctx); ok {
+		return b, nil
+	}
+	return sa.ModelFactory.Budget(llm.StageSynthesis, nil, nil)
+}
 
-	// Parse JSON response
-	type StatExtraction struct {
-		Name    string  `json:"name"`
-		Value   float32 `json:"value"`
-		Unit    string  `json:"unit"`
-		Excerpt string  `json:"excerpt"`
+// extractStatisticsWithLLM uses LLM to intelligently extract statistics from content
+func (sa *SynthesisAgent) extractStatisticsWithLLM(ctx context.Context, topic string, result models.SearchResult, content string, tables []*extract.Table) ([]models.CandidateStatistic, error) {
+	// Methodology hints are checked against the whole page, not just the
+	// part the LLM saw
+	pageText := extract.PageText([]byte(content))
+
+	budget, err := sa.promptBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data := prompts.ExtractData{
+		Topic:  topic,
+		URL:    result.URL,
+		Domain: result.Domain,
+	}
+	var pagePrompts []string
+	if sa.profile.Resolve(llm.SmallModel(budget.Provider, budget.Model)) == prompts.ProfileCompact {
+		pagePrompts, err = sa.compactPrompts(budget, data, pageText)
+	} else {
+		pagePrompts, err = sa.fullPrompt(budget, data, content, tables)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	var extractions []StatExtraction
-	if err := json.Unmarshal([]byte(response), &extractions); err != nil {
-		// LLM might wrap JSON in markdown code blocks
-		response = extractJSONFromMarkdown(response)
-		if err := json.Unmarshal([]byte(response), &extractions); err != nil {
-			return nil, fmt.Errorf("failed to parse LLM response as JSON: %w (response: %s)", err, response)
+	// Call LLM to extract statistics, re-prompting with the parse error if
+	// the output is not valid JSON. A chunk that fails costs only its own
+	// statistics.
+	var extractions []statExtraction
+	var extractErr error
+	for i, prompt := range pagePrompts {
+		response, err := sa.generate(ctx, prompt)
+		if err == nil {
+			var found []statExtraction
+			found, err = sa.parseWithRepair(ctx, response)
+			extractions = append(extractions, found...)
+		}
+		if err != nil {
+			extractErr = err
+			if len(pagePrompts) > 1 {
+				sa.Logger.Warn("failed to extract statistics from page chunk",
+					"url", result.URL, "chunk", i+1, "chunks", len(pagePrompts), "error", err)
+			}
 		}
 	}
+	if len(extractions) == 0 && extractErr != nil {
+		return nil, extractErr
+	}
 
 	// Convert to CandidateStatistic
 	candidates := make([]models.CandidateStatistic, 0, len(extractions))
 	for _, ext := range extractions {
-		if ext.Value == 0 || ext.Excerpt == "" {
-			continue // Skip invalid entries
-		}
+		// An unrecognized type is left unclassified
+		statType, _ := models.ParseStatisticType(ext.Type)
 
 		candidates = append(candidates, models.CandidateStatistic{
-			Name:      ext.Name,
-			Value:     ext.Value,
-			Unit:      ext.Unit,
-			Source:    result.Domain,
-			SourceURL: result.URL,
-			Excerpt:   ext.Excerpt,
+			Name:       ext.Name,
+			Value:      float32(ext.Value.Value),
+			Unit:       ext.Unit,
+			Source:     result.Domain,
+			SourceURL:  result.URL,
+			Excerpt:    ext.Excerpt,
+			Provenance: tableProvenance(tables, ext.Table, int(ext.Row), ext.Column, float32(ext.Value.Value)),
+			Type:       statType,
+
+			Methodology: methodology.Resolve(pageText, ext.Excerpt, ext.Methodology.model()),
 		})
 	}
 
 	return candidates, nil
 }
 
-// extractJSONFromMarkdown removes markdown code fences and extra text from LLM response
-func extractJSONFromMarkdown(response string) string {
-	response = strings.TrimSpace(response)
+// fullPrompt renders the full extraction prompt for a page. Tables take up
+// to a third of the room the model's context window leaves, and the page
+// the rest, up to SYNTHESIS_PAGE_TOKENS.
+func (sa *SynthesisAgent) fullPrompt(budget tokens.Budget, data prompts.ExtractData, content string, tables []*extract.Table) ([]string, error) {
+	prompt, err := sa.Prompts.Render(prompts.SynthesisExtract, data)
+	if err != nil {
+		return nil, err
+	}
+	room := budget.Room(prompt)
+	if room == 0 {
+		return nil, errNoRoom(budget)
+	}
+	data.Tables = renderTables(tables, budget.Provider, min(maxTableSectionTokens, room/3))
+	room -= tokens.Count(budget.Provider, data.Tables)
+	if sa.Cfg.SynthesisPageTokens > 0 {
+		room = min(room, sa.Cfg.SynthesisPageTokens)
+	}
+	data.Content = tokens.Trim(budget.Provider, content, room)
+	if len(data.Content) < len(content) {
+		sa.Logger.Debug("trimmed page to prompt budget",
+			"url", data.URL,
+			"tokens", room,
+			"kept_bytes", len(data.Content),
+			"page_bytes", len(content))
+	}
+
+	prompt, err = sa.Prompts.Render(prompts.SynthesisExtract, data)
+	if err != nil {
+		return nil, err
+	}
+	return []string{prompt}, nil
+}
+
+// compactPrompts renders the compact extraction prompt for each chunk of a
+// page's visible text, for models that lose track of long pages and
+// detailed instructions
+func (sa *SynthesisAgent) compactPrompts(budget tokens.Budget, data prompts.ExtractData, text string) ([]string, error) {
+	prompt, err := sa.Prompts.Render(prompts.SynthesisExtractCompact, data)
+	if err != nil {
+		return nil, err
+	}
+	room := budget.Room(prompt)
+	if room == 0 {
+		return nil, errNoRoom(budget)
+	}
+	size := min(room, prompts.CompactChunkTokens)
+	total := size * prompts.CompactMaxChunks
+	if sa.Cfg.SynthesisPageTokens > 0 {
+		total = min(total, sa.Cfg.SynthesisPageTokens)
+	}
 
-	// Try to find JSON array in the response
-	startIdx := strings.Index(response, "[")
-	if startIdx == -1 {
-		return response // No array found, return as-is
+	chunks := tokens.Split(budget.Provider, tokens.Trim(budget.Provider, text, total), size)
+	pagePrompts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		data.Content = chunk
+		prompt, err := sa.Prompts.Render(prompts.SynthesisExtractCompact, data)
+		if err != nil {
+			return nil, err
+		}
+		pagePrompts = append(pagePrompts, prompt)
 	}
+	return pagePrompts, nil
+}
+
+// errNoRoom reports an extraction prompt that leaves no room for the page
+func errNoRoom(budget tokens.Budget) error {
+	return fmt.Errorf("extraction prompt does not fit the %d-token context window of %s:%s", budget.Window, budget.Provider, budget.Model)
+}
+
+// maxTableSectionTokens bounds the structured table rows included in the prompt
+const maxTableSectionTokens = 3000
 
-	// Find the matching closing bracket
-	endIdx := strings.LastIndex(response, "]")
-	if endIdx == -1 || endIdx < startIdx {
-		return response // No valid closing bracket
+// renderTables formats parsed HTML or document tables as a prompt section of at most
+// limit tokens, dropping the tables that do not fit
+func renderTables(tables []*extract.Table, provider string, limit int) string {
+	if len(tables) == 0 {
+		return ""
 	}
 
-	// Extract just the JSON array
-	jsonStr := response[startIdx : endIdx+1]
-	return strings.TrimSpace(jsonStr)
+	var b strings.Builder
+	b.WriteString(`
+Tables (structured rows converted from the tables of the source):
+For statistics taken from these tables, also set "table" (its bracketed label, e.g. "table 2"), "row" (the row number),
+and "column" (the exact column header), and use the row's cell text as the excerpt.
+
+`)
+	header := b.Len()
+	used := tokens.Count(provider, b.String())
+	for _, t := range tables {
+		rendered := t.Render() + "\n"
+		n := tokens.Count(provider, rendered)
+		if used+n > limit {
+			break
+		}
+		b.WriteString(rendered)
+		used += n
+	}
+	if b.Len() == header {
+		return "" // No table fits
+	}
+	return b.String()
+}
+
+// tableProvenance returns the provenance for an LLM-reported table cell, or nil
+// if the reference does not resolve to a cell holding the extracted value.
+func tableProvenance(tables []*extract.Table, table string, row int, column string, value float32) *models.Provenance {
+	if table == "" || row == 0 || column == "" {
+		return nil
+	}
+	prov := &models.Provenance{Sheet: table, Row: row, Column: column}
+	for _, t := range tables {
+		if t.Sheet == table {
+			prov.Format = string(t.Format) // HTML, or a Word or PowerPoint document
+			break
+		}
+	}
+	cell, ok := extract.Lookup(tables, prov)
+	if !ok {
+		return nil
+	}
+	if v, ok := extract.ParseNumber(cell); !ok || float32(v) != value {
+		return nil
+	}
+	return prov
 }
 
 // Synthesize processes a synthesis request directly
-func (sa *SynthesisAgent) Synthesize(ctx context.Context, req *models.SynthesisRequest) (*models.SynthesisResponse, error) { // nolint:unparam // error return kept for future usage
+func (sa *SynthesisAgent) Synthesize(ctx context.Context, req *models.SynthesisRequest) (*models.SynthesisResponse, error) {
+	return sa.synthesize(ctx, req, nil)
+}
+
+// synthesize processes a synthesis request, reading the search results in
+// uploads from there rather than fetching them
+func (sa *SynthesisAgent) synthesize(ctx context.Context, req *models.SynthesisRequest, uploads map[string]*agentbase.Document) (*models.SynthesisResponse, error) {
 	sa.Logger.Info("processing search results", "count", len(req.SearchResults), "topic", req.Topic)
 
+	llmModel, err := sa.ModelFactory.ModelFor(ctx, req.Model, sa.Model)
+	if err != nil {
+		return nil, err
+	}
+	ctx = llm.WithModel(ctx, llmModel)
+	ctx = llm.WithGeneration(ctx, req.Generation)
+	ctx = llm.WithSampling(ctx, req.Sampling)
+	budget, err := sa.ModelFactory.Budget(llm.StageSynthesis, req.Model, req.Generation)
+	if err != nil {
+		return nil, err
+	}
+	ctx = llm.WithBudget(ctx, budget)
+	tracker := usage.NewTracker(string(llm.StageSynthesis))
+	ctx = usage.WithTracker(ctx, tracker)
+	timer := timing.New()
+	ctx = timing.WithRecorder(ctx, timer)
+
 	var candidates []models.CandidateStatistic
 	pagesProcessed := 0
-	minPagesToProcess := 15 // Process at least 15 pages for comprehensive coverage (increased from 5)
+	perPage := req.CandidatesPerPageCap
+	if perPage <= 0 {
+		perPage = extract.DefaultMaxCandidates
+	}
 
-	// Analyze each search result
-	for _, result := range req.SearchResults {
-		// Stop only if we have enough candidates AND processed minimum pages
-		if len(candidates) >= req.MaxStatistics && req.MaxStatistics > 0 && pagesProcessed >= minPagesToProcess {
+	// Analyze each search result, most promising first, until the page or
+	// candidate budget is spent
+	for _, result := range sa.prioritize(req.SearchResults) {
+		if req.MaxStatistics > 0 && len(candidates) >= req.MaxStatistics {
 			sa.Logger.Info("reached max statistics", "max", req.MaxStatistics, "pages", pagesProcessed)
 			break
 		}
+		if req.MaxPages > 0 && pagesProcessed >= req.MaxPages {
+			sa.Logger.Info("reached max pages", "max", req.MaxPages, "candidates", len(candidates))
+			break
+		}
 
-		// Fetch webpage content using base agent
-		content, err := sa.FetchURL(ctx, result.URL, 1)
-		if err != nil {
-			sa.Logger.Warn("failed to fetch URL", "url", result.URL, "error", err)
-			continue
+		limit := perPage
+		if req.MaxStatistics > 0 {
+			limit = min(limit, req.MaxStatistics-len(candidates))
 		}
 
-		// Extract statistics using LLM
-		stats, err := sa.extractStatisticsWithLLM(ctx, req.Topic, result, content)
+		// Fetch and extract statistics (structured parsing or LLM)
+		var stats []models.CandidateStatistic
+		var err error
+		if doc, ok := uploads[result.URL]; ok {
+			stats, err = sa.extractFromDocument(ctx, req.Topic, result, doc, limit)
+		} else {
+			stats, err = sa.extractFromSource(ctx, req.Topic, result, limit)
+		}
 		if err != nil {
 			sa.Logger.Warn("failed to extract statistics", "url", result.URL, "error", err)
 			continue
@@ -316,6 +639,11 @@ func (sa *SynthesisAgent) Synthesize(ctx context.Context, req *models.SynthesisR
 
 		pagesProcessed++
 
+		// LLM extraction does not take a limit, so cap its output here
+		if len(stats) > limit {
+			stats = stats[:limit]
+		}
+
 		if len(stats) > 0 {
 			candidates = append(candidates, stats...)
 			sa.Logger.Info("extracted statistics",
@@ -329,22 +657,18 @@ func (sa *SynthesisAgent) Synthesize(ctx context.Context, req *models.SynthesisR
 				"total", len(candidates),
 				"pages", pagesProcessed)
 		}
-
-		// Only stop early if we have well exceeded the minimum requirement
-		// Use 5x multiplier to account for verification failures (increased from 2x)
-		if len(candidates) >= req.MinStatistics*5 && pagesProcessed >= minPagesToProcess {
-			sa.Logger.Info("exceeded minimum threshold",
-				"candidates", len(candidates),
-				"pages", pagesProcessed)
-			break
-		}
 	}
 
+	// Time not spent fetching pages went to extraction
+	timer.Rest(timing.Extraction)
+
 	response := &models.SynthesisResponse{
 		Topic:           req.Topic,
 		Candidates:      candidates,
 		SourcesAnalyzed: min(len(req.SearchResults), len(candidates)/2+1),
 		Timestamp:       time.Now(),
+		Usage:           tracker.Summary(),
+		Timings:         timer.Timings(),
 	}
 
 	sa.Logger.Info("synthesis completed",
@@ -362,17 +686,27 @@ func (sa *SynthesisAgent) HandleSynthesisRequest(w http.ResponseWriter, r *http.
 	}
 
 	var req models.SynthesisRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := migrate.Decode(r.Body, &req); err != nil {
 		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	// Set defaults
+	if err := llm.ValidateOverride(sa.Cfg, req.Model); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := req.StageLimits.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Set defaults; the candidate budget allows for verification failures
+	req.StageLimits = sa.Cfg.FillLimits(req.StageLimits)
 	if req.MinStatistics == 0 {
 		req.MinStatistics = 5
 	}
 	if req.MaxStatistics == 0 {
-		req.MaxStatistics = 20
+		req.MaxStatistics = req.Budget(req.MinStatistics)
 	}
 
 	resp, err := sa.Synthesize(r.Context(), &req)
@@ -399,7 +733,12 @@ func main() {
 
 	// Start A2A server if enabled
 	if cfg.A2AEnabled {
-		a2aServer, err := NewA2AServer(synthesisAgent, "9004", logger)
+		sessions, err := sessionstore.New(context.Background(), cfg)
+		if err != nil {
+			logger.Error("failed to create A2A session store", "error", err)
+			os.Exit(1)
+		}
+		a2aServer, err := NewA2AServer(synthesisAgent, cfg.A2AListenAddr(9004), cfg.A2AURL(9004), sessions, logger)
 		if err != nil {
 			logger.Error("failed to create A2A server", "error", err)
 		} else {
@@ -408,20 +747,22 @@ func main() {
 					logger.Error("A2A server error", "error", err)
 				}
 			}()
-			logger.Info("A2A server started", "port", 9004)
+			logger.Info("A2A server started", "addr", cfg.A2AListenAddr(9004))
 		}
 	}
 
 	// Start HTTP server with timeout (backward compatible)
 	timeout := time.Duration(cfg.HTTPTimeoutSeconds) * time.Second
 	server := &http.Server{
-		Addr:         ":8004",
+		Addr:         cfg.ListenAddr(8004),
 		ReadTimeout:  timeout,
 		WriteTimeout: timeout,
 		IdleTimeout:  timeout * 2,
 	}
 
 	http.HandleFunc("/synthesize", synthesisAgent.HandleSynthesisRequest)
+	http.HandleFunc("/documents", synthesisAgent.HandleDocumentUpload)
+	http.HandleFunc("/fetch-failures", synthesisAgent.HandleFetchFailures)
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("OK")); err != nil {
@@ -429,34 +770,17 @@ func main() {
 		}
 	})
 
-	// Setup graceful shutdown
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-
-	go func() {
-		logger.Info("HTTP server starting",
-			"port", 8004,
-			"mode", "ADK-based LLM extraction")
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("HTTP server failed", "error", err)
-		}
-	}()
-
-	<-stop
-	logger.Info("shutting down gracefully...")
+	// Swap in rotated credentials on SIGHUP or config.json changes
+	go config.WatchReload(context.Background(), cfg, logger, synthesisAgent.Reload)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("server shutdown error", "error", err)
-	}
-
-	// Close agent to flush observability data
-	if err := synthesisAgent.Close(); err != nil {
-		logger.Error("failed to close agent", "error", err)
+	// Serve until SIGINT or SIGTERM, then flush observability data
+	logger.Info("HTTP server starting",
+		"addr", server.Addr,
+		"mode", "ADK-based LLM extraction")
+	if err := synthesisAgent.Start(context.Background(), server); err != nil {
+		logger.Error("HTTP server failed", "error", err)
+		os.Exit(1)
 	}
-	logger.Info("shutdown complete")
 }
 
 // Helper functions
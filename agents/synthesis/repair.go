@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+// statExtraction is one statistic as returned by the extraction prompt
+type statExtraction struct {
+	Name    string           `json:"name"`
+	Value   extract.Quantity `json:"value"`
+	Unit    string           `json:"unit"`
+	Excerpt string           `json:"excerpt"`
+	Table   string           `json:"table,omitempty"`
+	Row     hintNumber       `json:"row,omitempty"` // A row that does not parse drops the table reference only
+	Column  string           `json:"column,omitempty"`
+	Type    string           `json:"type,omitempty"`
+
+	Methodology *methodologyHint `json:"methodology,omitempty"`
+}
+
+// methodologyHint is the methodology the extraction prompt reports for a
+// statistic, checked against the page by methodology.Resolve
+type methodologyHint struct {
+	SampleSize       hintNumber `json:"sample_size"`
+	Population       string     `json:"population"`
+	Period           string     `json:"period"`
+	MarginOfError    hintNumber `json:"margin_of_error"`
+	CollectionMethod string     `json:"collection_method"`
+}
+
+// hintNumber is a methodology number or table row in LLM output. Unlike a
+// value, one that does not parse ("unknown", "not stated") is dropped rather
+// than failing the statistic.
+type hintNumber float32
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *hintNumber) UnmarshalJSON(data []byte) error {
+	var v extract.Number
+	if err := v.UnmarshalJSON(data); err == nil {
+		*n = hintNumber(v)
+	}
+	return nil
+}
+
+// methodology returns the hint as a model, or nil
+func (h *methodologyHint) model() *models.Methodology {
+	if h == nil {
+		return nil
+	}
+	return &models.Methodology{
+		SampleSize:       int(h.SampleSize),
+		Population:       h.Population,
+		Period:           h.Period,
+		MarginOfError:    float32(h.MarginOfError),
+		CollectionMethod: h.CollectionMethod,
+	}
+}
+
+// generate sends a prompt to the request's model and returns the response text
+func (sa *SynthesisAgent) generate(ctx context.Context, prompt string) (string, error) {
+	llmReq := &model.LLMRequest{
+		Contents: genai.Text(prompt),
+	}
+
+	var response string
+	for llmResp, err := range llm.FromContext(ctx, sa.Model).GenerateContent(ctx, llmReq, false) {
+		if err != nil {
+			return "", fmt.Errorf("LLM generation failed: %w", err)
+		}
+		if llmResp.Content != nil && llmResp.Content.Parts != nil {
+			for _, part := range llmResp.Content.Parts {
+				if part.Text != "" {
+					response += part.Text
+				}
+			}
+		}
+	}
+	return response, nil
+}
+
+// validate checks an extraction against the extraction schema, coercing
+// what it can first: a unit written into the value is merged into unit,
+// and an overlong excerpt is cut to the text around the value. A unit
+// written into the value that conflicts with unit fails.
+func (e *statExtraction) validate() error {
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return errors.New("missing name")
+	}
+	if e.Value.Value == 0 {
+		return errors.New("missing value")
+	}
+	unit, err := e.Value.MergeUnit(e.Unit)
+	if err != nil {
+		return err
+	}
+	e.Unit = unit
+	excerpt, err := extract.Excerpt(e.Excerpt, e.Value.Value)
+	if err != nil {
+		return err
+	}
+	e.Excerpt = excerpt
+	return nil
+}
+
+// parseExtractions parses an extraction response, unwrapping markdown code
+// fences if needed. Each entry is decoded and validated on its own, so one
+// that is malformed or invalid is dropped, with its reason, without failing
+// the page; only a response with no JSON array fails.
+func parseExtractions(response string) (extractions []statExtraction, dropped []string, err error) {
+	entries, err := extract.Entries(response)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, entry := range entries {
+		var ext statExtraction
+		err := json.Unmarshal(entry, &ext)
+		if err == nil {
+			err = ext.validate()
+		}
+		if err != nil {
+			dropped = append(dropped, fmt.Sprintf("statistic %d: %v", i+1, err))
+			continue
+		}
+		extractions = append(extractions, ext)
+	}
+	return extractions, dropped, nil
+}
+
+// parseWithRepair parses an extraction response. When parsing fails it
+// re-prompts the LLM with the parse error and the malformed output, up to
+// JSON_REPAIR_ATTEMPTS times, before giving up on the page.
+func (sa *SynthesisAgent) parseWithRepair(ctx context.Context, response string) ([]statExtraction, error) {
+	var extractions []statExtraction
+	var dropped []string
+	parse := func(response string) (err error) {
+		extractions, dropped, err = parseExtractions(response)
+		return err
+	}
+	attempt := 0
+	fix := func(ctx context.Context, malformed string, parseErr error) (string, error) {
+		attempt++
+		sa.Logger.Info("repairing malformed LLM output", "attempt", attempt, "error", parseErr)
+		prompt, err := sa.repairPrompt(malformed, parseErr)
+		if err != nil {
+			return "", err
+		}
+		return sa.generate(ctx, prompt)
+	}
+
+	response, err := extract.Repair(ctx, response, sa.Cfg.JSONRepairAttempts, parse, fix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse LLM response as JSON: %w (response: %s)", err, response)
+	}
+	if len(dropped) > 0 {
+		sa.Logger.Debug("dropped invalid statistics", "kept", len(extractions), "dropped", dropped)
+	}
+	return extractions, nil
+}
+
+// repairPrompt asks the LLM to turn malformed output into valid JSON
+func (sa *SynthesisAgent) repairPrompt(malformed string, parseErr error) (string, error) {
+	return sa.Prompts.Render(prompts.SynthesisRepair, prompts.RepairData{
+		Error:  parseErr.Error(),
+		Output: malformed,
+	})
+}
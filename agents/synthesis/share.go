@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// shareContent stores the page candidates were extracted from in the
+// snapshot archive and sets its content hash on them, so verification checks
+// them against the bytes extraction saw rather than a page that may have
+// changed since. Candidates keep no hash when the page cannot be stored, and
+// verification re-fetches it.
+func (sa *SynthesisAgent) shareContent(ctx context.Context, doc *agentbase.Document, candidates []models.CandidateStatistic) {
+	if sa.archive == nil || len(candidates) == 0 {
+		return
+	}
+
+	snap := archive.NewSnapshot(doc.URL, doc.ContentType, doc.Body)
+	location, err := sa.archive.Put(ctx, snap)
+	if err != nil {
+		sa.Logger.Warn("failed to share page content", "url", doc.URL, "error", err)
+		return
+	}
+	sa.Logger.Debug("shared page content", "url", doc.URL, "hash", snap.Hash, "location", location)
+
+	for i := range candidates {
+		candidates[i].ContentHash = snap.Hash
+	}
+}
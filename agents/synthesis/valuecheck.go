@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// checkValues drops LLM-extracted candidates whose excerpt does not state
+// their value, written or scaled ("1.5 million" for 1500000). Verification
+// matches the excerpt, not the value, so such a candidate either fails there
+// after a re-fetch or passes with a quote that does not support it; a local
+// check costs nothing. Candidates read from a table cell are checked against
+// the cell instead and are kept.
+func (sa *SynthesisAgent) checkValues(pageURL string, candidates []models.CandidateStatistic) []models.CandidateStatistic {
+	if !sa.Cfg.SynthesisValueCheck {
+		return candidates
+	}
+
+	kept := candidates[:0]
+	for _, c := range candidates {
+		if c.Provenance == nil && !extract.ValueAppears(c.Excerpt, float64(c.Value)) {
+			sa.Logger.Debug("dropped candidate whose excerpt lacks its value",
+				"url", pageURL, "name", c.Name, "value", c.Value, "excerpt", c.Excerpt)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if dropped := len(candidates) - len(kept); dropped > 0 {
+		sa.Logger.Info("dropped candidates failing the value check", "url", pageURL, "dropped", dropped, "kept", len(kept))
+	}
+	return kept
+}
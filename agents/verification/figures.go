@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/figures"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+// figureReading is the vision model's re-reading of a statistic
+type figureReading struct {
+	Found bool     `json:"found"`
+	Value *float64 `json:"value"`
+	Text  string   `json:"text"`
+}
+
+// verifyFigure checks a statistic read from a figure more strictly than one
+// quoted from the page text, which a model cannot have misread: the page
+// must still show the figure, and a fresh reading of the image must give
+// exactly the same value. There is no fuzzy or passage fallback.
+func (va *VerificationAgent) verifyFigure(ctx context.Context, candidate models.CandidateStatistic, doc *agentbase.Document) verdict {
+	if va.vision == nil {
+		return verdict{reason: "No vision model to re-read the figure; set FIGURE_EXTRACTION and a Gemini VISION_MODEL", category: models.FailureLLM, method: "vision"}
+	}
+	if !figures.OnPage(doc.Body, doc.URL, candidate.ImageURL) {
+		return verdict{reason: "Figure no longer on the source page", category: models.FailureExcerptNotFound, method: "vision"}
+	}
+
+	img, err := va.FetchDocument(ctx, candidate.ImageURL, figures.MaxBytes>>20+1)
+	if err != nil {
+		return verdict{reason: fmt.Sprintf("Failed to fetch figure: %v", err), category: models.FailureFetch, method: "vision", fetchFailure: agentbase.ClassifyFetchError(err)}
+	}
+	prompt, err := va.Prompts.Render(prompts.VerificationFigure, prompts.FigureCheckData{
+		Name:    candidate.Name,
+		Value:   candidate.Value,
+		Unit:    candidate.Unit,
+		Excerpt: candidate.Excerpt,
+	})
+	if err != nil {
+		return verdict{reason: fmt.Sprintf("Figure verification failed: %v", err), category: models.FailureLLM, method: "vision"}
+	}
+	response, err := figures.Read(ctx, va.vision, prompt, img.ContentType, img.Body)
+	if err != nil {
+		va.Logger.Warn("figure verification failed", "url", candidate.SourceURL, "image", candidate.ImageURL, "error", err)
+		return verdict{reason: fmt.Sprintf("Figure verification failed: %v", err), category: models.FailureLLM, method: "vision"}
+	}
+
+	var r figureReading
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &r); err != nil {
+		return verdict{reason: fmt.Sprintf("Failed to parse figure reading: %v", err), category: models.FailureLLM, method: "vision"}
+	}
+	va.Logger.Debug("figure re-read", "image", candidate.ImageURL, "found", r.Found, "text", r.Text)
+
+	switch {
+	case !r.Found || r.Value == nil:
+		return verdict{reason: "Figure does not show this statistic on a second reading", category: models.FailureExcerptNotFound, method: "vision"}
+	case float32(*r.Value) != candidate.Value:
+		return verdict{reason: fmt.Sprintf("Figure read as %v on a second reading, not %v", *r.Value, candidate.Value), category: models.FailureValueMismatch, method: "vision"}
+	}
+	return verdict{verified: true, method: "vision"}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+const (
+	// maxPassages limits how many value occurrences are shown to the LLM
+	maxPassages = 3
+	// passageRadius is the number of characters kept on each side of a value occurrence
+	passageRadius = 600
+)
+
+// llmVerdict is the structured verdict returned by the LLM
+type llmVerdict struct {
+	Verdict     string   `json:"verdict"`      // supported, value_mismatch, context_mismatch, not_found
+	SourceValue *float64 `json:"source_value"` // Value stated by the source, if any
+	Quote       string   `json:"quote"`        // Verbatim passage supporting the verdict
+	Explanation string   `json:"explanation"`
+}
+
+// verifyWithLLM asks the LLM whether passages of the source that mention the
+// candidate's value support the claimed statistic. Only passages around
+// occurrences of the value are sent, so pages that never state the number are
+// rejected without an LLM call.
+func (va *VerificationAgent) verifyWithLLM(ctx context.Context, candidate models.CandidateStatistic, pageText string) verdict {
+	passages := findValuePassages(pageText, candidate.Value)
+	if len(passages) == 0 {
+		return valueNotFound(candidate)
+	}
+
+	prompt, err := va.Prompts.Render(prompts.VerificationJudge, prompts.JudgeData{
+		Name:      candidate.Name,
+		Value:     candidate.Value,
+		Unit:      candidate.Unit,
+		Excerpt:   candidate.Excerpt,
+		SourceURL: candidate.SourceURL,
+		Passages:  passages,
+	})
+	if err != nil {
+		return llmFailure(err)
+	}
+
+	response, err := va.generate(ctx, prompt)
+	if err != nil {
+		va.Logger.Warn("LLM verification failed", "url", candidate.SourceURL, "error", err)
+		return llmFailure(err)
+	}
+
+	var v llmVerdict
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &v); err != nil {
+		return verdict{reason: fmt.Sprintf("Failed to parse LLM verdict: %v", err), category: models.FailureLLM, method: "llm"}
+	}
+	return va.judge(candidate, passages, v)
+}
+
+// verifyBatchWithLLM judges candidates citing the same page in one LLM call,
+// sending the passages around all their values once with one verdict asked
+// per candidate. Candidates whose value is not on the page are rejected
+// without a call, and one the reply skips or garbles is asked about alone.
+func (va *VerificationAgent) verifyBatchWithLLM(ctx context.Context, candidates []models.CandidateStatistic, pageText string) []verdict {
+	verdicts := make([]verdict, len(candidates))
+	passages := make([][]string, len(candidates))
+	var pending []int
+	for i, candidate := range candidates {
+		if passages[i] = findValuePassages(pageText, candidate.Value); len(passages[i]) == 0 {
+			verdicts[i] = valueNotFound(candidate)
+			continue
+		}
+		pending = append(pending, i)
+	}
+	if len(pending) <= 1 {
+		for _, i := range pending {
+			verdicts[i] = va.verifyWithLLM(ctx, candidates[i], pageText)
+		}
+		return verdicts
+	}
+
+	data := prompts.BatchJudgeData{SourceURL: candidates[pending[0]].SourceURL}
+	for _, i := range pending {
+		candidate := candidates[i]
+		data.Statistics = append(data.Statistics, prompts.JudgeData{
+			Name:    candidate.Name,
+			Value:   candidate.Value,
+			Unit:    candidate.Unit,
+			Excerpt: candidate.Excerpt,
+		})
+		for _, p := range passages[i] {
+			if !slices.Contains(data.Passages, p) {
+				data.Passages = append(data.Passages, p)
+			}
+		}
+	}
+
+	prompt, err := va.Prompts.Render(prompts.VerificationJudgeBatch, data)
+	if err == nil {
+		var response string
+		if response, err = va.generate(ctx, prompt); err == nil {
+			replies := parseBatchVerdicts(response, len(pending))
+			va.Logger.Debug("LLM batch verdicts", "url", data.SourceURL, "candidates", len(pending), "verdicts", len(replies))
+			for n, i := range pending {
+				if v, ok := replies[n+1]; ok {
+					verdicts[i] = va.judge(candidates[i], passages[i], v)
+				} else {
+					verdicts[i] = va.verifyWithLLM(ctx, candidates[i], pageText)
+				}
+			}
+			return verdicts
+		}
+		va.Logger.Warn("LLM verification failed", "url", data.SourceURL, "candidates", len(pending), "error", err)
+	}
+	for _, i := range pending {
+		verdicts[i] = llmFailure(err)
+	}
+	return verdicts
+}
+
+// parseBatchVerdicts returns the verdicts of a batch reply by statistic
+// number, skipping entries that do not decode or number no statistic
+func parseBatchVerdicts(response string, n int) map[int]llmVerdict {
+	entries, err := extract.Entries(response)
+	if err != nil {
+		return nil
+	}
+	replies := make(map[int]llmVerdict, len(entries))
+	for _, entry := range entries {
+		var v struct {
+			ID int `json:"id"`
+			llmVerdict
+		}
+		if json.Unmarshal(entry, &v) != nil || v.ID < 1 || v.ID > n || v.Verdict == "" {
+			continue
+		}
+		if _, seen := replies[v.ID]; !seen {
+			replies[v.ID] = v.llmVerdict
+		}
+	}
+	return replies
+}
+
+// generate sends a prompt to the run's verification model and returns the
+// text of its reply
+func (va *VerificationAgent) generate(ctx context.Context, prompt string) (string, error) {
+	llmReq := &model.LLMRequest{
+		Contents: genai.Text(prompt),
+	}
+
+	var response string
+	for llmResp, err := range llm.FromContext(ctx, va.Model).GenerateContent(ctx, llmReq, false) {
+		if err != nil {
+			return "", err
+		}
+		if llmResp.Content != nil {
+			for _, part := range llmResp.Content.Parts {
+				response += part.Text
+			}
+		}
+	}
+	return response, nil
+}
+
+// judge turns the LLM's verdict on a candidate into a verification verdict
+func (va *VerificationAgent) judge(candidate models.CandidateStatistic, passages []string, v llmVerdict) verdict {
+	va.Logger.Debug("LLM verdict",
+		"url", candidate.SourceURL,
+		"verdict", v.Verdict,
+		"explanation", v.Explanation)
+
+	switch v.Verdict {
+	case "supported":
+		// Guard against hallucinated support: the quote must exist in the passages
+		if ok, _ := textmatch.Contains(strings.Join(passages, " "), v.Quote, textmatch.DefaultThreshold); !ok {
+			return verdict{
+				reason:   "LLM reported support but its quote is not in the source",
+				category: models.FailureExcerptNotFound,
+				method:   "llm",
+			}
+		}
+		return verdict{verified: true, method: "llm"}
+	case "value_mismatch":
+		reason := "Source states a different value"
+		if v.SourceValue != nil {
+			reason = fmt.Sprintf("Source states %v, not %v", *v.SourceValue, candidate.Value)
+		}
+		return verdict{reason: withExplanation(reason, v.Explanation), category: models.FailureValueMismatch, method: "llm"}
+	case "context_mismatch":
+		return verdict{
+			reason:   withExplanation("Value appears in source but refers to something else", v.Explanation),
+			category: models.FailureContext,
+			method:   "llm",
+		}
+	default:
+		return verdict{
+			reason:   withExplanation("Excerpt not found in source content", v.Explanation),
+			category: models.FailureExcerptNotFound,
+			method:   "llm",
+		}
+	}
+}
+
+// valueNotFound is the verdict on a candidate whose value the page never states
+func valueNotFound(candidate models.CandidateStatistic) verdict {
+	return verdict{
+		reason:   fmt.Sprintf("Excerpt not found and value %v does not appear in source content", candidate.Value),
+		category: models.FailureExcerptNotFound,
+		method:   "fuzzy",
+	}
+}
+
+// llmFailure is the verdict on a candidate the LLM could not judge
+func llmFailure(err error) verdict {
+	return verdict{reason: fmt.Sprintf("LLM verification failed: %v", err), category: models.FailureLLM, method: "llm"}
+}
+
+// findValuePassages returns up to maxPassages non-overlapping windows of the
+// page text surrounding occurrences of the value in any common formatting.
+func findValuePassages(pageText string, value float32) []string {
+	var passages []string
+	covered := -1
+
+	for _, variant := range valueVariants(value) {
+		for start := 0; len(passages) < maxPassages; {
+			idx := strings.Index(pageText[start:], variant)
+			if idx < 0 {
+				break
+			}
+			idx += start
+			start = idx + len(variant)
+
+			if !isNumberBoundary(pageText, idx, start) || idx <= covered {
+				continue
+			}
+
+			from := max(0, idx-passageRadius)
+			to := min(len(pageText), start+passageRadius)
+			passages = append(passages, strings.TrimSpace(pageText[from:to]))
+			covered = to
+		}
+	}
+	return passages
+}
+
+// valueVariants returns the textual forms a value commonly takes on a page
+func valueVariants(value float32) []string {
+	plain := strconv.FormatFloat(float64(value), 'f', -1, 32)
+	variants := []string{plain}
+
+	intPart, frac, _ := strings.Cut(plain, ".")
+	if len(strings.TrimPrefix(intPart, "-")) > 3 {
+		grouped := groupThousands(intPart)
+		if frac != "" {
+			grouped += "." + frac
+		}
+		variants = append(variants, grouped)
+	}
+	return variants
+}
+
+// groupThousands inserts comma separators into an integer string
+func groupThousands(s string) string {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	var b strings.Builder
+	for i, ch := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(ch)
+	}
+	if neg {
+		return "-" + b.String()
+	}
+	return b.String()
+}
+
+// isNumberBoundary reports whether text[start:end] is not part of a longer number
+func isNumberBoundary(text string, start, end int) bool {
+	isDigit := func(b byte) bool { return b >= '0' && b <= '9' }
+	if start > 0 && (isDigit(text[start-1]) || text[start-1] == '.') {
+		return false
+	}
+	if end < len(text) && isDigit(text[end]) {
+		return false
+	}
+	if end+1 < len(text) && (text[end] == '.' || text[end] == ',') && isDigit(text[end+1]) {
+		return false
+	}
+	return true
+}
+
+// extractJSONObject strips markdown fences and surrounding text from a JSON object response
+func extractJSONObject(response string) string {
+	response = strings.TrimSpace(response)
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return response
+	}
+	return response[start : end+1]
+}
+
+// withExplanation appends the LLM's explanation to a failure reason
+func withExplanation(reason, explanation string) string {
+	if explanation == "" {
+		return reason
+	}
+	return reason + ": " + explanation
+}
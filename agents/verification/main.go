@@ -3,31 +3,50 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"os"
-	"os/signal"
+	"slices"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
 	// A2A and ADK imports
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 
+	"github.com/plexusone/agent-team-stats/pkg/admission"
 	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/archive"
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/fingerprint"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
 	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/policy"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/sessionstore"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+	"github.com/plexusone/agent-team-stats/pkg/timing"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
 )
 
 // VerificationAgent uses ADK for validating statistics
 type VerificationAgent struct {
 	*agentbase.BaseAgent
 	adkAgent agent.Agent
+	archive  archive.Store // nil when snapshot archival is disabled
+	archived sync.Map      // Content hashes already archived by this process
+	vision   model.LLM     // Re-reads figures; nil when FIGURE_EXTRACTION is off
 }
 
 // VerificationInput defines input for verification tool
@@ -45,15 +64,31 @@ func NewVerificationAgent(cfg *config.Config, logger *slog.Logger) (*Verificatio
 	ctx := logging.WithLogger(context.Background(), logger)
 
 	// Create base agent with LLM
-	base, err := agentbase.NewBaseAgent(ctx, cfg, 30)
+	base, err := agentbase.NewStageAgent(ctx, cfg, llm.StageVerification, 30)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create base agent: %w", err)
 	}
 
 	logger.Info("agent initialized", "provider", base.GetProviderInfo())
 
+	store, err := archive.New(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot archive: %w", err)
+	}
+	if store != nil {
+		logger.Info("snapshot archival enabled", "backend", cfg.ArchiveBackend, "content_sharing", cfg.ContentSharing)
+	}
+	base.Documents = store
+
 	va := &VerificationAgent{
 		BaseAgent: base,
+		archive:   store,
+	}
+	if cfg.FigureExtraction {
+		// Statistics read from figures fail verification without a vision model
+		if va.vision, err = base.ModelFactory.CreateVisionModel(ctx); err != nil {
+			logger.Warn("figure verification disabled", "error", err)
+		}
 	}
 
 	// Create verification tool
@@ -66,23 +101,16 @@ func NewVerificationAgent(cfg *config.Config, logger *slog.Logger) (*Verificatio
 	}
 
 	// Create ADK agent
+	instruction, err := base.Prompts.Render(prompts.VerificationSystem, nil)
+	if err != nil {
+		return nil, err
+	}
 	adkAgent, err := llmagent.New(llmagent.Config{
 		Name:        "statistics_verification_agent",
 		Model:       base.Model,
 		Description: "Verifies that statistics actually exist in their claimed sources",
-		Instruction: `You are a statistics verification agent. Your job is to:
-1. Fetch the content from the provided source URL
-2. Search for the verbatim excerpt in the source content
-3. Verify the numerical value matches exactly
-4. Check if the source is reputable
-5. Flag any discrepancies, hallucinations, or mismatches
-
-Verification criteria:
-- The exact excerpt must be present in the source
-- The numerical value must match (allowing for reasonable formatting differences)
-- The source must be accessible and legitimate
-- The context must support the claimed statistic`,
-		Tools: []tool.Tool{verifyTool},
+		Instruction: instruction,
+		Tools:       []tool.Tool{verifyTool},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ADK agent: %w", err)
@@ -97,101 +125,468 @@ Verification criteria:
 func (va *VerificationAgent) verifyToolHandler(ctx tool.Context, input VerificationInput) (VerificationToolOutput, error) {
 	va.Logger.Info("verifying candidates", "count", len(input.Candidates))
 
-	results := make([]models.VerificationResult, 0, len(input.Candidates))
-
-	for _, candidate := range input.Candidates {
-		result := va.verifyStatistic(ctx, candidate)
-		results = append(results, result)
-	}
+	results, _ := va.verifyCandidates(ctx, input.Candidates, false, nil)
 
 	return VerificationToolOutput{
 		Results: results,
 	}, nil
 }
 
-// verifyStatistic verifies a single candidate
-func (va *VerificationAgent) verifyStatistic(ctx context.Context, candidate models.CandidateStatistic) models.VerificationResult {
+// verdict is the outcome of checking one candidate against its source
+type verdict struct {
+	verified bool
+	reason   string
+	category models.FailureCategory
+	method   string
+
+	fetchFailure models.FetchFailure // Why the source could not be fetched, with FailureFetch
+}
+
+// checked is a candidate checked against its source without the LLM
+type checked struct {
+	candidate models.CandidateStatistic
+	doc       *agentbase.Document // Nil when the fetch failed
+	verdict   verdict
+	pageText  string // Visible text of the source, set when the LLM is to judge
+	judge     bool   // The excerpt was not found and the LLM is to judge
+}
+
+// verifyCandidates verifies candidates in order. Each is first checked
+// without the LLM, and finished at once unless the LLM is to judge it;
+// those left for the LLM are then judged together with the others citing
+// the same page, VerificationBatchSize to a call, and finished batch by
+// batch. emit, if not nil, receives each result as soon as it is final, so
+// results arrive in the order they finish; the results returned are in
+// candidate order. With permissiveOnly, statistics whose source is not
+// permissively licensed are rejected. It also returns the fingerprints of
+// the sources checked, one per URL.
+func (va *VerificationAgent) verifyCandidates(ctx context.Context, candidates []models.CandidateStatistic, permissiveOnly bool, emit func(models.VerificationResult)) ([]models.VerificationResult, []models.SourceFingerprint) {
+	results := make([]models.VerificationResult, len(candidates))
+	fingerprints := make([]*models.SourceFingerprint, len(candidates))
+	done := func(i int, c checked) {
+		result, fp := va.finish(ctx, c.candidate, c.doc, c.verdict)
+		results[i] = va.accept(c.candidate, result, permissiveOnly)
+		fingerprints[i] = fp
+		if emit != nil {
+			emit(results[i])
+		}
+	}
+
+	checks := make([]checked, len(candidates))
+	for i, candidate := range candidates {
+		checks[i] = va.check(ctx, candidate)
+		if !checks[i].judge {
+			done(i, checks[i])
+		}
+	}
+	va.judgeChecks(ctx, checks, done)
+
+	var sources []models.SourceFingerprint
+	for _, fp := range fingerprints {
+		if fp != nil && !slices.ContainsFunc(sources, func(s models.SourceFingerprint) bool { return s.URL == fp.URL }) {
+			sources = append(sources, *fp)
+		}
+	}
+	return results, sources
+}
+
+// check fetches a candidate's source and checks the candidate against it
+// without the LLM
+func (va *VerificationAgent) check(ctx context.Context, candidate models.CandidateStatistic) checked {
 	va.Logger.Debug("verifying statistic", "url", candidate.SourceURL)
 
+	c := checked{candidate: candidate}
+
+	// Only the sources of internal search results are fetched with the site
+	// credentials
+	if candidate.Internal {
+		ctx = httpclient.WithCredentials(ctx)
+	}
+
+	doc, err := va.source(ctx, candidate)
+	switch {
+	case err != nil:
+		cause := agentbase.ClassifyFetchError(err)
+		va.Logger.Warn("failed to fetch source", "url", candidate.SourceURL, "cause", cause, "error", err)
+		c.verdict = verdict{reason: fmt.Sprintf("Failed to fetch source: %v", err), category: models.FailureFetch, fetchFailure: cause}
+		return c
+	case candidate.Provenance != nil:
+		// Data file or table: re-parse and compare the cell at the recorded location
+		c.verdict = verifyProvenance(candidate, doc)
+	case candidate.OCRDerived:
+		// Figure: re-read the image the statistic was taken from
+		c.verdict = va.verifyFigure(ctx, candidate, doc)
+	default:
+		c.verdict, c.pageText, c.judge = va.matchExcerpt(candidate, doc)
+	}
+	c.doc = doc
+	return c
+}
+
+// source returns the content a candidate is checked against: the page
+// synthesis extracted it from, when the archive holds it and the URL still
+// answers, or else a fresh fetch
+func (va *VerificationAgent) source(ctx context.Context, candidate models.CandidateStatistic) (*agentbase.Document, error) {
+	if candidate.ContentHash != "" && va.archive != nil && va.Cfg.ContentSharing {
+		snap, err := va.archive.Get(ctx, candidate.ContentHash)
+		switch {
+		case err == nil && snap.URL == candidate.SourceURL:
+			if err := va.Probe(ctx, candidate.SourceURL); err != nil {
+				return nil, err
+			}
+			va.Logger.Debug("verifying against shared content", "url", candidate.SourceURL, "hash", snap.Hash)
+			return agentbase.NewDocument(snap.URL, snap.ContentType, snap.Body), nil
+		case err == nil:
+			// A hash must not lend another page's content to a candidate
+			va.Logger.Warn("shared content is of another page", "url", candidate.SourceURL, "hash", candidate.ContentHash, "page", snap.URL)
+		case errors.Is(err, archive.ErrNotFound):
+			va.Logger.Debug("shared content not archived", "url", candidate.SourceURL, "hash", candidate.ContentHash)
+		default:
+			va.Logger.Warn("failed to load shared content", "url", candidate.SourceURL, "hash", candidate.ContentHash, "error", err)
+		}
+	}
+
 	// Fetch source content using base agent
-	sourceContent, err := va.FetchURL(ctx, candidate.SourceURL, 1)
-	if err != nil {
-		va.Logger.Warn("failed to fetch source", "url", candidate.SourceURL, "error", err)
-		return models.VerificationResult{
-			Statistic: &models.Statistic{
-				Name:      candidate.Name,
-				Value:     candidate.Value,
-				Unit:      candidate.Unit,
-				Source:    candidate.Source,
-				SourceURL: candidate.SourceURL,
-				Excerpt:   candidate.Excerpt,
-				Verified:  false,
-				DateFound: time.Now(),
-			},
-			Verified: false,
-			Reason:   fmt.Sprintf("Failed to fetch source: %v", err),
+	return va.FetchDocument(ctx, candidate.SourceURL, 0)
+}
+
+// judgeChecks asks the LLM about the checks left for it, grouped by source
+// page in batches of VerificationBatchSize, passing each check to judged
+// once its batch is judged
+func (va *VerificationAgent) judgeChecks(ctx context.Context, checks []checked, judged func(i int, c checked)) {
+	var pages []string
+	byPage := make(map[string][]int)
+	for i, c := range checks {
+		if !c.judge {
+			continue
 		}
+		url := c.candidate.SourceURL
+		if _, ok := byPage[url]; !ok {
+			pages = append(pages, url)
+		}
+		byPage[url] = append(byPage[url], i)
+	}
+
+	size := max(1, va.Cfg.VerificationBatchSize)
+	for _, url := range pages {
+		for batch := range slices.Chunk(byPage[url], size) {
+			pageText := checks[batch[0]].pageText
+			if len(batch) == 1 {
+				checks[batch[0]].verdict = va.verifyWithLLM(ctx, checks[batch[0]].candidate, pageText)
+			} else {
+				candidates := make([]models.CandidateStatistic, len(batch))
+				for n, i := range batch {
+					candidates[n] = checks[i].candidate
+				}
+				for n, v := range va.verifyBatchWithLLM(ctx, candidates, pageText) {
+					checks[batch[n]].verdict = v
+				}
+			}
+			for _, i := range batch {
+				judged(i, checks[i])
+			}
+		}
+	}
+}
+
+// finish records the verdict on a checked candidate. A statistic the page
+// quotes from another organization is verified against the page it links,
+// when the value is found there.
+func (va *VerificationAgent) finish(ctx context.Context, candidate models.CandidateStatistic, doc *agentbase.Document, v verdict) (models.VerificationResult, *models.SourceFingerprint) {
+	var attribution *models.Attribution
+	if v.verified && candidate.Provenance == nil && !candidate.OCRDerived && va.Cfg.PrimarySourceEnabled {
+		var result *models.VerificationResult
+		var fp *models.SourceFingerprint
+		if result, fp, attribution = va.tracePrimary(ctx, candidate, doc); result != nil {
+			return *result, fp
+		}
+	}
+
+	result, fp := va.result(ctx, candidate, doc, v)
+	result.Statistic.Attribution = attribution
+	return result, fp
+}
+
+// accept fails a verified result whose source, the page it was finally
+// verified against, SOURCE_POLICY does not accept, or, with permissiveOnly,
+// which declares no permissive license. The organization's own documents,
+// uploaded or found by internal search, need no license.
+func (va *VerificationAgent) accept(candidate models.CandidateStatistic, result models.VerificationResult, permissiveOnly bool) models.VerificationResult {
+	if !result.Verified {
+		return result
+	}
+	stat := result.Statistic
+	_, internal := archive.DocumentHash(stat.SourceURL)
+	// A primary source the page cites is not internal for being cited by one
+	internal = internal || (candidate.Internal && stat.SourceURL == candidate.SourceURL)
+
+	if va.Cfg.SourcePolicy != nil {
+		src := policy.NewSource(stat.SourceURL, stat.PublishedAt)
+		src.Internal = internal
+		ok, err := va.Cfg.SourcePolicy.Allows(src)
+		if !ok {
+			reason := fmt.Sprintf("Source not accepted by policy: %s", va.Cfg.SourcePolicy)
+			if err != nil {
+				va.Logger.Warn("source policy failed", "url", stat.SourceURL, "error", err)
+				reason = err.Error()
+			}
+			va.Logger.Debug("source rejected by policy", "url", stat.SourceURL)
+			return reject(result, reason, models.FailurePolicy)
+		}
+	}
+
+	if permissiveOnly && !internal && (stat.License == nil || !stat.License.Permissive) {
+		reason := "Source declares no license"
+		if stat.License != nil {
+			reason = fmt.Sprintf("Source license is not permissive: %s", stat.License.Kind)
+		}
+		va.Logger.Debug("source rejected by license", "url", stat.SourceURL)
+		return reject(result, reason, models.FailureLicense)
 	}
+	return result
+}
 
-	// Simple verification: check if excerpt appears in source
-	verified := strings.Contains(sourceContent, candidate.Excerpt)
-	reason := ""
-	if !verified {
-		reason = "Excerpt not found in source content"
+// reject fails a verified result for reason
+func reject(result models.VerificationResult, reason string, category models.FailureCategory) models.VerificationResult {
+	result.Statistic.Verified = false
+	result.Verified = false
+	result.Reason = reason
+	result.Category = category
+	return result
+}
+
+// result records a verdict on a candidate checked against doc, which is nil
+// when the source could not be fetched
+func (va *VerificationAgent) result(ctx context.Context, candidate models.CandidateStatistic, doc *agentbase.Document, v verdict) (models.VerificationResult, *models.SourceFingerprint) {
+	stat := candidate.Statistic(v.verified, time.Now())
+
+	// Data files are checked cell by cell; their text is not fingerprinted
+	var fp *models.SourceFingerprint
+	if doc != nil && candidate.Provenance == nil {
+		text, _ := sourceText(candidate, doc)
+		page := fingerprint.New(candidate.SourceURL, []byte(text))
+		f := page.Fingerprint()
+		fp = &f
+		if v.verified {
+			stat.Section = page.Section(candidate.Excerpt)
+		}
 	}
 
-	stat := &models.Statistic{
-		Name:      candidate.Name,
-		Value:     candidate.Value,
-		Unit:      candidate.Unit,
-		Source:    candidate.Source,
-		SourceURL: candidate.SourceURL,
-		Excerpt:   candidate.Excerpt,
-		Verified:  verified,
-		DateFound: time.Now(),
+	if v.verified {
+		stat.ContentHash = va.archiveSnapshot(ctx, doc)
+	}
+
+	// Candidates imported or found without a fetch take the publication
+	// the page declares
+	if doc != nil {
+		if stat.Publisher == "" {
+			stat.Publisher = doc.Publisher
+		}
+		if stat.PublishedAt.IsZero() {
+			stat.PublishedAt = doc.Published
+		}
+		stat.License = doc.Metadata.License(doc.URL)
 	}
 
 	return models.VerificationResult{
-		Statistic: stat,
-		Verified:  verified,
-		Reason:    reason,
+		Statistic: &stat,
+		Verified:  v.verified,
+		Reason:    v.reason,
+		Category:  v.category,
+		Method:    v.method,
+
+		FetchFailure: v.fetchFailure,
+	}, fp
+}
+
+// archiveSnapshot returns the content hash of a verified source and, when
+// archival is enabled, stores the exact content under that hash. Archive
+// failures are logged but never fail verification.
+func (va *VerificationAgent) archiveSnapshot(ctx context.Context, doc *agentbase.Document) string {
+	snap := archive.NewSnapshot(doc.URL, doc.ContentType, doc.Body)
+	if va.archive == nil {
+		return snap.Hash
+	}
+	if _, seen := va.archived.LoadOrStore(snap.Hash, struct{}{}); seen {
+		return snap.Hash
+	}
+
+	location, err := va.archive.Put(ctx, snap)
+	if err != nil {
+		va.archived.Delete(snap.Hash)
+		va.Logger.Warn("failed to archive source snapshot", "url", doc.URL, "hash", snap.Hash, "error", err)
+		return snap.Hash
+	}
+	va.Logger.Debug("archived source snapshot", "url", doc.URL, "hash", snap.Hash, "location", location)
+	return snap.Hash
+}
+
+// verifyExcerpt checks that the candidate's excerpt appears in the source,
+// asking the LLM when it is not found and LLM verification is enabled
+func (va *VerificationAgent) verifyExcerpt(ctx context.Context, candidate models.CandidateStatistic, doc *agentbase.Document) verdict {
+	v, pageText, judge := va.matchExcerpt(candidate, doc)
+	if judge {
+		return va.verifyWithLLM(ctx, candidate, pageText)
 	}
+	return v
 }
 
-// Verify processes a verification request
-//
-//nolint:unparam // error return kept for API consistency
-func (va *VerificationAgent) Verify(ctx context.Context, req *models.VerificationRequest) (*models.VerificationResponse, error) {
+// matchExcerpt checks that the candidate's excerpt appears in the source. An
+// exact match on the raw content is tried first; otherwise the visible page text
+// and excerpt are normalized (NFKC, entities, whitespace) and compared against
+// the configured similarity threshold. If that also fails and LLM verification
+// is enabled, it reports that the LLM is to judge whether nearby passages of
+// the page text it returns support the statistic.
+func (va *VerificationAgent) matchExcerpt(candidate models.CandidateStatistic, doc *agentbase.Document) (v verdict, pageText string, judge bool) {
+	content, err := sourceText(candidate, doc)
+	if err != nil {
+		return verdict{reason: fmt.Sprintf("Failed to read source: %v", err), category: models.FailureParse, method: "exact"}, "", false
+	}
+	if strings.Contains(content, candidate.Excerpt) {
+		return verdict{verified: true, method: "exact"}, "", false
+	}
+
+	threshold := va.Cfg.ExcerptMatchThreshold
+	if threshold <= 0 {
+		threshold = textmatch.DefaultThreshold
+	}
+
+	pageText = extract.PageText([]byte(content))
+	found, score := textmatch.Contains(pageText, candidate.Excerpt, threshold)
+	if found {
+		va.Logger.Debug("excerpt matched after normalization", "url", candidate.SourceURL, "similarity", score)
+		return verdict{verified: true, method: "fuzzy"}, "", false
+	}
+
+	v = verdict{
+		reason:   fmt.Sprintf("Excerpt not found in source content (best match %.0f%%)", score*100),
+		category: models.FailureExcerptNotFound,
+		method:   "fuzzy",
+	}
+	if va.Cfg.VerificationLLMEnabled {
+		return v, pageText, true
+	}
+	return v, "", false
+}
+
+// sourceText returns the text a candidate is matched against: the pages of a
+// PDF, Word, or PowerPoint document, read as synthesis reads them, or the body of any
+// other source
+func sourceText(candidate models.CandidateStatistic, doc *agentbase.Document) (string, error) {
+	format := extract.DetectFormat(doc.ContentType, candidate.SourceURL)
+	if !format.IsDocument() {
+		return string(doc.Body), nil
+	}
+	pages, err := extract.Pages(format, doc.Body)
+	return strings.Join(pages, "\n\n"), err
+}
+
+// verifyProvenance checks a data-file or table candidate by looking up the cell
+// at its recorded row and column and comparing the parsed value.
+func verifyProvenance(candidate models.CandidateStatistic, doc *agentbase.Document) verdict {
+	prov := candidate.Provenance
+	format := extract.DetectFormat(doc.ContentType, candidate.SourceURL)
+	if string(format) != prov.Format {
+		return verdict{
+			reason:   fmt.Sprintf("Source format changed: expected %s, got %s", prov.Format, format),
+			category: models.FailureParse,
+			method:   "cell",
+		}
+	}
+
+	tables, err := extract.Parse(format, doc.Body)
+	if err != nil {
+		return verdict{reason: fmt.Sprintf("Failed to parse source data: %v", err), category: models.FailureParse, method: "cell"}
+	}
+
+	cell, ok := extract.Lookup(tables, prov)
+	if !ok {
+		return verdict{
+			reason:   fmt.Sprintf("Cell not found in source (row %d, column %q)", prov.Row, prov.Column),
+			category: models.FailureExcerptNotFound,
+			method:   "cell",
+		}
+	}
+
+	value, ok := extract.ParseNumber(cell)
+	if !ok {
+		return verdict{
+			reason:   fmt.Sprintf("Cell is not numeric (row %d, column %q): %q", prov.Row, prov.Column, cell),
+			category: models.FailureValueMismatch,
+			method:   "cell",
+		}
+	}
+
+	if math.Abs(value-float64(candidate.Value)) > math.Abs(value)*1e-6 {
+		return verdict{
+			reason:   fmt.Sprintf("Value mismatch at row %d, column %q: source has %v", prov.Row, prov.Column, value),
+			category: models.FailureValueMismatch,
+			method:   "cell",
+		}
+	}
+
+	return verdict{verified: true, method: "cell"}
+}
+
+// Verify processes a verification request. emit, if not nil, receives each
+// result as soon as it is final.
+func (va *VerificationAgent) Verify(ctx context.Context, req *models.VerificationRequest, emit func(models.VerificationResult)) (*models.VerificationResponse, error) {
 	va.Logger.Info("verifying candidates", "count", len(req.Candidates))
 
-	results := make([]models.VerificationResult, 0, len(req.Candidates))
+	llmModel, err := va.ModelFactory.ModelFor(ctx, req.Model, va.Model)
+	if err != nil {
+		return nil, err
+	}
+	ctx = llm.WithModel(ctx, llmModel)
+	ctx = llm.WithGeneration(ctx, req.Generation)
+	ctx = llm.WithSampling(ctx, req.Sampling)
+	tracker := usage.NewTracker(string(llm.StageVerification))
+	ctx = usage.WithTracker(ctx, tracker)
+	timer := timing.New()
+	ctx = timing.WithRecorder(ctx, timer)
+
+	results, sources := va.verifyCandidates(ctx, req.Candidates, req.PermissiveLicenseOnly, emit)
 	verifiedCount := 0
 	failedCount := 0
+	var fetchFailures map[models.FetchFailure]int
 
-	for _, candidate := range req.Candidates {
-		result := va.verifyStatistic(ctx, candidate)
-		results = append(results, result)
-
+	for _, result := range results {
 		if result.Verified {
 			verifiedCount++
 		} else {
 			failedCount++
 		}
+		if result.FetchFailure != "" {
+			if fetchFailures == nil {
+				fetchFailures = make(map[models.FetchFailure]int)
+			}
+			fetchFailures[result.FetchFailure]++
+		}
 	}
 
+	// Time not spent fetching sources went to matching and LLM checks
+	timer.Rest(timing.Verification)
+
 	response := &models.VerificationResponse{
 		Results:   results,
 		Verified:  verifiedCount,
 		Failed:    failedCount,
 		Timestamp: time.Now(),
+		Usage:     tracker.Summary(),
+		Timings:   timer.Timings(),
+		Sources:   sources,
+
+		FetchFailures: fetchFailures,
 	}
 
-	va.Logger.Info("verification completed", "verified", verifiedCount, "failed", failedCount)
+	va.Logger.Info("verification completed", "verified", verifiedCount, "failed", failedCount, "fetch_failures", fetchFailures)
 	return response, nil
 }
 
 // HandleVerificationRequest is the HTTP handler.
 // Supports ?format=claims query parameter for structured-evaluation ClaimsReport output.
+// A client accepting application/x-ndjson gets each result as soon as it is
+// final, one models.VerificationEvent per line, and then the whole response.
 func (va *VerificationAgent) HandleVerificationRequest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -199,19 +594,29 @@ func (va *VerificationAgent) HandleVerificationRequest(w http.ResponseWriter, r
 	}
 
 	var req models.VerificationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := migrate.Decode(r.Body, &req); err != nil {
 		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	resp, err := va.Verify(r.Context(), &req)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Verification failed: %v", err), http.StatusInternalServerError)
+	if err := llm.ValidateOverride(va.Cfg, req.Model); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Check for claims format request via query parameter
 	format := r.URL.Query().Get("format")
+	if format != "claims" && strings.Contains(r.Header.Get("Accept"), httpclient.ContentTypeNDJSON) {
+		va.streamVerification(w, r, &req)
+		return
+	}
+
+	resp, err := va.Verify(r.Context(), &req, nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Verification failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	topic := r.URL.Query().Get("topic")
 	if topic == "" {
 		topic = "statistics-verification"
@@ -231,6 +636,43 @@ func (va *VerificationAgent) HandleVerificationRequest(w http.ResponseWriter, r
 	}
 }
 
+// streamVerification answers a verification request with a stream of
+// newline-delimited events: each result as it is final, then the response
+func (va *VerificationAgent) streamVerification(w http.ResponseWriter, r *http.Request, req *models.VerificationRequest) {
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	started := false
+	send := func(ev models.VerificationEvent) error {
+		if !started {
+			w.Header().Set("Content-Type", httpclient.ContentTypeNDJSON)
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	resp, err := va.Verify(r.Context(), req, func(result models.VerificationResult) {
+		if err := send(models.VerificationEvent{Result: &result}); err != nil {
+			va.Logger.Debug("failed to stream verification result", "error", err)
+		}
+	})
+	if err != nil {
+		if started {
+			va.Logger.Error("verification failed mid-stream", "error", err)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Verification failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := send(models.VerificationEvent{Response: resp}); err != nil {
+		va.Logger.Error("failed to encode response", "error", err)
+	}
+}
+
 func main() {
 	logger := logging.NewAgentLogger("verification")
 	cfg := config.LoadConfig()
@@ -243,7 +685,12 @@ func main() {
 
 	// Start A2A server if enabled (standard protocol for agent interoperability)
 	if cfg.A2AEnabled {
-		a2aServer, err := NewA2AServer(verificationAgent, "9002", logger)
+		sessions, err := sessionstore.New(context.Background(), cfg)
+		if err != nil {
+			logger.Error("failed to create A2A session store", "error", err)
+			os.Exit(1)
+		}
+		a2aServer, err := NewA2AServer(verificationAgent, cfg.A2AListenAddr(9002), cfg.A2AURL(9002), sessions, logger)
 		if err != nil {
 			logger.Error("failed to create A2A server", "error", err)
 		} else {
@@ -252,20 +699,22 @@ func main() {
 					logger.Error("A2A server error", "error", err)
 				}
 			}()
-			logger.Info("A2A server started", "port", 9002)
+			logger.Info("A2A server started", "addr", cfg.A2AListenAddr(9002))
 		}
 	}
 
 	// Start HTTP server with timeout (for custom security: SPIFFE, KYA, XAA, and observability)
 	timeout := time.Duration(cfg.HTTPTimeoutSeconds) * time.Second
 	server := &http.Server{
-		Addr:         ":8002",
+		Addr:         cfg.ListenAddr(8002),
 		ReadTimeout:  timeout,
 		WriteTimeout: timeout,
 		IdleTimeout:  timeout * 2,
 	}
 
-	http.HandleFunc("/verify", verificationAgent.HandleVerificationRequest)
+	admit := admission.Verification(cfg)
+	http.HandleFunc("/verify", admit.Wrap(verificationAgent.HandleVerificationRequest))
+	http.HandleFunc("/fetch-failures", verificationAgent.HandleFetchFailures)
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("OK")); err != nil {
@@ -273,32 +722,15 @@ func main() {
 		}
 	})
 
-	// Setup graceful shutdown
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-
-	go func() {
-		logger.Info("HTTP server starting",
-			"port", 8002,
-			"mode", "dual (HTTP + A2A)")
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("HTTP server failed", "error", err)
-		}
-	}()
-
-	<-stop
-	logger.Info("shutting down gracefully...")
+	// Swap in rotated credentials on SIGHUP or config.json changes
+	go config.WatchReload(context.Background(), cfg, logger, verificationAgent.Reload)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("server shutdown error", "error", err)
-	}
-
-	// Close agent to flush observability data
-	if err := verificationAgent.Close(); err != nil {
-		logger.Error("failed to close agent", "error", err)
+	// Serve until SIGINT or SIGTERM, then flush observability data
+	logger.Info("HTTP server starting",
+		"addr", server.Addr,
+		"mode", "dual (HTTP + A2A)")
+	if err := verificationAgent.Start(context.Background(), server); err != nil {
+		logger.Error("HTTP server failed", "error", err)
+		os.Exit(1)
 	}
-	logger.Info("shutdown complete")
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	agentbase "github.com/plexusone/agent-team-stats/pkg/agent"
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/primary"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+// tracePrimary follows a verified candidate that its page quotes from another
+// organization to the page the quote links, and verifies the statistic there.
+// It returns the result against the primary source, or nil and the
+// unconfirmed attribution; both are nil when the page cites no one.
+func (va *VerificationAgent) tracePrimary(ctx context.Context, candidate models.CandidateStatistic, doc *agentbase.Document) (*models.VerificationResult, *models.SourceFingerprint, *models.Attribution) {
+	citation := primary.Find(doc.Body, doc.URL, candidate.Excerpt)
+	if citation == nil {
+		return nil, nil, nil
+	}
+	attribution := &models.Attribution{Organization: citation.Organization, URL: citation.URL}
+	if citation.URL == "" {
+		attribution.Reason = "The page does not link the cited source"
+		return nil, nil, attribution
+	}
+
+	va.Logger.Debug("following citation to primary source", "url", candidate.SourceURL, "organization", citation.Organization, "primary", citation.URL)
+	primaryDoc, err := va.FetchDocument(ctx, citation.URL, 0)
+	if err != nil {
+		attribution.Reason = fmt.Sprintf("Failed to fetch cited source: %v", err)
+		return nil, nil, attribution
+	}
+
+	threshold := va.Cfg.ExcerptMatchThreshold
+	if threshold <= 0 {
+		threshold = textmatch.DefaultThreshold
+	}
+	excerpt, ok := primary.Locate(extract.PageText(primaryDoc.Body), candidate, threshold)
+	if !ok {
+		attribution.Reason = "Value not found in cited source"
+		return nil, nil, attribution
+	}
+
+	quoted := candidate
+	quoted.Source = citation.Organization
+	quoted.SourceURL = citation.URL
+	quoted.Excerpt = excerpt
+	quoted.Publisher, quoted.PublishedAt = "", time.Time{}
+	v := va.verifyExcerpt(ctx, quoted, primaryDoc)
+	if !v.verified {
+		attribution.Reason = v.reason
+		return nil, nil, attribution
+	}
+
+	attribution.Verified = true
+	attribution.CitedBy = &models.QuotedSource{
+		Source:    candidate.Source,
+		SourceURL: candidate.SourceURL,
+		Excerpt:   candidate.Excerpt,
+	}
+	result, fp := va.result(ctx, quoted, primaryDoc, v)
+	result.Statistic.Attribution = attribution
+	return &result, fp, nil
+}
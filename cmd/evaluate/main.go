@@ -0,0 +1,167 @@
+// Command evaluate runs a golden topic dataset through the statistics
+// pipeline and reports precision (returned statistics truly present in their
+// sources), recall against the dataset's answer key, and hallucination rate.
+//
+// The agents must be running; evaluate calls the orchestrator (or, with
+// --direct, the direct agent) like any other client:
+//
+//	go run ./cmd/evaluate
+//	go run ./cmd/evaluate --dataset my-topics.json --output json --out report.json
+//	go run ./cmd/evaluate --min-precision 0.8 # exit 1 below 80% precision (CI)
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/eval"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/logging"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Options defines the CLI options
+type Options struct {
+	Dataset         string  `long:"dataset" description:"Dataset JSON file (default: built-in golden dataset)"`
+	OrchestratorURL string  `long:"orchestrator-url" description:"Orchestrator URL (overrides env var)" env:"ORCHESTRATOR_URL"`
+	Direct          bool    `long:"direct" description:"Evaluate the direct agent (DIRECT_AGENT_URL) instead of the orchestrator"`
+	MinStats        int     `short:"m" long:"min-stats" description:"Override each topic's minimum number of statistics"`
+	Timeout         int     `long:"timeout" default:"300" description:"Per-topic pipeline timeout in seconds"`
+	Output          string  `short:"o" long:"output" default:"text" choice:"text" choice:"json" description:"Report format"`
+	Out             string  `long:"out" description:"Write the report to a file instead of stdout"`
+	MinPrecision    float64 `long:"min-precision" description:"Exit with status 1 when overall precision is below this (0-1)"`
+	MinRecall       float64 `long:"min-recall" description:"Exit with status 1 when overall recall is below this (0-1)"`
+}
+
+func main() {
+	var opts Options
+	if _, err := flags.Parse(&opts); err != nil {
+		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(1)
+	}
+
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "evaluate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run evaluates the dataset, writes the report, and applies the thresholds
+func run(opts Options) error {
+	logger := logging.NewAgentLogger("evaluate")
+	cfg := config.LoadConfig()
+	if opts.OrchestratorURL != "" {
+		cfg.OrchestratorURL = opts.OrchestratorURL
+	}
+
+	var ds *eval.Dataset
+	var err error
+	if opts.Dataset != "" {
+		ds, err = eval.LoadDataset(opts.Dataset)
+	} else {
+		ds, err = eval.Builtin()
+	}
+	if err != nil {
+		return err
+	}
+
+	pipeline := &http.Client{Timeout: time.Duration(opts.Timeout) * time.Second}
+	fetch := eval.HTTPFetcher(&http.Client{Timeout: time.Duration(cfg.HTTPTimeoutSeconds) * time.Second, Transport: httpclient.FetchTransport()}, cfg.FetchIdentity)
+
+	ctx := context.Background()
+	results := make([]eval.TopicResult, 0, len(ds.Topics))
+	for i, topic := range ds.Topics {
+		if opts.MinStats > 0 {
+			topic.MinStats = opts.MinStats
+		}
+		logger.Info("evaluating topic", "topic", topic.Topic, "progress", fmt.Sprintf("%d/%d", i+1, len(ds.Topics)))
+
+		start := time.Now()
+		resp, err := runPipeline(ctx, pipeline, cfg, opts.Direct, topic)
+		elapsed := time.Since(start)
+		if err != nil {
+			logger.Warn("pipeline failed", "topic", topic.Topic, "error", err)
+			result := eval.Score(topic, nil, nil)
+			result.Error = err.Error()
+			result.Duration = elapsed
+			results = append(results, result)
+			continue
+		}
+
+		checks := eval.CheckStatistics(ctx, fetch, resp.Statistics)
+		result := eval.Score(topic, resp.Statistics, checks)
+		result.Duration = elapsed
+		result.CostSummary = resp.CostSummary
+		results = append(results, result)
+	}
+
+	report := eval.NewReport(ds.Name, results)
+	if err := writeReport(report, opts); err != nil {
+		return err
+	}
+
+	if opts.MinPrecision > 0 && (report.Precision == nil || *report.Precision < opts.MinPrecision) {
+		return fmt.Errorf("precision below %.2f", opts.MinPrecision)
+	}
+	if opts.MinRecall > 0 && (report.Recall == nil || *report.Recall < opts.MinRecall) {
+		return fmt.Errorf("recall below %.2f", opts.MinRecall)
+	}
+	return nil
+}
+
+// runPipeline runs one topic through the orchestrator or the direct agent
+func runPipeline(ctx context.Context, client *http.Client, cfg *config.Config, direct bool, topic eval.Topic) (*models.OrchestrationResponse, error) {
+	var resp models.OrchestrationResponse
+
+	if direct {
+		directURL := os.Getenv("DIRECT_AGENT_URL")
+		if directURL == "" {
+			directURL = "http://localhost:8005"
+		}
+		req := map[string]any{"topic": topic.Topic, "min_stats": topic.MinStats}
+		if err := httpclient.PostJSON(ctx, client, directURL+"/search", req, &resp); err != nil {
+			return nil, err
+		}
+		return &resp, nil
+	}
+
+	req := &models.OrchestrationRequest{
+		Topic:            topic.Topic,
+		MinVerifiedStats: topic.MinStats,
+		ReputableOnly:    topic.ReputableOnly,
+	}
+	if err := httpclient.PostJSON(ctx, client, cfg.OrchestratorURL+"/orchestrate", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// writeReport writes the report in the requested format
+func writeReport(report *eval.Report, opts Options) error {
+	var w io.Writer = os.Stdout
+	if opts.Out != "" {
+		f, err := os.Create(opts.Out)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if opts.Output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return report.WriteText(w)
+}
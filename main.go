@@ -8,12 +8,24 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/plexusone/agent-team-stats/pkg/archive"
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/diagnose"
+	"github.com/plexusone/agent-team-stats/pkg/evidence"
+	"github.com/plexusone/agent-team-stats/pkg/export"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
 	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/report"
 )
 
 var logger *slog.Logger
@@ -26,6 +38,25 @@ type Options struct {
 
 	// Commands
 	Search SearchCommand `command:"search" description:"Search for verified statistics on a topic"`
+	Config ConfigCommand `command:"config" description:"Inspect the configuration"`
+	Watch  WatchCommand  `command:"watch" description:"Show live progress of the orchestrator's active runs"`
+}
+
+// WatchCommand defines options for the watch command
+type WatchCommand struct {
+	OrchestratorURL string `long:"orchestrator-url" description:"Orchestrator URL (overrides env var)" env:"ORCHESTRATOR_URL"`
+}
+
+// ConfigCommand groups the configuration subcommands
+type ConfigCommand struct {
+	Validate ConfigValidateCommand `command:"validate" description:"Check providers, credentials, and agent reachability"`
+}
+
+// ConfigValidateCommand defines options for the config validate command
+type ConfigValidateCommand struct {
+	NoPing  bool   `long:"no-ping" description:"Only check that credentials are set; do not call the LLM and search providers"`
+	Timeout int    `long:"timeout" default:"15" description:"Timeout in seconds for each provider call and health check"`
+	Output  string `short:"o" long:"output" default:"text" choice:"text" choice:"json" description:"Output format"`
 }
 
 // SearchCommand defines options for the search command
@@ -36,12 +67,23 @@ type SearchCommand struct {
 	} `positional-args:"yes" required:"yes"`
 
 	// Search options
-	MinStats      int    `short:"m" long:"min-stats" default:"10" description:"Minimum number of verified statistics required"`
+	MinStats      int    `short:"m" long:"min-stats" description:"Minimum number of verified statistics required (default: DEFAULT_MIN_VERIFIED_STATS or 10)"`
 	MaxCandidates int    `short:"c" long:"max-candidates" default:"50" description:"Maximum number of candidate statistics to gather"`
+	MaxPages      int    `long:"max-pages" description:"Pages read per pass in pipeline mode (default: DEFAULT_MAX_PAGES or 15)"`
 	ReputableOnly bool   `short:"r" long:"reputable-only" description:"Only use reputable sources"`
-	Output        string `short:"o" long:"output" default:"both" choice:"json" choice:"text" choice:"both" description:"Output format"`
+	Permissive    bool   `long:"permissive-only" description:"Only keep statistics from permissively licensed sources (CC0, CC BY, CC BY-SA, OGL, public domain) in pipeline mode"`
+	Output        string `short:"o" long:"output" default:"both" choice:"json" choice:"text" choice:"both" choice:"bibtex" choice:"csl-json" choice:"apa" choice:"mla" choice:"csv" description:"Output format (bibtex, csl-json, apa, and mla export citations; csv a spreadsheet)"`
 	Direct        bool   `short:"d" long:"direct" description:"Use direct LLM search (faster, like ChatGPT)"`
 	DirectVerify  bool   `long:"direct-verify" description:"Verify LLM claims with verification agent (requires --direct and verification agent running)"`
+	Compare       string `long:"compare" description:"Comma-separated entities or years to compare the statistic across (e.g. \"2010,2020\")"`
+	Types         string `long:"types" description:"Comma-separated kinds of statistic to keep in pipeline mode: survey, measured, projection, forecast, self_reported"`
+	NoProjections bool   `long:"exclude-projections" description:"Drop projections and forecasts in pipeline mode"`
+	Summary       bool   `long:"summary" description:"Also write a cited summary paragraph of the verified statistics in pipeline mode"`
+	Report        string `long:"report" value-name:"FILE" description:"Also write a verification report to FILE (.html, or .md for Markdown)"`
+	Evidence      string `long:"evidence" value-name:"FILE" description:"Also write an evidence bundle of the run to FILE (.tar.gz, or .zip)"`
+	DryRun        bool   `long:"dry-run" description:"Only search and select sources; print the planned URLs, providers, and estimated cost"`
+	Reproducible  bool   `long:"reproducible" description:"Sample LLMs at temperature 0 with a fixed seed and store every intermediate artifact in pipeline mode"`
+	Seed          int32  `long:"seed" description:"Seed for a reproducible run (implies --reproducible; default: derived from the topic)"`
 
 	// Orchestrator options
 	OrchestratorURL string `long:"orchestrator-url" description:"Orchestrator URL (overrides env var)" env:"ORCHESTRATOR_URL"`
@@ -53,8 +95,38 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 
 	cfg := config.LoadConfig()
 
+	// Create orchestration request, filling unset flags from the configured defaults
+	req := &models.OrchestrationRequest{
+		Topic:            topic,
+		MinVerifiedStats: cmd.MinStats,
+		MaxCandidates:    cmd.MaxCandidates,
+		ReputableOnly:    cmd.ReputableOnly,
+		Compare:          splitList(cmd.Compare),
+		DryRun:           cmd.DryRun,
+		IncludeSummary:   cmd.Summary,
+		Reproducible:     cmd.Reproducible,
+		Seed:             cmd.Seed,
+		StageLimits:      models.StageLimits{MaxPages: cmd.MaxPages},
+		TypeFilter:       models.TypeFilter{ExcludeProjections: cmd.NoProjections},
+
+		PermissiveLicenseOnly: cmd.Permissive,
+	}
+	for _, t := range splitList(cmd.Types) {
+		req.StatisticTypes = append(req.StatisticTypes, models.StatisticType(t))
+	}
+	cfg.ApplyDefaults(req)
+	if err := req.StageLimits.Validate(); err != nil {
+		return err
+	}
+	if err := req.TypeFilter.Validate(); err != nil {
+		return err
+	}
+	if cmd.DryRun && cmd.Direct {
+		return fmt.Errorf("--dry-run plans a pipeline run and cannot be combined with --direct")
+	}
+
 	fmt.Printf("Searching for statistics about: %s\n", topic)
-	fmt.Printf("Target: %d verified statistics\n", cmd.MinStats)
+	fmt.Printf("Target: %d verified statistics\n", req.MinVerifiedStats)
 
 	var resp *models.OrchestrationResponse
 	var err error
@@ -67,14 +139,14 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 			fmt.Println("mode: Direct LLM search (fast, like ChatGPT)")
 		}
 		fmt.Println()
-		resp, err = callDirectLLMSearch(topic, cmd.MinStats, cmd.DirectVerify)
+		resp, err = callDirectLLMSearch(cfg, topic, req.MinVerifiedStats, cmd.DirectVerify)
 		if err != nil {
 			return fmt.Errorf("direct LLM search failed: %w", err)
 		}
 
 		// Direct mode - just print results, no retry loop
 		printResults(resp, cmd.Output)
-		return nil
+		return cmd.writeFiles(resp)
 	}
 
 	fmt.Println("mode: Multi-agent verification pipeline")
@@ -85,22 +157,34 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 		cfg.OrchestratorURL = cmd.OrchestratorURL
 	}
 
-	// Create orchestration request
-	req := &models.OrchestrationRequest{
-		Topic:            topic,
-		MinVerifiedStats: cmd.MinStats,
-		MaxCandidates:    cmd.MaxCandidates,
-		ReputableOnly:    cmd.ReputableOnly,
-	}
-
 	// Call orchestration agent
 	resp, err = callOrchestrator(cfg, req)
 	if err != nil {
 		return fmt.Errorf("orchestration failed: %w", err)
 	}
 
+	// Dry run - nothing was extracted or verified
+	if resp.Plan != nil {
+		printPlan(resp, cmd.Output)
+		return nil
+	}
+
+	// Comparison mode - the orchestrator already searched per entity, no retry loop
+	if resp.Comparison != nil {
+		printResults(resp, cmd.Output)
+		return cmd.writeFiles(resp)
+	}
+
+	// Searching again would repeat the same empty search
+	if resp.Status == models.StatusNoResults {
+		fmt.Printf("⚠️  NO RESULTS: search found no sources for %q, even with a relaxed query.\n", topic)
+		fmt.Println("Try a broader topic or different wording.")
+		return cmd.writeFiles(resp)
+	}
+
 	// Handle partial results with retry logic
 	allStatistics := resp.Statistics
+	allRejected := resp.Rejected
 	totalVerified := resp.VerifiedCount
 	retryCount := 0
 	maxRetries := 3
@@ -135,8 +219,10 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 		continueReq := &models.OrchestrationRequest{
 			Topic:            topic,
 			MinVerifiedStats: stillNeeded,
-			MaxCandidates:    cmd.MaxCandidates + (retryCount * 20), // Increase search space
-			ReputableOnly:    cmd.ReputableOnly,
+			MaxCandidates:    req.MaxCandidates + (retryCount * 20), // Increase search space
+			ReputableOnly:    req.ReputableOnly,
+
+			PermissiveLicenseOnly: req.PermissiveLicenseOnly,
 		}
 
 		continueResp, err := callOrchestrator(cfg, continueReq)
@@ -148,12 +234,14 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 
 		// Merge new statistics with existing ones
 		allStatistics = append(allStatistics, continueResp.Statistics...)
+		allRejected = append(allRejected, continueResp.Rejected...)
 		totalVerified += continueResp.VerifiedCount
 
 		// Update response for next iteration
 		resp = continueResp
 		resp.VerifiedCount = totalVerified
 		resp.Statistics = allStatistics
+		resp.Rejected = allRejected
 		resp.Partial = totalVerified < req.MinVerifiedStats
 
 		if !resp.Partial {
@@ -170,9 +258,195 @@ func (cmd *SearchCommand) Execute([]string) error { // param `args []string`
 		printResults(resp, cmd.Output)
 	}
 
+	return cmd.writeFiles(resp)
+}
+
+// writeFiles writes the --report and --evidence files
+func (cmd *SearchCommand) writeFiles(resp *models.OrchestrationResponse) error {
+	if err := cmd.writeReport(resp); err != nil {
+		return err
+	}
+	return cmd.writeEvidence(resp)
+}
+
+// writeReport writes the --report file, in HTML unless it ends in .md
+func (cmd *SearchCommand) writeReport(resp *models.OrchestrationResponse) error {
+	if cmd.Report == "" {
+		return nil
+	}
+	format := report.FormatHTML
+	if ext := strings.ToLower(filepath.Ext(cmd.Report)); ext == ".md" || ext == ".markdown" {
+		format = report.FormatMarkdown
+	}
+
+	f, err := os.Create(cmd.Report)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := report.Write(f, resp, format); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Printf("Verification report written to %s\n", cmd.Report)
 	return nil
 }
 
+// writeEvidence writes the --evidence bundle, in zip when it ends in .zip
+// and tar.gz otherwise. Snapshots come from the archive configured by
+// ARCHIVE_BACKEND, as the verification agent's are.
+func (cmd *SearchCommand) writeEvidence(resp *models.OrchestrationResponse) error {
+	if cmd.Evidence == "" {
+		return nil
+	}
+	ctx := context.Background()
+	cfg := config.LoadConfig()
+	snapshots, err := archive.New(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot archive: %w", err)
+	}
+	promptSet, err := prompts.FromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+	bundle, err := evidence.Build(ctx, resp, evidence.Options{Archive: snapshots, Prompts: promptSet})
+	if err != nil {
+		return fmt.Errorf("failed to build evidence bundle: %w", err)
+	}
+
+	f, err := os.Create(cmd.Evidence)
+	if err != nil {
+		return fmt.Errorf("failed to create evidence bundle: %w", err)
+	}
+	if err := bundle.Write(f, evidence.FormatOf(cmd.Evidence)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write evidence bundle: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write evidence bundle: %w", err)
+	}
+	fmt.Printf("Evidence bundle %s written to %s\n", bundle.Hash(), cmd.Evidence)
+	if n := len(bundle.Manifest.MissingSnapshots); n > 0 {
+		fmt.Printf("⚠️  %d source snapshot(s) were not in the archive; set ARCHIVE_BACKEND to include them\n", n)
+	}
+	return nil
+}
+
+// Execute runs the config validate command
+func (cmd *ConfigValidateCommand) Execute([]string) error {
+	ctx := context.Background()
+
+	// Load like the agents do, but report a config.json or secrets error
+	// instead of silently falling back to the environment
+	var results []diagnose.Result
+	cfg, err := config.Load(ctx)
+	if err != nil {
+		results = append(results, diagnose.Result{Check: "config load", Status: diagnose.StatusFail, Detail: err.Error()})
+		cfg = config.LoadConfig()
+	}
+
+	agents := append(diagnose.AgentsFromConfig(cfg), diagnose.Agent{Name: "direct", URL: directAgentURL()})
+	results = append(results, diagnose.Run(ctx, cfg, diagnose.Options{
+		Ping:    !cmd.NoPing,
+		Timeout: time.Duration(cmd.Timeout) * time.Second,
+		Agents:  agents,
+	})...)
+
+	if cmd.Output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else if err := diagnose.WriteTable(os.Stdout, results); err != nil {
+		return err
+	}
+
+	if diagnose.Failed(results) {
+		return fmt.Errorf("configuration has failing checks")
+	}
+	return nil
+}
+
+// watchReconnectDelay is how long watch waits before reconnecting to a
+// closed event stream
+const watchReconnectDelay = 3 * time.Second
+
+// Execute runs the watch command, redrawing the dashboard on every event and
+// once a second until interrupted
+func (cmd *WatchCommand) Execute([]string) error {
+	cfg := config.LoadConfig()
+	if cmd.OrchestratorURL != "" {
+		cfg.OrchestratorURL = cmd.OrchestratorURL
+	}
+	url := strings.TrimSuffix(cfg.OrchestratorURL, "/") + "/runs/events"
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	type streamState struct {
+		connected bool
+		err       error
+	}
+	events := make(chan progress.Event)
+	states := make(chan streamState)
+	go func() {
+		client := &http.Client{}
+		for ctx.Err() == nil {
+			err := progress.Stream(ctx, client, url, cfg.APIKey, func(ev progress.Event) {
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+				}
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case states <- streamState{err: err}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-time.After(watchReconnectDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	dashboard := progress.NewDashboard()
+	status := "connecting"
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		header := fmt.Sprintf("stats-agent watch  %s  %s  [%s]  (Ctrl-C to quit)", url, now.Format("15:04:05"), status)
+		fmt.Print("\033[H\033[2J")
+		if err := dashboard.Render(os.Stdout, header, now); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case ev := <-events:
+			if status != "connected" {
+				// A new stream lists the active runs again, then sends ready
+				dashboard.Reset()
+				status = "connected"
+			}
+			dashboard.Apply(ev)
+		case st := <-states:
+			status = fmt.Sprintf("disconnected: %v; retrying", st.err)
+		case <-ticker.C:
+		}
+	}
+}
+
 func main() {
 	logger = logging.NewAgentLogger("cli")
 
@@ -207,16 +481,28 @@ LLM_PROVIDER          LLM provider (gemini, claude, openai, ollama)
 GEMINI_API_KEY        API key for Gemini
 CLAUDE_API_KEY        API key for Claude
 OPENAI_API_KEY        API key for OpenAI
-SEARCH_PROVIDER       Search provider (serper, serpapi)
+SEARCH_PROVIDER       Search provider (serper, serpapi, elasticsearch, opensearch, sharepoint)
 SERPER_API_KEY        API key for Serper
 SERPAPI_API_KEY       API key for SerpAPI
 ORCHESTRATOR_URL      Orchestrator URL (default: http://localhost:8000)
+DEFAULT_MIN_VERIFIED_STATS  Default --min-stats (default: 10)
+DEFAULT_MAX_CANDIDATES      Default max candidates of the services (default: 30; the CLI uses 50)
+DEFAULT_REPUTABLE_ONLY      Always use reputable sources (default: false)
 
 EXAMPLES:
 stats-agent search "climate change"
 stats-agent search "AI adoption rates" --min-stats 15
 stats-agent search "cybersecurity 2024" --output json
+stats-agent search "housing affordability" --output bibtex
+stats-agent search "internet penetration" --compare 2010,2020
 stats-agent search "renewable energy" --reputable-only
+stats-agent search "sea level rise" --exclude-projections
+stats-agent search "electric vehicle sales" --summary
+stats-agent search "remote work trends" --report report.html
+stats-agent search "remote work trends" --evidence evidence.tar.gz
+stats-agent config validate
+stats-agent config validate --no-ping
+stats-agent watch
 `
 
 	// Parse arguments
@@ -238,12 +524,16 @@ stats-agent search "renewable energy" --reputable-only
 	}
 }
 
-func callDirectLLMSearch(topic string, minStats int, verify bool) (*models.OrchestrationResponse, error) {
-	// Get direct agent URL from config or use default
-	directURL := os.Getenv("DIRECT_AGENT_URL")
-	if directURL == "" {
-		directURL = "http://localhost:8005"
+// directAgentURL returns the direct agent URL from DIRECT_AGENT_URL or the default
+func directAgentURL() string {
+	if url := os.Getenv("DIRECT_AGENT_URL"); url != "" {
+		return url
 	}
+	return "http://localhost:8005"
+}
+
+func callDirectLLMSearch(cfg *config.Config, topic string, minStats int, verify bool) (*models.OrchestrationResponse, error) {
+	directURL := directAgentURL()
 
 	// Create request
 	type DirectSearchRequest struct {
@@ -270,6 +560,7 @@ func callDirectLLMSearch(topic string, minStats int, verify bool) (*models.Orche
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	setAPIKey(httpReq, cfg)
 
 	client := &http.Client{}
 	httpResp, err := client.Do(httpReq) //nolint:gosec // G704: URL from config, not user input
@@ -283,7 +574,7 @@ func callDirectLLMSearch(topic string, minStats int, verify bool) (*models.Orche
 	}
 
 	var resp models.OrchestrationResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := migrate.Decode(httpResp.Body, &resp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -303,6 +594,7 @@ func callOrchestrator(cfg *config.Config, req *models.OrchestrationRequest) (*mo
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
+	setAPIKey(httpReq, cfg)
 
 	client := &http.Client{}
 	httpResp, err := client.Do(httpReq) //nolint:gosec // G704: URL from config, not user input
@@ -316,14 +608,29 @@ func callOrchestrator(cfg *config.Config, req *models.OrchestrationRequest) (*mo
 	}
 
 	var resp models.OrchestrationResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := migrate.Decode(httpResp.Body, &resp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return &resp, nil
 }
 
+// setAPIKey sends the tenant API key from STATS_API_KEY, if any
+func setAPIKey(req *http.Request, cfg *config.Config) {
+	if cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", cfg.APIKey)
+	}
+}
+
 func printResults(resp *models.OrchestrationResponse, outputFormat string) {
+	if format, err := export.ParseFormat(outputFormat); err == nil {
+		// Citation export only
+		if err := export.Write(os.Stdout, resp, format); err != nil {
+			logger.Error("failed to export citations", "format", format, "error", err)
+		}
+		return
+	}
+
 	if outputFormat == "json" {
 		// JSON only
 		jsonData, err := json.MarshalIndent(resp.Statistics, "", "  ")
@@ -340,6 +647,27 @@ func printResults(resp *models.OrchestrationResponse, outputFormat string) {
 	fmt.Printf("Topic: %s\n", resp.Topic)
 	fmt.Printf("Found: %d verified statistics (from %d candidates)\n", resp.VerifiedCount, resp.TotalCandidates)
 	fmt.Printf("Failed verification: %d\n", resp.FailedCount)
+	if resp.Query != "" {
+		fmt.Printf("Search query relaxed to: %s\n", resp.Query)
+	}
+	for _, c := range resp.Conflicts {
+		fmt.Printf("Conflict %s: %s - %s\n", c.ID, c.Metric, strings.Join(c.Values, " vs "))
+	}
+	if h := resp.Honesty; h != nil {
+		fmt.Printf("Honesty (%s/%s): %.0f%% - %d/%d URLs resolved, %d excerpts found, %d fabricated\n",
+			h.Provider, h.Model, h.Score*100, h.URLsResolved, h.Checked, h.ExcerptsFound, h.Fabricated)
+	}
+	if t := resp.Timings; t != nil {
+		fmt.Printf("Time: %s (search %s, fetch %s, extraction %s, verification %s, %d retries)\n",
+			ms(t.TotalMS), ms(t.SearchMS), ms(t.FetchMS), ms(t.ExtractionMS), ms(t.VerificationMS), t.Retries)
+	}
+	if r := resp.Reproducibility; r != nil {
+		stored := "stored in the archive"
+		if !r.Stored {
+			stored = "hashes only; set ARCHIVE_BACKEND to store them"
+		}
+		fmt.Printf("Reproducible: seed %d, temperature %g, %d artifacts (%s)\n", r.Seed, r.Temperature, len(r.Artifacts), stored)
+	}
 	fmt.Printf("Timestamp: %s\n\n", resp.Timestamp.Format("2006-01-02 15:04:05"))
 
 	if len(resp.Statistics) == 0 {
@@ -347,6 +675,17 @@ func printResults(resp *models.OrchestrationResponse, outputFormat string) {
 		return
 	}
 
+	if s := resp.Summary; s != nil {
+		fmt.Println("=== Summary (LLM-written from the verified statistics below) ===")
+		fmt.Println()
+		fmt.Println(s.Text)
+		fmt.Println()
+		for _, c := range s.Citations {
+			fmt.Printf("[%d] %s: %s, %s\n", c.Marker, c.Name, models.FormatValue(c.Value, c.Unit), c.SourceURL)
+		}
+		fmt.Println()
+	}
+
 	if outputFormat == "both" {
 		// Print JSON
 		fmt.Println("=== Verified Statistics (JSON) ===")
@@ -360,16 +699,131 @@ func printResults(resp *models.OrchestrationResponse, outputFormat string) {
 		fmt.Println()
 	}
 
+	if resp.Comparison != nil {
+		printComparison(resp.Comparison)
+	}
+	for _, s := range resp.Series {
+		printSeries(s)
+	}
+
 	// Human-readable format
 	fmt.Println("=== Human-Readable Format ===")
 	fmt.Println()
 	for i, stat := range resp.Statistics {
 		fmt.Printf("%d. %s\n", i+1, stat.Name)
 		fmt.Printf("   Value: %v %s\n", stat.Value, stat.Unit)
+		if stat.Type != "" {
+			fmt.Printf("   Type: %s\n", stat.Type)
+		}
 		fmt.Printf("   Source: %s\n", stat.Source)
+		if stat.Publisher != "" {
+			fmt.Printf("   Publisher: %s\n", stat.Publisher)
+		}
+		if !stat.PublishedAt.IsZero() {
+			fmt.Printf("   Published: %s\n", stat.PublishedAt.Format("2006-01-02"))
+		}
 		fmt.Printf("   URL: %s\n", stat.SourceURL)
 		fmt.Printf("   Excerpt: \"%s\"\n", stat.Excerpt)
-		fmt.Printf("   Verified: ✓\n")
+		if stat.OCRDerived {
+			fmt.Printf("   Read from figure: %s\n", stat.ImageURL)
+		}
+		if m := stat.Methodology.String(); m != "" {
+			fmt.Printf("   Methodology: %s\n", m)
+		}
+		if a := stat.Attribution; a != nil && a.CitedBy != nil {
+			fmt.Printf("   Quoted by: %s\n", a.CitedBy.SourceURL)
+		} else if a != nil {
+			fmt.Printf("   Attributed to: %s (not verified: %s)\n", a.Organization, a.Reason)
+		}
+		if stat.ConflictGroup != "" {
+			fmt.Printf("   Conflict: %s (another source gives a different value)\n", stat.ConflictGroup)
+		}
+		if stat.Verified {
+			fmt.Printf("   Verified: ✓\n")
+		} else {
+			fmt.Printf("   Verified: ✗ (not found in source)\n")
+		}
 		fmt.Printf("   Date Found: %s\n\n", stat.DateFound.Format("2006-01-02"))
 	}
 }
+
+// printPlan prints the plan returned for a dry run
+func printPlan(resp *models.OrchestrationResponse, outputFormat string) {
+	plan := resp.Plan
+	if outputFormat == "json" {
+		jsonData, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			logger.Error("failed to marshal JSON", "error", err)
+			return
+		}
+		fmt.Println(string(jsonData))
+		return
+	}
+
+	fmt.Printf("=== Dry Run Plan ===\n\n")
+	fmt.Printf("Topic: %s\n", resp.Topic)
+	searches := 0
+	if resp.CostSummary != nil {
+		searches = resp.CostSummary.SearchCalls
+	}
+	fmt.Printf("Search: %s (%d searches run for this plan)\n", plan.SearchProvider, searches)
+	if len(plan.Adapters) > 0 {
+		fmt.Printf("Extraction adapters: %s\n", strings.Join(plan.Adapters, ", "))
+	}
+	if u := plan.EstimatedUsage; u != nil {
+		for _, m := range u.ByModel {
+			fmt.Printf("LLM (%s): %s/%s, %d calls, ~%d tokens\n", m.Stage, m.Provider, m.Model, m.Calls, m.PromptTokens+m.CompletionTokens)
+		}
+		cost := fmt.Sprintf("~$%.4f", u.EstimatedCostUSD)
+		if u.Unpriced {
+			cost += " (some models have no known price)"
+		}
+		fmt.Printf("Estimated cost per pass: %s, up to %d passes\n", cost, plan.MaxPasses)
+	}
+
+	fmt.Printf("\nPlanned sources (%d):\n", len(plan.Sources))
+	for i, src := range plan.Sources {
+		how := src.Extraction
+		if src.Adapter != "" {
+			how += ":" + src.Adapter
+		}
+		fmt.Printf("%d. [%s] %s\n", i+1, how, src.URL)
+	}
+}
+
+func printComparison(c *models.Comparison) {
+	fmt.Printf("=== Comparison: %s ===\n\n", c.Metric)
+	for _, entry := range c.Entries {
+		if entry.Statistic == nil {
+			fmt.Printf("%-20s (no comparable statistic found)\n", entry.Entity)
+			continue
+		}
+		fmt.Printf("%-20s %v %s  [%s]\n", entry.Entity, entry.Statistic.Value, entry.Statistic.Unit, entry.Statistic.Source)
+	}
+	fmt.Println()
+}
+
+// printSeries prints a data series as one line per year
+func printSeries(s models.Series) {
+	fmt.Printf("=== Series: %s (%s) ===\n\n", s.Metric, s.SourceURL)
+	for _, p := range s.Points {
+		fmt.Printf("%-6d %s\n", p.Year, models.FormatValue(p.Value, s.Unit))
+	}
+	fmt.Println()
+}
+
+// splitList parses a comma-separated flag value, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ms formats a duration in milliseconds to a tenth of a second
+func ms(n int64) string {
+	return (time.Duration(n) * time.Millisecond).Round(100 * time.Millisecond).String()
+}
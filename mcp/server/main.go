@@ -8,14 +8,19 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/plexusone/agent-team-stats/pkg/config"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
+	"github.com/plexusone/agent-team-stats/pkg/mcpresources"
 	"github.com/plexusone/agent-team-stats/pkg/models"
 	"github.com/plexusone/agent-team-stats/pkg/orchestration"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+	"github.com/plexusone/agent-team-stats/pkg/toolspec"
 )
 
 const (
@@ -28,10 +33,32 @@ type SearchStatisticsParams struct {
 	MinVerifiedStats int    `json:"min_verified_stats,omitempty"`
 	MaxCandidates    int    `json:"max_candidates,omitempty"`
 	ReputableOnly    bool   `json:"reputable_only,omitempty"`
+	LLMProvider      string `json:"llm_provider,omitempty"`
+	LLMModel         string `json:"llm_model,omitempty"`
+
+	StatisticTypes     []models.StatisticType `json:"statistic_types,omitempty"`
+	ExcludeProjections bool                   `json:"exclude_projections,omitempty"`
+	IncludeSummary     bool                   `json:"include_summary,omitempty"`
+	PermissiveOnly     bool                   `json:"permissive_license_only,omitempty"`
+	Reproducible       bool                   `json:"reproducible,omitempty"`
+	Seed               int32                  `json:"seed,omitempty"`
 }
 
+type LookupStatisticsParams struct {
+	Query          string  `json:"query,omitempty"`
+	Topic          string  `json:"topic,omitempty"`
+	PublishedAfter string  `json:"published_after,omitempty"`
+	MinConfidence  float64 `json:"min_confidence,omitempty"`
+	Limit          int     `json:"limit,omitempty"`
+}
+
+// defaultLookupLimit is the number of stored statistics a lookup returns
+// without a limit
+const defaultLookupLimit = 10
+
 var (
 	einoAgent *orchestration.EinoOrchestrationAgent
+	resources *mcpresources.Resources
 	logger    *slog.Logger
 )
 
@@ -46,23 +73,24 @@ func SearchStatistics(ctx context.Context, req *mcp.CallToolRequest, args Search
 		}, nil, nil
 	}
 
-	// Set defaults
-	if args.MinVerifiedStats == 0 {
-		args.MinVerifiedStats = 10
-	}
-	if args.MaxCandidates == 0 {
-		args.MaxCandidates = 30
-	}
-	if !args.ReputableOnly {
-		args.ReputableOnly = true // default to true
-	}
-
-	// Create orchestration request
+	// Create orchestration request. MCP clients always get reputable
+	// sources, whatever they ask for.
 	orchReq := &models.OrchestrationRequest{
 		Topic:            args.Topic,
 		MinVerifiedStats: args.MinVerifiedStats,
 		MaxCandidates:    args.MaxCandidates,
-		ReputableOnly:    args.ReputableOnly,
+		ReputableOnly:    true,
+		LLMProvider:      args.LLMProvider,
+		LLMModel:         args.LLMModel,
+		IncludeSummary:   args.IncludeSummary,
+		Reproducible:     args.Reproducible,
+		Seed:             args.Seed,
+
+		PermissiveLicenseOnly: args.PermissiveOnly,
+		TypeFilter: models.TypeFilter{
+			StatisticTypes:     args.StatisticTypes,
+			ExcludeProjections: args.ExcludeProjections,
+		},
 	}
 
 	logger.Info("searching for statistics", "topic", args.Topic)
@@ -80,6 +108,9 @@ func SearchStatistics(ctx context.Context, req *mcp.CallToolRequest, args Search
 		}, nil, nil
 	}
 
+	// List the run and its topic among the resources
+	resources.Refresh()
+
 	// Format response
 	response := formatResponse(result)
 	logger.Info("search completed",
@@ -93,6 +124,54 @@ func SearchStatistics(ctx context.Context, req *mcp.CallToolRequest, args Search
 	}, nil, nil
 }
 
+// LookupStatistics searches the statistics verified by previous runs,
+// answering without running the pipeline
+func LookupStatistics(ctx context.Context, req *mcp.CallToolRequest, args LookupStatisticsParams) (*mcp.CallToolResult, any, error) {
+	q := statstore.Query{Text: strings.TrimSpace(args.Query), Topic: strings.TrimSpace(args.Topic)}
+	if q.Text == "" && q.Topic == "" {
+		return toolError("Error: query or topic is required"), nil, nil
+	}
+	if args.MinConfidence < 0 || args.MinConfidence > 1 {
+		return toolError("Error: min_confidence must be from 0 to 1"), nil, nil
+	}
+	if args.Limit < 0 {
+		return toolError("Error: limit must not be negative"), nil, nil
+	}
+	var err error
+	if q.PublishedAfter, err = statstore.ParseTime(args.PublishedAfter, false); err != nil {
+		return toolError(fmt.Sprintf("Error: invalid published_after: %v", err)), nil, nil
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultLookupLimit
+	}
+
+	matches, mode, err := einoAgent.Statistics().Search(ctx, q)
+	if err != nil {
+		logger.Error("statistics lookup failed", "error", err)
+		return toolError(fmt.Sprintf("Error looking up statistics: %v", err)), nil, nil
+	}
+	matches = slices.DeleteFunc(matches, func(m models.StatisticMatch) bool { return m.Confidence < args.MinConfidence })
+	results, page := models.Paginate(matches, 0, limit)
+	logger.Info("lookup completed", "query", q.Text, "topic", q.Topic, "found", page.Total)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatMatches(q, mode, results, page.Total)},
+		},
+	}, nil, nil
+}
+
+// toolError returns a tool result reporting an error to the client
+func toolError(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}
+}
+
 // IOTransport implements a stdio transport for MCP
 type IOTransport struct {
 	r *bufio.Reader
@@ -181,41 +260,41 @@ func main() {
 		nil,
 	)
 
-	// Add the search_statistics tool
+	// Add the search_statistics tool. It always uses reputable sources, so
+	// say so in place of the configured default.
+	searchParams := toolspec.SearchParameters(cfg)
+	searchParams["properties"].(map[string]any)["reputable_only"] = map[string]any{
+		"type":        "boolean",
+		"description": "Ignored: this tool always uses reputable sources like government, academic, and research organizations",
+	}
 	mcp.AddTool(
 		server,
 		&mcp.Tool{
-			Name: "search_statistics",
-			Description: "Search for verified statistics on a given topic using a multi-agent system. " +
-				"The system uses research and verification agents to find and validate statistics from " +
-				"reputable sources (government agencies, academic institutions, research organizations). " +
-				"Returns verified statistics with their sources, URLs, and verbatim excerpts.",
-			InputSchema: map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"topic": map[string]interface{}{
-						"type":        "string",
-						"description": "The topic to search statistics for (e.g., 'climate change', 'AI adoption rates', 'cybersecurity threats')",
-					},
-					"min_verified_stats": map[string]interface{}{
-						"type":        "number",
-						"description": "Minimum number of verified statistics to return (default: 10)",
-					},
-					"max_candidates": map[string]interface{}{
-						"type":        "number",
-						"description": "Maximum number of candidate statistics to gather (default: 30)",
-					},
-					"reputable_only": map[string]interface{}{
-						"type":        "boolean",
-						"description": "Only use reputable sources like government, academic, and research organizations (default: true)",
-					},
-				},
-				"required": []string{"topic"},
-			},
+			Name:        toolspec.SearchStatistics,
+			Description: toolspec.SearchDescription,
+			InputSchema: searchParams,
 		},
 		SearchStatistics,
 	)
 
+	// Add the lookup_statistics tool when verified statistics are stored
+	if einoAgent.Statistics() != nil {
+		mcp.AddTool(
+			server,
+			&mcp.Tool{
+				Name:        toolspec.LookupStatistics,
+				Description: toolspec.LookupDescription,
+				InputSchema: toolspec.LookupParameters(),
+			},
+			LookupStatistics,
+		)
+	} else {
+		logger.Info("lookup_statistics disabled; set STATS_STORE_FILE to store verified statistics")
+	}
+
+	// Add the stats://runs and stats://topics resources of previous results
+	resources = mcpresources.Register(server, einoAgent.Reports(), einoAgent.Statistics(), logger)
+
 	// Create stdio transport
 	transport := NewIOTransport(os.Stdin, os.Stdout)
 
@@ -239,13 +318,39 @@ func formatResponse(result *models.OrchestrationResponse) string {
 	output += fmt.Sprintf("**Verified:** %d statistics\n", result.VerifiedCount)
 	output += fmt.Sprintf("**Failed:** %d statistics\n", result.FailedCount)
 	output += fmt.Sprintf("**Total Candidates:** %d\n", result.TotalCandidates)
+	if result.Query != "" {
+		output += fmt.Sprintf("**Relaxed Query:** %s\n", result.Query)
+	}
+	if t := result.Timings; t != nil {
+		output += fmt.Sprintf("**Time:** %dms (search %dms, fetch %dms, extraction %dms, verification %dms, %d retries)\n",
+			t.TotalMS, t.SearchMS, t.FetchMS, t.ExtractionMS, t.VerificationMS, t.Retries)
+	}
+	if r := result.Reproducibility; r != nil {
+		output += fmt.Sprintf("**Reproducible:** seed %d, temperature %g, %d artifacts\n", r.Seed, r.Temperature, len(r.Artifacts))
+	}
+	if result.ReportID != "" {
+		output += fmt.Sprintf("**Resource:** %s\n", mcpresources.RunURI(result.ReportID))
+	}
 	output += fmt.Sprintf("**Timestamp:** %s\n\n", result.Timestamp.Format("2006-01-02 15:04:05"))
 
+	if result.Status == models.StatusNoResults {
+		output += "Search found no sources for this topic, even with a relaxed query. Try a broader topic or different wording.\n"
+		return output
+	}
 	if len(result.Statistics) == 0 {
 		output += "No verified statistics found.\n"
 		return output
 	}
 
+	if s := result.Summary; s != nil {
+		output += "## Summary\n\n_Written by an LLM from the verified statistics below; the markers cite them._\n\n"
+		output += s.Text + "\n\n"
+		for _, c := range s.Citations {
+			output += fmt.Sprintf("[%d] %s: %s, %s\n", c.Marker, c.Name, models.FormatValue(c.Value, c.Unit), c.SourceURL)
+		}
+		output += "\n"
+	}
+
 	// Add JSON representation
 	output += "## JSON Output\n\n```json\n"
 	jsonData, err := json.MarshalIndent(result.Statistics, "", "  ")
@@ -256,6 +361,16 @@ func formatResponse(result *models.OrchestrationResponse) string {
 	}
 	output += "\n```\n\n"
 
+	if len(result.Series) > 0 {
+		output += "## Data Series\n\n```json\n"
+		if seriesData, err := json.MarshalIndent(result.Series, "", "  "); err == nil {
+			output += string(seriesData)
+		} else {
+			output += fmt.Sprintf("Error formatting JSON: %v", err)
+		}
+		output += "\n```\n\n"
+	}
+
 	// Add human-readable format
 	output += "## Verified Statistics\n\n"
 	for i, stat := range result.Statistics {
@@ -270,3 +385,49 @@ func formatResponse(result *models.OrchestrationResponse) string {
 
 	return output
 }
+
+// formatMatches formats the stored statistics found by a lookup for display
+func formatMatches(q statstore.Query, mode string, results []models.StatisticMatch, total int) string {
+	output := "# Stored Statistics\n\n"
+	if q.Text != "" {
+		output += fmt.Sprintf("**Query:** %s\n", q.Text)
+	}
+	if q.Topic != "" {
+		output += fmt.Sprintf("**Topic:** %s\n", q.Topic)
+		output += fmt.Sprintf("**Resource:** %s\n", mcpresources.TopicURI(q.Topic))
+	}
+	output += fmt.Sprintf("**Found:** %d statistics (showing %d, ranked by %s)\n\n", total, len(results), mode)
+
+	if len(results) == 0 {
+		output += "No previously verified statistics match. Use search_statistics to find and verify new ones.\n"
+		return output
+	}
+
+	output += "## JSON Output\n\n```json\n"
+	if jsonData, err := json.MarshalIndent(results, "", "  "); err == nil {
+		output += string(jsonData)
+	} else {
+		output += fmt.Sprintf("Error formatting JSON: %v", err)
+	}
+	output += "\n```\n\n"
+
+	output += "## Verified Statistics\n\n"
+	for i, stat := range results {
+		output += fmt.Sprintf("### %d. %s\n\n", i+1, stat.Name)
+		output += fmt.Sprintf("- **Value:** %v %s\n", stat.Value, stat.Unit)
+		output += fmt.Sprintf("- **Source:** %s\n", stat.Source)
+		output += fmt.Sprintf("- **URL:** %s\n", stat.SourceURL)
+		output += fmt.Sprintf("- **Excerpt:** \"%s\"\n", stat.Excerpt)
+		output += fmt.Sprintf("- **Confidence:** %.2f (%s source)\n", stat.Confidence, stat.DomainTier)
+		output += fmt.Sprintf("- **Last Verified:** %s\n", stat.LastSeen.Format("2006-01-02"))
+		if stat.Stale != nil {
+			output += fmt.Sprintf("- **Stale:** the source changed on %s; verify it again before citing (%s)\n", stat.Stale.DetectedAt.Format("2006-01-02"), stat.Stale.DiffURL)
+		}
+		if stat.SupersededBy != "" {
+			output += fmt.Sprintf("- **Superseded:** the source has revised this value (see statistic %s)\n", stat.SupersededBy)
+		}
+		output += "\n"
+	}
+
+	return output
+}
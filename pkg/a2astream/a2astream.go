@@ -0,0 +1,127 @@
+// Package a2astream streams the statistics an orchestrator's runs verify to
+// A2A clients the moment they pass verification, as chunks of a
+// verified_statistics artifact, rather than only in the agent's final
+// answer. Statistics a run later withdraws, because their verification pass
+// failed or the final result dropped them, are streamed the same way as a
+// retracted_statistics artifact. Clients using message/stream receive each
+// chunk as an artifact update event.
+package a2astream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+)
+
+// Artifact names
+const (
+	ArtifactName          = "verified_statistics"  // The verified statistics
+	RetractedArtifactName = "retracted_statistics" // Verified statistics the run withdrew
+)
+
+// Executor wraps an agent executor whose runs report to a progress hub,
+// writing the statistics they verify to the task's event queue
+type Executor struct {
+	next   a2asrv.AgentExecutor
+	logger *slog.Logger
+}
+
+var (
+	_ a2asrv.AgentExecutor         = (*Executor)(nil)
+	_ a2asrv.AgentExecutionCleaner = (*Executor)(nil)
+)
+
+// Wrap returns next, streaming the statistics its runs verify
+func Wrap(next a2asrv.AgentExecutor, logger *slog.Logger) *Executor {
+	return &Executor{next: next, logger: logger}
+}
+
+// Execute runs the task, writing one artifact chunk for each batch of
+// statistics its runs verify or retract. The first chunk of an artifact
+// creates it and later ones append to it.
+func (e *Executor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	verified := &artifact{id: a2a.NewArtifactID(), name: ArtifactName,
+		description: "Statistics verified so far, streamed as they pass verification"}
+	retracted := &artifact{id: a2a.NewArtifactID(), name: RetractedArtifactName,
+		description: "Statistics streamed earlier that the final answer does not hold"}
+	ctx = progress.OnVerified(ctx, func(stats []models.Statistic) {
+		e.write(ctx, reqCtx, queue, verified, stats)
+	})
+	ctx = progress.OnRetracted(ctx, func(stats []models.Statistic) {
+		e.write(ctx, reqCtx, queue, retracted, stats)
+	})
+	return e.next.Execute(ctx, reqCtx, queue)
+}
+
+// artifact is a streamed artifact and the number of chunks written to it
+type artifact struct {
+	id          a2a.ArtifactID
+	name        string
+	description string
+
+	mu     sync.Mutex
+	chunks int
+}
+
+// write appends stats to an artifact as one chunk
+func (e *Executor) write(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue, art *artifact, stats []models.Statistic) {
+	parts := make(a2a.ContentParts, 0, len(stats))
+	for _, stat := range stats {
+		data, err := toData(stat)
+		if err != nil {
+			e.logger.Warn("failed to encode statistic", "artifact", art.name, "error", err)
+			continue
+		}
+		parts = append(parts, a2a.DataPart{Data: data})
+	}
+	if len(parts) == 0 {
+		return
+	}
+
+	art.mu.Lock()
+	defer art.mu.Unlock()
+	ev := a2a.NewArtifactUpdateEvent(reqCtx, art.id, parts...)
+	if art.chunks == 0 {
+		ev.Append = false
+		ev.Artifact.Name = art.name
+		ev.Artifact.Description = art.description
+	}
+	art.chunks++
+	if err := queue.Write(ctx, ev); err != nil {
+		e.logger.Warn("failed to stream statistics", "artifact", art.name, "task_id", reqCtx.TaskID, "error", err)
+	}
+}
+
+// Cancel cancels the task
+func (e *Executor) Cancel(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	return e.next.Cancel(ctx, reqCtx, queue)
+}
+
+// Cleanup passes the end of an execution on to the wrapped executor, if it
+// cleans up after itself
+func (e *Executor) Cleanup(ctx context.Context, reqCtx *a2asrv.RequestContext, result a2a.SendMessageResult, cause error) {
+	if cleaner, ok := e.next.(a2asrv.AgentExecutionCleaner); ok {
+		cleaner.Cleanup(ctx, reqCtx, result, cause)
+	}
+}
+
+// toData converts a statistic to the JSON object of a data part
+func toData(stat models.Statistic) (map[string]any, error) {
+	b, err := json.Marshal(stat)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,84 @@
+package a2astream
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+)
+
+// recordingQueue records the events written to it
+type recordingQueue struct {
+	eventqueue.Queue
+	events []a2a.Event
+}
+
+func (q *recordingQueue) Write(_ context.Context, ev a2a.Event) error {
+	q.events = append(q.events, ev)
+	return nil
+}
+
+// runningExecutor runs like an orchestrator's tool: it reports a run to a
+// hub that verifies two statistics in two passes, drops the first from its
+// result, then completes the task
+type runningExecutor struct {
+	hub *progress.Hub
+}
+
+func (e runningExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	_, run := e.hub.Begin(ctx, "solar", 2)
+	run.Verified([]models.Statistic{{Name: "a", Value: 1}}, 0)
+	run.Verified(nil, 1)
+	run.Verified([]models.Statistic{{Name: "b", Value: 2}}, 0)
+	run.Reconcile([]models.Statistic{{Name: "b", Value: 2}})
+	run.Finish(models.StatusComplete, nil)
+	return queue.Write(ctx, a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, nil))
+}
+
+func (runningExecutor) Cancel(context.Context, *a2asrv.RequestContext, eventqueue.Queue) error {
+	return nil
+}
+
+func TestExecuteStreamsVerifiedStatistics(t *testing.T) {
+	queue := &recordingQueue{}
+	reqCtx := &a2asrv.RequestContext{TaskID: a2a.NewTaskID(), ContextID: "ctx-1"}
+	e := Wrap(runningExecutor{hub: progress.NewHub()}, slog.New(slog.DiscardHandler))
+	if err := e.Execute(context.Background(), reqCtx, queue); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queue.events) != 4 {
+		t.Fatalf("events = %d; want two chunks, a retraction, and the final status", len(queue.events))
+	}
+	first, ok1 := queue.events[0].(*a2a.TaskArtifactUpdateEvent)
+	second, ok2 := queue.events[1].(*a2a.TaskArtifactUpdateEvent)
+	if !ok1 || !ok2 {
+		t.Fatalf("events = %T, %T; want artifact updates first", queue.events[0], queue.events[1])
+	}
+	if first.Append || first.Artifact.Name != ArtifactName || !second.Append || second.Artifact.ID != first.Artifact.ID {
+		t.Errorf("chunks = %+v, %+v; want one artifact created, then appended to", first, second)
+	}
+	if first.TaskID != reqCtx.TaskID || first.ContextID != "ctx-1" {
+		t.Errorf("chunk task = %s/%s", first.TaskID, first.ContextID)
+	}
+	part, ok := second.Artifact.Parts[0].(a2a.DataPart)
+	if !ok || part.Data["name"] != "b" {
+		t.Errorf("second chunk = %+v; want statistic b", second.Artifact.Parts)
+	}
+	retracted, ok := queue.events[2].(*a2a.TaskArtifactUpdateEvent)
+	if !ok || retracted.Append || retracted.Artifact.Name != RetractedArtifactName || retracted.Artifact.ID == first.Artifact.ID {
+		t.Fatalf("third event = %+v; want a new retracted artifact", queue.events[2])
+	}
+	if part, ok := retracted.Artifact.Parts[0].(a2a.DataPart); !ok || part.Data["name"] != "a" {
+		t.Errorf("retracted = %+v; want statistic a", retracted.Artifact.Parts)
+	}
+	if _, ok := queue.events[3].(*a2a.TaskStatusUpdateEvent); !ok {
+		t.Errorf("last event = %T; want the final status", queue.events[3])
+	}
+}
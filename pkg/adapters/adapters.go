@@ -0,0 +1,107 @@
+// Package adapters holds site-specific extractors that read statistics from
+// frequently returned sources with a known structure (indicator APIs, data
+// tables, infoboxes) instead of sending the page to the LLM. Adapters emit
+// candidates the verification agent can check: data cells carry provenance
+// and page text carries an excerpt copied verbatim from the page.
+package adapters
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Fetcher retrieves a URL, returning its Content-Type and body
+type Fetcher func(ctx context.Context, url string) (contentType string, body []byte, err error)
+
+// Request is the input to an adapter
+type Request struct {
+	Topic  string
+	Result models.SearchResult
+	Limit  int // Maximum candidates to return; 0 for the adapter's default
+	Fetch  Fetcher
+}
+
+// Adapter extracts candidate statistics from one site
+type Adapter interface {
+	// Name identifies the adapter in logs
+	Name() string
+	// Match reports whether the adapter handles the URL
+	Match(u *url.URL) bool
+	// Extract returns candidates for the search result. No candidates and no
+	// error means the page lacks the expected structure, and the caller
+	// falls back to generic extraction.
+	Extract(ctx context.Context, req Request) ([]models.CandidateStatistic, error)
+}
+
+// Registry is an ordered set of adapters; the first match handles a URL
+type Registry struct {
+	mu       sync.RWMutex
+	adapters []Adapter
+}
+
+// NewRegistry creates a registry of the given adapters
+func NewRegistry(adapters ...Adapter) *Registry {
+	return &Registry{adapters: adapters}
+}
+
+// Register adds an adapter, taking precedence over those already registered
+func (r *Registry) Register(a Adapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adapters = append([]Adapter{a}, r.adapters...)
+}
+
+// Lookup returns the adapter for a URL, or nil if none matches. A nil
+// registry matches nothing.
+func (r *Registry) Lookup(rawURL string) Adapter {
+	if r == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, a := range r.adapters {
+		if a.Match(u) {
+			return a
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the registered adapters in lookup order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, len(r.adapters))
+	for i, a := range r.adapters {
+		names[i] = a.Name()
+	}
+	return names
+}
+
+// defaultRegistry holds the built-in adapters and any registered at init
+var defaultRegistry = NewRegistry(WorldBank{}, Statista{}, Wikipedia{})
+
+// Default returns the registry of built-in adapters
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds an adapter to the default registry. Call it from an init
+// function to plug in support for another site.
+func Register(a Adapter) {
+	defaultRegistry.Register(a)
+}
+
+// hostIs reports whether host is domain or one of its subdomains
+func hostIs(host, domain string) bool {
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,155 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+// fakeFetch serves canned bodies by URL
+func fakeFetch(pages map[string]string) Fetcher {
+	return func(_ context.Context, u string) (string, []byte, error) {
+		body, ok := pages[u]
+		if !ok {
+			return "", nil, fmt.Errorf("unexpected fetch: %s", u)
+		}
+		return "text/html", []byte(body), nil
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := map[string]string{
+		"https://data.worldbank.org/indicator/SP.POP.TOTL?locations=US": "worldbank",
+		"https://data.worldbank.org/country/united-states":              "",
+		"https://www.statista.com/statistics/273018/number-of-users/":   "statista",
+		"https://en.wikipedia.org/wiki/Tokyo":                           "wikipedia",
+		"https://example.com/wiki/Tokyo":                                "",
+	}
+	for u, want := range tests {
+		got := ""
+		if a := Default().Lookup(u); a != nil {
+			got = a.Name()
+		}
+		if got != want {
+			t.Errorf("Lookup(%s) = %q, want %q", u, got, want)
+		}
+	}
+	if (*Registry)(nil).Lookup("https://en.wikipedia.org/wiki/Tokyo") != nil {
+		t.Error("nil registry matched")
+	}
+}
+
+func TestWorldBank(t *testing.T) {
+	page := "https://data.worldbank.org/indicator/SL.UEM.TOTL.ZS?locations=US-cn"
+	apiURL, err := worldBankAPIURL(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(apiURL)
+	if u.Path != "/v2/country/US;CN/indicator/SL.UEM.TOTL.ZS" || u.Query().Get("per_page") != "10" {
+		t.Fatalf("API URL = %s", apiURL)
+	}
+
+	body := `[{"page":1,"pages":1},[
+		{"indicator":{"id":"SL.UEM.TOTL.ZS","value":"Unemployment, total (% of total labor force)"},"country":{"id":"US","value":"United States"},"date":"2024","value":4.1,"unit":""},
+		{"indicator":{"id":"SL.UEM.TOTL.ZS","value":"Unemployment, total (% of total labor force)"},"country":{"id":"US","value":"United States"},"date":"2023","value":null,"unit":""}
+	]]`
+	cands, err := WorldBank{}.Extract(context.Background(), Request{
+		Result: models.SearchResult{URL: page},
+		Fetch:  fakeFetch(map[string]string{apiURL: body}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cands) != 1 {
+		t.Fatalf("got %d candidates, want 1 (null values skipped)", len(cands))
+	}
+	c := cands[0]
+	if c.Value != 4.1 || c.Unit != "%" || c.SourceURL != apiURL || c.Name != "Unemployment, total (% of total labor force) - United States (2024)" {
+		t.Errorf("candidate = %+v", c)
+	}
+
+	// The verification agent re-parses the API response and reads the cell
+	tables, _ := extract.Parse(extract.FormatJSON, []byte(body))
+	if cell, ok := extract.Lookup(tables, c.Provenance); !ok || cell != "4.1" {
+		t.Errorf("provenance cell = %q, %v", cell, ok)
+	}
+}
+
+func TestStatista(t *testing.T) {
+	page := "https://www.statista.com/statistics/1/smartphone-users/"
+	body := `<html><head><title>Smartphone users | Statista</title></head><body>
+		<h1>Number of smartphone users worldwide</h1>
+		<table><tr><th>Year</th><th>Users in billions</th></tr>
+		<tr><td>2023</td><td>4.6</td></tr><tr><td>2024</td><td>4.9</td></tr></table>
+		<table><tr><th>Related</th><th>Value</th></tr><tr><td>Other</td><td>1</td></tr></table>
+	</body></html>`
+	cands, err := Statista{}.Extract(context.Background(), Request{
+		Topic:  "smartphone users",
+		Result: models.SearchResult{URL: page, Domain: "www.statista.com"},
+		Fetch:  fakeFetch(map[string]string{page: body}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cands) != 2 {
+		t.Fatalf("got %d candidates, want 2 from the first table only", len(cands))
+	}
+	if cands[1].Name != "Number of smartphone users worldwide - 2024" || cands[1].Value != 4.9 || cands[1].Source != "Statista" {
+		t.Errorf("candidate = %+v", cands[1])
+	}
+}
+
+func TestWikipedia(t *testing.T) {
+	page := "https://en.wikipedia.org/wiki/Tokyo"
+	body := `<html><body><h1>Tokyo</h1>
+		<table class="infobox ib-settlement vcard">
+		<tr><th colspan="2">Tokyo</th></tr>
+		<tr><th>Founded</th><td>1457</td></tr>
+		<tr><th>Area<sup>[3]</sup></th><td></td></tr>
+		<tr><th>• Total</th><td>2,194.07 km<sup>2</sup></td></tr>
+		<tr><th>Population (2020)</th><td>14,047,594<sup>[5]</sup></td></tr>
+		<tr><th>GDP growth</th><td>2.5 percent</td></tr>
+		<tr><th>Website</th><td>metro.tokyo.lg.jp</td></tr>
+		</table><p>Tokyo is the capital of Japan.</p></body></html>`
+	cands, err := Wikipedia{}.Extract(context.Background(), Request{
+		Topic:  "tokyo population",
+		Result: models.SearchResult{URL: page, Domain: "en.wikipedia.org"},
+		Fetch:  fakeFetch(map[string]string{page: body}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name  string
+		value float32
+		unit  string
+	}{
+		{"Tokyo - Population (2020)", 14047594, ""}, // topic match first
+		{"Tokyo - Area - Total", 2194.07, ""},
+		{"Tokyo - GDP growth", 2.5, "%"},
+	}
+	if len(cands) != len(want) {
+		t.Fatalf("got %d candidates: %+v", len(cands), cands)
+	}
+	pageText := extract.PageText([]byte(body))
+	for i, w := range want {
+		c := cands[i]
+		if c.Name != w.name || c.Value != w.value || c.Unit != w.unit {
+			t.Errorf("candidate %d = %q %v %q, want %q %v %q", i, c.Name, c.Value, c.Unit, w.name, w.value, w.unit)
+		}
+		if !strings.Contains(pageText, c.Excerpt) {
+			t.Errorf("excerpt %q not in page text", c.Excerpt)
+		}
+		if found, _ := textmatch.Contains(pageText, c.Excerpt, textmatch.DefaultThreshold); !found {
+			t.Errorf("excerpt %q would not verify", c.Excerpt)
+		}
+	}
+}
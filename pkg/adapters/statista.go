@@ -0,0 +1,119 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Statista reads the data table behind the chart on statista.com statistic
+// pages. Candidates are named after the page heading and carry table
+// provenance, like tables parsed from any other page.
+type Statista struct{}
+
+// Name implements Adapter
+func (Statista) Name() string { return "statista" }
+
+// Match implements Adapter
+func (Statista) Match(u *url.URL) bool {
+	return hostIs(u.Hostname(), "statista.com") && strings.HasPrefix(u.Path, "/statistics/")
+}
+
+// Extract implements Adapter
+func (Statista) Extract(ctx context.Context, req Request) ([]models.CandidateStatistic, error) {
+	_, body, err := req.Fetch(ctx, req.Result.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	tables, err := extract.Parse(extract.FormatHTML, body)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	// The statistic's own data is the first table; later ones are
+	// related-statistics widgets. Its first column labels the chart's
+	// categories (often years), so only the series columns are values.
+	t := tables[0]
+	title := pageHeading(body)
+	var candidates []models.CandidateStatistic
+	for _, c := range t.Candidates(req.Topic, req.Result, 0) {
+		if len(t.Headers) == 0 || c.Provenance.Column == t.Headers[0] {
+			continue
+		}
+		cell, _ := extract.Lookup(tables, c.Provenance)
+		label, _ := extract.Lookup(tables, &models.Provenance{
+			Format: c.Provenance.Format,
+			Sheet:  c.Provenance.Sheet,
+			Row:    c.Provenance.Row,
+			Column: t.Headers[0],
+		})
+		parts := []string{title, label}
+		if len(t.Headers) > 2 {
+			parts = append(parts, c.Provenance.Column)
+		}
+		c.Name = joinNonEmpty(parts, " - ")
+		c.Excerpt = fmt.Sprintf("%s: %s", c.Name, cell)
+		c.Source = "Statista"
+		candidates = append(candidates, c)
+		if req.Limit > 0 && len(candidates) >= req.Limit {
+			break
+		}
+	}
+	return candidates, nil
+}
+
+// joinNonEmpty joins the non-empty parts with sep
+func joinNonEmpty(parts []string, sep string) string {
+	kept := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// pageHeading returns the text of the first <h1>, or the <title>
+func pageHeading(body []byte) string {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var h1, title string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if h1 != "" {
+			return
+		}
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.H1:
+				h1 = extract.NodeText(n)
+				return
+			case atom.Title:
+				if title == "" {
+					title = extract.NodeText(n)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if h1 != "" {
+		return h1
+	}
+	return title
+}
@@ -0,0 +1,197 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// infoboxLimit caps the candidates read from one infobox
+const infoboxLimit = 15
+
+var (
+	// footnote matches reference markers such as [1] or [note 2]
+	footnote = regexp.MustCompile(`\[[^\]]{1,12}\]`)
+	// leadingValue matches the first number in a cell and a scale or
+	// percent sign right after it
+	leadingValue = regexp.MustCompile(`(?i)(\d[\d,]*(?:\.\d+)?)\s*(%|percent|million|billion|trillion)?`)
+	// bareYear matches a cell that is only a year, e.g. "Founded 1998"
+	bareYear = regexp.MustCompile(`^(1\d|20)\d{2}$`)
+)
+
+// Wikipedia reads the numeric rows of the infobox on Wikipedia articles.
+// Each candidate's excerpt is the row's text as it appears on the page, so
+// the verification agent finds it like any other excerpt.
+type Wikipedia struct{}
+
+// Name implements Adapter
+func (Wikipedia) Name() string { return "wikipedia" }
+
+// Match implements Adapter
+func (Wikipedia) Match(u *url.URL) bool {
+	return hostIs(u.Hostname(), "wikipedia.org") && strings.HasPrefix(u.Path, "/wiki/")
+}
+
+// Extract implements Adapter
+func (Wikipedia) Extract(ctx context.Context, req Request) ([]models.CandidateStatistic, error) {
+	_, body, err := req.Fetch(ctx, req.Result.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	box := findInfobox(doc)
+	if box == nil {
+		return nil, nil
+	}
+	title := strings.TrimSuffix(pageHeading(body), " - Wikipedia")
+	terms := strings.Fields(strings.ToLower(req.Topic))
+
+	type scored struct {
+		cand  models.CandidateStatistic
+		score int
+	}
+	var all []scored
+	for _, row := range infoboxRows(box) {
+		value, unit, ok := infoboxValue(row.value)
+		if !ok {
+			continue
+		}
+		name := row.label
+		if title != "" {
+			name = title + " - " + row.label
+		}
+		score := 0
+		for _, term := range terms {
+			if len(term) > 3 && strings.Contains(strings.ToLower(row.label), term) {
+				score++
+			}
+		}
+		all = append(all, scored{
+			cand: models.CandidateStatistic{
+				Name:      name,
+				Value:     float32(value),
+				Unit:      unit,
+				Source:    req.Result.Domain,
+				SourceURL: req.Result.URL,
+				Excerpt:   row.text,
+			},
+			score: score,
+		})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score > all[j].score })
+
+	limit := infoboxLimit
+	if req.Limit > 0 && req.Limit < limit {
+		limit = req.Limit
+	}
+	candidates := make([]models.CandidateStatistic, 0, min(limit, len(all)))
+	for _, s := range all[:min(limit, len(all))] {
+		candidates = append(candidates, s.cand)
+	}
+	return candidates, nil
+}
+
+// infoboxRow is a labelled row of an infobox
+type infoboxRow struct {
+	label string
+	value string
+	text  string // Row text as rendered on the page
+}
+
+// findInfobox returns the first table with the "infobox" class
+func findInfobox(n *html.Node) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Table {
+		for _, a := range n.Attr {
+			if a.Key == "class" && strings.Contains(" "+a.Val+" ", " infobox ") {
+				return n
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if box := findInfobox(c); box != nil {
+			return box
+		}
+	}
+	return nil
+}
+
+// infoboxRows returns rows holding one header cell and one data cell.
+// Subordinate rows such as "• Density" are labelled with their section,
+// e.g. "Population - Density".
+func infoboxRows(box *html.Node) []infoboxRow {
+	var rows []infoboxRow
+	section := ""
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Tr {
+			var th, td *html.Node
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				switch {
+				case c.Type != html.ElementNode:
+				case c.DataAtom == atom.Th && th == nil:
+					th = c
+				case c.DataAtom == atom.Td && td == nil:
+					td = c
+				}
+			}
+			switch {
+			case th != nil && td != nil:
+				raw := extract.NodeText(th)
+				label := infoboxLabel(strings.TrimLeft(raw, "•· "))
+				if (strings.HasPrefix(raw, "•") || strings.HasPrefix(raw, "·")) && section != "" {
+					label = section + " - " + label
+				} else {
+					section = label
+				}
+				rows = append(rows, infoboxRow{label: label, value: extract.NodeText(td), text: extract.NodeText(n)})
+			case th != nil:
+				section = infoboxLabel(extract.NodeText(th))
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(box)
+	return rows
+}
+
+// infoboxLabel removes footnote markers from a row label
+func infoboxLabel(s string) string {
+	return strings.Join(strings.Fields(footnote.ReplaceAllString(s, "")), " ")
+}
+
+// infoboxValue parses the leading number of an infobox cell, ignoring
+// footnote markers, and returns the unit written after it. Cells that hold
+// only a year are not statistics.
+func infoboxValue(cell string) (float64, string, bool) {
+	cell = strings.TrimSpace(footnote.ReplaceAllString(cell, ""))
+	m := leadingValue.FindStringSubmatch(cell)
+	if m == nil || bareYear.MatchString(cell) {
+		return 0, "", false
+	}
+	value, ok := extract.ParseNumber(m[1])
+	if !ok {
+		return 0, "", false
+	}
+	unit := strings.ToLower(m[2])
+	if unit == "percent" {
+		unit = "%"
+	}
+	return value, unit, true
+}
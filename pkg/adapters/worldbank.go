@@ -0,0 +1,143 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// worldBankAPI is the base URL of the World Bank indicators API
+var worldBankAPI = "https://api.worldbank.org/v2"
+
+// worldBankRecent is how many of the most recent years are requested per
+// country
+const worldBankRecent = 5
+
+var (
+	indicatorPath = regexp.MustCompile(`^/indicator/([A-Za-z0-9._]+)/?$`)
+	locationCode  = regexp.MustCompile(`^[A-Za-z0-9]{2,3}$`)
+)
+
+// WorldBank reads data.worldbank.org indicator pages, which render their
+// chart in the browser, from the indicators API. Candidates cite the API
+// response and its cells, so verification re-reads the same JSON.
+type WorldBank struct{}
+
+// Name implements Adapter
+func (WorldBank) Name() string { return "worldbank" }
+
+// Match implements Adapter
+func (WorldBank) Match(u *url.URL) bool {
+	return hostIs(u.Hostname(), "data.worldbank.org") && indicatorPath.MatchString(u.Path)
+}
+
+// Extract implements Adapter
+func (WorldBank) Extract(ctx context.Context, req Request) ([]models.CandidateStatistic, error) {
+	apiURL, err := worldBankAPIURL(req.Result.URL)
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := req.Fetch(ctx, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch World Bank API: %w", err)
+	}
+	tables, err := extract.Parse(extract.FormatJSON, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse World Bank API response: %w", err)
+	}
+
+	t := tables[0]
+	col := make(map[string]int, len(t.Headers))
+	for i, h := range t.Headers {
+		col[h] = i
+	}
+	if _, ok := col["value"]; !ok {
+		return nil, nil
+	}
+	cell := func(row extract.Row, header string) string {
+		if i, ok := col[header]; ok && i < len(row.Cells) {
+			return row.Cells[i]
+		}
+		return ""
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = extract.DefaultMaxCandidates
+	}
+	var candidates []models.CandidateStatistic
+	for _, row := range t.Rows {
+		if len(candidates) >= limit {
+			break
+		}
+		value, ok := extract.ParseNumber(cell(row, "value"))
+		if !ok {
+			continue
+		}
+		indicator, country, date := cell(row, "indicator.value"), cell(row, "country.value"), cell(row, "date")
+		name := fmt.Sprintf("%s - %s (%s)", indicator, country, date)
+		candidates = append(candidates, models.CandidateStatistic{
+			Name:      name,
+			Value:     float32(value),
+			Unit:      worldBankUnit(indicator, cell(row, "unit")),
+			Source:    "World Bank",
+			SourceURL: apiURL,
+			Excerpt:   fmt.Sprintf("%s: %s", name, cell(row, "value")),
+			Provenance: &models.Provenance{
+				Format: string(extract.FormatJSON),
+				Row:    row.Number,
+				Column: "value",
+			},
+		})
+	}
+	return candidates, nil
+}
+
+// worldBankAPIURL maps an indicator page to the API request for the
+// locations it shows (the world aggregate when none are selected)
+func worldBankAPIURL(pageURL string) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	m := indicatorPath.FindStringSubmatch(u.Path)
+	if m == nil {
+		return "", fmt.Errorf("not a World Bank indicator page: %s", pageURL)
+	}
+
+	var locations []string
+	for _, loc := range strings.Split(u.Query().Get("locations"), "-") {
+		if locationCode.MatchString(loc) {
+			locations = append(locations, strings.ToUpper(loc))
+		}
+	}
+	if len(locations) == 0 {
+		locations = []string{"WLD"}
+	}
+
+	q := url.Values{}
+	q.Set("format", "json")
+	q.Set("mrv", fmt.Sprint(worldBankRecent))
+	q.Set("per_page", fmt.Sprint(worldBankRecent*len(locations)))
+	return fmt.Sprintf("%s/country/%s/indicator/%s?%s",
+		worldBankAPI, strings.Join(locations, ";"), url.PathEscape(m[1]), q.Encode()), nil
+}
+
+// worldBankUnit derives a unit from the indicator name, e.g.
+// "GDP (current US$)" or "Unemployment, total (% of total labor force)"
+func worldBankUnit(indicator, unit string) string {
+	switch {
+	case unit != "":
+		return unit
+	case strings.Contains(indicator, "(%"), strings.Contains(indicator, "%)"):
+		return "%"
+	case strings.Contains(indicator, "US$"):
+		return "US$"
+	}
+	return ""
+}
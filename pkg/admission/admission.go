@@ -0,0 +1,176 @@
+// Package admission bounds how many requests a handler runs at once. Requests
+// beyond the limit wait in a queue of fixed depth; once the queue is full,
+// or a request has waited too long, it is answered 429 Too Many Requests
+// with a Retry-After estimate instead of piling onto saturated workers
+// until the client times out.
+package admission
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+// defaultRunTime seeds the run-time estimate behind Retry-After until
+// requests have completed
+const defaultRunTime = 10 * time.Second
+
+// Controller admits at most a fixed number of concurrent requests
+type Controller struct {
+	slots   chan struct{}
+	depth   int
+	maxWait time.Duration
+
+	mu     sync.Mutex
+	queued int
+	avgRun time.Duration // Moving average of completed run times
+	stats  Stats
+}
+
+// Stats is the state of a controller
+type Stats struct {
+	Running  int `json:"running"`
+	Queued   int `json:"queued"`
+	Admitted int `json:"admitted"`
+	Rejected int `json:"rejected"`
+}
+
+// New creates a controller running at most concurrency requests, with up to
+// depth more waiting at most maxWait for a slot. It returns nil, which admits
+// everything, when concurrency is not positive.
+func New(concurrency, depth int, maxWait time.Duration) *Controller {
+	if concurrency <= 0 {
+		return nil
+	}
+	return &Controller{
+		slots:   make(chan struct{}, concurrency),
+		depth:   max(depth, 0),
+		maxWait: maxWait,
+		avgRun:  defaultRunTime,
+	}
+}
+
+// Orchestration creates the controller for the orchestration handlers,
+// configured by ORCHESTRATION_MAX_CONCURRENT and ORCHESTRATION_QUEUE_DEPTH
+func Orchestration(cfg *config.Config) *Controller {
+	return New(cfg.OrchestrationMaxConcurrent, cfg.OrchestrationQueueDepth, maxWait(cfg))
+}
+
+// Verification creates the controller for the verification handler,
+// configured by VERIFICATION_MAX_CONCURRENT and VERIFICATION_QUEUE_DEPTH
+func Verification(cfg *config.Config) *Controller {
+	return New(cfg.VerificationMaxConcurrent, cfg.VerificationQueueDepth, maxWait(cfg))
+}
+
+func maxWait(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.AdmissionMaxWaitSeconds) * time.Second
+}
+
+// Acquire waits for a slot. It returns a release function, or false and a
+// suggested retry delay when the request is rejected.
+func (c *Controller) Acquire(ctx context.Context) (func(), bool, time.Duration) {
+	if c == nil {
+		return func() {}, true, 0
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return c.admitted(), true, 0
+	default:
+	}
+
+	c.mu.Lock()
+	if c.queued >= c.depth {
+		c.stats.Rejected++
+		retry := c.retryAfterLocked()
+		c.mu.Unlock()
+		return nil, false, retry
+	}
+	c.queued++
+	c.mu.Unlock()
+
+	timer := time.NewTimer(c.maxWait)
+	defer timer.Stop()
+	select {
+	case c.slots <- struct{}{}:
+		c.dequeue(false)
+		return c.admitted(), true, 0
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return nil, false, c.dequeue(true)
+}
+
+// admitted counts a request that took a slot and returns its release
+func (c *Controller) admitted() func() {
+	start := time.Now()
+	c.mu.Lock()
+	c.stats.Admitted++
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-c.slots
+			c.mu.Lock()
+			// Weight recent runs so the estimate follows the current load
+			c.avgRun = (c.avgRun*4 + time.Since(start)) / 5
+			c.mu.Unlock()
+		})
+	}
+}
+
+// dequeue removes a waiting request, counting it as rejected if it gave up,
+// and returns the retry delay for a rejection
+func (c *Controller) dequeue(rejected bool) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queued--
+	if rejected {
+		c.stats.Rejected++
+	}
+	return c.retryAfterLocked()
+}
+
+// retryAfterLocked estimates when a slot will be free for a new request:
+// the average run time for each batch of requests ahead of it. The caller
+// must hold c.mu.
+func (c *Controller) retryAfterLocked() time.Duration {
+	batches := math.Ceil(float64(c.queued+1) / float64(cap(c.slots)))
+	return max(time.Duration(batches)*c.avgRun, time.Second)
+}
+
+// Stats returns the controller's current state
+func (c *Controller) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Running = len(c.slots)
+	s.Queued = c.queued
+	return s
+}
+
+// Wrap admits requests to next, answering 429 with Retry-After when the
+// controller is saturated. A nil controller returns next unchanged.
+func (c *Controller) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	if c == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		release, ok, retry := c.Acquire(r.Context())
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			http.Error(w, "server busy, retry later", http.StatusTooManyRequests)
+			return
+		}
+		defer release()
+		next(w, r)
+	}
+}
@@ -0,0 +1,79 @@
+package admission
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAcquireQueuesThenRejects(t *testing.T) {
+	c := New(1, 1, time.Second)
+	ctx := context.Background()
+
+	release, ok, _ := c.Acquire(ctx)
+	if !ok {
+		t.Fatal("first request rejected")
+	}
+
+	// The second request waits in the queue for the running one
+	admitted := make(chan bool)
+	go func() {
+		release2, ok, _ := c.Acquire(ctx)
+		if ok {
+			release2()
+		}
+		admitted <- ok
+	}()
+	for c.Stats().Queued == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// With the queue full, a third is turned away at once
+	if _, ok, retry := c.Acquire(ctx); ok || retry < time.Second {
+		t.Errorf("third request = %v, retry %v; want rejected", ok, retry)
+	}
+
+	release()
+	if !<-admitted {
+		t.Error("queued request rejected after a slot freed")
+	}
+	if s := c.Stats(); s.Admitted != 2 || s.Rejected != 1 || s.Running != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestAcquireGivesUpAfterMaxWait(t *testing.T) {
+	c := New(1, 5, 20*time.Millisecond)
+	release, _, _ := c.Acquire(context.Background())
+	defer release()
+	if _, ok, _ := c.Acquire(context.Background()); ok {
+		t.Error("expected rejection after waiting")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	c := New(1, 0, time.Second)
+	block := make(chan struct{})
+	started := make(chan struct{})
+	handler := c.Wrap(func(http.ResponseWriter, *http.Request) {
+		close(started)
+		<-block
+	})
+
+	go handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/verify", nil))
+	<-started
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/verify", nil))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "10" {
+		t.Errorf("busy response = %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	close(block)
+
+	var nilController *Controller
+	if nilController.Wrap(handler) == nil {
+		t.Error("nil controller dropped the handler")
+	}
+}
@@ -2,18 +2,27 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"sync"
 	"time"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/model"
 
+	"github.com/plexusone/agent-team-stats/pkg/archive"
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
 	"github.com/plexusone/agent-team-stats/pkg/llm"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
+	"github.com/plexusone/agent-team-stats/pkg/pagemeta"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/ratelimit"
+	"github.com/plexusone/agent-team-stats/pkg/search"
+	"github.com/plexusone/agent-team-stats/pkg/timing"
 )
 
 // BaseAgent provides common functionality for all agents
@@ -22,25 +31,55 @@ type BaseAgent struct {
 	Client       *http.Client
 	Model        model.LLM
 	ModelFactory *llm.ModelFactory
+	Stage        llm.Stage // Pipeline stage whose model is used; empty for the default model
+	Prompts      *prompts.Set
+	Fetches      *ratelimit.Limiter // Spaces page fetches per domain; nil for no limit
+	Documents    archive.Store      // Serves the document: URLs of documents sent with requests; nil serves none
+	SharePoint   *http.Client       // Downloads the files the sharepoint search provider finds; nil when it is not configured
+	Failures     *FailureCounter    // Counts failed page fetches by cause; nil counts none
 	Logger       *slog.Logger
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // NewBaseAgent creates a new base agent with LLM initialization
 func NewBaseAgent(ctx context.Context, cfg *config.Config, timeoutSec int) (*BaseAgent, error) {
+	return NewStageAgent(ctx, cfg, "", timeoutSec)
+}
+
+// NewStageAgent creates a base agent whose model is the one configured for
+// a pipeline stage (e.g. SYNTHESIS_MODEL), falling back to LLM_MODEL
+func NewStageAgent(ctx context.Context, cfg *config.Config, stage llm.Stage, timeoutSec int) (*BaseAgent, error) {
 	logger := logging.FromContext(ctx)
 
 	// Create model using factory
 	modelFactory := llm.NewModelFactory(ctx, cfg)
-	llmModel, err := modelFactory.CreateModel(ctx)
+	llmModel, err := modelFactory.CreateModelFor(ctx, stage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	promptSet, err := loadPrompts(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	fetches, err := ratelimit.FetchFromConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fetch rate limiter: %w", err)
+	}
+
 	return &BaseAgent{
 		Cfg:          cfg,
-		Client:       &http.Client{Timeout: time.Duration(timeoutSec) * time.Second},
+		Client:       httpclient.NewFetchClient(time.Duration(timeoutSec)*time.Second, cfg.FetchCredentials),
+		SharePoint:   search.NewSharePointClient(cfg, time.Duration(timeoutSec)*time.Second),
 		Model:        llmModel,
 		ModelFactory: modelFactory,
+		Stage:        stage,
+		Prompts:      promptSet,
+		Fetches:      fetches,
+		Failures:     NewFailureCounter(),
 		Logger:       logger,
 	}, nil
 }
@@ -57,56 +96,249 @@ func NewBaseAgentWithLogger(ctx context.Context, cfg *config.Config, timeoutSec
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	promptSet, err := loadPrompts(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	fetches, err := ratelimit.FetchFromConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fetch rate limiter: %w", err)
+	}
+
 	return &BaseAgent{
 		Cfg:          cfg,
-		Client:       &http.Client{Timeout: time.Duration(timeoutSec) * time.Second},
+		Client:       httpclient.NewFetchClient(time.Duration(timeoutSec)*time.Second, cfg.FetchCredentials),
+		SharePoint:   search.NewSharePointClient(cfg, time.Duration(timeoutSec)*time.Second),
 		Model:        llmModel,
 		ModelFactory: modelFactory,
+		Prompts:      promptSet,
+		Fetches:      fetches,
+		Failures:     NewFailureCounter(),
 		Logger:       logger,
 	}, nil
 }
 
 // GetProviderInfo returns information about the LLM provider
 func (ba *BaseAgent) GetProviderInfo() string {
-	return ba.ModelFactory.GetProviderInfo()
+	return ba.ModelFactory.GetStageInfo(ba.Stage)
+}
+
+// Reload swaps in the LLM credentials of a reloaded configuration without
+// interrupting requests in progress
+func (ba *BaseAgent) Reload(ctx context.Context, cfg *config.Config) error {
+	return ba.ModelFactory.Reload(ctx, cfg)
+}
+
+// Start serves HTTP on server until ctx ends or the process is signalled
+// to stop, then closes the agent. Observability hooks are set up when the
+// agent is created, so Start is the only call a main needs to make.
+func (ba *BaseAgent) Start(ctx context.Context, server *http.Server) error {
+	return Serve(ctx, server, ba.Logger, ba)
 }
 
-// Close cleans up resources including flushing observability data
+// Close cleans up resources including flushing observability data. Calls
+// after the first return its result.
 func (ba *BaseAgent) Close() error {
-	if ba.ModelFactory != nil {
-		return ba.ModelFactory.Close()
-	}
-	return nil
+	ba.closeOnce.Do(func() {
+		if ba.ModelFactory != nil {
+			ba.closeErr = ba.ModelFactory.Close()
+		}
+	})
+	return ba.closeErr
+}
+
+// Document is the raw body of a fetched URL along with its declared content
+// type and, for HTML pages, the publisher, publication date, and license
+// they declare
+type Document struct {
+	URL         string
+	ContentType string
+	Body        []byte
+
+	pagemeta.Metadata
 }
 
-// FetchURL fetches content from a URL with proper error handling
+// FetchURL fetches content from a URL with proper error handling.
+// maxSizeMB is as for FetchDocument.
 func (ba *BaseAgent) FetchURL(ctx context.Context, url string, maxSizeMB int) (string, error) {
+	doc, err := ba.FetchDocument(ctx, url, maxSizeMB)
+	if err != nil {
+		return "", err
+	}
+	return string(doc.Body), nil
+}
+
+// FetchDocument fetches a URL and returns its body together with the
+// Content-Type header, so callers can parse data files structurally. The
+// time taken is recorded as fetch time in the timing recorder carried by ctx.
+// A document: URL is read from the Documents archive.
+//
+// Only the media types FETCH_ALLOWED_TYPES lists are read; others fail with
+// ErrContentType. Pages are cut off at FETCH_MAX_PAGE_MB and larger
+// documents refused at FETCH_MAX_DOCUMENT_MB, unless maxSizeMB is positive
+// and replaces both. Text arrives as UTF-8 whatever its charset.
+func (ba *BaseAgent) FetchDocument(ctx context.Context, url string, maxSizeMB int) (*Document, error) {
+	defer timing.FromContext(ctx).Since(timing.Fetch, time.Now())
+
+	if hash, ok := archive.DocumentHash(url); ok {
+		return ba.document(ctx, url, hash)
+	}
+
+	if err := ba.Fetches.WaitURL(ctx, url); err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "StatsAgentTeam/1.0")
+	ba.identity().Apply(req)
+	ba.credentials().Apply(req)
 
-	resp, err := ba.Client.Do(req) //nolint:gosec // G704: URL provided by caller for web scraping
+	resp, err := ba.clientFor(ctx, url).Do(req) //nolint:gosec // G704: URL provided by caller for web scraping
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, ba.fetchFailed(url, fmt.Errorf("failed to fetch URL: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return nil, ba.fetchFailed(url, &StatusError{Code: resp.StatusCode, Status: resp.Status})
 	}
 
-	// Limit response size
-	maxBytes := int64(maxSizeMB * 1024 * 1024)
-	limitedReader := io.LimitReader(resp.Body, maxBytes)
-	body, err := io.ReadAll(limitedReader)
+	contentType, body, err := readBody(resp, url, ba.contentPolicy(), int64(maxSizeMB)<<20)
+	if err != nil {
+		return nil, ba.fetchFailed(url, err)
+	}
+
+	return NewDocument(url, contentType, body), nil
+}
+
+// document reads a document sent with a request from the archive. It is
+// served whole, as the request's size limits already bound it.
+func (ba *BaseAgent) document(ctx context.Context, url, hash string) (*Document, error) {
+	if ba.Documents == nil {
+		return nil, fmt.Errorf("documents need a snapshot archive (set ARCHIVE_BACKEND)")
+	}
+	snap, err := ba.Documents.Get(ctx, hash)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	return NewDocument(url, snap.ContentType, snap.Body), nil
+}
+
+// clientFor returns the client that fetches url: the SharePoint client for
+// a file the sharepoint provider found, when ctx may send credentials, so a
+// URL from a request cannot read files with the app's token, and Client for
+// the rest
+func (ba *BaseAgent) clientFor(ctx context.Context, url string) *http.Client {
+	if ba.SharePoint != nil && search.IsSharePointFile(url) && httpclient.CredentialsAllowed(ctx) {
+		return ba.SharePoint
+	}
+	return ba.Client
+}
+
+// identity returns how the agent's fetches present themselves to sites
+func (ba *BaseAgent) identity() *httpclient.Identity {
+	if ba.Cfg == nil {
+		return nil
+	}
+	return ba.Cfg.FetchIdentity
+}
+
+// contentPolicy returns the size limits and media types the agent's
+// fetches read
+func (ba *BaseAgent) contentPolicy() *httpclient.ContentPolicy {
+	if ba.Cfg == nil {
+		return nil
 	}
+	return ba.Cfg.FetchContent
+}
 
-	return string(body), nil
+// credentials returns what the agent's fetches send to sites that need
+// them
+func (ba *BaseAgent) credentials() httpclient.Credentials {
+	if ba.Cfg == nil {
+		return nil
+	}
+	return ba.Cfg.FetchCredentials
+}
+
+// NewDocument returns the document of a body fetched from url, reading the
+// publication metadata of HTML pages
+func NewDocument(url, contentType string, body []byte) *Document {
+	doc := &Document{
+		URL:         url,
+		ContentType: contentType,
+		Body:        body,
+	}
+	if isHTML(doc.ContentType) {
+		doc.Metadata = pagemeta.Parse(body)
+	}
+	return doc
+}
+
+// Probe checks that a URL is still served, with a HEAD request, or a GET
+// whose body is not read when the server refuses HEAD. The time taken is
+// recorded as fetch time like FetchDocument's.
+func (ba *BaseAgent) Probe(ctx context.Context, url string) error {
+	defer timing.FromContext(ctx).Since(timing.Fetch, time.Now())
+
+	if hash, ok := archive.DocumentHash(url); ok {
+		_, err := ba.document(ctx, url, hash)
+		return err
+	}
+
+	if err := ba.Fetches.WaitURL(ctx, url); err != nil {
+		return err
+	}
+
+	status := 0
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		ba.identity().Apply(req)
+		ba.credentials().Apply(req)
+
+		resp, err := ba.clientFor(ctx, url).Do(req) //nolint:gosec // G704: URL provided by caller for web scraping
+		if err != nil {
+			return ba.fetchFailed(url, fmt.Errorf("failed to fetch URL: %w", err))
+		}
+		resp.Body.Close()
+		status = resp.StatusCode
+		if status == http.StatusOK {
+			break
+		}
+	}
+
+	if status != http.StatusOK {
+		return ba.fetchFailed(url, &StatusError{Code: status, Status: http.StatusText(status)})
+	}
+	return nil
+}
+
+// fetchFailed counts a failed fetch of url by its cause and returns err.
+// Failures of the caller's own context, such as a cancelled request, say
+// nothing of the source and are not counted.
+func (ba *BaseAgent) fetchFailed(url string, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	cause := ClassifyFetchError(err)
+	ba.Failures.Record(url, cause)
+	if ba.Logger != nil {
+		ba.Logger.Debug("fetch failed", "url", url, "cause", cause, "error", err)
+	}
+	return err
+}
+
+// isHTML reports whether a content type is an HTML page or undeclared
+func isHTML(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml"
 }
 
 // Info logs an informational message
@@ -142,3 +374,16 @@ func NewAgentWrapper(base *BaseAgent, adkAgent agent.Agent) *AgentWrapper {
 		ADKAgent:  adkAgent,
 	}
 }
+
+// loadPrompts loads the prompt templates, logging versions when overrides
+// are configured
+func loadPrompts(cfg *config.Config, logger *slog.Logger) (*prompts.Set, error) {
+	promptSet, err := prompts.FromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+	if cfg.PromptsDir != "" {
+		logger.Info("prompt overrides loaded", "dir", cfg.PromptsDir, "versions", promptSet.Versions())
+	}
+	return promptSet, nil
+}
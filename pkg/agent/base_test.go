@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+func TestProbe(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		switch {
+		case r.URL.Path == "/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/no-head" && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	ba := &BaseAgent{Client: server.Client()}
+	ctx := context.Background()
+
+	if err := ba.Probe(ctx, server.URL+"/page"); err != nil || len(methods) != 1 {
+		t.Errorf("Probe(page) = %v with %v, want nil with one HEAD", err, methods)
+	}
+
+	methods = nil
+	if err := ba.Probe(ctx, server.URL+"/no-head"); err != nil || len(methods) != 2 || methods[1] != http.MethodGet {
+		t.Errorf("Probe(no-head) = %v with %v, want nil after a GET", err, methods)
+	}
+
+	if err := ba.Probe(ctx, server.URL+"/gone"); err == nil {
+		t.Error("Probe(gone) should fail")
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	page := NewDocument("https://example.com", "text/html", []byte(`<html><head><meta property="og:site_name" content="Example"></head></html>`))
+	if page.Publisher != "Example" {
+		t.Errorf("NewDocument(html).Publisher = %q", page.Publisher)
+	}
+	data := NewDocument("https://example.com/data.csv", "text/csv", []byte("a,b\n1,2\n"))
+	if data.Publisher != "" || string(data.Body) != "a,b\n1,2\n" {
+		t.Errorf("NewDocument(csv) = %+v", data)
+	}
+}
+
+func TestFetchDocumentFromArchive(t *testing.T) {
+	ctx := context.Background()
+	store, err := archive.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := archive.NewSnapshot("report.csv", "text/csv", []byte("a,b\n1,2\n"))
+	if _, err := store.Put(ctx, snap); err != nil {
+		t.Fatal(err)
+	}
+	url := archive.DocumentURL(snap.Hash)
+
+	if _, err := (&BaseAgent{}).FetchDocument(ctx, url, 1); err == nil {
+		t.Error("FetchDocument(document) without an archive should fail")
+	}
+	ba := &BaseAgent{Documents: store}
+	doc, err := ba.FetchDocument(ctx, url, 1)
+	if err != nil || doc.URL != url || doc.ContentType != "text/csv" || string(doc.Body) != "a,b\n1,2\n" {
+		t.Errorf("FetchDocument(document) = %+v, %v", doc, err)
+	}
+	if err := ba.Probe(ctx, archive.DocumentURL(archive.Hash([]byte("missing")))); err == nil {
+		t.Error("Probe(missing document) should fail")
+	}
+}
+
+func TestClientForSharePointFile(t *testing.T) {
+	sharePoint := &http.Client{}
+	ba := &BaseAgent{Client: &http.Client{}, SharePoint: sharePoint}
+	file := "https://graph.microsoft.com/v1.0/drives/drive-1/items/item-1/content"
+	trusted := httpclient.WithCredentials(context.Background())
+
+	if ba.clientFor(trusted, file) != sharePoint {
+		t.Error("a SharePoint file from internal search should download with the SharePoint client")
+	}
+	if ba.clientFor(context.Background(), file) == sharePoint {
+		t.Error("a SharePoint file URL from a request must not get the app's token")
+	}
+	if ba.clientFor(trusted, "https://example.com/report") == sharePoint {
+		t.Error("other pages should use the fetch client")
+	}
+}
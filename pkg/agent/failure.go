@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// ErrTooLarge is returned for a document or data file over its size limit
+var ErrTooLarge = errors.New("too large")
+
+// StatusError is returned for a response other than 200 OK
+type StatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.Code, e.Status)
+}
+
+// ClassifyFetchError returns why a fetch failed: a dead link (the host does
+// not resolve, or the page is missing or forbidden), a failing site, or
+// network trouble on the way to it
+func ClassifyFetchError(err error) models.FetchFailure {
+	var status *StatusError
+	if errors.As(err, &status) {
+		if status.Code >= 500 {
+			return models.FetchServerError
+		}
+		return models.FetchClientError
+	}
+	if errors.Is(err, ErrContentType) || errors.Is(err, ErrTooLarge) || errors.Is(err, httpclient.ErrNotPublic) {
+		return models.FetchRefused
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		switch {
+		case dnsErr.IsNotFound:
+			return models.FetchDNS
+		case dnsErr.IsTimeout:
+			return models.FetchTimeout
+		}
+		return models.FetchNetwork
+	}
+
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+	)
+	if errors.As(err, &verifyErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr) || errors.As(err, &recordErr) || errors.As(err, &alertErr) {
+		return models.FetchTLS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return models.FetchTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return models.FetchNetwork
+	}
+	return models.FetchOther
+}
+
+// maxFailureDomains bounds the domains a FailureCounter keeps apart; the
+// failures of others count toward the totals only
+const maxFailureDomains = 500
+
+// FailureCounter counts failed fetches by cause and domain since the agent
+// started. It is safe for concurrent use; a nil FailureCounter counts
+// nothing.
+type FailureCounter struct {
+	mu       sync.Mutex
+	since    time.Time
+	total    int
+	byCause  map[models.FetchFailure]int
+	byDomain map[string]*models.DomainFetchFailures
+}
+
+// NewFailureCounter creates an empty counter
+func NewFailureCounter() *FailureCounter {
+	return &FailureCounter{
+		since:    time.Now(),
+		byCause:  make(map[models.FetchFailure]int),
+		byDomain: make(map[string]*models.DomainFetchFailures),
+	}
+}
+
+// Record counts a failed fetch of rawURL
+func (c *FailureCounter) Record(rawURL string, cause models.FetchFailure) {
+	if c == nil {
+		return
+	}
+	domain := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		domain = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.total++
+	c.byCause[cause]++
+	d, ok := c.byDomain[domain]
+	if !ok {
+		if len(c.byDomain) >= maxFailureDomains {
+			return
+		}
+		d = &models.DomainFetchFailures{Domain: domain, ByCause: make(map[models.FetchFailure]int)}
+		c.byDomain[domain] = d
+	}
+	d.Total++
+	d.ByCause[cause]++
+}
+
+// Snapshot returns the counts so far, domains with the most failures first
+func (c *FailureCounter) Snapshot() models.FetchFailureStats {
+	if c == nil {
+		return models.FetchFailureStats{ByCause: map[models.FetchFailure]int{}, Domains: []models.DomainFetchFailures{}}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := models.FetchFailureStats{
+		Since:   c.since,
+		Total:   c.total,
+		ByCause: make(map[models.FetchFailure]int, len(c.byCause)),
+		Domains: make([]models.DomainFetchFailures, 0, len(c.byDomain)),
+	}
+	for cause, n := range c.byCause {
+		stats.ByCause[cause] = n
+	}
+	for _, d := range c.byDomain {
+		byCause := make(map[models.FetchFailure]int, len(d.ByCause))
+		for cause, n := range d.ByCause {
+			byCause[cause] = n
+		}
+		stats.Domains = append(stats.Domains, models.DomainFetchFailures{Domain: d.Domain, Total: d.Total, ByCause: byCause})
+	}
+	sort.Slice(stats.Domains, func(i, j int) bool {
+		if stats.Domains[i].Total != stats.Domains[j].Total {
+			return stats.Domains[i].Total > stats.Domains[j].Total
+		}
+		return stats.Domains[i].Domain < stats.Domains[j].Domain
+	})
+	return stats
+}
+
+// HandleFetchFailures serves the agent's failed fetch counts as JSON
+func (ba *BaseAgent) HandleFetchFailures(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ba.Failures.Snapshot()); err != nil && ba.Logger != nil {
+		ba.Logger.Error("failed to encode fetch failures", "error", err)
+	}
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestClassifyFetchError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want models.FetchFailure
+	}{
+		{"not found", &StatusError{Code: 404, Status: "404 Not Found"}, models.FetchClientError},
+		{"unavailable", &StatusError{Code: 503, Status: "503 Service Unavailable"}, models.FetchServerError},
+		{"no such host", fmt.Errorf("failed to fetch URL: %w", &net.DNSError{Err: "no such host", Name: "gone.example", IsNotFound: true}), models.FetchDNS},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, models.FetchTimeout},
+		{"dns server", &net.DNSError{Err: "server misbehaving", IsTemporary: true}, models.FetchNetwork},
+		{"certificate", fmt.Errorf("failed to fetch URL: %w", x509.UnknownAuthorityError{}), models.FetchTLS},
+		{"deadline", fmt.Errorf("failed to fetch URL: %w", context.DeadlineExceeded), models.FetchTimeout},
+		{"refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, models.FetchNetwork},
+		{"content type", fmt.Errorf("%w: video/mp4", ErrContentType), models.FetchRefused},
+		{"too large", fmt.Errorf("%w: application/pdf is larger than 10 MB", ErrTooLarge), models.FetchRefused},
+		{"other", errors.New("unexpected"), models.FetchOther},
+	}
+	for _, tt := range tests {
+		if got := ClassifyFetchError(tt.err); got != tt.want {
+			t.Errorf("ClassifyFetchError(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetchFailuresCounted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	ba := &BaseAgent{Client: server.Client(), Failures: NewFailureCounter()}
+	_, err := ba.FetchDocument(context.Background(), server.URL+"/gone", 0)
+	var status *StatusError
+	if !errors.As(err, &status) || status.Code != http.StatusNotFound {
+		t.Fatalf("FetchDocument() error = %v, want a 404 StatusError", err)
+	}
+	ba.Failures.Record("https://www.census.gov/a", models.FetchTimeout)
+	ba.Failures.Record("https://census.gov/b", models.FetchTimeout)
+
+	stats := ba.Failures.Snapshot()
+	if stats.Total != 3 || stats.ByCause[models.FetchClientError] != 1 || stats.ByCause[models.FetchTimeout] != 2 {
+		t.Errorf("Snapshot() = %+v", stats)
+	}
+	if len(stats.Domains) != 2 || stats.Domains[0].Domain != "census.gov" || stats.Domains[0].Total != 2 {
+		t.Errorf("Snapshot().Domains = %+v, want census.gov first with 2", stats.Domains)
+	}
+}
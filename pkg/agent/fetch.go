@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+// ErrContentType is returned for a response whose media type page fetches
+// do not read; its body is left unread
+var ErrContentType = errors.New("content type not accepted")
+
+// sniffLen is how much of a body without a declared type is sniffed
+const sniffLen = 512
+
+// readBody reads a fetched response within the content policy. A page is
+// cut off at its size limit; a document or data file, which is useless
+// truncated, is refused beyond its limit, before its body is read when it
+// declares its length. maxBytes, when positive, replaces both limits. Text
+// is transcoded to UTF-8, and the returned content type says so.
+func readBody(resp *http.Response, url string, policy *httpclient.ContentPolicy, maxBytes int64) (string, []byte, error) {
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	body := bufio.NewReaderSize(resp.Body, sniffLen)
+	if mediaType == "" {
+		// Undeclared bodies are read as pages unless they sniff as binary
+		sniffed, _ := body.Peek(sniffLen)
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(sniffed))
+	}
+	if !accepts(policy, mediaType, url) {
+		return "", nil, fmt.Errorf("%w: %s", ErrContentType, mediaType)
+	}
+
+	page := isText(mediaType) && extract.DetectFormat(contentType, url) == extract.FormatHTML
+	limit := policy.DocumentBytes()
+	if page {
+		limit = policy.PageBytes()
+	}
+	if maxBytes > 0 {
+		limit = maxBytes
+	}
+	if !page && resp.ContentLength > limit {
+		return "", nil, fmt.Errorf("%w: %s is larger than %d MB", ErrTooLarge, mediaType, limit>>20)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(data)) > limit {
+		if !page {
+			return "", nil, fmt.Errorf("%w: %s is larger than %d MB", ErrTooLarge, mediaType, limit>>20)
+		}
+		data = data[:limit]
+	}
+
+	if isText(mediaType) {
+		data, contentType = toUTF8(data, contentType)
+	}
+	return contentType, data, nil
+}
+
+// accepts reports whether the policy reads a media type. A generic binary
+// type is read when the URL names a document or data file, as servers send
+// downloads that way.
+func accepts(policy *httpclient.ContentPolicy, mediaType, url string) bool {
+	if policy.Allows(mediaType) {
+		return true
+	}
+	if mediaType == "application/octet-stream" {
+		format := extract.DetectFormat("", url)
+		return format != extract.FormatHTML && policy.Allows(format.MediaType())
+	}
+	return false
+}
+
+// isText reports whether a media type is text, which may need transcoding
+func isText(mediaType string) bool {
+	return mediaType == "" || strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/xhtml+xml" || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// toUTF8 transcodes text in the charset its content type, byte order mark,
+// or <meta> tag declares to UTF-8, so excerpts match whatever the source's
+// encoding. Undeclared text that is valid UTF-8 is kept; other undeclared
+// text is read as Windows-1252, as browsers do.
+func toUTF8(body []byte, contentType string) ([]byte, string) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" || (!certain && utf8.Valid(body)) {
+		return body, contentType
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, contentType
+	}
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil {
+		params["charset"] = "utf-8"
+		contentType = mime.FormatMediaType(mediaType, params)
+	}
+	return decoded, contentType
+}
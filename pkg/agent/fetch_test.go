@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+func TestFetchDocumentContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/video":
+			w.Header().Set("Content-Type", "video/mp4")
+			_, _ = w.Write(make([]byte, 64<<10))
+		case "/latin1":
+			w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+			_, _ = w.Write([]byte("<p>Part de march\xe9 : 12 %</p>"))
+		case "/meta":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><meta charset="windows-1252"></head><body>Caf` + "\xe9 sales rose 4\x96 5%</body></html>"))
+		case "/utf8":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(strings.Repeat("a", 2000) + "Müller"))
+		case "/big.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write(make([]byte, 2<<20))
+		case "/big.pdf", "/files/report.pdf":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			size := 2 << 20
+			if r.URL.Path == "/files/report.pdf" {
+				size = 1024
+			}
+			_, _ = w.Write(make([]byte, size))
+		case "/setup.exe":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte("MZ"))
+		}
+	}))
+	defer server.Close()
+
+	policy, err := httpclient.NewContentPolicy(1, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ba := &BaseAgent{Client: server.Client(), Cfg: &config.Config{FetchContent: policy}}
+	ctx := context.Background()
+
+	if _, err := ba.FetchDocument(ctx, server.URL+"/video", 0); !errors.Is(err, ErrContentType) {
+		t.Errorf("FetchDocument(video) = %v, want ErrContentType", err)
+	}
+	if _, err := ba.FetchDocument(ctx, server.URL+"/setup.exe", 0); !errors.Is(err, ErrContentType) {
+		t.Errorf("FetchDocument(exe) = %v, want ErrContentType", err)
+	}
+
+	doc, err := ba.FetchDocument(ctx, server.URL+"/latin1", 0)
+	if err != nil || string(doc.Body) != "<p>Part de marché : 12 %</p>" || doc.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("FetchDocument(latin1) = %q, %q, %v", doc.Body, doc.ContentType, err)
+	}
+	doc, err = ba.FetchDocument(ctx, server.URL+"/meta", 0)
+	if err != nil || !strings.Contains(string(doc.Body), "Café sales rose 4– 5%") {
+		t.Errorf("FetchDocument(meta charset) = %q, %v", doc.Body, err)
+	}
+	doc, err = ba.FetchDocument(ctx, server.URL+"/utf8", 0)
+	if err != nil || !strings.HasSuffix(string(doc.Body), "Müller") {
+		t.Errorf("FetchDocument(undeclared utf-8) = %q, %v", doc.Body[2000:], err)
+	}
+
+	doc, err = ba.FetchDocument(ctx, server.URL+"/big.html", 0)
+	if err != nil || len(doc.Body) != 1<<20 {
+		t.Errorf("FetchDocument(big page) = %d bytes, %v, want cut off at 1 MB", len(doc.Body), err)
+	}
+	if _, err := ba.FetchDocument(ctx, server.URL+"/big.pdf", 0); err == nil || !strings.Contains(err.Error(), "larger than 1 MB") {
+		t.Errorf("FetchDocument(big pdf) = %v, want refused", err)
+	}
+	doc, err = ba.FetchDocument(ctx, server.URL+"/files/report.pdf", 0)
+	if err != nil || len(doc.Body) != 1024 {
+		t.Errorf("FetchDocument(pdf download) = %v", err)
+	}
+}
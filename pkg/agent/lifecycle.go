@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout bounds how long requests in progress may finish after a
+// shutdown signal
+const shutdownTimeout = 5 * time.Second
+
+// Serve runs server until ctx ends or the process receives SIGINT or
+// SIGTERM, then shuts it down gracefully and closes each closer, so agents
+// flush buffered observability traces before exiting. It returns the
+// server's error, if it failed to start or shut down.
+func Serve(ctx context.Context, server *http.Server, logger *slog.Logger, closers ...io.Closer) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		logger.Info("shutting down gracefully...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err = server.Shutdown(shutdownCtx)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	for _, c := range closers {
+		if cerr := c.Close(); cerr != nil {
+			logger.Error("failed to close", "error", cerr)
+		}
+	}
+	if err == nil {
+		logger.Info("shutdown complete")
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type closeCounter struct{ n int }
+
+func (c *closeCounter) Close() error {
+	c.n++
+	return nil
+}
+
+func TestServeClosesOnShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &http.Server{Addr: "127.0.0.1:0"}
+	closer := &closeCounter{}
+
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, server, slog.Default(), closer) }()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Serve() = %v", err)
+		}
+	case <-time.After(shutdownTimeout + time.Second):
+		t.Fatal("Serve() did not return after cancel")
+	}
+	if closer.n != 1 {
+		t.Errorf("closer called %d times, want 1", closer.n)
+	}
+}
+
+func TestBaseAgentCloseOnce(t *testing.T) {
+	ba := &BaseAgent{}
+	if err := ba.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := ba.Close(); err != nil {
+		t.Fatalf("second Close() = %v", err)
+	}
+}
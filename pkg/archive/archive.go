@@ -0,0 +1,129 @@
+// Package archive stores content-addressed snapshots of verified source pages,
+// so disputes about a statistic can be resolved against the exact page version
+// that was verified. Snapshots are keyed by the SHA-256 hash of the page body
+// and stored gzip-compressed alongside a small JSON metadata record.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+// ErrNotFound is returned when no snapshot exists for a hash
+var ErrNotFound = errors.New("snapshot not found")
+
+// hashPrefix identifies the hash algorithm in content hashes
+const hashPrefix = "sha256:"
+
+// Snapshot is an archived copy of a fetched source
+type Snapshot struct {
+	Hash        string    `json:"hash"` // "sha256:<hex>"
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	Size        int       `json:"size"` // Uncompressed body size in bytes
+	FetchedAt   time.Time `json:"fetched_at"`
+	Body        []byte    `json:"-"`
+}
+
+// Store persists snapshots
+type Store interface {
+	// Put stores the snapshot and returns its location. Storing a hash that
+	// already exists is a no-op.
+	Put(ctx context.Context, snap *Snapshot) (string, error)
+	// Get loads the snapshot with the given hash, or returns ErrNotFound.
+	Get(ctx context.Context, hash string) (*Snapshot, error)
+}
+
+// Hash returns the content hash of a body in "sha256:<hex>" form
+func Hash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// documentScheme prefixes the URL of a document read from the archive rather
+// than the web, such as one sent with a request: "document:sha256:<hex>"
+const documentScheme = "document:"
+
+// DocumentURL returns the URL the agents read the document with a hash at
+func DocumentURL(hash string) string {
+	return documentScheme + hash
+}
+
+// DocumentHash returns the content hash a document URL names. The second
+// result is false for any other URL.
+func DocumentHash(rawURL string) (string, bool) {
+	hash, ok := strings.CutPrefix(rawURL, documentScheme)
+	return hash, ok && strings.HasPrefix(hash, hashPrefix)
+}
+
+// NewSnapshot creates a snapshot of a fetched body
+func NewSnapshot(url, contentType string, body []byte) *Snapshot {
+	return &Snapshot{
+		Hash:        Hash(body),
+		URL:         url,
+		ContentType: contentType,
+		Size:        len(body),
+		FetchedAt:   time.Now(),
+		Body:        body,
+	}
+}
+
+// New creates the store selected by cfg.ArchiveBackend. It returns a nil
+// store when archival is disabled.
+func New(ctx context.Context, cfg *config.Config) (Store, error) {
+	switch cfg.ArchiveBackend {
+	case "", "none":
+		return nil, nil
+	case "local":
+		return NewLocalStore(cfg.ArchiveDir)
+	case "s3":
+		return NewS3Store(ctx, cfg.ArchiveS3Bucket, cfg.ArchiveS3Prefix)
+	default:
+		return nil, fmt.Errorf("unsupported archive backend: %s (supported: none, local, s3)", cfg.ArchiveBackend)
+	}
+}
+
+// objectKey returns the storage key for a hash, sharded by the first two hex digits
+func objectKey(hash, ext string) (string, error) {
+	hexHash, ok := strings.CutPrefix(hash, hashPrefix)
+	if !ok || len(hexHash) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid content hash: %q", hash)
+	}
+	if _, err := hex.DecodeString(hexHash); err != nil {
+		return "", fmt.Errorf("invalid content hash: %q", hash)
+	}
+	return hexHash[:2] + "/" + hexHash + ext, nil
+}
+
+// compress gzips a body
+func compress(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress gunzips a body
+func decompress(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
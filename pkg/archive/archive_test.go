@@ -0,0 +1,69 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	got := Hash([]byte("abc"))
+	want := "sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got != want {
+		t.Errorf("Hash() = %q, want %q", got, want)
+	}
+}
+
+func TestDocumentURL(t *testing.T) {
+	hash := Hash([]byte("abc"))
+	got, ok := DocumentHash(DocumentURL(hash))
+	if !ok || got != hash {
+		t.Errorf("DocumentHash(DocumentURL()) = %q, %v, want %q", got, ok, hash)
+	}
+	for _, u := range []string{"https://example.com/" + hash, "document:md5:abc"} {
+		if _, ok := DocumentHash(u); ok {
+			t.Errorf("DocumentHash(%q) is a document", u)
+		}
+	}
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+
+	body := []byte(strings.Repeat("<p>Unemployment was 3.9% in 2024.</p>", 100))
+	snap := NewSnapshot("https://example.com/report", "text/html", body)
+
+	location, err := store.Put(ctx, snap)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if !strings.HasSuffix(location, ".gz") {
+		t.Errorf("Put() location = %q, want .gz file", location)
+	}
+	if again, err := store.Put(ctx, snap); err != nil || again != location {
+		t.Errorf("second Put() = %q, %v; want %q, nil", again, err, location)
+	}
+
+	got, err := store.Get(ctx, snap.Hash)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(got.Body) != string(body) {
+		t.Error("Get() body does not match archived body")
+	}
+	if got.URL != snap.URL || got.ContentType != snap.ContentType || got.Size != len(body) {
+		t.Errorf("Get() metadata = %+v", got)
+	}
+
+	if _, err := store.Get(ctx, Hash([]byte("missing"))); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() missing error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "sha256:../../etc"); err == nil {
+		t.Error("Get() accepted invalid hash")
+	}
+}
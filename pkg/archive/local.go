@@ -0,0 +1,97 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore stores snapshots on the local filesystem
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore creates a filesystem store rooted at dir
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("archive directory is required for the local backend")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// Put writes the compressed body and metadata for a snapshot
+func (s *LocalStore) Put(_ context.Context, snap *Snapshot) (string, error) {
+	bodyKey, err := objectKey(snap.Hash, ".gz")
+	if err != nil {
+		return "", err
+	}
+	metaKey, _ := objectKey(snap.Hash, ".json")
+	bodyPath := filepath.Join(s.dir, filepath.FromSlash(bodyKey))
+
+	if _, err := os.Stat(bodyPath); err == nil {
+		return bodyPath, nil // Content-addressed: already archived
+	}
+
+	if err := os.MkdirAll(filepath.Dir(bodyPath), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	compressed, err := compress(snap.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	meta, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot metadata: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(s.dir, filepath.FromSlash(metaKey)), meta, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write snapshot metadata: %w", err)
+	}
+	if err := os.WriteFile(bodyPath, compressed, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return bodyPath, nil
+}
+
+// Get reads a snapshot and its metadata from disk
+func (s *LocalStore) Get(_ context.Context, hash string) (*Snapshot, error) {
+	bodyKey, err := objectKey(hash, ".gz")
+	if err != nil {
+		return nil, err
+	}
+	metaKey, _ := objectKey(hash, ".json")
+
+	meta, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(metaKey)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot metadata: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(meta, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot metadata: %w", err)
+	}
+
+	compressed, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(bodyKey)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if snap.Body, err = decompress(compressed); err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+
+	return &snap, nil
+}
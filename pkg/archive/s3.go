@@ -0,0 +1,133 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Store stores snapshots in an S3 bucket
+type S3Store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Store creates an S3 store using the default AWS credential chain
+func NewS3Store(ctx context.Context, bucket, prefix string) (*S3Store, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("ARCHIVE_S3_BUCKET is required for the s3 backend")
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &S3Store{
+		client: s3.NewFromConfig(awsCfg),
+		bucket: bucket,
+		prefix: prefix,
+	}, nil
+}
+
+// Put uploads the compressed body and metadata for a snapshot
+func (s *S3Store) Put(ctx context.Context, snap *Snapshot) (string, error) {
+	bodyKey, err := objectKey(snap.Hash, ".gz")
+	if err != nil {
+		return "", err
+	}
+	metaKey, _ := objectKey(snap.Hash, ".json")
+	bodyKey = path.Join(s.prefix, bodyKey)
+	metaKey = path.Join(s.prefix, metaKey)
+	location := fmt.Sprintf("s3://%s/%s", s.bucket, bodyKey)
+
+	// Content-addressed: skip the upload if the object already exists
+	if _, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(bodyKey),
+	}); err == nil {
+		return location, nil
+	}
+
+	compressed, err := compress(snap.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	meta, err := json.Marshal(snap)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshot metadata: %w", err)
+	}
+
+	if _, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(metaKey),
+		Body:        bytes.NewReader(meta),
+		ContentType: aws.String("application/json"),
+	}); err != nil {
+		return "", fmt.Errorf("failed to upload snapshot metadata: %w", err)
+	}
+	if _, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(bodyKey),
+		Body:            bytes.NewReader(compressed),
+		ContentType:     aws.String(snap.ContentType),
+		ContentEncoding: aws.String("gzip"),
+	}); err != nil {
+		return "", fmt.Errorf("failed to upload snapshot: %w", err)
+	}
+
+	return location, nil
+}
+
+// Get downloads a snapshot and its metadata
+func (s *S3Store) Get(ctx context.Context, hash string) (*Snapshot, error) {
+	bodyKey, err := objectKey(hash, ".gz")
+	if err != nil {
+		return nil, err
+	}
+	metaKey, _ := objectKey(hash, ".json")
+
+	meta, err := s.getObject(ctx, path.Join(s.prefix, metaKey))
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(meta, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot metadata: %w", err)
+	}
+
+	compressed, err := s.getObject(ctx, path.Join(s.prefix, bodyKey))
+	if err != nil {
+		return nil, err
+	}
+	if snap.Body, err = decompress(compressed); err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+
+	return &snap, nil
+}
+
+// getObject reads an object's bytes, mapping missing keys to ErrNotFound
+func (s *S3Store) getObject(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noKey *types.NoSuchKey
+		if errors.As(err, &noKey) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
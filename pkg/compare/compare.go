@@ -0,0 +1,212 @@
+// Package compare implements comparison mode: the same statistic gathered for
+// several entities or periods (e.g. "internet penetration" for 2010 and 2020)
+// by running one orchestration per entity and aligning the results.
+package compare
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/timing"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
+)
+
+// OrchestrateFunc runs a single-topic orchestration
+type OrchestrateFunc func(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error)
+
+// Requests returns the single-topic request run for each entity in
+// req.Compare. The statistics and candidate budgets are split evenly across
+// entities.
+func Requests(req *models.OrchestrationRequest) []models.OrchestrationRequest {
+	reqs := make([]models.OrchestrationRequest, len(req.Compare))
+	for i, entity := range req.Compare {
+		// Copy the request so per-run settings such as model overrides carry over
+		reqs[i] = *req
+		reqs[i].Topic = req.Topic + " " + entity
+		reqs[i].MinVerifiedStats = share(req.MinVerifiedStats, len(req.Compare))
+		reqs[i].MaxCandidates = share(req.MaxCandidates, len(req.Compare))
+		reqs[i].Compare = nil
+	}
+	return reqs
+}
+
+// Run orchestrates one search per entity in req.Compare, concurrently, and
+// merges them into a single response with an aligned Comparison. The
+// statistics and candidate budgets are split evenly across entities. Stage
+// timings are summed across the searches, so with several entities they can
+// add up to more than the total time.
+func Run(ctx context.Context, req *models.OrchestrationRequest, orchestrate OrchestrateFunc) (*models.OrchestrationResponse, error) {
+	entities := req.Compare
+	responses := make([]*models.OrchestrationResponse, len(entities))
+	errs := make([]error, len(entities))
+	timer := timing.New()
+
+	var wg sync.WaitGroup
+	for i, entityReq := range Requests(req) {
+		wg.Go(func() {
+			responses[i], errs[i] = orchestrate(ctx, &entityReq)
+		})
+	}
+	wg.Wait()
+
+	merged := &models.OrchestrationResponse{
+		Topic:       req.Topic,
+		Statistics:  []models.Statistic{},
+		Timestamp:   time.Now(),
+		TargetCount: req.MinVerifiedStats,
+	}
+	perEntity := make([][]models.Statistic, len(entities))
+	seen := make(map[string]bool)
+	noSources := true
+	tracker := usage.NewTracker("")
+
+	for i, resp := range responses {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("comparison search for %q failed: %w", entities[i], errs[i])
+		}
+		perEntity[i] = resp.Statistics
+		merged.TotalCandidates += resp.TotalCandidates
+		merged.FailedCount += resp.FailedCount
+		merged.Rejected = append(merged.Rejected, resp.Rejected...)
+		merged.Sources = append(merged.Sources, resp.Sources...)
+		merged.Conflicts = append(merged.Conflicts, resp.Conflicts...)
+		merged.Series = append(merged.Series, resp.Series...)
+		merged.Partial = merged.Partial || resp.Partial
+		noSources = noSources && resp.Status == models.StatusNoResults
+		tracker.Merge(resp.CostSummary)
+		timer.Merge(resp.Timings)
+
+		for _, stat := range resp.Statistics {
+			key := fmt.Sprintf("%s|%s|%v", stat.SourceURL, stat.Name, stat.Value)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged.Statistics = append(merged.Statistics, stat)
+		}
+	}
+	merged.VerifiedCount = len(merged.Statistics)
+	switch {
+	case noSources:
+		merged.Status = models.StatusNoResults
+	case merged.Partial:
+		merged.Status = models.StatusPartial
+	default:
+		merged.Status = models.StatusComplete
+	}
+	merged.Comparison = Align(req.Topic, entities, perEntity)
+	merged.CostSummary = tracker.Summary()
+	merged.Timings = timer.Timings()
+
+	return merged, nil
+}
+
+// Align picks one comparable statistic per entity. The comparison unit is the
+// one found for the most entities; within it, statistics that mention the
+// entity and share the most words with the metric are preferred.
+func Align(metric string, entities []string, perEntity [][]models.Statistic) *models.Comparison {
+	unit := commonUnit(entities, perEntity)
+	terms := strings.Fields(strings.ToLower(metric))
+
+	comparison := &models.Comparison{
+		Metric:  metric,
+		Unit:    unit,
+		Entries: make([]models.ComparisonEntry, len(entities)),
+	}
+
+	for i, entity := range entities {
+		comparison.Entries[i].Entity = entity
+
+		bestScore := -1
+		for j := range perEntity[i] {
+			stat := &perEntity[i][j]
+			if normalizeUnit(stat.Unit) != unit {
+				continue
+			}
+			if score := relevance(stat, entity, terms); score > bestScore {
+				bestScore = score
+				comparison.Entries[i].Statistic = stat
+			}
+		}
+	}
+
+	return comparison
+}
+
+// commonUnit returns the normalized unit present for the most entities,
+// preferring statistics that mention their entity
+func commonUnit(entities []string, perEntity [][]models.Statistic) string {
+	counts := make(map[string]int)
+	var order []string
+
+	for i, entity := range entities {
+		unitsSeen := make(map[string]bool)
+		for _, stat := range perEntity[i] {
+			unit := normalizeUnit(stat.Unit)
+			if unitsSeen[unit] {
+				continue
+			}
+			unitsSeen[unit] = true
+			if _, ok := counts[unit]; !ok {
+				order = append(order, unit)
+			}
+			counts[unit] += 2
+			if mentions(stat, entity) {
+				counts[unit]++
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	for _, unit := range order {
+		if counts[unit] > bestCount {
+			best, bestCount = unit, counts[unit]
+		}
+	}
+	return best
+}
+
+// relevance scores how well a statistic matches an entity and metric
+func relevance(stat *models.Statistic, entity string, metricTerms []string) int {
+	score := 0
+	if mentions(*stat, entity) {
+		score += len(metricTerms) + 1 // Entity match outweighs any term overlap
+	}
+	name := strings.ToLower(stat.Name)
+	for _, term := range metricTerms {
+		if strings.Contains(name, term) {
+			score++
+		}
+	}
+	return score
+}
+
+// mentions reports whether a statistic's name or excerpt names the entity
+func mentions(stat models.Statistic, entity string) bool {
+	entity = strings.ToLower(entity)
+	return strings.Contains(strings.ToLower(stat.Name), entity) ||
+		strings.Contains(strings.ToLower(stat.Excerpt), entity)
+}
+
+// normalizeUnit folds common spellings of the same unit
+func normalizeUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	switch unit {
+	case "percent", "percentage", "pct":
+		return "%"
+	}
+	return unit
+}
+
+// share splits a budget across n entities, rounding up. Zero stays zero so
+// the orchestrator applies its own default.
+func share(total, n int) int {
+	if total <= 0 {
+		return 0
+	}
+	return max(1, (total+n-1)/n)
+}
@@ -0,0 +1,72 @@
+package compare
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestAlign(t *testing.T) {
+	perEntity := [][]models.Statistic{
+		{
+			{Name: "Internet users worldwide", Value: 2.0, Unit: "billion", Excerpt: "2 billion people were online in 2010"},
+			{Name: "Internet penetration rate", Value: 29, Unit: "%", Excerpt: "In 2010, 29% of the world used the internet"},
+		},
+		{
+			{Name: "Internet penetration rate", Value: 54, Unit: "percent", Excerpt: "54% of people were online in 2020"},
+			{Name: "Mobile subscriptions", Value: 8, Unit: "billion", Excerpt: "8 billion subscriptions"},
+		},
+		{
+			{Name: "Broadband speed", Value: 100, Unit: "Mbps", Excerpt: "average speed in 2024"},
+		},
+	}
+
+	got := Align("internet penetration", []string{"2010", "2020", "2024"}, perEntity)
+
+	if got.Unit != "%" {
+		t.Fatalf("Align() unit = %q, want %%", got.Unit)
+	}
+	if s := got.Entries[0].Statistic; s == nil || s.Value != 29 {
+		t.Errorf("Align() 2010 = %+v, want 29%%", s)
+	}
+	if s := got.Entries[1].Statistic; s == nil || s.Value != 54 {
+		t.Errorf("Align() 2020 = %+v, want 54%%", s)
+	}
+	if got.Entries[2].Statistic != nil {
+		t.Errorf("Align() 2024 = %+v, want nil (no statistic in unit)", got.Entries[2].Statistic)
+	}
+}
+
+func TestRun(t *testing.T) {
+	orchestrate := func(_ context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+		year := req.Topic[strings.LastIndex(req.Topic, " ")+1:]
+		if req.MinVerifiedStats != 5 || req.MaxCandidates != 15 {
+			t.Errorf("per-entity budget = %d/%d, want 5/15", req.MinVerifiedStats, req.MaxCandidates)
+		}
+		return &models.OrchestrationResponse{
+			Topic:           req.Topic,
+			Statistics:      []models.Statistic{{Name: "GDP growth " + year, Value: 2, Unit: "%", SourceURL: "https://example.com/" + year}},
+			TotalCandidates: 3,
+			FailedCount:     2,
+		}, nil
+	}
+
+	resp, err := Run(context.Background(), &models.OrchestrationRequest{
+		Topic:            "GDP growth",
+		MinVerifiedStats: 10,
+		MaxCandidates:    30,
+		Compare:          []string{"2022", "2023"},
+	}, orchestrate)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if resp.VerifiedCount != 2 || resp.TotalCandidates != 6 || resp.FailedCount != 4 {
+		t.Errorf("Run() counts = %d/%d/%d", resp.VerifiedCount, resp.TotalCandidates, resp.FailedCount)
+	}
+	if len(resp.Comparison.Entries) != 2 || resp.Comparison.Entries[1].Statistic.Name != "GDP growth 2023" {
+		t.Errorf("Run() comparison = %+v", resp.Comparison)
+	}
+}
@@ -7,8 +7,12 @@ import (
 	"context"
 	"os"
 	"strconv"
+	"strings"
 
 	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/policy"
 )
 
 // Config holds the application configuration.
@@ -29,6 +33,271 @@ type Config struct {
 
 	// HTTP Server Configuration
 	HTTPTimeoutSeconds int
+
+	// Listen addresses: an empty BindAddress listens on all interfaces, and a
+	// zero port uses the agent's default
+	BindAddress string
+	Port        int
+	A2APort     int
+
+	// Externally reachable base URL advertised in A2A agent cards; empty
+	// advertises the listen address
+	A2APublicURL string
+
+	// Outbound proxy (empty uses HTTPS_PROXY/HTTP_PROXY), extra trusted CAs,
+	// and connection pool for fetching, search, and LLM calls
+	ProxyURL     string
+	CABundleFile string
+	HTTPPool     httpclient.PoolConfig
+
+	// How page fetches present themselves to sites: User-Agent, contact URL,
+	// Accept-Language, and per-domain User-Agents; nil sends the default
+	// User-Agent alone
+	FetchIdentity *httpclient.Identity
+
+	// Basic auth, header tokens, and cookies page fetches send to intranet
+	// and subscription sites, by domain; never logged
+	FetchCredentials httpclient.Credentials
+
+	// Size limits and media types page fetches read; nil applies the
+	// defaults
+	FetchContent *httpclient.ContentPolicy
+
+	// Sources statistics may come from, checked in research and
+	// verification; nil accepts every source
+	SourcePolicy *policy.Policy
+
+	// Defaults for orchestration requests that leave fields unset
+	Defaults RequestDefaults
+
+	// Seconds between checks of config.json for changes to reload; 0 reloads
+	// on SIGHUP only
+	ConfigWatchSeconds int
+
+	// Gemini backend: "api" (API key) or "vertexai" (Application Default
+	// Credentials, e.g. GKE workload identity, with a GCP project and location)
+	GeminiBackend  string
+	VertexProject  string
+	VertexLocation string
+
+	// API keys for OpenAI-compatible providers not covered by agentkit
+	GroqAPIKey     string
+	MistralAPIKey  string
+	DeepSeekAPIKey string
+
+	// Per-request LLM overrides allowed, as "provider:model" or "provider:*"
+	LLMModelAllowlist []string
+
+	// Stage-specific models as "provider:model" or a model of LLM_PROVIDER;
+	// empty uses LLM_MODEL
+	SynthesisModel    string
+	VerificationModel string
+	PlanningModel     string
+	VisionModel       string // Reads figures; must be a Gemini model
+
+	// Generation settings as "temperature=0.2,top_p=0.9,max_tokens=4096":
+	// LLMGeneration for every stage, and the stage settings over it
+	LLMGeneration          string
+	SynthesisGeneration    string
+	VerificationGeneration string
+	PlanningGeneration     string
+	VisionGeneration       string
+
+	// Context windows in tokens as "model=tokens", matched by model-name
+	// prefix over the built-in table, for sizing prompts
+	LLMContextWindows []string
+
+	// Research: follow redirects and canonical links when deduplicating search results
+	CanonicalURLResolution bool
+
+	// Research: domains never returned, plus domains demoted after
+	// DomainDemoteAfter candidates without a verified statistic, as recorded
+	// by the orchestrators in DomainYieldFile (empty disables learning)
+	DomainSkipList    []string
+	DomainYieldFile   string
+	DomainDemoteAfter int
+
+	// Research: follow links from landing pages (topic, category, home) among
+	// the top results to report pages, up to CrawlMaxDepth levels and
+	// CrawlMaxPages pages per landing page
+	CrawlEnabled  bool
+	CrawlMaxDepth int
+	CrawlMaxPages int
+
+	// Research: also read the cited sources of the topic's Wikipedia article
+	// at WikipediaURL, up to WikipediaMaxReferences per run
+	WikipediaCitations     bool
+	WikipediaURL           string
+	WikipediaMaxReferences int
+
+	// Search: a second provider to fail over to when the primary is rate
+	// limited or out of quota, and how a rate-limited provider is retried
+	SearchFallbackProvider  string
+	SearchRateLimitRetries  int
+	SearchMaxBackoffSeconds int
+
+	// Internal search: an Elasticsearch or OpenSearch index of intranet
+	// documents, with an API key or basic auth and "role=field" mappings of
+	// its title, url, content, and date fields
+	InternalSearchURL      string
+	InternalSearchAPIKey   string
+	InternalSearchUsername string
+	InternalSearchPassword string
+	InternalSearchFields   []string
+
+	// Internal search: SharePoint through Microsoft Graph, with an Entra ID
+	// app registration's client credentials and the data region app-only
+	// searches require
+	SharePointTenantID     string
+	SharePointClientID     string
+	SharePointClientSecret string
+	SharePointRegion       string
+
+	// Semantic dedup of verified statistics: embeddings come from "local"
+	// (hashed, no network), "gemini", or "openai"; a threshold of 0 uses the
+	// provider's default
+	SemanticDedupEnabled   bool
+	EmbeddingProvider      string
+	EmbeddingModel         string
+	SemanticDedupThreshold float64
+
+	// Orchestrators: statistics verified by past runs, kept in
+	// StatisticsStoreFile (empty disables the store) for GET
+	// /statistics/search, which also ranks by embedding similarity when
+	// StatisticsSearchEmbeddings is set
+	StatisticsStoreFile        string
+	StatisticsSearchEmbeddings bool
+
+	// Directory of <name>.tmpl files overriding the embedded LLM prompts
+	PromptsDir string
+
+	// LLM record/replay: "record" saves every LLM response as a fixture in
+	// LLMReplayDir, "replay" answers from the fixtures without calling a provider
+	LLMReplayMode string
+	LLMReplayDir  string
+
+	// Synthesis: re-prompts to fix malformed JSON before giving up on a page
+	JSONRepairAttempts int
+
+	// Synthesis: the most page tokens sent per page, when the model's
+	// context window has room for them
+	SynthesisPageTokens int
+
+	// Synthesis: extraction prompt profile, "auto", "full", or "compact";
+	// auto uses the compact prompt for small and heavily quantized models
+	SynthesisPromptProfile string
+
+	// Synthesis: drop candidates whose excerpt does not state their value
+	// before they reach verification
+	SynthesisValueCheck bool
+
+	// Synthesis: read known sites (World Bank, Statista, Wikipedia) with
+	// site-specific adapters instead of the LLM
+	ExtractionAdapters bool
+
+	// Synthesis: read statistics from up to FigureMaxImages charts and
+	// infographics per page with VisionModel; verification re-reads them
+	FigureExtraction bool
+	FigureMaxImages  int
+
+	// Verification: minimum normalized similarity for an excerpt to count as found
+	ExcerptMatchThreshold float64
+
+	// Verification: ask the LLM to judge candidates whose excerpt was not found
+	VerificationLLMEnabled bool
+
+	// Verification: candidates from the same page judged in one LLM call;
+	// 1 judges each on its own
+	VerificationBatchSize int
+
+	// Verification: follow a page's citation of another organization to the
+	// page it links and verify the statistic there
+	PrimarySourceEnabled bool
+
+	// Direct: check that unverified results' URLs resolve and excerpts exist,
+	// and report an honesty score, giving each page this many seconds
+	DirectHonestyCheck          bool
+	DirectHonestyTimeoutSeconds int
+
+	// Eino orchestration: the pipeline's stages in order, built-in and
+	// registered; empty runs the built-in stages
+	OrchestrationStages []string
+
+	// Orchestration: transform of the final statistics, a command or a WASI
+	// module reading and writing JSON; both empty runs none
+	PostprocessCommand        string
+	PostprocessWASM           string
+	PostprocessTimeoutSeconds int
+
+	// Orchestration: JSON file of safety categories screening topics and
+	// verified statistics; empty screens nothing
+	SafetyRulesFile string
+
+	// Snapshot archival of verified sources: backend is none, local, or s3
+	ArchiveBackend  string
+	ArchiveDir      string
+	ArchiveS3Bucket string
+	ArchiveS3Prefix string
+
+	// Synthesis stores the pages it extracts from in the snapshot archive,
+	// and verification checks candidates against those bytes, re-fetching
+	// only to confirm the source is still live
+	ContentSharing bool
+
+	// Directory where orchestrators save a verification report per run;
+	// empty disables saving
+	ReportDir string
+
+	// Tenants: a JSON file of tenant API keys and daily quotas required by the
+	// orchestration and direct services (empty leaves them open), and the
+	// key clients such as the CLI send
+	TenantsFile string
+	APIKey      string
+
+	// Rate limits: API requests per client and page fetches per domain, each
+	// per minute (0 disables), counted in memory or in Redis (REDIS_URL) to
+	// share them across replicas
+	RateLimitBackend string
+	APIRateLimitRPM  int
+	FetchDomainRPM   int
+
+	// Job queue: none runs orchestration in the HTTP handler; memory, nats,
+	// or sqs queue /orchestrate requests for a pool of workers in every
+	// replica. Job state is kept in memory or in Redis (REDIS_URL).
+	JobQueue          string
+	NATSURL           string
+	NATSSubject       string
+	SQSQueueURL       string
+	JobWorkers        int
+	JobMaxAttempts    int
+	JobTimeoutMinutes int
+	JobStoreBackend   string
+	JobTTLHours       int
+
+	// Admission control: requests the orchestration and verification
+	// handlers run at once (0 for no limit), how many more may wait for a
+	// slot, and for how long, before they are answered 429
+	OrchestrationMaxConcurrent int
+	OrchestrationQueueDepth    int
+	VerificationMaxConcurrent  int
+	VerificationQueueDepth     int
+	AdmissionMaxWaitSeconds    int
+
+	// Topic monitoring: optional JSON file persisting subscriptions across restarts
+	MonitorStateFile string
+
+	// SMTP settings for email notifications
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// A2A session storage: backend is memory or redis
+	SessionBackend   string
+	RedisURL         string
+	SessionTTLHours  int
+	SessionKeyPrefix string
 }
 
 // Load loads configuration from config.json, environment variables, and OmniVault.
@@ -37,8 +306,39 @@ type Config struct {
 //   - Allows environment variable overrides
 //   - Loads secrets from OmniVault (API keys from env or AWS Secrets Manager)
 func Load(ctx context.Context) (*Config, error) {
+	// Proxy and CA settings apply to every outbound client, including the
+	// secrets backends below
+	transport, err := configureTransport()
+	if err != nil {
+		return nil, err
+	}
+	identity, err := fetchIdentity()
+	if err != nil {
+		return nil, err
+	}
+	content, err := fetchContent()
+	if err != nil {
+		return nil, err
+	}
+	sources, err := sourcePolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	// Vault and GCP Secret Manager are read by this package; agentkit
+	// loads everything else and handles the env and AWS providers
+	external, err := externalSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := akconfig.LoadOptions{}
+	if external != nil {
+		defer external.Close()
+		opts.SecretsProvider = akconfig.SecretsProviderEnv
+	}
+
 	// Load agentkit config (handles config.json + env + secrets)
-	akCfg, err := akconfig.Load(ctx, akconfig.LoadOptions{})
+	akCfg, err := akconfig.Load(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +358,191 @@ func Load(ctx context.Context) (*Config, error) {
 
 		// HTTP Server
 		HTTPTimeoutSeconds: getEnvInt("HTTP_TIMEOUT_SECONDS", 300),
+
+		// Listen addresses
+		BindAddress: getEnv("BIND_ADDRESS", ""),
+		Port:        getEnvInt("PORT", 0),
+		A2APort:     getEnvInt("A2A_PORT", 0),
+
+		// A2A agent card
+		A2APublicURL: getEnv("A2A_PUBLIC_URL", ""),
+
+		// Outbound proxy
+		ProxyURL:     transport.ProxyURL,
+		CABundleFile: transport.CABundleFile,
+		HTTPPool:     transport.Pool,
+
+		// Fetch identity and content limits
+		FetchIdentity: identity,
+		FetchContent:  content,
+		SourcePolicy:  sources,
+
+		// Request defaults
+		Defaults: loadRequestDefaults(),
+
+		// Config reload
+		ConfigWatchSeconds: getEnvInt("CONFIG_WATCH_SECONDS", 10),
+
+		// Gemini backend
+		GeminiBackend:  getEnv("GEMINI_BACKEND", "api"),
+		VertexProject:  getEnv("GOOGLE_CLOUD_PROJECT", ""),
+		VertexLocation: getEnv("GOOGLE_CLOUD_LOCATION", "us-central1"),
+
+		// OpenAI-compatible providers
+		GroqAPIKey:     getEnv("GROQ_API_KEY", ""),
+		MistralAPIKey:  getEnv("MISTRAL_API_KEY", ""),
+		DeepSeekAPIKey: getEnv("DEEPSEEK_API_KEY", ""),
+
+		// Per-request LLM overrides
+		LLMModelAllowlist: getEnvList("LLM_MODEL_ALLOWLIST"),
+
+		// Stage-specific models
+		SynthesisModel:    getEnv("SYNTHESIS_MODEL", ""),
+		VerificationModel: getEnv("VERIFICATION_MODEL", ""),
+		PlanningModel:     getEnv("PLANNING_MODEL", ""),
+		VisionModel:       getEnv("VISION_MODEL", ""),
+
+		// Stage generation settings
+		LLMGeneration:          getEnv("LLM_GENERATION", ""),
+		SynthesisGeneration:    getEnv("SYNTHESIS_GENERATION", ""),
+		VerificationGeneration: getEnv("VERIFICATION_GENERATION", ""),
+		PlanningGeneration:     getEnv("PLANNING_GENERATION", ""),
+		VisionGeneration:       getEnv("VISION_GENERATION", ""),
+
+		// Prompt budgeting
+		LLMContextWindows: getEnvList("LLM_CONTEXT_WINDOWS"),
+
+		// Research
+		CanonicalURLResolution: getEnv("CANONICAL_URL_RESOLUTION", "true") == "true",
+		DomainSkipList:         getEnvListOr("DOMAIN_SKIPLIST", defaultDomainSkipList),
+		DomainYieldFile:        getEnv("DOMAIN_YIELD_FILE", ""),
+		DomainDemoteAfter:      getEnvInt("DOMAIN_DEMOTE_AFTER", 10),
+		CrawlEnabled:           getEnv("CRAWL_ENABLED", "true") == "true",
+		CrawlMaxDepth:          getEnvInt("CRAWL_MAX_DEPTH", 1),
+		CrawlMaxPages:          getEnvInt("CRAWL_MAX_PAGES", 5),
+		WikipediaCitations:     getEnv("WIKIPEDIA_CITATIONS", "false") == "true",
+		WikipediaURL:           getEnv("WIKIPEDIA_URL", "https://en.wikipedia.org"),
+		WikipediaMaxReferences: getEnvInt("WIKIPEDIA_MAX_REFERENCES", 10),
+
+		// Search quota and rate limits
+		SearchFallbackProvider:  getEnv("SEARCH_FALLBACK_PROVIDER", ""),
+		SearchRateLimitRetries:  getEnvInt("SEARCH_RATE_LIMIT_RETRIES", 2),
+		SearchMaxBackoffSeconds: getEnvInt("SEARCH_MAX_BACKOFF_SECONDS", 60),
+
+		// Internal search
+		InternalSearchURL:      getEnv("INTERNAL_SEARCH_URL", ""),
+		InternalSearchAPIKey:   getEnv("INTERNAL_SEARCH_API_KEY", ""),
+		InternalSearchUsername: getEnv("INTERNAL_SEARCH_USERNAME", ""),
+		InternalSearchPassword: getEnv("INTERNAL_SEARCH_PASSWORD", ""),
+		InternalSearchFields:   getEnvList("INTERNAL_SEARCH_FIELDS"),
+		SharePointTenantID:     getEnv("SHAREPOINT_TENANT_ID", ""),
+		SharePointClientID:     getEnv("SHAREPOINT_CLIENT_ID", ""),
+		SharePointClientSecret: getEnv("SHAREPOINT_CLIENT_SECRET", ""),
+		SharePointRegion:       getEnv("SHAREPOINT_REGION", ""),
+
+		// Semantic dedup
+		SemanticDedupEnabled:   getEnv("SEMANTIC_DEDUP", "false") == "true",
+		EmbeddingProvider:      getEnv("EMBEDDING_PROVIDER", "local"),
+		EmbeddingModel:         getEnv("EMBEDDING_MODEL", ""),
+		SemanticDedupThreshold: getEnvFloat("SEMANTIC_DEDUP_THRESHOLD", 0),
+
+		// Statistics store
+		StatisticsStoreFile:        getEnv("STATS_STORE_FILE", ""),
+		StatisticsSearchEmbeddings: getEnv("STATS_SEARCH_EMBEDDINGS", "false") == "true",
+
+		// Prompt templates
+		PromptsDir: getEnv("PROMPTS_DIR", ""),
+
+		// LLM record/replay
+		LLMReplayMode: getEnv("LLM_REPLAY_MODE", ""),
+		LLMReplayDir:  getEnv("LLM_REPLAY_DIR", "testdata/llm"),
+
+		// Synthesis
+		JSONRepairAttempts:     getEnvInt("JSON_REPAIR_ATTEMPTS", 2),
+		SynthesisPageTokens:    getEnvInt("SYNTHESIS_PAGE_TOKENS", 32000),
+		SynthesisPromptProfile: getEnv("SYNTHESIS_PROMPT_PROFILE", "auto"),
+		SynthesisValueCheck:    getEnv("SYNTHESIS_VALUE_CHECK", "true") == "true",
+		ExtractionAdapters:     getEnv("EXTRACTION_ADAPTERS", "true") == "true",
+		FigureExtraction:       getEnv("FIGURE_EXTRACTION", "false") == "true",
+		FigureMaxImages:        getEnvInt("FIGURE_MAX_IMAGES", 5),
+
+		// Verification
+		ExcerptMatchThreshold:  getEnvFloat("EXCERPT_MATCH_THRESHOLD", 0.9),
+		VerificationLLMEnabled: getEnv("VERIFICATION_LLM_ENABLED", "true") == "true",
+		VerificationBatchSize:  getEnvInt("VERIFICATION_LLM_BATCH_SIZE", 5),
+		PrimarySourceEnabled:   getEnv("PRIMARY_SOURCE_ENABLED", "true") == "true",
+
+		// Direct
+		DirectHonestyCheck:          getEnv("DIRECT_HONESTY_CHECK", "true") == "true",
+		DirectHonestyTimeoutSeconds: getEnvInt("DIRECT_HONESTY_TIMEOUT_SECONDS", 10),
+
+		// Eino orchestration
+		OrchestrationStages: getEnvList("ORCHESTRATION_STAGES"),
+
+		// Post-processing
+		PostprocessCommand:        getEnv("POSTPROCESS_COMMAND", ""),
+		PostprocessWASM:           getEnv("POSTPROCESS_WASM", ""),
+		PostprocessTimeoutSeconds: getEnvInt("POSTPROCESS_TIMEOUT_SECONDS", 30),
+		SafetyRulesFile:           getEnv("SAFETY_RULES_FILE", ""),
+
+		// Snapshot archival
+		ArchiveBackend:  getEnv("ARCHIVE_BACKEND", "none"),
+		ArchiveDir:      getEnv("ARCHIVE_DIR", "./archive"),
+		ArchiveS3Bucket: getEnv("ARCHIVE_S3_BUCKET", ""),
+		ArchiveS3Prefix: getEnv("ARCHIVE_S3_PREFIX", "snapshots/"),
+		ContentSharing:  getEnv("CONTENT_SHARING", "true") == "true",
+
+		// Verification reports
+		ReportDir: getEnv("REPORT_DIR", ""),
+
+		// Tenants
+		TenantsFile: getEnv("TENANTS_FILE", ""),
+		APIKey:      getEnv("STATS_API_KEY", ""),
+
+		// Rate limits
+		RateLimitBackend: getEnv("RATE_LIMIT_BACKEND", "memory"),
+		APIRateLimitRPM:  getEnvInt("API_RATE_LIMIT_RPM", 0),
+		FetchDomainRPM:   getEnvInt("FETCH_DOMAIN_RPM", 0),
+
+		// Job queue
+		JobQueue:          getEnv("JOB_QUEUE", "none"),
+		NATSURL:           getEnv("NATS_URL", ""),
+		NATSSubject:       getEnv("NATS_SUBJECT", "stats.jobs"),
+		SQSQueueURL:       getEnv("SQS_QUEUE_URL", ""),
+		JobWorkers:        getEnvInt("JOB_WORKERS", 2),
+		JobMaxAttempts:    getEnvInt("JOB_MAX_ATTEMPTS", 3),
+		JobTimeoutMinutes: getEnvInt("JOB_TIMEOUT_MINUTES", 15),
+		JobStoreBackend:   getEnv("JOB_STORE_BACKEND", "memory"),
+		JobTTLHours:       getEnvInt("JOB_TTL_HOURS", 24),
+
+		// Admission control
+		OrchestrationMaxConcurrent: getEnvInt("ORCHESTRATION_MAX_CONCURRENT", 0),
+		OrchestrationQueueDepth:    getEnvInt("ORCHESTRATION_QUEUE_DEPTH", 10),
+		VerificationMaxConcurrent:  getEnvInt("VERIFICATION_MAX_CONCURRENT", 0),
+		VerificationQueueDepth:     getEnvInt("VERIFICATION_QUEUE_DEPTH", 20),
+		AdmissionMaxWaitSeconds:    getEnvInt("ADMISSION_MAX_WAIT_SECONDS", 30),
+
+		// Topic monitoring
+		MonitorStateFile: getEnv("MONITOR_STATE_FILE", ""),
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:         getEnv("SMTP_FROM", ""),
+
+		// A2A session storage
+		SessionBackend:   getEnv("SESSION_BACKEND", "memory"),
+		RedisURL:         getEnv("REDIS_URL", ""),
+		SessionTTLHours:  getEnvInt("SESSION_TTL_HOURS", 24),
+		SessionKeyPrefix: getEnv("SESSION_KEY_PREFIX", "stats-agent:"),
+	}
+
+	if external != nil {
+		cfg.loadSecrets(ctx, external)
+	}
+	cfg.applyCompatProvider()
+	if cfg.FetchCredentials, err = fetchCredentials(ctx, external); err != nil {
+		return nil, err
 	}
 
 	// Provider-specific observability settings
@@ -99,6 +584,8 @@ func LoadConfig() *Config {
 // This is used as a fallback when config.json or OmniVault is unavailable.
 func loadFromEnvOnly() *Config {
 	provider := getEnv("LLM_PROVIDER", "gemini")
+	transport := configureTransportOrWarn()
+	identity := fetchIdentityOrWarn()
 
 	// Create a minimal agentkit config from env vars
 	akCfg := &akconfig.Config{
@@ -159,8 +646,156 @@ func loadFromEnvOnly() *Config {
 		ObservabilityWorkspace: getEnv("OBSERVABILITY_WORKSPACE", getEnv("OPIK_WORKSPACE", getEnv("PHOENIX_SPACE_ID", ""))),
 
 		HTTPTimeoutSeconds: getEnvInt("HTTP_TIMEOUT_SECONDS", 300),
+
+		BindAddress: getEnv("BIND_ADDRESS", ""),
+		Port:        getEnvInt("PORT", 0),
+		A2APort:     getEnvInt("A2A_PORT", 0),
+
+		A2APublicURL: getEnv("A2A_PUBLIC_URL", ""),
+
+		ProxyURL:     transport.ProxyURL,
+		CABundleFile: transport.CABundleFile,
+		HTTPPool:     transport.Pool,
+
+		FetchIdentity:    identity,
+		FetchCredentials: fetchCredentialsOrWarn(),
+		FetchContent:     fetchContentOrWarn(),
+		SourcePolicy:     sourcePolicyOrReject(),
+
+		Defaults: loadRequestDefaults(),
+
+		ConfigWatchSeconds: getEnvInt("CONFIG_WATCH_SECONDS", 10),
+
+		GeminiBackend:  getEnv("GEMINI_BACKEND", "api"),
+		VertexProject:  getEnv("GOOGLE_CLOUD_PROJECT", ""),
+		VertexLocation: getEnv("GOOGLE_CLOUD_LOCATION", "us-central1"),
+
+		GroqAPIKey:     getEnv("GROQ_API_KEY", ""),
+		MistralAPIKey:  getEnv("MISTRAL_API_KEY", ""),
+		DeepSeekAPIKey: getEnv("DEEPSEEK_API_KEY", ""),
+
+		LLMModelAllowlist: getEnvList("LLM_MODEL_ALLOWLIST"),
+
+		SynthesisModel:    getEnv("SYNTHESIS_MODEL", ""),
+		VerificationModel: getEnv("VERIFICATION_MODEL", ""),
+		PlanningModel:     getEnv("PLANNING_MODEL", ""),
+		VisionModel:       getEnv("VISION_MODEL", ""),
+
+		LLMGeneration:          getEnv("LLM_GENERATION", ""),
+		SynthesisGeneration:    getEnv("SYNTHESIS_GENERATION", ""),
+		VerificationGeneration: getEnv("VERIFICATION_GENERATION", ""),
+		PlanningGeneration:     getEnv("PLANNING_GENERATION", ""),
+		VisionGeneration:       getEnv("VISION_GENERATION", ""),
+
+		LLMContextWindows: getEnvList("LLM_CONTEXT_WINDOWS"),
+
+		CanonicalURLResolution: getEnv("CANONICAL_URL_RESOLUTION", "true") == "true",
+		DomainSkipList:         getEnvListOr("DOMAIN_SKIPLIST", defaultDomainSkipList),
+		DomainYieldFile:        getEnv("DOMAIN_YIELD_FILE", ""),
+		DomainDemoteAfter:      getEnvInt("DOMAIN_DEMOTE_AFTER", 10),
+		CrawlEnabled:           getEnv("CRAWL_ENABLED", "true") == "true",
+		CrawlMaxDepth:          getEnvInt("CRAWL_MAX_DEPTH", 1),
+		CrawlMaxPages:          getEnvInt("CRAWL_MAX_PAGES", 5),
+		WikipediaCitations:     getEnv("WIKIPEDIA_CITATIONS", "false") == "true",
+		WikipediaURL:           getEnv("WIKIPEDIA_URL", "https://en.wikipedia.org"),
+		WikipediaMaxReferences: getEnvInt("WIKIPEDIA_MAX_REFERENCES", 10),
+
+		SearchFallbackProvider:  getEnv("SEARCH_FALLBACK_PROVIDER", ""),
+		SearchRateLimitRetries:  getEnvInt("SEARCH_RATE_LIMIT_RETRIES", 2),
+		SearchMaxBackoffSeconds: getEnvInt("SEARCH_MAX_BACKOFF_SECONDS", 60),
+
+		InternalSearchURL:      getEnv("INTERNAL_SEARCH_URL", ""),
+		InternalSearchAPIKey:   getEnv("INTERNAL_SEARCH_API_KEY", ""),
+		InternalSearchUsername: getEnv("INTERNAL_SEARCH_USERNAME", ""),
+		InternalSearchPassword: getEnv("INTERNAL_SEARCH_PASSWORD", ""),
+		InternalSearchFields:   getEnvList("INTERNAL_SEARCH_FIELDS"),
+		SharePointTenantID:     getEnv("SHAREPOINT_TENANT_ID", ""),
+		SharePointClientID:     getEnv("SHAREPOINT_CLIENT_ID", ""),
+		SharePointClientSecret: getEnv("SHAREPOINT_CLIENT_SECRET", ""),
+		SharePointRegion:       getEnv("SHAREPOINT_REGION", ""),
+
+		SemanticDedupEnabled:   getEnv("SEMANTIC_DEDUP", "false") == "true",
+		EmbeddingProvider:      getEnv("EMBEDDING_PROVIDER", "local"),
+		EmbeddingModel:         getEnv("EMBEDDING_MODEL", ""),
+		SemanticDedupThreshold: getEnvFloat("SEMANTIC_DEDUP_THRESHOLD", 0),
+
+		StatisticsStoreFile:        getEnv("STATS_STORE_FILE", ""),
+		StatisticsSearchEmbeddings: getEnv("STATS_SEARCH_EMBEDDINGS", "false") == "true",
+
+		PromptsDir: getEnv("PROMPTS_DIR", ""),
+
+		LLMReplayMode: getEnv("LLM_REPLAY_MODE", ""),
+		LLMReplayDir:  getEnv("LLM_REPLAY_DIR", "testdata/llm"),
+
+		JSONRepairAttempts:     getEnvInt("JSON_REPAIR_ATTEMPTS", 2),
+		SynthesisPageTokens:    getEnvInt("SYNTHESIS_PAGE_TOKENS", 32000),
+		SynthesisPromptProfile: getEnv("SYNTHESIS_PROMPT_PROFILE", "auto"),
+		SynthesisValueCheck:    getEnv("SYNTHESIS_VALUE_CHECK", "true") == "true",
+		ExtractionAdapters:     getEnv("EXTRACTION_ADAPTERS", "true") == "true",
+		FigureExtraction:       getEnv("FIGURE_EXTRACTION", "false") == "true",
+		FigureMaxImages:        getEnvInt("FIGURE_MAX_IMAGES", 5),
+
+		ExcerptMatchThreshold:  getEnvFloat("EXCERPT_MATCH_THRESHOLD", 0.9),
+		VerificationLLMEnabled: getEnv("VERIFICATION_LLM_ENABLED", "true") == "true",
+		VerificationBatchSize:  getEnvInt("VERIFICATION_LLM_BATCH_SIZE", 5),
+		PrimarySourceEnabled:   getEnv("PRIMARY_SOURCE_ENABLED", "true") == "true",
+
+		DirectHonestyCheck:          getEnv("DIRECT_HONESTY_CHECK", "true") == "true",
+		DirectHonestyTimeoutSeconds: getEnvInt("DIRECT_HONESTY_TIMEOUT_SECONDS", 10),
+
+		OrchestrationStages: getEnvList("ORCHESTRATION_STAGES"),
+
+		PostprocessCommand:        getEnv("POSTPROCESS_COMMAND", ""),
+		PostprocessWASM:           getEnv("POSTPROCESS_WASM", ""),
+		PostprocessTimeoutSeconds: getEnvInt("POSTPROCESS_TIMEOUT_SECONDS", 30),
+		SafetyRulesFile:           getEnv("SAFETY_RULES_FILE", ""),
+
+		ArchiveBackend:  getEnv("ARCHIVE_BACKEND", "none"),
+		ArchiveDir:      getEnv("ARCHIVE_DIR", "./archive"),
+		ArchiveS3Bucket: getEnv("ARCHIVE_S3_BUCKET", ""),
+		ArchiveS3Prefix: getEnv("ARCHIVE_S3_PREFIX", "snapshots/"),
+		ContentSharing:  getEnv("CONTENT_SHARING", "true") == "true",
+
+		ReportDir: getEnv("REPORT_DIR", ""),
+
+		TenantsFile: getEnv("TENANTS_FILE", ""),
+		APIKey:      getEnv("STATS_API_KEY", ""),
+
+		RateLimitBackend: getEnv("RATE_LIMIT_BACKEND", "memory"),
+		APIRateLimitRPM:  getEnvInt("API_RATE_LIMIT_RPM", 0),
+		FetchDomainRPM:   getEnvInt("FETCH_DOMAIN_RPM", 0),
+
+		JobQueue:          getEnv("JOB_QUEUE", "none"),
+		NATSURL:           getEnv("NATS_URL", ""),
+		NATSSubject:       getEnv("NATS_SUBJECT", "stats.jobs"),
+		SQSQueueURL:       getEnv("SQS_QUEUE_URL", ""),
+		JobWorkers:        getEnvInt("JOB_WORKERS", 2),
+		JobMaxAttempts:    getEnvInt("JOB_MAX_ATTEMPTS", 3),
+		JobTimeoutMinutes: getEnvInt("JOB_TIMEOUT_MINUTES", 15),
+		JobStoreBackend:   getEnv("JOB_STORE_BACKEND", "memory"),
+		JobTTLHours:       getEnvInt("JOB_TTL_HOURS", 24),
+
+		OrchestrationMaxConcurrent: getEnvInt("ORCHESTRATION_MAX_CONCURRENT", 0),
+		OrchestrationQueueDepth:    getEnvInt("ORCHESTRATION_QUEUE_DEPTH", 10),
+		VerificationMaxConcurrent:  getEnvInt("VERIFICATION_MAX_CONCURRENT", 0),
+		VerificationQueueDepth:     getEnvInt("VERIFICATION_QUEUE_DEPTH", 20),
+		AdmissionMaxWaitSeconds:    getEnvInt("ADMISSION_MAX_WAIT_SECONDS", 30),
+
+		MonitorStateFile: getEnv("MONITOR_STATE_FILE", ""),
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:         getEnv("SMTP_FROM", ""),
+
+		SessionBackend:   getEnv("SESSION_BACKEND", "memory"),
+		RedisURL:         getEnv("REDIS_URL", ""),
+		SessionTTLHours:  getEnvInt("SESSION_TTL_HOURS", 24),
+		SessionKeyPrefix: getEnv("SESSION_KEY_PREFIX", "stats-agent:"),
 	}
 
+	cfg.applyCompatProvider()
+
 	// Provider-specific observability settings
 	if cfg.ObservabilityEnabled {
 		switch cfg.ObservabilityProvider {
@@ -184,6 +819,43 @@ func loadFromEnvOnly() *Config {
 	return cfg
 }
 
+// compatProviderModels are the default models of providers that agentkit
+// does not know about
+var compatProviderModels = map[string]string{
+	"groq":     "llama-3.3-70b-versatile",
+	"mistral":  "mistral-small-latest",
+	"deepseek": "deepseek-chat",
+}
+
+// applyCompatProvider fills in the API key and default model for groq,
+// mistral, and deepseek, which agentkit leaves unset or defaults to Gemini
+func (c *Config) applyCompatProvider() {
+	defaultModel, ok := compatProviderModels[c.LLMProvider]
+	if !ok {
+		return
+	}
+	if c.LLMModel == "" || (os.Getenv("LLM_MODEL") == "" && c.LLMModel == akconfig.GetDefaultModel(c.LLMProvider)) {
+		c.LLMModel = defaultModel
+	}
+	if c.LLMAPIKey == "" {
+		c.LLMAPIKey = c.compatAPIKey(c.LLMProvider)
+	}
+}
+
+// compatAPIKey returns the configured API key for groq, mistral, or deepseek
+func (c *Config) compatAPIKey(provider string) string {
+	switch provider {
+	case "groq":
+		return c.GroqAPIKey
+	case "mistral":
+		return c.MistralAPIKey
+	case "deepseek":
+		return c.DeepSeekAPIKey
+	default:
+		return ""
+	}
+}
+
 // getAgentURL gets an agent URL from agentkit config or returns default.
 func getAgentURL(cfg *akconfig.Config, name, defaultURL string) string {
 	if url := cfg.GetAgentURL(name); url != "" {
@@ -209,3 +881,43 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvFloat gets an environment variable as float64 or returns a default value.
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList gets a comma-separated environment variable as a list, skipping empty entries.
+func getEnvList(key string) []string {
+	return splitList(os.Getenv(key))
+}
+
+// getEnvListOr is getEnvList with a default used only when the variable is
+// not set at all, so that setting it empty clears the list.
+func getEnvListOr(key string, defaultValue []string) []string {
+	if value, ok := os.LookupEnv(key); ok {
+		return splitList(value)
+	}
+	return defaultValue
+}
+
+// splitList splits a comma-separated list, skipping empty entries
+func splitList(value string) []string {
+	return splitListSep(value, ",")
+}
+
+// splitListSep splits a list separated by sep, skipping empty entries
+func splitListSep(value, sep string) []string {
+	var list []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+// fetchCredentials returns the per-domain credentials page fetches send:
+// the FETCH_CREDENTIALS secret from the external secrets backend, the
+// FETCH_CREDENTIALS variable, or the contents of FETCH_CREDENTIALS_FILE
+// (e.g. a mounted Kubernetes secret), in that order. sc may be nil.
+func fetchCredentials(ctx context.Context, sc *akconfig.SecretsClient) (httpclient.Credentials, error) {
+	data := getEnv("FETCH_CREDENTIALS", "")
+	if sc != nil {
+		if v, err := sc.Get(ctx, "FETCH_CREDENTIALS"); err == nil && v != "" {
+			data = v
+		}
+	}
+	if path := getEnv("FETCH_CREDENTIALS_FILE", ""); data == "" && path != "" {
+		file, err := os.ReadFile(path) //nolint:gosec // G304: path from configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to read FETCH_CREDENTIALS_FILE: %w", err)
+		}
+		data = string(file)
+	}
+	return httpclient.ParseCredentials(data)
+}
+
+// fetchCredentialsOrWarn is fetchCredentials for the env-only fallback,
+// which cannot return an error
+func fetchCredentialsOrWarn() httpclient.Credentials {
+	c, err := fetchCredentials(context.Background(), nil)
+	if err != nil {
+		slog.Warn("fetch credentials ignored", "error", err)
+		return nil
+	}
+	return c
+}
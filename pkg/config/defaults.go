@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Built-in request defaults, used unless config.json or the environment
+// overrides them
+const (
+	defaultMinVerifiedStats = 10
+	defaultMaxCandidates    = 30
+
+	// One synthesis pass reads 15 pages, keeps up to 10 candidates from
+	// each, so no one page fills the candidate budget, and gathers five
+	// candidates per statistic still needed
+	defaultMaxPages                 = 15
+	defaultCandidatesPerPageCap     = 10
+	defaultVerificationBufferFactor = 5.0
+)
+
+// defaultDomainSkipList are sites that rarely hold primary statistics: social
+// networks, Q&A sites, and document aggregators. DOMAIN_SKIPLIST replaces it.
+var defaultDomainSkipList = []string{
+	"pinterest.com", "quora.com", "facebook.com", "instagram.com", "tiktok.com",
+	"answers.com", "slideshare.net", "scribd.com", "coursehero.com", "studocu.com",
+}
+
+// RequestDefaults fill in orchestration request fields a client leaves unset
+type RequestDefaults struct {
+	MinVerifiedStats int  `json:"min_verified_stats"`
+	MaxCandidates    int  `json:"max_candidates"`
+	ReputableOnly    bool `json:"reputable_only"`
+
+	models.StageLimits
+}
+
+// configFiles are the config.json locations checked for a "defaults"
+// section, in the order agentkit searches them
+var configFiles = []string{"config.json", "../config.json"}
+
+// readConfigSection decodes a top-level section of the first config.json
+// found into v, reporting whether the section was present. agentkit does
+// not expose sections it does not know about.
+func readConfigSection(name string, v any) bool {
+	for _, path := range configFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var file map[string]json.RawMessage
+		if json.Unmarshal(data, &file) != nil {
+			return false
+		}
+		section, ok := file[name]
+		return ok && json.Unmarshal(section, v) == nil
+	}
+	return false
+}
+
+// builtinLimits are the stage limits used unless overridden
+var builtinLimits = models.StageLimits{
+	MaxPages:                 defaultMaxPages,
+	CandidatesPerPageCap:     defaultCandidatesPerPageCap,
+	VerificationBufferFactor: defaultVerificationBufferFactor,
+}
+
+// loadRequestDefaults returns the built-in defaults overridden by the
+// "defaults" section of config.json and then by DEFAULT_MIN_VERIFIED_STATS,
+// DEFAULT_MAX_CANDIDATES, DEFAULT_REPUTABLE_ONLY, and the DEFAULT_ stage
+// limits. Out-of-range stage limits are ignored with a warning.
+func loadRequestDefaults() RequestDefaults {
+	d := RequestDefaults{
+		MinVerifiedStats: defaultMinVerifiedStats,
+		MaxCandidates:    defaultMaxCandidates,
+		StageLimits:      builtinLimits,
+	}
+
+	var file RequestDefaults
+	if readConfigSection("defaults", &file) {
+		if file.MinVerifiedStats > 0 {
+			d.MinVerifiedStats = file.MinVerifiedStats
+		}
+		if file.MaxCandidates > 0 {
+			d.MaxCandidates = file.MaxCandidates
+		}
+		d.ReputableOnly = file.ReputableOnly
+		d.StageLimits = mergeLimits(d.StageLimits, file.StageLimits)
+	}
+
+	d.MinVerifiedStats = getEnvInt("DEFAULT_MIN_VERIFIED_STATS", d.MinVerifiedStats)
+	d.MaxCandidates = getEnvInt("DEFAULT_MAX_CANDIDATES", d.MaxCandidates)
+	if v := os.Getenv("DEFAULT_REPUTABLE_ONLY"); v != "" {
+		d.ReputableOnly = v == "true"
+	}
+	d.StageLimits = mergeLimits(d.StageLimits, models.StageLimits{
+		MaxPages:                 getEnvInt("DEFAULT_MAX_PAGES", 0),
+		CandidatesPerPageCap:     getEnvInt("DEFAULT_CANDIDATES_PER_PAGE_CAP", 0),
+		VerificationBufferFactor: getEnvFloat("DEFAULT_VERIFICATION_BUFFER_FACTOR", 0),
+	})
+
+	if err := d.StageLimits.Validate(); err != nil {
+		slog.Warn("invalid default stage limits, using built-in limits", "error", err)
+		d.StageLimits = builtinLimits
+	}
+	return d
+}
+
+// mergeLimits returns base with the non-zero fields of override applied
+func mergeLimits(base, override models.StageLimits) models.StageLimits {
+	if override.MaxPages != 0 {
+		base.MaxPages = override.MaxPages
+	}
+	if override.CandidatesPerPageCap != 0 {
+		base.CandidatesPerPageCap = override.CandidatesPerPageCap
+	}
+	if override.VerificationBufferFactor != 0 {
+		base.VerificationBufferFactor = override.VerificationBufferFactor
+	}
+	return base
+}
+
+// ApplyDefaults fills in the request fields left unset. ReputableOnly cannot
+// be told apart from an explicit false, so a default of true always applies.
+func (c *Config) ApplyDefaults(req *models.OrchestrationRequest) {
+	if req.MinVerifiedStats <= 0 {
+		req.MinVerifiedStats = c.Defaults.MinVerifiedStats
+	}
+	if req.MaxCandidates <= 0 {
+		req.MaxCandidates = c.Defaults.MaxCandidates
+	}
+	if c.Defaults.ReputableOnly {
+		req.ReputableOnly = true
+	}
+	req.StageLimits = c.FillLimits(req.StageLimits)
+	if req.Seed != 0 {
+		req.Reproducible = true
+	}
+	if req.Reproducible && req.Seed == 0 {
+		req.Seed = topicSeed(req.Topic)
+	}
+}
+
+// topicSeed derives a positive seed from a topic, so reproducible runs of
+// the same topic sample alike without the caller choosing a seed
+func topicSeed(topic string) int32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(strings.TrimSpace(topic))))
+	return int32(h.Sum32()&math.MaxInt32) | 1 //nolint:gosec // G115: masked to 31 bits
+}
+
+// FillLimits returns l with its unset fields taken from the defaults
+func (c *Config) FillLimits(l models.StageLimits) models.StageLimits {
+	return mergeLimits(c.Defaults.StageLimits, l)
+}
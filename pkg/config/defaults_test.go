@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestLoadRequestDefaults(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if d := loadRequestDefaults(); d.MinVerifiedStats != 10 || d.MaxCandidates != 30 || d.ReputableOnly {
+		t.Errorf("built-in defaults = %+v", d)
+	}
+
+	file := `{"llm": {"provider": "gemini"}, "defaults": {"min_verified_stats": 5, "max_candidates": 40, "reputable_only": true}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if d := loadRequestDefaults(); d.MinVerifiedStats != 5 || d.MaxCandidates != 40 || !d.ReputableOnly {
+		t.Errorf("config.json defaults = %+v", d)
+	}
+
+	t.Setenv("DEFAULT_MAX_CANDIDATES", "60")
+	t.Setenv("DEFAULT_REPUTABLE_ONLY", "false")
+	if d := loadRequestDefaults(); d.MinVerifiedStats != 5 || d.MaxCandidates != 60 || d.ReputableOnly {
+		t.Errorf("env defaults = %+v", d)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	cfg := &Config{Defaults: RequestDefaults{MinVerifiedStats: 10, MaxCandidates: 30, ReputableOnly: true}}
+
+	req := &models.OrchestrationRequest{Topic: "t", MinVerifiedStats: 3}
+	cfg.ApplyDefaults(req)
+	if req.MinVerifiedStats != 3 || req.MaxCandidates != 30 || !req.ReputableOnly {
+		t.Errorf("ApplyDefaults() = %+v", req)
+	}
+}
+
+func TestLoadRequestDefaultsStageLimits(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if d := loadRequestDefaults(); d.StageLimits != builtinLimits {
+		t.Errorf("built-in limits = %+v", d.StageLimits)
+	}
+
+	t.Setenv("DEFAULT_MAX_PAGES", "8")
+	t.Setenv("DEFAULT_VERIFICATION_BUFFER_FACTOR", "2.5")
+	want := models.StageLimits{MaxPages: 8, CandidatesPerPageCap: defaultCandidatesPerPageCap, VerificationBufferFactor: 2.5}
+	if d := loadRequestDefaults(); d.StageLimits != want {
+		t.Errorf("env limits = %+v, want %+v", d.StageLimits, want)
+	}
+
+	t.Setenv("DEFAULT_VERIFICATION_BUFFER_FACTOR", "0.5")
+	if d := loadRequestDefaults(); d.StageLimits != builtinLimits {
+		t.Errorf("invalid env limits = %+v, want built-in", d.StageLimits)
+	}
+}
+
+func TestApplyDefaultsStageLimits(t *testing.T) {
+	cfg := &Config{Defaults: RequestDefaults{StageLimits: builtinLimits}}
+
+	req := &models.OrchestrationRequest{Topic: "t", StageLimits: models.StageLimits{MaxPages: 4}}
+	cfg.ApplyDefaults(req)
+	want := builtinLimits
+	want.MaxPages = 4
+	if req.StageLimits != want {
+		t.Errorf("ApplyDefaults() limits = %+v, want %+v", req.StageLimits, want)
+	}
+}
+
+func TestApplyDefaultsSeed(t *testing.T) {
+	cfg := &Config{}
+
+	req := &models.OrchestrationRequest{Topic: "Solar power", Reproducible: true}
+	cfg.ApplyDefaults(req)
+	again := &models.OrchestrationRequest{Topic: "solar power ", Reproducible: true}
+	cfg.ApplyDefaults(again)
+	if req.Seed <= 0 || req.Seed != again.Seed {
+		t.Errorf("derived seeds = %d, %d; want the same positive seed", req.Seed, again.Seed)
+	}
+
+	seeded := &models.OrchestrationRequest{Topic: "t", Seed: 42}
+	cfg.ApplyDefaults(seeded)
+	if !seeded.Reproducible || seeded.Seed != 42 {
+		t.Errorf("seeded request = %+v, want reproducible with seed 42", seeded)
+	}
+
+	plain := &models.OrchestrationRequest{Topic: "t"}
+	cfg.ApplyDefaults(plain)
+	if plain.Reproducible || plain.Seed != 0 {
+		t.Errorf("plain request = %+v, want no seed", plain)
+	}
+}
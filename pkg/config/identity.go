@@ -0,0 +1,51 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+// fetchIdentity returns the User-Agent, contact URL, Accept-Language, and
+// per-domain User-Agents page fetches present to sites. Overrides are
+// separated by "|", since User-Agents contain commas.
+func fetchIdentity() (*httpclient.Identity, error) {
+	return httpclient.NewIdentity(
+		getEnv("FETCH_USER_AGENT", httpclient.DefaultUserAgent),
+		getEnv("FETCH_CONTACT_URL", ""),
+		getEnv("FETCH_ACCEPT_LANGUAGE", ""),
+		splitListSep(os.Getenv("FETCH_USER_AGENT_OVERRIDES"), "|"),
+	)
+}
+
+// fetchIdentityOrWarn is fetchIdentity for the env-only fallback, which
+// cannot return an error
+func fetchIdentityOrWarn() *httpclient.Identity {
+	id, err := fetchIdentity()
+	if err != nil {
+		slog.Warn("fetch identity settings ignored", "error", err)
+		return nil
+	}
+	return id
+}
+
+// fetchContent returns the size limits and media types page fetches read
+func fetchContent() (*httpclient.ContentPolicy, error) {
+	return httpclient.NewContentPolicy(
+		getEnvInt("FETCH_MAX_PAGE_MB", httpclient.DefaultMaxPageMB),
+		getEnvInt("FETCH_MAX_DOCUMENT_MB", httpclient.DefaultMaxDocumentMB),
+		splitList(os.Getenv("FETCH_ALLOWED_TYPES")),
+	)
+}
+
+// fetchContentOrWarn is fetchContent for the env-only fallback, which
+// cannot return an error
+func fetchContentOrWarn() *httpclient.ContentPolicy {
+	p, err := fetchContent()
+	if err != nil {
+		slog.Warn("fetch content settings ignored", "error", err)
+		return nil
+	}
+	return p
+}
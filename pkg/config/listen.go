@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ListenAddr returns the HTTP server's listen address: BIND_ADDRESS and
+// PORT, or the agent's default port
+func (c *Config) ListenAddr(defaultPort int) string {
+	return net.JoinHostPort(c.BindAddress, strconv.Itoa(portOr(c.Port, defaultPort)))
+}
+
+// A2AListenAddr returns the A2A server's listen address: BIND_ADDRESS and
+// A2A_PORT, or the agent's default A2A port
+func (c *Config) A2AListenAddr(defaultPort int) string {
+	return net.JoinHostPort(c.BindAddress, strconv.Itoa(portOr(c.A2APort, defaultPort)))
+}
+
+// HTTPURL returns the base URL of the HTTP server, as advertised in API
+// documentation
+func (c *Config) HTTPURL(defaultPort int) *url.URL {
+	return c.advertisedURL(portOr(c.Port, defaultPort))
+}
+
+// A2AURL returns the base URL the A2A server advertises in its agent card:
+// A2A_PUBLIC_URL when it is set and valid, otherwise the listen address
+func (c *Config) A2AURL(defaultPort int) *url.URL {
+	if u, err := c.A2APublicBaseURL(); err == nil && u != nil {
+		return u
+	}
+	return c.advertisedURL(portOr(c.A2APort, defaultPort))
+}
+
+// A2APublicBaseURL parses A2A_PUBLIC_URL, the externally reachable base URL
+// of the A2A server (e.g. behind a Kubernetes ingress). It returns nil when
+// the URL is not set.
+func (c *Config) A2APublicBaseURL() (*url.URL, error) {
+	if c.A2APublicURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(c.A2APublicURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid A2A_PUBLIC_URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid A2A_PUBLIC_URL %q: must be an absolute http or https URL", c.A2APublicURL)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawQuery, u.Fragment = "", ""
+	return u, nil
+}
+
+// advertisedURL returns an http URL for port on the bind address. An agent
+// bound to all interfaces advertises localhost.
+func (c *Config) advertisedURL(port int) *url.URL {
+	host := c.BindAddress
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return &url.URL{Scheme: "http", Host: net.JoinHostPort(host, strconv.Itoa(port))}
+}
+
+// portOr returns port, or def when port is unset
+func portOr(port, def int) int {
+	if port > 0 {
+		return port
+	}
+	return def
+}
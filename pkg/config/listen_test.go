@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestListenAddrs(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.ListenAddr(8001); got != ":8001" {
+		t.Errorf("ListenAddr() = %q", got)
+	}
+	if got := cfg.A2AURL(9001).String(); got != "http://localhost:9001" {
+		t.Errorf("A2AURL() = %q", got)
+	}
+
+	cfg = &Config{BindAddress: "::1", Port: 18001, A2APort: 19001}
+	if got := cfg.ListenAddr(8001); got != "[::1]:18001" {
+		t.Errorf("ListenAddr() = %q", got)
+	}
+	if got := cfg.A2AListenAddr(9001); got != "[::1]:19001" {
+		t.Errorf("A2AListenAddr() = %q", got)
+	}
+	if got := cfg.A2AURL(9001).JoinPath("invoke").String(); got != "http://[::1]:19001/invoke" {
+		t.Errorf("A2AURL() = %q", got)
+	}
+
+	cfg = &Config{BindAddress: "0.0.0.0", Port: 18005}
+	if got := cfg.HTTPURL(8005).String(); got != "http://localhost:18005" {
+		t.Errorf("HTTPURL() = %q", got)
+	}
+}
+
+func TestA2APublicURL(t *testing.T) {
+	cfg := &Config{A2APublicURL: "https://agents.example.com/research/", A2APort: 19001}
+	if got := cfg.A2AURL(9001).JoinPath("invoke").String(); got != "https://agents.example.com/research/invoke" {
+		t.Errorf("A2AURL() = %q", got)
+	}
+
+	cfg.A2APublicURL = "agents.example.com:9001"
+	if _, err := cfg.A2APublicBaseURL(); err == nil {
+		t.Error("A2APublicBaseURL() accepted a URL without a scheme")
+	}
+	if got := cfg.A2AURL(9001).String(); got != "http://localhost:19001" {
+		t.Errorf("A2AURL() with invalid public URL = %q", got)
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"log/slog"
+
+	"github.com/plexusone/agent-team-stats/pkg/policy"
+)
+
+// sourcePolicy returns the operator's source acceptance policy
+func sourcePolicy() (*policy.Policy, error) {
+	return policy.Compile(getEnv("SOURCE_POLICY", ""))
+}
+
+// sourcePolicyOrReject is sourcePolicy for the env-only fallback, which
+// cannot return an error. Unlike other settings an invalid policy is not
+// ignored, which would accept every source: it rejects them all.
+func sourcePolicyOrReject() *policy.Policy {
+	p, err := sourcePolicy()
+	if err != nil {
+		slog.Error("invalid source policy rejects every source", "error", err)
+		p, _ = policy.Compile("false")
+	}
+	return p
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+// transportConfig returns the outbound proxy, CA, connection pool, DNS
+// cache, and private host settings
+func transportConfig() httpclient.TransportConfig {
+	return httpclient.TransportConfig{
+		ProxyURL:     getEnv("PROXY_URL", ""),
+		CABundleFile: getEnv("CA_BUNDLE_FILE", ""),
+		Pool: httpclient.PoolConfig{
+			MaxConnsPerHost:       getEnvInt("HTTP_MAX_CONNS_PER_HOST", 0),
+			MaxIdleConns:          getEnvInt("HTTP_MAX_IDLE_CONNS", 0),
+			MaxIdleConnsPerHost:   getEnvInt("HTTP_MAX_IDLE_CONNS_PER_HOST", 0),
+			IdleConnTimeout:       getEnvSeconds("HTTP_IDLE_CONN_TIMEOUT_SECONDS"),
+			DialTimeout:           getEnvSeconds("HTTP_DIAL_TIMEOUT_SECONDS"),
+			TLSHandshakeTimeout:   getEnvSeconds("HTTP_TLS_HANDSHAKE_TIMEOUT_SECONDS"),
+			ResponseHeaderTimeout: getEnvSeconds("HTTP_RESPONSE_HEADER_TIMEOUT_SECONDS"),
+			DisableHTTP2:          getEnv("HTTP_DISABLE_HTTP2", "false") == "true",
+		},
+		DNSCacheTTL:  time.Duration(getEnvInt("HTTP_DNS_CACHE_SECONDS", int(httpclient.DefaultDNSCacheTTL/time.Second))) * time.Second,
+		PrivateHosts: getEnvList("FETCH_PRIVATE_HOSTS"),
+	}
+}
+
+// getEnvSeconds gets an environment variable of whole seconds as a
+// duration, 0 when unset
+func getEnvSeconds(key string) time.Duration {
+	return time.Duration(getEnvInt(key, 0)) * time.Second
+}
+
+// configureTransport installs the outbound transport settings for every
+// HTTP client in the process, returning them for the Config
+func configureTransport() (httpclient.TransportConfig, error) {
+	tc := transportConfig()
+	return tc, httpclient.Configure(tc)
+}
+
+// configureTransportOrWarn is configureTransport for the env-only fallback,
+// which cannot return an error
+func configureTransportOrWarn() httpclient.TransportConfig {
+	tc, err := configureTransport()
+	if err != nil {
+		slog.Warn("outbound transport settings ignored", "error", err)
+	}
+	return tc
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ReloadFunc applies a newly loaded configuration. Returning an error keeps
+// the current configuration in effect.
+type ReloadFunc func(ctx context.Context, cfg *Config) error
+
+// WatchReload reloads the configuration whenever the process receives SIGHUP
+// or, when cfg.ConfigWatchSeconds is positive, config.json changes, and
+// passes it to reload. A configuration that fails to load is logged and
+// ignored. It blocks until ctx is done.
+//
+// Only values read from config.json and the secrets provider can change:
+// a process's environment variables are fixed when it starts.
+func WatchReload(ctx context.Context, cfg *Config, logger *slog.Logger, reload ReloadFunc) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if cfg.ConfigWatchSeconds > 0 {
+		ticker := time.NewTicker(time.Duration(cfg.ConfigWatchSeconds) * time.Second)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	path, modTime := configFileModTime()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.Info("SIGHUP received, reloading configuration")
+		case <-tick:
+			p, m := configFileModTime()
+			if p == path && m.Equal(modTime) {
+				continue
+			}
+			path, modTime = p, m
+			logger.Info("config file changed, reloading configuration", "path", p)
+		}
+
+		next, err := Load(ctx)
+		if err != nil {
+			logger.Error("config reload failed, keeping current configuration", "error", err)
+			continue
+		}
+		if err := reload(ctx, next); err != nil {
+			logger.Error("config reload failed, keeping current configuration", "error", err)
+			continue
+		}
+		logger.Info("configuration reloaded",
+			"llm_provider", next.LLMProvider,
+			"search_provider", next.SearchProvider)
+	}
+}
+
+// configFileModTime returns the config.json in use and its modification
+// time. Stat follows symlinks, so Kubernetes ConfigMap updates are seen.
+func configFileModTime() (string, time.Time) {
+	for _, path := range configFiles {
+		if info, err := os.Stat(path); err == nil {
+			return path, info.ModTime()
+		}
+	}
+	return "", time.Time{}
+}
+
+// FilePath returns the config.json in use, or "" when there is none
+func FilePath() string {
+	path, _ := configFileModTime()
+	return path
+}
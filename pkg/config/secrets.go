@@ -0,0 +1,139 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	akconfig "github.com/plexusone/agentkit/config"
+	"github.com/plexusone/omnivault/vault"
+
+	"github.com/plexusone/agent-team-stats/pkg/secrets"
+)
+
+// Secrets providers served by pkg/secrets rather than agentkit
+const (
+	SecretsProviderVault = "vault"  // HashiCorp Vault KV v2
+	SecretsProviderGCP   = "gcp-sm" // Google Cloud Secret Manager
+)
+
+// secretsSettings returns the secrets provider and path prefix from the
+// "secrets" section of config.json, overridden by SECRETS_PROVIDER and
+// SECRETS_PREFIX
+func secretsSettings() (provider, prefix string) {
+	var file struct {
+		Provider string `json:"provider"`
+		Prefix   string `json:"prefix"`
+	}
+	readConfigSection("secrets", &file)
+	return getEnv("SECRETS_PROVIDER", file.Provider), getEnv("SECRETS_PREFIX", file.Prefix)
+}
+
+// externalSecrets returns a secrets client for the Vault or GCP provider,
+// or nil when agentkit handles the configured provider
+func externalSecrets(ctx context.Context) (*akconfig.SecretsClient, error) {
+	provider, prefix := secretsSettings()
+
+	var v vault.Vault
+	var err error
+	switch provider {
+	case SecretsProviderVault:
+		v, err = secrets.NewHashiCorp(secrets.HashiCorpConfig{
+			Addr:      getEnv("VAULT_ADDR", ""),
+			Token:     vaultToken(),
+			Namespace: getEnv("VAULT_NAMESPACE", ""),
+			Mount:     getEnv("VAULT_KV_MOUNT", "secret"),
+		})
+	case SecretsProviderGCP:
+		v, err = secrets.NewGCP(ctx, secrets.GCPConfig{Project: getEnv("GOOGLE_CLOUD_PROJECT", "")})
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s secrets backend: %w", provider, err)
+	}
+
+	// The secrets client falls back to the environment on any error, so
+	// check once that the backend is reachable and the credentials work
+	if _, err := v.Exists(ctx, prefix+"LLM_API_KEY"); err != nil {
+		return nil, fmt.Errorf("%s secrets backend unavailable: %w", provider, err)
+	}
+
+	return akconfig.NewSecretsClient(akconfig.SecretsConfig{
+		Provider:      akconfig.SecretsProvider(provider),
+		Prefix:        prefix,
+		CustomVault:   v,
+		FallbackToEnv: true,
+	})
+}
+
+// vaultToken returns VAULT_TOKEN or, when unset, the contents of
+// VAULT_TOKEN_FILE (e.g. a Vault Agent token sink)
+func vaultToken() string {
+	if token := getEnv("VAULT_TOKEN", ""); token != "" {
+		return token
+	}
+	if path := getEnv("VAULT_TOKEN_FILE", ""); path != "" {
+		if data, err := os.ReadFile(path); err == nil { //nolint:gosec // G304: path from configuration
+			return strings.TrimSpace(string(data))
+		}
+	}
+	return ""
+}
+
+// loadSecrets sets credentials from an external secrets backend. As with
+// agentkit's providers, a secret found in the backend takes precedence over
+// the environment variable of the same name.
+func (c *Config) loadSecrets(ctx context.Context, sc *akconfig.SecretsClient) {
+	set := func(field *string, names ...string) bool {
+		for _, name := range names {
+			if v, err := sc.Get(ctx, name); err == nil && v != "" {
+				*field = v
+				return true
+			}
+		}
+		return false
+	}
+
+	llmKey := set(&c.LLMAPIKey, "LLM_API_KEY")
+	set(&c.GeminiAPIKey, "GEMINI_API_KEY", "GOOGLE_API_KEY")
+	set(&c.ClaudeAPIKey, "CLAUDE_API_KEY", "ANTHROPIC_API_KEY")
+	set(&c.OpenAIAPIKey, "OPENAI_API_KEY")
+	set(&c.XAIAPIKey, "XAI_API_KEY")
+	set(&c.GroqAPIKey, "GROQ_API_KEY")
+	set(&c.MistralAPIKey, "MISTRAL_API_KEY")
+	set(&c.DeepSeekAPIKey, "DEEPSEEK_API_KEY")
+	set(&c.SerperAPIKey, "SERPER_API_KEY")
+	set(&c.SerpAPIKey, "SERPAPI_API_KEY")
+	set(&c.InternalSearchAPIKey, "INTERNAL_SEARCH_API_KEY")
+	set(&c.InternalSearchPassword, "INTERNAL_SEARCH_PASSWORD")
+	set(&c.SharePointClientSecret, "SHAREPOINT_CLIENT_SECRET")
+	set(&c.ObservabilityAPIKey, "OBSERVABILITY_API_KEY", "OPIK_API_KEY")
+	set(&c.A2AAuthToken, "A2A_AUTH_TOKEN")
+	set(&c.SMTPPassword, "SMTP_PASSWORD")
+	set(&c.RedisURL, "REDIS_URL")
+
+	// Keep LLM_API_KEY in step with the provider's key, as agentkit does
+	if !llmKey {
+		if key := c.providerAPIKey(c.LLMProvider); key != "" {
+			c.LLMAPIKey = key
+		}
+	}
+}
+
+// providerAPIKey returns the configured API key for an LLM provider
+func (c *Config) providerAPIKey(provider string) string {
+	switch provider {
+	case "gemini":
+		return c.GeminiAPIKey
+	case "claude":
+		return c.ClaudeAPIKey
+	case "openai":
+		return c.OpenAIAPIKey
+	case "xai":
+		return c.XAIAPIKey
+	default:
+		return c.compatAPIKey(provider)
+	}
+}
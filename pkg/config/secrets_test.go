@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadVaultSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/stats-agent" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"GEMINI_API_KEY":"vault-gemini","SERPER_API_KEY":"vault-serper","FETCH_CREDENTIALS":"{\"intranet.example.com\":{\"username\":\"svc\",\"password\":\"vault-pass\"}}"}}}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	file := `{"llm": {"provider": "gemini"}, "secrets": {"provider": "vault", "prefix": "stats-agent/"}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("test-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN_FILE", filepath.Join(dir, "token"))
+	t.Setenv("GEMINI_API_KEY", "env-gemini")
+	t.Setenv("OPENAI_API_KEY", "env-openai")
+
+	cfg, err := Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GeminiAPIKey != "vault-gemini" || cfg.LLMAPIKey != "vault-gemini" {
+		t.Errorf("gemini key = %q, LLM key = %q, want vault-gemini", cfg.GeminiAPIKey, cfg.LLMAPIKey)
+	}
+	if cfg.SerperAPIKey != "vault-serper" || cfg.OpenAIAPIKey != "env-openai" {
+		t.Errorf("serper key = %q, openai key = %q", cfg.SerperAPIKey, cfg.OpenAIAPIKey)
+	}
+	if cred := cfg.FetchCredentials["intranet.example.com"]; cred.Password != "vault-pass" {
+		t.Errorf("fetch credentials = %v", cfg.FetchCredentials.Domains())
+	}
+
+	t.Setenv("VAULT_ADDR", "")
+	if _, err := Load(context.Background()); err == nil {
+		t.Error("Load() without VAULT_ADDR succeeded")
+	}
+}
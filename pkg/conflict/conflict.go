@@ -0,0 +1,188 @@
+// Package conflict flags statistics in one response that contradict each
+// other: the same metric, for the same period, from different sources, with
+// values that cannot both be right. Each group of contradicting statistics
+// shares a conflict_group ID, so users are not handed mutually exclusive
+// numbers unknowingly.
+package conflict
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+const (
+	// valueTolerance is the relative difference under which two values
+	// agree, allowing for rounding ("38%" and "38.4%")
+	valueTolerance = 0.02
+	// minNameOverlap is the share of words two names must have in common to
+	// describe the same metric
+	minNameOverlap = 0.6
+)
+
+var (
+	years = regexp.MustCompile(`\b(19\d{2}|20\d{2}|2100)\b`)
+	words = regexp.MustCompile(`[\p{L}]+`)
+)
+
+// stopWords say nothing about what a statistic measures
+var stopWords = map[string]bool{
+	"the": true, "a": true, "an": true, "of": true, "in": true, "on": true, "for": true, "to": true,
+	"and": true, "or": true, "by": true, "as": true, "at": true, "from": true, "per": true, "with": true,
+	"number": true, "total": true, "share": true, "percentage": true, "percent": true, "rate": true,
+}
+
+// Detect sets ConflictGroup on the statistics that contradict another in
+// stats, clearing it on the rest, and returns one Conflict per group in the
+// order of its first statistic
+func Detect(stats []models.Statistic) []models.Conflict {
+	parent := make([]int, len(stats))
+	for i := range parent {
+		parent[i] = i
+		stats[i].ConflictGroup = ""
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range stats {
+		for j := i + 1; j < len(stats); j++ {
+			if Contradicts(stats[i], stats[j]) {
+				if a, b := find(i), find(j); a != b {
+					parent[max(a, b)] = min(a, b)
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range stats {
+		r := find(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], i)
+	}
+
+	var conflicts []models.Conflict
+	for _, r := range roots {
+		members := groups[r]
+		if len(members) < 2 {
+			continue
+		}
+		c := models.Conflict{ID: groupID(stats, members), Metric: stats[r].Name}
+		for _, i := range members {
+			stats[i].ConflictGroup = c.ID
+			c.Values = append(c.Values, fmt.Sprintf("%s (%s)", models.FormatValue(stats[i].Value, stats[i].Unit), source(stats[i])))
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts
+}
+
+// Contradicts reports whether a and b give different values for the same
+// metric and period. Statistics from the same page are breakdowns rather than
+// contradictions, and statistics of different types (a forecast and the
+// measured outcome) or periods are not comparable.
+func Contradicts(a, b models.Statistic) bool {
+	if a.SourceURL == b.SourceURL || normalizeUnit(a.Unit) != normalizeUnit(b.Unit) {
+		return false
+	}
+	if a.Type != "" && b.Type != "" && a.Type != b.Type {
+		return false
+	}
+	if pa, pb := period(a), period(b); len(pa) > 0 && len(pb) > 0 && !overlaps(pa, pb) {
+		return false
+	}
+	if nameOverlap(a.Name, b.Name) < minNameOverlap {
+		return false
+	}
+	x, y := float64(a.Value), float64(b.Value)
+	return math.Abs(x-y) > valueTolerance*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// period returns the years a statistic is for: those in its name, or else
+// those in its excerpt
+func period(s models.Statistic) []string {
+	if y := years.FindAllString(s.Name, -1); len(y) > 0 {
+		return y
+	}
+	return years.FindAllString(s.Excerpt, -1)
+}
+
+func overlaps(a, b []string) bool {
+	return slices.ContainsFunc(a, func(y string) bool { return slices.Contains(b, y) })
+}
+
+// nameOverlap returns the Jaccard similarity of two names' content words,
+// ignoring years and plurals
+func nameOverlap(a, b string) float64 {
+	x, y := wordSet(a), wordSet(b)
+	if len(x) == 0 || len(y) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range x {
+		if y[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(x)+len(y)-shared)
+}
+
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range words.FindAllString(strings.ToLower(s), -1) {
+		if stopWords[w] {
+			continue
+		}
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			w = strings.TrimSuffix(w, "s")
+		}
+		set[w] = true
+	}
+	return set
+}
+
+// normalizeUnit folds the spellings of percent into "%"
+func normalizeUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	switch unit {
+	case "percent", "percentage", "pct":
+		return "%"
+	}
+	return unit
+}
+
+// source names a statistic's source briefly: its host, or its source name
+func source(s models.Statistic) string {
+	if u, err := url.Parse(s.SourceURL); err == nil && u.Hostname() != "" {
+		return strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	return s.Source
+}
+
+// groupID derives a group's ID from its statistics, so the same
+// contradiction has the same ID in every response and IDs from separate
+// searches, such as the entities of a comparison, never collide
+func groupID(stats []models.Statistic, members []int) string {
+	keys := make([]string, len(members))
+	for k, i := range members {
+		keys[k] = fmt.Sprintf("%s|%s|%v", stats[i].SourceURL, stats[i].Name, stats[i].Value)
+	}
+	slices.Sort(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+	return "conflict-" + hex.EncodeToString(sum[:4])
+}
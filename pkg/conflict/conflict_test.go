@@ -0,0 +1,71 @@
+package conflict
+
+import (
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestDetect(t *testing.T) {
+	stats := []models.Statistic{
+		{Name: "Global EV sales in 2023", Value: 14, Unit: "million", SourceURL: "https://www.iea.org/ev"},
+		{Name: "Global EV sales, 2023", Value: 10.5, Unit: "million", SourceURL: "https://example.com/evs"},
+		{Name: "Global EV sales in 2022", Value: 10.5, Unit: "million", SourceURL: "https://example.org/2022"},
+		{Name: "Global EV sales in 2023", Value: 13.9, Unit: "million", SourceURL: "https://rounded.example/ev"},
+		{Name: "Share of EVs in car sales 2023", Value: 18, Unit: "percent", SourceURL: "https://www.iea.org/share"},
+	}
+
+	conflicts := Detect(stats)
+	if len(conflicts) != 1 {
+		t.Fatalf("Detect = %+v; want one group", conflicts)
+	}
+	c := conflicts[0]
+	if c.Metric != "Global EV sales in 2023" || len(c.Values) != 3 || c.Values[0] != "14 million (iea.org)" {
+		t.Errorf("conflict = %+v", c)
+	}
+
+	// The rounded value agrees with 14 million but contradicts 10.5
+	for i, want := range []bool{true, true, false, true, false} {
+		if got := stats[i].ConflictGroup == c.ID; got != want {
+			t.Errorf("stats[%d] (%s) in group = %v; want %v", i, stats[i].SourceURL, got, want)
+		}
+	}
+
+	// Detecting again gives the same ID
+	if again := Detect(stats); len(again) != 1 || again[0].ID != c.ID {
+		t.Errorf("Detect again = %+v; want ID %s", again, c.ID)
+	}
+
+	// Once the values agree, the groups are cleared
+	stats[1].Value = 14
+	if again := Detect(stats); len(again) != 0 || stats[0].ConflictGroup != "" {
+		t.Errorf("Detect after agreement = %+v, group %q", again, stats[0].ConflictGroup)
+	}
+}
+
+func TestContradicts(t *testing.T) {
+	base := models.Statistic{Name: "U.S. unemployment rate", Value: 3.9, Unit: "%", SourceURL: "https://bls.gov", Excerpt: "was 3.9% in April 2024"}
+	tests := []struct {
+		name  string
+		other models.Statistic
+		want  bool
+	}{
+		{"different value", models.Statistic{Name: "Unemployment rate in the U.S.", Value: 4.5, Unit: "percent", SourceURL: "https://example.com", Excerpt: "hit 4.5% in 2024"}, true},
+		{"same page", models.Statistic{Name: "U.S. unemployment rate", Value: 6.1, Unit: "%", SourceURL: "https://bls.gov", Excerpt: "6.1% in April 2024 for young workers"}, false},
+		{"different year", models.Statistic{Name: "U.S. unemployment rate", Value: 6.7, Unit: "%", SourceURL: "https://example.com", Excerpt: "6.7% in 2020"}, false},
+		{"different metric", models.Statistic{Name: "U.S. labor force participation", Value: 62.7, Unit: "%", SourceURL: "https://example.com"}, false},
+		{"different type", models.Statistic{Name: "U.S. unemployment rate", Value: 4.5, Unit: "%", SourceURL: "https://example.com", Type: models.TypeForecast}, false},
+		{"different unit", models.Statistic{Name: "U.S. unemployment rate", Value: 6.4, Unit: "million", SourceURL: "https://example.com"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := base
+			if tt.name == "different type" {
+				a.Type = models.TypeMeasured
+			}
+			if got := Contradicts(a, tt.other); got != tt.want {
+				t.Errorf("Contradicts = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Package connectapi serves the orchestrator's stats.v1.OrchestratorService
+// (proto/stats/v1) over the Connect, gRPC, and gRPC-Web protocols. Browser
+// dashboards stream run progress and results with plain HTTP requests, and
+// native clients get gRPC semantics, from the same handler.
+package connectapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"github.com/plexusone/agent-team-stats/pkg/admission"
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+	"github.com/plexusone/agent-team-stats/pkg/statsv1"
+	"github.com/plexusone/agent-team-stats/pkg/statsv1/statsv1connect"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+// defaultLimit is the page size of a search without a limit
+const defaultLimit = 10
+
+// Backend is the orchestrator the service serves. Procedures needing a nil
+// component fail with FailedPrecondition.
+type Backend struct {
+	Progress   *progress.Hub
+	Statistics *statstore.Store
+	Jobs       *jobqueue.Runner      // Queue mode: Orchestrate submits a job instead of running
+	Admission  *admission.Controller // Limits the runs the service runs itself
+	Validate   func(*models.OrchestrationRequest) error
+	Run        func(context.Context, *models.OrchestrationRequest) (*models.OrchestrationResponse, error)
+}
+
+// Server implements OrchestratorService over a backend
+type Server struct {
+	backend Backend
+	logger  *slog.Logger
+}
+
+var _ statsv1connect.OrchestratorServiceHandler = (*Server)(nil)
+
+// New creates the service over b
+func New(b Backend, logger *slog.Logger) *Server {
+	return &Server{backend: b, logger: logger}
+}
+
+// Handler returns the path prefix to mount the service at and its handler
+func (s *Server) Handler() (string, http.Handler) {
+	path, handler := statsv1connect.NewOrchestratorServiceHandler(s)
+	return path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Streams outlive the server's write timeout
+		if r.URL.Path == statsv1connect.OrchestratorServiceOrchestrateStreamProcedure ||
+			r.URL.Path == statsv1connect.OrchestratorServiceWatchRunsProcedure {
+			if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+				s.logger.Debug("failed to clear write deadline", "error", err)
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Protocols returns the protocols of a server hosting the service: HTTP/1
+// for browsers, and unencrypted HTTP/2 for gRPC clients, which require it
+func Protocols() *http.Protocols {
+	p := new(http.Protocols)
+	p.SetHTTP1(true)
+	p.SetUnencryptedHTTP2(true)
+	return p
+}
+
+// Orchestrate runs a request, or queues it in queue mode
+func (s *Server) Orchestrate(ctx context.Context, req *connect.Request[statsv1.OrchestrateRequest]) (*connect.Response[statsv1.OrchestrateResponse], error) {
+	orchReq, err := s.request(req.Msg.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	// In queue mode a worker runs the request. A dry run only searches, so
+	// it is answered directly.
+	if s.backend.Jobs != nil && !orchReq.DryRun {
+		job, err := s.backend.Jobs.Submit(ctx, orchReq)
+		if err != nil {
+			s.logger.Error("failed to queue job", "error", err)
+			return nil, connect.NewError(connect.CodeUnavailable, errors.New("failed to queue job"))
+		}
+		s.logger.Info("job queued", "job_id", job.ID, "topic", orchReq.Topic)
+		return connect.NewResponse(&statsv1.OrchestrateResponse{Outcome: &statsv1.OrchestrateResponse_Job{Job: toJob(job)}}), nil
+	}
+
+	resp, err := s.run(ctx, orchReq)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&statsv1.OrchestrateResponse{Outcome: &statsv1.OrchestrateResponse_Result{Result: toResult(resp)}}), nil
+}
+
+// outcome is what a run returned
+type outcome struct {
+	resp *models.OrchestrationResponse
+	err  error
+}
+
+// OrchestrateStream runs a request on this replica, streaming its progress,
+// the statistics it verifies or retracts, and then its result
+func (s *Server) OrchestrateStream(ctx context.Context, req *connect.Request[statsv1.OrchestrateStreamRequest], stream *connect.ServerStream[statsv1.OrchestrateStreamResponse]) error {
+	orchReq, err := s.request(req.Msg.GetOptions())
+	if err != nil {
+		return err
+	}
+	if s.backend.Progress == nil {
+		resp, err := s.run(ctx, orchReq)
+		if err != nil {
+			return err
+		}
+		return stream.Send(&statsv1.OrchestrateStreamResponse{Event: &statsv1.OrchestrateStreamResponse_Result{Result: toResult(resp)}})
+	}
+
+	// Subscribe before starting, and learn the run's ID before its first
+	// event, so none of the run's events are missed
+	events, unsubscribe := s.backend.Progress.Subscribe()
+	defer unsubscribe()
+	ids := make(chan string, 1)
+	runCtx := progress.OnBegin(ctx, func(id string) { ids <- id })
+	done := make(chan outcome, 1)
+	go func() {
+		resp, err := s.run(runCtx, orchReq)
+		done <- outcome{resp, err}
+	}()
+
+	var id string
+	send := func(ev progress.Event) error {
+		if id == "" {
+			select {
+			case id = <-ids:
+			default:
+			}
+		}
+		if id == "" || ev.Run.ID != id {
+			return nil
+		}
+		if err := stream.Send(&statsv1.OrchestrateStreamResponse{Event: &statsv1.OrchestrateStreamResponse_Progress{Progress: toRun(ev.Run)}}); err != nil {
+			return err
+		}
+		for _, stat := range ev.Statistics {
+			msg := &statsv1.OrchestrateStreamResponse{Event: &statsv1.OrchestrateStreamResponse_Statistic{Statistic: toStatistic(stat)}}
+			if ev.Type == progress.EventRetracted {
+				msg.Event = &statsv1.OrchestrateStreamResponse_Retracted{Retracted: toStatistic(stat)}
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		select {
+		case ev := <-events:
+			if err := send(ev); err != nil {
+				return err
+			}
+		case out := <-done:
+			// The run's last events may still be waiting
+			for len(events) > 0 {
+				if err := send(<-events); err != nil {
+					return err
+				}
+			}
+			if out.err != nil {
+				return out.err
+			}
+			return stream.Send(&statsv1.OrchestrateStreamResponse{Event: &statsv1.OrchestrateStreamResponse_Result{Result: toResult(out.resp)}})
+		}
+	}
+}
+
+// WatchRuns streams the progress of the active runs the caller may see
+func (s *Server) WatchRuns(ctx context.Context, _ *connect.Request[statsv1.WatchRunsRequest], stream *connect.ServerStream[statsv1.WatchRunsResponse]) error {
+	if s.backend.Progress == nil {
+		return connect.NewError(connect.CodeFailedPrecondition, errors.New("run progress is not available"))
+	}
+	// Subscribe before taking the snapshot so no change falls between them
+	events, unsubscribe := s.backend.Progress.Subscribe()
+	defer unsubscribe()
+
+	send := func(ev progress.Event) error {
+		if !ev.Run.VisibleTo(ctx) {
+			return nil
+		}
+		return stream.Send(&statsv1.WatchRunsResponse{Type: eventTypes[ev.Type], Run: toRun(ev.Run), Statistics: toStatistics(ev.Statistics)})
+	}
+	for _, run := range s.backend.Progress.Runs() {
+		if err := send(progress.Event{Type: progress.EventStarted, Run: run}); err != nil {
+			return err
+		}
+	}
+	if err := stream.Send(&statsv1.WatchRunsResponse{Type: statsv1.EventType_EVENT_TYPE_READY}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetJob returns a queued run the caller may see
+func (s *Server) GetJob(ctx context.Context, req *connect.Request[statsv1.GetJobRequest]) (*connect.Response[statsv1.GetJobResponse], error) {
+	if s.backend.Jobs == nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("job queue is not configured; set JOB_QUEUE"))
+	}
+	job, err := s.backend.Jobs.Job(ctx, req.Msg.GetId())
+	if errors.Is(err, jobqueue.ErrNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+	if err != nil {
+		s.logger.Error("failed to load job", "job_id", req.Msg.GetId(), "error", err)
+		return nil, connect.NewError(connect.CodeInternal, errors.New("failed to load job"))
+	}
+	return connect.NewResponse(&statsv1.GetJobResponse{Job: toJob(job)}), nil
+}
+
+// SearchStatistics searches the statistics store
+func (s *Server) SearchStatistics(ctx context.Context, req *connect.Request[statsv1.SearchStatisticsRequest]) (*connect.Response[statsv1.SearchStatisticsResponse], error) {
+	if s.backend.Statistics == nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("statistics store is not enabled; set STATS_STORE_FILE"))
+	}
+	msg := req.Msg
+	q := statstore.Query{Text: strings.TrimSpace(msg.GetQuery()), Topic: strings.TrimSpace(msg.GetTopic())}
+	if q.Text == "" && q.Topic == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("query or topic is required"))
+	}
+	if msg.GetOffset() < 0 || msg.GetLimit() < 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("offset and limit must not be negative"))
+	}
+	if msg.PublishedAfter != nil {
+		q.PublishedAfter = msg.GetPublishedAfter().AsTime()
+	}
+	limit := int(msg.GetLimit())
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	matches, mode, err := s.backend.Statistics.Search(ctx, q)
+	if err != nil {
+		s.logger.Error("statistics search failed", "error", err)
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("search failed: %w", err))
+	}
+	results, page := models.Paginate(matches, int(msg.GetOffset()), limit)
+	resp := &statsv1.SearchStatisticsResponse{Mode: mode, Total: count(page.Total), NextOffset: count(page.NextOffset)}
+	for _, m := range results {
+		resp.Results = append(resp.Results, toStored(m))
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// request converts and validates run options
+func (s *Server) request(o *statsv1.RunOptions) (*models.OrchestrationRequest, error) {
+	req, err := runRequest(o)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if s.backend.Validate != nil {
+		if err := s.backend.Validate(req); err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+	}
+	return req, nil
+}
+
+// run runs a request under the admission limit, charging its tenant
+func (s *Server) run(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+	if s.backend.Run == nil {
+		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("runs cannot be started here"))
+	}
+	release, ok, retry := s.backend.Admission.Acquire(ctx)
+	if !ok {
+		return nil, connect.NewError(connect.CodeResourceExhausted,
+			fmt.Errorf("orchestrator is busy; retry in %d seconds", int(math.Ceil(retry.Seconds()))))
+	}
+	defer release()
+	resp, err := s.backend.Run(ctx, req)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("orchestration failed: %w", err))
+	}
+	tenant.Charge(ctx, resp.CostSummary)
+	return resp, nil
+}
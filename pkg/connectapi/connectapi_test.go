@@ -0,0 +1,196 @@
+package connectapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+	"github.com/plexusone/agent-team-stats/pkg/statsv1"
+	"github.com/plexusone/agent-team-stats/pkg/statsv1/statsv1connect"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+var testStat = models.Statistic{
+	Name: "Global electric vehicle sales in 2023", Value: 14, Unit: "million", Source: "IEA",
+	SourceURL: "https://www.iea.org/reports/global-ev-outlook-2024",
+	Excerpt:   "Almost 14 million new electric cars were registered globally in 2023.", Verified: true,
+	License: &models.License{Kind: models.LicenseCCBY, Permissive: true},
+}
+
+// serve starts the service over b and returns a client of it
+func serve(t *testing.T, b Backend) (statsv1connect.OrchestratorServiceClient, string) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle(New(b, testLogger).Handler())
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return statsv1connect.NewOrchestratorServiceClient(srv.Client(), srv.URL), srv.URL
+}
+
+// fakeRun runs like an orchestrator: it reports its progress to hub and
+// returns one verified statistic
+func fakeRun(hub *progress.Hub) func(context.Context, *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+	return func(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+		if req.Topic == "fail" {
+			return nil, errors.New("search failed")
+		}
+		_, r := hub.Begin(ctx, req.Topic, req.MinVerifiedStats)
+		r.Stage(progress.StageResearch, 1)
+		r.Verified([]models.Statistic{testStat}, 0)
+		r.Finish(models.StatusComplete, nil)
+		return &models.OrchestrationResponse{Topic: req.Topic, Status: models.StatusComplete, Statistics: []models.Statistic{testStat}, VerifiedCount: 1}, nil
+	}
+}
+
+func TestOrchestrate(t *testing.T) {
+	hub := progress.NewHub()
+	client, _ := serve(t, Backend{Progress: hub, Run: fakeRun(hub)})
+
+	resp, err := client.Orchestrate(context.Background(), connect.NewRequest(&statsv1.OrchestrateRequest{
+		Options: &statsv1.RunOptions{Topic: "electric vehicles", MinVerifiedStats: 1, StatisticTypes: []string{"measured"}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := resp.Msg.GetResult()
+	if result.GetStatus() != models.StatusComplete || len(result.GetStatistics()) != 1 ||
+		result.GetStatistics()[0].GetLicense().GetKind() != string(models.LicenseCCBY) {
+		t.Errorf("result = %v", result)
+	}
+
+	for _, opts := range []*statsv1.RunOptions{{}, {Topic: "x", StatisticTypes: []string{"rumor"}}} {
+		_, err := client.Orchestrate(context.Background(), connect.NewRequest(&statsv1.OrchestrateRequest{Options: opts}))
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Orchestrate(%v) = %v; want InvalidArgument", opts, err)
+		}
+	}
+	_, err = client.Orchestrate(context.Background(), connect.NewRequest(&statsv1.OrchestrateRequest{Options: &statsv1.RunOptions{Topic: "fail"}}))
+	if connect.CodeOf(err) != connect.CodeInternal {
+		t.Errorf("failed run = %v; want Internal", err)
+	}
+}
+
+func TestOrchestrateStream(t *testing.T) {
+	hub := progress.NewHub()
+	client, _ := serve(t, Backend{Progress: hub, Run: fakeRun(hub)})
+
+	// Another run's events are not part of the stream
+	_, other := hub.Begin(context.Background(), "other", 1)
+	defer other.Finish(models.StatusPartial, nil)
+
+	stream, err := client.OrchestrateStream(context.Background(), connect.NewRequest(&statsv1.OrchestrateStreamRequest{
+		Options: &statsv1.RunOptions{Topic: "electric vehicles", MinVerifiedStats: 1},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stages []string
+	var result *statsv1.Result
+	for stream.Receive() {
+		msg := stream.Msg()
+		if run := msg.GetProgress(); run != nil {
+			if run.GetTopic() != "electric vehicles" {
+				t.Errorf("progress of another run: %v", run)
+			}
+			stages = append(stages, run.GetStage())
+		}
+		if stat := msg.GetStatistic(); stat != nil {
+			stages = append(stages, "statistic:"+stat.GetSource())
+		}
+		if r := msg.GetResult(); r != nil {
+			result = r
+		}
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(stages, " "); got != " research research statistic:IEA done" {
+		t.Errorf("stages = %q", got)
+	}
+	if result == nil || result.GetVerifiedCount() != 1 {
+		t.Errorf("result = %v", result)
+	}
+}
+
+func TestWatchRuns(t *testing.T) {
+	hub := progress.NewHub()
+	_, active := hub.Begin(context.Background(), "solar", 3)
+	client, _ := serve(t, Backend{Progress: hub})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.WatchRuns(ctx, connect.NewRequest(&statsv1.WatchRunsRequest{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []statsv1.EventType
+	for stream.Receive() {
+		types = append(types, stream.Msg().GetType())
+		if stream.Msg().GetType() == statsv1.EventType_EVENT_TYPE_READY {
+			active.Finish(models.StatusComplete, nil)
+		}
+		if stream.Msg().GetType() == statsv1.EventType_EVENT_TYPE_FINISHED {
+			break
+		}
+	}
+	want := []statsv1.EventType{statsv1.EventType_EVENT_TYPE_STARTED, statsv1.EventType_EVENT_TYPE_READY, statsv1.EventType_EVENT_TYPE_FINISHED}
+	if len(types) != len(want) || types[0] != want[0] || types[1] != want[1] || types[2] != want[2] {
+		t.Errorf("events = %v; want %v", types, want)
+	}
+}
+
+func TestSearchStatistics(t *testing.T) {
+	store, err := statstore.New(filepath.Join(t.TempDir(), "statistics.json"), nil, testLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Merge(context.Background(), "electric vehicles", []models.Statistic{testStat}, nil); err != nil {
+		t.Fatal(err)
+	}
+	client, url := serve(t, Backend{Statistics: store})
+
+	resp, err := client.SearchStatistics(context.Background(), connect.NewRequest(&statsv1.SearchStatisticsRequest{Query: "electric vehicle sales"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Msg.GetTotal() != 1 || resp.Msg.GetResults()[0].GetStatistic().GetValue() != 14 || resp.Msg.GetResults()[0].GetDomain() != "iea.org" {
+		t.Errorf("response = %v", resp.Msg)
+	}
+	if _, err := client.SearchStatistics(context.Background(), connect.NewRequest(&statsv1.SearchStatisticsRequest{})); connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Errorf("empty search = %v; want InvalidArgument", err)
+	}
+
+	// Browsers call procedures with plain JSON
+	httpResp, err := http.Post(url+statsv1connect.OrchestratorServiceSearchStatisticsProcedure, "application/json", strings.NewReader(`{"topic": "electric vehicles"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		t.Errorf("JSON request status = %d", httpResp.StatusCode)
+	}
+}
+
+func TestDisabledComponents(t *testing.T) {
+	client, _ := serve(t, Backend{})
+	if _, err := client.GetJob(context.Background(), connect.NewRequest(&statsv1.GetJobRequest{Id: "x"})); connect.CodeOf(err) != connect.CodeFailedPrecondition {
+		t.Errorf("GetJob = %v; want FailedPrecondition", err)
+	}
+	if _, err := client.SearchStatistics(context.Background(), connect.NewRequest(&statsv1.SearchStatisticsRequest{Query: "x"})); connect.CodeOf(err) != connect.CodeFailedPrecondition {
+		t.Errorf("SearchStatistics = %v; want FailedPrecondition", err)
+	}
+	if _, err := client.Orchestrate(context.Background(), connect.NewRequest(&statsv1.OrchestrateRequest{Options: &statsv1.RunOptions{Topic: "x"}})); connect.CodeOf(err) != connect.CodeUnimplemented {
+		t.Errorf("Orchestrate = %v; want Unimplemented", err)
+	}
+}
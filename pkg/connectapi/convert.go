@@ -0,0 +1,170 @@
+package connectapi
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/statsv1"
+)
+
+// runRequest converts run options to an orchestration request
+func runRequest(o *statsv1.RunOptions) (*models.OrchestrationRequest, error) {
+	req := &models.OrchestrationRequest{
+		Topic:                 o.GetTopic(),
+		MinVerifiedStats:      int(o.GetMinVerifiedStats()),
+		MaxCandidates:         int(o.GetMaxCandidates()),
+		ReputableOnly:         o.GetReputableOnly(),
+		Compare:               o.GetCompare(),
+		DryRun:                o.GetDryRun(),
+		IncludeSummary:        o.GetIncludeSummary(),
+		PermissiveLicenseOnly: o.GetPermissiveLicenseOnly(),
+		Reproducible:          o.GetReproducible(),
+		LLMProvider:           o.GetLlmProvider(),
+		LLMModel:              o.GetLlmModel(),
+	}
+	req.ExcludeProjections = o.GetExcludeProjections()
+	for _, s := range o.GetStatisticTypes() {
+		t, ok := models.ParseStatisticType(s)
+		if !ok {
+			return nil, fmt.Errorf("unknown statistic type %q", s)
+		}
+		req.StatisticTypes = append(req.StatisticTypes, t)
+	}
+	if strings.TrimSpace(req.Topic) == "" {
+		return nil, errors.New("topic is required")
+	}
+	if req.MinVerifiedStats < 0 || req.MaxCandidates < 0 {
+		return nil, errors.New("min_verified_stats and max_candidates must not be negative")
+	}
+	return req, nil
+}
+
+func toStatistic(s models.Statistic) *statsv1.Statistic {
+	out := &statsv1.Statistic{
+		Name:        s.Name,
+		Value:       float64(s.Value),
+		Unit:        s.Unit,
+		Source:      s.Source,
+		SourceUrl:   s.SourceURL,
+		Excerpt:     s.Excerpt,
+		Verified:    s.Verified,
+		Type:        string(s.Type),
+		Publisher:   s.Publisher,
+		PublishedAt: timestamp(s.PublishedAt),
+		ContentHash: s.ContentHash,
+		SafetyFlags: s.SafetyFlags,
+	}
+	if l := s.License; l != nil {
+		out.License = &statsv1.License{Kind: string(l.Kind), Url: l.URL, Notice: l.Notice, Permissive: l.Permissive}
+	}
+	for _, c := range s.CorroboratedBy {
+		out.CorroboratedBy = append(out.CorroboratedBy, &statsv1.Corroboration{Name: c.Name, Source: c.Source, SourceUrl: c.SourceURL})
+	}
+	return out
+}
+
+func toStatistics(stats []models.Statistic) []*statsv1.Statistic {
+	out := make([]*statsv1.Statistic, len(stats))
+	for i, s := range stats {
+		out[i] = toStatistic(s)
+	}
+	return out
+}
+
+func toStored(m models.StatisticMatch) *statsv1.StoredStatistic {
+	return &statsv1.StoredStatistic{
+		Id:         m.ID,
+		Statistic:  toStatistic(m.Statistic),
+		Topics:     m.Topics,
+		FirstSeen:  timestamp(m.FirstSeen),
+		LastSeen:   timestamp(m.LastSeen),
+		Domain:     m.Domain,
+		DomainTier: m.DomainTier,
+		Confidence: m.Confidence,
+		Score:      m.Score,
+	}
+}
+
+func toRun(r progress.Run) *statsv1.Run {
+	return &statsv1.Run{
+		Id:         r.ID,
+		Topic:      r.Topic,
+		Stage:      r.Stage,
+		Pass:       count(r.Pass),
+		Candidates: count(r.Candidates),
+		Verified:   count(r.Verified),
+		Failed:     count(r.Failed),
+		Target:     count(r.Target),
+		Status:     r.Status,
+		Error:      r.Error,
+		Recent:     toStatistics(r.Recent),
+		StartedAt:  timestamp(r.StartedAt),
+		UpdatedAt:  timestamp(r.UpdatedAt),
+	}
+}
+
+func toResult(r *models.OrchestrationResponse) *statsv1.Result {
+	if r == nil {
+		return nil
+	}
+	out := &statsv1.Result{
+		Topic:           r.Topic,
+		Status:          r.Status,
+		Partial:         r.Partial,
+		Statistics:      toStatistics(r.Statistics),
+		TotalCandidates: count(r.TotalCandidates),
+		VerifiedCount:   count(r.VerifiedCount),
+		FailedCount:     count(r.FailedCount),
+		TargetCount:     count(r.TargetCount),
+		SessionId:       r.SessionID,
+		ReportId:        r.ReportID,
+		Timestamp:       timestamp(r.Timestamp),
+	}
+	if r.CostSummary != nil {
+		out.EstimatedCostUsd = r.CostSummary.EstimatedCostUSD
+	}
+	return out
+}
+
+func toJob(j *models.Job) *statsv1.Job {
+	return &statsv1.Job{
+		Id:         j.ID,
+		Status:     string(j.Status),
+		Topic:      j.Request.Topic,
+		Attempts:   count(j.Attempts),
+		Error:      j.Error,
+		Result:     toResult(j.Response),
+		CreatedAt:  timestamp(j.CreatedAt),
+		StartedAt:  timestamp(j.StartedAt),
+		FinishedAt: timestamp(j.FinishedAt),
+	}
+}
+
+// eventTypes maps progress event types to their enum values
+var eventTypes = map[string]statsv1.EventType{
+	progress.EventStarted:   statsv1.EventType_EVENT_TYPE_STARTED,
+	progress.EventProgress:  statsv1.EventType_EVENT_TYPE_PROGRESS,
+	progress.EventFinished:  statsv1.EventType_EVENT_TYPE_FINISHED,
+	progress.EventReady:     statsv1.EventType_EVENT_TYPE_READY,
+	progress.EventRetracted: statsv1.EventType_EVENT_TYPE_RETRACTED,
+}
+
+// count converts a count to int32, saturating at its maximum
+func count(n int) int32 {
+	return int32(min(n, math.MaxInt32)) //nolint:gosec // G115: n is clamped
+}
+
+// timestamp returns nil for a zero time, leaving the field unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
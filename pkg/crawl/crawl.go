@@ -0,0 +1,449 @@
+// Package crawl follows links from publisher landing pages, such as a
+// research organization's topic page, to the report pages that hold the
+// numbers. The crawl is shallow: it stays on the landing page's site, reads
+// at most a few levels of landing pages, honors robots.txt, and falls back to
+// the site's sitemap when a page links nothing relevant.
+package crawl
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+const (
+	// maxPageBytes bounds how much of a landing page or sitemap is read
+	maxPageBytes = 2 << 20
+	// maxSitemaps bounds the sitemap files read per site, indexes included
+	maxSitemaps = 3
+	// minLinkScore is the score a link needs to be followed
+	minLinkScore = 2
+)
+
+// landingSegments are path segments of topic, category, and tag pages
+var landingSegments = map[string]bool{
+	"topic": true, "topics": true, "category": true, "categories": true,
+	"tag": true, "tags": true, "subject": true, "subjects": true,
+	"issue": true, "issues": true, "research-topics": true, "hub": true,
+	"collection": true, "collections": true, "series": true,
+}
+
+// reportWords mark paths of pages that usually present findings
+var reportWords = []string{
+	"report", "fact-sheet", "factsheet", "short-read", "data", "survey",
+	"study", "statistic", "analysis", "brief", "indicator", "findings",
+}
+
+// skipExtensions are linked files synthesis cannot read
+var skipExtensions = map[string]bool{
+	".pdf": true, ".jpg": true, ".jpeg": true, ".png": true, ".gif": true,
+	".svg": true, ".zip": true, ".mp3": true, ".mp4": true, ".doc": true,
+	".docx": true, ".ppt": true, ".pptx": true,
+}
+
+var yearSegment = regexp.MustCompile(`/(19|20)\d{2}/`)
+
+// Page is a report page found from a landing page
+type Page struct {
+	URL   string
+	Title string // Link text, or the sitemap entry's URL slug
+	From  string // Landing page the link was found on
+	Depth int    // 1 for links on the landing page itself
+}
+
+// Crawler finds report pages linked from landing pages
+type Crawler struct {
+	client   *http.Client
+	identity *httpclient.Identity // Nil sends the default User-Agent
+	maxDepth int
+	maxPages int
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	robots map[string]cachedRobots // By scheme and host
+}
+
+// How long robots.txt rules are kept: a day for a file that was read or
+// is missing, as RFC 9309 allows, and a few minutes for one that failed, so
+// a passing outage does not keep a site disallowed
+const (
+	robotsTTL      = 24 * time.Hour
+	robotsRetryTTL = 5 * time.Minute
+)
+
+// cachedRobots is a site's robots.txt rules and when to read them again
+type cachedRobots struct {
+	rules   *robots
+	expires time.Time
+}
+
+// New creates a crawler that reads landing pages up to maxDepth levels deep
+// and returns at most maxPages report pages per landing page, presenting
+// identity to sites and their robots.txt
+func New(client *http.Client, identity *httpclient.Identity, maxDepth, maxPages int, logger *slog.Logger) *Crawler {
+	if client == nil {
+		client = httpclient.NewFetchClient(15*time.Second, nil)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Crawler{
+		client:   client,
+		identity: identity,
+		maxDepth: max(maxDepth, 1),
+		maxPages: max(maxPages, 1),
+		logger:   logger,
+		robots:   make(map[string]cachedRobots),
+	}
+}
+
+// FromConfig creates the crawler configured by CRAWL_MAX_DEPTH and
+// CRAWL_MAX_PAGES. It returns nil when CRAWL_ENABLED is off.
+func FromConfig(cfg *config.Config, logger *slog.Logger) *Crawler {
+	if !cfg.CrawlEnabled {
+		return nil
+	}
+	return New(nil, cfg.FetchIdentity, cfg.CrawlMaxDepth, cfg.CrawlMaxPages, logger)
+}
+
+// IsLandingPage reports whether a URL looks like a site's home, topic,
+// category, or tag page rather than an article
+func IsLandingPage(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	p := strings.Trim(u.Path, "/")
+	if p == "" {
+		return true
+	}
+	if ext := path.Ext(p); ext != "" && ext != ".html" && ext != ".htm" {
+		return false
+	}
+	segments := strings.Split(strings.ToLower(p), "/")
+	for _, s := range segments[:len(segments)-1] {
+		if landingSegments[s] {
+			return true
+		}
+	}
+	return len(segments) == 1 && landingSegments[segments[0]]
+}
+
+// Crawl returns the report pages linked from a landing page, most relevant
+// to topic first. A nil crawler returns nothing.
+func (c *Crawler) Crawl(ctx context.Context, topic, landingURL string) []Page {
+	if c == nil {
+		return nil
+	}
+	landing, err := url.Parse(landingURL)
+	if err != nil || landing.Host == "" {
+		return nil
+	}
+	terms := topicTerms(topic)
+
+	seen := map[string]bool{key(landing): true}
+	var pages []Page
+	queue := []*url.URL{landing}
+	for depth := 1; depth <= c.maxDepth && len(queue) > 0 && len(pages) < c.maxPages; depth++ {
+		var next []*url.URL
+		for _, page := range queue {
+			links, err := c.links(ctx, page, terms)
+			if err != nil {
+				c.logger.Debug("crawl skipped page", "url", page.String(), "error", err)
+				continue
+			}
+			if len(links) == 0 && depth == 1 {
+				links = c.sitemapLinks(ctx, landing, terms)
+			}
+			for _, l := range links {
+				k := key(l.u)
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				if IsLandingPage(l.u.String()) {
+					next = append(next, l.u)
+					continue
+				}
+				if len(pages) < c.maxPages {
+					pages = append(pages, Page{URL: l.u.String(), Title: l.text, From: landingURL, Depth: depth})
+				}
+			}
+		}
+		queue = next
+	}
+
+	c.logger.Debug("crawled landing page", "url", landingURL, "pages", len(pages))
+	return pages
+}
+
+// link is a scored candidate link
+type link struct {
+	u     *url.URL
+	text  string
+	score int
+}
+
+// links fetches a page and returns its relevant same-site links, best first
+func (c *Crawler) links(ctx context.Context, page *url.URL, terms []string) ([]link, error) {
+	if !c.allowed(ctx, page) {
+		return nil, fmt.Errorf("disallowed by robots.txt")
+	}
+	body, err := c.get(ctx, page.String())
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []link
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			for _, a := range n.Attr {
+				if a.Key != "href" {
+					continue
+				}
+				if l, ok := c.candidate(ctx, page, a.Val, extract.NodeText(n), terms); ok {
+					found = append(found, l)
+				}
+			}
+			return
+		}
+		for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+			walk(ch)
+		}
+	}
+	walk(doc)
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+	return found, nil
+}
+
+// candidate resolves an href against the page and scores it, rejecting
+// off-site, non-page, and disallowed links
+func (c *Crawler) candidate(ctx context.Context, page *url.URL, href, text string, terms []string) (link, bool) {
+	u, err := page.Parse(strings.TrimSpace(href))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !sameSite(u, page) {
+		return link{}, false
+	}
+	u.Fragment = ""
+	if key(u) == key(page) || skipExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return link{}, false
+	}
+	score := scoreLink(u, text, terms)
+	if score < minLinkScore || !c.allowed(ctx, u) {
+		return link{}, false
+	}
+	return link{u: u, text: text, score: score}, true
+}
+
+// scoreLink rates a link by topic terms in its text and path, words that
+// mark reports, and a year in the path. Landing pages are scored the same
+// way so only relevant ones are followed.
+func scoreLink(u *url.URL, text string, terms []string) int {
+	p := strings.ToLower(u.Path)
+	haystack := strings.ToLower(text) + " " + strings.NewReplacer("-", " ", "_", " ", "/", " ").Replace(p)
+	score := 0
+	for _, t := range terms {
+		if strings.Contains(haystack, t) {
+			score += 2
+		}
+	}
+	for _, w := range reportWords {
+		if strings.Contains(p, w) {
+			score++
+			break
+		}
+	}
+	if yearSegment.MatchString(p) {
+		score++
+	}
+	return score
+}
+
+// sitemapDoc is a sitemap <urlset> or a <sitemapindex> of other sitemaps
+type sitemapDoc struct {
+	XMLName xml.Name
+	URLs    []sitemapEntry `xml:"url"`
+	Maps    []sitemapEntry `xml:"sitemap"`
+}
+
+// sitemapEntry is a <url> or <sitemap> element
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// sitemapLinks scores the URLs of the site's sitemap (from robots.txt, or
+// /sitemap.xml) for pages whose path mentions the topic. Of a sitemap
+// index, only the most recently modified sitemaps are read.
+func (c *Crawler) sitemapLinks(ctx context.Context, site *url.URL, terms []string) []link {
+	maps := c.robotsFor(ctx, site).sitemaps
+	if len(maps) == 0 {
+		maps = []string{site.Scheme + "://" + site.Host + "/sitemap.xml"}
+	}
+
+	var found []link
+	for i := 0; i < len(maps) && i < maxSitemaps; i++ {
+		doc, err := c.sitemap(ctx, maps[i])
+		if err != nil {
+			c.logger.Debug("failed to read sitemap", "url", maps[i], "error", err)
+			continue
+		}
+		if len(doc.Maps) > 0 {
+			sort.SliceStable(doc.Maps, func(a, b int) bool { return doc.Maps[a].LastMod > doc.Maps[b].LastMod })
+			for _, m := range doc.Maps[:min(maxSitemaps-1, len(doc.Maps))] {
+				maps = append(maps, m.Loc)
+			}
+			continue
+		}
+		for _, e := range doc.URLs {
+			u, err := url.Parse(strings.TrimSpace(e.Loc))
+			if err != nil || !sameSite(u, site) || skipExtensions[strings.ToLower(path.Ext(u.Path))] {
+				continue
+			}
+			// Sitemap entries have no link text; terms must appear in the path
+			if score := scoreLink(u, "", terms); score >= minLinkScore+1 && c.allowed(ctx, u) {
+				found = append(found, link{u: u, text: slugTitle(u), score: score})
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score > found[j].score })
+	return found
+}
+
+// sitemap fetches and parses a sitemap or sitemap index
+func (c *Crawler) sitemap(ctx context.Context, rawURL string) (*sitemapDoc, error) {
+	body, err := c.get(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	var doc sitemapDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+	return &doc, nil
+}
+
+// allowed checks a URL against its site's robots.txt
+func (c *Crawler) allowed(ctx context.Context, u *url.URL) bool {
+	return c.robotsFor(ctx, u).allowed(u.RequestURI())
+}
+
+// robotsFor returns the cached robots.txt rules of a URL's site, reading
+// them again once they expire
+func (c *Crawler) robotsFor(ctx context.Context, u *url.URL) *robots {
+	site := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	cached, ok := c.robots[site]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.rules
+	}
+
+	r, ttl := c.fetchRobots(ctx, site)
+	if ctx.Err() != nil {
+		return r // The caller gave up; that says nothing of the site
+	}
+	c.mu.Lock()
+	c.robots[site] = cachedRobots{rules: r, expires: time.Now().Add(ttl)}
+	c.mu.Unlock()
+	return r
+}
+
+// fetchRobots reads a site's robots.txt and returns its rules and how long
+// to keep them. Following RFC 9309, a file the server says is unavailable
+// (4xx) allows everything, and one it fails to serve (5xx, or a network
+// error) allows nothing until it is read again.
+func (c *Crawler) fetchRobots(ctx context.Context, site string) (*robots, time.Duration) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, site+"/robots.txt", nil)
+	if err != nil {
+		return disallowAll, robotsRetryTTL
+	}
+	c.identity.Apply(req)
+	resp, err := c.client.Do(req) //nolint:gosec // G704: site of a search result
+	if err != nil {
+		return disallowAll, robotsRetryTTL
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500:
+		return disallowAll, robotsRetryTTL
+	case resp.StatusCode >= 400:
+		return allowAll, robotsTTL
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return disallowAll, robotsRetryTTL
+	}
+	return parseRobots(body, c.identity.RobotsToken()), robotsTTL
+}
+
+// get fetches a URL, reading at most maxPageBytes
+func (c *Crawler) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.identity.Apply(req)
+	resp, err := c.client.Do(req) //nolint:gosec // G704: URL on the site of a search result
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+}
+
+// topicTerms returns the lowercased words of a topic that are long enough
+// to be meaningful in a URL
+func topicTerms(topic string) []string {
+	var terms []string
+	for _, w := range strings.Fields(strings.ToLower(topic)) {
+		if w = strings.Trim(w, `.,;:!?"'()`); len(w) > 3 {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// sameSite reports whether two URLs share a host, ignoring "www."
+func sameSite(a, b *url.URL) bool {
+	return strings.TrimPrefix(strings.ToLower(a.Hostname()), "www.") ==
+		strings.TrimPrefix(strings.ToLower(b.Hostname()), "www.")
+}
+
+// key identifies a URL for deduplication
+func key(u *url.URL) string {
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") + strings.TrimSuffix(u.EscapedPath(), "/") + "?" + u.RawQuery
+}
+
+// slugTitle turns the last path segment of a URL into a title
+func slugTitle(u *url.URL) string {
+	slug := path.Base(strings.TrimSuffix(u.Path, "/"))
+	slug = strings.TrimSuffix(slug, path.Ext(slug))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(slug)
+}
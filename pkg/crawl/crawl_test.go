@@ -0,0 +1,166 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCrawl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private/\nSitemap: http://"+r.Host+"/sitemap.xml\n")
+	})
+	mux.HandleFunc("/topics/remote-work/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body>
+			<a href="/about/">About us</a>
+			<a href="/short-reads/2024/03/30/remote-work-facts/">5 facts about remote work</a>
+			<a href="/private/2024/remote-work-draft/">Draft remote work report</a>
+			<a href="/reports/remote-work.pdf">Remote work report (PDF)</a>
+			<a href="https://other.example/remote-work-study">Remote work study elsewhere</a>
+			<a href="/topics/remote-work/">Remote work</a>
+			<a href="/topics/hybrid-work/">Hybrid work</a>
+			<a href="/2023/01/telework-survey/#results">Remote telework survey</a>
+		</body></html>`)
+	})
+	mux.HandleFunc("/topics/hybrid-work/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="/data/hybrid-work-trends/">Hybrid work trends</a>`)
+	})
+	mux.HandleFunc("/topics/commuting/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><div id="app"></div></body></html>`)
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<urlset>
+			<url><loc>http://%[1]s/2024/commuting-times-report/</loc></url>
+			<url><loc>http://%[1]s/2024/gardening-tips/</loc></url>
+		</urlset>`, r.Host)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(srv.Client(), nil, 2, 5, nil)
+	pages := c.Crawl(context.Background(), "remote work hybrid", srv.URL+"/topics/remote-work/")
+
+	want := []string{
+		srv.URL + "/short-reads/2024/03/30/remote-work-facts/",
+		srv.URL + "/2023/01/telework-survey/",
+		srv.URL + "/data/hybrid-work-trends/", // via the nested landing page
+	}
+	if len(pages) != len(want) {
+		t.Fatalf("got %d pages: %+v", len(pages), pages)
+	}
+	for i, p := range pages {
+		if p.URL != want[i] {
+			t.Errorf("pages[%d] = %s, want %s", i, p.URL, want[i])
+		}
+	}
+	if pages[0].Title != "5 facts about remote work" || pages[2].Depth != 2 {
+		t.Errorf("unexpected page details: %+v", pages)
+	}
+
+	// A page with no relevant links falls back to the sitemap
+	pages = c.Crawl(context.Background(), "commuting times", srv.URL+"/topics/commuting/")
+	if len(pages) != 1 || pages[0].URL != srv.URL+"/2024/commuting-times-report/" || pages[0].Title != "commuting times report" {
+		t.Errorf("sitemap pages = %+v", pages)
+	}
+}
+
+func TestRobots(t *testing.T) {
+	r := parseRobots([]byte(`
+User-agent: Googlebot
+Disallow: /
+
+User-agent: StatsAgentTeam
+User-agent: OtherBot
+Disallow: /search
+Disallow: /*.json$
+Allow: /search/about
+
+User-agent: *
+Disallow: /
+`), "statsagentteam")
+
+	tests := map[string]bool{
+		"/":                true,
+		"/search?q=x":      false,
+		"/search/about":    true,
+		"/data/file.json":  false,
+		"/data/file.jsonl": true,
+	}
+	for path, want := range tests {
+		if got := r.allowed(path); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRobotsCache(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+	}))
+	defer srv.Close()
+
+	c := New(srv.Client(), nil, 1, 1, nil)
+	page, _ := url.Parse(srv.URL + "/report")
+	private, _ := url.Parse(srv.URL + "/private/report")
+	expiresIn := func() time.Duration {
+		return time.Until(c.robots[srv.URL].expires)
+	}
+
+	// A failing server disallows everything, but only briefly
+	if c.allowed(context.Background(), page) || expiresIn() > robotsRetryTTL {
+		t.Fatalf("after 503: allowed, or kept for %v", expiresIn())
+	}
+	status = http.StatusOK
+	if c.allowed(context.Background(), page) {
+		t.Error("the failure should be cached until it expires")
+	}
+
+	// Read again once it expires, and kept for a day
+	c.robots[srv.URL] = cachedRobots{rules: c.robots[srv.URL].rules, expires: time.Now().Add(-time.Second)}
+	if !c.allowed(context.Background(), page) || c.allowed(context.Background(), private) || expiresIn() < robotsTTL-time.Minute {
+		t.Errorf("after 200: rules not applied, or kept for %v", expiresIn())
+	}
+
+	// A missing file allows everything
+	status = http.StatusNotFound
+	c.robots = make(map[string]cachedRobots)
+	if !c.allowed(context.Background(), private) {
+		t.Error("after 404: should allow everything")
+	}
+
+	// A cancelled caller caches nothing
+	c.robots = make(map[string]cachedRobots)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.allowed(ctx, page)
+	if _, ok := c.robots[srv.URL]; ok {
+		t.Error("a cancelled fetch should not be cached")
+	}
+}
+
+func TestIsLandingPage(t *testing.T) {
+	tests := map[string]bool{
+		"https://www.pewresearch.org/":                    true,
+		"https://www.pewresearch.org/topic/economy-work/": true,
+		"https://example.com/category/health/page/2":      true,
+		"https://example.com/topics":                      true,
+		"https://example.com/2024/05/jobs-report/":        false,
+		"https://example.com/topics/health/report.pdf":    false,
+		"https://example.com/blog/why-topics-matter/":     false,
+	}
+	for u, want := range tests {
+		if got := IsLandingPage(u); got != want {
+			t.Errorf("IsLandingPage(%s) = %v, want %v", u, got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package crawl
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// robots holds the robots.txt rules that apply to the crawler
+type robots struct {
+	rules    []robotsRule
+	sitemaps []string
+}
+
+// robotsRule is an Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// allowAll is used for sites without a robots.txt
+var allowAll = &robots{}
+
+// disallowAll is used when robots.txt cannot be read, as a server error may
+// hide a ban
+var disallowAll = &robots{rules: []robotsRule{{pattern: "/", re: regexp.MustCompile(`^/`)}}}
+
+// parseRobots reads the group for agent (a product token such as
+// "statsagentteam"), or the "*" group when there is none. Sitemap lines
+// apply regardless of group.
+func parseRobots(body []byte, agent string) *robots {
+	agent = strings.ToLower(agent)
+
+	type group struct {
+		agents []string
+		rules  []robotsRule
+	}
+	var groups []*group
+	var current *group
+	inRules := false
+	r := &robots{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive User-agent lines share one group
+			if current == nil || inRules {
+				current = &group{}
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if value == "" {
+				continue // An empty Disallow allows everything
+			}
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				pattern: value,
+				re:      robotsPattern(value),
+			})
+		case "sitemap":
+			r.sitemaps = append(r.sitemaps, value)
+		}
+	}
+
+	var fallback *group
+	for _, g := range groups {
+		for _, a := range g.agents {
+			if a == "*" && fallback == nil {
+				fallback = g
+			}
+			if a != "*" && strings.Contains(agent, a) {
+				r.rules = g.rules
+				return r
+			}
+		}
+	}
+	if fallback != nil {
+		r.rules = fallback.rules
+	}
+	return r
+}
+
+// robotsPattern compiles a path pattern with * wildcards and a $ end anchor
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed reports whether a path (with query) may be fetched: the longest
+// matching rule wins, and Allow wins a tie
+func (r *robots) allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best, allow = n, rule.allow
+		}
+	}
+	return allow
+}
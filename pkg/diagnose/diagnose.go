@@ -0,0 +1,392 @@
+// Package diagnose checks a configuration end to end: that the configured
+// LLM and search providers have usable credentials and answer, and that the
+// agents are reachable. It backs the `stats-agent config validate` command.
+package diagnose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/replay"
+	"github.com/plexusone/agent-team-stats/pkg/search"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusOK   Status = "ok"   // The check passed
+	StatusWarn Status = "warn" // Something is missing that not every setup needs
+	StatusFail Status = "fail" // The configuration will not work
+	StatusSkip Status = "skip" // The check was not run
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Check  string `json:"check"`
+	Status Status `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Agent is an agent whose health endpoint is checked
+type Agent struct {
+	Name string
+	URL  string
+}
+
+// Options controls which checks run
+type Options struct {
+	// Ping sends a one-line prompt to each LLM and a one-result query to the
+	// search provider; without it only credentials are checked
+	Ping bool
+	// Timeout bounds each provider call and health check
+	Timeout time.Duration
+	// Agents are the agents whose /health endpoints are checked
+	Agents []Agent
+}
+
+// pingPrompt is the prompt sent to check that an LLM answers
+const pingPrompt = "Reply with the single word OK."
+
+// AgentsFromConfig returns the configured agents
+func AgentsFromConfig(cfg *config.Config) []Agent {
+	agents := []Agent{
+		{Name: "research", URL: cfg.ResearchAgentURL},
+		{Name: "synthesis", URL: cfg.SynthesisAgentURL},
+		{Name: "verification", URL: cfg.VerificationAgentURL},
+		{Name: "orchestrator", URL: cfg.OrchestratorURL},
+	}
+	if cfg.OrchestratorEinoURL != cfg.OrchestratorURL {
+		agents = append(agents, Agent{Name: "orchestrator-eino", URL: cfg.OrchestratorEinoURL})
+	}
+	return agents
+}
+
+// Run runs every check against cfg
+func Run(ctx context.Context, cfg *config.Config, opts Options) []Result {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 15 * time.Second
+	}
+
+	results := []Result{checkConfigFile()}
+	results = append(results, checkModels(ctx, cfg, opts)...)
+	results = append(results, checkAllowlist(cfg))
+	results = append(results, checkPromptBudget(cfg))
+	results = append(results, checkFetchIdentity(cfg))
+	results = append(results, checkSearch(ctx, cfg, opts))
+	results = append(results, checkObservability(cfg))
+	results = append(results, checkA2APublicURL(cfg))
+	results = append(results, checkAgents(ctx, opts)...)
+	return results
+}
+
+// Failed reports whether any check failed
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTable writes the results as an aligned table
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Check, strings.ToUpper(string(r.Status)), r.Detail)
+	}
+	return tw.Flush()
+}
+
+// checkConfigFile reports which config.json is in use
+func checkConfigFile() Result {
+	r := Result{Check: "config file"}
+	if path := config.FilePath(); path != "" {
+		r.Status, r.Detail = StatusOK, path
+	} else {
+		r.Status, r.Detail = StatusWarn, "no config.json found, using environment variables only"
+	}
+	return r
+}
+
+// checkModels creates the model of each pipeline stage, which fails on an
+// unsupported provider or missing credentials, and optionally pings it.
+// Stages that share a provider and model are checked once.
+func checkModels(ctx context.Context, cfg *config.Config, opts Options) []Result {
+	factory := llm.NewModelFactory(ctx, cfg)
+	defer factory.Close()
+
+	stages := []llm.Stage{"", llm.StageSynthesis, llm.StageVerification, llm.StagePlanning}
+	if cfg.FigureExtraction {
+		stages = append(stages, llm.StageVision)
+	}
+	byModel := make(map[string][]string)
+	var specs []string
+	first := make(map[string]llm.Stage)
+	for _, stage := range stages {
+		provider, modelName := factory.StageModel(stage)
+		spec := provider + ":" + modelName
+		if _, ok := byModel[spec]; !ok {
+			specs = append(specs, spec)
+			first[spec] = stage
+		}
+		name := string(stage)
+		if stage == "" {
+			name = "default"
+		}
+		byModel[spec] = append(byModel[spec], name)
+	}
+
+	results := make([]Result, 0, len(specs)+1)
+	if provider, modelName := factory.StageModel(llm.StageVision); cfg.FigureExtraction && !llm.SupportsImages(provider) {
+		results = append(results, Result{
+			Check:  "llm (vision)",
+			Status: StatusFail,
+			Detail: fmt.Sprintf("%s:%s cannot read images; set VISION_MODEL to a Gemini model", provider, modelName),
+		})
+	}
+	for _, spec := range specs {
+		r := Result{Check: fmt.Sprintf("llm (%s)", strings.Join(byModel[spec], ", "))}
+		m, err := factory.CreateModelFor(ctx, first[spec])
+		switch {
+		case err != nil:
+			r.Status, r.Detail = StatusFail, err.Error()
+		case cfg.LLMReplayMode == replay.ModeReplay:
+			r.Status, r.Detail = StatusOK, spec+" (replaying fixtures from "+cfg.LLMReplayDir+")"
+		case !opts.Ping:
+			r.Status, r.Detail = StatusOK, spec+" (credentials set, not pinged)"
+		default:
+			start := time.Now()
+			if err := ping(ctx, m, opts.Timeout); err != nil {
+				r.Status, r.Detail = StatusFail, fmt.Sprintf("%s: %v", spec, err)
+			} else {
+				r.Status, r.Detail = StatusOK, fmt.Sprintf("%s answered in %s", spec, time.Since(start).Round(time.Millisecond))
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// ping sends a short prompt and waits for any answer
+func ping(ctx context.Context, m model.LLM, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req := &model.LLMRequest{Contents: genai.Text(pingPrompt)}
+	for resp, err := range m.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return err
+		}
+		if resp != nil && resp.Content != nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("empty response")
+}
+
+// checkAllowlist reports LLM_MODEL_ALLOWLIST entries that can never match
+func checkAllowlist(cfg *config.Config) Result {
+	r := Result{Check: "llm allowlist"}
+	if len(cfg.LLMModelAllowlist) == 0 {
+		r.Status, r.Detail = StatusOK, "empty (per-request model overrides disabled)"
+		return r
+	}
+	var invalid []string
+	for _, entry := range cfg.LLMModelAllowlist {
+		if o := llm.ParseModelSpec(entry); o == nil || o.Provider == "" || o.Model == "" {
+			invalid = append(invalid, entry)
+		}
+	}
+	if len(invalid) > 0 {
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("not provider:model or provider:*: %s", strings.Join(invalid, ", "))
+		return r
+	}
+	r.Status, r.Detail = StatusOK, strings.Join(cfg.LLMModelAllowlist, ", ")
+	return r
+}
+
+// minPageTokens is the page text below which extraction misses most of a
+// page's statistics
+const minPageTokens = 2000
+
+// checkPromptBudget reports which extraction prompt the synthesis model
+// gets and how much page text it has room for, warning when its context
+// window leaves too little
+func checkPromptBudget(cfg *config.Config) Result {
+	r := Result{Check: "prompt budget"}
+	b, err := llm.RunBudget(cfg, llm.StageSynthesis, nil, nil)
+	if err != nil {
+		r.Status, r.Detail = StatusFail, err.Error()
+		return r
+	}
+	profile, err := prompts.ParseProfile(cfg.SynthesisPromptProfile)
+	if err != nil {
+		r.Status, r.Detail = StatusFail, err.Error()
+		return r
+	}
+	profile = profile.Resolve(llm.SmallModel(b.Provider, b.Model))
+	set, err := prompts.FromConfig(cfg)
+	if err != nil {
+		r.Status, r.Detail = StatusFail, err.Error()
+		return r
+	}
+	prompt, err := set.Render(profile.Extract(), prompts.ExtractData{})
+	if err != nil {
+		r.Status, r.Detail = StatusFail, err.Error()
+		return r
+	}
+	pageTokens := b.Room(prompt)
+	if profile == prompts.ProfileCompact {
+		pageTokens = min(pageTokens, prompts.CompactChunkTokens) * prompts.CompactMaxChunks
+	}
+	if cfg.SynthesisPageTokens > 0 {
+		pageTokens = min(pageTokens, cfg.SynthesisPageTokens)
+	}
+	r.Detail = fmt.Sprintf("%s:%s has a %d-token context window, %d reserved for output; pages get up to %d tokens with the %s prompt",
+		b.Provider, b.Model, b.Window, b.Output, pageTokens, profile)
+	if pageTokens < minPageTokens {
+		r.Status = StatusWarn
+		r.Detail += "; raise the window with LLM_CONTEXT_WINDOWS if the model allows more"
+		return r
+	}
+	r.Status = StatusOK
+	return r
+}
+
+// checkFetchIdentity reports the User-Agent page fetches send, how many
+// sites get their own, and which sites get credentials, never the
+// credentials themselves
+func checkFetchIdentity(cfg *config.Config) Result {
+	r := Result{Check: "fetch identity", Status: StatusOK}
+	id := cfg.FetchIdentity
+	r.Detail = id.UserAgentFor("")
+	if id != nil && len(id.Overrides) > 0 {
+		r.Detail += fmt.Sprintf("; %d domain overrides", len(id.Overrides))
+	}
+	if len(cfg.FetchCredentials) > 0 {
+		r.Detail += "; credentials for " + strings.Join(cfg.FetchCredentials.Domains(), ", ")
+	}
+	return r
+}
+
+// checkSearch creates the search client, which fails on an unsupported
+// provider or missing key, and optionally runs a one-result query
+func checkSearch(ctx context.Context, cfg *config.Config, opts Options) Result {
+	r := Result{Check: "search"}
+	svc, err := search.NewService(cfg)
+	if err != nil {
+		r.Status, r.Detail = StatusFail, err.Error()
+		return r
+	}
+	if !opts.Ping {
+		r.Status, r.Detail = StatusOK, cfg.SearchProvider+" (credentials set, not pinged)"
+		return r
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	start := time.Now()
+	if _, err := svc.Search(ctx, "statistics", 1); err != nil {
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("%s: %v", cfg.SearchProvider, err)
+		return r
+	}
+	r.Status, r.Detail = StatusOK, fmt.Sprintf("%s answered in %s", cfg.SearchProvider, time.Since(start).Round(time.Millisecond))
+	return r
+}
+
+// checkObservability reports an enabled provider without credentials
+func checkObservability(cfg *config.Config) Result {
+	r := Result{Check: "observability"}
+	switch {
+	case !cfg.ObservabilityEnabled:
+		r.Status, r.Detail = StatusSkip, "disabled"
+	case cfg.ObservabilityAPIKey == "" && cfg.ObservabilityEndpoint == "":
+		r.Status, r.Detail = StatusWarn, cfg.ObservabilityProvider+" enabled without OBSERVABILITY_API_KEY or OBSERVABILITY_ENDPOINT"
+	default:
+		r.Status, r.Detail = StatusOK, cfg.ObservabilityProvider+" (project "+cfg.ObservabilityProject+")"
+	}
+	return r
+}
+
+// checkA2APublicURL checks the URL agents advertise in their A2A agent cards
+func checkA2APublicURL(cfg *config.Config) Result {
+	r := Result{Check: "a2a public url"}
+	u, err := cfg.A2APublicBaseURL()
+	switch {
+	case !cfg.A2AEnabled:
+		r.Status, r.Detail = StatusSkip, "A2A disabled"
+	case err != nil:
+		r.Status, r.Detail = StatusFail, err.Error()
+	case u == nil:
+		r.Status, r.Detail = StatusSkip, "not set; agent cards advertise the listen address"
+	default:
+		r.Status, r.Detail = StatusOK, u.String()
+	}
+	return r
+}
+
+// checkAgents checks every agent's /health endpoint concurrently. An
+// unreachable agent is a warning, since not every deployment runs them all.
+func checkAgents(ctx context.Context, opts Options) []Result {
+	client := &http.Client{Timeout: opts.Timeout}
+	results := make([]Result, len(opts.Agents))
+	var wg sync.WaitGroup
+	for i, a := range opts.Agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkAgent(ctx, client, a)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// checkAgent checks one agent's /health endpoint
+func checkAgent(ctx context.Context, client *http.Client, a Agent) Result {
+	r := Result{Check: "agent " + a.Name}
+	if a.URL == "" {
+		r.Status, r.Detail = StatusSkip, "no URL configured"
+		return r
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(a.URL, "/")+"/health", nil)
+	if err != nil {
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("invalid URL %s: %v", a.URL, err)
+		return r
+	}
+	start := time.Now()
+	resp, err := client.Do(req) //nolint:gosec // G704: agent URLs from configuration
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		r.Status, r.Detail = StatusWarn, fmt.Sprintf("%s unreachable: %v", a.URL, err)
+		return r
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		r.Status, r.Detail = StatusWarn, fmt.Sprintf("%s returned HTTP %d", a.URL, resp.StatusCode)
+		return r
+	}
+	r.Status, r.Detail = StatusOK, fmt.Sprintf("%s healthy in %s", a.URL, time.Since(start).Round(time.Millisecond))
+	return r
+}
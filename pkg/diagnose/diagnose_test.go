@@ -0,0 +1,111 @@
+package diagnose
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+func TestCheckModels(t *testing.T) {
+	cfg := &config.Config{
+		Config:         &akconfig.Config{LLMProvider: "gemini", GeminiAPIKey: "test-key", LLMModel: "gemini-2.5-flash"},
+		SynthesisModel: "claude:claude-sonnet-4",
+		PlanningModel:  "gemini-2.5-flash",
+	}
+
+	results := checkModels(context.Background(), cfg, Options{})
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %+v", len(results), results)
+	}
+	if results[0].Check != "llm (default, verification, planning)" || results[0].Status != StatusOK {
+		t.Errorf("first = %+v", results[0])
+	}
+	if results[1].Check != "llm (synthesis)" || results[1].Status != StatusFail || !strings.Contains(results[1].Detail, "claude API key not set") {
+		t.Errorf("second = %+v", results[1])
+	}
+}
+
+func TestCheckAllowlist(t *testing.T) {
+	cfg := &config.Config{LLMModelAllowlist: []string{"openai:gpt-4o-mini", "claude:*", "gpt-4o"}}
+	r := checkAllowlist(cfg)
+	if r.Status != StatusFail || !strings.HasSuffix(r.Detail, ": gpt-4o") {
+		t.Errorf("checkAllowlist() = %+v", r)
+	}
+}
+
+func TestCheckFetchIdentity(t *testing.T) {
+	id, err := httpclient.NewIdentity("ExampleBot/1.0", "mailto:ops@example.org", "", []string{"example.com=Mozilla/5.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := httpclient.Credentials{"intranet.example.com": {Username: "svc", Password: "secret"}}
+	r := checkFetchIdentity(&config.Config{FetchIdentity: id, FetchCredentials: credentials})
+	if r.Status != StatusOK || r.Detail != "ExampleBot/1.0 (+mailto:ops@example.org); 1 domain overrides; credentials for intranet.example.com" {
+		t.Errorf("checkFetchIdentity() = %+v", r)
+	}
+}
+
+func TestCheckAgents(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer healthy.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	results := checkAgents(context.Background(), Options{
+		Timeout: time.Second,
+		Agents: []Agent{
+			{Name: "research", URL: healthy.URL + "/"},
+			{Name: "synthesis", URL: down.URL},
+			{Name: "direct"},
+		},
+	})
+	want := []Status{StatusOK, StatusWarn, StatusSkip}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("%s = %s (%s), want %s", r.Check, r.Status, r.Detail, want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "agent research") || Failed(results) {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestCheckPromptBudget(t *testing.T) {
+	cfg := &config.Config{Config: &akconfig.Config{LLMProvider: "ollama", LLMModel: "llama3.2"}}
+	if r := checkPromptBudget(cfg); r.Status != StatusOK || !strings.HasSuffix(r.Detail, "with the compact prompt") {
+		t.Errorf("checkPromptBudget() = %+v", r)
+	}
+
+	cfg.SynthesisPromptProfile = "full"
+	if r := checkPromptBudget(cfg); r.Status != StatusWarn || !strings.Contains(r.Detail, "4096-token context window") {
+		t.Errorf("checkPromptBudget() with the full prompt = %+v", r)
+	}
+
+	cfg.LLMContextWindows = []string{"llama3.2=32768"}
+	if r := checkPromptBudget(cfg); r.Status != StatusOK {
+		t.Errorf("checkPromptBudget() with window = %+v", r)
+	}
+
+	cfg.LLMContextWindows = []string{"llama3.2"}
+	if r := checkPromptBudget(cfg); r.Status != StatusFail {
+		t.Errorf("checkPromptBudget() with invalid window = %+v", r)
+	}
+}
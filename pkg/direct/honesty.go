@@ -0,0 +1,112 @@
+package direct
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/plexusone/agent-team-stats/pkg/eval"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// HonestyTracker aggregates honesty reports per provider and model so
+// providers can be compared across runs. It is safe for concurrent use.
+type HonestyTracker struct {
+	mu      sync.Mutex
+	byModel map[string]*models.ProviderHonesty
+}
+
+// NewHonestyTracker creates an empty tracker
+func NewHonestyTracker() *HonestyTracker {
+	return &HonestyTracker{byModel: make(map[string]*models.ProviderHonesty)}
+}
+
+// Record adds a run's report to its provider's totals
+func (t *HonestyTracker) Record(r *models.HonestyReport) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := r.Provider + ":" + r.Model
+	agg, ok := t.byModel[key]
+	if !ok {
+		agg = &models.ProviderHonesty{HonestyReport: models.HonestyReport{Provider: r.Provider, Model: r.Model}}
+		t.byModel[key] = agg
+	}
+	agg.Runs++
+	agg.Checked += r.Checked
+	agg.URLsResolved += r.URLsResolved
+	agg.ExcerptsFound += r.ExcerptsFound
+	agg.Misquoted += r.Misquoted
+	agg.Fabricated += r.Fabricated
+	if agg.Checked > 0 {
+		agg.Score = float64(agg.ExcerptsFound) / float64(agg.Checked)
+	}
+}
+
+// Snapshot returns the per-provider totals, most honest first
+func (t *HonestyTracker) Snapshot() []models.ProviderHonesty {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]models.ProviderHonesty, 0, len(t.byModel))
+	for _, agg := range t.byModel {
+		out = append(out, *agg)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Provider+out[i].Model < out[j].Provider+out[j].Model
+	})
+	return out
+}
+
+// scoreHonesty checks the sources of unverified LLM results, marks only the
+// statistics found in their sources as verified, and returns the run's report
+func (s *LLMSearchService) scoreHonesty(ctx context.Context, stats []models.Statistic) *models.HonestyReport {
+	checks := eval.CheckStatistics(ctx, s.fetch, stats)
+	for i, c := range checks {
+		stats[i].Verified = c.Status == eval.StatusPresent
+	}
+
+	provider, modelName := s.modelFactory.StageModel("")
+	report := newHonestyReport(provider, modelName, checks)
+	s.honesty.Record(report)
+
+	s.logger.Info("honesty check completed",
+		"provider", report.Provider,
+		"model", report.Model,
+		"checked", report.Checked,
+		"urls_resolved", report.URLsResolved,
+		"excerpts_found", report.ExcerptsFound,
+		"score", report.Score)
+	return report
+}
+
+// newHonestyReport summarizes source checks
+func newHonestyReport(provider, modelName string, checks []eval.Check) *models.HonestyReport {
+	r := &models.HonestyReport{Provider: provider, Model: modelName, Checked: len(checks)}
+	for _, c := range checks {
+		if c.Status != eval.StatusUnreachable {
+			r.URLsResolved++
+		}
+		switch c.Status {
+		case eval.StatusPresent:
+			r.ExcerptsFound++
+		case eval.StatusMisquoted:
+			r.Misquoted++
+		case eval.StatusFabricated:
+			r.Fabricated++
+		}
+	}
+	if r.Checked > 0 {
+		r.Score = float64(r.ExcerptsFound) / float64(r.Checked)
+	}
+	return r
+}
+
+// HonestyByProvider returns the honesty totals of every provider and model
+// this service has run
+func (s *LLMSearchService) HonestyByProvider() []models.ProviderHonesty {
+	return s.honesty.Snapshot()
+}
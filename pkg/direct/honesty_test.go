@@ -0,0 +1,42 @@
+package direct
+
+import (
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/eval"
+)
+
+func TestHonestyTracker(t *testing.T) {
+	good := newHonestyReport("claude", "claude-sonnet-4", []eval.Check{
+		{Status: eval.StatusPresent},
+		{Status: eval.StatusPresent},
+		{Status: eval.StatusMisquoted},
+		{Status: eval.StatusUnreachable},
+	})
+	if good.Checked != 4 || good.URLsResolved != 3 || good.ExcerptsFound != 2 || good.Score != 0.5 {
+		t.Errorf("newHonestyReport() = %+v", good)
+	}
+
+	poor := newHonestyReport("openai", "gpt-4o-mini", []eval.Check{
+		{Status: eval.StatusFabricated},
+		{Status: eval.StatusPresent},
+		{Status: eval.StatusUnreachable},
+		{Status: eval.StatusUnreachable},
+	})
+
+	tracker := NewHonestyTracker()
+	tracker.Record(good)
+	tracker.Record(poor)
+	tracker.Record(good)
+
+	got := tracker.Snapshot()
+	if len(got) != 2 {
+		t.Fatalf("Snapshot() returned %d providers, want 2", len(got))
+	}
+	if got[0].Provider != "claude" || got[0].Runs != 2 || got[0].Checked != 8 || got[0].Score != 0.5 {
+		t.Errorf("first = %+v, want claude with 2 runs, 8 checked, score 0.5", got[0])
+	}
+	if got[1].Provider != "openai" || got[1].Fabricated != 1 || got[1].Score != 0.25 {
+		t.Errorf("second = %+v, want openai with 1 fabricated, score 0.25", got[1])
+	}
+}
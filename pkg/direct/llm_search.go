@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -14,16 +15,27 @@ import (
 	"google.golang.org/genai"
 
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/eval"
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
 	"github.com/plexusone/agent-team-stats/pkg/llm"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
 	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
 )
 
 // LLMSearchService provides direct LLM-based statistics search (like ChatGPT)
 type LLMSearchService struct {
-	cfg    *config.Config
-	model  model.LLM
-	logger *slog.Logger
+	cfg          *config.Config
+	model        model.LLM
+	modelFactory *llm.ModelFactory
+	prompts      *prompts.Set
+	fetch        eval.Fetcher
+	honesty      *HonestyTracker
+	logger       *slog.Logger
 }
 
 // NewLLMSearchService creates a new direct LLM search service
@@ -38,13 +50,43 @@ func NewLLMSearchService(cfg *config.Config) (*LLMSearchService, error) {
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	promptSet, err := prompts.FromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts: %w", err)
+	}
+
 	return &LLMSearchService{
-		cfg:    cfg,
-		model:  llmModel,
-		logger: logger,
+		cfg:          cfg,
+		model:        llmModel,
+		modelFactory: modelFactory,
+		prompts:      promptSet,
+		fetch:        eval.HTTPFetcher(&http.Client{Timeout: honestyTimeout(cfg), Transport: httpclient.FetchTransport()}, cfg.FetchIdentity),
+		honesty:      NewHonestyTracker(),
+		logger:       logger,
 	}, nil
 }
 
+// honestyTimeout is the time the honesty check gives each source page. The
+// pages are fetched concurrently while the client waits, so it is far
+// shorter than HTTP_TIMEOUT_SECONDS.
+func honestyTimeout(cfg *config.Config) time.Duration {
+	if cfg.DirectHonestyTimeoutSeconds <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(cfg.DirectHonestyTimeoutSeconds) * time.Second
+}
+
+// Close flushes the observability data of the service's LLM calls
+func (s *LLMSearchService) Close() error {
+	return s.modelFactory.Close()
+}
+
+// Reload swaps in the LLM credentials of a reloaded configuration without
+// interrupting searches in progress
+func (s *LLMSearchService) Reload(ctx context.Context, cfg *config.Config) error {
+	return s.modelFactory.Reload(ctx, cfg)
+}
+
 // SearchStatistics uses LLM directly to find statistics (like ChatGPT with web search)
 // If verifyWithAgent is true, sends LLM claims to verification agent for actual web verification
 func (s *LLMSearchService) SearchStatistics(ctx context.Context, topic string, minStats int) (*models.OrchestrationResponse, error) {
@@ -52,50 +94,19 @@ func (s *LLMSearchService) SearchStatistics(ctx context.Context, topic string, m
 }
 
 // SearchStatisticsWithVerification allows optional verification agent integration
-func (s *LLMSearchService) SearchStatisticsWithVerification(ctx context.Context, topic string, minStats int, verifyWithAgent bool) (*models.OrchestrationResponse, error) {
-	prompt := fmt.Sprintf(`Find %d or more verified, numerical statistics about "%s".
-
-For each statistic, provide:
-1. name: Brief description
-2. value: The exact numerical value (as a plain number, NO commas or formatting)
-3. unit: Unit of measurement
-4. source: Name of the authoritative source
-5. source_url: Direct URL to the source (if available)
-6. excerpt: Exact quote containing the statistic
-
-IMPORTANT INSTRUCTIONS:
-- Prioritize statistics from reputable sources (government agencies, research organizations, academic institutions)
-- Include the actual URL where each statistic can be verified
-- Use real, verifiable data - do not make up statistics
-- Extract the exact numerical values
-- Provide verbatim excerpts
-- CRITICAL: The "value" field must be a plain number with NO commas (e.g., 2537 not 2,537)
-
-Return a JSON array:
-[
-  {
-    "name": "Global temperature increase since 1880",
-    "value": 1.1,
-    "unit": "degrees Celsius",
-    "source": "NASA",
-    "source_url": "https://climate.nasa.gov/vital-signs/global-temperature/",
-    "excerpt": "The planet's average surface temperature has risen about 1.1 degrees Celsius since the late 19th century"
-  },
-  {
-    "name": "Example large number",
-    "value": 75000,
-    "unit": "people",
-    "source": "Example",
-    "source_url": "https://example.com",
-    "excerpt": "Over 75,000 people participated"
-  }
-]
-
-REMEMBER: Numbers like 75,000 should be written as 75000 (no comma).
-
-Find at least %d statistics. Return only the JSON array, no other text.`, minStats, topic, minStats)
-
-	// Call LLM
+func (s *LLMSearchService) SearchStatisticsWithVerification(ctx context.Context, topic string, minStats int, verifyWithAgent bool) (_ *models.OrchestrationResponse, err error) {
+	prompt, err := s.prompts.Render(prompts.DirectSearch, prompts.DirectSearchData{
+		Topic:         topic,
+		MinStatistics: minStats,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Call LLM, recording its usage for the response's cost summary
+	tracker := usage.NewTracker("")
+	ctx = usage.WithTracker(ctx, tracker)
+	defer tenant.ChargeFailed(ctx, tracker.Summary, &err)
 	req := &model.LLMRequest{
 		Contents: genai.Text(prompt),
 	}
@@ -114,64 +125,52 @@ Find at least %d statistics. Return only the JSON array, no other text.`, minSta
 		}
 	}
 
-	// Extract JSON from response
-	response = extractJSONFromMarkdown(response)
-
-	// Parse JSON
-	type StatResponse struct {
-		Name      string  `json:"name"`
-		Value     float32 `json:"value"`
-		Unit      string  `json:"unit"`
-		Source    string  `json:"source"`
-		SourceURL string  `json:"source_url"`
-		Excerpt   string  `json:"excerpt"`
-	}
-
-	var stats []StatResponse
-	if err := json.Unmarshal([]byte(response), &stats); err != nil {
+	candidates, dropped, err := parseStatistics(response)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse LLM response: %w\nResponse: %s", err, response)
 	}
-
-	// Convert to candidate statistics for potential verification
-	candidates := make([]models.CandidateStatistic, 0, len(stats))
-	for _, stat := range stats {
-		candidates = append(candidates, models.CandidateStatistic{
-			Name:      stat.Name,
-			Value:     stat.Value,
-			Unit:      stat.Unit,
-			Source:    stat.Source,
-			SourceURL: stat.SourceURL,
-			Excerpt:   stat.Excerpt,
-		})
+	if len(dropped) > 0 {
+		s.logger.Debug("dropped invalid statistics", "kept", len(candidates), "dropped", dropped)
 	}
 
 	// If verification requested, send to verification agent
 	if verifyWithAgent {
-		return s.verifyWithVerificationAgent(ctx, topic, candidates, minStats)
+		resp, err := s.verifyWithVerificationAgent(ctx, topic, candidates, minStats)
+		if err != nil {
+			return nil, err
+		}
+		tracker.Merge(resp.CostSummary)
+		resp.CostSummary = tracker.Summary()
+		return resp, nil
 	}
 
-	// Otherwise, trust LLM claims and mark as verified
+	// Otherwise, trust LLM claims and mark as verified, unless the honesty
+	// check is enabled, which keeps Verified only for statistics found in
+	// their sources
 	verifiedStats := make([]models.Statistic, 0, len(candidates))
 	for _, cand := range candidates {
-		verifiedStats = append(verifiedStats, models.Statistic{
-			Name:      cand.Name,
-			Value:     cand.Value,
-			Unit:      cand.Unit,
-			Source:    cand.Source,
-			SourceURL: cand.SourceURL,
-			Excerpt:   cand.Excerpt,
-			Verified:  true, // Marked as verified since from LLM with sources (not web-verified)
-			DateFound: time.Now(),
-		})
+		// Marked as verified since from LLM with sources (not web-verified)
+		verifiedStats = append(verifiedStats, cand.Statistic(true, time.Now()))
+	}
+
+	var honesty *models.HonestyReport
+	verifiedCount := len(verifiedStats)
+	if s.cfg.DirectHonestyCheck {
+		honesty = s.scoreHonesty(ctx, verifiedStats)
+		verifiedCount = honesty.ExcerptsFound
 	}
 
 	return &models.OrchestrationResponse{
-		Topic:         topic,
-		Statistics:    verifiedStats,
-		VerifiedCount: len(verifiedStats),
-		Timestamp:     time.Now(),
-		Partial:       len(verifiedStats) < minStats,
-		TargetCount:   minStats,
+		Topic:           topic,
+		Statistics:      verifiedStats,
+		TotalCandidates: len(candidates),
+		VerifiedCount:   verifiedCount,
+		FailedCount:     len(verifiedStats) - verifiedCount,
+		Timestamp:       time.Now(),
+		Partial:         verifiedCount < minStats,
+		TargetCount:     minStats,
+		CostSummary:     tracker.Summary(),
+		Honesty:         honesty,
 	}, nil
 }
 
@@ -213,7 +212,7 @@ func (s *LLMSearchService) verifyWithVerificationAgent(ctx context.Context, topi
 
 	// Parse verification response
 	var verifyResp models.VerificationResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&verifyResp); err != nil {
+	if err := migrate.Decode(httpResp.Body, &verifyResp); err != nil {
 		return nil, fmt.Errorf("failed to decode verification response: %w", err)
 	}
 
@@ -239,23 +238,67 @@ func (s *LLMSearchService) verifyWithVerificationAgent(ctx context.Context, topi
 		Timestamp:       time.Now(),
 		Partial:         len(verifiedStats) < minStats,
 		TargetCount:     minStats,
+		CostSummary:     verifyResp.Usage,
 	}, nil
 }
 
-// extractJSONFromMarkdown removes markdown code fences from response
-func extractJSONFromMarkdown(response string) string {
-	response = strings.TrimSpace(response)
+// statResponse is one statistic as returned by the direct search prompt
+type statResponse struct {
+	Name      string           `json:"name"`
+	Value     extract.Quantity `json:"value"`
+	Unit      string           `json:"unit"`
+	Source    string           `json:"source"`
+	SourceURL string           `json:"source_url"`
+	Excerpt   string           `json:"excerpt"`
+}
 
-	// Try to find JSON array
-	startIdx := strings.Index(response, "[")
-	if startIdx == -1 {
-		return response
+// parseStatistics parses a direct search response into candidates. Each
+// entry is decoded and validated on its own, coercing a unit written into
+// the value, an overlong excerpt, and a slightly malformed source URL; one
+// that is still invalid is dropped with its reason.
+func parseStatistics(response string) (candidates []models.CandidateStatistic, dropped []string, err error) {
+	entries, err := extract.Entries(response)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	endIdx := strings.LastIndex(response, "]")
-	if endIdx == -1 || endIdx < startIdx {
-		return response
+	for i, entry := range entries {
+		cand, err := parseStatistic(entry)
+		if err != nil {
+			dropped = append(dropped, fmt.Sprintf("statistic %d: %v", i+1, err))
+			continue
+		}
+		candidates = append(candidates, cand)
 	}
+	return candidates, dropped, nil
+}
 
-	return strings.TrimSpace(response[startIdx : endIdx+1])
+// parseStatistic decodes and validates one entry of a direct search response
+func parseStatistic(entry json.RawMessage) (models.CandidateStatistic, error) {
+	var stat statResponse
+	if err := json.Unmarshal(entry, &stat); err != nil {
+		return models.CandidateStatistic{}, err
+	}
+	if stat.Name = strings.TrimSpace(stat.Name); stat.Name == "" {
+		return models.CandidateStatistic{}, errors.New("missing name")
+	}
+	sourceURL, err := extract.SourceURL(stat.SourceURL)
+	if err != nil {
+		return models.CandidateStatistic{}, err
+	}
+	excerpt, err := extract.Excerpt(stat.Excerpt, stat.Value.Value)
+	if err != nil {
+		return models.CandidateStatistic{}, err
+	}
+	unit, err := stat.Value.MergeUnit(stat.Unit)
+	if err != nil {
+		return models.CandidateStatistic{}, err
+	}
+	return models.CandidateStatistic{
+		Name:      stat.Name,
+		Value:     float32(stat.Value.Value),
+		Unit:      unit,
+		Source:    stat.Source,
+		SourceURL: sourceURL,
+		Excerpt:   excerpt,
+	}, nil
 }
@@ -0,0 +1,32 @@
+package direct
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStatistics(t *testing.T) {
+	response := "```json\n" + `[
+  {"name": "Remote work share", "value": "28%", "source": "BLS", "source_url": "www.bls.gov/news.release/atus.nr0.htm", "excerpt": "28% of employed persons did some or all of their work at home"},
+  {"name": "Missing link", "value": 12, "source": "Unknown", "source_url": "N/A", "excerpt": "12 percent of respondents agreed"},
+  {"name": "No excerpt", "value": 3.4, "unit": "million", "source": "Census", "source_url": "https://www.census.gov/", "excerpt": ""},
+  {"name": "Spending", "value": 2.1, "unit": "trillion USD", "source": "BEA", "source_url": "[BEA](https://www.bea.gov/data)", "excerpt": "spending rose to $2.1 trillion"}
+]` + "\n```"
+
+	candidates, dropped, err := parseStatistics(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 2 || len(dropped) != 2 {
+		t.Fatalf("parseStatistics() = %d candidates, dropped %v; want 2 and 2", len(candidates), dropped)
+	}
+	if c := candidates[0]; c.Value != 28 || c.Unit != "%" || c.SourceURL != "https://www.bls.gov/news.release/atus.nr0.htm" {
+		t.Errorf("candidates[0] = %+v", c)
+	}
+	if c := candidates[1]; c.Unit != "trillion USD" || c.SourceURL != "https://www.bea.gov/data" {
+		t.Errorf("candidates[1] = %+v", c)
+	}
+	if !strings.HasPrefix(dropped[0], "statistic 2:") || !strings.HasPrefix(dropped[1], "statistic 3:") {
+		t.Errorf("dropped = %v", dropped)
+	}
+}
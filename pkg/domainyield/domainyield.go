@@ -0,0 +1,260 @@
+// Package domainyield decides which source domains the research agent skips.
+// A configured skip list covers sites known to hold no primary statistics,
+// and a store of per-domain extraction yield, recorded by the orchestrators
+// after each run, demotes domains whose candidates never verify.
+package domainyield
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Yield is the historical extraction yield of one domain
+type Yield struct {
+	Candidates int       `json:"candidates"` // Candidate statistics sent to verification
+	Verified   int       `json:"verified"`   // Candidates that passed verification
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Store persists per-domain yield in a JSON file shared by the orchestrator,
+// which records it, and the research agent, which reads it
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	yields  map[string]*Yield
+	modTime time.Time
+}
+
+// NewStore opens the yield store at cfg.DomainYieldFile. It returns nil when
+// no file is configured.
+func NewStore(cfg *config.Config) (*Store, error) {
+	if cfg.DomainYieldFile == "" {
+		return nil, nil
+	}
+	s := &Store{path: cfg.DomainYieldFile, yields: make(map[string]*Yield)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refreshLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Record adds the verified and rejected statistics of a run to the yield of
+// their source domains. Statistics merged as corroborations count as
+// verified for their own domain.
+func (s *Store) Record(resp *models.OrchestrationResponse) error {
+	if s == nil || resp == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refreshLocked(); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	add := func(sourceURL string, verified bool) {
+		domain := Domain(sourceURL)
+		if domain == "" {
+			return
+		}
+		y := s.yields[domain]
+		if y == nil {
+			y = &Yield{}
+			s.yields[domain] = y
+		}
+		y.Candidates++
+		if verified {
+			y.Verified++
+		}
+		y.UpdatedAt = now
+	}
+	for _, stat := range resp.Statistics {
+		add(stat.SourceURL, true)
+		for _, c := range stat.CorroboratedBy {
+			add(c.SourceURL, true)
+		}
+	}
+	for _, result := range resp.Rejected {
+		if result.Statistic != nil {
+			add(result.Statistic.SourceURL, false)
+		}
+	}
+	return s.saveLocked()
+}
+
+// Yields returns a copy of the recorded yields by domain
+func (s *Store) Yields() (map[string]Yield, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refreshLocked(); err != nil {
+		return nil, err
+	}
+	yields := make(map[string]Yield, len(s.yields))
+	for domain, y := range s.yields {
+		yields[domain] = *y
+	}
+	return yields, nil
+}
+
+// refreshLocked reloads the file when another process has changed it. The
+// caller must hold s.mu.
+func (s *Store) refreshLocked() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read domain yield: %w", err)
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read domain yield: %w", err)
+	}
+	yields := make(map[string]*Yield)
+	if err := json.Unmarshal(data, &yields); err != nil {
+		return fmt.Errorf("failed to parse domain yield %s: %w", s.path, err)
+	}
+	s.yields = yields
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// saveLocked writes the yields to the file. The caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	data, err := json.MarshalIndent(s.yields, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write domain yield: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace domain yield: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// SkipList matches domains the research agent should not return
+type SkipList struct {
+	domains     []string
+	store       *Store
+	demoteAfter int
+	logger      *slog.Logger
+}
+
+// NewSkipList returns the skip list configured by DOMAIN_SKIPLIST, extended
+// with domains from store that produced at least DOMAIN_DEMOTE_AFTER
+// candidates and none that verified. store may be nil.
+func NewSkipList(cfg *config.Config, store *Store, logger *slog.Logger) *SkipList {
+	domains := make([]string, 0, len(cfg.DomainSkipList))
+	for _, d := range cfg.DomainSkipList {
+		if d = normalizeHost(d); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return &SkipList{domains: domains, store: store, demoteAfter: cfg.DomainDemoteAfter, logger: logger}
+}
+
+// Demoted returns the learned low-yield domains, sorted
+func (l *SkipList) Demoted() []string {
+	if l.store == nil || l.demoteAfter <= 0 {
+		return nil
+	}
+	yields, err := l.store.Yields()
+	if err != nil {
+		l.logger.Warn("domain yield unavailable", "error", err)
+		return nil
+	}
+	var demoted []string
+	for domain, y := range yields {
+		if y.Verified == 0 && y.Candidates >= l.demoteAfter {
+			demoted = append(demoted, domain)
+		}
+	}
+	sort.Strings(demoted)
+	return demoted
+}
+
+// Matcher returns a function reporting why a host or URL is skipped, or ""
+// when it is not. It reads the learned domains once, so callers filtering a
+// page of results should get one matcher per page. A context marked
+// WithoutDemotion skips only the configured domains.
+func (l *SkipList) Matcher(ctx context.Context) func(hostOrURL string) string {
+	var demoted []string
+	if skip, _ := ctx.Value(withoutDemotionKey{}).(bool); !skip {
+		demoted = l.Demoted()
+	}
+	return func(hostOrURL string) string {
+		host := Domain(hostOrURL)
+		if host == "" {
+			host = normalizeHost(hostOrURL)
+		}
+		for _, d := range l.domains {
+			if matchDomain(host, d) {
+				return "skip list"
+			}
+		}
+		for _, d := range demoted {
+			if matchDomain(host, d) {
+				return "low yield"
+			}
+		}
+		return ""
+	}
+}
+
+// withoutDemotionKey is the context key marking a reproducible search
+type withoutDemotionKey struct{}
+
+// WithoutDemotion returns a context whose matchers ignore the learned
+// low-yield domains, which change from run to run
+func WithoutDemotion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutDemotionKey{}, true)
+}
+
+// Domain returns the lowercased host of a URL without a leading "www."
+func Domain(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return normalizeHost(u.Hostname())
+}
+
+// normalizeHost lowercases a host and strips "www." and a trailing dot
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+// matchDomain reports whether host is domain or one of its subdomains
+func matchDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,80 @@
+package domainyield
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestSkipList(t *testing.T) {
+	cfg := &config.Config{
+		DomainSkipList:    []string{"Pinterest.com", "quora.com"},
+		DomainYieldFile:   filepath.Join(t.TempDir(), "yield.json"),
+		DomainDemoteAfter: 2,
+	}
+	writer, err := NewStore(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two runs: spam.example never verifies, stats.gov does
+	for range 2 {
+		err := writer.Record(&models.OrchestrationResponse{
+			Statistics: []models.Statistic{{
+				SourceURL:      "https://www.stats.gov/a",
+				CorroboratedBy: []models.Corroboration{{SourceURL: "https://news.example.com/b"}},
+			}},
+			Rejected: []models.VerificationResult{
+				{Statistic: &models.Statistic{SourceURL: "https://spam.example/x"}},
+				{Statistic: &models.Statistic{SourceURL: "https://stats.gov/c"}},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A second process reads what the first recorded
+	reader, err := NewStore(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yields, _ := reader.Yields()
+	if y := yields["stats.gov"]; y.Candidates != 4 || y.Verified != 2 {
+		t.Errorf("stats.gov yield = %+v", y)
+	}
+	if y := yields["news.example.com"]; y.Verified != 2 {
+		t.Errorf("corroborating domain yield = %+v", y)
+	}
+
+	skipList := NewSkipList(cfg, reader, slog.Default())
+	skip := skipList.Matcher(context.Background())
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://www.pinterest.com/pin/1", "skip list"},
+		{"uk.pinterest.com", "skip list"},
+		{"https://spam.example/y", "low yield"},
+		{"https://stats.gov/d", ""},
+		{"https://notpinterest.com/", ""},
+	}
+	for _, tt := range tests {
+		if got := skip(tt.in); got != tt.want {
+			t.Errorf("skip(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// A reproducible search skips only the configured domains
+	static := skipList.Matcher(WithoutDemotion(context.Background()))
+	if got := static("https://spam.example/y"); got != "" {
+		t.Errorf("skip without demotion = %q, want none", got)
+	}
+	if got := static("uk.pinterest.com"); got != "skip list" {
+		t.Errorf("skip without demotion = %q, want skip list", got)
+	}
+}
@@ -0,0 +1,219 @@
+// Package dryrun plans an orchestration without running it. A plan runs
+// research, so search is called and sources are selected as in a real run,
+// but no page is fetched and no LLM is called: the extraction and
+// verification work those sources would need is estimated instead, so users
+// can check a request's scope and cost before spending money on it.
+package dryrun
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/adapters"
+	"github.com/plexusone/agent-team-stats/pkg/compare"
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/sourcelist"
+	"github.com/plexusone/agent-team-stats/pkg/tokens"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
+)
+
+// MaxPasses is the most research, synthesis, and verification passes an
+// orchestration makes while below its target
+const MaxPasses = 3
+
+// Assumptions behind the estimates. Page text is assumed to fill the room
+// the synthesis model's prompt budget leaves, up to SYNTHESIS_PAGE_TOKENS, or
+// CompactMaxChunks full chunks with the compact prompt, and every candidate
+// is assumed to need an LLM verdict in a call of its own, while only those
+// whose excerpt is not found verbatim need one and those from the same page
+// share calls, so the estimate is an upper bound for one pass.
+const (
+	charsPerToken            = 4
+	judgePassageChars        = 3 * 1200
+	synthesisOutputTokens    = 1000
+	verificationOutputTokens = 150
+)
+
+// ResearchFunc calls the research agent
+type ResearchFunc func(ctx context.Context, req *models.ResearchRequest) (*models.ResearchResponse, error)
+
+// Planner builds dry-run plans
+type Planner struct {
+	cfg      *config.Config
+	prompts  *prompts.Set
+	adapters *adapters.Registry // Nil when EXTRACTION_ADAPTERS is off
+	research ResearchFunc
+}
+
+// New creates a planner that selects sources with research and sizes
+// prompts with promptSet
+func New(cfg *config.Config, promptSet *prompts.Set, research ResearchFunc) *Planner {
+	p := &Planner{cfg: cfg, prompts: promptSet, research: research}
+	if cfg.ExtractionAdapters {
+		p.adapters = adapters.Default()
+	}
+	return p
+}
+
+// Plan selects the sources a run of req would read, one search per compared
+// entity, and returns a response carrying the plan. Its cost summary counts
+// only the searches, the one thing a dry run spends.
+func (p *Planner) Plan(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+	reqs := []models.OrchestrationRequest{*req}
+	if len(req.Compare) > 0 {
+		reqs = compare.Requests(req)
+	}
+
+	plan := &models.RunPlan{SearchProvider: p.cfg.SearchProvider, MaxPasses: MaxPasses}
+	spent := usage.NewTracker("")
+	estimate := usage.NewTracker("")
+	for i := range reqs {
+		searches, err := p.planTopic(ctx, &reqs[i], plan, estimate)
+		if err != nil {
+			return nil, err
+		}
+		spent.AddSearches(searches)
+	}
+	plan.EstimatedUsage = estimate.Summary()
+
+	return &models.OrchestrationResponse{
+		Topic:       req.Topic,
+		Statistics:  []models.Statistic{},
+		Timestamp:   time.Now(),
+		Status:      models.StatusDryRun,
+		TargetCount: req.MinVerifiedStats,
+		CostSummary: spent.Summary(),
+		Plan:        plan,
+	}, nil
+}
+
+// planTopic adds the sources and estimated usage of the first pass of a
+// single-topic request to plan, returning the searches research ran. A
+// request's listed sources are planned without searching, all of them, as
+// a run reads them all.
+func (p *Planner) planTopic(ctx context.Context, req *models.OrchestrationRequest, plan *models.RunPlan, estimate *usage.Tracker) (int, error) {
+	resp, err := p.sources(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	llmPages := 0
+	for _, cand := range resp.Candidates {
+		source := models.PlannedSource{
+			URL:        cand.SourceURL,
+			Domain:     cand.Source,
+			Title:      cand.Name,
+			Extraction: models.ExtractionLLM,
+		}
+		switch a := p.adapters.Lookup(cand.SourceURL); {
+		case a != nil:
+			source.Extraction = models.ExtractionAdapter
+			source.Adapter = a.Name()
+			if !slices.Contains(plan.Adapters, a.Name()) {
+				plan.Adapters = append(plan.Adapters, a.Name())
+			}
+		case extract.DetectFormat("", cand.SourceURL).IsStructured():
+			source.Extraction = models.ExtractionDataFile
+		default:
+			llmPages++
+		}
+		plan.Sources = append(plan.Sources, source)
+	}
+	if len(resp.Candidates) == 0 {
+		return resp.SearchCalls, nil
+	}
+
+	budget, err := llm.RunBudget(p.cfg, llm.StageSynthesis, req.SynthesisOverride(), req.Generation)
+	if err != nil {
+		return 0, err
+	}
+	profile, err := prompts.ParseProfile(p.cfg.SynthesisPromptProfile)
+	if err != nil {
+		return 0, err
+	}
+	profile = profile.Resolve(llm.SmallModel(budget.Provider, budget.Model))
+	synthesisPrompt, err := p.prompts.Render(profile.Extract(), prompts.ExtractData{Topic: req.Topic})
+	if err != nil {
+		return 0, err
+	}
+
+	// Compact prompts read a page in chunks, one call each
+	pageTokens, calls := budget.Room(synthesisPrompt), llmPages
+	if profile == prompts.ProfileCompact {
+		pageTokens = min(pageTokens, prompts.CompactChunkTokens)
+		calls *= prompts.CompactMaxChunks
+	}
+	if p.cfg.SynthesisPageTokens > 0 {
+		pageTokens = min(pageTokens, p.cfg.SynthesisPageTokens)
+	}
+	p.add(estimate, llm.StageSynthesis, req.SynthesisOverride(), calls,
+		tokens.Count(budget.Provider, synthesisPrompt)+pageTokens, synthesisOutputTokens)
+
+	if p.cfg.VerificationLLMEnabled {
+		judgePrompt, err := p.prompts.Render(prompts.VerificationJudge, prompts.JudgeData{})
+		if err != nil {
+			return 0, err
+		}
+		candidates := min(req.Budget(req.MinVerifiedStats), req.MaxCandidates)
+		p.add(estimate, llm.StageVerification, req.VerificationOverride(), candidates,
+			estimateTokens(len(judgePrompt)+judgePassageChars), verificationOutputTokens)
+	}
+	return resp.SearchCalls, nil
+}
+
+// sources returns the sources a run of req would read first: its listed
+// sources, or those research finds
+func (p *Planner) sources(ctx context.Context, req *models.OrchestrationRequest) (*models.ResearchResponse, error) {
+	if len(req.Sources) > 0 {
+		resp := &models.ResearchResponse{Topic: req.Topic}
+		for _, r := range sourcelist.Results(req.Sources) {
+			resp.Candidates = append(resp.Candidates, models.CandidateStatistic{
+				Name:      r.Title,
+				SourceURL: r.URL,
+				Source:    r.Domain,
+			})
+		}
+		return resp, nil
+	}
+	resp, err := p.research(ctx, &models.ResearchRequest{
+		Topic:         req.Topic,
+		MinStatistics: req.MinVerifiedStats,
+		MaxStatistics: req.MaxPages,
+		ReputableOnly: req.ReputableOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("research failed: %w", err)
+	}
+	return resp, nil
+}
+
+// add records calls LLM calls of a stage, each with the given prompt and
+// completion tokens, at the price of the model the stage would use
+func (p *Planner) add(estimate *usage.Tracker, stage llm.Stage, o *models.ModelOverride, calls, promptTokens, completionTokens int) {
+	if calls <= 0 {
+		return
+	}
+	provider, modelName := llm.RunStageModel(p.cfg, stage, o)
+	cost, priced := usage.Cost(provider, modelName, calls*promptTokens, calls*completionTokens)
+	estimate.Merge(&models.CostSummary{ByModel: []models.ModelUsage{{
+		Provider:         provider,
+		Model:            modelName,
+		Stage:            string(stage),
+		Calls:            calls,
+		PromptTokens:     calls * promptTokens,
+		CompletionTokens: calls * completionTokens,
+		EstimatedCostUSD: cost,
+		Unpriced:         !priced,
+	}}})
+}
+
+// estimateTokens estimates the tokens of a prompt of n characters
+func estimateTokens(n int) int {
+	return (n + charsPerToken - 1) / charsPerToken
+}
@@ -0,0 +1,107 @@
+package dryrun
+
+import (
+	"context"
+	"testing"
+
+	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+func TestPlan(t *testing.T) {
+	cfg := &config.Config{
+		Config:                 &akconfig.Config{LLMProvider: "gemini", LLMModel: "gemini-2.5-flash", SearchProvider: "serper"},
+		ExtractionAdapters:     true,
+		VerificationLLMEnabled: true,
+	}
+	var topics []string
+	research := func(_ context.Context, req *models.ResearchRequest) (*models.ResearchResponse, error) {
+		topics = append(topics, req.Topic)
+		return &models.ResearchResponse{
+			SearchCalls: 1,
+			Candidates: []models.CandidateStatistic{
+				{SourceURL: "https://www.pewresearch.org/report", Source: "pewresearch.org"},
+				{SourceURL: "https://example.com/data.csv", Source: "example.com"},
+				{SourceURL: "https://en.wikipedia.org/wiki/Solar_power", Source: "en.wikipedia.org"},
+			},
+		}, nil
+	}
+	req := &models.OrchestrationRequest{
+		Topic:            "solar",
+		MinVerifiedStats: 4,
+		MaxCandidates:    10,
+		Compare:          []string{"2010", "2020"},
+		DryRun:           true,
+		StageLimits:      models.StageLimits{MaxPages: 3, VerificationBufferFactor: 2},
+	}
+
+	resp, err := New(cfg, prompts.Default(), research).Plan(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics) != 2 || topics[0] != "solar 2010" || topics[1] != "solar 2020" {
+		t.Errorf("researched %v; want one search per compared entity", topics)
+	}
+	if resp.Status != models.StatusDryRun || resp.CostSummary.SearchCalls != 2 || resp.CostSummary.Calls != 0 {
+		t.Errorf("got status %q and cost summary %+v; want dry_run with only the searches spent", resp.Status, resp.CostSummary)
+	}
+
+	plan := resp.Plan
+	if len(plan.Sources) != 6 {
+		t.Fatalf("got %d sources; want 6", len(plan.Sources))
+	}
+	want := []string{models.ExtractionLLM, models.ExtractionDataFile, models.ExtractionAdapter}
+	for i, w := range want {
+		if plan.Sources[i].Extraction != w {
+			t.Errorf("source %d extraction = %q; want %q", i, plan.Sources[i].Extraction, w)
+		}
+	}
+	if len(plan.Adapters) != 1 || plan.Adapters[0] != "wikipedia" {
+		t.Errorf("adapters = %v; want [wikipedia]", plan.Adapters)
+	}
+
+	calls := map[string]int{}
+	for _, m := range plan.EstimatedUsage.ByModel {
+		calls[m.Stage] = m.Calls
+		if m.Provider != "gemini" || m.Model != "gemini-2.5-flash" || m.EstimatedCostUSD <= 0 {
+			t.Errorf("unexpected estimate %+v", m)
+		}
+	}
+	// One LLM page per entity; each entity needs 2 statistics, so 4 candidates
+	if calls["synthesis"] != 2 || calls["verification"] != 8 {
+		t.Errorf("estimated calls = %v; want synthesis 2, verification 8", calls)
+	}
+}
+
+func TestPlanSources(t *testing.T) {
+	cfg := &config.Config{Config: &akconfig.Config{LLMProvider: "gemini", LLMModel: "gemini-2.5-flash"}}
+	research := func(context.Context, *models.ResearchRequest) (*models.ResearchResponse, error) {
+		t.Error("listed sources should not be researched")
+		return &models.ResearchResponse{}, nil
+	}
+	req := &models.OrchestrationRequest{
+		Topic:            "solar",
+		MinVerifiedStats: 2,
+		MaxCandidates:    10,
+		DryRun:           true,
+		StageLimits:      models.StageLimits{MaxPages: 1},
+		Sources: []models.InputSource{
+			{URL: "https://example.com/data.csv"},
+			{Filename: "report.html", Data: []byte("<p>Solar grew 12%</p>")},
+		},
+	}
+
+	resp, err := New(cfg, prompts.Default(), research).Plan(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.CostSummary != nil || len(resp.Plan.Sources) != 2 {
+		t.Fatalf("got cost summary %+v and %d sources; want nothing spent and every listed source", resp.CostSummary, len(resp.Plan.Sources))
+	}
+	if s := resp.Plan.Sources[1]; s.Domain != "report.html" || s.Extraction != models.ExtractionLLM {
+		t.Errorf("document source = %+v", s)
+	}
+}
@@ -0,0 +1,62 @@
+// Package embed turns short texts into vectors for semantic similarity. It
+// offers a dependency-free local embedder and provider-backed embedders for
+// Gemini and OpenAI.
+package embed
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+// Embedder returns one vector per input text, in order
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// New creates the embedder selected by cfg.EmbeddingProvider: "local"
+// (default), "gemini", or "openai"
+func New(ctx context.Context, cfg *config.Config) (Embedder, error) {
+	switch cfg.EmbeddingProvider {
+	case "local", "":
+		return NewLocal(), nil
+	case "gemini":
+		return NewGemini(ctx, cfg)
+	case "openai":
+		return NewOpenAI(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider: %s (supported: local, gemini, openai)", cfg.EmbeddingProvider)
+	}
+}
+
+// DefaultThreshold returns the cosine similarity above which two texts from
+// a provider's embeddings are treated as the same statistic. Local hashed
+// embeddings only see shared words, so their scores run lower.
+func DefaultThreshold(provider string) float64 {
+	switch provider {
+	case "local", "":
+		return 0.6
+	default:
+		return 0.85
+	}
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 if either is
+// zero or their lengths differ
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
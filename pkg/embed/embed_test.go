@@ -0,0 +1,88 @@
+package embed
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+func TestCosine(t *testing.T) {
+	for _, tt := range []struct {
+		a, b []float32
+		want float64
+	}{
+		{[]float32{1, 0}, []float32{1, 0}, 1},
+		{[]float32{1, 0}, []float32{0, 1}, 0},
+		{[]float32{1, 2}, []float32{-1, -2}, -1},
+		{[]float32{3, 4}, []float32{6, 8}, 1},
+		{[]float32{1, 2}, []float32{1, 2, 3}, 0},
+		{[]float32{0, 0}, []float32{1, 1}, 0},
+		{nil, nil, 0},
+	} {
+		if got := Cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Cosine(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLocalEmbed(t *testing.T) {
+	vectors, err := NewLocal().Embed(context.Background(), []string{
+		"US unemployment rate in 2023",
+		"Unemployment rates in the US, 2023",
+		"Global smartphone users",
+		"",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 4 {
+		t.Fatalf("got %d vectors, want 4", len(vectors))
+	}
+	for i, v := range vectors[:3] {
+		if len(v) != localDims {
+			t.Errorf("vector %d has %d dimensions", i, len(v))
+		}
+		if n := Cosine(v, v); math.Abs(n-1) > 1e-6 {
+			t.Errorf("vector %d is not normalized: %v", i, n)
+		}
+	}
+	reworded, unrelated := Cosine(vectors[0], vectors[1]), Cosine(vectors[0], vectors[2])
+	if reworded < DefaultThreshold("local") || unrelated >= DefaultThreshold("local") {
+		t.Errorf("similarity reworded = %.3f, unrelated = %.3f", reworded, unrelated)
+	}
+	if Cosine(vectors[0], vectors[3]) != 0 {
+		t.Error("empty text has a non-zero vector")
+	}
+
+	// The same text always embeds the same
+	again, _ := NewLocal().Embed(context.Background(), []string{"US unemployment rate in 2023"})
+	if !slices.Equal(again[0], vectors[0]) {
+		t.Error("local embedding is not deterministic")
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("The share of adults using Social Media, 2023: 48.5%")
+	want := []string{"share", "adult", "using", "social", "media", "2023", "48.5%"}
+	if !slices.Equal(got, want) {
+		t.Errorf("tokenize() = %q, want %q", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, provider := range []string{"", "local"} {
+		e, err := New(context.Background(), &config.Config{EmbeddingProvider: provider})
+		if _, ok := e.(*Local); err != nil || !ok {
+			t.Errorf("New(%q) = %T, %v", provider, e, err)
+		}
+	}
+	if _, err := New(context.Background(), &config.Config{EmbeddingProvider: "word2vec"}); err == nil {
+		t.Error("New() accepted an unknown provider")
+	}
+	if DefaultThreshold("local") >= DefaultThreshold("openai") {
+		t.Error("local threshold should be below the provider threshold")
+	}
+}
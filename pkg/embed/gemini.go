@@ -0,0 +1,73 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+// defaultGeminiModel is used when EMBEDDING_MODEL is not set
+const defaultGeminiModel = "text-embedding-004"
+
+// Gemini embeds texts with the Gemini embeddings API, using the same API key
+// or Vertex AI settings as the Gemini LLM
+type Gemini struct {
+	client *genai.Client
+	model  string
+}
+
+// NewGemini creates a Gemini embedder
+func NewGemini(ctx context.Context, cfg *config.Config) (*Gemini, error) {
+	clientCfg := &genai.ClientConfig{}
+	if cfg.GeminiBackend == "vertexai" {
+		clientCfg.Backend = genai.BackendVertexAI
+		clientCfg.Project = cfg.VertexProject
+		clientCfg.Location = cfg.VertexLocation
+	} else {
+		clientCfg.APIKey = cfg.GeminiAPIKey
+		if clientCfg.APIKey == "" {
+			return nil, fmt.Errorf("gemini API key not set - please set GOOGLE_API_KEY or GEMINI_API_KEY")
+		}
+	}
+
+	client, err := genai.NewClient(ctx, clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+
+	modelName := cfg.EmbeddingModel
+	if modelName == "" {
+		modelName = defaultGeminiModel
+	}
+	return &Gemini{client: client, model: modelName}, nil
+}
+
+// Embed implements Embedder
+func (g *Gemini) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+
+	resp, err := g.client.Models.EmbedContent(ctx, g.model, contents, &genai.EmbedContentConfig{
+		TaskType: "SEMANTIC_SIMILARITY",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gemini embedding failed: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("gemini returned %d embeddings for %d texts", len(resp.Embeddings), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, e := range resp.Embeddings {
+		vectors[i] = e.Values
+	}
+	return vectors, nil
+}
@@ -0,0 +1,101 @@
+package embed
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+// localDims is the size of local hashed vectors
+const localDims = 1024
+
+// stopwords carry no meaning for comparing statistic descriptions
+var stopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "in": true, "on": true, "at": true,
+	"for": true, "to": true, "by": true, "and": true, "or": true, "as": true, "is": true,
+	"are": true, "was": true, "were": true, "be": true, "with": true, "from": true,
+	"that": true, "this": true, "its": true, "their": true, "about": true, "than": true,
+}
+
+// Local embeds texts by feature hashing of word unigrams, word bigrams, and
+// character trigrams. It needs no network or model, and catches rewordings
+// that share most of their words (reordering, plurals, filler words), but
+// not synonyms.
+type Local struct{}
+
+// NewLocal creates a local hashed embedder
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// Embed implements Embedder
+func (Local) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = localVector(text)
+	}
+	return vectors, nil
+}
+
+// localVector builds an L2-normalized hashed feature vector
+func localVector(text string) []float32 {
+	v := make([]float32, localDims)
+	words := tokenize(text)
+	for i, w := range words {
+		add(v, "w:"+w, 1)
+		if i > 0 {
+			add(v, "b:"+words[i-1]+" "+w, 0.5)
+		}
+		padded := " " + w + " "
+		for j := 0; j+3 <= len(padded); j++ {
+			add(v, "c:"+padded[j:j+3], 0.25)
+		}
+	}
+
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range v {
+			v[i] *= scale
+		}
+	}
+	return v
+}
+
+// tokenize normalizes text into lowercase words without stopwords, with a
+// plural "s" stripped
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(textmatch.Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '%'
+	})
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(f, ".")
+		if f == "" || stopwords[f] {
+			continue
+		}
+		if len(f) > 3 && strings.HasSuffix(f, "s") && !strings.HasSuffix(f, "ss") {
+			f = f[:len(f)-1]
+		}
+		words = append(words, f)
+	}
+	return words
+}
+
+// add hashes a feature into v with a sign bit, so collisions tend to cancel
+func add(v []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum&1 == 1 {
+		weight = -weight
+	}
+	v[(sum>>1)%localDims] += weight
+}
@@ -0,0 +1,82 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+const (
+	// defaultOpenAIModel is used when EMBEDDING_MODEL is not set
+	defaultOpenAIModel = "text-embedding-3-small"
+	// openAIEmbeddingsURL is the OpenAI embeddings endpoint
+	openAIEmbeddingsURL = "https://api.openai.com/v1/embeddings"
+)
+
+// OpenAI embeds texts with the OpenAI embeddings API
+type OpenAI struct {
+	client *http.Client
+	apiKey string
+	model  string
+}
+
+// NewOpenAI creates an OpenAI embedder
+func NewOpenAI(cfg *config.Config) (*OpenAI, error) {
+	if cfg.OpenAIAPIKey == "" {
+		return nil, fmt.Errorf("openai API key not set - please set OPENAI_API_KEY")
+	}
+	modelName := cfg.EmbeddingModel
+	if modelName == "" {
+		modelName = defaultOpenAIModel
+	}
+	return &OpenAI{
+		client: &http.Client{Timeout: 30 * time.Second},
+		apiKey: cfg.OpenAIAPIKey,
+		model:  modelName,
+	}, nil
+}
+
+// openAIRequest is the body of an embeddings request
+type openAIRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// openAIResponse is the body of an embeddings response
+type openAIResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed implements Embedder
+func (o *OpenAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var resp openAIResponse
+	headers := map[string]string{"Authorization": "Bearer " + o.apiKey}
+	if err := httpclient.PostJSONWithHeaders(ctx, o.client, openAIEmbeddingsURL, headers, openAIRequest{Model: o.model, Input: texts}, &resp); err != nil {
+		return nil, fmt.Errorf("openai embedding failed: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("openai returned embedding index %d for %d texts", d.Index, len(texts))
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("openai returned no embedding for text %d", i)
+		}
+	}
+	return vectors, nil
+}
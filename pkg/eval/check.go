@@ -0,0 +1,163 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+const (
+	// maxSourceBytes bounds how much of a source is read for checking
+	maxSourceBytes = 10 * 1024 * 1024
+	// fetchConcurrency limits concurrent source fetches
+	fetchConcurrency = 8
+)
+
+// Status is the outcome of checking a statistic against its source
+type Status string
+
+const (
+	StatusPresent     Status = "present"     // The excerpt (or data cell) is in the source
+	StatusMisquoted   Status = "misquoted"   // The value is in the source but the excerpt is not
+	StatusFabricated  Status = "fabricated"  // Neither the excerpt nor the value is in the source
+	StatusUnreachable Status = "unreachable" // The source could not be fetched
+)
+
+// Check is the result of checking one statistic
+type Check struct {
+	Name      string `json:"name"`
+	SourceURL string `json:"source_url"`
+	Status    Status `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// Source is a fetched source document
+type Source struct {
+	ContentType string
+	Body        []byte
+}
+
+// Fetcher fetches a statistic's source
+type Fetcher func(ctx context.Context, url string) (*Source, error)
+
+// HTTPFetcher returns a Fetcher that downloads sources with client,
+// presenting identity to sites
+func HTTPFetcher(client *http.Client, identity *httpclient.Identity) Fetcher {
+	return func(ctx context.Context, url string) (*Source, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		identity.Apply(req)
+
+		resp, err := client.Do(req) //nolint:gosec // G704: source URLs of returned statistics
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch URL: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBytes))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		return &Source{ContentType: resp.Header.Get("Content-Type"), Body: body}, nil
+	}
+}
+
+// CheckStatistics fetches the source of each statistic, once per URL and up
+// to fetchConcurrency at a time, and checks the statistics against them
+func CheckStatistics(ctx context.Context, fetch Fetcher, stats []models.Statistic) []Check {
+	type fetched struct {
+		src *Source
+		err error
+	}
+	var urls []string
+	sources := make(map[string]*fetched)
+	for _, stat := range stats {
+		if _, ok := sources[stat.SourceURL]; !ok {
+			sources[stat.SourceURL] = nil
+			urls = append(urls, stat.SourceURL)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, fetchConcurrency)
+	for _, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			f := &fetched{}
+			if url == "" {
+				f.err = fmt.Errorf("no source URL")
+			} else {
+				f.src, f.err = fetch(ctx, url)
+			}
+			mu.Lock()
+			sources[url] = f
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	checks := make([]Check, 0, len(stats))
+	for _, stat := range stats {
+		f := sources[stat.SourceURL]
+		if f.err != nil {
+			checks = append(checks, Check{Name: stat.Name, SourceURL: stat.SourceURL, Status: StatusUnreachable, Detail: f.err.Error()})
+			continue
+		}
+		checks = append(checks, CheckStatistic(f.src, stat))
+	}
+	return checks
+}
+
+// CheckStatistic checks whether a statistic is truly present in its source.
+// Data-file statistics are looked up by their recorded cell; others must have
+// their excerpt in the page text. A statistic whose value appears nowhere in
+// the source is reported as fabricated.
+func CheckStatistic(src *Source, stat models.Statistic) Check {
+	check := Check{Name: stat.Name, SourceURL: stat.SourceURL}
+
+	if prov := stat.Provenance; prov != nil {
+		tables, err := extract.Parse(extract.DetectFormat(src.ContentType, stat.SourceURL), src.Body)
+		if err == nil {
+			if cell, ok := extract.Lookup(tables, prov); ok {
+				if v, ok := extract.ParseNumber(cell); ok && extract.SameNumber(v, float64(stat.Value)) {
+					check.Status = StatusPresent
+					return check
+				}
+			}
+		}
+	}
+
+	pageText := extract.PageText(src.Body)
+	if stat.Excerpt != "" {
+		if ok, _ := textmatch.Contains(pageText, stat.Excerpt, textmatch.DefaultThreshold); ok {
+			check.Status = StatusPresent
+			return check
+		}
+	}
+
+	if extract.ValueAppears(pageText, float64(stat.Value)) {
+		check.Status = StatusMisquoted
+		check.Detail = "excerpt not found in source"
+	} else {
+		check.Status = StatusFabricated
+		check.Detail = fmt.Sprintf("value %v not found in source", stat.Value)
+	}
+	return check
+}
@@ -0,0 +1,77 @@
+// Package eval scores the pipeline against golden topic datasets: precision
+// (returned statistics truly present in their sources), recall against a
+// curated answer key, and hallucination rate (values absent from their
+// sources). It backs cmd/evaluate and is meant for judging prompt and model
+// changes.
+package eval
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// DefaultTolerance is the relative difference under which a returned value
+// matches an expected one
+const DefaultTolerance = 0.02
+
+//go:embed datasets/golden.json
+var builtin embed.FS
+
+// Dataset is a set of topics with their answer keys
+type Dataset struct {
+	Name   string  `json:"name"`
+	Topics []Topic `json:"topics"`
+}
+
+// Topic is one evaluation topic
+type Topic struct {
+	Topic         string     `json:"topic"`
+	MinStats      int        `json:"min_stats,omitempty"`
+	ReputableOnly bool       `json:"reputable_only,omitempty"`
+	Expected      []Expected `json:"expected"` // Answer key; recall is undefined when empty
+}
+
+// Expected is a statistic a good run should find
+type Expected struct {
+	Name      string  `json:"name"`
+	Value     float64 `json:"value"`
+	Unit      string  `json:"unit,omitempty"`      // Matched loosely (case-insensitive, % = percent)
+	Tolerance float64 `json:"tolerance,omitempty"` // Relative; 0 uses DefaultTolerance
+}
+
+// Builtin returns the golden dataset shipped with the binary
+func Builtin() (*Dataset, error) {
+	data, err := builtin.ReadFile("datasets/golden.json")
+	if err != nil {
+		return nil, err
+	}
+	return parseDataset(data)
+}
+
+// LoadDataset reads a dataset from a JSON file
+func LoadDataset(path string) (*Dataset, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: dataset path from CLI flag
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	return parseDataset(data)
+}
+
+// parseDataset decodes and validates a dataset
+func parseDataset(data []byte) (*Dataset, error) {
+	var ds Dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("invalid dataset: %w", err)
+	}
+	if len(ds.Topics) == 0 {
+		return nil, fmt.Errorf("dataset %q has no topics", ds.Name)
+	}
+	for i, t := range ds.Topics {
+		if t.Topic == "" {
+			return nil, fmt.Errorf("dataset %q: topic %d is empty", ds.Name, i+1)
+		}
+	}
+	return &ds, nil
+}
@@ -0,0 +1,116 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+const page = `<html><body><p>The planet has warmed by about 1.1&deg;C since 1850-1900.</p>
+<p>Around 5.5 billion people, or 68% of the world's population, use the internet.</p></body></html>`
+
+func TestCheckStatistic(t *testing.T) {
+	src := &Source{ContentType: "text/html", Body: []byte(page)}
+
+	tests := []struct {
+		name string
+		stat models.Statistic
+		want Status
+	}{
+		{"excerpt present", models.Statistic{Value: 1.1, Excerpt: "The planet has warmed by about 1.1°C since 1850-1900"}, StatusPresent},
+		{"value present, excerpt reworded", models.Statistic{Value: 68, Excerpt: "68 percent of people are online"}, StatusMisquoted},
+		{"value scaled by magnitude word", models.Statistic{Value: 5.5e9, Excerpt: "5,500,000,000 internet users"}, StatusMisquoted},
+		{"value absent", models.Statistic{Value: 72, Excerpt: "72% of the world is online"}, StatusFabricated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckStatistic(src, tt.stat); got.Status != tt.want {
+				t.Errorf("CheckStatistic() = %s (%s), want %s", got.Status, got.Detail, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckStatisticsFetchesOncePerURL(t *testing.T) {
+	var fetches atomic.Int32
+	fetch := func(_ context.Context, url string) (*Source, error) {
+		fetches.Add(1)
+		if url == "https://down.example" {
+			return nil, errors.New("HTTP 503")
+		}
+		return &Source{ContentType: "text/html", Body: []byte(page)}, nil
+	}
+
+	stats := []models.Statistic{
+		{SourceURL: "https://up.example", Value: 1.1, Excerpt: "warmed by about 1.1°C"},
+		{SourceURL: "https://up.example", Value: 68, Excerpt: "68% of the world's population"},
+		{SourceURL: "https://down.example", Value: 3},
+	}
+	checks := CheckStatistics(context.Background(), fetch, stats)
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetched %d times, want 2", n)
+	}
+	want := []Status{StatusPresent, StatusPresent, StatusUnreachable}
+	for i, c := range checks {
+		if c.Status != want[i] {
+			t.Errorf("check %d = %s, want %s", i, c.Status, want[i])
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	topic := Topic{
+		Topic: "internet",
+		Expected: []Expected{
+			{Name: "users", Value: 5.5, Unit: "billion"},
+			{Name: "share", Value: 68, Unit: "percent"},
+			{Name: "warming", Value: 1.1, Unit: "degrees Celsius"},
+		},
+	}
+	stats := []models.Statistic{
+		{Value: 5500000000, Unit: "people"},
+		{Value: 68, Unit: "%"},
+		{Value: 1.1, Unit: "million"},
+	}
+	checks := []Check{{Status: StatusPresent}, {Status: StatusFabricated}, {Status: StatusUnreachable}}
+
+	r := Score(topic, stats, checks)
+	if *r.Precision != 0.5 || *r.HallucinationRate != 0.5 {
+		t.Errorf("precision = %v, hallucination = %v, want 0.5, 0.5", *r.Precision, *r.HallucinationRate)
+	}
+	if len(r.Found) != 2 || len(r.Missed) != 1 || r.Missed[0] != "warming" {
+		t.Errorf("found = %v, missed = %v", r.Found, r.Missed)
+	}
+
+	failed := Score(Topic{Topic: "failed", Expected: topic.Expected[:1]}, nil, nil)
+	failed.Error = "timeout"
+	report := NewReport("test", []TopicResult{r, failed})
+	if *report.Recall != 0.5 || report.Failed != 1 {
+		t.Errorf("recall = %v, failed = %d, want 0.5, 1", *report.Recall, report.Failed)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "ERROR: timeout") || !strings.Contains(buf.String(), "  - warming") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	ds, err := Builtin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, topic := range ds.Topics {
+		if len(topic.Expected) == 0 {
+			t.Errorf("topic %q has no answer key", topic.Topic)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// TopicResult is the score of one topic. Metrics are nil when undefined:
+// precision and hallucination rate when no source could be checked, recall
+// when the topic has no answer key.
+type TopicResult struct {
+	Topic             string              `json:"topic"`
+	Returned          int                 `json:"returned"`
+	Present           int                 `json:"present"`
+	Misquoted         int                 `json:"misquoted"`
+	Fabricated        int                 `json:"fabricated"`
+	Unreachable       int                 `json:"unreachable"`
+	Expected          int                 `json:"expected"`
+	Found             []string            `json:"found,omitempty"`  // Answer-key entries matched
+	Missed            []string            `json:"missed,omitempty"` // Answer-key entries not matched
+	Precision         *float64            `json:"precision,omitempty"`
+	Recall            *float64            `json:"recall,omitempty"`
+	HallucinationRate *float64            `json:"hallucination_rate,omitempty"`
+	Duration          time.Duration       `json:"duration_ns"`
+	CostSummary       *models.CostSummary `json:"cost_summary,omitempty"`
+	Checks            []Check             `json:"checks,omitempty"`
+	Error             string              `json:"error,omitempty"` // Pipeline failure; the topic scores zero
+}
+
+// Report is the result of evaluating a dataset
+type Report struct {
+	Dataset           string        `json:"dataset"`
+	Timestamp         time.Time     `json:"timestamp"`
+	Topics            []TopicResult `json:"topics"`
+	Precision         *float64      `json:"precision,omitempty"`
+	Recall            *float64      `json:"recall,omitempty"`
+	HallucinationRate *float64      `json:"hallucination_rate,omitempty"`
+	Failed            int           `json:"failed"`      // Topics whose pipeline run failed
+	CostUSD           float64       `json:"cost_usd"`    // Estimated LLM cost of all runs
+	Duration          time.Duration `json:"duration_ns"` // Total pipeline time
+}
+
+// Score scores a topic's returned statistics given their source checks
+func Score(topic Topic, stats []models.Statistic, checks []Check) TopicResult {
+	r := TopicResult{Topic: topic.Topic, Returned: len(stats), Expected: len(topic.Expected), Checks: checks}
+
+	for _, c := range checks {
+		switch c.Status {
+		case StatusPresent:
+			r.Present++
+		case StatusMisquoted:
+			r.Misquoted++
+		case StatusFabricated:
+			r.Fabricated++
+		case StatusUnreachable:
+			r.Unreachable++
+		}
+	}
+	if checked := r.Present + r.Misquoted + r.Fabricated; checked > 0 {
+		r.Precision = ratio(r.Present, checked)
+		r.HallucinationRate = ratio(r.Fabricated, checked)
+	}
+
+	for _, want := range topic.Expected {
+		if matchesAny(want, stats) {
+			r.Found = append(r.Found, want.Name)
+		} else {
+			r.Missed = append(r.Missed, want.Name)
+		}
+	}
+	if len(topic.Expected) > 0 {
+		r.Recall = ratio(len(r.Found), len(topic.Expected))
+	}
+	return r
+}
+
+// NewReport aggregates topic results. Metrics are micro-averaged over all
+// checked statistics and answer-key entries; failed topics count every
+// answer-key entry as missed.
+func NewReport(dataset string, results []TopicResult) *Report {
+	r := &Report{Dataset: dataset, Timestamp: time.Now(), Topics: results}
+
+	var present, fabricated, checked, found, expected int
+	for _, t := range results {
+		present += t.Present
+		fabricated += t.Fabricated
+		checked += t.Present + t.Misquoted + t.Fabricated
+		found += len(t.Found)
+		expected += t.Expected
+		r.Duration += t.Duration
+		if t.Error != "" {
+			r.Failed++
+		}
+		if t.CostSummary != nil {
+			r.CostUSD += t.CostSummary.EstimatedCostUSD
+		}
+	}
+	if checked > 0 {
+		r.Precision = ratio(present, checked)
+		r.HallucinationRate = ratio(fabricated, checked)
+	}
+	if expected > 0 {
+		r.Recall = ratio(found, expected)
+	}
+	return r
+}
+
+// WriteText writes a human-readable report
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Evaluation of dataset %q (%d topics, %d failed)\n\n", r.Dataset, len(r.Topics), r.Failed)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOPIC\tRETURNED\tPRESENT\tMISQUOTED\tFABRICATED\tUNREACHABLE\tPRECISION\tRECALL\tHALLUCINATION\tTIME")
+	for _, t := range r.Topics {
+		if t.Error != "" {
+			fmt.Fprintf(tw, "%s\tERROR: %s\n", t.Topic, t.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			t.Topic, t.Returned, t.Present, t.Misquoted, t.Fabricated, t.Unreachable,
+			percent(t.Precision), percent(t.Recall), percent(t.HallucinationRate), t.Duration.Round(time.Second))
+	}
+	fmt.Fprintf(tw, "OVERALL\t\t\t\t\t\t%s\t%s\t%s\t%s\n",
+		percent(r.Precision), percent(r.Recall), percent(r.HallucinationRate), r.Duration.Round(time.Second))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, t := range r.Topics {
+		if len(t.Missed) > 0 {
+			fmt.Fprintf(w, "\nMissed for %q:\n", t.Topic)
+			for _, name := range t.Missed {
+				fmt.Fprintf(w, "  - %s\n", name)
+			}
+		}
+	}
+	if r.CostUSD > 0 {
+		fmt.Fprintf(w, "\nEstimated LLM cost: $%.4f\n", r.CostUSD)
+	}
+	return nil
+}
+
+// matchesAny reports whether any statistic matches an answer-key entry
+func matchesAny(want Expected, stats []models.Statistic) bool {
+	tolerance := want.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	wantValue, wantUnit := scale(want.Value, want.Unit)
+	for _, s := range stats {
+		value, unit := scale(float64(s.Value), s.Unit)
+		if math.Abs(value-wantValue) > tolerance*math.Abs(wantValue) {
+			continue
+		}
+		if unitsCompatible(unit, wantUnit) {
+			return true
+		}
+	}
+	return false
+}
+
+// scale multiplies a value by a magnitude word in its unit ("1.5", "billion
+// people") and returns the remaining unit
+func scale(value float64, unit string) (float64, string) {
+	var rest []string
+	for _, word := range strings.Fields(strings.ToLower(unit)) {
+		if m, ok := extract.Magnitudes[word]; ok {
+			value *= m
+			continue
+		}
+		rest = append(rest, word)
+	}
+	return value, strings.Join(rest, " ")
+}
+
+// unitAliases folds common unit symbols into words
+var unitAliases = strings.NewReplacer("%", "percent", "°c", "degrees celsius", "℃", "degrees celsius")
+
+// unitsCompatible compares units loosely: an empty unit matches anything, and
+// otherwise one must contain the other after folding symbols into words
+func unitsCompatible(a, b string) bool {
+	a, b = unitAliases.Replace(a), unitAliases.Replace(b)
+	if a == "" || b == "" {
+		return true
+	}
+	return strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+// ratio returns n/d as a metric
+func ratio(n, d int) *float64 {
+	v := float64(n) / float64(d)
+	return &v
+}
+
+// percent formats a metric, or "-" when undefined
+func percent(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", *v*100)
+}
@@ -0,0 +1,365 @@
+// Package evidence packages everything needed to audit a run into a single
+// tar.gz or zip bundle: the response, the archived snapshots of the sources
+// its statistics were verified against, an audit log of every verification
+// decision, the prompt templates in use, and the intermediate artifacts of a
+// reproducible run. The bundle is content addressed: its manifest lists the
+// SHA-256 of every file, snapshots are named by their content hash, and the
+// same run always produces the same bytes, so the manifest's hash identifies
+// the bundle.
+package evidence
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+// Format is an archive format of a bundle
+type Format string
+
+// Bundle formats
+const (
+	FormatTarGz Format = "tar.gz"
+	FormatZip   Format = "zip"
+)
+
+// ParseFormat validates a format name; "tar", "tgz", and "" mean tar.gz
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "tar.gz", "tgz", "tar":
+		return FormatTarGz, nil
+	case "zip":
+		return FormatZip, nil
+	}
+	return "", fmt.Errorf("unsupported evidence bundle format: %q (supported: tar.gz, zip)", s)
+}
+
+// FormatOf returns the format of a bundle file name, zip for .zip and
+// tar.gz otherwise
+func FormatOf(name string) Format {
+	if strings.EqualFold(path.Ext(name), ".zip") {
+		return FormatZip
+	}
+	return FormatTarGz
+}
+
+// ContentType returns the MIME type of a format
+func (f Format) ContentType() string {
+	if f == FormatZip {
+		return "application/zip"
+	}
+	return "application/gzip"
+}
+
+// Extension returns the file extension of a format
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// ManifestPath is the bundle's table of contents, its first file
+const ManifestPath = "manifest.json"
+
+// Manifest describes a bundle
+type Manifest struct {
+	Topic            string            `json:"topic"`
+	JobID            string            `json:"job_id,omitempty"`
+	RunTimestamp     time.Time         `json:"run_timestamp"`
+	Files            []File            `json:"files"`                       // Every other file in the bundle
+	PromptVersions   map[string]string `json:"prompt_versions,omitempty"`   // Version of each prompt template
+	MissingSnapshots []string          `json:"missing_snapshots,omitempty"` // Content hashes of sources not in the archive
+	MissingArtifacts []string          `json:"missing_artifacts,omitempty"` // Reproducible-run artifacts not in the archive
+}
+
+// File is a file in a bundle
+type File struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"` // "sha256:<hex>"
+	Size   int    `json:"size"`
+}
+
+// AuditEntry is one line of audit.ndjson: the verification decision on one
+// candidate
+type AuditEntry struct {
+	Decision    string                 `json:"decision"` // "verified" or "rejected"
+	Name        string                 `json:"name"`
+	Value       float32                `json:"value"`
+	Unit        string                 `json:"unit"`
+	Source      string                 `json:"source"`
+	SourceURL   string                 `json:"source_url"`
+	Excerpt     string                 `json:"excerpt"`
+	Category    models.FailureCategory `json:"category,omitempty"` // Why a rejected candidate failed
+	Reason      string                 `json:"reason,omitempty"`
+	Method      string                 `json:"method,omitempty"`
+	ContentHash string                 `json:"content_hash,omitempty"` // Hash of the source content that was verified
+	Snapshot    string                 `json:"snapshot,omitempty"`     // Path of that content in the bundle
+	DateFound   time.Time              `json:"date_found,omitzero"`
+}
+
+// Options are the optional sources of a bundle's contents
+type Options struct {
+	Job     *models.Job   // The job that ran, when the run was queued
+	Archive archive.Store // Snapshots of verified sources; nil leaves them out
+	Prompts *prompts.Set  // Prompt templates in use; nil leaves them out
+}
+
+// Bundle is an assembled evidence bundle
+type Bundle struct {
+	Manifest Manifest
+	manifest []byte
+	files    map[string][]byte
+	modTime  time.Time
+}
+
+// Build assembles the bundle of a run. Snapshots missing from the archive
+// are listed in the manifest rather than failing the bundle.
+func Build(ctx context.Context, resp *models.OrchestrationResponse, opts Options) (*Bundle, error) {
+	if resp == nil {
+		return nil, errors.New("no response to bundle")
+	}
+	b := &Bundle{
+		Manifest: Manifest{Topic: resp.Topic, RunTimestamp: resp.Timestamp.UTC()},
+		files:    make(map[string][]byte),
+		modTime:  resp.Timestamp.UTC(),
+	}
+	if b.modTime.IsZero() {
+		b.modTime = time.Unix(0, 0).UTC()
+	}
+
+	if err := b.addJSON("response.json", resp); err != nil {
+		return nil, err
+	}
+	if opts.Job != nil {
+		b.Manifest.JobID = opts.Job.ID
+		if err := b.addJSON("job.json", opts.Job); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshots, err := b.addSnapshots(ctx, resp, opts.Archive)
+	if err != nil {
+		return nil, err
+	}
+	b.addAudit(resp, snapshots)
+	if err := b.addArtifacts(ctx, resp, opts.Archive); err != nil {
+		return nil, err
+	}
+
+	if opts.Prompts != nil {
+		b.Manifest.PromptVersions = make(map[string]string)
+		for name, text := range opts.Prompts.Sources() {
+			b.add("prompts/"+string(name)+".tmpl", []byte(text))
+			b.Manifest.PromptVersions[string(name)] = opts.Prompts.Version(name)
+		}
+	}
+
+	for p, data := range b.files {
+		b.Manifest.Files = append(b.Manifest.Files, File{Path: p, SHA256: archive.Hash(data), Size: len(data)})
+	}
+	slices.SortFunc(b.Manifest.Files, func(x, y File) int { return strings.Compare(x.Path, y.Path) })
+	b.manifest, err = json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Bundle) add(p string, data []byte) {
+	b.files[p] = data
+}
+
+func (b *Bundle) addJSON(p string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", p, err)
+	}
+	b.add(p, data)
+	return nil
+}
+
+// addSnapshots adds the archived content of each verified statistic's
+// source and returns the bundle path by content hash
+func (b *Bundle) addSnapshots(ctx context.Context, resp *models.OrchestrationResponse, store archive.Store) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, stat := range resp.Statistics {
+		hash := stat.ContentHash
+		if hash == "" || paths[hash] != "" || slices.Contains(b.Manifest.MissingSnapshots, hash) {
+			continue
+		}
+		if store == nil {
+			b.Manifest.MissingSnapshots = append(b.Manifest.MissingSnapshots, hash)
+			continue
+		}
+		snap, err := store.Get(ctx, hash)
+		if errors.Is(err, archive.ErrNotFound) {
+			b.Manifest.MissingSnapshots = append(b.Manifest.MissingSnapshots, hash)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load snapshot %s: %w", hash, err)
+		}
+
+		base := "snapshots/" + strings.TrimPrefix(hash, "sha256:")
+		paths[hash] = base + extension(snap.ContentType)
+		b.add(paths[hash], snap.Body)
+		if err := b.addJSON(base+".json", snap); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// addArtifacts adds the intermediate artifacts a reproducible run stored in
+// the archive, as artifacts/<name>.json
+func (b *Bundle) addArtifacts(ctx context.Context, resp *models.OrchestrationResponse, store archive.Store) error {
+	if resp.Reproducibility == nil {
+		return nil
+	}
+	for _, a := range resp.Reproducibility.Artifacts {
+		if store == nil {
+			b.Manifest.MissingArtifacts = append(b.Manifest.MissingArtifacts, a.Name)
+			continue
+		}
+		snap, err := store.Get(ctx, a.Hash)
+		if errors.Is(err, archive.ErrNotFound) {
+			b.Manifest.MissingArtifacts = append(b.Manifest.MissingArtifacts, a.Name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load artifact %s: %w", a.Name, err)
+		}
+		b.add("artifacts/"+a.Name+".json", snap.Body)
+	}
+	return nil
+}
+
+// extension returns the file extension of a content type, or none
+func extension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return ".html"
+	case "application/pdf":
+		return ".pdf"
+	case "application/json":
+		return ".json"
+	case "text/csv":
+		return ".csv"
+	case "text/plain":
+		return ".txt"
+	}
+	return ""
+}
+
+// addAudit adds audit.ndjson, the verified statistics and then the
+// rejected candidates in the order they were decided
+func (b *Bundle) addAudit(resp *models.OrchestrationResponse, snapshots map[string]string) {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	entry := func(decision string, stat models.Statistic) AuditEntry {
+		return AuditEntry{
+			Decision:    decision,
+			Name:        stat.Name,
+			Value:       stat.Value,
+			Unit:        stat.Unit,
+			Source:      stat.Source,
+			SourceURL:   stat.SourceURL,
+			Excerpt:     stat.Excerpt,
+			ContentHash: stat.ContentHash,
+			Snapshot:    snapshots[stat.ContentHash],
+			DateFound:   stat.DateFound,
+		}
+	}
+	for _, stat := range resp.Statistics {
+		_ = enc.Encode(entry("verified", stat)) // Encoding into memory cannot fail
+	}
+	for _, result := range resp.Rejected {
+		if result.Statistic == nil {
+			continue
+		}
+		e := entry("rejected", *result.Statistic)
+		e.Category, e.Reason, e.Method = result.Category, result.Reason, result.Method
+		_ = enc.Encode(e)
+	}
+	b.add("audit.ndjson", []byte(buf.String()))
+}
+
+// Hash returns the bundle's identity, the SHA-256 of its manifest
+func (b *Bundle) Hash() string {
+	sum := sha256.Sum256(b.manifest)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Write writes the bundle in format f: the manifest first, then the files
+// in path order
+func (b *Bundle) Write(w io.Writer, f Format) error {
+	paths := make([]string, 0, len(b.files))
+	for _, file := range b.Manifest.Files {
+		paths = append(paths, file.Path)
+	}
+	if f == FormatZip {
+		return b.writeZip(w, paths)
+	}
+	return b.writeTarGz(w, paths)
+}
+
+func (b *Bundle) writeTarGz(w io.Writer, paths []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(p string, data []byte) error {
+		hdr := &tar.Header{Name: p, Mode: 0o644, Size: int64(len(data)), ModTime: b.modTime, Format: tar.FormatPAX}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := write(ManifestPath, b.manifest); err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if err := write(p, b.files[p]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (b *Bundle) writeZip(w io.Writer, paths []string) error {
+	zw := zip.NewWriter(w)
+	write := func(p string, data []byte) error {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: p, Method: zip.Deflate, Modified: b.modTime})
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(data)
+		return err
+	}
+	if err := write(ManifestPath, b.manifest); err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if err := write(p, b.files[p]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,221 @@
+package evidence
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+var discard = slog.New(slog.DiscardHandler)
+
+func testRun(t *testing.T) (*models.OrchestrationResponse, archive.Store) {
+	t.Helper()
+	store, err := archive.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := archive.NewSnapshot("https://example.gov/report", "text/html; charset=utf-8", []byte("<p>Solar grew 24% in 2024.</p>"))
+	if _, err := store.Put(context.Background(), snap); err != nil {
+		t.Fatal(err)
+	}
+	artifact := archive.NewSnapshot("artifact:01-request", "application/json", []byte(`{"topic": "solar"}`))
+	if _, err := store.Put(context.Background(), artifact); err != nil {
+		t.Fatal(err)
+	}
+	resp := &models.OrchestrationResponse{
+		Topic: "solar",
+		Reproducibility: &models.Reproducibility{Artifacts: []models.Artifact{
+			{Name: "01-request", Hash: artifact.Hash},
+			{Name: "02-research-request", Hash: archive.Hash([]byte("lost"))},
+		}},
+		Statistics: []models.Statistic{
+			{Name: "Solar growth", Value: 24, Unit: "%", SourceURL: "https://example.gov/report", Excerpt: "Solar grew 24% in 2024.", ContentHash: snap.Hash},
+			{Name: "Wind growth", Value: 9, Unit: "%", SourceURL: "https://example.org/wind", Excerpt: "Wind grew 9%.", ContentHash: archive.Hash([]byte("gone"))},
+		},
+		Rejected: []models.VerificationResult{
+			{Statistic: &models.Statistic{Name: "Hydro", Value: 3, SourceURL: "https://example.com"}, Category: models.FailureValueMismatch, Reason: "value not in source"},
+		},
+		Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	return resp, store
+}
+
+// untar returns the names and contents of a tar.gz bundle, in order
+func untar(t *testing.T, data []byte) ([]string, map[string][]byte) {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	files := make(map[string][]byte)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		files[hdr.Name] = body
+	}
+	return names, files
+}
+
+func TestBuild(t *testing.T) {
+	resp, store := testRun(t)
+	opts := Options{Archive: store, Prompts: prompts.Default()}
+	bundle, err := Build(context.Background(), resp, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tgz bytes.Buffer
+	if err := bundle.Write(&tgz, FormatTarGz); err != nil {
+		t.Fatal(err)
+	}
+	names, files := untar(t, tgz.Bytes())
+	if names[0] != ManifestPath {
+		t.Errorf("first file = %s; want the manifest", names[0])
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(files[ManifestPath], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Files) != len(names)-1 {
+		t.Errorf("manifest lists %d files; bundle has %d", len(manifest.Files), len(names)-1)
+	}
+	for _, f := range manifest.Files {
+		if got := archive.Hash(files[f.Path]); got != f.SHA256 {
+			t.Errorf("%s hash = %s; manifest says %s", f.Path, got, f.SHA256)
+		}
+	}
+	if len(manifest.MissingSnapshots) != 1 || manifest.MissingSnapshots[0] != resp.Statistics[1].ContentHash {
+		t.Errorf("missing snapshots = %v", manifest.MissingSnapshots)
+	}
+	if string(files["artifacts/01-request.json"]) != `{"topic": "solar"}` {
+		t.Errorf("request artifact = %q", files["artifacts/01-request.json"])
+	}
+	if len(manifest.MissingArtifacts) != 1 || manifest.MissingArtifacts[0] != "02-research-request" {
+		t.Errorf("missing artifacts = %v", manifest.MissingArtifacts)
+	}
+	if len(manifest.PromptVersions) == 0 {
+		t.Error("no prompt versions")
+	}
+
+	snapshot := "snapshots/" + strings.TrimPrefix(resp.Statistics[0].ContentHash, "sha256:") + ".html"
+	if string(files[snapshot]) != "<p>Solar grew 24% in 2024.</p>" {
+		t.Errorf("snapshot %s = %q", snapshot, files[snapshot])
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(files["audit.ndjson"])), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("audit log has %d entries; want 3", len(lines))
+	}
+	var first, last AuditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil {
+		t.Fatal(err)
+	}
+	if first.Decision != "verified" || first.Snapshot != snapshot {
+		t.Errorf("first audit entry = %+v", first)
+	}
+	if last.Decision != "rejected" || last.Category != models.FailureValueMismatch {
+		t.Errorf("last audit entry = %+v", last)
+	}
+
+	// The same run always produces the same bundle
+	again, err := Build(context.Background(), resp, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tgz2 bytes.Buffer
+	if err := again.Write(&tgz2, FormatTarGz); err != nil {
+		t.Fatal(err)
+	}
+	if again.Hash() != bundle.Hash() || !bytes.Equal(tgz.Bytes(), tgz2.Bytes()) {
+		t.Error("rebuilding the bundle changed it")
+	}
+}
+
+func TestJobHandler(t *testing.T) {
+	resp, store := testRun(t)
+	jobs := jobqueue.NewMemoryStore(0)
+	ctx := context.Background()
+	if err := jobs.Put(ctx, &models.Job{ID: "done", Status: models.JobSucceeded, Response: resp}); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Put(ctx, &models.Job{ID: "queued", Status: models.JobQueued}); err != nil {
+		t.Fatal(err)
+	}
+	runner := jobqueue.New(jobqueue.NewMemoryQueue(), jobs, nil, nil, 0, 1, time.Minute, discard)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/{id}/evidence", JobHandler(runner, store, nil, discard))
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	rec := get("/jobs/done/evidence?format=zip")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("zip GET = %d %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get(HashHeader) == "" {
+		t.Error("no bundle hash header")
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zr.File[0].Name != ManifestPath {
+		t.Errorf("first zip file = %s", zr.File[0].Name)
+	}
+	var hasJob bool
+	for _, f := range zr.File {
+		hasJob = hasJob || f.Name == "job.json"
+	}
+	if !hasJob {
+		t.Error("bundle of a job has no job.json")
+	}
+
+	if rec := get("/jobs/queued/evidence"); rec.Code != http.StatusConflict {
+		t.Errorf("unfinished job GET = %d; want 409", rec.Code)
+	}
+	if rec := get("/jobs/nope/evidence"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown job GET = %d; want 404", rec.Code)
+	}
+	if rec := get("/jobs/done/evidence?format=rar"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad format GET = %d; want 400", rec.Code)
+	}
+
+	mux = http.NewServeMux()
+	mux.HandleFunc("/jobs/{id}/evidence", JobHandler(nil, store, nil, discard))
+	if rec := get("/jobs/done/evidence"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET without a job queue = %d; want 404", rec.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package evidence
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+// HashHeader carries a bundle's hash in the response
+const HashHeader = "X-Evidence-Bundle-Hash"
+
+// JobHandler returns the handler of GET /jobs/{id}/evidence, which
+// downloads the evidence bundle of a succeeded job, as tar.gz or, with
+// ?format=zip, zip. Tenants only see their own jobs.
+func JobHandler(jobs *jobqueue.Runner, store archive.Store, promptSet *prompts.Set, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if jobs == nil {
+			http.Error(w, "job queue is not configured; set JOB_QUEUE", http.StatusNotFound)
+			return
+		}
+		format, err := ParseFormat(r.URL.Query().Get("format"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id := r.PathValue("id")
+		job, err := jobs.Job(r.Context(), id)
+		if errors.Is(err, jobqueue.ErrNotFound) {
+			http.Error(w, jobqueue.ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to load job", "job_id", id, "error", err)
+			http.Error(w, "failed to load job", http.StatusInternalServerError)
+			return
+		}
+		if job.Response == nil {
+			http.Error(w, fmt.Sprintf("job is %s; evidence is available once it succeeds", job.Status), http.StatusConflict)
+			return
+		}
+
+		bundle, err := Build(r.Context(), job.Response, Options{Job: job, Archive: store, Prompts: promptSet})
+		if err != nil {
+			logger.Error("failed to build evidence bundle", "job_id", id, "error", err)
+			http.Error(w, fmt.Sprintf("Evidence bundle failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "evidence-"+id+format.Extension()))
+		w.Header().Set(HashHeader, bundle.Hash())
+		if err := bundle.Write(w, format); err != nil {
+			logger.Error("failed to write evidence bundle", "job_id", id, "error", err)
+		}
+	}
+}
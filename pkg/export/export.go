@@ -0,0 +1,357 @@
+// Package export converts verified statistics into citation formats for
+// academic and journalistic use: BibTeX, CSL-JSON, and formatted APA and MLA
+// reference lists. It also writes them as CSV for spreadsheets.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Format is a citation export format
+type Format string
+
+const (
+	FormatBibTeX  Format = "bibtex"
+	FormatCSLJSON Format = "csl-json"
+	FormatAPA     Format = "apa"
+	FormatMLA     Format = "mla"
+	FormatCSV     Format = "csv"
+)
+
+// Formats lists the supported export formats
+var Formats = []Format{FormatBibTeX, FormatCSLJSON, FormatAPA, FormatMLA, FormatCSV}
+
+// ParseFormat validates a format name
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported export format: %q (supported: bibtex, csl-json, apa, mla, csv)", s)
+}
+
+// ContentType returns the MIME type for a format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatBibTeX:
+		return "application/x-bibtex; charset=utf-8"
+	case FormatCSLJSON:
+		return "application/vnd.citationstyles.csl+json"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Write renders the statistics of an orchestration response in the given format
+func Write(w io.Writer, resp *models.OrchestrationResponse, f Format) error {
+	var out string
+	switch f {
+	case FormatBibTeX:
+		out = BibTeX(resp.Statistics)
+	case FormatCSLJSON:
+		data, err := CSLJSON(resp.Statistics)
+		if err != nil {
+			return err
+		}
+		out = string(data) + "\n"
+	case FormatAPA:
+		out = referenceList(resp.Statistics, APA)
+	case FormatMLA:
+		out = referenceList(resp.Statistics, MLA)
+	case FormatCSV:
+		return CSV(w, resp.Statistics)
+	default:
+		return fmt.Errorf("unsupported export format: %q", f)
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+// csvColumns are the columns of the CSV format, one row per statistic
+var csvColumns = []string{"name", "value", "unit", "source", "source_url", "publisher", "published_at", "type", "excerpt", "date_found"}
+
+// CSV writes statistics as CSV with a header row. Undated sources leave
+// published_at empty.
+func CSV(w io.Writer, stats []models.Statistic) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, stat := range stats {
+		published := ""
+		if !stat.PublishedAt.IsZero() {
+			published = stat.PublishedAt.Format(time.DateOnly)
+		}
+		found := ""
+		if !stat.DateFound.IsZero() {
+			found = stat.DateFound.Format(time.DateOnly)
+		}
+		record := []string{
+			stat.Name, strconv.FormatFloat(float64(stat.Value), 'f', -1, 32), stat.Unit,
+			stat.Source, stat.SourceURL, stat.Publisher, published, string(stat.Type), stat.Excerpt, found,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// BibTeX renders statistics as @misc entries with unique citation keys
+func BibTeX(stats []models.Statistic) string {
+	keys := make(map[string]int)
+	var b strings.Builder
+
+	for i, stat := range stats {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		key := citationKey(stat)
+		if n := keys[key]; n > 0 {
+			keys[key]++
+			key = fmt.Sprintf("%s%c", key, 'a'+rune(n-1)%26)
+		} else {
+			keys[key] = 1
+		}
+
+		fmt.Fprintf(&b, "@misc{%s,\n", key)
+		writeField(&b, "title", "{"+escapeBibTeX(stat.Name)+"}")
+		if author := publisher(stat); author != "" {
+			// Double braces keep organizational authors from being split into names
+			writeField(&b, "author", "{"+escapeBibTeX(author)+"}")
+		}
+		writeField(&b, "howpublished", `\url{`+stat.SourceURL+"}")
+		writeField(&b, "url", stat.SourceURL)
+		if !stat.PublishedAt.IsZero() {
+			writeField(&b, "date", stat.PublishedAt.Format("2006-01-02"))
+			writeField(&b, "year", stat.PublishedAt.Format("2006"))
+		}
+		if !stat.DateFound.IsZero() {
+			writeField(&b, "urldate", stat.DateFound.Format("2006-01-02"))
+		}
+		writeField(&b, "note", escapeBibTeX(valueNote(stat)))
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// cslItem is a CSL-JSON item (https://citationstyles.org/)
+type cslItem struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Author    []cslName `json:"author,omitempty"`
+	Publisher string    `json:"publisher,omitempty"`
+	URL       string    `json:"URL"`
+	Issued    *cslDate  `json:"issued,omitempty"`
+	Accessed  *cslDate  `json:"accessed,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	Abstract  string    `json:"abstract,omitempty"`
+}
+
+type cslName struct {
+	Literal string `json:"literal"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// CSLJSON renders statistics as a CSL-JSON array, importable into Zotero,
+// Mendeley, and pandoc-citeproc
+func CSLJSON(stats []models.Statistic) ([]byte, error) {
+	items := make([]cslItem, 0, len(stats))
+	for i, stat := range stats {
+		item := cslItem{
+			ID:        fmt.Sprintf("stat-%d", i+1),
+			Type:      "webpage",
+			Title:     stat.Name,
+			Publisher: publisher(stat),
+			URL:       stat.SourceURL,
+			Note:      valueNote(stat),
+			Abstract:  stat.Excerpt,
+		}
+		if item.Publisher != "" {
+			item.Author = []cslName{{Literal: item.Publisher}}
+		}
+		if !stat.PublishedAt.IsZero() {
+			item.Issued = cslDateOf(stat.PublishedAt)
+		}
+		if !stat.DateFound.IsZero() {
+			item.Accessed = cslDateOf(stat.DateFound)
+		}
+		items = append(items, item)
+	}
+	return json.MarshalIndent(items, "", "  ")
+}
+
+func cslDateOf(t time.Time) *cslDate {
+	return &cslDate{DateParts: [][]int{{t.Year(), int(t.Month()), t.Day()}}}
+}
+
+// APA formats a statistic as an APA 7 reference for a web page, dated when
+// the page declares its publication date:
+//
+//	Publisher. (2024, March 5). Title. Retrieved January 5, 2025, from https://...
+//	Source. (n.d.). Title. Retrieved January 5, 2025, from https://...
+func APA(stat models.Statistic) string {
+	var b strings.Builder
+	author := publisher(stat)
+	if author == "" {
+		author = siteName(stat.SourceURL)
+	}
+	date := "n.d."
+	if !stat.PublishedAt.IsZero() {
+		date = stat.PublishedAt.Format("2006, January 2")
+	}
+	b.WriteString(strings.TrimSuffix(author, ".") + ". (" + date + "). ")
+	b.WriteString(sentenceEnd(stat.Name) + " ")
+	if !stat.DateFound.IsZero() {
+		b.WriteString("Retrieved " + stat.DateFound.Format("January 2, 2006") + ", from ")
+	}
+	b.WriteString(stat.SourceURL)
+	return b.String()
+}
+
+// MLA formats a statistic as an MLA 9 works-cited entry, with the
+// publication date when the page declares it:
+//
+//	"Title." Publisher, 5 Mar. 2024, https://.... Accessed 5 Jan. 2025.
+func MLA(stat models.Statistic) string {
+	var b strings.Builder
+	b.WriteString(`"` + sentenceEnd(stat.Name) + `" `)
+	if p := publisher(stat); p != "" {
+		b.WriteString(p + ", ")
+	}
+	if !stat.PublishedAt.IsZero() {
+		b.WriteString(mlaDate(stat.PublishedAt) + ", ")
+	}
+	b.WriteString(strings.TrimPrefix(strings.TrimPrefix(stat.SourceURL, "https://"), "http://") + ".")
+	if !stat.DateFound.IsZero() {
+		b.WriteString(" Accessed " + mlaDate(stat.DateFound) + ".")
+	}
+	return b.String()
+}
+
+// referenceList renders one formatted citation per statistic
+func referenceList(stats []models.Statistic, format func(models.Statistic) string) string {
+	var b strings.Builder
+	for _, stat := range stats {
+		b.WriteString(format(stat))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// mlaMonths are the MLA abbreviations for month names
+var mlaMonths = [...]string{"Jan.", "Feb.", "Mar.", "Apr.", "May", "June", "July", "Aug.", "Sept.", "Oct.", "Nov.", "Dec."}
+
+// mlaDate formats a date in MLA day-month-year style, e.g. "5 Jan. 2025"
+func mlaDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), mlaMonths[t.Month()-1], t.Year())
+}
+
+// valueNote describes the statistic's value, e.g. "Value: 48 %"
+func valueNote(stat models.Statistic) string {
+	note := fmt.Sprintf("Value: %v", stat.Value)
+	if stat.Unit != "" {
+		note += " " + stat.Unit
+	}
+	return note
+}
+
+// publisher names the source in citations: the publisher the page
+// declares, or else the source
+func publisher(stat models.Statistic) string {
+	if stat.Publisher != "" {
+		return stat.Publisher
+	}
+	return stat.Source
+}
+
+// citationKey builds a BibTeX key from the publisher and the publication
+// year, or the access year of undated pages
+func citationKey(stat models.Statistic) string {
+	base := publisher(stat)
+	if base == "" {
+		base = siteName(stat.SourceURL)
+	}
+
+	var b strings.Builder
+	for _, word := range strings.Fields(base) {
+		for _, r := range strings.ToLower(word) {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				b.WriteRune(r)
+			}
+		}
+		if b.Len() >= 24 {
+			break
+		}
+	}
+	key := b.String()
+	if key == "" {
+		key = "stat"
+	}
+	switch {
+	case !stat.PublishedAt.IsZero():
+		key += stat.PublishedAt.Format("2006")
+	case !stat.DateFound.IsZero():
+		key += stat.DateFound.Format("2006")
+	}
+	return key
+}
+
+// siteName returns the host of a URL without a leading "www."
+func siteName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// sentenceEnd ensures text ends with terminal punctuation
+func sentenceEnd(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s[len(s)-1:], ".?!") {
+		return s
+	}
+	return s + "."
+}
+
+// writeField writes one BibTeX field line
+func writeField(b *strings.Builder, name, value string) {
+	fmt.Fprintf(b, "  %s = {%s},\n", name, value)
+}
+
+// bibtexEscaper escapes characters with special meaning in BibTeX
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"&", `\&`,
+	"%", `\%`,
+	"$", `\$`,
+	"#", `\#`,
+	"_", `\_`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+// escapeBibTeX escapes text for use inside a BibTeX field
+func escapeBibTeX(s string) string {
+	return bibtexEscaper.Replace(s)
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+var testStats = []models.Statistic{
+	{
+		Name:      "Share of U.S. adults who get news from social media",
+		Value:     48,
+		Unit:      "%",
+		Source:    "Pew Research Center",
+		SourceURL: "https://www.pewresearch.org/journalism/fact-sheet/social-media",
+		Excerpt:   "48% of U.S. adults get news from social media",
+		DateFound: time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Name:      "R&D spending growth",
+		Value:     3.2,
+		Unit:      "%",
+		Source:    "Pew Research Center",
+		SourceURL: "https://www.pewresearch.org/science/rd",
+		DateFound: time.Date(2025, time.September, 14, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+func TestBibTeX(t *testing.T) {
+	out := BibTeX(testStats)
+
+	for _, want := range []string{
+		"@misc{pewresearchcenter2025,",
+		"@misc{pewresearchcenter2025a,",
+		"author = {{Pew Research Center}},",
+		"title = {{R\\&D spending growth}},",
+		"urldate = {2025-01-05},",
+		"note = {Value: 48 \\%},",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("BibTeX() missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestCSLJSON(t *testing.T) {
+	data, err := CSLJSON(testStats[:1])
+	if err != nil {
+		t.Fatalf("CSLJSON() error = %v", err)
+	}
+
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		t.Fatalf("CSLJSON() produced invalid JSON: %v", err)
+	}
+	if len(items) != 1 || items[0]["type"] != "webpage" || items[0]["URL"] != testStats[0].SourceURL {
+		t.Errorf("CSLJSON() = %s", data)
+	}
+	if !strings.Contains(string(data), `"date-parts": [`) {
+		t.Errorf("CSLJSON() missing accessed date: %s", data)
+	}
+}
+
+func TestFormattedCitations(t *testing.T) {
+	apa := APA(testStats[0])
+	wantAPA := "Pew Research Center. (n.d.). Share of U.S. adults who get news from social media. " +
+		"Retrieved January 5, 2025, from https://www.pewresearch.org/journalism/fact-sheet/social-media"
+	if apa != wantAPA {
+		t.Errorf("APA() = %q, want %q", apa, wantAPA)
+	}
+
+	mla := MLA(testStats[1])
+	wantMLA := `"R&D spending growth." Pew Research Center, www.pewresearch.org/science/rd. Accessed 14 Sept. 2025.`
+	if mla != wantMLA {
+		t.Errorf("MLA() = %q, want %q", mla, wantMLA)
+	}
+}
+
+func TestPublishedCitations(t *testing.T) {
+	stat := testStats[0]
+	stat.Source = "pewresearch.org"
+	stat.Publisher = "Pew Research Center"
+	stat.PublishedAt = time.Date(2024, time.September, 17, 0, 0, 0, 0, time.UTC)
+
+	wantAPA := "Pew Research Center. (2024, September 17). Share of U.S. adults who get news from social media. " +
+		"Retrieved January 5, 2025, from https://www.pewresearch.org/journalism/fact-sheet/social-media"
+	if apa := APA(stat); apa != wantAPA {
+		t.Errorf("APA() = %q, want %q", apa, wantAPA)
+	}
+	wantMLA := `"Share of U.S. adults who get news from social media." Pew Research Center, 17 Sept. 2024, ` +
+		`www.pewresearch.org/journalism/fact-sheet/social-media. Accessed 5 Jan. 2025.`
+	if mla := MLA(stat); mla != wantMLA {
+		t.Errorf("MLA() = %q, want %q", mla, wantMLA)
+	}
+
+	bib := BibTeX([]models.Statistic{stat})
+	for _, want := range []string{"@misc{pewresearchcenter2024,", "author = {{Pew Research Center}},", "date = {2024-09-17},"} {
+		if !strings.Contains(bib, want) {
+			t.Errorf("BibTeX() missing %q in:\n%s", want, bib)
+		}
+	}
+
+	data, err := CSLJSON([]models.Statistic{stat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"issued"`) || !strings.Contains(string(data), `"publisher": "Pew Research Center"`) {
+		t.Errorf("CSLJSON() = %s", data)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	stats := append([]models.Statistic(nil), testStats...)
+	stats[0].PublishedAt = time.Date(2024, time.September, 25, 0, 0, 0, 0, time.UTC)
+	stats[0].Type = models.TypeSurvey
+	stats[1].Name = `R&D spending growth, "real" terms`
+
+	var b strings.Builder
+	if err := Write(&b, &models.OrchestrationResponse{Statistics: stats}, FormatCSV); err != nil {
+		t.Fatalf("Write(csv) error = %v", err)
+	}
+	want := "name,value,unit,source,source_url,publisher,published_at,type,excerpt,date_found\n" +
+		"Share of U.S. adults who get news from social media,48,%,Pew Research Center,https://www.pewresearch.org/journalism/fact-sheet/social-media,,2024-09-25,survey,48% of U.S. adults get news from social media,2025-01-05\n" +
+		`"R&D spending growth, ""real"" terms",3.2,%,Pew Research Center,https://www.pewresearch.org/science/rd,,,,,2025-09-14` + "\n"
+	if b.String() != want {
+		t.Errorf("Write(csv) =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("BibTeX"); err != nil || f != FormatBibTeX {
+		t.Errorf("ParseFormat(BibTeX) = %q, %v", f, err)
+	}
+	if _, err := ParseFormat("json"); err == nil {
+		t.Error("ParseFormat(json) should fail")
+	}
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+)
+
+// Handler returns the /export HTTP handler. It accepts a POSTed
+// OrchestrationResponse and returns its statistics in the format named by the
+// ?format= query parameter (bibtex, csl-json, apa, mla, or csv; default bibtex).
+func Handler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := r.URL.Query().Get("format")
+		if name == "" {
+			name = string(FormatBibTeX)
+		}
+		format, err := ParseFormat(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var resp models.OrchestrationResponse
+		if err := migrate.Decode(r.Body, &resp); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		if err := Write(w, &resp, format); err != nil {
+			logger.Error("failed to write export", "format", format, "error", err)
+		}
+	}
+}
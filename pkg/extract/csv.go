@@ -0,0 +1,58 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseCSV parses CSV content, treating the first record as the header row
+func parseCSV(body []byte) (*Table, error) {
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")) // UTF-8 BOM
+
+	r := csv.NewReader(bytes.NewReader(body))
+	r.FieldsPerRecord = -1 // Tolerate ragged rows
+	r.LazyQuotes = true
+
+	table := &Table{Format: FormatCSV}
+	var offset int64
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		raw := strings.TrimSpace(string(body[offset:r.InputOffset()]))
+		offset = r.InputOffset()
+
+		if table.Headers == nil {
+			table.Headers = trimAll(record)
+			continue
+		}
+		table.Rows = append(table.Rows, Row{
+			Number: line,
+			Cells:  record,
+			Raw:    raw,
+		})
+	}
+
+	if table.Headers == nil {
+		return nil, fmt.Errorf("CSV has no header row")
+	}
+	return table, nil
+}
+
+// trimAll trims whitespace from every value
+func trimAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.TrimSpace(v)
+	}
+	return out
+}
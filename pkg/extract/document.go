@@ -0,0 +1,117 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+// IsDocument reports whether the format is a paged document, whose text is
+// read out of a binary file page by page
+func (f Format) IsDocument() bool {
+	return f == FormatPDF || f == FormatDOCX || f == FormatPPTX
+}
+
+// Document is the text of a PDF, Word, or PowerPoint file, page by page,
+// and the tables of a Word or PowerPoint file
+type Document struct {
+	Pages  []string
+	Tables []*Table // Labelled "table N", or "slide N table M" in a presentation
+}
+
+// ReadDocument reads a PDF, Word, or PowerPoint file. A Word document is
+// split where Word last laid out its page breaks, and is a single page when
+// it was never laid out; each slide of a presentation is a page, followed by
+// its speaker notes.
+func ReadDocument(format Format, body []byte) (*Document, error) {
+	var doc *Document
+	var err error
+	switch format {
+	case FormatPDF:
+		doc = &Document{}
+		doc.Pages, err = pdfPages(body)
+	case FormatDOCX:
+		doc, err = readDOCX(body)
+	case FormatPPTX:
+		doc, err = readPPTX(body)
+	default:
+		return nil, fmt.Errorf("unsupported document format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(strings.Join(doc.Pages, "")) == "" {
+		// Scanned pages have no text layer
+		return nil, fmt.Errorf("%s document contains no text", format)
+	}
+	return doc, nil
+}
+
+// Pages returns the text of each page of a PDF, Word, or PowerPoint file
+func Pages(format Format, body []byte) ([]string, error) {
+	doc, err := ReadDocument(format, body)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Pages, nil
+}
+
+// PageOf returns the 1-based number of the page showing excerpt, matched
+// after normalization, or 0 when no page does
+func PageOf(pages []string, excerpt string) int {
+	want := textmatch.Normalize(excerpt)
+	if want == "" {
+		return 0
+	}
+	for i, page := range pages {
+		if strings.Contains(textmatch.Normalize(page), want) {
+			return i + 1
+		}
+	}
+	best, bestScore := 0, 0.0
+	for i, page := range pages {
+		if found, score := textmatch.Contains(page, excerpt, textmatch.DefaultThreshold); found && score > bestScore {
+			best, bestScore = i+1, score
+		}
+	}
+	return best
+}
+
+// pdfPages reads the text layer of each page of a PDF
+func pdfPages(body []byte) (pages []string, err error) {
+	// The PDF reader panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			pages, err = nil, fmt.Errorf("failed to read PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= r.NumPage(); i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			pages = append(pages, "")
+			continue
+		}
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				f := page.Font(name)
+				fonts[name] = &f
+			}
+		}
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PDF page %d: %w", i, err)
+		}
+		pages = append(pages, text)
+	}
+	return pages, nil
+}
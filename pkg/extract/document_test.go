@@ -0,0 +1,187 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestPagesPDF(t *testing.T) {
+	body := buildPDF(t, "Solar capacity grew 24% in 2023", "Wind capacity grew 9% in 2023")
+
+	pages, err := Pages(FormatPDF, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 || !strings.Contains(pages[1], "Wind capacity grew 9%") {
+		t.Fatalf("Pages(pdf) = %q", pages)
+	}
+	if got := PageOf(pages, "wind capacity grew 9%"); got != 2 {
+		t.Errorf("PageOf(wind) = %d, want 2", got)
+	}
+	if got := PageOf(pages, "hydro output fell"); got != 0 {
+		t.Errorf("PageOf(missing) = %d, want 0", got)
+	}
+
+	if _, err := Pages(FormatPDF, []byte("%PDF-1.4 truncated")); err == nil {
+		t.Error("Pages(truncated pdf) should fail")
+	}
+}
+
+func TestPagesDOCX(t *testing.T) {
+	body := buildXLSX(t, map[string]string{"word/document.xml": `<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Revenue rose </w:t></w:r><w:r><w:t>12%</w:t></w:r></w:p>
+<w:p><w:r><w:br w:type="page"/><w:t>Headcount reached 4,200</w:t></w:r></w:p>
+<w:p><w:r><w:lastRenderedPageBreak/><w:t>Churn was 3.1%</w:t></w:r></w:p>
+</w:body></w:document>`})
+
+	pages, err := Pages(FormatDOCX, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 3 || strings.TrimSpace(pages[0]) != "Revenue rose 12%" {
+		t.Fatalf("Pages(docx) = %q", pages)
+	}
+	if got := PageOf(pages, "Churn was 3.1%"); got != 3 {
+		t.Errorf("PageOf(churn) = %d, want 3", got)
+	}
+
+	if _, err := Pages(FormatDOCX, buildXLSX(t, map[string]string{"word/styles.xml": "<styles/>"})); err == nil {
+		t.Error("Pages(docx without a body) should fail")
+	}
+}
+
+func TestReadDocumentDOCXTables(t *testing.T) {
+	body := buildXLSX(t, map[string]string{"word/document.xml": `<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:tabs><w:tab w:val="left" w:pos="720"/></w:tabs></w:pPr><w:r><w:t>Regional results</w:t></w:r></w:p>
+<w:tbl>
+<w:tr><w:tc><w:p><w:r><w:t>Region</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Churn</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>North</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>3.1%</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:tcPr><w:gridSpan w:val="2"/></w:tcPr><w:p><w:r><w:t>n/a</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Layout</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+</w:body></w:document>`})
+
+	doc, err := ReadDocument(FormatDOCX, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Pages) != 1 || !strings.HasPrefix(doc.Pages[0], "Regional results\n") || !strings.Contains(doc.Pages[0], "North") {
+		t.Errorf("Pages = %q", doc.Pages)
+	}
+	if len(doc.Tables) != 1 {
+		t.Fatalf("Tables = %d, want 1 (the layout table has no data rows)", len(doc.Tables))
+	}
+	table := doc.Tables[0]
+	if table.Format != FormatDOCX || table.Sheet != "table 1" || strings.Join(table.Headers, "|") != "Region|Churn" {
+		t.Errorf("table = %+v", table)
+	}
+	if len(table.Rows) != 2 || table.Rows[1].Number != 3 || strings.Join(table.Rows[1].Cells, "|") != "n/a|n/a" {
+		t.Errorf("rows = %+v", table.Rows)
+	}
+
+	tables, err := Parse(FormatDOCX, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cell, ok := Lookup(tables, &models.Provenance{Format: "docx", Sheet: "table 1", Row: 2, Column: "Churn"})
+	if !ok || cell != "3.1%" {
+		t.Errorf("Lookup(row 2, Churn) = %q, %v", cell, ok)
+	}
+}
+
+func TestReadDocumentPPTX(t *testing.T) {
+	const (
+		pres   = `xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+		rels   = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+		number = `<p:sp><p:nvSpPr><p:nvPr><p:ph type="sldNum"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:fld type="slidenum"><a:t>7</a:t></a:fld></a:p></p:txBody></p:sp>`
+	)
+	body := buildXLSX(t, map[string]string{
+		"ppt/presentation.xml": `<p:presentation ` + pres + `><p:sldIdLst><p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId2"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": rels +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide1.xml"/>` +
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide2.xml"/></Relationships>`,
+		// Shown first, though saved second
+		"ppt/slides/slide2.xml": `<p:sld ` + pres + `><p:cSld><p:spTree>
+<p:sp><p:txBody><a:p><a:r><a:t>Q3 highlights</a:t></a:r></a:p></p:txBody></p:sp>` + number + `
+</p:spTree></p:cSld></p:sld>`,
+		"ppt/slides/_rels/slide2.xml.rels": rels +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="../notesSlides/notesSlide1.xml"/></Relationships>`,
+		"ppt/notesSlides/notesSlide1.xml": `<p:notes ` + pres + `><p:cSld><p:spTree>
+<p:sp><p:nvSpPr><p:nvPr><p:ph type="body"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Net retention reached 118%</a:t></a:r></a:p></p:txBody></p:sp>` + number + `
+</p:spTree></p:cSld></p:notes>`,
+		"ppt/slides/slide1.xml": `<p:sld ` + pres + `><p:cSld><p:spTree>
+<p:graphicFrame><a:graphic><a:graphicData><a:tbl>
+<a:tr><a:tc><a:txBody><a:p><a:r><a:t>Segment</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>Win rate</a:t></a:r></a:p></a:txBody></a:tc></a:tr>
+<a:tr><a:tc><a:txBody><a:p><a:r><a:t>Enterprise</a:t></a:r></a:p></a:txBody></a:tc><a:tc><a:txBody><a:p><a:r><a:t>27%</a:t></a:r></a:p></a:txBody></a:tc></a:tr>
+</a:tbl></a:graphicData></a:graphic></p:graphicFrame>
+</p:spTree></p:cSld></p:sld>`,
+	})
+
+	doc, err := ReadDocument(FormatPPTX, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Pages) != 2 {
+		t.Fatalf("Pages = %q, want 2", doc.Pages)
+	}
+	if want := "Q3 highlights\n\nNotes:\nNet retention reached 118%\n"; doc.Pages[0] != want {
+		t.Errorf("Pages[0] = %q, want %q", doc.Pages[0], want)
+	}
+	if got := PageOf(doc.Pages, "net retention reached 118%"); got != 1 {
+		t.Errorf("PageOf(notes) = %d, want 1", got)
+	}
+	if got := PageOf(doc.Pages, "Enterprise 27%"); got != 2 {
+		t.Errorf("PageOf(table) = %d, want 2", got)
+	}
+	if len(doc.Tables) != 1 || doc.Tables[0].Sheet != "slide 2 table 1" || doc.Tables[0].Format != FormatPPTX {
+		t.Fatalf("Tables = %+v", doc.Tables)
+	}
+	if row := doc.Tables[0].Rows[0]; row.Number != 2 || strings.Join(row.Cells, "|") != "Enterprise|27%" {
+		t.Errorf("row = %+v", row)
+	}
+
+	if _, err := ReadDocument(FormatPPTX, buildXLSX(t, map[string]string{"ppt/slides/slide1.xml": "<p:sld/>"})); err == nil {
+		t.Error("ReadDocument(pptx without a presentation) should fail")
+	}
+}
+
+// buildPDF writes a PDF with one line of text on each page
+func buildPDF(t *testing.T, pages ...string) []byte {
+	t.Helper()
+	var objects []string
+	kids := make([]string, len(pages))
+	for i, text := range pages {
+		page, content := 3+2*i, 4+2*i
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+		stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>", 3+2*len(pages), content),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+	}, objects...)
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
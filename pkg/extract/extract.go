@@ -0,0 +1,347 @@
+// Package extract parses structured data files (CSV, JSON, XLSX) and HTML
+// <table> elements into header-aware tables, and turns their numeric cells
+// into candidate statistics with row/column provenance, so data files never
+// have to be dumped into an LLM prompt. It also reads the text of PDF, Word,
+// and PowerPoint documents page by page, and the tables of Word and
+// PowerPoint documents.
+package extract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Format identifies the structure of fetched content
+type Format string
+
+const (
+	FormatHTML Format = "html" // Web page: text goes to the LLM, <table> elements are parsed
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatXLSX Format = "xlsx"
+	FormatPDF  Format = "pdf"  // Paged document: the text of its pages goes to the LLM
+	FormatDOCX Format = "docx" // Word document, read like a PDF; its tables are parsed too
+	FormatPPTX Format = "pptx" // PowerPoint presentation, a page per slide
+)
+
+// DefaultMaxCandidates caps the candidates emitted from a single table
+const DefaultMaxCandidates = 50
+
+// Table is a header-aware view of a data file or HTML table
+type Table struct {
+	Format  Format
+	Sheet   string   // Worksheet name (XLSX) or table label (HTML, DOCX, PPTX)
+	Headers []string // Column headers
+	Rows    []Row
+}
+
+// Row is a single data row with its position in the source file
+type Row struct {
+	Number int      // 1-based row number within the file or sheet
+	Cells  []string // Cell values aligned with Table.Headers
+	Raw    string   // Verbatim source text of the row, when available
+}
+
+// DetectFormat determines the content format from the Content-Type header,
+// falling back to the URL file extension for generic types.
+func DetectFormat(contentType, rawURL string) Format {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "text/csv", "application/csv":
+			return FormatCSV
+		case "application/json", "text/json":
+			return FormatJSON
+		case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+			return FormatXLSX
+		case "application/pdf":
+			return FormatPDF
+		case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+			return FormatDOCX
+		case "application/vnd.openxmlformats-officedocument.presentationml.presentation":
+			return FormatPPTX
+		}
+		if strings.HasSuffix(mediaType, "+json") {
+			return FormatJSON
+		}
+	}
+
+	if u, err := url.Parse(rawURL); err == nil {
+		switch strings.ToLower(path.Ext(u.Path)) {
+		case ".csv":
+			return FormatCSV
+		case ".json":
+			return FormatJSON
+		case ".xlsx":
+			return FormatXLSX
+		case ".pdf":
+			return FormatPDF
+		case ".docx":
+			return FormatDOCX
+		case ".pptx":
+			return FormatPPTX
+		}
+	}
+
+	return FormatHTML
+}
+
+// mediaTypes are the media types of formats read from binary files or
+// named by a file extension
+var mediaTypes = map[Format]string{
+	FormatCSV:  "text/csv",
+	FormatJSON: "application/json",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+	FormatDOCX: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	FormatPPTX: "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	FormatHTML: "text/html",
+}
+
+// MediaType returns the media type of a format, so content saved without its
+// filename is still recognized by DetectFormat
+func (f Format) MediaType() string {
+	return mediaTypes[f]
+}
+
+// IsStructured reports whether the format can be parsed without an LLM
+func (f Format) IsStructured() bool {
+	return f == FormatCSV || f == FormatJSON || f == FormatXLSX
+}
+
+// Parse parses a data file into one or more tables. HTML pages and Word and
+// PowerPoint documents may have none.
+func Parse(format Format, body []byte) ([]*Table, error) {
+	switch format {
+	case FormatCSV:
+		t, err := parseCSV(body)
+		if err != nil {
+			return nil, err
+		}
+		return []*Table{t}, nil
+	case FormatJSON:
+		t, err := parseJSON(body)
+		if err != nil {
+			return nil, err
+		}
+		return []*Table{t}, nil
+	case FormatXLSX:
+		return parseXLSX(body)
+	case FormatHTML:
+		return parseHTMLTables(body)
+	case FormatDOCX, FormatPPTX:
+		doc, err := ReadDocument(format, body)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Tables, nil
+	default:
+		return nil, fmt.Errorf("unsupported data format: %s", format)
+	}
+}
+
+// Candidates converts the numeric cells of the table into candidate statistics.
+// Cells whose row label or column header mention topic terms are emitted first;
+// at most limit candidates are returned (DefaultMaxCandidates if limit <= 0).
+func (t *Table) Candidates(topic string, result models.SearchResult, limit int) []models.CandidateStatistic {
+	if limit <= 0 {
+		limit = DefaultMaxCandidates
+	}
+	terms := topicTerms(topic)
+
+	type scored struct {
+		cand  models.CandidateStatistic
+		score int
+	}
+	var all []scored
+
+	for _, row := range t.Rows {
+		label := t.rowLabel(row)
+		for col, cell := range row.Cells {
+			if col >= len(t.Headers) {
+				break
+			}
+			value, ok := ParseNumber(cell)
+			if !ok {
+				continue
+			}
+			header := t.Headers[col]
+
+			name := header
+			if label != "" {
+				name = fmt.Sprintf("%s - %s", label, header)
+			}
+
+			excerpt := row.Raw
+			if excerpt == "" {
+				excerpt = fmt.Sprintf("%s: %s", name, strings.TrimSpace(cell))
+			}
+
+			all = append(all, scored{
+				cand: models.CandidateStatistic{
+					Name:      name,
+					Value:     float32(value),
+					Unit:      unitFromHeader(header, cell),
+					Source:    result.Domain,
+					SourceURL: result.URL,
+					Excerpt:   excerpt,
+					Provenance: &models.Provenance{
+						Format: string(t.Format),
+						Sheet:  t.Sheet,
+						Row:    row.Number,
+						Column: header,
+					},
+				},
+				score: countTerms(strings.ToLower(label+" "+header), terms),
+			})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score > all[j].score })
+
+	candidates := make([]models.CandidateStatistic, 0, min(limit, len(all)))
+	for _, s := range all {
+		if len(candidates) >= limit {
+			break
+		}
+		candidates = append(candidates, s.cand)
+	}
+	return candidates
+}
+
+// Lookup returns the raw cell value at the given provenance, if present
+func Lookup(tables []*Table, prov *models.Provenance) (string, bool) {
+	if prov == nil {
+		return "", false
+	}
+	for _, t := range tables {
+		if string(t.Format) != prov.Format || t.Sheet != prov.Sheet {
+			continue
+		}
+		col := -1
+		for i, h := range t.Headers {
+			if h == prov.Column {
+				col = i
+				break
+			}
+		}
+		if col < 0 {
+			continue
+		}
+		for _, row := range t.Rows {
+			if row.Number == prov.Row && col < len(row.Cells) {
+				return row.Cells[col], true
+			}
+		}
+	}
+	return "", false
+}
+
+// rowLabel returns the first non-numeric cell of a row, used to name its values
+func (t *Table) rowLabel(row Row) string {
+	for _, cell := range row.Cells {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		if _, ok := ParseNumber(cell); !ok {
+			return cell
+		}
+	}
+	return ""
+}
+
+// ParseNumber parses a numeric cell, tolerating thousands separators,
+// currency symbols, and a trailing percent sign.
+func ParseNumber(cell string) (float64, bool) {
+	s := strings.TrimSpace(cell)
+	s = strings.TrimPrefix(s, "$")
+	s = strings.TrimPrefix(s, "€")
+	s = strings.TrimPrefix(s, "£")
+	s = strings.TrimSuffix(s, "%")
+	s = strings.ReplaceAll(s, ",", "")
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Number is a statistic value in LLM output. It accepts a JSON number or a
+// numeric string such as "1,234" or "12.5%", which models return for values
+// copied from the page text.
+type Number float32
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *Number) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var f float32
+	if err := json.Unmarshal(data, &f); err == nil {
+		*n = Number(f)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("value %s is not a number", data)
+	}
+	v, ok := ParseNumber(s)
+	if !ok {
+		return fmt.Errorf("value %q is not a number", s)
+	}
+	*n = Number(v)
+	return nil
+}
+
+// unitFromHeader infers a unit from the column header or the cell formatting
+func unitFromHeader(header, cell string) string {
+	if strings.HasSuffix(strings.TrimSpace(cell), "%") {
+		return "%"
+	}
+	lower := strings.ToLower(header)
+	if strings.Contains(lower, "%") || strings.Contains(lower, "percent") {
+		return "%"
+	}
+	// "GDP (current US$)" -> "current US$"
+	if open := strings.LastIndex(header, "("); open >= 0 {
+		if end := strings.LastIndex(header, ")"); end > open+1 {
+			return strings.TrimSpace(header[open+1 : end])
+		}
+	}
+	return ""
+}
+
+// topicTerms splits a topic into lowercase search terms
+func topicTerms(topic string) []string {
+	var terms []string
+	for _, f := range strings.Fields(strings.ToLower(topic)) {
+		if len(f) > 2 {
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
+
+// countTerms counts how many terms occur in s
+func countTerms(s string, terms []string) int {
+	n := 0
+	for _, term := range terms {
+		if strings.Contains(s, term) {
+			n++
+		}
+	}
+	return n
+}
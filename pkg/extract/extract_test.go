@@ -0,0 +1,203 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		url         string
+		want        Format
+	}{
+		{"text/csv; charset=utf-8", "https://example.com/data", FormatCSV},
+		{"application/json", "https://api.example.com/v1/stats", FormatJSON},
+		{"application/vnd.api+json", "https://api.example.com/v1/stats", FormatJSON},
+		{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "https://example.com/f", FormatXLSX},
+		{"application/octet-stream", "https://example.com/files/table.xlsx", FormatXLSX},
+		{"", "https://example.com/export.CSV?download=1", FormatCSV},
+		{"text/html; charset=utf-8", "https://example.com/report", FormatHTML},
+		{"application/pdf", "https://example.com/download?id=7", FormatPDF},
+		{"", "https://example.com/annual-report.pdf", FormatPDF},
+		{"application/octet-stream", "https://example.com/survey.docx", FormatDOCX},
+		{"application/vnd.openxmlformats-officedocument.presentationml.presentation", "https://example.com/f", FormatPPTX},
+		{"", "https://example.com/q3-review.pptx", FormatPPTX},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.contentType, tt.url); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.contentType, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestParseCSV_Candidates(t *testing.T) {
+	body := []byte("Country,Internet users (%),Population\nNorway,99.0,\"5,457,127\"\nChad,10.3,17179740\n")
+
+	tables, err := Parse(FormatCSV, body)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("expected 1 table, got %d", len(tables))
+	}
+
+	result := models.SearchResult{URL: "https://example.com/internet.csv", Domain: "example.com"}
+	cands := tables[0].Candidates("internet users", result, 0)
+	if len(cands) != 4 {
+		t.Fatalf("expected 4 candidates, got %d", len(cands))
+	}
+
+	// Topic-relevant columns are emitted first
+	first := cands[0]
+	if first.Name != "Norway - Internet users (%)" {
+		t.Errorf("unexpected name %q", first.Name)
+	}
+	if first.Value != 99.0 || first.Unit != "%" {
+		t.Errorf("unexpected value/unit %v %q", first.Value, first.Unit)
+	}
+	if first.Excerpt != "Norway,99.0,\"5,457,127\"" {
+		t.Errorf("expected raw CSV line as excerpt, got %q", first.Excerpt)
+	}
+	if first.Provenance == nil || first.Provenance.Row != 2 || first.Provenance.Column != "Internet users (%)" {
+		t.Errorf("unexpected provenance %+v", first.Provenance)
+	}
+
+	cell, ok := Lookup(tables, &models.Provenance{Format: "csv", Row: 2, Column: "Population"})
+	if !ok || cell != "5,457,127" {
+		t.Errorf("Lookup = %q, %v", cell, ok)
+	}
+	if v, ok := ParseNumber(cell); !ok || v != 5457127 {
+		t.Errorf("ParseNumber(%q) = %v, %v", cell, v, ok)
+	}
+}
+
+func TestParseJSON_WorldBankShape(t *testing.T) {
+	body := []byte(`[{"page":1},[{"country":{"value":"Kenya"},"date":"2022","value":29.5}]]`)
+
+	tables, err := Parse(FormatJSON, body)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	table := tables[0]
+
+	cell, ok := Lookup(tables, &models.Provenance{Format: "json", Row: 1, Column: "value"})
+	if !ok || cell != "29.5" {
+		t.Errorf("Lookup = %q, %v (headers %v)", cell, ok, table.Headers)
+	}
+
+	cands := table.Candidates("", models.SearchResult{URL: "https://api.worldbank.org/x"}, 0)
+	if len(cands) != 2 { // date and value are both numeric
+		t.Fatalf("expected 2 candidates, got %d", len(cands))
+	}
+	if cands[0].Name != "Kenya - date" && cands[0].Name != "Kenya - value" {
+		t.Errorf("expected row label from country.value, got %q", cands[0].Name)
+	}
+}
+
+func TestParseXLSX(t *testing.T) {
+	body := buildXLSX(t, map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Share" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>Vendor</t></si><si><t>Market share %</t></si><si><t>Acme</t></si></sst>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row><row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3"><v>41.5</v></c></row></sheetData></worksheet>`,
+	})
+
+	tables, err := Parse(FormatXLSX, body)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	cands := tables[0].Candidates("market share", models.SearchResult{}, 0)
+	if len(cands) != 1 {
+		t.Fatalf("expected 1 candidate, got %d", len(cands))
+	}
+	c := cands[0]
+	if c.Name != "Acme - Market share %" || c.Value != 41.5 || c.Unit != "%" {
+		t.Errorf("unexpected candidate %+v", c)
+	}
+	if c.Provenance.Sheet != "Share" || c.Provenance.Row != 3 {
+		t.Errorf("unexpected provenance %+v", c.Provenance)
+	}
+}
+
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseHTMLTables(t *testing.T) {
+	body := []byte(`<html><body>
+<table><tr><td><a href="/">Home</a></td></tr></table>
+<table>
+  <thead><tr><th>Browser</th><th>Market share</th></tr></thead>
+  <tbody>
+    <tr><td>Chrome</td><td>65.7%</td></tr>
+    <tr><td><b>Safari</b></td><td> 18.6 % </td></tr>
+  </tbody>
+</table>
+</body></html>`)
+
+	tables, err := Parse(FormatHTML, body)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("expected layout table to be skipped, got %d tables", len(tables))
+	}
+	table := tables[0]
+	if table.Sheet != "table 2" {
+		t.Errorf("expected label 'table 2', got %q", table.Sheet)
+	}
+
+	cell, ok := Lookup(tables, &models.Provenance{Format: "html", Sheet: "table 2", Row: 3, Column: "Market share"})
+	if !ok || cell != "18.6 %" {
+		t.Errorf("Lookup = %q, %v", cell, ok)
+	}
+
+	rendered := table.Render()
+	want := "[table 2] columns: Browser | Market share\nrow 2: Browser=Chrome; Market share=65.7%;\nrow 3: Browser=Safari; Market share=18.6 %;\n"
+	if rendered != want {
+		t.Errorf("Render() = %q, want %q", rendered, want)
+	}
+}
+
+func TestNumberUnmarshal(t *testing.T) {
+	var got []struct {
+		Value Number `json:"value"`
+	}
+	in := `[{"value": 12.5}, {"value": "1,234"}, {"value": "7.2%"}, {"value": null}]`
+	if err := json.Unmarshal([]byte(in), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []Number{12.5, 1234, 7.2, 0}
+	for i, w := range want {
+		if got[i].Value != w {
+			t.Errorf("value %d = %v, want %v", i, got[i].Value, w)
+		}
+	}
+
+	var n Number
+	if err := json.Unmarshal([]byte(`"about half"`), &n); err == nil {
+		t.Error("expected an error for a non-numeric string")
+	}
+}
@@ -0,0 +1,158 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// minTableRows skips layout tables that are too small to hold statistics
+const minTableRows = 2
+
+// parseHTMLTables converts every data-bearing <table> element of a page into
+// a header-aware table. Tables are labelled "table N" in document order and
+// rows are numbered by their position in the table, header row included.
+func parseHTMLTables(body []byte) ([]*Table, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var tables []*Table
+	index := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Table {
+			index++
+			if t := tableFromNode(n, fmt.Sprintf("table %d", index)); t != nil {
+				tables = append(tables, t)
+			}
+			// Nested tables are handled as part of their parent's cell text
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return tables, nil
+}
+
+// tableFromNode builds a table from a <table> node, or nil if it has no data rows
+func tableFromNode(n *html.Node, label string) *Table {
+	var rows [][]string
+
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Table:
+				continue // Skip nested tables
+			case atom.Tr:
+				var cells []string
+				for td := c.FirstChild; td != nil; td = td.NextSibling {
+					if td.Type != html.ElementNode || (td.DataAtom != atom.Td && td.DataAtom != atom.Th) {
+						continue
+					}
+					text := nodeText(td)
+					cells = append(cells, text)
+					for i := 1; i < colspan(td); i++ {
+						cells = append(cells, text)
+					}
+				}
+				if len(cells) == 0 {
+					continue
+				}
+				rows = append(rows, cells)
+			default:
+				collect(c) // thead, tbody, tfoot
+			}
+		}
+	}
+	collect(n)
+
+	if len(rows) < minTableRows {
+		return nil
+	}
+
+	// The first row holds the headers, whether marked up with <th> or not
+	t := &Table{Format: FormatHTML, Sheet: label, Headers: rows[0]}
+	for i, cells := range rows[1:] {
+		t.Rows = append(t.Rows, Row{Number: i + 2, Cells: cells})
+	}
+	return t
+}
+
+// colspan returns the colspan attribute of a cell (1 if absent or invalid)
+func colspan(n *html.Node) int {
+	for _, a := range n.Attr {
+		if a.Key == "colspan" {
+			var span int
+			if _, err := fmt.Sscanf(a.Val, "%d", &span); err == nil && span > 1 && span <= 50 {
+				return span
+			}
+		}
+	}
+	return 1
+}
+
+// NodeText returns the whitespace-collapsed text content of a node, as it
+// appears in PageText
+func NodeText(n *html.Node) string {
+	return nodeText(n)
+}
+
+// nodeText returns the whitespace-collapsed text content of a node
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style):
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Render formats the table as labelled rows for inclusion in an LLM prompt.
+// Each line carries the row number so extracted values can be traced back.
+func (t *Table) Render() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] columns: %s\n", t.Sheet, strings.Join(t.Headers, " | "))
+	for _, row := range t.Rows {
+		fmt.Fprintf(&b, "row %d:", row.Number)
+		for i, cell := range row.Cells {
+			if i >= len(t.Headers) || strings.TrimSpace(cell) == "" {
+				continue
+			}
+			fmt.Fprintf(&b, " %s=%s;", t.Headers[i], cell)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// PageText returns the visible text of an HTML page with tags, scripts, and
+// styles removed. Non-HTML content is returned unchanged.
+func PageText(body []byte) string {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return string(body)
+	}
+	return nodeText(doc)
+}
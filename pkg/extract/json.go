@@ -0,0 +1,125 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// parseJSON parses a JSON document containing an array of records. The array
+// may be the document itself, nested one level inside an array (World Bank
+// style [meta, records]), or the first array-valued field of an object.
+func parseJSON(body []byte) (*Table, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	records := findRecords(doc)
+	if records == nil {
+		return nil, fmt.Errorf("JSON document contains no array of records")
+	}
+
+	table := &Table{Format: FormatJSON}
+	index := make(map[string]int)
+	flat := make([]map[string]string, 0, len(records))
+
+	for _, rec := range records {
+		fields := make(map[string]string)
+		flatten("", rec, fields)
+
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := index[k]; !ok {
+				index[k] = len(table.Headers)
+				table.Headers = append(table.Headers, k)
+			}
+		}
+		flat = append(flat, fields)
+	}
+
+	for i, fields := range flat {
+		cells := make([]string, len(table.Headers))
+		for k, v := range fields {
+			cells[index[k]] = v
+		}
+		table.Rows = append(table.Rows, Row{Number: i + 1, Cells: cells})
+	}
+
+	return table, nil
+}
+
+// findRecords locates the first array of objects in a decoded JSON document
+func findRecords(doc any) []map[string]any {
+	switch v := doc.(type) {
+	case []any:
+		if recs := asRecords(v); recs != nil {
+			return recs
+		}
+		for _, item := range v {
+			if arr, ok := item.([]any); ok {
+				if recs := asRecords(arr); recs != nil {
+					return recs
+				}
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if arr, ok := v[k].([]any); ok {
+				if recs := asRecords(arr); recs != nil {
+					return recs
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// asRecords returns the array as records if every element is an object
+func asRecords(arr []any) []map[string]any {
+	if len(arr) == 0 {
+		return nil
+	}
+	recs := make([]map[string]any, 0, len(arr))
+	for _, item := range arr {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil
+		}
+		recs = append(recs, obj)
+	}
+	return recs
+}
+
+// flatten converts a record into dotted-key scalar fields, one level deep
+func flatten(prefix string, obj map[string]any, out map[string]string) {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case string:
+			out[key] = val
+		case json.Number:
+			out[key] = val.String()
+		case map[string]any:
+			if prefix == "" {
+				flatten(key, val, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package extract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// pptxPresentation lists the slides of a presentation in showing order
+type pptxPresentation struct {
+	Slides []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sldIdLst>sldId"`
+}
+
+// pptxNotesSlide is the relationship type linking a slide to its notes
+const pptxNotesSlide = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide"
+
+// slideFurniture are the placeholder types of slide and notes shapes that
+// hold slide numbers, dates, footers, and thumbnails rather than content
+var slideFurniture = map[string]bool{"sldNum": true, "dt": true, "ftr": true, "hdr": true, "sldImg": true}
+
+// readDOCX reads the text of a Word document's body, split into pages, and
+// its tables
+func readDOCX(body []byte) (*Document, error) {
+	files, err := zipFiles(FormatDOCX, body)
+	if err != nil {
+		return nil, err
+	}
+	r := &officeReader{format: FormatDOCX}
+	if err := r.read(files, "word/document.xml"); err != nil {
+		return nil, err
+	}
+	return &Document{Pages: r.pages, Tables: r.tables}, nil
+}
+
+// readPPTX reads each slide of a presentation as a page, with its speaker
+// notes after the slide text, and the tables of every slide
+func readPPTX(body []byte) (*Document, error) {
+	files, err := zipFiles(FormatPPTX, body)
+	if err != nil {
+		return nil, err
+	}
+	var pres pptxPresentation
+	if err := readXMLPart(files, FormatPPTX, "ppt/presentation.xml", &pres); err != nil {
+		return nil, err
+	}
+	rels, err := partRelationships(files, "ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	for i, s := range pres.Slides {
+		// A missing slide keeps its page, so later pages keep their numbers
+		target, ok := rels[s.RID]
+		if !ok {
+			doc.Pages = append(doc.Pages, "")
+			continue
+		}
+		slide := &officeReader{format: FormatPPTX, label: fmt.Sprintf("slide %d ", i+1)}
+		if err := slide.read(files, target.Target); err != nil {
+			return nil, err
+		}
+		page := strings.Join(slide.pages, "")
+
+		slideRels, err := partRelationships(files, target.Target)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range slideRels {
+			if rel.Type != pptxNotesSlide {
+				continue
+			}
+			notes := &officeReader{format: FormatPPTX}
+			if err := notes.read(files, rel.Target); err != nil {
+				return nil, err
+			}
+			if text := strings.TrimSpace(strings.Join(notes.pages, "")); text != "" {
+				page += "\nNotes:\n" + text + "\n"
+			}
+		}
+
+		doc.Pages = append(doc.Pages, page)
+		doc.Tables = append(doc.Tables, slide.tables...)
+	}
+	return doc, nil
+}
+
+// relationship is a link from one part of an Office archive to another
+type relationship struct {
+	Type   string
+	Target string // Part name within the archive
+}
+
+// partRelationships returns the relationships of an archive part by ID, with
+// their targets resolved to part names. A part with no relationships part
+// has none.
+func partRelationships(files map[string]*zip.File, part string) (map[string]relationship, error) {
+	name := path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+	if _, ok := files[name]; !ok {
+		return nil, nil
+	}
+	var rels xlsxRelationships
+	if err := readXMLPart(files, FormatPPTX, name, &rels); err != nil {
+		return nil, err
+	}
+	out := make(map[string]relationship, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join(path.Dir(part), target)
+		}
+		out[rel.ID] = relationship{Type: rel.Type, Target: target}
+	}
+	return out, nil
+}
+
+// officeReader reads the text and tables of a Word document body, a slide,
+// or a notes page, whose markup shares its element names: paragraphs (p) of
+// text runs (t), and tables (tbl) of rows (tr) of cells (tc). Table text is
+// kept in the page text as well, a tab after each cell.
+type officeReader struct {
+	format Format
+	label  string // Prefix of table labels, such as "slide 2 "
+
+	pages  []string
+	page   strings.Builder
+	tables []*Table
+	count  int // Tables seen, numbering the labels
+
+	depth int // Table nesting; a nested table is read as its cell's text
+	rows  [][]string
+	cell  strings.Builder
+	span  int // Grid columns spanned by the current cell
+
+	inText bool
+	skip   bool // In a shape holding a slide number, date, or footer
+}
+
+// read reads one XML part, ending the current page after it
+func (r *officeReader) read(files map[string]*zip.File, name string) error {
+	rc, err := openPart(files, r.format, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	dec := xml.NewDecoder(io.LimitReader(rc, maxXLSXPartSize))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			r.start(t)
+		case xml.EndElement:
+			r.end(t.Name.Local)
+		case xml.CharData:
+			if r.inText {
+				r.write(string(t))
+			}
+		}
+	}
+	r.pages = append(r.pages, r.page.String())
+	r.page.Reset()
+	return nil
+}
+
+func (r *officeReader) start(e xml.StartElement) {
+	switch e.Name.Local {
+	case "t":
+		r.inText = true
+	case "tab":
+		// Tab stops in paragraph properties are also tab elements
+		if attr(e, "pos") == "" {
+			r.write("\t")
+		}
+	case "br":
+		if attr(e, "type") == "page" {
+			r.breakPage()
+		} else {
+			r.write("\n")
+		}
+	case "lastRenderedPageBreak":
+		r.breakPage()
+	case "ph":
+		r.skip = slideFurniture[attr(e, "type")]
+	case "tbl":
+		r.depth++
+		if r.depth == 1 {
+			r.rows = nil
+		}
+	case "tr":
+		if r.depth == 1 {
+			r.rows = append(r.rows, nil)
+		}
+	case "tc":
+		if r.depth == 1 {
+			r.cell.Reset()
+			r.span = 1
+		}
+	case "gridSpan":
+		// Word gives a merged cell its span; PowerPoint keeps a cell for
+		// each spanned column instead
+		if n, err := strconv.Atoi(attr(e, "val")); err == nil && r.depth == 1 && n > 1 && n <= 50 {
+			r.span = n
+		}
+	}
+}
+
+func (r *officeReader) end(name string) {
+	switch name {
+	case "t":
+		r.inText = false
+	case "p":
+		r.write("\n")
+	case "tc":
+		if r.depth == 1 && len(r.rows) > 0 {
+			text := strings.Join(strings.Fields(r.cell.String()), " ")
+			row := &r.rows[len(r.rows)-1]
+			for range r.span {
+				*row = append(*row, text)
+			}
+		}
+		r.write("\t")
+	case "tbl":
+		if r.depth == 1 {
+			r.addTable()
+		}
+		r.depth = max(r.depth-1, 0)
+	case "sp":
+		r.skip = false
+	}
+}
+
+// write adds text to the page, and to the current cell within a table
+func (r *officeReader) write(s string) {
+	if r.skip {
+		return
+	}
+	r.page.WriteString(s)
+	if r.depth > 0 {
+		r.cell.WriteString(s)
+	}
+}
+
+// breakPage starts a new page, unless the current one is still empty
+func (r *officeReader) breakPage() {
+	// A rendered break right after an explicit one is the same break
+	if strings.TrimSpace(r.page.String()) != "" {
+		r.pages = append(r.pages, r.page.String())
+		r.page.Reset()
+	}
+}
+
+// addTable keeps the table just read when it has data rows. Like HTML
+// tables, the first row holds the headers and rows are numbered by their
+// position in the table, header row included.
+func (r *officeReader) addTable() {
+	r.count++
+	var rows [][]string
+	for _, cells := range r.rows {
+		if !isBlank(cells) {
+			rows = append(rows, cells)
+		}
+	}
+	if len(rows) < minTableRows {
+		return
+	}
+	t := &Table{Format: r.format, Sheet: fmt.Sprintf("%stable %d", r.label, r.count), Headers: rows[0]}
+	for i, cells := range rows[1:] {
+		t.Rows = append(t.Rows, Row{Number: i + 2, Cells: cells})
+	}
+	r.tables = append(r.tables, t)
+}
+
+// attr returns the value of an element's attribute, in any namespace
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
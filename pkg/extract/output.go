@@ -0,0 +1,273 @@
+package extract
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Bounds of a usable excerpt from LLM output. A shorter one ("27%") matches
+// too much of a page to verify; a longer one is cut to the text around its
+// value.
+const (
+	MinExcerptLen = 8
+	MaxExcerptLen = 500
+)
+
+// Entries splits an LLM's JSON array into its entries, unwrapping markdown
+// code fences and surrounding prose, so each entry can be decoded and
+// validated on its own and a malformed one costs only itself. A lone object
+// is an array of one. It fails only when the response holds no JSON array
+// or object.
+func Entries(response string) ([]json.RawMessage, error) {
+	var entries []json.RawMessage
+	err := json.Unmarshal([]byte(response), &entries)
+	if err == nil {
+		return entries, nil
+	}
+	if start, end := strings.Index(response, "["), strings.LastIndex(response, "]"); start >= 0 && end > start {
+		if json.Unmarshal([]byte(response[start:end+1]), &entries) == nil {
+			return entries, nil
+		}
+	}
+	if start, end := strings.Index(response, "{"), strings.LastIndex(response, "}"); start >= 0 && end > start {
+		obj := []byte(response[start : end+1])
+		if json.Valid(obj) && bytes.HasPrefix(obj, []byte("{")) {
+			return []json.RawMessage{obj}, nil
+		}
+	}
+	return nil, err
+}
+
+// Quantity is a statistic value in LLM output. It accepts a JSON number or
+// a string such as "1,234", "75%", "$2.1 billion", or "1.5°C", keeping a
+// unit written into the value apart so it is not lost.
+type Quantity struct {
+	Value float64
+	Unit  string // Unit written into a string value, e.g. "%" or "billion USD"
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if err := json.Unmarshal(data, &q.Value); err == nil {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("value %s is not a number", data)
+	}
+	parsed, ok := ParseQuantity(s)
+	if !ok {
+		return fmt.Errorf("value %q is not a number", s)
+	}
+	*q = parsed
+	return nil
+}
+
+var (
+	// quantityPattern splits a value string into currency, number, and unit
+	quantityPattern = regexp.MustCompile(`^(US\$|[$€£¥]|USD|EUR|GBP)?\s*([-+]?(?:\d[\d,]*(?:\.\d+)?|\.\d+))\s*(.*)$`)
+	// rangeSuffix matches the rest of a range such as "1.2-1.5" or "12 to 15",
+	// which is no single value
+	rangeSuffix = regexp.MustCompile(`^(?:[-–—]|to\s)\s*\d`)
+)
+
+// currencies maps currency symbols to codes
+var currencies = map[string]string{"$": "USD", "US$": "USD", "€": "EUR", "£": "GBP", "¥": "JPY"}
+
+// ParseQuantity parses a value string with an optional currency and unit,
+// such as "$2.1 billion" (2.1, "billion USD"). Ranges and text without a
+// leading number do not parse.
+func ParseQuantity(s string) (Quantity, bool) {
+	m := quantityPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || rangeSuffix.MatchString(m[3]) {
+		return Quantity{}, false
+	}
+	v, ok := ParseNumber(m[2])
+	if !ok {
+		return Quantity{}, false
+	}
+
+	unit := strings.TrimSpace(m[3])
+	if code, ok := currencies[m[1]]; ok {
+		unit = strings.TrimSpace(unit + " " + code)
+	} else if m[1] != "" {
+		unit = strings.TrimSpace(unit + " " + m[1])
+	}
+	return Quantity{Value: v, Unit: unit}, true
+}
+
+// MergeUnit returns the unit of a statistic whose value is q and whose unit
+// field is unit. A unit written into the value fills an empty unit field,
+// and its magnitude or currency is kept when the field leaves it out:
+// "$2.1 billion" with unit "USD" is 2.1 "billion USD", not 2.1 USD. It
+// fails when the two name different magnitudes or currencies.
+func (q Quantity) MergeUnit(unit string) (string, error) {
+	unit = strings.TrimSpace(unit)
+	if q.Unit == "" || strings.EqualFold(unit, q.Unit) {
+		return unit, nil
+	}
+	if unit == "" {
+		return q.Unit, nil
+	}
+
+	valueMag, valueRest := splitMagnitude(q.Unit)
+	unitMag, unitRest := splitMagnitude(unit)
+	if valueMag != "" && unitMag != "" && valueMag != unitMag {
+		return "", fmt.Errorf("value %v %s conflicts with unit %q", q.Value, q.Unit, unit)
+	}
+	if isCurrency(valueRest) && isCurrency(unitRest) && !strings.EqualFold(valueRest, unitRest) {
+		return "", fmt.Errorf("value %v %s conflicts with unit %q", q.Value, q.Unit, unit)
+	}
+	if (valueMag == "" || unitMag != "") && (unitRest != "" || valueRest == "") {
+		return unit, nil
+	}
+	return strings.TrimSpace(cmp.Or(unitMag, valueMag) + " " + cmp.Or(unitRest, valueRest)), nil
+}
+
+// splitMagnitude splits the magnitude word out of a unit such as
+// "billion USD", returning it in lower case and the rest of the unit
+func splitMagnitude(unit string) (magnitude, rest string) {
+	var words []string
+	for _, word := range strings.Fields(unit) {
+		if _, ok := Magnitudes[strings.ToLower(word)]; ok && magnitude == "" {
+			magnitude = strings.ToLower(word)
+			continue
+		}
+		words = append(words, word)
+	}
+	return magnitude, strings.Join(words, " ")
+}
+
+// isCurrency reports whether s is a currency code ParseQuantity writes
+func isCurrency(s string) bool {
+	for _, code := range currencies {
+		if strings.EqualFold(s, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// numberPattern matches a number as written in text
+var numberPattern = regexp.MustCompile(`\d[\d,]*(?:\.\d+)?`)
+
+// Excerpt checks the length of an excerpt, cutting one over MaxExcerptLen
+// to the words around value. It fails when the excerpt is too short, or too
+// long and does not show value.
+func Excerpt(excerpt string, value float64) (string, error) {
+	excerpt = strings.TrimSpace(excerpt)
+	switch {
+	case excerpt == "":
+		return "", errors.New("missing excerpt")
+	case len(excerpt) < MinExcerptLen:
+		return "", fmt.Errorf("excerpt %q is too short", excerpt)
+	case len(excerpt) <= MaxExcerptLen:
+		return excerpt, nil
+	}
+
+	for _, loc := range numberPattern.FindAllStringIndex(excerpt, -1) {
+		if v, ok := ParseNumber(excerpt[loc[0]:loc[1]]); !ok || float32(v) != float32(value) {
+			continue
+		}
+		start := max(0, (loc[0]+loc[1]-MaxExcerptLen)/2)
+		end := min(len(excerpt), start+MaxExcerptLen)
+		start = max(0, end-MaxExcerptLen)
+		// Cut at spaces so no word is split, or at least no character
+		if start > 0 {
+			if i := strings.IndexByte(excerpt[start:loc[0]], ' '); i >= 0 {
+				start += i + 1
+			}
+			for !utf8.RuneStart(excerpt[start]) {
+				start++
+			}
+		}
+		if end < len(excerpt) {
+			if i := strings.LastIndexByte(excerpt[loc[1]:end], ' '); i >= 0 {
+				end = loc[1] + i
+			}
+			for !utf8.RuneStart(excerpt[end]) {
+				end--
+			}
+		}
+		return excerpt[start:end], nil
+	}
+	return "", fmt.Errorf("excerpt of %d characters is too long and does not show the value", len(excerpt))
+}
+
+// valuePattern matches a number with optional thousands separators and a
+// following magnitude word
+var valuePattern = regexp.MustCompile(`(\d[\d,]*(?:\.\d+)?)\s*(thousand|million|billion|trillion)?`)
+
+// Magnitudes scales numbers written with a magnitude word, keyed by the
+// lowercased word
+var Magnitudes = map[string]float64{
+	"thousand": 1e3,
+	"million":  1e6,
+	"billion":  1e9,
+	"trillion": 1e12,
+}
+
+// ValueAppears reports whether value is stated anywhere in text, either as
+// written or scaled by a magnitude word ("1.5 million" for 1500000). Signs
+// are ignored, since a fall of 2% is often written "fell 2%".
+func ValueAppears(text string, value float64) bool {
+	value = math.Abs(value)
+	for _, m := range valuePattern.FindAllStringSubmatch(strings.ToLower(text), -1) {
+		n, ok := ParseNumber(m[1])
+		if !ok {
+			continue
+		}
+		if SameNumber(n, value) {
+			return true
+		}
+		if scale, ok := Magnitudes[m[2]]; ok && SameNumber(n*scale, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// SameNumber compares numbers allowing for float32 rounding of stored values
+func SameNumber(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-6*math.Max(math.Abs(a), math.Abs(b))+1e-9
+}
+
+// markdownLink matches a markdown link, whose target is the URL
+var markdownLink = regexp.MustCompile(`^\[[^\]]*\]\((\S+)\)$`)
+
+// SourceURL checks that a source URL from LLM output is an absolute http(s)
+// URL, repairing common slips: a markdown link, angle brackets, trailing
+// punctuation, or a missing scheme
+func SourceURL(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if m := markdownLink.FindStringSubmatch(s); m != nil {
+		s = m[1]
+	}
+	s = strings.TrimLeft(s, "(<")
+	s = strings.TrimRight(s, ".,;>")
+	// A closing parenthesis belongs to the URL only when it is balanced, as
+	// in Wikipedia titles
+	for strings.HasSuffix(s, ")") && strings.Count(s, ")") > strings.Count(s, "(") {
+		s = strings.TrimSuffix(s, ")")
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.Contains(u.Hostname(), ".") || strings.ContainsAny(u.Host, " ") {
+		return "", fmt.Errorf("source URL %q is not a web address", raw)
+	}
+	return u.String(), nil
+}
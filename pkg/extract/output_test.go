@@ -0,0 +1,165 @@
+package extract
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	tests := []struct {
+		response string
+		want     int
+	}{
+		{`[{"name": "a"}, {"name": "b"}]`, 2},
+		{"```json\n[{\"name\": \"a\"}]\n```", 1},
+		{`Here are the statistics: [{"name": "a"}, {"value": "about half"}] Hope this helps.`, 2},
+		{`{"name": "a"}`, 1},
+		{`[]`, 0},
+	}
+	for _, tt := range tests {
+		entries, err := Entries(tt.response)
+		if err != nil || len(entries) != tt.want {
+			t.Errorf("Entries(%q) = %d entries, %v; want %d", tt.response, len(entries), err, tt.want)
+		}
+	}
+	if _, err := Entries(`[{"name": "a",}`); err == nil {
+		t.Error("Entries() of truncated JSON should fail")
+	}
+}
+
+func TestMergeUnit(t *testing.T) {
+	tests := []struct {
+		value string
+		unit  string
+		want  string
+		ok    bool
+	}{
+		{"$2.1 billion", "USD", "billion USD", true},
+		{"$2.1 billion", "", "billion USD", true},
+		{"$2.1 billion", "billion", "billion USD", true},
+		{"$2.1 billion", "billion US dollars", "billion US dollars", true},
+		{"$2.1 billion", "dollars", "billion dollars", true},
+		{"3.5 million", "people", "million people", true},
+		{"75%", "percent", "percent", true},
+		{"1,234", "jobs", "jobs", true},
+		{"$2.1 billion", "million USD", "", false},
+		{"$2.1 billion", "EUR", "", false},
+		{"€40", "billion USD", "", false},
+	}
+	for _, tt := range tests {
+		q, ok := ParseQuantity(tt.value)
+		if !ok {
+			t.Fatalf("ParseQuantity(%q) failed", tt.value)
+		}
+		got, err := q.MergeUnit(tt.unit)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("MergeUnit(%q, %q) = %q, %v; want %q, ok %v", tt.value, tt.unit, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+		ok   bool
+	}{
+		{"75%", Quantity{75, "%"}, true},
+		{"1,234", Quantity{1234, ""}, true},
+		{"3.5 million", Quantity{3.5, "million"}, true},
+		{"$2.1 billion", Quantity{2.1, "billion USD"}, true},
+		{"€40", Quantity{40, "EUR"}, true},
+		{"1.5°C", Quantity{1.5, "°C"}, true},
+		{"-0.3 percent", Quantity{-0.3, "percent"}, true},
+		{"1.2-1.5", Quantity{}, false},
+		{"12 to 15 percent", Quantity{}, false},
+		{"about half", Quantity{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseQuantity(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseQuantity(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	var q Quantity
+	if err := json.Unmarshal([]byte(`"61% of workers"`), &q); err == nil {
+		if q.Value != 61 || q.Unit != "% of workers" {
+			t.Errorf("Unmarshal = %+v", q)
+		}
+	}
+	if err := json.Unmarshal([]byte(`"n/a"`), &q); err == nil {
+		t.Error("Unmarshal of a non-numeric string should fail")
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got, err := Excerpt("  61% of remote workers prefer hybrid  ", 61); err != nil || got != "61% of remote workers prefer hybrid" {
+		t.Errorf("Excerpt() = %q, %v", got, err)
+	}
+	if _, err := Excerpt("61%", 61); err == nil {
+		t.Error("Excerpt() of a bare number should fail")
+	}
+
+	long := strings.Repeat("Background text without the figure. ", 20) +
+		"Unemployment fell to 3,912 claims in March. " +
+		strings.Repeat("More text that follows the figure. ", 20)
+	got, err := Excerpt(long, 3912)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) > MaxExcerptLen || !strings.Contains(got, "fell to 3,912 claims") || !strings.Contains(long, got) {
+		t.Errorf("Excerpt() of a long excerpt = %q", got)
+	}
+	if strings.HasPrefix(got, " ") || strings.HasSuffix(got, " ") {
+		t.Errorf("Excerpt() = %q, want it cut between words", got)
+	}
+	if _, err := Excerpt(long, 42); err == nil {
+		t.Error("Excerpt() of a long excerpt without the value should fail")
+	}
+}
+
+func TestSourceURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://www.bls.gov/cps/", "https://www.bls.gov/cps/"},
+		{"www.who.int/data", "https://www.who.int/data"},
+		{"[BLS](https://www.bls.gov/cps/)", "https://www.bls.gov/cps/"},
+		{"<https://ourworldindata.org/co2>.", "https://ourworldindata.org/co2"},
+		{"(https://en.wikipedia.org/wiki/Mercury_(planet))", "https://en.wikipedia.org/wiki/Mercury_(planet)"},
+	}
+	for _, tt := range tests {
+		if got, err := SourceURL(tt.in); err != nil || got != tt.want {
+			t.Errorf("SourceURL(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "N/A", "the BLS website", "ftp://example.com/data"} {
+		if _, err := SourceURL(bad); err == nil {
+			t.Errorf("SourceURL(%q) should fail", bad)
+		}
+	}
+}
+
+func TestValueAppears(t *testing.T) {
+	tests := []struct {
+		text  string
+		value float64
+		want  bool
+	}{
+		{"61% of remote workers prefer hybrid", 61, true},
+		{"a population of 1,234,567 people", 1234567, true},
+		{"revenue reached $1.5 billion in 2023", 1.5e9, true},
+		{"revenue reached $1.5 billion in 2023", 1.5, true},
+		{"prices fell 0.3 percent in May", -0.3, true},
+		{"three quarters of respondents agreed", 75, false},
+		{"61% of remote workers prefer hybrid", 16, false},
+		{"about 2.5 million cases", 25, false},
+	}
+	for _, tt := range tests {
+		if got := ValueAppears(tt.text, tt.value); got != tt.want {
+			t.Errorf("ValueAppears(%q, %v) = %v, want %v", tt.text, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package extract
+
+import (
+	"context"
+	"unicode/utf8"
+)
+
+// MaxRepairInputLen bounds, in bytes, the malformed output echoed back to
+// the LLM when asking it to repair that output
+const MaxRepairInputLen = 20000
+
+// Repair parses an LLM response with parse and, while parsing fails, asks
+// fix for a corrected response, up to attempts times. fix gets the last
+// response, cut to MaxRepairInputLen bytes on a rune boundary, and its
+// parse error. Repair returns the last response and its parse error, or
+// the error of a failed fix.
+func Repair(ctx context.Context, response string, attempts int, parse func(string) error, fix func(ctx context.Context, malformed string, parseErr error) (string, error)) (string, error) {
+	err := parse(response)
+	for attempt := 1; err != nil && attempt <= attempts; attempt++ {
+		repaired, fixErr := fix(ctx, Truncate(response, MaxRepairInputLen), err)
+		if fixErr != nil {
+			return response, fixErr
+		}
+		response = repaired
+		err = parse(response)
+	}
+	return response, err
+}
+
+// Truncate cuts s to at most n bytes without splitting a UTF-8 character
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,96 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRepair(t *testing.T) {
+	errFix := errors.New("provider down")
+	tests := []struct {
+		name     string
+		response string
+		fixes    []string // Responses of successive fixes
+		fixErr   error
+		attempts int
+		want     string
+		wantErr  bool
+		wantFix  int // Number of fix calls
+	}{
+		{name: "valid", response: `[1]`, attempts: 2, want: `[1]`},
+		{name: "repaired", response: `[1,`, fixes: []string{`[1]`}, attempts: 2, want: `[1]`, wantFix: 1},
+		{name: "repaired on second attempt", response: `[1,`, fixes: []string{`[1,,`, `[1]`}, attempts: 2, want: `[1]`, wantFix: 2},
+		{name: "attempts exhausted", response: `[1,`, fixes: []string{`[1,,`, `[1,,,`}, attempts: 2, want: `[1,,,`, wantErr: true, wantFix: 2},
+		{name: "repair disabled", response: `[1,`, attempts: 0, want: `[1,`, wantErr: true},
+		{name: "fix fails", response: `[1,`, fixErr: errFix, attempts: 2, want: `[1,`, wantErr: true, wantFix: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parse := func(s string) error {
+				_, err := Entries(s)
+				return err
+			}
+			calls := 0
+			fix := func(_ context.Context, malformed string, parseErr error) (string, error) {
+				calls++
+				if parseErr == nil {
+					t.Error("fix called without a parse error")
+				}
+				if tt.fixErr != nil {
+					return "", tt.fixErr
+				}
+				return tt.fixes[calls-1], nil
+			}
+			got, err := Repair(context.Background(), tt.response, tt.attempts, parse, fix)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("Repair() = %q, %v; want %q, error %t", got, err, tt.want, tt.wantErr)
+			}
+			if tt.fixErr != nil && !errors.Is(err, tt.fixErr) {
+				t.Errorf("Repair() error = %v, want %v", err, tt.fixErr)
+			}
+			if calls != tt.wantFix {
+				t.Errorf("fix called %d times, want %d", calls, tt.wantFix)
+			}
+		})
+	}
+}
+
+func TestRepairTruncatesOnRuneBoundary(t *testing.T) {
+	// A two-byte character straddles the limit
+	response := strings.Repeat("a", MaxRepairInputLen-1) + "é" + "tail"
+	var malformed string
+	_, _ = Repair(context.Background(), response, 1,
+		func(string) error { return errors.New("not JSON") },
+		func(_ context.Context, s string, _ error) (string, error) {
+			malformed = s
+			return "", nil
+		})
+	if len(malformed) != MaxRepairInputLen-1 || !utf8.ValidString(malformed) {
+		t.Errorf("malformed output cut to %d bytes (valid UTF-8: %t), want %d", len(malformed), utf8.ValidString(malformed), MaxRepairInputLen-1)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exact", 5, "exact"},
+		{"ascii text", 5, "ascii"},
+		{"naïve", 3, "na"},  // ï is two bytes at 2-3
+		{"naïve", 4, "naï"}, // the limit falls after ï
+		{"日本語", 4, "日"},     // three-byte characters
+		{"日本語", 2, ""},
+		{"", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
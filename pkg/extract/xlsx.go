@@ -0,0 +1,219 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxXLSXPartSize limits the decompressed size of a single workbook part
+const maxXLSXPartSize = 32 * 1024 * 1024
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxRichText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xlsxRichText) text() string {
+	if len(rt.R) == 0 {
+		return rt.T
+	}
+	var b strings.Builder
+	b.WriteString(rt.T)
+	for _, r := range rt.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// parseXLSX parses every worksheet of an XLSX workbook into a table,
+// treating the first non-empty row of each sheet as its header row.
+func parseXLSX(body []byte) ([]*Table, error) {
+	files, err := zipFiles(FormatXLSX, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var wb xlsxWorkbook
+	if err := readXMLPart(files, FormatXLSX, "xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	var rels xlsxRelationships
+	if err := readXMLPart(files, FormatXLSX, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := readXMLPart(files, FormatXLSX, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := strings.TrimPrefix(rel.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	tables := make([]*Table, 0, len(wb.Sheets))
+	for _, sheet := range wb.Sheets {
+		target, ok := targets[sheet.RID]
+		if !ok {
+			continue
+		}
+		var ws xlsxWorksheet
+		if err := readXMLPart(files, FormatXLSX, target, &ws); err != nil {
+			return nil, err
+		}
+
+		table := &Table{Format: FormatXLSX, Sheet: sheet.Name}
+		for _, row := range ws.Rows {
+			var cells []string
+			for i, c := range row.Cells {
+				col := i
+				if idx := columnIndex(c.Ref); idx >= 0 {
+					col = idx
+				}
+				for len(cells) <= col {
+					cells = append(cells, "")
+				}
+				cells[col] = cellText(c.Type, c.Value, c.Inline, shared.Items)
+			}
+			if isBlank(cells) {
+				continue
+			}
+			if table.Headers == nil {
+				table.Headers = trimAll(cells)
+				continue
+			}
+			table.Rows = append(table.Rows, Row{Number: row.R, Cells: cells})
+		}
+		if table.Headers != nil {
+			tables = append(tables, table)
+		}
+	}
+
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("XLSX workbook contains no data")
+	}
+	return tables, nil
+}
+
+// readXMLPart decodes a single XML part of an Office archive
+func readXMLPart(files map[string]*zip.File, format Format, name string, v any) error {
+	rc, err := openPart(files, format, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(rc, maxXLSXPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// openPart opens a single part of an Office archive
+func openPart(files map[string]*zip.File, format Format, name string) (io.ReadCloser, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s archive missing %s", strings.ToUpper(string(format)), name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	return rc, nil
+}
+
+// zipFiles opens an Office archive, indexing its parts by name
+func zipFiles(format Format, body []byte) (map[string]*zip.File, error) {
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s archive: %w", strings.ToUpper(string(format)), err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	return files, nil
+}
+
+// cellText resolves the display text of a worksheet cell
+func cellText(cellType, value string, inline xlsxRichText, shared []xlsxRichText) string {
+	switch cellType {
+	case "s":
+		idx, err := strconv.Atoi(value)
+		if err != nil || idx < 0 || idx >= len(shared) {
+			return ""
+		}
+		return shared[idx].text()
+	case "inlineStr":
+		return inline.text()
+	default:
+		return value
+	}
+}
+
+// columnIndex converts a cell reference such as "AB12" to a 0-based column index
+func columnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+	}
+	return col - 1
+}
+
+// isBlank reports whether all cells are empty
+func isBlank(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+// Package figures finds the charts and infographics on an HTML page, whose
+// numbers never appear in the page text. A vision model reads the images
+// this package selects; the rest of a page's images (logos, icons, photos of
+// people, tracking pixels) are skipped to keep that step cheap.
+package figures
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// MaxBytes bounds the size of an image sent to the vision model
+const MaxBytes = 4 << 20
+
+// minSide is the smallest declared width or height of a chart
+const minSide = 150
+
+// Figure is an image on a page that may hold statistics
+type Figure struct {
+	URL     string // Absolute image URL
+	Alt     string // The image's alt text
+	Caption string // Its <figcaption>, when in a <figure>
+}
+
+var (
+	// chartWords in alt text, captions, or file names suggest a figure with numbers
+	chartWords = regexp.MustCompile(`(?i)\b(chart|graph|plot|infographic|figure|fig\.?|diagram|statistics?|data|trend|survey|percent|share|growth|rate|map)\b`)
+	// decorative images never hold statistics
+	decorative = regexp.MustCompile(`(?i)(logo|icon|avatar|sprite|badge|banner|button|pixel|spacer|emoji|author|headshot|profile|thumb)`)
+)
+
+// imageTypes are the formats a vision model reads
+var imageTypes = []string{"image/png", "image/jpeg", "image/webp", "image/gif"}
+
+// Find returns up to limit figures on the page at pageURL, most likely charts
+// first: images in a <figure> or with chart words in their alt text or
+// caption, then other large images. SVG and data: images are skipped.
+func Find(body []byte, pageURL string, limit int) []Figure {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil || limit <= 0 {
+		return nil
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	type scored struct {
+		fig   Figure
+		score int
+	}
+	var found []scored
+	seen := make(map[string]bool)
+
+	var walk func(n *html.Node, caption string)
+	walk = func(n *html.Node, caption string) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Figure:
+				caption = text(findChild(n, atom.Figcaption))
+				if caption == "" {
+					caption = " " // Inside a figure, even without a caption
+				}
+			case atom.Img:
+				if fig, score, ok := candidate(n, base, caption); ok && !seen[fig.URL] {
+					seen[fig.URL] = true
+					found = append(found, scored{fig, score})
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, caption)
+		}
+	}
+	walk(doc, "")
+
+	slices.SortStableFunc(found, func(a, b scored) int { return b.score - a.score })
+	figs := make([]Figure, 0, min(limit, len(found)))
+	for _, s := range found[:min(limit, len(found))] {
+		figs = append(figs, s.fig)
+	}
+	return figs
+}
+
+// candidate scores an <img>, rejecting decorative, tiny, and vector images
+func candidate(n *html.Node, base *url.URL, caption string) (Figure, int, bool) {
+	src := attr(n, "src")
+	if src == "" {
+		src = attr(n, "data-src") // Lazy-loaded images
+	}
+	if src == "" || strings.HasPrefix(src, "data:") {
+		return Figure{}, 0, false
+	}
+	ref, err := url.Parse(strings.TrimSpace(src))
+	if err != nil {
+		return Figure{}, 0, false
+	}
+	abs := base.ResolveReference(ref)
+	if abs.Scheme != "http" && abs.Scheme != "https" || strings.HasSuffix(strings.ToLower(abs.Path), ".svg") {
+		return Figure{}, 0, false
+	}
+
+	alt := strings.TrimSpace(attr(n, "alt"))
+	if decorative.MatchString(abs.Path + " " + attr(n, "class") + " " + attr(n, "id") + " " + alt) {
+		return Figure{}, 0, false
+	}
+	if small(attr(n, "width")) || small(attr(n, "height")) {
+		return Figure{}, 0, false
+	}
+
+	fig := Figure{URL: abs.String(), Alt: alt, Caption: strings.TrimSpace(caption)}
+	score := 0
+	if caption != "" {
+		score += 2
+	}
+	if chartWords.MatchString(alt + " " + fig.Caption + " " + abs.Path) {
+		score += 3
+	}
+	return fig, score, true
+}
+
+// small reports whether a declared dimension is too small for a chart
+func small(dim string) bool {
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(dim), "px"))
+	return err == nil && n < minSide
+}
+
+// IsImage reports whether a content type is an image format vision models
+// read, returning its media type
+func IsImage(contentType string) (string, bool) {
+	mediaType, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(contentType)), ";")
+	return mediaType, slices.Contains(imageTypes, strings.TrimSpace(mediaType))
+}
+
+// OnPage reports whether the page still shows the image at imageURL
+func OnPage(body []byte, pageURL, imageURL string) bool {
+	return slices.ContainsFunc(Find(body, pageURL, 1000), func(f Figure) bool { return f.URL == imageURL })
+}
+
+func findChild(n *html.Node, a atom.Atom) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			return c
+		}
+	}
+	return nil
+}
+
+// text returns the whitespace-collapsed text inside n
+func text(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
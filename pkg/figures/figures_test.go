@@ -0,0 +1,60 @@
+package figures
+
+import "testing"
+
+func TestFind(t *testing.T) {
+	page := `<html><body>
+		<img src="/static/logo.png" alt="Pew Research Center">
+		<img src="/photos/team.jpg" alt="Our researchers">
+		<img src="/icons/share.png" width="24" height="24">
+		<img src="/charts/spark.png" width="80">
+		<img src="/charts/trend.svg" alt="Chart of remote work">
+		<img src="data:image/png;base64,AAAA" alt="Chart">
+		<figure>
+			<img src="remote-share.png">
+			<figcaption>Share of workers who work remotely, 2019-2024</figcaption>
+		</figure>
+		<img data-src="https://cdn.example.org/growth.webp" alt="Graph of remote work growth">
+		<img src="/charts/trend.png" alt="Chart"><img src="/charts/trend.png" alt="Chart">
+	</body></html>`
+
+	figs := Find([]byte(page), "https://www.example.com/reports/remote.html", 10)
+	want := []Figure{
+		{URL: "https://www.example.com/reports/remote-share.png", Caption: "Share of workers who work remotely, 2019-2024"},
+		{URL: "https://cdn.example.org/growth.webp", Alt: "Graph of remote work growth"},
+		{URL: "https://www.example.com/charts/trend.png", Alt: "Chart"},
+		{URL: "https://www.example.com/photos/team.jpg", Alt: "Our researchers"},
+	}
+	if len(figs) != len(want) {
+		t.Fatalf("Find() = %+v, want %+v", figs, want)
+	}
+	for i := range want {
+		if figs[i] != want[i] {
+			t.Errorf("figure %d = %+v, want %+v", i, figs[i], want[i])
+		}
+	}
+
+	if got := Find([]byte(page), "https://www.example.com/reports/remote.html", 2); len(got) != 2 || got[0] != want[0] {
+		t.Errorf("Find(limit 2) = %+v", got)
+	}
+	if !OnPage([]byte(page), "https://www.example.com/reports/remote.html", "https://cdn.example.org/growth.webp") {
+		t.Error("OnPage() = false for a figure on the page")
+	}
+	if OnPage([]byte(page), "https://www.example.com/reports/remote.html", "https://www.example.com/static/logo.png") {
+		t.Error("OnPage() = true for a logo")
+	}
+}
+
+func TestIsImage(t *testing.T) {
+	for contentType, want := range map[string]bool{
+		"image/png":                  true,
+		"IMAGE/JPEG; charset=binary": true,
+		"image/svg+xml":              false,
+		"text/html":                  false,
+		"":                           false,
+	} {
+		if _, got := IsImage(contentType); got != want {
+			t.Errorf("IsImage(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package figures
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// Read sends prompt with an image to a vision model and returns the text of
+// its answer. The image must be a format vision models read and at most
+// MaxBytes.
+func Read(ctx context.Context, vision model.LLM, prompt, contentType string, image []byte) (string, error) {
+	mediaType, ok := IsImage(contentType)
+	if !ok {
+		return "", fmt.Errorf("not a supported image type: %q", contentType)
+	}
+	if len(image) > MaxBytes {
+		return "", fmt.Errorf("image larger than %d bytes", MaxBytes)
+	}
+
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{
+			genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromBytes(image, mediaType),
+				genai.NewPartFromText(prompt),
+			}, genai.RoleUser),
+		},
+	}
+
+	var response string
+	for resp, err := range vision.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return "", err
+		}
+		if resp.Content != nil {
+			for _, part := range resp.Content.Parts {
+				response += part.Text
+			}
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,164 @@
+// Package fingerprint hashes the visible text of source pages so later
+// fetches show whether the text around a verified statistic changed. The
+// text is normalized like excerpts are for matching, then split into
+// content-defined chunks: a chunk ends after a word whose hash has its low
+// bits clear, so inserting or editing words moves only the boundaries
+// nearby and the other chunks keep their hashes.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+const (
+	minChunkWords = 8  // Words before a chunk may end
+	maxChunkWords = 64 // Words after which a chunk always ends
+	boundaryMask  = 15 // A chunk ends after a word whose hash & boundaryMask is 0: every 16 words on average
+	anchorWords   = 6  // Words of the preceding chunk kept to link to a section
+)
+
+// Page is the fingerprinted text of one fetched source
+type Page struct {
+	url    string
+	words  []string
+	chunks []chunk
+	hash   string
+}
+
+// chunk is words [start, end) of a page
+type chunk struct {
+	start, end int
+	hash       string
+}
+
+// New fingerprints the visible text of a fetched body
+func New(sourceURL string, body []byte) *Page {
+	text := textmatch.Normalize(extract.PageText(body))
+	sum := sha256.Sum256([]byte(text))
+	p := &Page{url: sourceURL, words: strings.Fields(text), hash: "sha256:" + hex.EncodeToString(sum[:])}
+
+	start := 0
+	for i, word := range p.words {
+		n := i + 1 - start
+		if n >= maxChunkWords || (n >= minChunkWords && wordHash(word)&boundaryMask == 0) || i == len(p.words)-1 {
+			p.chunks = append(p.chunks, chunk{start: start, end: i + 1, hash: chunkHash(p.words[start : i+1])})
+			start = i + 1
+		}
+	}
+	return p
+}
+
+func wordHash(word string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(word))
+	return h.Sum32()
+}
+
+func chunkHash(words []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(words, " ")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Fingerprint returns the page's fingerprint
+func (p *Page) Fingerprint() models.SourceFingerprint {
+	fp := models.SourceFingerprint{URL: p.url, Hash: p.hash, Chunks: make([]string, len(p.chunks))}
+	for i, c := range p.chunks {
+		fp.Chunks[i] = c.hash
+	}
+	return fp
+}
+
+// Section returns the chunks holding excerpt, or nil when the normalized
+// excerpt is not in the page's text word for word, as with an excerpt
+// verified by fuzzy matching or the LLM
+func (p *Page) Section(excerpt string) *models.SourceSection {
+	want := strings.Fields(textmatch.Normalize(excerpt))
+	if len(want) == 0 {
+		return nil
+	}
+	at := -1
+	for i := 0; i+len(want) <= len(p.words); i++ {
+		if slices.Equal(p.words[i:i+len(want)], want) {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		return nil
+	}
+
+	section := &models.SourceSection{Fingerprint: p.hash}
+	for i, c := range p.chunks {
+		if c.end <= at || c.start >= at+len(want) {
+			continue
+		}
+		if len(section.Chunks) == 0 && i > 0 {
+			prev := p.chunks[i-1]
+			section.Anchor = prev.hash
+			section.AnchorText = strings.Join(p.words[max(prev.start, prev.end-anchorWords):prev.end], " ")
+		}
+		section.Chunks = append(section.Chunks, c.hash)
+	}
+	return section
+}
+
+// Changed reports whether any chunk of section is missing from a later
+// fingerprint of its source
+func Changed(section *models.SourceSection, later models.SourceFingerprint) bool {
+	if section.Fingerprint == later.Hash {
+		return false
+	}
+	for _, h := range section.Chunks {
+		if !slices.Contains(later.Chunks, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffURL links to the changed section of a source. When the chunk before
+// the section is still on the page, the link carries a text fragment of its
+// last words, and the change follows them. Otherwise it is the source URL.
+func DiffURL(sourceURL string, section *models.SourceSection, later models.SourceFingerprint) string {
+	if section.AnchorText == "" || !slices.Contains(later.Chunks, section.Anchor) {
+		return sourceURL
+	}
+	return TextLink(sourceURL, section.AnchorText)
+}
+
+// TextLink links to text on a page with a text fragment (#:~:text=), which
+// supporting browsers scroll to and highlight. Long text is matched by its
+// first and last few words.
+func TextLink(sourceURL, text string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return sourceURL
+	}
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return sourceURL
+	}
+	u.Fragment = ""
+	fragment := fragmentEscape(strings.Join(words, " "))
+	if len(words) > 2*anchorWords {
+		fragment = fragmentEscape(strings.Join(words[:anchorWords], " ")) + "," + fragmentEscape(strings.Join(words[len(words)-anchorWords:], " "))
+	}
+	return u.String() + "#:~:text=" + fragment
+}
+
+// fragmentEscape escapes text for a text fragment, including the
+// characters with meaning there that url.PathEscape leaves
+func fragmentEscape(text string) string {
+	return fragmentEscaper.Replace(url.PathEscape(text))
+}
+
+var fragmentEscaper = strings.NewReplacer("-", "%2D", ",", "%2C", "&", "%26")
@@ -0,0 +1,71 @@
+package fingerprint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// page returns an HTML page of n filler paragraphs with the statistic's
+// paragraph in the middle
+func page(n int, statistic string) []byte {
+	var b strings.Builder
+	b.WriteString("<html><body><nav>Home | Reports</nav>")
+	for i := range n {
+		if i == n/2 {
+			fmt.Fprintf(&b, "<p>%s</p>", statistic)
+		}
+		fmt.Fprintf(&b, "<p>Paragraph %d discusses energy markets, grid investment, and policy outlook number %d in some detail.</p>", i, i*7)
+	}
+	b.WriteString("</body></html>")
+	return []byte(b.String())
+}
+
+const excerpt = "Solar energy provided about 3.9% of U.S. electricity generation in 2023."
+
+func TestSectionSurvivesUnrelatedEdits(t *testing.T) {
+	before := New("https://www.eia.gov/solar", page(40, excerpt))
+	section := before.Section(excerpt)
+	if section == nil || len(section.Chunks) == 0 || section.Anchor == "" {
+		t.Fatalf("section = %+v", section)
+	}
+
+	// Markup, whitespace, and typography changes leave the fingerprint alone
+	restyled := New("https://www.eia.gov/solar", []byte(strings.ReplaceAll(string(page(40, excerpt)), "<p>", "<p class=\"lead\">\n  ")))
+	if restyled.Fingerprint().Hash != before.Fingerprint().Hash {
+		t.Error("restyling changed the fingerprint")
+	}
+
+	// An edit far from the statistic changes the page but not its section
+	edited := strings.Replace(string(page(40, excerpt)), "Paragraph 2 discusses", "Paragraph 2 now briefly discusses", 1)
+	later := New("https://www.eia.gov/solar", []byte(edited)).Fingerprint()
+	if later.Hash == section.Fingerprint {
+		t.Fatal("edit did not change the fingerprint")
+	}
+	if Changed(section, later) {
+		t.Error("edit far from the statistic changed its section")
+	}
+}
+
+func TestSectionChange(t *testing.T) {
+	section := New("https://www.eia.gov/solar", page(40, excerpt)).Section(excerpt)
+	revised := New("https://www.eia.gov/solar", page(40, strings.Replace(excerpt, "3.9%", "4.1%", 1))).Fingerprint()
+	if !Changed(section, revised) {
+		t.Fatal("revised value not detected")
+	}
+
+	link := DiffURL("https://www.eia.gov/solar#top", section, revised)
+	if !strings.HasPrefix(link, "https://www.eia.gov/solar#:~:text=") || strings.Contains(link, "#top") || strings.ContainsAny(link[len("https://www.eia.gov/solar#:~:text="):], " ,&") {
+		t.Errorf("diff URL = %s", link)
+	}
+}
+
+func TestSectionNotFound(t *testing.T) {
+	p := New("https://example.com", page(5, excerpt))
+	if s := p.Section("A sentence that is not on the page."); s != nil {
+		t.Errorf("section = %+v", s)
+	}
+	if s := p.Section(""); s != nil {
+		t.Errorf("empty excerpt section = %+v", s)
+	}
+}
@@ -0,0 +1,402 @@
+// Package graphqlapi serves one GraphQL API over an orchestrator at
+// POST /graphql: queries for its active runs, queued jobs, stored
+// statistics, and the sources they were verified on, and a startRun
+// mutation. Frontends fetch exactly the fields they need from one endpoint
+// instead of combining the REST handlers of each.
+package graphqlapi
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/plexusone/agent-team-stats/pkg/admission"
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+const (
+	defaultLimit = 20      // Page size of a list without a limit argument
+	maxBody      = 1 << 20 // Largest request body accepted
+)
+
+// Backend is the orchestrator the API serves. Nil components answer their
+// queries with an error saying they are not enabled.
+type Backend struct {
+	Progress   *progress.Hub
+	Statistics *statstore.Store
+	Jobs       *jobqueue.Runner      // Queue mode: startRun submits a job instead of running
+	Admission  *admission.Controller // Limits the runs startRun runs itself
+	Validate   func(*models.OrchestrationRequest) error
+	Run        func(context.Context, *models.OrchestrationRequest) (*models.OrchestrationResponse, error)
+}
+
+// API is the GraphQL schema over a backend
+type API struct {
+	backend Backend
+	schema  graphql.Schema
+	logger  *slog.Logger
+}
+
+// New builds the schema over b
+func New(b Backend, logger *slog.Logger) (*API, error) {
+	a := &API{backend: b, logger: logger}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: a.query(), Mutation: a.mutation()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+	a.schema = schema
+	return a, nil
+}
+
+// request is a GraphQL request body
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Do executes a GraphQL request
+func (a *API) Do(ctx context.Context, query, operation string, variables map[string]any) *graphql.Result {
+	return graphql.Do(graphql.Params{
+		Schema:         a.schema,
+		RequestString:  query,
+		OperationName:  operation,
+		VariableValues: variables,
+		Context:        ctx,
+	})
+}
+
+// Handler returns the handler of POST /graphql. Errors in a well-formed
+// request are reported in the response's errors, with status 200.
+func (a *API) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(req.Query) == "" {
+			http.Error(w, "query is required", http.StatusBadRequest)
+			return
+		}
+
+		result := a.Do(r.Context(), req.Query, req.OperationName, req.Variables)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			a.logger.Error("failed to encode GraphQL response", "error", err)
+		}
+	}
+}
+
+// pageArgs are the arguments of a paginated list
+var pageArgs = graphql.FieldConfigArgument{
+	"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+	"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit, Description: "At most this many; 0 for all"},
+}
+
+func (a *API) query() *graphql.Object {
+	statisticsArgs := graphql.FieldConfigArgument{
+		"query":          &graphql.ArgumentConfig{Type: graphql.String, Description: "Words to match; results are ranked by relevance"},
+		"topic":          &graphql.ArgumentConfig{Type: graphql.String, Description: "Only statistics verified for a topic with these words"},
+		"tiers":          &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Only sources of these domain tiers"},
+		"minConfidence":  &graphql.ArgumentConfig{Type: graphql.Float},
+		"publishedAfter": &graphql.ArgumentConfig{Type: graphql.DateTime, Description: "Only sources published since"},
+	}
+	sourcesArgs := graphql.FieldConfigArgument{
+		"topic": statisticsArgs["topic"],
+		"tiers": statisticsArgs["tiers"],
+	}
+	for name, arg := range pageArgs {
+		statisticsArgs[name] = arg
+		sourcesArgs[name] = arg
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"runs": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(runType))),
+				Description: "Active runs, oldest first",
+				Resolve:     a.runs,
+			},
+			"run": &graphql.Field{
+				Type:        runType,
+				Description: "An active run",
+				Args:        graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     a.run,
+			},
+			"job": &graphql.Field{
+				Type:        jobType,
+				Description: "A queued run, in queue mode",
+				Args:        graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     a.job,
+			},
+			"statistics": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(storedStatisticType))),
+				Description: "Statistics verified by past runs, most relevant first for a query, else in the order first verified",
+				Args:        statisticsArgs,
+				Resolve:     a.statistics,
+			},
+			"sources": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(sourceType))),
+				Description: "Sources of stored statistics, those with the most statistics first",
+				Args:        sourcesArgs,
+				Resolve:     a.sources,
+			},
+		},
+	})
+}
+
+func (a *API) mutation() *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"startRun": &graphql.Field{
+				Type:        graphql.NewNonNull(startedRunType),
+				Description: "Starts a run. In queue mode it returns the job to poll; otherwise it waits for the result.",
+				Args:        graphql.FieldConfigArgument{"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(runInputType)}},
+				Resolve:     a.startRun,
+			},
+		},
+	})
+}
+
+func (a *API) runs(p graphql.ResolveParams) (any, error) {
+	if a.backend.Progress == nil {
+		return nil, errors.New("run progress is not available")
+	}
+	runs := []progress.Run{}
+	for _, run := range a.backend.Progress.Runs() {
+		if run.VisibleTo(p.Context) {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+func (a *API) run(p graphql.ResolveParams) (any, error) {
+	id, _ := p.Args["id"].(string)
+	for _, run := range a.backend.Progress.Runs() {
+		if run.ID == id && run.VisibleTo(p.Context) {
+			return run, nil
+		}
+	}
+	return nil, nil
+}
+
+func (a *API) job(p graphql.ResolveParams) (any, error) {
+	if a.backend.Jobs == nil {
+		return nil, errors.New("job queue is not configured; set JOB_QUEUE")
+	}
+	id, _ := p.Args["id"].(string)
+	job, err := a.backend.Jobs.Job(p.Context, id)
+	if errors.Is(err, jobqueue.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		a.logger.Error("failed to load job", "job_id", id, "error", err)
+		return nil, errors.New("failed to load job")
+	}
+	return job, nil
+}
+
+func (a *API) statistics(p graphql.ResolveParams) (any, error) {
+	store := a.backend.Statistics
+	if store == nil {
+		return nil, errors.New("statistics store is not enabled; set STATS_STORE_FILE")
+	}
+	offset, limit, err := page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	text, _ := p.Args["query"].(string)
+	topic, _ := p.Args["topic"].(string)
+	publishedAfter, _ := p.Args["publishedAfter"].(time.Time)
+	minConfidence, _ := p.Args["minConfidence"].(float64)
+	tiers := stringList(p.Args["tiers"])
+
+	// A query ranks by relevance; the other arguments only filter
+	if strings.TrimSpace(text) != "" {
+		matches, _, err := store.Search(p.Context, statstore.Query{Text: text, Topic: topic, PublishedAfter: publishedAfter})
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		matches = slices.DeleteFunc(matches, func(m models.StatisticMatch) bool {
+			return (len(tiers) > 0 && !slices.Contains(tiers, m.DomainTier)) || m.Confidence < minConfidence
+		})
+		matches, _ = models.Paginate(matches, offset, limit)
+		return matches, nil
+	}
+	stats, err := store.List(statstore.Filter{Topic: topic, Tiers: tiers, MinConfidence: minConfidence, PublishedAfter: publishedAfter})
+	if err != nil {
+		return nil, fmt.Errorf("listing statistics failed: %w", err)
+	}
+	stats, _ = models.Paginate(stats, offset, limit)
+	return stats, nil
+}
+
+// source is a page stored statistics were verified on
+type source struct {
+	URL         string
+	Name        string
+	Domain      string
+	DomainTier  string
+	Publisher   string
+	PublishedAt time.Time
+	License     *models.License
+	Statistics  []models.StoredStatistic
+}
+
+func (a *API) sources(p graphql.ResolveParams) (any, error) {
+	store := a.backend.Statistics
+	if store == nil {
+		return nil, errors.New("statistics store is not enabled; set STATS_STORE_FILE")
+	}
+	offset, limit, err := page(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	topic, _ := p.Args["topic"].(string)
+	stats, err := store.List(statstore.Filter{Topic: topic, Tiers: stringList(p.Args["tiers"])})
+	if err != nil {
+		return nil, fmt.Errorf("listing statistics failed: %w", err)
+	}
+
+	byURL := make(map[string]*source)
+	var sources []*source
+	for _, s := range stats {
+		if s.SourceURL == "" {
+			continue
+		}
+		src, ok := byURL[s.SourceURL]
+		if !ok {
+			src = &source{URL: s.SourceURL, Domain: s.Domain, DomainTier: s.DomainTier}
+			byURL[s.SourceURL] = src
+			sources = append(sources, src)
+		}
+		// The latest verification describes the page as it is now
+		src.Name = cmp.Or(s.Source, src.Name)
+		src.Publisher = cmp.Or(s.Publisher, src.Publisher)
+		if !s.PublishedAt.IsZero() {
+			src.PublishedAt = s.PublishedAt
+		}
+		if s.License != nil {
+			src.License = s.License
+		}
+		src.Statistics = append(src.Statistics, s)
+	}
+	slices.SortStableFunc(sources, func(x, y *source) int { return cmp.Compare(len(y.Statistics), len(x.Statistics)) })
+	sources, _ = models.Paginate(sources, offset, limit)
+	return sources, nil
+}
+
+func (a *API) startRun(p graphql.ResolveParams) (any, error) {
+	req, err := runRequest(p.Args["input"])
+	if err != nil {
+		return nil, err
+	}
+	if a.backend.Validate != nil {
+		if err := a.backend.Validate(req); err != nil {
+			return nil, err
+		}
+	}
+
+	// In queue mode a worker runs the request. A dry run only searches, so
+	// it is answered directly.
+	if a.backend.Jobs != nil && !req.DryRun {
+		job, err := a.backend.Jobs.Submit(p.Context, req)
+		if err != nil {
+			a.logger.Error("failed to queue job", "error", err)
+			return nil, errors.New("failed to queue job")
+		}
+		a.logger.Info("job queued", "job_id", job.ID, "topic", req.Topic)
+		return map[string]any{"job": job}, nil
+	}
+	if a.backend.Run == nil {
+		return nil, errors.New("runs cannot be started here")
+	}
+
+	release, ok, retry := a.backend.Admission.Acquire(p.Context)
+	if !ok {
+		return nil, fmt.Errorf("orchestrator is busy; retry in %d seconds", int(math.Ceil(retry.Seconds())))
+	}
+	defer release()
+	resp, err := a.backend.Run(p.Context, req)
+	if err != nil {
+		return nil, fmt.Errorf("orchestration failed: %w", err)
+	}
+	tenant.Charge(p.Context, resp.CostSummary)
+	return map[string]any{"result": resp}, nil
+}
+
+// runRequest converts a RunInput to an orchestration request
+func runRequest(input any) (*models.OrchestrationRequest, error) {
+	in, _ := input.(map[string]any)
+	req := &models.OrchestrationRequest{}
+	req.Topic, _ = in["topic"].(string)
+	req.MinVerifiedStats, _ = in["minVerifiedStats"].(int)
+	req.MaxCandidates, _ = in["maxCandidates"].(int)
+	req.ReputableOnly, _ = in["reputableOnly"].(bool)
+	req.Compare = stringList(in["compare"])
+	req.DryRun, _ = in["dryRun"].(bool)
+	req.IncludeSummary, _ = in["includeSummary"].(bool)
+	req.PermissiveLicenseOnly, _ = in["permissiveLicenseOnly"].(bool)
+	req.Reproducible, _ = in["reproducible"].(bool)
+	req.ExcludeProjections, _ = in["excludeProjections"].(bool)
+	req.LLMProvider, _ = in["llmProvider"].(string)
+	req.LLMModel, _ = in["llmModel"].(string)
+	for _, s := range stringList(in["statisticTypes"]) {
+		t, ok := models.ParseStatisticType(s)
+		if !ok {
+			return nil, fmt.Errorf("unknown statistic type %q", s)
+		}
+		req.StatisticTypes = append(req.StatisticTypes, t)
+	}
+	if strings.TrimSpace(req.Topic) == "" {
+		return nil, errors.New("topic is required")
+	}
+	if req.MinVerifiedStats < 0 || req.MaxCandidates < 0 {
+		return nil, errors.New("minVerifiedStats and maxCandidates must not be negative")
+	}
+	return req, nil
+}
+
+// page reads the offset and limit arguments
+func page(args map[string]any) (offset, limit int, err error) {
+	offset, _ = args["offset"].(int)
+	limit, _ = args["limit"].(int)
+	if offset < 0 || limit < 0 {
+		return 0, 0, errors.New("offset and limit must not be negative")
+	}
+	return offset, limit, nil
+}
+
+// stringList converts a list argument of strings
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
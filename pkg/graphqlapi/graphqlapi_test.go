@@ -0,0 +1,248 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+var testStats = []models.Statistic{
+	{
+		Name: "Global electric vehicle sales in 2023", Value: 14, Unit: "million", Source: "IEA",
+		SourceURL: "https://www.iea.org/reports/global-ev-outlook-2024",
+		Excerpt:   "Almost 14 million new electric cars were registered globally in 2023.", Verified: true,
+		License: &models.License{Kind: models.LicenseCCBY, Permissive: true},
+	},
+	{
+		Name: "Share of new cars sold in China that were electric", Value: 38, Unit: "%", Source: "IEA",
+		SourceURL: "https://www.iea.org/reports/global-ev-outlook-2024",
+		Excerpt:   "Electric cars accounted for 38% of new car sales in China.", Verified: true,
+	},
+	{
+		Name: "Electric share of US car sales", Value: 7.6, Unit: "%", Source: "Example News",
+		SourceURL: "https://news.example.com/ev-sales",
+		Excerpt:   "Electric vehicles made up 7.6% of US car sales.", Verified: true,
+		PublishedAt: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+func newAPI(t *testing.T, b Backend) *API {
+	t.Helper()
+	a, err := New(b, testLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// do posts a query to the API's handler and decodes its data into out,
+// returning the errors reported
+func do(t *testing.T, a *API, query string, variables map[string]any, out any) []string {
+	t.Helper()
+	body, _ := json.Marshal(request{Query: query, Variables: variables})
+	rec := httptest.NewRecorder()
+	a.Handler()(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var errs []string
+	for _, e := range resp.Errors {
+		errs = append(errs, e.Message)
+	}
+	if out != nil && len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return errs
+}
+
+func TestQueries(t *testing.T) {
+	store, err := statstore.New(filepath.Join(t.TempDir(), "statistics.json"), nil, testLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Merge(context.Background(), "electric vehicles", testStats, nil); err != nil {
+		t.Fatal(err)
+	}
+	hub := progress.NewHub()
+	_, reporter := hub.Begin(context.Background(), "electric vehicles", 5)
+	reporter.Stage(progress.StageResearch, 1)
+	a := newAPI(t, Backend{Progress: hub, Statistics: store})
+
+	var data struct {
+		Runs []struct {
+			ID, Topic, Stage string
+			Target           int
+		}
+		Statistics []struct {
+			ID         string
+			Name       string
+			Value      float64
+			Domain     string
+			Topics     []string
+			Score      *float64
+			License    *struct{ Kind string }
+			DomainTier string
+		}
+		Sources []struct {
+			URL            string
+			StatisticCount int
+			License        *struct{ Permissive bool }
+		}
+	}
+	errs := do(t, a, `{
+		runs { id topic stage target }
+		statistics(query: "electric car sales china", limit: 1) { id name value domain topics score license { kind } domainTier }
+		sources(topic: "electric vehicles") { url statisticCount license { permissive } }
+	}`, nil, &data)
+	if errs != nil {
+		t.Fatalf("errors = %v", errs)
+	}
+	if len(data.Runs) != 1 || data.Runs[0].Topic != "electric vehicles" || data.Runs[0].Stage != progress.StageResearch || data.Runs[0].Target != 5 {
+		t.Errorf("runs = %+v", data.Runs)
+	}
+	if len(data.Statistics) != 1 || data.Statistics[0].Value != 38 || data.Statistics[0].Domain != "iea.org" ||
+		data.Statistics[0].Score == nil || data.Statistics[0].License != nil || len(data.Statistics[0].Topics) != 1 {
+		t.Errorf("statistics = %+v", data.Statistics)
+	}
+	if len(data.Sources) != 2 || data.Sources[0].StatisticCount != 2 || data.Sources[0].License == nil ||
+		!data.Sources[0].License.Permissive || data.Sources[1].URL != "https://news.example.com/ev-sales" {
+		t.Errorf("sources = %+v", data.Sources)
+	}
+
+	var one struct {
+		Run *struct{ Topic string }
+	}
+	id := data.Runs[0].ID
+	if errs := do(t, a, `query($id: ID!) { run(id: $id) { topic } }`, map[string]any{"id": id}, &one); errs != nil || one.Run == nil {
+		t.Errorf("run(%s) = %+v, %v", id, one.Run, errs)
+	}
+	if errs := do(t, a, `{ run(id: "missing") { topic } }`, nil, &one); errs != nil || one.Run != nil {
+		t.Errorf("run(missing) = %+v, %v", one.Run, errs)
+	}
+
+	var listed struct {
+		Statistics []struct {
+			Name        string
+			PublishedAt *string
+		}
+	}
+	if errs := do(t, a, `{ statistics(publishedAfter: "2024-01-01T00:00:00Z") { name publishedAt } }`, nil, &listed); errs != nil ||
+		len(listed.Statistics) != 1 || listed.Statistics[0].PublishedAt == nil {
+		t.Errorf("statistics(publishedAfter) = %+v, %v", listed.Statistics, errs)
+	}
+}
+
+func TestDisabledComponents(t *testing.T) {
+	a := newAPI(t, Backend{})
+	for _, query := range []string{`{ statistics { id } }`, `{ sources { url } }`, `{ job(id: "x") { id } }`} {
+		if errs := do(t, a, query, nil, nil); len(errs) != 1 {
+			t.Errorf("%s: errors = %v, want one", query, errs)
+		}
+	}
+}
+
+func TestStartRun(t *testing.T) {
+	var got *models.OrchestrationRequest
+	a := newAPI(t, Backend{
+		Validate: func(req *models.OrchestrationRequest) error {
+			if req.Topic == "blocked" {
+				return errors.New("topic is blocked")
+			}
+			return nil
+		},
+		Run: func(_ context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+			got = req
+			return &models.OrchestrationResponse{
+				Topic:         req.Topic,
+				Status:        models.StatusComplete,
+				Statistics:    []models.Statistic{testStats[0]},
+				VerifiedCount: 1,
+				CostSummary:   &models.CostSummary{EstimatedCostUSD: 0.02},
+			}, nil
+		},
+	})
+
+	const mutation = `mutation($input: RunInput!) {
+		startRun(input: $input) { job { id } result { topic status verifiedCount estimatedCostUsd statistics { name license { kind } } } }
+	}`
+	var data struct {
+		StartRun struct {
+			Job    *struct{ ID string }
+			Result *struct {
+				Topic, Status    string
+				VerifiedCount    int
+				EstimatedCostUSD float64 `json:"estimatedCostUsd"`
+				Statistics       []struct {
+					Name    string
+					License struct{ Kind string }
+				}
+			}
+		}
+	}
+	input := map[string]any{"topic": "electric vehicles", "minVerifiedStats": 3, "compare": []string{"US", "China"}, "statisticTypes": []string{"survey"}}
+	if errs := do(t, a, mutation, map[string]any{"input": input}, &data); errs != nil {
+		t.Fatalf("errors = %v", errs)
+	}
+	if got == nil || got.Topic != "electric vehicles" || got.MinVerifiedStats != 3 || len(got.Compare) != 2 ||
+		len(got.StatisticTypes) != 1 || got.StatisticTypes[0] != models.TypeSurvey {
+		t.Errorf("request = %+v", got)
+	}
+	r := data.StartRun.Result
+	if data.StartRun.Job != nil || r == nil || r.Status != models.StatusComplete || r.EstimatedCostUSD != 0.02 ||
+		len(r.Statistics) != 1 || r.Statistics[0].License.Kind != string(models.LicenseCCBY) {
+		t.Errorf("startRun = %+v", data.StartRun)
+	}
+
+	for _, input := range []map[string]any{
+		{"topic": "blocked"},
+		{"topic": " "},
+		{"topic": "x", "statisticTypes": []string{"rumor"}},
+	} {
+		got = nil
+		if errs := do(t, a, mutation, map[string]any{"input": input}, nil); len(errs) != 1 || got != nil {
+			t.Errorf("startRun(%v) errors = %v, ran %v", input, errs, got != nil)
+		}
+	}
+}
+
+func TestHandlerRejectsBadRequests(t *testing.T) {
+	a := newAPI(t, Backend{})
+	for _, tt := range []struct {
+		method, body string
+		want         int
+	}{
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "{", http.StatusBadRequest},
+		{http.MethodPost, `{"query": ""}`, http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		a.Handler()(rec, httptest.NewRequest(tt.method, "/graphql", strings.NewReader(tt.body)))
+		if rec.Code != tt.want {
+			t.Errorf("%s %q: status = %d, want %d", tt.method, tt.body, rec.Code, tt.want)
+		}
+	}
+}
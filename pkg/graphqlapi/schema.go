@@ -0,0 +1,289 @@
+package graphqlapi
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+)
+
+// Object types. Fields the default resolver cannot reach, such as those of
+// the Statistic embedded in a StoredStatistic, have resolvers of their own.
+
+var licenseType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "License",
+	Description: "What a source declares about reusing its content",
+	Fields: graphql.Fields{
+		"kind":       &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: licenseField(func(l *models.License) any { return string(l.Kind) })},
+		"url":        &graphql.Field{Type: graphql.String, Resolve: licenseField(func(l *models.License) any { return l.URL })},
+		"notice":     &graphql.Field{Type: graphql.String, Resolve: licenseField(func(l *models.License) any { return l.Notice })},
+		"permissive": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: licenseField(func(l *models.License) any { return l.Permissive })},
+	},
+})
+
+var corroborationType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Corroboration",
+	Description: "Another source reporting the same statistic",
+	Fields: graphql.Fields{
+		"name":       &graphql.Field{Type: graphql.String},
+		"source":     &graphql.Field{Type: graphql.String},
+		"sourceUrl":  &graphql.Field{Type: graphql.String},
+		"excerpt":    &graphql.Field{Type: graphql.String},
+		"similarity": &graphql.Field{Type: graphql.Float},
+	},
+})
+
+// statisticFields are the fields of a statistic, shared by Statistic and
+// StoredStatistic
+func statisticFields() graphql.Fields {
+	return graphql.Fields{
+		"name":           statField(graphql.NewNonNull(graphql.String), func(s *models.Statistic) any { return s.Name }),
+		"value":          statField(graphql.NewNonNull(graphql.Float), func(s *models.Statistic) any { return float64(s.Value) }),
+		"unit":           statField(graphql.String, func(s *models.Statistic) any { return s.Unit }),
+		"source":         statField(graphql.String, func(s *models.Statistic) any { return s.Source }),
+		"sourceUrl":      statField(graphql.String, func(s *models.Statistic) any { return s.SourceURL }),
+		"excerpt":        statField(graphql.String, func(s *models.Statistic) any { return s.Excerpt }),
+		"verified":       statField(graphql.NewNonNull(graphql.Boolean), func(s *models.Statistic) any { return s.Verified }),
+		"type":           statField(graphql.String, func(s *models.Statistic) any { return optional(string(s.Type)) }),
+		"publisher":      statField(graphql.String, func(s *models.Statistic) any { return optional(s.Publisher) }),
+		"publishedAt":    statField(graphql.DateTime, func(s *models.Statistic) any { return timestamp(s.PublishedAt) }),
+		"license":        statField(licenseType, func(s *models.Statistic) any { return s.License }),
+		"contentHash":    statField(graphql.String, func(s *models.Statistic) any { return optional(s.ContentHash) }),
+		"corroboratedBy": statField(graphql.NewList(graphql.NewNonNull(corroborationType)), func(s *models.Statistic) any { return s.CorroboratedBy }),
+		"safetyFlags":    statField(graphql.NewList(graphql.NewNonNull(graphql.String)), func(s *models.Statistic) any { return s.SafetyFlags }),
+	}
+}
+
+var statisticType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Statistic",
+	Description: "A statistic found by a run",
+	Fields:      statisticFields(),
+})
+
+var storedStatisticType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "StoredStatistic",
+	Description: "A statistic verified by a past run, kept in the statistics store",
+	Fields: func() graphql.Fields {
+		fields := statisticFields()
+		fields["id"] = storedField(graphql.NewNonNull(graphql.ID), func(s *models.StoredStatistic) any { return s.ID })
+		fields["topics"] = storedField(graphql.NewList(graphql.NewNonNull(graphql.String)), func(s *models.StoredStatistic) any { return s.Topics })
+		fields["firstSeen"] = storedField(graphql.DateTime, func(s *models.StoredStatistic) any { return timestamp(s.FirstSeen) })
+		fields["lastSeen"] = storedField(graphql.DateTime, func(s *models.StoredStatistic) any { return timestamp(s.LastSeen) })
+		fields["domain"] = storedField(graphql.String, func(s *models.StoredStatistic) any { return s.Domain })
+		fields["domainTier"] = storedField(graphql.String, func(s *models.StoredStatistic) any { return s.DomainTier })
+		fields["confidence"] = storedField(graphql.Float, func(s *models.StoredStatistic) any { return s.Confidence })
+		fields["stale"] = storedField(graphql.NewNonNull(graphql.Boolean), func(s *models.StoredStatistic) any { return s.Stale != nil })
+		fields["supersededBy"] = storedField(graphql.ID, func(s *models.StoredStatistic) any { return optional(s.SupersededBy) })
+		fields["score"] = &graphql.Field{
+			Type:        graphql.Float,
+			Description: "Relevance to the statistics query, from 0 to 1",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				if m, ok := p.Source.(models.StatisticMatch); ok {
+					return m.Score, nil
+				}
+				return nil, nil
+			},
+		}
+		return fields
+	}(),
+})
+
+var sourceType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Source",
+	Description: "A page stored statistics were verified on",
+	Fields: graphql.Fields{
+		"url":            &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: sourceField(func(s *source) any { return s.URL })},
+		"name":           &graphql.Field{Type: graphql.String, Resolve: sourceField(func(s *source) any { return s.Name })},
+		"domain":         &graphql.Field{Type: graphql.String, Resolve: sourceField(func(s *source) any { return s.Domain })},
+		"domainTier":     &graphql.Field{Type: graphql.String, Resolve: sourceField(func(s *source) any { return s.DomainTier })},
+		"publisher":      &graphql.Field{Type: graphql.String, Resolve: sourceField(func(s *source) any { return optional(s.Publisher) })},
+		"publishedAt":    &graphql.Field{Type: graphql.DateTime, Resolve: sourceField(func(s *source) any { return timestamp(s.PublishedAt) })},
+		"license":        &graphql.Field{Type: licenseType, Resolve: sourceField(func(s *source) any { return s.License })},
+		"statisticCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: sourceField(func(s *source) any { return len(s.Statistics) })},
+		"statistics":     &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(storedStatisticType)), Resolve: sourceField(func(s *source) any { return s.Statistics })},
+	},
+})
+
+var runType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Run",
+	Description: "The live progress of an active orchestration run",
+	Fields: graphql.Fields{
+		"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: runField(func(r *progress.Run) any { return r.ID })},
+		"topic":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: runField(func(r *progress.Run) any { return r.Topic })},
+		"stage":      &graphql.Field{Type: graphql.String, Resolve: runField(func(r *progress.Run) any { return r.Stage })},
+		"pass":       &graphql.Field{Type: graphql.Int, Resolve: runField(func(r *progress.Run) any { return r.Pass })},
+		"candidates": &graphql.Field{Type: graphql.Int, Resolve: runField(func(r *progress.Run) any { return r.Candidates })},
+		"verified":   &graphql.Field{Type: graphql.Int, Resolve: runField(func(r *progress.Run) any { return r.Verified })},
+		"failed":     &graphql.Field{Type: graphql.Int, Resolve: runField(func(r *progress.Run) any { return r.Failed })},
+		"target":     &graphql.Field{Type: graphql.Int, Resolve: runField(func(r *progress.Run) any { return r.Target })},
+		"status":     &graphql.Field{Type: graphql.String, Resolve: runField(func(r *progress.Run) any { return optional(r.Status) })},
+		"error":      &graphql.Field{Type: graphql.String, Resolve: runField(func(r *progress.Run) any { return optional(r.Error) })},
+		"recent":     &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(statisticType)), Resolve: runField(func(r *progress.Run) any { return r.Recent })},
+		"startedAt":  &graphql.Field{Type: graphql.DateTime, Resolve: runField(func(r *progress.Run) any { return timestamp(r.StartedAt) })},
+		"updatedAt":  &graphql.Field{Type: graphql.DateTime, Resolve: runField(func(r *progress.Run) any { return timestamp(r.UpdatedAt) })},
+	},
+})
+
+var resultType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "RunResult",
+	Description: "The outcome of a finished run",
+	Fields: graphql.Fields{
+		"topic":            &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: resultField(func(r *models.OrchestrationResponse) any { return r.Topic })},
+		"status":           &graphql.Field{Type: graphql.String, Resolve: resultField(func(r *models.OrchestrationResponse) any { return optional(r.Status) })},
+		"partial":          &graphql.Field{Type: graphql.Boolean, Resolve: resultField(func(r *models.OrchestrationResponse) any { return r.Partial })},
+		"statistics":       &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(statisticType)), Resolve: resultField(func(r *models.OrchestrationResponse) any { return r.Statistics })},
+		"totalCandidates":  &graphql.Field{Type: graphql.Int, Resolve: resultField(func(r *models.OrchestrationResponse) any { return r.TotalCandidates })},
+		"verifiedCount":    &graphql.Field{Type: graphql.Int, Resolve: resultField(func(r *models.OrchestrationResponse) any { return r.VerifiedCount })},
+		"failedCount":      &graphql.Field{Type: graphql.Int, Resolve: resultField(func(r *models.OrchestrationResponse) any { return r.FailedCount })},
+		"targetCount":      &graphql.Field{Type: graphql.Int, Resolve: resultField(func(r *models.OrchestrationResponse) any { return r.TargetCount })},
+		"sessionId":        &graphql.Field{Type: graphql.ID, Resolve: resultField(func(r *models.OrchestrationResponse) any { return optional(r.SessionID) })},
+		"reportId":         &graphql.Field{Type: graphql.ID, Resolve: resultField(func(r *models.OrchestrationResponse) any { return optional(r.ReportID) })},
+		"estimatedCostUsd": &graphql.Field{Type: graphql.Float, Resolve: resultField(estimatedCost)},
+		"timestamp":        &graphql.Field{Type: graphql.DateTime, Resolve: resultField(func(r *models.OrchestrationResponse) any { return timestamp(r.Timestamp) })},
+	},
+})
+
+var jobType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Job",
+	Description: "A queued run, polled until it is done",
+	Fields: graphql.Fields{
+		"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: jobField(func(j *models.Job) any { return j.ID })},
+		"status":     &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: jobField(func(j *models.Job) any { return string(j.Status) })},
+		"topic":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: jobField(func(j *models.Job) any { return j.Request.Topic })},
+		"attempts":   &graphql.Field{Type: graphql.Int, Resolve: jobField(func(j *models.Job) any { return j.Attempts })},
+		"error":      &graphql.Field{Type: graphql.String, Resolve: jobField(func(j *models.Job) any { return optional(j.Error) })},
+		"result":     &graphql.Field{Type: resultType, Resolve: jobField(func(j *models.Job) any { return j.Response })},
+		"createdAt":  &graphql.Field{Type: graphql.DateTime, Resolve: jobField(func(j *models.Job) any { return timestamp(j.CreatedAt) })},
+		"startedAt":  &graphql.Field{Type: graphql.DateTime, Resolve: jobField(func(j *models.Job) any { return timestamp(j.StartedAt) })},
+		"finishedAt": &graphql.Field{Type: graphql.DateTime, Resolve: jobField(func(j *models.Job) any { return timestamp(j.FinishedAt) })},
+	},
+})
+
+var startedRunType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "StartedRun",
+	Description: "A run started by startRun: its job in queue mode, otherwise its result",
+	Fields: graphql.Fields{
+		"job":    &graphql.Field{Type: jobType},
+		"result": &graphql.Field{Type: resultType},
+	},
+})
+
+var runInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "RunInput",
+	Description: "An orchestration request; omitted fields take the configured defaults",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"topic":                 &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"minVerifiedStats":      &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"maxCandidates":         &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"reputableOnly":         &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"compare":               &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		"dryRun":                &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"includeSummary":        &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"permissiveLicenseOnly": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"reproducible":          &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"statisticTypes":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		"excludeProjections":    &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"llmProvider":           &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"llmModel":              &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+// statField resolves a field of the statistic of a Statistic or
+// StoredStatistic object
+func statField(t graphql.Output, get func(*models.Statistic) any) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (any, error) {
+		switch s := p.Source.(type) {
+		case models.Statistic:
+			return get(&s), nil
+		case models.StoredStatistic:
+			return get(&s.Statistic), nil
+		case models.StatisticMatch:
+			return get(&s.Statistic), nil
+		}
+		return nil, nil
+	}}
+}
+
+// storedField resolves a field of a StoredStatistic object
+func storedField(t graphql.Output, get func(*models.StoredStatistic) any) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (any, error) {
+		switch s := p.Source.(type) {
+		case models.StoredStatistic:
+			return get(&s), nil
+		case models.StatisticMatch:
+			return get(&s.StoredStatistic), nil
+		}
+		return nil, nil
+	}}
+}
+
+func licenseField(get func(*models.License) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		if l, ok := p.Source.(*models.License); ok && l != nil {
+			return get(l), nil
+		}
+		return nil, nil
+	}
+}
+
+func sourceField(get func(*source) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		if s, ok := p.Source.(*source); ok {
+			return get(s), nil
+		}
+		return nil, nil
+	}
+}
+
+func runField(get func(*progress.Run) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		if r, ok := p.Source.(progress.Run); ok {
+			return get(&r), nil
+		}
+		return nil, nil
+	}
+}
+
+func resultField(get func(*models.OrchestrationResponse) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		if r, ok := p.Source.(*models.OrchestrationResponse); ok && r != nil {
+			return get(r), nil
+		}
+		return nil, nil
+	}
+}
+
+func jobField(get func(*models.Job) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		if j, ok := p.Source.(*models.Job); ok && j != nil {
+			return get(j), nil
+		}
+		return nil, nil
+	}
+}
+
+// estimatedCost returns a run's estimated LLM cost, if it was tracked
+func estimatedCost(r *models.OrchestrationResponse) any {
+	if r.CostSummary == nil {
+		return nil
+	}
+	return r.CostSummary.EstimatedCostUSD
+}
+
+// optional returns nil for an empty string, so it reads as null
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// timestamp returns nil for a zero time, so it reads as null
+func timestamp(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
@@ -56,13 +56,18 @@ type NamespaceConfig struct {
 
 // LLMConfig contains LLM provider configuration.
 type LLMConfig struct {
-	Provider    string `yaml:"provider" validate:"required,oneof=gemini claude openai ollama"`
+	Provider    string `yaml:"provider" validate:"required,oneof=gemini claude openai ollama groq mistral deepseek"`
 	BaseURL     string `yaml:"baseUrl"`
 	GeminiModel string `yaml:"geminiModel"`
-	ClaudeModel string `yaml:"claudeModel"`
-	OpenAIModel string `yaml:"openaiModel"`
-	OllamaModel string `yaml:"ollamaModel"`
-	OllamaURL   string `yaml:"ollamaUrl" validate:"omitempty,url"`
+	// GeminiBackend selects API-key auth ("api") or Vertex AI with
+	// Application Default Credentials ("vertexai", e.g. GKE workload identity)
+	GeminiBackend  string `yaml:"geminiBackend" validate:"omitempty,oneof=api vertexai"`
+	VertexProject  string `yaml:"vertexProject"`
+	VertexLocation string `yaml:"vertexLocation"`
+	ClaudeModel    string `yaml:"claudeModel"`
+	OpenAIModel    string `yaml:"openaiModel"`
+	OllamaModel    string `yaml:"ollamaModel"`
+	OllamaURL      string `yaml:"ollamaUrl" validate:"omitempty,url"`
 }
 
 // SearchConfig contains search provider configuration.
@@ -77,6 +82,9 @@ type SecretsConfig struct {
 	ClaudeAPIKey    string `yaml:"claudeApiKey"`
 	OpenAIAPIKey    string `yaml:"openaiApiKey"`
 	AnthropicAPIKey string `yaml:"anthropicApiKey"`
+	GroqAPIKey      string `yaml:"groqApiKey"`
+	MistralAPIKey   string `yaml:"mistralApiKey"`
+	DeepSeekAPIKey  string `yaml:"deepseekApiKey"`
 	SerperAPIKey    string `yaml:"serperApiKey"`
 	SerpAPIKey      string `yaml:"serpApiKey"`
 }
@@ -330,16 +338,27 @@ func (v *Validator) validateBusinessRules(values *Values) []error {
 		}
 	}
 
+	// Vertex AI needs a project; credentials come from the pod's identity
+	if values.LLM.GeminiBackend == "vertexai" && values.LLM.VertexProject == "" {
+		errs = append(errs, fmt.Errorf("llm.vertexProject is required when llm.geminiBackend is vertexai"))
+	}
+
 	// Check that secrets are configured when secret creation is enabled
 	if values.Secrets.Create {
 		hasLLMKey := false
 		switch values.LLM.Provider {
 		case "gemini":
-			hasLLMKey = values.Secrets.GeminiAPIKey != ""
+			hasLLMKey = values.Secrets.GeminiAPIKey != "" || values.LLM.GeminiBackend == "vertexai"
 		case "claude":
 			hasLLMKey = values.Secrets.ClaudeAPIKey != "" || values.Secrets.AnthropicAPIKey != ""
 		case "openai":
 			hasLLMKey = values.Secrets.OpenAIAPIKey != ""
+		case "groq":
+			hasLLMKey = values.Secrets.GroqAPIKey != ""
+		case "mistral":
+			hasLLMKey = values.Secrets.MistralAPIKey != ""
+		case "deepseek":
+			hasLLMKey = values.Secrets.DeepSeekAPIKey != ""
 		case "ollama":
 			hasLLMKey = true // Ollama doesn't require API key
 		}
@@ -250,6 +250,9 @@ func TestLLMProviderValidation(t *testing.T) {
 		{"valid claude", "claude", false},
 		{"valid openai", "openai", false},
 		{"valid ollama", "ollama", false},
+		{"valid groq", "groq", false},
+		{"valid mistral", "mistral", false},
+		{"valid deepseek", "deepseek", false},
 		{"invalid provider", "invalid", true},
 		{"empty provider", "", true},
 	}
@@ -272,6 +275,37 @@ func TestLLMProviderValidation(t *testing.T) {
 	}
 }
 
+func TestGeminiBackendValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		project string
+		wantErr bool
+	}{
+		{"default backend", "", "", false},
+		{"api backend", "api", "", false},
+		{"vertexai with project", "vertexai", "my-project", false},
+		{"vertexai without project", "vertexai", "", true},
+		{"invalid backend", "vertex", "my-project", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := createTestValues()
+			values.LLM.GeminiBackend = tt.backend
+			values.LLM.VertexProject = tt.project
+
+			errs := NewValidator().ValidateWithContext(values)
+			if tt.wantErr && len(errs) == 0 {
+				t.Errorf("expected validation error for backend '%s'", tt.backend)
+			}
+			if !tt.wantErr && len(errs) > 0 {
+				t.Errorf("unexpected validation errors for backend '%s': %v", tt.backend, errs)
+			}
+		})
+	}
+}
+
 // createTestValues returns a valid Values struct for testing.
 func createTestValues() *Values {
 	return &Values{
@@ -7,24 +7,30 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
 )
 
 // PostJSON makes a POST request with JSON payload and decodes the JSON response
 func PostJSON(ctx context.Context, client *http.Client, url string, request interface{}, response interface{}) error {
+	return PostJSONWithHeaders(ctx, client, url, nil, request, response)
+}
+
+// PostJSONWithHeaders is PostJSON with extra request headers, such as
+// authorization. Responses written by older agents are upgraded to the
+// current schema as they are decoded. An agent answering 429 with a short
+// Retry-After is retried after the delay it asks for.
+func PostJSONWithHeaders(ctx context.Context, client *http.Client, url string, headers map[string]string, request interface{}, response interface{}) error {
 	reqData, err := json.Marshal(request)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(httpReq) //nolint:gosec // G704: URL from config, not user input
+	resp, err := postWithRetry(ctx, client, url, headers, reqData)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
@@ -33,9 +39,64 @@ func PostJSON(ctx context.Context, client *http.Client, url string, request inte
 		return fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+	if err := migrate.Decode(resp.Body, response); err != nil {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return nil
 }
+
+const (
+	// busyRetries is how many times a request answered 429 Too Many
+	// Requests is retried, waiting as long as its Retry-After asks
+	busyRetries = 2
+	// maxRetryAfter is the longest Retry-After that is waited out; a
+	// longer one is returned to the caller as an error
+	maxRetryAfter = 30 * time.Second
+)
+
+// postWithRetry POSTs body, retrying while the agent is busy
+func postWithRetry(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			httpReq.Header.Set(key, value)
+		}
+
+		resp, err := client.Do(httpReq) //nolint:gosec // G704: URL from config, not user input
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt == busyRetries {
+			return resp, nil
+		}
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("request failed: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds, reporting
+// whether it is short enough to wait out
+func retryAfter(header string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	wait := time.Duration(seconds) * time.Second
+	return wait, wait <= maxRetryAfter
+}
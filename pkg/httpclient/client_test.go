@@ -0,0 +1,45 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPostJSONRetriesBusyAgent(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "server busy", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer srv.Close()
+
+	var resp struct {
+		OK bool `json:"ok"`
+	}
+	if err := PostJSON(context.Background(), srv.Client(), srv.URL, map[string]string{}, &resp); err != nil || !resp.OK {
+		t.Fatalf("PostJSON() = %v, %+v", err, resp)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d; want 2", calls)
+	}
+}
+
+func TestPostJSONGivesUpOnLongRetryAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, "server busy", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	var resp struct{}
+	if err := PostJSON(context.Background(), srv.Client(), srv.URL, map[string]string{}, &resp); err == nil {
+		t.Error("expected an error")
+	}
+}
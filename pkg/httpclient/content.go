@@ -0,0 +1,174 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Default limits on what page fetches read
+const (
+	DefaultMaxPageMB     = 1  // HTML and plain text, cut off beyond this
+	DefaultMaxDocumentMB = 10 // PDF, Office, data, and image files, refused beyond this
+)
+
+// DefaultAllowedTypes are the media types page fetches read unless
+// FETCH_ALLOWED_TYPES is set: web pages and text, data files, PDF and Office
+// documents, and images for figure extraction. Video, audio, archives, and
+// executables are refused before their body is read.
+var DefaultAllowedTypes = []string{
+	"text/*",
+	"application/xhtml+xml",
+	"application/json",
+	"application/*+json",
+	"application/xml",
+	"application/*+xml",
+	"application/csv",
+	"application/pdf",
+	"application/vnd.openxmlformats-officedocument.*",
+	"application/vnd.ms-excel",
+	"image/*",
+}
+
+// ContentPolicy bounds what page fetches read. A nil ContentPolicy applies
+// the defaults.
+type ContentPolicy struct {
+	MaxPageMB     int
+	MaxDocumentMB int
+	AllowedTypes  []string // Media types, with "*" matching the rest of one
+}
+
+// NewContentPolicy validates fetch content settings; zero sizes and an empty
+// allowlist take the defaults
+func NewContentPolicy(maxPageMB, maxDocumentMB int, allowed []string) (*ContentPolicy, error) {
+	p := &ContentPolicy{MaxPageMB: maxPageMB, MaxDocumentMB: maxDocumentMB}
+	if p.MaxPageMB == 0 {
+		p.MaxPageMB = DefaultMaxPageMB
+	}
+	if p.MaxDocumentMB == 0 {
+		p.MaxDocumentMB = DefaultMaxDocumentMB
+	}
+	if p.MaxPageMB < 1 || p.MaxPageMB > 100 {
+		return nil, fmt.Errorf("invalid page size limit %d MB: want 1-100", maxPageMB)
+	}
+	if p.MaxDocumentMB < 1 || p.MaxDocumentMB > 500 {
+		return nil, fmt.Errorf("invalid document size limit %d MB: want 1-500", maxDocumentMB)
+	}
+	for _, t := range allowed {
+		t = strings.ToLower(strings.TrimSpace(t))
+		kind, sub, ok := strings.Cut(t, "/")
+		if !ok || kind == "" || sub == "" || (strings.Contains(kind, "*") && t != "*/*") {
+			return nil, fmt.Errorf("invalid allowed content type %q: want type/subtype, e.g. text/* or application/pdf", t)
+		}
+		p.AllowedTypes = append(p.AllowedTypes, t)
+	}
+	if len(p.AllowedTypes) == 0 {
+		p.AllowedTypes = DefaultAllowedTypes
+	}
+	return p, nil
+}
+
+// Allows reports whether a media type may be read, matched without its
+// parameters
+func (p *ContentPolicy) Allows(mediaType string) bool {
+	allowed := DefaultAllowedTypes
+	if p != nil {
+		allowed = p.AllowedTypes
+	}
+	mediaType = strings.ToLower(mediaType)
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	for _, pattern := range allowed {
+		if pattern == "*/*" {
+			return true
+		}
+		prefix, suffix, wild := strings.Cut(pattern, "*")
+		if !wild && mediaType == pattern {
+			return true
+		}
+		if wild && len(mediaType) >= len(prefix)+len(suffix) && strings.HasPrefix(mediaType, prefix) && strings.HasSuffix(mediaType, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PageBytes returns the most bytes read of a page
+func (p *ContentPolicy) PageBytes() int64 {
+	if p == nil {
+		return DefaultMaxPageMB << 20
+	}
+	return int64(p.MaxPageMB) << 20
+}
+
+// DocumentBytes returns the most bytes read of a document
+func (p *ContentPolicy) DocumentBytes() int64 {
+	if p == nil {
+		return DefaultMaxDocumentMB << 20
+	}
+	return int64(p.MaxDocumentMB) << 20
+}
+
+// decodingTransport asks for gzip or brotli compression and decodes the
+// response. The standard transport decodes gzip alone, and only when the
+// request leaves Accept-Encoding unset.
+type decodingTransport struct {
+	base http.RoundTripper
+}
+
+func (t decodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", "gzip, br")
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	var open func(io.Reader) (io.Reader, error)
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		open = func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+	case "br":
+		open = func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }
+	default:
+		return resp, nil
+	}
+	resp.Body = &decodedBody{body: resp.Body, open: open}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// decodedBody decodes a compressed body, starting at the first read so an
+// empty body, as for HEAD, is never decoded
+type decodedBody struct {
+	body io.ReadCloser
+	open func(io.Reader) (io.Reader, error)
+	r    io.Reader
+	err  error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.r, b.err = b.open(b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	return b.body.Close()
+}
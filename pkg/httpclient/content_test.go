@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestContentPolicy(t *testing.T) {
+	var defaults *ContentPolicy
+	for mediaType, want := range map[string]bool{
+		"text/html; charset=utf-8": true,
+		"application/vnd.api+json": true,
+		"application/PDF":          true,
+		"image/png":                true,
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+		"video/mp4":                false,
+		"application/zip":          false,
+		"application/octet-stream": false,
+		"application/x-msdownload": false,
+	} {
+		if got := defaults.Allows(mediaType); got != want {
+			t.Errorf("Allows(%q) = %v, want %v", mediaType, got, want)
+		}
+	}
+	if defaults.PageBytes() != 1<<20 || defaults.DocumentBytes() != 10<<20 {
+		t.Errorf("default limits = %d, %d", defaults.PageBytes(), defaults.DocumentBytes())
+	}
+
+	p, err := NewContentPolicy(2, 0, []string{"text/html", "application/pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.PageBytes() != 2<<20 || p.DocumentBytes() != DefaultMaxDocumentMB<<20 || p.Allows("text/csv") || !p.Allows("application/pdf") {
+		t.Errorf("NewContentPolicy(2, 0, html+pdf) = %+v", p)
+	}
+	if p, err := NewContentPolicy(0, 0, []string{"*/*"}); err != nil || !p.Allows("video/mp4") {
+		t.Errorf("NewContentPolicy(*/*) = %+v, %v", p, err)
+	}
+
+	for _, bad := range []struct {
+		page, doc int
+		allowed   []string
+	}{
+		{-1, 0, nil},
+		{0, 1000, nil},
+		{0, 0, []string{"pdf"}},
+		{0, 0, []string{"*/json"}},
+	} {
+		if _, err := NewContentPolicy(bad.page, bad.doc, bad.allowed); err == nil {
+			t.Errorf("NewContentPolicy(%d, %d, %v) should fail", bad.page, bad.doc, bad.allowed)
+		}
+	}
+}
+
+func TestFetchClientDecodes(t *testing.T) {
+	const text = "Renewables supplied 30% of global electricity in 2023"
+	var gz, br bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte(text))
+	_ = zw.Close()
+	bw := brotli.NewWriter(&br)
+	_, _ = bw.Write([]byte(text))
+	_ = bw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip, br" {
+			t.Errorf("Accept-Encoding = %q", r.Header.Get("Accept-Encoding"))
+		}
+		switch r.URL.Path {
+		case "/gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(gz.Bytes())
+		case "/br":
+			w.Header().Set("Content-Encoding", "br")
+			if r.Method != http.MethodHead {
+				_, _ = w.Write(br.Bytes())
+			}
+		default:
+			_, _ = w.Write([]byte(text))
+		}
+	}))
+	defer server.Close()
+
+	client := NewFetchClient(0, nil)
+	for _, path := range []string{"/gzip", "/br", "/plain"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || string(body) != text || resp.Header.Get("Content-Encoding") != "" {
+			t.Errorf("GET %s = %q, %v", path, body, err)
+		}
+	}
+
+	resp, err := client.Head(server.URL + "/br")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("HEAD /br = %v", err)
+	} else {
+		resp.Body.Close()
+	}
+}
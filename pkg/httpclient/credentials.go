@@ -0,0 +1,228 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// Credential is what a site needs before it serves pages: basic auth, header
+// tokens, and session cookies
+type Credential struct {
+	Username string            `json:"username,omitempty"`
+	Password string            `json:"password,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"` // e.g. {"Authorization": "Bearer ..."}
+	Cookies  map[string]string `json:"cookies,omitempty"` // Cookie values by name
+}
+
+// Credentials maps domains to the credentials page fetches send them, also
+// used for their subdomains. A nil Credentials sends none.
+type Credentials map[string]Credential
+
+// ParseCredentials parses a JSON object of credentials by domain, such as
+// {"intranet.example.com": {"username": "svc", "password": "..."}}. An empty
+// string has no credentials.
+func ParseCredentials(data string) (Credentials, error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, nil
+	}
+	var raw Credentials
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		// The JSON error can quote the input, so keep it out of the message
+		return nil, errors.New("fetch credentials are not a JSON object of credentials by domain")
+	}
+
+	c := make(Credentials, len(raw))
+	for domain, cred := range raw {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+		if domain == "" || strings.ContainsAny(domain, "/: ") {
+			return nil, fmt.Errorf("invalid fetch credentials domain %q: want a host name", domain)
+		}
+		if cred.Password != "" && cred.Username == "" {
+			return nil, fmt.Errorf("fetch credentials for %s have a password but no username", domain)
+		}
+		for name, value := range cred.Headers {
+			if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+				return nil, fmt.Errorf("fetch credentials for %s have an invalid header %q", domain, name)
+			}
+		}
+		for name, value := range cred.Cookies {
+			if (&http.Cookie{Name: name, Value: value}).Valid() != nil {
+				return nil, fmt.Errorf("fetch credentials for %s have an invalid cookie %q", domain, name)
+			}
+		}
+		c[domain] = cred
+	}
+	return c, nil
+}
+
+// Domains returns the domains with credentials, sorted
+func (c Credentials) Domains() []string {
+	domains := make([]string, 0, len(c))
+	for domain := range c {
+		domains = append(domains, domain)
+	}
+	slices.Sort(domains)
+	return domains
+}
+
+// domainFor returns the most specific domain with credentials that host is
+// or is a subdomain of
+func (c Credentials) domainFor(host string) (string, bool) {
+	host = strings.ToLower(host)
+	best := ""
+	for domain := range c {
+		if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > len(best) {
+			best = domain
+		}
+	}
+	return best, best != ""
+}
+
+// trustedKey marks a context whose fetches may send credentials
+type trustedKey struct{}
+
+// WithCredentials marks fetches made with ctx as allowed to send the site
+// credentials: their URL came from an internal search backend or the
+// operator's configuration. URLs from requests, LLM answers, or links on
+// pages are fetched without them, so a caller cannot point the agents'
+// logins at a page of its choosing and read it through the results.
+func WithCredentials(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedKey{}, true)
+}
+
+// CredentialsAllowed reports whether fetches made with ctx may send
+// credentials, because it is marked by WithCredentials
+func CredentialsAllowed(ctx context.Context) bool {
+	trusted, _ := ctx.Value(trustedKey{}).(bool)
+	return trusted
+}
+
+// credentialsAllowed reports whether a request may send credentials: its
+// context is marked by WithCredentials and it goes over HTTPS, so they are
+// never sent in the clear
+func credentialsAllowed(req *http.Request) bool {
+	return CredentialsAllowed(req.Context()) && req.URL.Scheme == "https"
+}
+
+// Apply sets the basic auth and headers of the request host's credentials,
+// when the request may send them. Cookies are sent by the fetch client.
+func (c Credentials) Apply(req *http.Request) {
+	if !credentialsAllowed(req) {
+		return
+	}
+	domain, ok := c.domainFor(req.URL.Hostname())
+	if !ok {
+		return
+	}
+	cred := c[domain]
+	if cred.Username != "" {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+	for name, value := range cred.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// CheckRedirect is an http.Client CheckRedirect that sends each hop the
+// credentials of its own host. The client forwards the first request's
+// headers to every hop, which would hand a header token to whatever site a
+// page redirects to.
+func (c Credentials) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	req.Header.Del("Authorization")
+	for _, cred := range c {
+		for name := range cred.Headers {
+			req.Header.Del(name)
+		}
+	}
+	c.Apply(req)
+	return nil
+}
+
+// Jar returns a cookie jar holding the credentials' cookies, sent over
+// HTTPS only. It also keeps the session cookies those sites set, so a login
+// redirect is followed, and ignores cookies from every other site, so
+// public pages are fetched without state.
+func (c Credentials) Jar() http.CookieJar {
+	jar, _ := cookiejar.New(nil) // Never fails without options
+	for domain, cred := range c {
+		cookies := make([]*http.Cookie, 0, len(cred.Cookies))
+		for name, value := range cred.Cookies {
+			cookies = append(cookies, &http.Cookie{Name: name, Value: value, Domain: domain, Path: "/", Secure: true})
+		}
+		jar.SetCookies(&url.URL{Scheme: "https", Host: domain, Path: "/"}, cookies)
+	}
+	return &credentialJar{jar: jar, credentials: c}
+}
+
+// credentialJar is a cookie jar limited to domains with credentials
+type credentialJar struct {
+	jar         *cookiejar.Jar
+	credentials Credentials
+}
+
+// SetCookies implements http.CookieJar
+func (j *credentialJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if _, ok := j.credentials.domainFor(u.Hostname()); ok {
+		j.jar.SetCookies(u, cookies)
+	}
+}
+
+// Cookies implements http.CookieJar
+func (j *credentialJar) Cookies(u *url.URL) []*http.Cookie {
+	if _, ok := j.credentials.domainFor(u.Hostname()); !ok {
+		return nil
+	}
+	return j.jar.Cookies(u)
+}
+
+// cookieTransport sends and keeps the cookies of its jar for requests that
+// may send credentials, and no cookies for the rest. An http.Client Jar
+// cannot tell them apart, since it sees only the URL.
+type cookieTransport struct {
+	base http.RoundTripper
+	jar  http.CookieJar
+}
+
+func (t cookieTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !credentialsAllowed(req) {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	for _, cookie := range t.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		t.jar.SetCookies(req.URL, cookies)
+	}
+	return resp, nil
+}
+
+// NewFetchClient returns a client for page fetches that sends credentials,
+// to requests whose context is marked by WithCredentials, and decodes gzip
+// and brotli responses, with the given timeout (0 for none), over
+// FetchTransport
+func NewFetchClient(timeout time.Duration, credentials Credentials) *http.Client {
+	client := &http.Client{Timeout: timeout, Transport: decodingTransport{base: FetchTransport()}}
+	if len(credentials) > 0 {
+		client.Transport = cookieTransport{base: client.Transport, jar: credentials.Jar()}
+		client.CheckRedirect = credentials.CheckRedirect
+	}
+	return client
+}
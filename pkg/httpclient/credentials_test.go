@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseCredentials(t *testing.T) {
+	c, err := ParseCredentials(`{
+		"WWW.Intranet.example.com": {"username": "svc", "password": "secret"},
+		"reports.example.org": {"headers": {"X-Api-Token": "abc"}, "cookies": {"session": "s1"}}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Domains(); len(got) != 2 || got[0] != "intranet.example.com" || got[1] != "reports.example.org" {
+		t.Errorf("Domains() = %v", got)
+	}
+
+	for _, bad := range []string{
+		`["intranet.example.com"]`,
+		`{"https://intranet.example.com": {"username": "svc"}}`,
+		`{"intranet.example.com": {"password": "secret"}}`,
+		`{"intranet.example.com": {"headers": {"X Token": "abc"}}}`,
+		`{"intranet.example.com": {"headers": {"X-Token": "a\nb"}}}`,
+		`{"intranet.example.com": {"cookies": {"session": "a;b"}}}`,
+	} {
+		if _, err := ParseCredentials(bad); err == nil {
+			t.Errorf("ParseCredentials(%s) should fail", bad)
+		}
+	}
+	if c, err := ParseCredentials(" "); err != nil || c != nil {
+		t.Errorf("ParseCredentials(\" \") = %v, %v", c, err)
+	}
+}
+
+func TestFetchClient(t *testing.T) {
+	var public *http.Request
+	publicSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		public = r
+		http.SetCookie(w, &http.Cookie{Name: "tracker", Value: "t1"})
+	}))
+	defer publicSrv.Close()
+
+	var private []*http.Request
+	privateSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		private = append(private, r)
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "auth", Value: "a1", Path: "/"})
+			http.Redirect(w, r, "/report", http.StatusFound)
+		case "/away":
+			http.Redirect(w, r, "https://news.example.com/landing", http.StatusFound)
+		}
+	}))
+	defer privateSrv.Close()
+
+	// The test certificate covers *.example.com; dial each name to its server
+	roots := x509.NewCertPool()
+	roots.AddCert(privateSrv.Certificate())
+	servers := map[string]string{
+		"intranet.example.com:443": privateSrv.Listener.Addr().String(),
+		"news.example.com:443":     publicSrv.Listener.Addr().String(),
+	}
+	defer func(saved http.RoundTripper) { fetchTransport = saved }(fetchTransport)
+	fetchTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, servers[addr])
+		},
+	}
+
+	c := Credentials{"intranet.example.com": {
+		Username: "svc",
+		Password: "secret",
+		Headers:  map[string]string{"X-Api-Token": "abc"},
+		Cookies:  map[string]string{"session": "s1"},
+	}}
+	client := NewFetchClient(0, c)
+	get := func(ctx context.Context, u string) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		c.Apply(req)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	trusted := WithCredentials(context.Background())
+
+	// A login redirect keeps the session cookie it sets
+	get(trusted, "https://intranet.example.com/login")
+	if len(private) != 2 {
+		t.Fatalf("private requests = %d, want 2", len(private))
+	}
+	report := private[1]
+	if user, pass, ok := report.BasicAuth(); !ok || user != "svc" || pass != "secret" {
+		t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+	}
+	if report.Header.Get("X-Api-Token") != "abc" {
+		t.Errorf("X-Api-Token = %q", report.Header.Get("X-Api-Token"))
+	}
+	if s, err := report.Cookie("session"); err != nil || s.Value != "s1" {
+		t.Errorf("session cookie = %v, %v", s, err)
+	}
+	if a, err := report.Cookie("auth"); err != nil || a.Value != "a1" {
+		t.Errorf("auth cookie = %v, %v", a, err)
+	}
+
+	// A redirect to another site carries none of the credentials
+	get(trusted, "https://intranet.example.com/away")
+	if public == nil {
+		t.Fatal("redirect to the public server not followed")
+	}
+	if public.Header.Get("Authorization") != "" || public.Header.Get("X-Api-Token") != "" || len(public.Cookies()) != 0 {
+		t.Errorf("public request headers = %v", public.Header)
+	}
+
+	// Cookies from other sites are not kept
+	get(trusted, "https://news.example.com/again")
+	if len(public.Cookies()) != 0 {
+		t.Errorf("public request cookies = %v", public.Cookies())
+	}
+
+	// A URL that did not come from internal search or the operator is
+	// fetched without credentials, though its host has them
+	private = nil
+	get(context.Background(), "https://intranet.example.com/report")
+	if r := private[0]; r.Header.Get("Authorization") != "" || r.Header.Get("X-Api-Token") != "" || len(r.Cookies()) != 0 {
+		t.Errorf("untrusted request headers = %v", r.Header)
+	}
+
+	// Nor are they sent over plain HTTP
+	req, _ := http.NewRequestWithContext(trusted, http.MethodGet, "http://intranet.example.com/report", nil)
+	c.Apply(req)
+	if req.Header.Get("Authorization") != "" || req.Header.Get("X-Api-Token") != "" {
+		t.Errorf("plain HTTP request headers = %v", req.Header)
+	}
+	if cookies := c.Jar().Cookies(req.URL); len(cookies) != 0 {
+		t.Errorf("jar cookies over plain HTTP = %v", cookies)
+	}
+}
@@ -0,0 +1,208 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Resolver caching settings
+const (
+	DefaultDNSCacheTTL = time.Minute
+	dnsNegativeTTL     = 10 * time.Second // How long a host that does not resolve is remembered
+	dnsCacheSize       = 4096             // Hosts cached; the cache is cleared when full
+	dnsMinDialTimeout  = 2 * time.Second  // Least time an address gets when the dial timeout is split among several
+)
+
+// cachingResolver remembers host lookups, so fetching many pages of a
+// domain, or retrying one, does not ask the DNS server each time. A host
+// that does not exist is remembered for a shorter while; other lookup
+// failures, which may pass, are not remembered. Concurrent lookups of a
+// host that is not cached share one query.
+type cachingResolver struct {
+	lookup func(ctx context.Context, host string) ([]string, error)
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]dnsEntry
+	inflight singleflight.Group
+}
+
+// dnsEntry is a cached lookup: its addresses, or the error of a host that
+// does not exist
+type dnsEntry struct {
+	addrs   []string
+	err     error
+	expires time.Time
+}
+
+func newCachingResolver(ttl time.Duration) *cachingResolver {
+	return &cachingResolver{
+		lookup:  net.DefaultResolver.LookupHost,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]dnsEntry),
+	}
+}
+
+// LookupHost returns the addresses of host, from the cache while fresh
+func (r *cachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	r.mu.Lock()
+	e, ok := r.entries[host]
+	r.mu.Unlock()
+	if ok && r.now().Before(e.expires) {
+		return e.addrs, e.err
+	}
+
+	// The shared query is not canceled with the first caller's context, so
+	// its cancellation does not fail the others; each caller still stops
+	// waiting when its own context ends
+	ch := r.inflight.DoChan(host, func() (any, error) {
+		return r.query(context.WithoutCancel(ctx), host)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		e := res.Val.(dnsEntry)
+		return e.addrs, e.err
+	case <-ctx.Done():
+		return nil, &net.DNSError{Err: ctx.Err().Error(), Name: host, IsTimeout: errors.Is(ctx.Err(), context.DeadlineExceeded)}
+	}
+}
+
+// query looks host up and caches the answer, returning an error only for a
+// failure that is not cached
+func (r *cachingResolver) query(ctx context.Context, host string) (dnsEntry, error) {
+	addrs, err := r.lookup(ctx, host)
+	var (
+		e      dnsEntry
+		dnsErr *net.DNSError
+	)
+	switch {
+	case err == nil:
+		e = dnsEntry{addrs: addrs, expires: r.now().Add(r.ttl)}
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		e = dnsEntry{err: err, expires: r.now().Add(min(r.ttl, dnsNegativeTTL))}
+	default:
+		return dnsEntry{}, err
+	}
+
+	r.mu.Lock()
+	if len(r.entries) >= dnsCacheSize {
+		clear(r.entries)
+	}
+	r.entries[host] = e
+	r.mu.Unlock()
+	return e, nil
+}
+
+// dialer dials a host through the cache, trying its addresses in turn,
+// alternating between IPv6 and IPv4 so an unreachable family does not hold
+// up the other. Like the standard dialer, it splits the dial timeout among
+// the addresses left, so one that does not answer cannot use it all. A
+// lookup failure is returned as the *net.DNSError it is, so fetch failures
+// can still be told apart.
+func (r *cachingResolver) dialer(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return d.DialContext(ctx, network, addr)
+		}
+		if d.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+			defer cancel()
+		}
+		addrs, err := r.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var candidates []string
+		for _, ip := range addrs {
+			if matchesNetwork(network, ip) {
+				candidates = append(candidates, ip)
+			}
+		}
+		candidates = interleaveFamilies(candidates)
+
+		var firstErr error
+		for i, ip := range candidates {
+			attempt, cancel := ctx, context.CancelFunc(func() {})
+			if deadline, ok := ctx.Deadline(); ok {
+				share := time.Until(deadline) / time.Duration(len(candidates)-i)
+				attempt, cancel = context.WithTimeout(ctx, max(share, dnsMinDialTimeout))
+			}
+			conn, err := d.DialContext(attempt, network, net.JoinHostPort(ip, port))
+			cancel()
+			if err == nil {
+				return conn, nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		if firstErr == nil {
+			firstErr = &net.DNSError{Err: "no suitable address", Name: host, IsNotFound: true}
+		}
+		return nil, firstErr
+	}
+}
+
+// interleaveFamilies orders addresses alternately by family, starting with
+// the family of the first, keeping their order within each family
+func interleaveFamilies(addrs []string) []string {
+	if len(addrs) < 2 {
+		return addrs
+	}
+	isV4 := func(s string) bool {
+		ip, err := netip.ParseAddr(s)
+		return err == nil && ip.Unmap().Is4()
+	}
+	var primary, fallback []string
+	for _, a := range addrs {
+		if isV4(a) == isV4(addrs[0]) {
+			primary = append(primary, a)
+		} else {
+			fallback = append(fallback, a)
+		}
+	}
+	out := make([]string, 0, len(addrs))
+	for i := 0; i < max(len(primary), len(fallback)); i++ {
+		if i < len(primary) {
+			out = append(out, primary[i])
+		}
+		if i < len(fallback) {
+			out = append(out, fallback[i])
+		}
+	}
+	return out
+}
+
+// matchesNetwork reports whether an address can be dialed on network, such
+// as tcp4 or tcp6
+func matchesNetwork(network, ip string) bool {
+	parsed := net.ParseIP(ip)
+	switch network {
+	case "tcp4", "udp4":
+		return parsed != nil && parsed.To4() != nil
+	case "tcp6", "udp6":
+		return parsed != nil && parsed.To4() == nil
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachingResolver(t *testing.T) {
+	lookups := 0
+	now := time.Now()
+	r := newCachingResolver(time.Minute)
+	r.now = func() time.Time { return now }
+	r.lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		switch host {
+		case "stats.example.com":
+			return []string{"192.0.2.1"}, nil
+		case "gone.example.com":
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return nil, &net.DNSError{Err: "server misbehaving", Name: host, IsTemporary: true}
+	}
+	ctx := context.Background()
+
+	for range 2 {
+		if addrs, err := r.LookupHost(ctx, "stats.example.com"); err != nil || len(addrs) != 1 {
+			t.Fatalf("LookupHost() = %v, %v", addrs, err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("lookups = %d, want 1 while cached", lookups)
+	}
+	now = now.Add(2 * time.Minute)
+	r.LookupHost(ctx, "stats.example.com") //nolint:errcheck
+	if lookups != 2 {
+		t.Errorf("lookups = %d, want 2 after expiry", lookups)
+	}
+
+	// A host that does not exist is remembered; a server failure is not
+	for range 2 {
+		var dnsErr *net.DNSError
+		if _, err := r.LookupHost(ctx, "gone.example.com"); !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			t.Fatalf("LookupHost(gone) = %v, want not found", err)
+		}
+		r.LookupHost(ctx, "flaky.example.com") //nolint:errcheck
+	}
+	if lookups != 5 {
+		t.Errorf("lookups = %d, want 5", lookups)
+	}
+	now = now.Add(dnsNegativeTTL)
+	r.LookupHost(ctx, "gone.example.com") //nolint:errcheck
+	if lookups != 6 {
+		t.Errorf("lookups = %d, want 6 after the negative entry expires", lookups)
+	}
+}
+
+func TestCachingResolverDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	r := newCachingResolver(time.Minute)
+	r.lookup = func(ctx context.Context, host string) ([]string, error) {
+		if host == "stats.example.com" {
+			// The IPv6 address is skipped on tcp4
+			return []string{"::1", "127.0.0.1"}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	dial := r.dialer(&net.Dialer{Timeout: time.Second})
+
+	conn, err := dial(context.Background(), "tcp4", net.JoinHostPort("stats.example.com", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	var dnsErr *net.DNSError
+	if _, err := dial(context.Background(), "tcp", "gone.example.com:443"); !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("dial(gone) = %v, want the lookup's *net.DNSError", err)
+	}
+}
+
+func TestCachingResolverSharesLookups(t *testing.T) {
+	var lookups atomic.Int32
+	release := make(chan struct{})
+	r := newCachingResolver(time.Minute)
+	r.lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups.Add(1)
+		<-release
+		return []string{"192.0.2.1"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			if addrs, err := r.LookupHost(context.Background(), "stats.example.com"); err != nil || len(addrs) != 1 {
+				t.Errorf("LookupHost() = %v, %v", addrs, err)
+			}
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("lookups = %d, want 1 for concurrent callers", n)
+	}
+}
+
+func TestCachingResolverSplitsDialTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	r := newCachingResolver(time.Minute)
+	r.lookup = func(ctx context.Context, host string) ([]string, error) {
+		// 192.0.2.1 is a documentation address that never answers
+		return []string{"192.0.2.1", "127.0.0.1"}, nil
+	}
+	dial := r.dialer(&net.Dialer{Timeout: 2 * dnsMinDialTimeout})
+
+	start := time.Now()
+	conn, err := dial(context.Background(), "tcp4", net.JoinHostPort("stats.example.com", port))
+	if err != nil {
+		t.Fatalf("dial() = %v; the unreachable address used the whole timeout", err)
+	}
+	conn.Close()
+	if elapsed := time.Since(start); elapsed > 2*dnsMinDialTimeout {
+		t.Errorf("dial() took %v", elapsed)
+	}
+}
+
+func TestInterleaveFamilies(t *testing.T) {
+	got := interleaveFamilies([]string{"2001:db8::1", "2001:db8::2", "2001:db8::3", "192.0.2.1", "192.0.2.2"})
+	want := []string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2", "2001:db8::3"}
+	if !slices.Equal(got, want) {
+		t.Errorf("interleaveFamilies() = %v, want %v", got, want)
+	}
+}
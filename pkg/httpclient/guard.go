@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ErrNotPublic is returned when a page fetch would connect to an address
+// that is not on the public internet and is not in PrivateHosts
+var ErrNotPublic = errors.New("address is not public")
+
+// nonPublic are ranges that netip does not classify as private, loopback,
+// or link-local but that no public site is served from
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "This network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // Reserved
+	netip.MustParsePrefix("2001:db8::/32"),  // Documentation
+	netip.MustParsePrefix("fec0::/10"),      // Deprecated site-local
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use NAT64
+}
+
+// nat64 is the well-known NAT64 prefix, which embeds an IPv4 address
+var nat64 = netip.MustParsePrefix("64:ff9b::/96")
+
+// isPublic reports whether ip is a public unicast address. Loopback,
+// private, link-local (including the cloud metadata address
+// 169.254.169.254), multicast, and unspecified addresses are not, nor is a
+// NAT64 address of one of them.
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if nat64.Contains(ip) {
+		b := ip.As16()
+		return isPublic(netip.AddrFrom4([4]byte(b[12:])))
+	}
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, p := range nonPublic {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialGuard refuses connections to addresses that are not public, so a
+// page URL from a search result, an LLM, or a request cannot reach the
+// metadata service, localhost, or the internal network. The check runs on
+// the address actually dialed, after DNS resolution, so a public name that
+// resolves to a private address is refused too. Hosts and ranges the
+// operator lists, and the proxy, are dialed without it.
+type dialGuard struct {
+	hosts []string       // Host names, also matching their subdomains
+	nets  []netip.Prefix // Address ranges
+}
+
+// newDialGuard returns a guard allowing the private hosts, which are host
+// names, IP addresses, or CIDR ranges, and the proxy
+func newDialGuard(privateHosts []string, proxyURL string) (*dialGuard, error) {
+	g := &dialGuard{}
+	for _, entry := range privateHosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case strings.Contains(entry, "/"):
+			p, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid private host range %q", entry)
+			}
+			g.nets = append(g.nets, p.Masked())
+		default:
+			if ip, err := netip.ParseAddr(entry); err == nil {
+				g.nets = append(g.nets, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+				continue
+			}
+			if strings.ContainsAny(entry, ": ") {
+				return nil, fmt.Errorf("invalid private host %q: want a host name, IP address, or CIDR range", entry)
+			}
+			g.hosts = append(g.hosts, strings.TrimPrefix(entry, "."))
+		}
+	}
+
+	// The proxy is dialed in place of every site, so it must be reachable
+	// wherever it runs
+	if proxyURL == "" {
+		env := httpproxy.FromEnvironment()
+		proxyURL = cmp.Or(env.HTTPSProxy, env.HTTPProxy)
+	}
+	if proxyURL != "" {
+		if u, err := url.Parse(proxyURL); err == nil && u.Hostname() != "" {
+			g.hosts = append(g.hosts, strings.ToLower(u.Hostname()))
+		}
+	}
+	return g, nil
+}
+
+// allowsHost reports whether host was listed by the operator, by name or
+// subdomain, or is an address in a listed range
+func (g *dialGuard) allowsHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, h := range g.hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return g.allowsAddr(ip)
+	}
+	return false
+}
+
+// allowsAddr reports whether ip is in a listed range
+func (g *dialGuard) allowsAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range g.nets {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// control is a net.Dialer Control that refuses non-public addresses
+func (g *dialGuard) control(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("refusing to connect to %s: %w", address, ErrNotPublic)
+	}
+	if isPublic(ap.Addr()) || g.allowsAddr(ap.Addr()) {
+		return nil
+	}
+	return fmt.Errorf("refusing to connect to %s: %w", ap.Addr(), ErrNotPublic)
+}
+
+// wrap returns a dial function that dials listed hosts with open and every
+// other host with guarded, whose dialer runs control
+func (g *dialGuard) wrap(open, guarded dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err == nil && g.allowsHost(host) {
+			return open(ctx, network, addr)
+		}
+		return guarded(ctx, network, addr)
+	}
+}
+
+// dialFunc is the signature of http.Transport.DialContext
+type dialFunc = func(ctx context.Context, network, addr string) (net.Conn, error)
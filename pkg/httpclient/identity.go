@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultUserAgent identifies page fetches unless FETCH_USER_AGENT is set
+const DefaultUserAgent = "StatsAgentTeam/1.0"
+
+// Identity is how page fetches present themselves to sites: a User-Agent
+// with an optional contact URL for site operators, an optional
+// Accept-Language, and User-Agents sent verbatim to sites that block
+// generic bots. A nil Identity sends DefaultUserAgent alone.
+type Identity struct {
+	UserAgent      string
+	ContactURL     string            // Appended to the User-Agent as "(+url)"
+	AcceptLanguage string            // Empty sends no Accept-Language
+	Overrides      map[string]string // User-Agent by domain, also used for its subdomains
+}
+
+// NewIdentity validates fetch identity settings. overrides are
+// "domain=User-Agent" entries, such as "example.com=Mozilla/5.0 (...)".
+func NewIdentity(userAgent, contactURL, acceptLanguage string, overrides []string) (*Identity, error) {
+	id := &Identity{
+		UserAgent:      strings.TrimSpace(userAgent),
+		ContactURL:     strings.TrimSpace(contactURL),
+		AcceptLanguage: strings.TrimSpace(acceptLanguage),
+		Overrides:      make(map[string]string, len(overrides)),
+	}
+	if id.UserAgent == "" {
+		id.UserAgent = DefaultUserAgent
+	}
+	if !validHeader(id.UserAgent) {
+		return nil, fmt.Errorf("invalid user agent %q", userAgent)
+	}
+	if !validHeader(id.AcceptLanguage) {
+		return nil, fmt.Errorf("invalid Accept-Language %q", acceptLanguage)
+	}
+	if id.ContactURL != "" {
+		u, err := url.Parse(id.ContactURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") || !validHeader(id.ContactURL) {
+			return nil, fmt.Errorf("invalid contact URL %q: want an http, https, or mailto URL", contactURL)
+		}
+	}
+	for _, entry := range overrides {
+		domain, agent, ok := strings.Cut(entry, "=")
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		agent = strings.TrimSpace(agent)
+		if !ok || domain == "" || strings.ContainsAny(domain, "/: ") || agent == "" || !validHeader(agent) {
+			return nil, fmt.Errorf("invalid user agent override %q: want domain=User-Agent", entry)
+		}
+		id.Overrides[strings.TrimPrefix(domain, "www.")] = agent
+	}
+	return id, nil
+}
+
+// UserAgentFor returns the User-Agent sent to host: its domain's override,
+// or the User-Agent with the contact URL
+func (id *Identity) UserAgentFor(host string) string {
+	if id == nil {
+		return DefaultUserAgent
+	}
+	host = strings.ToLower(host)
+	for domain, agent := range id.Overrides {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return agent
+		}
+	}
+	if id.ContactURL != "" {
+		return id.UserAgent + " (+" + id.ContactURL + ")"
+	}
+	return id.UserAgent
+}
+
+// Apply sets the identity's headers on a request
+func (id *Identity) Apply(req *http.Request) {
+	req.Header.Set("User-Agent", id.UserAgentFor(req.URL.Hostname()))
+	if id != nil && id.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", id.AcceptLanguage)
+	}
+}
+
+// RobotsToken returns the product name robots.txt rules address the
+// fetcher by, the lowercased User-Agent up to its version
+func (id *Identity) RobotsToken() string {
+	agent := DefaultUserAgent
+	if id != nil {
+		agent = id.UserAgent
+	}
+	token, _, _ := strings.Cut(agent, "/")
+	token, _, _ = strings.Cut(token, " ")
+	return strings.ToLower(token)
+}
+
+// validHeader reports whether s can be sent as a header value
+func validHeader(s string) bool {
+	return !strings.ContainsFunc(s, func(r rune) bool { return r < ' ' || r == 0x7f })
+}
@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	id, err := NewIdentity("ExampleBot/2.1", "https://example.org/bot", "en-US,en;q=0.9",
+		[]string{"www.statista.com=Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host, want string
+	}{
+		{"www.bls.gov", "ExampleBot/2.1 (+https://example.org/bot)"},
+		{"statista.com", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko)"},
+		{"de.statista.com", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko)"},
+		{"notstatista.com", "ExampleBot/2.1 (+https://example.org/bot)"},
+	}
+	for _, tt := range tests {
+		if got := id.UserAgentFor(tt.host); got != tt.want {
+			t.Errorf("UserAgentFor(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+	if got := id.RobotsToken(); got != "examplebot" {
+		t.Errorf("RobotsToken() = %q", got)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://www.bls.gov/cps/", nil)
+	id.Apply(req)
+	if req.Header.Get("User-Agent") != tests[0].want || req.Header.Get("Accept-Language") != "en-US,en;q=0.9" {
+		t.Errorf("Apply() headers = %v", req.Header)
+	}
+
+	var none *Identity
+	req, _ = http.NewRequest(http.MethodGet, "https://www.bls.gov/cps/", nil)
+	none.Apply(req)
+	if req.Header.Get("User-Agent") != DefaultUserAgent || req.Header.Get("Accept-Language") != "" || none.RobotsToken() != "statsagentteam" {
+		t.Errorf("nil Apply() headers = %v", req.Header)
+	}
+
+	for _, bad := range [][]string{
+		{"Bot/1.0\r\nX-Injected: 1", "", ""},
+		{"", "not a url", ""},
+		{"", "", "en\x00US"},
+	} {
+		if _, err := NewIdentity(bad[0], bad[1], bad[2], nil); err == nil {
+			t.Errorf("NewIdentity(%q) should fail", bad)
+		}
+	}
+	if _, err := NewIdentity("", "", "", []string{"statista.com"}); err == nil {
+		t.Error("NewIdentity() with an override without a User-Agent should fail")
+	}
+}
@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+)
+
+// ContentTypeNDJSON is the media type of a newline-delimited JSON stream
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// maxLineSize bounds one line of a stream
+const maxLineSize = 16 << 20
+
+// PostNDJSON makes a POST request with JSON payload, asking for a stream of
+// newline-delimited JSON, and passes each line to fn as it arrives,
+// stopping at fn's first error. A server that answers with a single JSON
+// document instead, such as an agent from before it streamed, has its
+// document passed to fn whole.
+func PostNDJSON(ctx context.Context, client *http.Client, url string, request any, fn func(line []byte) error) error {
+	reqData, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := postWithRetry(ctx, client, url, map[string]string{"Accept": ContentTypeNDJSON + ", application/json"}, reqData)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != ContentTypeNDJSON {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return fn(body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+	return nil
+}
+
+// PostVerification asks a verification agent to verify candidates, passing
+// each result to onResult, if not nil, as soon as the agent has it, and
+// returns the agent's whole response. Agents that do not stream have their
+// results passed on once the response arrives.
+func PostVerification(ctx context.Context, client *http.Client, url string, req *models.VerificationRequest, onResult func(models.VerificationResult)) (*models.VerificationResponse, error) {
+	var resp *models.VerificationResponse
+	streamed := false
+	err := PostNDJSON(ctx, client, url, req, func(line []byte) error {
+		var ev models.VerificationEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		switch {
+		case ev.Result != nil:
+			streamed = true
+			if onResult != nil {
+				onResult(*ev.Result)
+			}
+		case ev.Response != nil:
+			resp = ev.Response
+		default:
+			// A whole response, from an agent that does not stream
+			resp = new(models.VerificationResponse)
+			if err := migrate.Unmarshal(line, resp); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, errors.New("verification stream ended without a response")
+	}
+	if !streamed && onResult != nil {
+		for _, result := range resp.Results {
+			onResult(result)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,63 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestPostVerification(t *testing.T) {
+	results := []models.VerificationResult{
+		{Statistic: &models.Statistic{Name: "a"}, Verified: true},
+		{Statistic: &models.Statistic{Name: "b"}, Reason: "Excerpt not found"},
+	}
+	whole := models.VerificationResponse{SchemaVersion: models.SchemaVersion, Results: results, Verified: 1, Failed: 1}
+
+	streaming := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), ContentTypeNDJSON) {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", ContentTypeNDJSON)
+		enc := json.NewEncoder(w)
+		for i := range results {
+			_ = enc.Encode(models.VerificationEvent{Result: &results[i]})
+		}
+		_ = enc.Encode(models.VerificationEvent{Response: &whole})
+	}))
+	defer streaming.Close()
+	legacy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(whole)
+	}))
+	defer legacy.Close()
+	truncated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeNDJSON)
+		_ = json.NewEncoder(w).Encode(models.VerificationEvent{Result: &results[0]})
+	}))
+	defer truncated.Close()
+
+	for _, srv := range []*httptest.Server{streaming, legacy} {
+		var names []string
+		resp, err := PostVerification(context.Background(), srv.Client(), srv.URL, &models.VerificationRequest{}, func(r models.VerificationResult) {
+			names = append(names, r.Statistic.Name)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Verified != 1 || len(resp.Results) != 2 {
+			t.Errorf("response = %+v", resp)
+		}
+		if strings.Join(names, " ") != "a b" {
+			t.Errorf("results passed on = %v; want a and b once each", names)
+		}
+	}
+
+	if _, err := PostVerification(context.Background(), truncated.Client(), truncated.URL, &models.VerificationRequest{}, nil); err == nil {
+		t.Error("stream without a response succeeded")
+	}
+}
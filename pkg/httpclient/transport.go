@@ -0,0 +1,265 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TransportConfig configures outbound HTTP connections
+type TransportConfig struct {
+	ProxyURL     string // Proxy for all requests; empty uses HTTPS_PROXY/HTTP_PROXY
+	CABundleFile string // PEM file of extra trusted CAs, added to the system roots
+
+	Pool PoolConfig
+
+	DNSCacheTTL time.Duration // How long page fetches cache host lookups; 0 asks the DNS server every time
+
+	// PrivateHosts are the host names (with their subdomains), IP
+	// addresses, and CIDR ranges page fetches may reach although they are
+	// not public, such as an intranet. Every other non-public address is
+	// refused.
+	PrivateHosts []string
+}
+
+// PoolConfig sizes the connection pool shared by every outbound client and
+// bounds how long a connection may take to set up. Zero values take the
+// defaults of DefaultPool, except ResponseHeaderTimeout, which is off.
+type PoolConfig struct {
+	MaxConnsPerHost       int           // Connections page fetches open to one host at once, queuing the rest
+	MaxIdleConns          int           // Idle connections kept across all hosts
+	MaxIdleConnsPerHost   int           // Idle connections kept per host for reuse
+	IdleConnTimeout       time.Duration // How long an idle connection is kept
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration // Wait for response headers; LLM calls share it
+	DisableHTTP2          bool
+}
+
+// DefaultPool keeps enough idle connections for concurrent page fetches to
+// reuse them, where the standard library keeps two per host and opens and
+// closes a connection, and its TLS session, for nearly every fetch
+var DefaultPool = PoolConfig{
+	MaxConnsPerHost:     32,
+	MaxIdleConns:        256,
+	MaxIdleConnsPerHost: 16,
+	IdleConnTimeout:     90 * time.Second,
+	DialTimeout:         10 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
+}
+
+// tlsSessions is how many TLS sessions are kept for resumption
+const tlsSessions = 512
+
+// withDefaults fills the pool's zero settings from DefaultPool
+func (p PoolConfig) withDefaults() PoolConfig {
+	if p.MaxConnsPerHost == 0 {
+		p.MaxConnsPerHost = DefaultPool.MaxConnsPerHost
+	}
+	if p.MaxIdleConns == 0 {
+		p.MaxIdleConns = DefaultPool.MaxIdleConns
+	}
+	if p.MaxIdleConnsPerHost == 0 {
+		p.MaxIdleConnsPerHost = DefaultPool.MaxIdleConnsPerHost
+	}
+	if p.IdleConnTimeout == 0 {
+		p.IdleConnTimeout = DefaultPool.IdleConnTimeout
+	}
+	if p.DialTimeout == 0 {
+		p.DialTimeout = DefaultPool.DialTimeout
+	}
+	if p.TLSHandshakeTimeout == 0 {
+		p.TLSHandshakeTimeout = DefaultPool.TLSHandshakeTimeout
+	}
+	return p
+}
+
+// validate rejects negative pool settings
+func (p PoolConfig) validate() error {
+	for _, n := range []struct {
+		name  string
+		value int64
+	}{
+		{"max connections per host", int64(p.MaxConnsPerHost)},
+		{"max idle connections", int64(p.MaxIdleConns)},
+		{"max idle connections per host", int64(p.MaxIdleConnsPerHost)},
+		{"idle connection timeout", int64(p.IdleConnTimeout)},
+		{"dial timeout", int64(p.DialTimeout)},
+		{"TLS handshake timeout", int64(p.TLSHandshakeTimeout)},
+		{"response header timeout", int64(p.ResponseHeaderTimeout)},
+	} {
+		if n.value < 0 {
+			return fmt.Errorf("invalid %s: must not be negative", n.name)
+		}
+	}
+	return nil
+}
+
+var (
+	// baseTransport is the standard library default, captured before
+	// Configure replaces it
+	baseTransport = http.DefaultTransport.(*http.Transport).Clone()
+
+	// fetchTransport is the guarded transport for page fetches, nil until
+	// Configure
+	fetchTransport http.RoundTripper
+
+	configureOnce sync.Once
+)
+
+// NewTransport returns a transport with the proxy, CA, and pool settings.
+// NO_PROXY is honored with either an explicit or environment proxy. TLS
+// sessions are cached, so a reconnect to a host resumes its session rather
+// than repeating the full handshake.
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	if err := cfg.Pool.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.DNSCacheTTL < 0 {
+		return nil, fmt.Errorf("invalid DNS cache TTL: must not be negative")
+	}
+	pool := cfg.Pool.withDefaults()
+
+	t := baseTransport.Clone()
+	t.DialContext = (&net.Dialer{Timeout: pool.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	t.MaxIdleConns = pool.MaxIdleConns
+	t.MaxIdleConnsPerHost = pool.MaxIdleConnsPerHost
+	t.IdleConnTimeout = pool.IdleConnTimeout
+	t.TLSHandshakeTimeout = pool.TLSHandshakeTimeout
+	t.ResponseHeaderTimeout = pool.ResponseHeaderTimeout
+	t.ForceAttemptHTTP2 = !pool.DisableHTTP2
+	if pool.DisableHTTP2 {
+		// A non-nil empty map keeps the transport from upgrading to HTTP/2
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	t.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ClientSessionCache: tls.NewLRUClientSessionCache(tlsSessions),
+	}
+
+	if cfg.ProxyURL != "" {
+		u, err := url.Parse(cfg.ProxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", cfg.ProxyURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https, or socks5", cfg.ProxyURL)
+		}
+		proxy := (&httpproxy.Config{
+			HTTPProxy:  cfg.ProxyURL,
+			HTTPSProxy: cfg.ProxyURL,
+			NoProxy:    getenvAny("NO_PROXY", "no_proxy"),
+		}).ProxyFunc()
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+	}
+
+	if cfg.CABundleFile != "" {
+		pem, err := os.ReadFile(cfg.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.CABundleFile)
+		}
+		t.TLSClientConfig.RootCAs = roots
+	}
+
+	return t, nil
+}
+
+// NewFetchTransport returns a transport like NewTransport for fetching
+// pages, which limits the connections open to one host to MaxConnsPerHost,
+// caches host lookups when DNSCacheTTL is set, and refuses to connect to
+// addresses that are not public unless they are in PrivateHosts or are the
+// proxy
+func NewFetchTransport(cfg TransportConfig) (*http.Transport, error) {
+	t, err := NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	guard, err := newDialGuard(cfg.PrivateHosts, cfg.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	pool := cfg.Pool.withDefaults()
+
+	dialer := &net.Dialer{Timeout: pool.DialTimeout, KeepAlive: 30 * time.Second}
+	guarded := *dialer
+	guarded.Control = guard.control
+	open, checked := dialer.DialContext, guarded.DialContext
+	if cfg.DNSCacheTTL > 0 {
+		// Fetches visit many pages of few sites, where a cached lookup
+		// saves a query per page
+		resolver := newCachingResolver(cfg.DNSCacheTTL)
+		open, checked = resolver.dialer(dialer), resolver.dialer(&guarded)
+	}
+	t.DialContext = guard.wrap(open, checked)
+
+	// Only page fetches are capped per host: LLM providers are a handful of
+	// hosts that every concurrent call goes to
+	t.MaxConnsPerHost = pool.MaxConnsPerHost
+	return t, nil
+}
+
+// Configure validates the proxy, CA, and pool settings and installs them as
+// http.DefaultTransport, which every client without its own transport uses,
+// including the LLM provider SDKs, and as the guarded transport of page
+// fetches. Only the first successful call takes effect; changing the
+// settings requires a restart.
+func Configure(cfg TransportConfig) error {
+	t, err := NewTransport(cfg)
+	if err != nil {
+		return err
+	}
+	ft, err := NewFetchTransport(cfg)
+	if err != nil {
+		return err
+	}
+	configureOnce.Do(func() {
+		http.DefaultTransport = t
+		fetchTransport = ft
+	})
+	return nil
+}
+
+// FetchTransport returns the transport for fetching pages from URLs that
+// come from search results, LLMs, or requests. It refuses non-public
+// addresses once Configure has run; before then it is http.DefaultTransport.
+func FetchTransport() http.RoundTripper {
+	if fetchTransport != nil {
+		return fetchTransport
+	}
+	return http.DefaultTransport
+}
+
+// New returns a client with the given timeout (0 for none) that uses the
+// configured transport
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: http.DefaultTransport}
+}
+
+// getenvAny returns the first non-empty environment variable of names
+func getenvAny(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewTransportProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	tr, err := NewTransport(TransportConfig{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: tr}).Get("http://stats.example.com/report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if proxied != "http://stats.example.com/report" {
+		t.Errorf("proxy saw %q", proxied)
+	}
+
+	if _, err := NewTransport(TransportConfig{ProxyURL: "ftp://proxy:21"}); err == nil {
+		t.Error("NewTransport() accepted an ftp proxy")
+	}
+}
+
+func TestNewTransportCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(file, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := http.Get(srv.URL); err == nil { //nolint:noctx // test
+		t.Fatal("default transport trusted the test CA")
+	}
+	tr, err := NewTransport(TransportConfig{CABundleFile: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("transport with CA bundle: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := NewTransport(TransportConfig{CABundleFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("NewTransport() accepted a missing CA bundle")
+	}
+}
+
+func TestNewTransportPool(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(file, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := NewTransport(TransportConfig{CABundleFile: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.MaxIdleConnsPerHost != DefaultPool.MaxIdleConnsPerHost || tr.MaxConnsPerHost != 0 || tr.TLSClientConfig.ClientSessionCache == nil {
+		t.Errorf("NewTransport() pool = %d idle, %d max per host", tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost)
+	}
+	fetch, err := NewFetchTransport(TransportConfig{CABundleFile: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetch.MaxConnsPerHost != DefaultPool.MaxConnsPerHost {
+		t.Errorf("NewFetchTransport() max per host = %d, want %d", fetch.MaxConnsPerHost, DefaultPool.MaxConnsPerHost)
+	}
+	for _, tt := range []struct {
+		pool PoolConfig
+		want string
+	}{
+		{PoolConfig{}, "HTTP/2.0"},
+		{PoolConfig{DisableHTTP2: true, MaxIdleConnsPerHost: 4}, "HTTP/1.1"},
+	} {
+		tr, err := NewTransport(TransportConfig{CABundleFile: file, Pool: tt.pool})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proto, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(proto) != tt.want {
+			t.Errorf("NewTransport(%+v) spoke %s, want %s", tt.pool, proto, tt.want)
+		}
+	}
+
+	if _, err := NewTransport(TransportConfig{Pool: PoolConfig{DialTimeout: -time.Second}}); err == nil {
+		t.Error("NewTransport() accepted a negative dial timeout")
+	}
+}
+
+func TestNewFetchTransportRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	for _, tt := range []struct {
+		private []string
+		url     string
+		allowed bool
+	}{
+		{nil, srv.URL, false},
+		{nil, fmt.Sprintf("http://localhost:%d/", port), false},
+		{[]string{"127.0.0.1"}, srv.URL, true},
+		{[]string{"127.0.0.0/8"}, srv.URL, true},
+		{[]string{"localhost"}, fmt.Sprintf("http://localhost:%d/", port), true},
+		{[]string{"10.0.0.0/8"}, srv.URL, false},
+	} {
+		tr, err := NewFetchTransport(TransportConfig{PrivateHosts: tt.private})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := (&http.Client{Transport: tr}).Get(tt.url)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if tt.allowed != (err == nil) || (!tt.allowed && !errors.Is(err, ErrNotPublic)) {
+			t.Errorf("private %v: Get(%s) error = %v", tt.private, tt.url, err)
+		}
+	}
+
+	if _, err := NewFetchTransport(TransportConfig{PrivateHosts: []string{"10.0.0.0/33"}}); err == nil {
+		t.Error("NewFetchTransport() accepted an invalid range")
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":      true,
+		"2606:2800:220:1::":  true,
+		"127.0.0.1":          false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"100.64.0.1":         false,
+		"0.0.0.0":            false,
+		"::1":                false,
+		"fd00::1":            false,
+		"fe80::1":            false,
+		"::ffff:127.0.0.1":   false,
+		"64:ff9b::a9fe:a9fe": false, // NAT64 of 169.254.169.254
+		"64:ff9b::5db8:d822": true,
+		"224.0.0.1":          false,
+		"255.255.255.255":    false,
+	} {
+		if got := isPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublic(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
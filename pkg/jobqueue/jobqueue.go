@@ -0,0 +1,97 @@
+// Package jobqueue runs orchestration requests as queued jobs. In queue mode
+// POST /orchestrate publishes a job to NATS JetStream or SQS and answers
+// 202 Accepted, and a pool of workers in every orchestrator replica takes
+// jobs off the queue, so adding replicas adds throughput rather than just
+// concurrent HTTP handlers. Job state is kept in a Store, which must be
+// Redis when more than one replica serves GET /jobs/{id}.
+package jobqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// ErrNotFound is returned when no job exists for an ID
+var ErrNotFound = errors.New("job not found")
+
+// Message is a job taken off a queue by a worker
+type Message interface {
+	// Job returns the queued job
+	Job() *models.Job
+	// Ack removes the job from the queue once it has been handled
+	Ack(ctx context.Context) error
+	// Retry returns the job to the queue for another worker
+	Retry(ctx context.Context) error
+	// Extend keeps the job from being redelivered to another worker while
+	// a long run is still in progress
+	Extend(ctx context.Context) error
+}
+
+// Queue carries jobs from the replica that accepted a request to a worker
+type Queue interface {
+	// Publish adds a job to the queue
+	Publish(ctx context.Context, job *models.Job) error
+	// Receive waits for the next job. It returns nil and no error when no
+	// job arrived within the backend's poll interval.
+	Receive(ctx context.Context) (Message, error)
+	// Close disconnects from the queue
+	Close() error
+}
+
+// Store keeps the state of jobs for GET /jobs/{id}
+type Store interface {
+	// Put saves the current state of a job
+	Put(ctx context.Context, job *models.Job) error
+	// Get loads a job, or returns ErrNotFound
+	Get(ctx context.Context, id string) (*models.Job, error)
+}
+
+// NewQueue creates the queue selected by cfg.JobQueue. It returns a nil
+// queue when orchestration runs synchronously.
+func NewQueue(ctx context.Context, cfg *config.Config) (Queue, error) {
+	switch cfg.JobQueue {
+	case "", "none":
+		return nil, nil
+	case "memory":
+		return NewMemoryQueue(), nil
+	case "nats":
+		return NewNATSQueue(ctx, cfg.NATSURL, cfg.NATSSubject, jobTimeout(cfg))
+	case "sqs":
+		return NewSQSQueue(ctx, cfg.SQSQueueURL, jobTimeout(cfg))
+	default:
+		return nil, fmt.Errorf("unsupported job queue: %s (supported: none, memory, nats, sqs)", cfg.JobQueue)
+	}
+}
+
+// NewStore creates the job store selected by cfg.JobStoreBackend
+func NewStore(ctx context.Context, cfg *config.Config) (Store, error) {
+	ttl := time.Duration(cfg.JobTTLHours) * time.Hour
+	switch cfg.JobStoreBackend {
+	case "", "memory":
+		return NewMemoryStore(ttl), nil
+	case "redis":
+		return NewRedisStore(ctx, cfg.RedisURL, cfg.SessionKeyPrefix, ttl)
+	default:
+		return nil, fmt.Errorf("unsupported job store backend: %s (supported: memory, redis)", cfg.JobStoreBackend)
+	}
+}
+
+// jobTimeout is how long a worker may hold a job without extending it
+// before the queue hands it to another worker
+func jobTimeout(cfg *config.Config) time.Duration {
+	return time.Duration(max(cfg.JobTimeoutMinutes, 1)) * time.Minute
+}
+
+// newID returns a random job ID
+func newID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
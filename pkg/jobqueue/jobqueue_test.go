@@ -0,0 +1,150 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// waitDone polls the store until the job finishes
+func waitDone(t *testing.T, store Store, id string) *models.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := store.Get(context.Background(), id)
+		if err == nil && job.Done() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
+
+func TestRunnerRunsQueuedJobs(t *testing.T) {
+	tenants, err := tenant.New([]tenant.Tenant{{Name: "team", Key: "k"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+		if tenant.FromContext(ctx) == nil {
+			return nil, errors.New("tenant not carried to the worker")
+		}
+		if req.Topic == "fail" {
+			return nil, errors.New("research failed")
+		}
+		return &models.OrchestrationResponse{Topic: req.Topic, VerifiedCount: 3,
+			CostSummary: &models.CostSummary{Calls: 2, SearchCalls: 1}}, nil
+	}
+	store := NewMemoryStore(time.Hour)
+	r := New(NewMemoryQueue(), store, run, tenants, 2, 3, time.Minute, discard)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Start(ctx)
+
+	submitCtx := tenants.WithTenant(context.Background(), "team")
+	ok, err := r.Submit(submitCtx, &models.OrchestrationRequest{Topic: "solar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed, err := r.Submit(submitCtx, &models.OrchestrationRequest{Topic: "fail"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job := waitDone(t, store, ok.ID); job.Status != models.JobSucceeded || job.Response.VerifiedCount != 3 || job.Tenant != "team" || job.Attempts != 1 {
+		t.Errorf("job = %+v", job)
+	}
+	if job := waitDone(t, store, failed.ID); job.Status != models.JobFailed || job.Error != "research failed" {
+		t.Errorf("failed job = %+v", job)
+	}
+	if u := tenants.Usage()[0]; u.LLMCalls != 2 || u.SearchCalls != 1 {
+		t.Errorf("worker usage not charged to the tenant: %+v", u)
+	}
+}
+
+func TestRunnerAbandonsAfterMaxAttempts(t *testing.T) {
+	store := NewMemoryStore(0)
+	queue := NewMemoryQueue()
+	r := New(queue, store, nil, nil, 1, 2, time.Minute, discard)
+
+	job := &models.Job{ID: "j1", Status: models.JobRunning, Attempts: 2}
+	if err := store.Put(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	r.handle(context.Background(), &memoryMessage{queue: queue, job: job})
+
+	got, _ := store.Get(context.Background(), "j1")
+	if got.Status != models.JobFailed || got.Attempts != 3 {
+		t.Errorf("job = %+v", got)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	store := NewRedisStoreFromClient(client, "", time.Hour)
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) = %v; want ErrNotFound", err)
+	}
+	job := &models.Job{ID: "j1", Status: models.JobQueued, Request: models.OrchestrationRequest{Topic: "solar"}}
+	if err := store.Put(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(ctx, "j1")
+	if err != nil || got.Request.Topic != "solar" {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+	if ttl := mr.TTL("stats-agent:job:j1"); ttl != time.Hour {
+		t.Errorf("TTL = %v", ttl)
+	}
+}
+
+func TestHandlerHidesOtherTenantsJobs(t *testing.T) {
+	tenants, err := tenant.New([]tenant.Tenant{{Name: "a", Key: "ka"}, {Name: "b", Key: "kb"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemoryStore(0)
+	r := New(NewMemoryQueue(), store, nil, tenants, 0, 1, time.Minute, discard)
+	job, err := r.Submit(tenants.WithTenant(context.Background(), "a"), &models.OrchestrationRequest{Topic: "solar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := tenants.Middleware(r.Handler(discard))
+	get := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/jobs/"+job.ID, nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("ka")
+	var got models.Job
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || got.Status != models.JobQueued {
+		t.Errorf("owner GET = %d %+v %v", rec.Code, got, err)
+	}
+	if rec := get("kb"); rec.Code != http.StatusNotFound {
+		t.Errorf("other tenant GET = %d; want 404", rec.Code)
+	}
+}
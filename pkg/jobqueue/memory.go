@@ -0,0 +1,109 @@
+package jobqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// pollInterval is how long Receive waits for a job before returning nil
+const pollInterval = 5 * time.Second
+
+// MemoryQueue is a Queue within one process, for running workers without
+// a broker. Jobs are lost when the process exits.
+type MemoryQueue struct {
+	jobs chan *models.Job
+}
+
+// NewMemoryQueue creates an empty in-process queue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{jobs: make(chan *models.Job, 1024)}
+}
+
+// Publish implements Queue
+func (q *MemoryQueue) Publish(ctx context.Context, job *models.Job) error {
+	copied := *job
+	select {
+	case q.jobs <- &copied:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Receive implements Queue
+func (q *MemoryQueue) Receive(ctx context.Context) (Message, error) {
+	timer := time.NewTimer(pollInterval)
+	defer timer.Stop()
+	select {
+	case job := <-q.jobs:
+		return &memoryMessage{queue: q, job: job}, nil
+	case <-timer.C:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close implements Queue
+func (q *MemoryQueue) Close() error { return nil }
+
+type memoryMessage struct {
+	queue *MemoryQueue
+	job   *models.Job
+}
+
+func (m *memoryMessage) Job() *models.Job                { return m.job }
+func (m *memoryMessage) Ack(context.Context) error       { return nil }
+func (m *memoryMessage) Extend(context.Context) error    { return nil }
+func (m *memoryMessage) Retry(ctx context.Context) error { return m.queue.Publish(ctx, m.job) }
+
+// MemoryStore is a Store within one process
+type MemoryStore struct {
+	ttl time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*models.Job
+}
+
+// NewMemoryStore creates a store that forgets finished jobs after ttl; a
+// zero ttl keeps them
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{ttl: ttl, jobs: make(map[string]*models.Job)}
+}
+
+// Put implements Store
+func (s *MemoryStore) Put(_ context.Context, job *models.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expireLocked(time.Now())
+	copied := *job
+	s.jobs[job.ID] = &copied
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(_ context.Context, id string) (*models.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// expireLocked drops finished jobs older than the TTL. The caller must hold s.mu.
+func (s *MemoryStore) expireLocked(now time.Time) {
+	if s.ttl <= 0 {
+		return
+	}
+	for id, job := range s.jobs {
+		if job.Done() && now.Sub(job.FinishedAt) > s.ttl {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// natsConsumer is the durable consumer shared by every orchestrator's
+// workers, so each job is delivered to one of them
+const natsConsumer = "orchestrators"
+
+// NATSQueue is a Queue on a NATS JetStream work-queue stream
+type NATSQueue struct {
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	consumer jetstream.Consumer
+	subject  string
+}
+
+// NewNATSQueue connects to the NATS server at natsURL and creates, if
+// needed, a work-queue stream for subject and a durable consumer for it. A
+// job not acknowledged within ackWait is redelivered to another worker.
+func NewNATSQueue(ctx context.Context, natsURL, subject string, ackWait time.Duration) (*NATSQueue, error) {
+	if natsURL == "" {
+		return nil, fmt.Errorf("NATS_URL is required for the nats job queue")
+	}
+	if subject == "" {
+		subject = "stats.jobs"
+	}
+	conn, err := nats.Connect(natsURL, nats.Name("stats-agent-orchestrator"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open jetstream: %w", err)
+	}
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      streamName(subject),
+		Subjects:  []string{subject},
+		Retention: jetstream.WorkQueuePolicy,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create job stream: %w", err)
+	}
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:   natsConsumer,
+		AckPolicy: jetstream.AckExplicitPolicy,
+		AckWait:   ackWait,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create job consumer: %w", err)
+	}
+	return &NATSQueue{conn: conn, js: js, consumer: consumer, subject: subject}, nil
+}
+
+// streamName derives a stream name from a subject, e.g. STATS_JOBS for
+// stats.jobs
+func streamName(subject string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "*", "_", ">", "_").Replace(subject))
+}
+
+// Publish implements Queue
+func (q *NATSQueue) Publish(ctx context.Context, job *models.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	if _, err := q.js.Publish(ctx, q.subject, data, jetstream.WithMsgID(job.ID)); err != nil {
+		return fmt.Errorf("failed to publish job: %w", err)
+	}
+	return nil
+}
+
+// Receive implements Queue
+func (q *NATSQueue) Receive(ctx context.Context) (Message, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, pollInterval)
+	defer cancel()
+	msg, err := q.consumer.Next(jetstream.FetchContext(fetchCtx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to receive job: %w", err)
+	}
+	var job models.Job
+	if err := json.Unmarshal(msg.Data(), &job); err != nil {
+		// A message that can never be decoded would otherwise be redelivered forever
+		_ = msg.Term()
+		return nil, fmt.Errorf("invalid job message: %w", err)
+	}
+	return &natsMessage{msg: msg, job: &job}, nil
+}
+
+// Close implements Queue
+func (q *NATSQueue) Close() error {
+	return q.conn.Drain()
+}
+
+type natsMessage struct {
+	msg jetstream.Msg
+	job *models.Job
+}
+
+func (m *natsMessage) Job() *models.Job             { return m.job }
+func (m *natsMessage) Ack(context.Context) error    { return m.msg.Ack() }
+func (m *natsMessage) Retry(context.Context) error  { return m.msg.Nak() }
+func (m *natsMessage) Extend(context.Context) error { return m.msg.InProgress() }
@@ -0,0 +1,80 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// RedisStore is a Store shared by every replica connected to one Redis
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore connects to the Redis server at redisURL (for example
+// redis://localhost:6379/0). Jobs expire ttl after their last update; a
+// zero ttl keeps them.
+func NewRedisStore(ctx context.Context, redisURL, prefix string, ttl time.Duration) (*RedisStore, error) {
+	if redisURL == "" {
+		return nil, fmt.Errorf("REDIS_URL is required for the redis job store")
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return NewRedisStoreFromClient(client, prefix, ttl), nil
+}
+
+// NewRedisStoreFromClient creates a store using an existing client
+func NewRedisStoreFromClient(client *redis.Client, prefix string, ttl time.Duration) *RedisStore {
+	if prefix == "" {
+		prefix = "stats-agent:"
+	}
+	return &RedisStore{client: client, prefix: prefix + "job:", ttl: ttl}
+}
+
+// Close closes the Redis connection
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// Put implements Store
+func (s *RedisStore) Put(ctx context.Context, job *models.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	if err := s.client.Set(ctx, s.prefix+job.ID, data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+// Get implements Store
+func (s *RedisStore) Get(ctx context.Context, id string) (*models.Job, error) {
+	data, err := s.client.Get(ctx, s.prefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	var job models.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+	return &job, nil
+}
@@ -0,0 +1,292 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+// RunFunc runs one orchestration request
+type RunFunc func(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error)
+
+// Runner accepts orchestration requests as jobs and runs a pool of workers
+// taking them off the queue
+type Runner struct {
+	queue       Queue
+	store       Store
+	run         RunFunc
+	tenants     *tenant.Registry
+	workers     int
+	maxAttempts int
+	timeout     time.Duration
+	logger      *slog.Logger
+}
+
+// New creates a runner of workers workers, each running one job at a
+// time for at most timeout. A job whose worker stopped before finishing it
+// is retried until it has been started maxAttempts times.
+func New(queue Queue, store Store, run RunFunc, tenants *tenant.Registry, workers, maxAttempts int, timeout time.Duration, logger *slog.Logger) *Runner {
+	return &Runner{
+		queue:       queue,
+		store:       store,
+		run:         run,
+		tenants:     tenants,
+		workers:     workers,
+		maxAttempts: max(maxAttempts, 1),
+		timeout:     timeout,
+		logger:      logger,
+	}
+}
+
+// FromConfig creates the runner configured by JOB_QUEUE, JOB_WORKERS, and
+// the job store settings. It returns nil when JOB_QUEUE is unset, so
+// orchestration runs synchronously in the HTTP handler.
+func FromConfig(ctx context.Context, cfg *config.Config, run RunFunc, tenants *tenant.Registry, logger *slog.Logger) (*Runner, error) {
+	queue, err := NewQueue(ctx, cfg)
+	if err != nil || queue == nil {
+		return nil, err
+	}
+	store, err := NewStore(ctx, cfg)
+	if err != nil {
+		queue.Close()
+		return nil, err
+	}
+	return New(queue, store, run, tenants, cfg.JobWorkers, cfg.JobMaxAttempts, jobTimeout(cfg), logger), nil
+}
+
+// Submit queues req as a job for the tenant of ctx and returns it
+func (r *Runner) Submit(ctx context.Context, req *models.OrchestrationRequest) (*models.Job, error) {
+	job := &models.Job{
+		ID:        newID(),
+		Status:    models.JobQueued,
+		Request:   *req,
+		CreatedAt: time.Now(),
+	}
+	if t := tenant.FromContext(ctx); t != nil {
+		job.Tenant = t.Name
+	}
+	if err := r.store.Put(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := r.queue.Publish(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Accept queues a validated orchestration request and answers 202 Accepted
+// with the job, whose state is polled at the Location header's URL
+func (r *Runner) Accept(w http.ResponseWriter, req *http.Request, orchReq *models.OrchestrationRequest) {
+	job, err := r.Submit(req.Context(), orchReq)
+	if err != nil {
+		r.logger.Error("failed to queue job", "error", err)
+		http.Error(w, "failed to queue job", http.StatusServiceUnavailable)
+		return
+	}
+	r.logger.Info("job queued", "job_id", job.ID, "topic", orchReq.Topic)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		r.logger.Error("failed to encode job", "error", err)
+	}
+}
+
+// Start runs the workers until ctx ends. With no workers the replica only
+// accepts jobs, leaving them to other replicas.
+func (r *Runner) Start(ctx context.Context) {
+	r.logger.Info("job workers started", "workers", r.workers)
+	var wg sync.WaitGroup
+	for range r.workers {
+		wg.Go(func() { r.work(ctx) })
+	}
+	wg.Wait()
+	if err := r.queue.Close(); err != nil {
+		r.logger.Warn("failed to close job queue", "error", err)
+	}
+}
+
+// work takes jobs off the queue one at a time until ctx ends
+func (r *Runner) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		msg, err := r.queue.Receive(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.logger.Warn("failed to receive job", "error", err)
+				sleep(ctx, pollInterval)
+			}
+			continue
+		}
+		if msg != nil {
+			r.handle(ctx, msg)
+		}
+	}
+}
+
+// handle runs one job and records its outcome. A job interrupted by the
+// worker stopping is returned to the queue rather than failed.
+func (r *Runner) handle(ctx context.Context, msg Message) {
+	job := msg.Job()
+	logger := r.logger.With("job_id", job.ID)
+
+	if stored, err := r.store.Get(ctx, job.ID); err == nil {
+		if stored.Done() {
+			// Redelivered after it finished, e.g. when the ack was lost
+			r.ack(ctx, msg, logger)
+			return
+		}
+		job.Attempts = stored.Attempts
+	}
+	job.Attempts++
+	if job.Attempts > r.maxAttempts {
+		r.finish(ctx, msg, job, nil, fmt.Errorf("abandoned after %d attempts", r.maxAttempts), logger)
+		return
+	}
+
+	job.Status = models.JobRunning
+	job.StartedAt = time.Now()
+	if err := r.store.Put(ctx, job); err != nil {
+		logger.Warn("failed to save job state", "error", err)
+	}
+	logger.Info("job started", "topic", job.Request.Topic, "attempt", job.Attempts)
+
+	runCtx, cancel := context.WithTimeout(r.tenants.WithTenant(ctx, job.Tenant), r.timeout)
+	stopExtending := r.extend(runCtx, msg, logger)
+	resp, err := r.run(runCtx, &job.Request)
+	stopExtending()
+	cancel()
+
+	if ctx.Err() != nil {
+		// Shutting down: hand the job to another worker
+		retryCtx, cancelRetry := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancelRetry()
+		if err := msg.Retry(retryCtx); err != nil {
+			logger.Warn("failed to return job to the queue", "error", err)
+		}
+		return
+	}
+	if err == nil {
+		tenant.Charge(runCtx, resp.CostSummary)
+	}
+	r.finish(ctx, msg, job, resp, err, logger)
+}
+
+// finish records the outcome of a job and removes it from the queue
+func (r *Runner) finish(ctx context.Context, msg Message, job *models.Job, resp *models.OrchestrationResponse, runErr error, logger *slog.Logger) {
+	job.FinishedAt = time.Now()
+	if runErr != nil {
+		job.Status = models.JobFailed
+		job.Error = runErr.Error()
+		logger.Warn("job failed", "error", runErr)
+	} else {
+		job.Status = models.JobSucceeded
+		job.Response = resp
+		logger.Info("job succeeded", "verified", resp.VerifiedCount)
+	}
+	if err := r.store.Put(ctx, job); err != nil {
+		// Leave the job queued so its outcome is not lost
+		logger.Error("failed to save job result", "error", err)
+		if err := msg.Retry(ctx); err != nil {
+			logger.Warn("failed to return job to the queue", "error", err)
+		}
+		return
+	}
+	r.ack(ctx, msg, logger)
+}
+
+func (r *Runner) ack(ctx context.Context, msg Message, logger *slog.Logger) {
+	if err := msg.Ack(ctx); err != nil {
+		logger.Warn("failed to acknowledge job", "error", err)
+	}
+}
+
+// extend keeps a running job from being redelivered until the returned
+// function is called
+func (r *Runner) extend(ctx context.Context, msg Message, logger *slog.Logger) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(max(r.timeout/3, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := msg.Extend(ctx); err != nil {
+					logger.Warn("failed to extend job", "error", err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// Handler returns the GET /jobs/{id} handler. Tenants only see their own
+// jobs; admins see every job.
+func (r *Runner) Handler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r == nil {
+			http.Error(w, "job queue is not configured; set JOB_QUEUE", http.StatusNotFound)
+			return
+		}
+		id := strings.Trim(strings.TrimPrefix(req.URL.Path, "/jobs"), "/")
+		job, err := r.Job(req.Context(), id)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to load job", "job_id", id, "error", err)
+			http.Error(w, "failed to load job", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(job); err != nil {
+			logger.Error("failed to encode job", "error", err)
+		}
+	}
+}
+
+// Job loads a job the caller may see, returning ErrNotFound for another
+// tenant's job
+func (r *Runner) Job(ctx context.Context, id string) (*models.Job, error) {
+	job, err := r.store.Get(ctx, id)
+	if err == nil && !visible(ctx, job) {
+		return nil, ErrNotFound
+	}
+	return job, err
+}
+
+// visible reports whether the caller may see a job
+func visible(ctx context.Context, job *models.Job) bool {
+	t := tenant.FromContext(ctx)
+	return t == nil || t.Admin || t.Name == job.Tenant
+}
+
+// sleep waits for d or until ctx ends
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,123 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// SQSQueue is a Queue on an Amazon SQS queue
+type SQSQueue struct {
+	client     *sqs.Client
+	queueURL   string
+	visibility time.Duration
+}
+
+// NewSQSQueue creates an SQS queue client using the default AWS credential
+// chain. A received job stays hidden from other workers for visibility,
+// and is redelivered if it is neither deleted nor extended by then.
+func NewSQSQueue(ctx context.Context, queueURL string, visibility time.Duration) (*SQSQueue, error) {
+	if queueURL == "" {
+		return nil, fmt.Errorf("SQS_QUEUE_URL is required for the sqs job queue")
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &SQSQueue{
+		client:     sqs.NewFromConfig(awsCfg),
+		queueURL:   queueURL,
+		visibility: visibility,
+	}, nil
+}
+
+// Publish implements Queue
+func (q *SQSQueue) Publish(ctx context.Context, job *models.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	if _, err := q.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.queueURL),
+		MessageBody: aws.String(string(data)),
+	}); err != nil {
+		return fmt.Errorf("failed to publish job: %w", err)
+	}
+	return nil
+}
+
+// Receive implements Queue
+func (q *SQSQueue) Receive(ctx context.Context) (Message, error) {
+	out, err := q.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(q.queueURL),
+		MaxNumberOfMessages: 1,
+		WaitTimeSeconds:     int32(pollInterval / time.Second),
+		VisibilityTimeout:   q.visibilitySeconds(),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to receive job: %w", err)
+	}
+	if len(out.Messages) == 0 {
+		return nil, nil
+	}
+	msg := out.Messages[0]
+	var job models.Job
+	if err := json.Unmarshal([]byte(aws.ToString(msg.Body)), &job); err != nil {
+		// Leave undecodable messages to the queue's redrive policy
+		return nil, fmt.Errorf("invalid job message: %w", err)
+	}
+	return &sqsMessage{queue: q, receipt: msg.ReceiptHandle, job: &job}, nil
+}
+
+// Close implements Queue
+func (q *SQSQueue) Close() error { return nil }
+
+// visibilitySeconds is the visibility timeout in the form SQS takes, capped
+// at its 12-hour maximum
+func (q *SQSQueue) visibilitySeconds() int32 {
+	return int32(min(q.visibility, 12*time.Hour) / time.Second) //nolint:gosec // G115: capped above
+}
+
+type sqsMessage struct {
+	queue   *SQSQueue
+	receipt *string
+	job     *models.Job
+}
+
+func (m *sqsMessage) Job() *models.Job { return m.job }
+
+func (m *sqsMessage) Ack(ctx context.Context) error {
+	_, err := m.queue.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(m.queue.queueURL),
+		ReceiptHandle: m.receipt,
+	})
+	return err
+}
+
+// Retry makes the job visible to other workers again right away
+func (m *sqsMessage) Retry(ctx context.Context) error {
+	return m.setVisibility(ctx, 0)
+}
+
+func (m *sqsMessage) Extend(ctx context.Context) error {
+	return m.setVisibility(ctx, m.queue.visibilitySeconds())
+}
+
+func (m *sqsMessage) setVisibility(ctx context.Context, seconds int32) error {
+	_, err := m.queue.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(m.queue.queueURL),
+		ReceiptHandle:     m.receipt,
+		VisibilityTimeout: seconds,
+	})
+	return err
+}
@@ -10,11 +10,14 @@ import (
 	"github.com/plexusone/omnillm/provider"
 	"google.golang.org/adk/model"
 	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
 )
 
 // OmniLLMAdapterConfig holds configuration for creating a OmniLLM adapter
 type OmniLLMAdapterConfig struct {
 	ProviderName      string
+	BaseURL           string // Custom endpoint, e.g. for OpenAI-compatible providers
 	APIKey            string `json:"-"` //nolint:gosec // G117: field name, not a hardcoded credential
 	ModelName         string
 	Timeout           time.Duration // HTTP timeout for API calls (0 = provider default)
@@ -48,7 +51,11 @@ func NewOmniLLMAdapterWithConfig(cfg OmniLLMAdapterConfig) (*OmniLLMAdapter, err
 			{
 				Provider: omnillm.ProviderName(cfg.ProviderName),
 				APIKey:   cfg.APIKey,
+				BaseURL:  cfg.BaseURL,
 				Timeout:  cfg.Timeout,
+				// Thin providers use this client; the SDK-based ones use
+				// http.DefaultTransport, which httpclient.Configure also sets
+				HTTPClient: httpclient.New(cfg.Timeout),
 			},
 		},
 		ObservabilityHook: cfg.ObservabilityHook,
@@ -73,33 +80,12 @@ func (m *OmniLLMAdapter) Name() string {
 // GenerateContent implements the LLM interface
 func (m *OmniLLMAdapter) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
 	return func(yield func(*model.LLMResponse, error) bool) {
-		// Convert ADK request to OmniLLM request
-		messages := make([]provider.Message, 0)
-
-		for _, content := range req.Contents {
-			var text string
-			for _, part := range content.Parts {
-				text += part.Text
-			}
-
-			role := provider.RoleUser
-			if content.Role == "model" || content.Role == "assistant" {
-				role = provider.RoleAssistant
-			} else if content.Role == "system" {
-				role = provider.RoleSystem
-			}
-
-			messages = append(messages, provider.Message{
-				Role:    role,
-				Content: text,
-			})
-		}
-
 		// Create OmniLLM request
 		omniReq := &provider.ChatCompletionRequest{
 			Model:    m.model,
-			Messages: messages,
+			Messages: toMessages(req),
 		}
+		applyConfig(omniReq, req.Config)
 
 		// Call OmniLLM API
 		// Note: The observability hook is called automatically by the ChatClient
@@ -111,7 +97,8 @@ func (m *OmniLLMAdapter) GenerateContent(ctx context.Context, req *model.LLMRequ
 			return
 		}
 
-		// Convert OmniLLM response to ADK response
+		// Convert OmniLLM response to ADK response, keeping token usage in
+		// the same form the Gemini model reports it
 		if len(resp.Choices) > 0 {
 			adkResp := &model.LLMResponse{
 				Content: &genai.Content{
@@ -119,8 +106,60 @@ func (m *OmniLLMAdapter) GenerateContent(ctx context.Context, req *model.LLMRequ
 						{Text: resp.Choices[0].Message.Content},
 					},
 				},
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount:     int32(resp.Usage.PromptTokens),     //nolint:gosec // G115: token counts fit in int32
+					CandidatesTokenCount: int32(resp.Usage.CompletionTokens), //nolint:gosec // G115: token counts fit in int32
+					TotalTokenCount:      int32(resp.Usage.TotalTokens),      //nolint:gosec // G115: token counts fit in int32
+				},
+				ModelVersion: resp.Model,
 			}
 			yield(adkResp, nil)
 		}
 	}
 }
+
+// applyConfig carries the generation settings of an ADK request over to
+// OmniLLM. Providers without a seed parameter ignore it.
+func applyConfig(omniReq *provider.ChatCompletionRequest, cfg *genai.GenerateContentConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.Temperature != nil {
+		omniReq.Temperature = genai.Ptr(float64(*cfg.Temperature))
+	}
+	if cfg.TopP != nil {
+		omniReq.TopP = genai.Ptr(float64(*cfg.TopP))
+	}
+	if cfg.MaxOutputTokens > 0 {
+		omniReq.MaxTokens = genai.Ptr(int(cfg.MaxOutputTokens))
+	}
+	if cfg.Seed != nil {
+		omniReq.Seed = genai.Ptr(int(*cfg.Seed))
+	}
+}
+
+// toMessages converts the contents of an ADK request to OmniLLM messages,
+// joining the text parts of each content
+func toMessages(req *model.LLMRequest) []provider.Message {
+	messages := make([]provider.Message, 0, len(req.Contents))
+
+	for _, content := range req.Contents {
+		var text string
+		for _, part := range content.Parts {
+			text += part.Text
+		}
+
+		role := provider.RoleUser
+		if content.Role == "model" || content.Role == "assistant" {
+			role = provider.RoleAssistant
+		} else if content.Role == "system" {
+			role = provider.RoleSystem
+		}
+
+		messages = append(messages, provider.Message{
+			Role:    role,
+			Content: text,
+		})
+	}
+	return messages
+}
@@ -0,0 +1,107 @@
+package adapters
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omnillm"
+	"github.com/plexusone/omnillm/provider"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// tracedModel wraps a model.LLM that is not built on OmniLLM and reports
+// every call to an OmniLLM observability hook
+type tracedModel struct {
+	model.LLM
+	provider string
+	hook     omnillm.ObservabilityHook
+}
+
+// Trace returns a model whose calls are reported to hook with their
+// prompt, response, latency, and token usage, as OmniLLM reports the calls
+// of its own clients. It is for models such as Gemini that do not go through
+// an OmniLLM adapter; m is returned unchanged when hook is nil.
+func Trace(m model.LLM, providerName string, hook omnillm.ObservabilityHook) model.LLM {
+	if hook == nil {
+		return m
+	}
+	return &tracedModel{LLM: m, provider: providerName, hook: hook}
+}
+
+// GenerateContent implements model.LLM
+func (m *tracedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		info := omnillm.LLMCallInfo{
+			CallID:       newCallID(),
+			ProviderName: m.provider,
+			StartTime:    time.Now(),
+		}
+		omniReq := &provider.ChatCompletionRequest{
+			Model:    m.Name(),
+			Messages: toMessages(req),
+		}
+		if req.Config != nil && req.Config.SystemInstruction != nil {
+			system := toMessages(&model.LLMRequest{Contents: []*genai.Content{req.Config.SystemInstruction}})
+			system[0].Role = provider.RoleSystem
+			omniReq.Messages = append(system, omniReq.Messages...)
+		}
+		ctx = m.hook.BeforeRequest(ctx, info, omniReq)
+
+		var (
+			text    strings.Builder
+			resp    = &provider.ChatCompletionResponse{Model: m.Name()}
+			callErr error
+		)
+		defer func() {
+			if callErr != nil {
+				m.hook.AfterResponse(ctx, info, omniReq, nil, callErr)
+				return
+			}
+			resp.Choices = []provider.ChatCompletionChoice{{
+				Message: provider.Message{Role: provider.RoleAssistant, Content: text.String()},
+			}}
+			m.hook.AfterResponse(ctx, info, omniReq, resp, nil)
+		}()
+
+		for r, err := range m.LLM.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				callErr = err
+			} else if r != nil && !r.Partial {
+				// Streaming calls end with a non-partial response holding
+				// the whole text, so partial chunks are not counted twice
+				if r.Content != nil {
+					for _, part := range r.Content.Parts {
+						text.WriteString(part.Text)
+					}
+				}
+				if r.ModelVersion != "" {
+					resp.Model = r.ModelVersion
+				}
+				if md := r.UsageMetadata; md != nil {
+					resp.Usage = provider.Usage{
+						PromptTokens:     int(md.PromptTokenCount),
+						CompletionTokens: int(md.CandidatesTokenCount),
+						TotalTokens:      int(md.TotalTokenCount),
+					}
+				}
+			}
+			if !yield(r, err) {
+				return
+			}
+		}
+	}
+}
+
+// newCallID returns a random identifier correlating a call's trace events
+func newCallID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,111 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/plexusone/omnillm"
+	"github.com/plexusone/omnillm/provider"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+type fakeModel struct {
+	responses []*model.LLMResponse
+	err       error
+}
+
+func (f *fakeModel) Name() string { return "fake-model" }
+
+func (f *fakeModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for _, r := range f.responses {
+			if !yield(r, nil) {
+				return
+			}
+		}
+		if f.err != nil {
+			yield(nil, f.err)
+		}
+	}
+}
+
+type fakeHook struct {
+	before int
+	req    *provider.ChatCompletionRequest
+	resp   *provider.ChatCompletionResponse
+	err    error
+	after  int
+}
+
+func (h *fakeHook) BeforeRequest(ctx context.Context, info omnillm.LLMCallInfo, req *provider.ChatCompletionRequest) context.Context {
+	h.before++
+	h.req = req
+	return ctx
+}
+
+func (h *fakeHook) AfterResponse(ctx context.Context, info omnillm.LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	h.after++
+	h.resp = resp
+	h.err = err
+}
+
+func (h *fakeHook) WrapStream(ctx context.Context, info omnillm.LLMCallInfo, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream {
+	return stream
+}
+
+func drain(m model.LLM, req *model.LLMRequest) {
+	for range m.GenerateContent(context.Background(), req, true) { //nolint:revive // draining the sequence
+	}
+}
+
+func TestTraceReportsStreamedCall(t *testing.T) {
+	hook := &fakeHook{}
+	m := Trace(&fakeModel{responses: []*model.LLMResponse{
+		{Content: genai.NewContentFromText("Hel", genai.RoleModel), Partial: true},
+		{
+			Content:       genai.NewContentFromText("Hello", genai.RoleModel),
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 7, CandidatesTokenCount: 2, TotalTokenCount: 9},
+		},
+	}}, "gemini", hook)
+
+	drain(m, &model.LLMRequest{
+		Contents: []*genai.Content{genai.NewContentFromText("Say hello", genai.RoleUser)},
+		Config:   &genai.GenerateContentConfig{SystemInstruction: genai.NewContentFromText("Be brief", genai.RoleUser)},
+	})
+
+	if hook.before != 1 || hook.after != 1 {
+		t.Fatalf("hook called %d/%d times, want 1/1", hook.before, hook.after)
+	}
+	if len(hook.req.Messages) != 2 || hook.req.Messages[0].Role != provider.RoleSystem || hook.req.Messages[1].Content != "Say hello" {
+		t.Errorf("request messages = %+v", hook.req.Messages)
+	}
+	if hook.err != nil || hook.resp == nil {
+		t.Fatalf("AfterResponse got resp %v, err %v", hook.resp, hook.err)
+	}
+	if got := hook.resp.Choices[0].Message.Content; got != "Hello" {
+		t.Errorf("response text = %q, want %q", got, "Hello")
+	}
+	if hook.resp.Usage.PromptTokens != 7 || hook.resp.Usage.CompletionTokens != 2 || hook.resp.Usage.TotalTokens != 9 {
+		t.Errorf("usage = %+v", hook.resp.Usage)
+	}
+}
+
+func TestTraceReportsError(t *testing.T) {
+	hook := &fakeHook{}
+	wantErr := errors.New("quota exceeded")
+	drain(Trace(&fakeModel{err: wantErr}, "gemini", hook), &model.LLMRequest{})
+
+	if hook.after != 1 || !errors.Is(hook.err, wantErr) || hook.resp != nil {
+		t.Errorf("AfterResponse got resp %v, err %v after %d calls", hook.resp, hook.err, hook.after)
+	}
+}
+
+func TestTraceWithoutHook(t *testing.T) {
+	m := &fakeModel{}
+	if got := Trace(m, "gemini", nil); got != m {
+		t.Error("Trace with a nil hook should return the model unchanged")
+	}
+}
@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/tokens"
+)
+
+// DefaultOutputTokens is the room reserved for a completion when no
+// max_tokens is set, at most a quarter of the model's context window
+const DefaultOutputTokens = 4096
+
+// RunBudget returns the prompt budget of the model a stage uses for a run.
+// The completion gets max_tokens from the generation settings the call would
+// use: the stage's and g for the stage's model, only g for the run's model
+// override. Context windows come from LLM_CONTEXT_WINDOWS over the built-in
+// table.
+func RunBudget(cfg *config.Config, stage Stage, o *models.ModelOverride, g *models.Generation) (tokens.Budget, error) {
+	overrides, err := tokens.ParseWindows(cfg.LLMContextWindows)
+	if err != nil {
+		return tokens.Budget{}, err
+	}
+	if o.IsZero() {
+		stageGen, err := StageGeneration(cfg, stage)
+		if err != nil {
+			return tokens.Budget{}, err
+		}
+		g = stageGen.Merge(g)
+	}
+
+	b := tokens.Budget{}
+	b.Provider, b.Model = RunStageModel(cfg, stage, o)
+	b.Window = overrides.Lookup(b.Provider, b.Model)
+	b.Output = min(DefaultOutputTokens, b.Window/4)
+	if g != nil && g.MaxTokens > 0 {
+		b.Output = g.MaxTokens
+	}
+	return b, nil
+}
+
+// Budget returns the prompt budget of the model a stage uses for a run
+// under the factory's current configuration
+func (mf *ModelFactory) Budget(stage Stage, o *models.ModelOverride, g *models.Generation) (tokens.Budget, error) {
+	return RunBudget(mf.config(), stage, o, g)
+}
+
+type budgetKey struct{}
+
+// WithBudget returns a context whose prompts are sized to b
+func WithBudget(ctx context.Context, b tokens.Budget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, b)
+}
+
+// BudgetFrom returns the budget carried by ctx. The second result is false
+// if ctx carries none.
+func BudgetFrom(ctx context.Context) (tokens.Budget, bool) {
+	b, ok := ctx.Value(budgetKey{}).(tokens.Budget)
+	return b, ok
+}
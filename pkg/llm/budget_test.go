@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"testing"
+
+	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestRunBudget(t *testing.T) {
+	cfg := &config.Config{
+		Config:              &akconfig.Config{LLMProvider: "gemini", LLMModel: "gemini-2.5-flash"},
+		SynthesisModel:      "ollama:llama3.2",
+		SynthesisGeneration: "max_tokens=2048",
+		LLMContextWindows:   []string{"llama3.2=16384"},
+	}
+
+	b, err := RunBudget(cfg, StageSynthesis, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Provider != "ollama" || b.Window != 16384 || b.Output != 2048 {
+		t.Errorf("stage budget = %+v, want the configured window and max_tokens", b)
+	}
+
+	b, err = RunBudget(cfg, StageSynthesis, &models.ModelOverride{Model: "gemini-2.5-pro"}, &models.Generation{MaxTokens: 8192})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Model != "gemini-2.5-pro" || b.Window != 1_048_576 || b.Output != 8192 {
+		t.Errorf("override budget = %+v, want the override's window and the run's max_tokens", b)
+	}
+
+	b, err = RunBudget(cfg, StageVerification, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Output != DefaultOutputTokens {
+		t.Errorf("verification output = %d, want %d", b.Output, DefaultOutputTokens)
+	}
+
+	cfg.LLMContextWindows = []string{"llama3.2"}
+	if _, err := RunBudget(cfg, StageSynthesis, nil, nil); err == nil {
+		t.Error("RunBudget() with an invalid window should fail")
+	}
+}
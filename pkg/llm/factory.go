@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/grokify/mogo/log/slogutil"
@@ -15,7 +17,10 @@ import (
 	"google.golang.org/genai"
 
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
 	"github.com/plexusone/agent-team-stats/pkg/llm/adapters"
+	"github.com/plexusone/agent-team-stats/pkg/replay"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
 
 	// Import observability providers (driver registration via init())
 	// TODO: move to build tags for smaller binaries
@@ -26,10 +31,14 @@ import (
 
 // ModelFactory creates LLM models based on configuration
 type ModelFactory struct {
-	cfg      *config.Config
+	cfg      atomic.Pointer[config.Config] // Swapped by Reload
 	logger   *slog.Logger
 	obsHook  omnillm.ObservabilityHook
 	obsClose func() error
+
+	mu        sync.Mutex
+	overrides map[string]model.LLM // Per-request override models by "provider:model"
+	reloaded  []*reloadableModel   // Models recreated by Reload
 }
 
 // NewModelFactory creates a new model factory.
@@ -37,9 +46,9 @@ type ModelFactory struct {
 func NewModelFactory(ctx context.Context, cfg *config.Config) *ModelFactory {
 	logger := slogutil.LoggerFromContext(ctx, slog.Default())
 	mf := &ModelFactory{
-		cfg:    cfg,
 		logger: logger.With("component", "model-factory"),
 	}
+	mf.cfg.Store(cfg)
 
 	// Initialize observability if enabled
 	if cfg.ObservabilityEnabled && cfg.ObservabilityProvider != "" {
@@ -53,41 +62,42 @@ func NewModelFactory(ctx context.Context, cfg *config.Config) *ModelFactory {
 
 // initObservability initializes the observability provider and returns a hook
 func (mf *ModelFactory) initObservability() (omnillm.ObservabilityHook, func() error) {
+	cfg := mf.config()
 	opts := []llmops.ClientOption{
-		llmops.WithProjectName(mf.cfg.ObservabilityProject),
+		llmops.WithProjectName(cfg.ObservabilityProject),
 	}
 
-	if mf.cfg.ObservabilityAPIKey != "" {
-		opts = append(opts, llmops.WithAPIKey(mf.cfg.ObservabilityAPIKey))
+	if cfg.ObservabilityAPIKey != "" {
+		opts = append(opts, llmops.WithAPIKey(cfg.ObservabilityAPIKey))
 	}
 
-	if mf.cfg.ObservabilityEndpoint != "" {
-		opts = append(opts, llmops.WithEndpoint(mf.cfg.ObservabilityEndpoint))
+	if cfg.ObservabilityEndpoint != "" {
+		opts = append(opts, llmops.WithEndpoint(cfg.ObservabilityEndpoint))
 	}
 
-	if mf.cfg.ObservabilityWorkspace != "" {
-		opts = append(opts, llmops.WithWorkspace(mf.cfg.ObservabilityWorkspace))
+	if cfg.ObservabilityWorkspace != "" {
+		opts = append(opts, llmops.WithWorkspace(cfg.ObservabilityWorkspace))
 	}
 
-	provider, err := llmops.Open(mf.cfg.ObservabilityProvider, opts...)
+	provider, err := llmops.Open(cfg.ObservabilityProvider, opts...)
 	if err != nil {
 		// Log warning but don't fail - observability is optional
 		mf.logger.Warn("failed to initialize observability provider",
-			"provider", mf.cfg.ObservabilityProvider,
+			"provider", cfg.ObservabilityProvider,
 			"error", err)
 		return nil, nil
 	}
 
 	// Ensure project exists (some providers require this)
 	ctx := context.Background()
-	if _, err = provider.CreateProject(ctx, mf.cfg.ObservabilityProject); err != nil {
+	if _, err = provider.CreateProject(ctx, cfg.ObservabilityProject); err != nil {
 		// Ignore error - project may already exist
 		mf.logger.Debug("CreateProject returned error (may already exist)", "error", err)
 	}
 
 	// Set the project as active
-	if err := provider.SetProject(ctx, mf.cfg.ObservabilityProject); err != nil {
-		mf.logger.Warn("failed to set observability project", "project", mf.cfg.ObservabilityProject, "error", err)
+	if err := provider.SetProject(ctx, cfg.ObservabilityProject); err != nil {
+		mf.logger.Warn("failed to set observability project", "project", cfg.ObservabilityProject, "error", err)
 	}
 
 	hook := omnillmhook.NewHook(provider)
@@ -107,61 +117,184 @@ func (mf *ModelFactory) Close() error {
 	return nil
 }
 
-// CreateModel creates an LLM model based on the configured provider
+// config returns the current configuration
+func (mf *ModelFactory) config() *config.Config {
+	return mf.cfg.Load()
+}
+
+// CreateModel creates an LLM model based on the configured provider. The
+// model picks up new credentials when the factory is reloaded.
 func (mf *ModelFactory) CreateModel(ctx context.Context) (model.LLM, error) {
-	switch mf.cfg.LLMProvider {
+	m, err := mf.createStageModel(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return mf.track("", m), nil
+}
+
+// CreateModelFor creates the model configured for a pipeline stage, falling
+// back to the default LLM_PROVIDER/LLM_MODEL when the stage has no model set.
+// The model picks up new credentials when the factory is reloaded.
+func (mf *ModelFactory) CreateModelFor(ctx context.Context, stage Stage) (model.LLM, error) {
+	m, err := mf.createStageModel(ctx, stage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s model: %w", stageName(stage), err)
+	}
+	return mf.track(stage, m), nil
+}
+
+// createStageModel creates the model currently configured for a stage,
+// generating with the stage's configured settings
+func (mf *ModelFactory) createStageModel(ctx context.Context, stage Stage) (model.LLM, error) {
+	gen, err := StageGeneration(mf.config(), stage)
+	if err != nil {
+		return nil, err
+	}
+	provider, modelName := mf.StageModel(stage)
+	m, err := mf.CreateModelWith(ctx, provider, modelName)
+	if err != nil || gen == nil {
+		return m, err
+	}
+	return withGeneration(m, gen), nil
+}
+
+// CreateModelWith creates a model for an explicit provider and model name,
+// using the provider's default model when modelName is empty. Credentials
+// still come from the configuration. Token usage of every call is recorded
+// in the usage tracker carried by the call's context, calls made with
+// WithGeneration or WithSampling use its settings, and calls are recorded or
+// replayed when LLM_REPLAY_MODE is set.
+func (mf *ModelFactory) CreateModelWith(ctx context.Context, provider, modelName string) (model.LLM, error) {
+	cfg := mf.config()
+	if provider == "" {
+		provider = "gemini"
+	}
+	if modelName == "" {
+		modelName = DefaultModel(provider)
+	}
+
+	// Replay answers from recorded fixtures, so no provider (or API key) is needed
+	if cfg.LLMReplayMode == replay.ModeReplay {
+		m, err := replay.Wrap(nil, replay.ModeReplay, cfg.LLMReplayDir, provider, modelName)
+		if err != nil {
+			return nil, err
+		}
+		return usage.Wrap(m, provider, modelName, usage.Record), nil
+	}
+
+	var m model.LLM
+	var err error
+	switch provider {
+	case "gemini":
+		m, err = mf.createGeminiModel(ctx, modelName)
+	case "claude":
+		m, err = mf.createClaudeModel(modelName)
+	case "openai":
+		m, err = mf.createOpenAIModel(modelName)
+	case "xai":
+		m, err = mf.createXAIModel(modelName)
+	case "ollama":
+		m, err = mf.createOllamaModel(modelName)
+	case "groq", "mistral", "deepseek":
+		m, err = mf.createCompatModel(provider, modelName)
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %s (supported: gemini, claude, openai, xai, ollama, groq, mistral, deepseek)", provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m = withGeneration(m, nil)
+	if cfg.LLMReplayMode != "" {
+		if m, err = replay.Wrap(m, cfg.LLMReplayMode, cfg.LLMReplayDir, provider, modelName); err != nil {
+			return nil, err
+		}
+	}
+	return usage.Wrap(m, provider, modelName, usage.Record), nil
+}
+
+// DefaultModel returns the model used for a provider when none is configured
+func DefaultModel(provider string) string {
+	switch provider {
 	case "gemini", "":
-		return mf.createGeminiModel(ctx)
+		return "gemini-2.0-flash-exp"
 	case "claude":
-		return mf.createClaudeModel()
+		return "claude-3-5-sonnet-20241022"
 	case "openai":
-		return mf.createOpenAIModel()
+		return "gpt-4o-mini" // Use mini for cost efficiency
 	case "xai":
-		return mf.createXAIModel()
+		return "grok-3"
 	case "ollama":
-		return mf.createOllamaModel()
+		return "llama3.2"
+	case "groq":
+		return "llama-3.3-70b-versatile"
+	case "mistral":
+		return "mistral-small-latest"
+	case "deepseek":
+		return "deepseek-chat"
 	default:
-		return nil, fmt.Errorf("unsupported LLM provider: %s (supported: gemini, claude, openai, xai, ollama)", mf.cfg.LLMProvider)
+		return ""
 	}
 }
 
 // createGeminiModel creates a Gemini model
-func (mf *ModelFactory) createGeminiModel(ctx context.Context) (model.LLM, error) {
-	apiKey := mf.cfg.GeminiAPIKey
+func (mf *ModelFactory) createGeminiModel(ctx context.Context, modelName string) (model.LLM, error) {
+	cfg := mf.config()
+	if cfg.GeminiBackend == "vertexai" {
+		return mf.createVertexModel(ctx, modelName)
+	}
+
+	apiKey := cfg.GeminiAPIKey
 	if apiKey == "" {
-		apiKey = mf.cfg.LLMAPIKey
+		apiKey = cfg.LLMAPIKey
 	}
 
 	if apiKey == "" {
 		return nil, fmt.Errorf("gemini API key not set - please set GOOGLE_API_KEY or GEMINI_API_KEY")
 	}
 
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "gemini-2.0-flash-exp"
+	m, err := gemini.NewModel(ctx, modelName, &genai.ClientConfig{
+		APIKey:     apiKey,
+		HTTPClient: httpclient.New(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The Gemini model does not go through OmniLLM, so it is traced here
+	return adapters.Trace(m, "gemini", mf.obsHook), nil
+}
+
+// createVertexModel creates a Gemini model served by Vertex AI. Credentials
+// come from Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS,
+// workload identity, or gcloud), so no API key is needed.
+func (mf *ModelFactory) createVertexModel(ctx context.Context, modelName string) (model.LLM, error) {
+	cfg := mf.config()
+	if cfg.VertexProject == "" {
+		return nil, fmt.Errorf("vertex AI project not set - please set GOOGLE_CLOUD_PROJECT")
 	}
 
-	return gemini.NewModel(ctx, modelName, &genai.ClientConfig{
-		APIKey: apiKey,
+	m, err := gemini.NewModel(ctx, modelName, &genai.ClientConfig{
+		Backend:  genai.BackendVertexAI,
+		Project:  cfg.VertexProject,
+		Location: cfg.VertexLocation,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return adapters.Trace(m, "vertexai", mf.obsHook), nil
 }
 
 // createClaudeModel creates a Claude model using OmniLLM
-func (mf *ModelFactory) createClaudeModel() (model.LLM, error) {
-	apiKey := mf.cfg.ClaudeAPIKey
+func (mf *ModelFactory) createClaudeModel(modelName string) (model.LLM, error) {
+	cfg := mf.config()
+	apiKey := cfg.ClaudeAPIKey
 	if apiKey == "" {
-		apiKey = mf.cfg.LLMAPIKey
+		apiKey = cfg.LLMAPIKey
 	}
 
 	if apiKey == "" {
 		return nil, fmt.Errorf("claude API key not set - please set CLAUDE_API_KEY or ANTHROPIC_API_KEY")
 	}
 
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "claude-3-5-sonnet-20241022"
-	}
-
 	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
 		ProviderName:      "anthropic",
 		APIKey:            apiKey,
@@ -172,21 +305,17 @@ func (mf *ModelFactory) createClaudeModel() (model.LLM, error) {
 }
 
 // createOpenAIModel creates an OpenAI model using OmniLLM
-func (mf *ModelFactory) createOpenAIModel() (model.LLM, error) {
-	apiKey := mf.cfg.OpenAIAPIKey
+func (mf *ModelFactory) createOpenAIModel(modelName string) (model.LLM, error) {
+	cfg := mf.config()
+	apiKey := cfg.OpenAIAPIKey
 	if apiKey == "" {
-		apiKey = mf.cfg.LLMAPIKey
+		apiKey = cfg.LLMAPIKey
 	}
 
 	if apiKey == "" {
 		return nil, fmt.Errorf("openai API key not set - please set OPENAI_API_KEY")
 	}
 
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "gpt-4o-mini" // Use mini for cost efficiency
-	}
-
 	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
 		ProviderName:      "openai",
 		APIKey:            apiKey,
@@ -197,21 +326,17 @@ func (mf *ModelFactory) createOpenAIModel() (model.LLM, error) {
 }
 
 // createXAIModel creates an xAI Grok model using OmniLLM
-func (mf *ModelFactory) createXAIModel() (model.LLM, error) {
-	apiKey := mf.cfg.XAIAPIKey
+func (mf *ModelFactory) createXAIModel(modelName string) (model.LLM, error) {
+	cfg := mf.config()
+	apiKey := cfg.XAIAPIKey
 	if apiKey == "" {
-		apiKey = mf.cfg.LLMAPIKey
+		apiKey = cfg.LLMAPIKey
 	}
 
 	if apiKey == "" {
 		return nil, fmt.Errorf("xAI API key not set - please set XAI_API_KEY")
 	}
 
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "grok-3"
-	}
-
 	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
 		ProviderName:      "xai",
 		APIKey:            apiKey,
@@ -222,12 +347,7 @@ func (mf *ModelFactory) createXAIModel() (model.LLM, error) {
 }
 
 // createOllamaModel creates an Ollama model using OmniLLM
-func (mf *ModelFactory) createOllamaModel() (model.LLM, error) {
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "llama3.2"
-	}
-
+func (mf *ModelFactory) createOllamaModel(modelName string) (model.LLM, error) {
 	// Ollama doesn't need an API key for local instances
 	// OmniLLM will use the base URL from environment or default to localhost
 	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
@@ -239,15 +359,67 @@ func (mf *ModelFactory) createOllamaModel() (model.LLM, error) {
 	})
 }
 
+// compatBaseURLs are the OpenAI-compatible endpoints of providers served
+// through OmniLLM's OpenAI provider
+var compatBaseURLs = map[string]string{
+	"groq":     "https://api.groq.com/openai/v1",
+	"mistral":  "https://api.mistral.ai/v1",
+	"deepseek": "https://api.deepseek.com/v1",
+}
+
+// createCompatModel creates a Groq, Mistral, or DeepSeek model using
+// OmniLLM's OpenAI provider pointed at the provider's compatible endpoint
+func (mf *ModelFactory) createCompatModel(provider, modelName string) (model.LLM, error) {
+	cfg := mf.config()
+	var apiKey, envVar string
+	switch provider {
+	case "groq":
+		apiKey, envVar = cfg.GroqAPIKey, "GROQ_API_KEY"
+	case "mistral":
+		apiKey, envVar = cfg.MistralAPIKey, "MISTRAL_API_KEY"
+	case "deepseek":
+		apiKey, envVar = cfg.DeepSeekAPIKey, "DEEPSEEK_API_KEY"
+	}
+	if apiKey == "" && provider == cfg.LLMProvider {
+		apiKey = cfg.LLMAPIKey
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("%s API key not set - please set %s", provider, envVar)
+	}
+
+	baseURL := compatBaseURLs[provider]
+	if provider == cfg.LLMProvider && cfg.LLMBaseURL != "" {
+		baseURL = cfg.LLMBaseURL
+	}
+
+	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
+		ProviderName:      "openai",
+		BaseURL:           baseURL,
+		APIKey:            apiKey,
+		ModelName:         modelName,
+		Timeout:           mf.getTimeout(),
+		ObservabilityHook: mf.obsHook,
+	})
+}
+
 // getTimeout returns the configured HTTP timeout for LLM API calls
 func (mf *ModelFactory) getTimeout() time.Duration {
-	if mf.cfg.HTTPTimeoutSeconds > 0 {
-		return time.Duration(mf.cfg.HTTPTimeoutSeconds) * time.Second
+	cfg := mf.config()
+	if cfg.HTTPTimeoutSeconds > 0 {
+		return time.Duration(cfg.HTTPTimeoutSeconds) * time.Second
 	}
 	return 0 // Let provider use its default
 }
 
 // GetProviderInfo returns information about the current provider
 func (mf *ModelFactory) GetProviderInfo() string {
-	return fmt.Sprintf("Provider: %s, Model: %s", mf.cfg.LLMProvider, mf.cfg.LLMModel)
+	cfg := mf.config()
+	return fmt.Sprintf("Provider: %s, Model: %s", cfg.LLMProvider, cfg.LLMModel)
+}
+
+// GetStageInfo returns information about the provider and model used for a stage
+func (mf *ModelFactory) GetStageInfo(stage Stage) string {
+	provider, modelName := mf.StageModel(stage)
+	return fmt.Sprintf("Provider: %s, Model: %s", provider, modelName)
 }
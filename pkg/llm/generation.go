@@ -0,0 +1,154 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// ParseGeneration parses a generation setting such as
+// "temperature=0.2,top_p=0.9,max_tokens=4096". An empty setting returns nil.
+func ParseGeneration(spec string) (*models.Generation, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	g := &models.Generation{}
+	for _, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid generation setting %q: want key=value", field)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "temperature", "top_p":
+			f, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", key, value)
+			}
+			v := float32(f)
+			if key == "temperature" {
+				g.Temperature = &v
+			} else {
+				g.TopP = &v
+			}
+		case "max_tokens":
+			n, err := strconv.Atoi(value)
+			if err != nil || n == 0 {
+				return nil, fmt.Errorf("invalid max_tokens %q", value)
+			}
+			g.MaxTokens = n
+		default:
+			return nil, fmt.Errorf("unknown generation setting %q (supported: temperature, top_p, max_tokens)", key)
+		}
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// generationSpec returns the configured generation setting for a stage
+func generationSpec(cfg *config.Config, stage Stage) string {
+	switch stage {
+	case StageSynthesis:
+		return cfg.SynthesisGeneration
+	case StageVerification:
+		return cfg.VerificationGeneration
+	case StagePlanning:
+		return cfg.PlanningGeneration
+	case StageVision:
+		return cfg.VisionGeneration
+	default:
+		return ""
+	}
+}
+
+// StageGeneration returns the generation settings of a stage: LLM_GENERATION
+// with the stage's own setting applied over it
+func StageGeneration(cfg *config.Config, stage Stage) (*models.Generation, error) {
+	base, err := ParseGeneration(cfg.LLMGeneration)
+	if err != nil {
+		return nil, fmt.Errorf("LLM_GENERATION: %w", err)
+	}
+	own, err := ParseGeneration(generationSpec(cfg, stage))
+	if err != nil {
+		return nil, fmt.Errorf("%s generation: %w", stageName(stage), err)
+	}
+	return base.Merge(own), nil
+}
+
+// generationKey is the context key for the generation settings of a request
+type generationKey struct{}
+
+// WithGeneration returns a context whose LLM calls use g over their stage's
+// settings. A nil g leaves the stage's settings.
+func WithGeneration(ctx context.Context, g *models.Generation) context.Context {
+	if g == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, generationKey{}, g)
+}
+
+// GenerationFrom returns the generation settings carried by ctx, or nil
+func GenerationFrom(ctx context.Context) *models.Generation {
+	g, _ := ctx.Value(generationKey{}).(*models.Generation)
+	return g
+}
+
+// configuredModel applies generation settings to each request: the fixed
+// settings of its stage, if any, and then those carried by the call's
+// context, so a request's generation and a reproducible run's sampling take
+// precedence
+type configuredModel struct {
+	model.LLM
+	generation *models.Generation
+}
+
+// withGeneration wraps m so its calls use g and the settings of their context
+func withGeneration(m model.LLM, g *models.Generation) model.LLM {
+	return configuredModel{LLM: m, generation: g}
+}
+
+// GenerateContent implements model.LLM, setting the generation parameters
+// on a copy of the request's config
+func (m configuredModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	g := m.generation.Merge(GenerationFrom(ctx))
+	s := SamplingFrom(ctx)
+	if g == nil && s == nil {
+		return m.LLM.GenerateContent(ctx, req, stream)
+	}
+
+	configured := *req
+	cfg := genai.GenerateContentConfig{}
+	if req.Config != nil {
+		cfg = *req.Config
+	}
+	if g != nil {
+		if g.Temperature != nil {
+			cfg.Temperature = genai.Ptr(*g.Temperature)
+		}
+		if g.TopP != nil {
+			cfg.TopP = genai.Ptr(*g.TopP)
+		}
+		if g.MaxTokens != 0 {
+			cfg.MaxOutputTokens = int32(g.MaxTokens) //nolint:gosec // G115: bounded by models.MaxGenerationSize
+		}
+	}
+	if s != nil {
+		cfg.Temperature = genai.Ptr(s.Temperature)
+		if s.Seed != 0 {
+			cfg.Seed = genai.Ptr(s.Seed)
+		}
+	}
+	configured.Config = &cfg
+	return m.LLM.GenerateContent(ctx, &configured, stream)
+}
@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestParseGeneration(t *testing.T) {
+	g, err := ParseGeneration(" temperature=0.2, top_p=0.9 ,max_tokens=4096")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *g.Temperature != 0.2 || *g.TopP != 0.9 || g.MaxTokens != 4096 {
+		t.Errorf("ParseGeneration() = %+v", g)
+	}
+	if g, err := ParseGeneration(""); g != nil || err != nil {
+		t.Errorf("empty setting = %+v, %v; want nil", g, err)
+	}
+	for _, bad := range []string{"temperature", "temperature=hot", "temperature=3", "top_p=0", "max_tokens=-1", "seed=1"} {
+		if _, err := ParseGeneration(bad); err == nil {
+			t.Errorf("ParseGeneration(%q) should fail", bad)
+		}
+	}
+}
+
+func TestStageGeneration(t *testing.T) {
+	cfg := &config.Config{LLMGeneration: "temperature=0.7,max_tokens=2048", SynthesisGeneration: "temperature=0.1"}
+	g, err := StageGeneration(cfg, StageSynthesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *g.Temperature != 0.1 || g.MaxTokens != 2048 {
+		t.Errorf("synthesis generation = %+v, want its temperature over the shared max_tokens", g)
+	}
+	if g, _ := StageGeneration(cfg, StageVerification); *g.Temperature != 0.7 {
+		t.Errorf("verification generation = %+v, want the shared settings", g)
+	}
+
+	cfg.VerificationGeneration = "top_p=2"
+	if _, err := StageGeneration(cfg, StageVerification); err == nil {
+		t.Error("expected an out-of-range stage setting to fail")
+	}
+}
+
+// configModel records the config of the last request it received
+type configModel struct {
+	got *genai.GenerateContentConfig
+}
+
+func (m *configModel) Name() string { return "config" }
+
+func (m *configModel) GenerateContent(_ context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	m.got = req.Config
+	return func(func(*model.LLMResponse, error) bool) {}
+}
+
+func TestWithGeneration(t *testing.T) {
+	inner := &configModel{}
+	stage := &models.Generation{Temperature: genai.Ptr[float32](0.5), MaxTokens: 1000}
+	m := withGeneration(inner, nil)
+	req := &model.LLMRequest{Config: &genai.GenerateContentConfig{CandidateCount: 1}}
+
+	for range m.GenerateContent(context.Background(), req, false) {
+	}
+	if inner.got != req.Config {
+		t.Error("a call without settings should pass its config through")
+	}
+
+	// The request's settings apply over the stage's
+	m = withGeneration(inner, stage)
+	ctx := WithGeneration(context.Background(), &models.Generation{TopP: genai.Ptr[float32](0.8), MaxTokens: 200})
+	for range m.GenerateContent(ctx, req, false) {
+	}
+	if got := inner.got; got == nil || *got.Temperature != 0.5 || *got.TopP != 0.8 || got.MaxOutputTokens != 200 || got.CandidateCount != 1 {
+		t.Errorf("configured = %+v", got)
+	}
+
+	// A reproducible run's sampling applies over both
+	ctx = WithSampling(ctx, &models.Sampling{Temperature: 0, Seed: 42})
+	for range m.GenerateContent(ctx, req, false) {
+	}
+	if got := inner.got; *got.Temperature != 0 || got.Seed == nil || *got.Seed != 42 || *got.TopP != 0.8 {
+		t.Errorf("pinned config = %+v", got)
+	}
+	if req.Config.Temperature != nil || req.Config.Seed != nil || req.Config.MaxOutputTokens != 0 {
+		t.Error("configuring should not modify the caller's request")
+	}
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Resolve fills in the provider and model an override selects, defaulting
+// to the configured provider and that provider's configured or default model
+func Resolve(cfg *config.Config, o *models.ModelOverride) (provider, modelName string) {
+	provider = cfg.LLMProvider
+	if provider == "" {
+		provider = "gemini"
+	}
+	if o != nil && o.Provider != "" {
+		provider = o.Provider
+	}
+	if o != nil && o.Model != "" {
+		return provider, o.Model
+	}
+	if provider == cfg.LLMProvider && cfg.LLMModel != "" {
+		return provider, cfg.LLMModel
+	}
+	return provider, DefaultModel(provider)
+}
+
+// ValidateOverride checks a per-request override against cfg.LLMModelAllowlist.
+// Selecting the configured default or a configured stage model is always
+// allowed; anything else must match a "provider:model" or "provider:*"
+// allowlist entry.
+func ValidateOverride(cfg *config.Config, o *models.ModelOverride) error {
+	if o.IsZero() {
+		return nil
+	}
+	provider, modelName := Resolve(cfg, o)
+	for _, spec := range []string{"", cfg.SynthesisModel, cfg.VerificationModel, cfg.PlanningModel} {
+		if p, m := Resolve(cfg, ParseModelSpec(spec)); provider == p && modelName == m {
+			return nil
+		}
+	}
+	if len(cfg.LLMModelAllowlist) == 0 {
+		return fmt.Errorf("LLM model overrides are disabled (set LLM_MODEL_ALLOWLIST to enable them)")
+	}
+	for _, entry := range cfg.LLMModelAllowlist {
+		p, m, ok := strings.Cut(entry, ":")
+		if ok && p == provider && (m == "*" || m == modelName) {
+			return nil
+		}
+	}
+	return fmt.Errorf("LLM model %s:%s is not in LLM_MODEL_ALLOWLIST", provider, modelName)
+}
+
+// ValidateRequest checks the stage limits, statistic types, generation
+// settings, listed sources, and every model override of an orchestration
+// request
+func ValidateRequest(cfg *config.Config, req *models.OrchestrationRequest) error {
+	if err := req.StageLimits.Validate(); err != nil {
+		return err
+	}
+	if err := req.TypeFilter.Validate(); err != nil {
+		return err
+	}
+	if err := req.Generation.Validate(); err != nil {
+		return err
+	}
+	if err := models.ValidateSources(req.Sources); err != nil {
+		return err
+	}
+	for _, o := range []*models.ModelOverride{req.RunModel(), req.SynthesisModel, req.VerificationModel} {
+		if err := ValidateOverride(cfg, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ModelFor returns the model selected by a per-request override, creating
+// and caching it on first use. It returns fallback when o is empty.
+func (mf *ModelFactory) ModelFor(ctx context.Context, o *models.ModelOverride, fallback model.LLM) (model.LLM, error) {
+	if o.IsZero() {
+		return fallback, nil
+	}
+	cfg := mf.config()
+	if err := ValidateOverride(cfg, o); err != nil {
+		return nil, err
+	}
+	provider, modelName := Resolve(cfg, o)
+	key := provider + ":" + modelName
+
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+	if m, ok := mf.overrides[key]; ok {
+		return m, nil
+	}
+	m, err := mf.CreateModelWith(ctx, provider, modelName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model %s: %w", key, err)
+	}
+	if mf.overrides == nil {
+		mf.overrides = make(map[string]model.LLM)
+	}
+	mf.overrides[key] = m
+	mf.logger.Info("created override model", "provider", provider, "model", modelName)
+	return m, nil
+}
+
+// modelKey is the context key for a per-request model
+type modelKey struct{}
+
+// WithModel returns a context carrying the model to use for this request
+func WithModel(ctx context.Context, m model.LLM) context.Context {
+	return context.WithValue(ctx, modelKey{}, m)
+}
+
+// FromContext returns the model carried by ctx, or fallback if none is set
+func FromContext(ctx context.Context, fallback model.LLM) model.LLM {
+	if m, ok := ctx.Value(modelKey{}).(model.LLM); ok && m != nil {
+		return m
+	}
+	return fallback
+}
@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestValidateOverride(t *testing.T) {
+	cfg := &config.Config{
+		Config:            &akconfig.Config{LLMProvider: "gemini", LLMModel: "gemini-2.5-flash"},
+		LLMModelAllowlist: []string{"openai:gpt-4o-mini", "claude:*"},
+	}
+
+	tests := []struct {
+		name    string
+		o       *models.ModelOverride
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"configured default", &models.ModelOverride{Model: "gemini-2.5-flash"}, false},
+		{"allowed model", &models.ModelOverride{Provider: "openai", Model: "gpt-4o-mini"}, false},
+		{"allowed provider wildcard", &models.ModelOverride{Provider: "claude", Model: "claude-sonnet-4"}, false},
+		{"provider default model", &models.ModelOverride{Provider: "claude"}, false},
+		{"model not allowed", &models.ModelOverride{Provider: "openai", Model: "gpt-4o"}, true},
+		{"provider not allowed", &models.ModelOverride{Provider: "xai", Model: "grok-3"}, true},
+		{"other model of default provider", &models.ModelOverride{Model: "gemini-2.5-pro"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOverride(cfg, tt.o)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateOverride() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg.LLMModelAllowlist = nil
+	if err := ValidateOverride(cfg, &models.ModelOverride{Provider: "openai", Model: "gpt-4o-mini"}); err == nil {
+		t.Error("expected overrides to be rejected without an allowlist")
+	}
+}
+
+func TestStageOverrides(t *testing.T) {
+	req := &models.OrchestrationRequest{
+		LLMProvider:       "openai",
+		LLMModel:          "gpt-4o-mini",
+		VerificationModel: &models.ModelOverride{Provider: "claude", Model: "claude-sonnet-4"},
+	}
+	if got := req.SynthesisOverride(); got == nil || got.Model != "gpt-4o-mini" {
+		t.Errorf("SynthesisOverride() = %+v, want run-level model", got)
+	}
+	if got := req.VerificationOverride(); got == nil || got.Model != "claude-sonnet-4" {
+		t.Errorf("VerificationOverride() = %+v, want stage model", got)
+	}
+	if got := (&models.OrchestrationRequest{}).SynthesisOverride(); got != nil {
+		t.Errorf("SynthesisOverride() = %+v, want nil", got)
+	}
+}
+
+func TestParseModelSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want *models.ModelOverride
+	}{
+		{"", nil},
+		{"openai:gpt-4o-mini", &models.ModelOverride{Provider: "openai", Model: "gpt-4o-mini"}},
+		{"gemini-2.5-flash", &models.ModelOverride{Model: "gemini-2.5-flash"}},
+		{"llama3:8b", &models.ModelOverride{Model: "llama3:8b"}},
+		{"ollama:llama3:8b", &models.ModelOverride{Provider: "ollama", Model: "llama3:8b"}},
+	}
+	for _, tt := range tests {
+		got := ParseModelSpec(tt.spec)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("ParseModelSpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestStageModel(t *testing.T) {
+	cfg := &config.Config{
+		Config:            &akconfig.Config{LLMProvider: "gemini", LLMModel: "gemini-2.5-pro"},
+		SynthesisModel:    "gemini-2.5-flash",
+		VerificationModel: "claude:claude-sonnet-4-20250514",
+	}
+	mf := NewModelFactory(context.Background(), cfg)
+
+	tests := []struct {
+		stage                   Stage
+		wantProvider, wantModel string
+	}{
+		{StageSynthesis, "gemini", "gemini-2.5-flash"},
+		{StageVerification, "claude", "claude-sonnet-4-20250514"},
+		{StagePlanning, "gemini", "gemini-2.5-pro"},
+	}
+	for _, tt := range tests {
+		provider, modelName := mf.StageModel(tt.stage)
+		if provider != tt.wantProvider || modelName != tt.wantModel {
+			t.Errorf("StageModel(%s) = %s:%s, want %s:%s", tt.stage, provider, modelName, tt.wantProvider, tt.wantModel)
+		}
+	}
+
+	// Configured stage models may be selected per request without an allowlist entry
+	if err := ValidateOverride(cfg, &models.ModelOverride{Provider: "claude", Model: "claude-sonnet-4-20250514"}); err != nil {
+		t.Errorf("ValidateOverride(stage model) = %v", err)
+	}
+}
+
+func TestSmallModel(t *testing.T) {
+	tests := []struct {
+		provider, model string
+		want            bool
+	}{
+		{"ollama", "llama3.2", true},
+		{"ollama", "llama3.2:3b", true},
+		{"ollama", "qwen2.5:14b-instruct-q4_K_M", true},
+		{"ollama", "llama3.3:70b", false},
+		{"ollama", "llama3.3:70b-instruct-q3_K_M", true},
+		{"ollama", "mixtral:8x7b", false},
+		{"groq", "llama-3.1-8b-instant", true},
+		{"groq", "llama-3.3-70b-versatile", false},
+		{"openai", "gpt-4o-mini", false},
+		{"gemini", "gemini-2.5-flash", false},
+	}
+	for _, tt := range tests {
+		if got := SmallModel(tt.provider, tt.model); got != tt.want {
+			t.Errorf("SmallModel(%q, %q) = %v, want %v", tt.provider, tt.model, got, tt.want)
+		}
+	}
+}
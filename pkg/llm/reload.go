@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync/atomic"
+
+	"google.golang.org/adk/model"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+// reloadableModel is a model whose underlying provider model can be swapped
+// while it is in use. Each call runs on the model current when it started,
+// so a swap never interrupts a call in progress.
+type reloadableModel struct {
+	stage   Stage
+	current atomic.Pointer[model.LLM]
+}
+
+// track wraps m so Reload can replace it
+func (mf *ModelFactory) track(stage Stage, m model.LLM) model.LLM {
+	rm := &reloadableModel{stage: stage}
+	rm.current.Store(&m)
+
+	mf.mu.Lock()
+	mf.reloaded = append(mf.reloaded, rm)
+	mf.mu.Unlock()
+	return rm
+}
+
+// Name implements model.LLM
+func (rm *reloadableModel) Name() string {
+	return (*rm.current.Load()).Name()
+}
+
+// GenerateContent implements model.LLM
+func (rm *reloadableModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return (*rm.current.Load()).GenerateContent(ctx, req, stream)
+}
+
+// Reload switches the factory to cfg and recreates every model returned by
+// CreateModel and CreateModelFor, so rotated API keys take effect without a
+// restart. Calls already in progress finish on the models they started with.
+// If any model cannot be created the factory keeps its previous
+// configuration and models. Cached override models are dropped and are
+// recreated with the new credentials on next use. Observability settings
+// are not reloaded.
+func (mf *ModelFactory) Reload(ctx context.Context, cfg *config.Config) error {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+
+	previous := mf.cfg.Swap(cfg)
+	fresh := make([]model.LLM, len(mf.reloaded))
+	for i, rm := range mf.reloaded {
+		m, err := mf.createStageModel(ctx, rm.stage)
+		if err != nil {
+			mf.cfg.Store(previous)
+			return fmt.Errorf("failed to recreate %s model: %w", stageName(rm.stage), err)
+		}
+		fresh[i] = m
+	}
+
+	for i, rm := range mf.reloaded {
+		rm.current.Store(&fresh[i])
+	}
+	mf.overrides = nil
+
+	mf.logger.Info("models reloaded", "count", len(fresh), "provider", cfg.LLMProvider)
+	return nil
+}
+
+// stageName names a stage in log and error messages
+func stageName(stage Stage) string {
+	if stage == "" {
+		return "default"
+	}
+	return string(stage)
+}
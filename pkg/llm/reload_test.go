@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/replay"
+)
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	replayConfig := func(synthesisModel string) *config.Config {
+		return &config.Config{
+			Config:         &akconfig.Config{LLMProvider: "gemini", LLMModel: "gemini-2.5-pro"},
+			SynthesisModel: synthesisModel,
+			LLMReplayMode:  replay.ModeReplay,
+			LLMReplayDir:   t.TempDir(),
+		}
+	}
+
+	mf := NewModelFactory(ctx, replayConfig("gemini-2.5-flash"))
+	m, err := mf.CreateModelFor(ctx, StageSynthesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name() != "gemini-2.5-flash" {
+		t.Fatalf("Name() = %q, want gemini-2.5-flash", m.Name())
+	}
+
+	if err := mf.Reload(ctx, replayConfig("gemini-2.0-flash")); err != nil {
+		t.Fatal(err)
+	}
+	if m.Name() != "gemini-2.0-flash" {
+		t.Errorf("after reload Name() = %q, want gemini-2.0-flash", m.Name())
+	}
+
+	// A configuration whose models cannot be created leaves everything as it was
+	broken := &config.Config{Config: &akconfig.Config{LLMProvider: "claude"}}
+	if err := mf.Reload(ctx, broken); err == nil {
+		t.Fatal("Reload() with a missing API key succeeded")
+	}
+	if m.Name() != "gemini-2.0-flash" {
+		t.Errorf("after failed reload Name() = %q, want gemini-2.0-flash", m.Name())
+	}
+	if provider, _ := mf.StageModel(StageSynthesis); provider != "gemini" {
+		t.Errorf("after failed reload provider = %q, want gemini", provider)
+	}
+}
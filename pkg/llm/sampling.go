@@ -0,0 +1,25 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// samplingKey is the context key for the sampling of a reproducible run
+type samplingKey struct{}
+
+// WithSampling returns a context whose LLM calls sample as s pins. A nil s
+// leaves the providers' defaults.
+func WithSampling(ctx context.Context, s *models.Sampling) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, samplingKey{}, s)
+}
+
+// SamplingFrom returns the sampling carried by ctx, or nil if none is set
+func SamplingFrom(ctx context.Context) *models.Sampling {
+	s, _ := ctx.Value(samplingKey{}).(*models.Sampling)
+	return s
+}
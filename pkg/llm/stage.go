@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/adk/model"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Stage is a pipeline step that can run on its own model, so cheap models
+// can handle high-volume extraction while a stronger model verifies
+type Stage string
+
+const (
+	// StageSynthesis extracts candidate statistics from fetched pages
+	StageSynthesis Stage = "synthesis"
+	// StageVerification judges candidates whose excerpt was not found verbatim
+	StageVerification Stage = "verification"
+	// StagePlanning covers the orchestrator's own reasoning: claim parsing,
+	// refinement filtering, and query rewriting
+	StagePlanning Stage = "planning"
+	// StageVision reads statistics from charts and infographics, and re-reads
+	// them during verification
+	StageVision Stage = "vision"
+)
+
+// providers are the supported LLM_PROVIDER values
+var providers = map[string]bool{
+	"gemini": true, "claude": true, "openai": true, "xai": true, "ollama": true,
+	"groq": true, "mistral": true, "deepseek": true,
+}
+
+// ParseModelSpec parses a "provider:model" or bare "model" setting. A bare
+// model uses the configured provider. Ollama tags such as "llama3:8b" are
+// kept intact because "llama3" is not a provider name.
+func ParseModelSpec(spec string) *models.ModelOverride {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil
+	}
+	if provider, name, ok := strings.Cut(spec, ":"); ok && providers[provider] {
+		return &models.ModelOverride{Provider: provider, Model: name}
+	}
+	return &models.ModelOverride{Model: spec}
+}
+
+// stageSpec returns the configured model setting for a stage
+func stageSpec(cfg *config.Config, stage Stage) string {
+	switch stage {
+	case StageSynthesis:
+		return cfg.SynthesisModel
+	case StageVerification:
+		return cfg.VerificationModel
+	case StagePlanning:
+		return cfg.PlanningModel
+	case StageVision:
+		return cfg.VisionModel
+	default:
+		return ""
+	}
+}
+
+// StageModel returns the provider and model name used for a stage
+func (mf *ModelFactory) StageModel(stage Stage) (provider, modelName string) {
+	return RunStageModel(mf.config(), stage, nil)
+}
+
+// RunStageModel returns the provider and model name a stage uses for a run:
+// the run's override if it has one, otherwise the stage's configured model
+func RunStageModel(cfg *config.Config, stage Stage, o *models.ModelOverride) (provider, modelName string) {
+	if !o.IsZero() {
+		return Resolve(cfg, o)
+	}
+	return Resolve(cfg, ParseModelSpec(stageSpec(cfg, stage)))
+}
+
+// SupportsImages reports whether a provider's models accept images. Only
+// Gemini models do here: the other providers go through the OmniLLM adapter,
+// whose messages carry text only.
+func SupportsImages(provider string) bool {
+	return provider == "gemini" || provider == ""
+}
+
+// smallModelParams is the largest parameter count, in billions, of a model
+// treated as small
+const smallModelParams = 14
+
+var (
+	// paramSize matches a parameter count in a model name, as in
+	// "llama3.2:3b", "llama-3.1-8b-instant", or "mixtral:8x7b"
+	paramSize = regexp.MustCompile(`(?i)(?:^|[^a-z0-9.])(?:(\d+)x)?(\d+(?:\.\d+)?)b(?:$|[^a-z0-9])`)
+	// lowBitQuant matches a 2- or 3-bit quantization tag, as in "q3_K_M" or "iq2_xxs"
+	lowBitQuant = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])i?q[23](?:$|[^0-9])`)
+)
+
+// SmallModel reports whether a model is too small to follow the full
+// extraction prompt: one quantized to 2 or 3 bits, one whose name gives at
+// most 14 billion parameters, or an Ollama model whose name gives no size,
+// since Ollama's default tags are small models
+func SmallModel(provider, modelName string) bool {
+	if lowBitQuant.MatchString(modelName) {
+		return true
+	}
+	if m := paramSize.FindStringSubmatch(modelName); m != nil {
+		n, err := strconv.ParseFloat(m[2], 64)
+		if experts, _ := strconv.Atoi(m[1]); experts > 0 {
+			n *= float64(experts)
+		}
+		return err == nil && n <= smallModelParams
+	}
+	return provider == "ollama"
+}
+
+// CreateVisionModel creates the model that reads figures, failing when the
+// configured VISION_MODEL cannot accept images
+func (mf *ModelFactory) CreateVisionModel(ctx context.Context) (model.LLM, error) {
+	if provider, _ := mf.StageModel(StageVision); !SupportsImages(provider) {
+		return nil, fmt.Errorf("vision model must be a Gemini model to read images, got provider %q", provider)
+	}
+	return mf.CreateModelFor(ctx, StageVision)
+}
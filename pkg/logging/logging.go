@@ -11,25 +11,27 @@ import (
 )
 
 // DefaultLogger returns the default logger configured for the application.
-// Uses JSON handler for production-ready structured logging.
+// Uses JSON handler for production-ready structured logging. Secrets and PII
+// are redacted as configured by DefaultRedactor.
 func DefaultLogger() *slog.Logger {
-	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	return slog.New(RedactHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
-	}))
+	}), DefaultRedactor()))
 }
 
 // JSONLogger returns a logger with JSON output format.
 func JSONLogger() *slog.Logger {
-	return slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+	return slog.New(RedactHandler(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
-	}))
+	}), DefaultRedactor()))
 }
 
-// DebugLogger returns a logger with debug level enabled.
+// DebugLogger returns a logger with debug level enabled. Prompts and fetched
+// content logged at debug are redacted like everything else.
 func DebugLogger() *slog.Logger {
-	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	return slog.New(RedactHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
-	}))
+	}), DefaultRedactor()))
 }
 
 // WithLogger returns a new context with the given logger attached.
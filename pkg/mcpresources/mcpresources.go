@@ -0,0 +1,203 @@
+// Package mcpresources registers MCP resources for the results of previous
+// runs, so MCP clients can browse them and pull them into context without
+// searching again:
+//
+//	stats://runs/{id}        the verification report of a run (needs REPORT_DIR)
+//	stats://topics/{topic}   the statistics stored for a topic (needs STATS_STORE_FILE)
+//
+// Recent runs and stored topics are also listed as resources; Refresh
+// updates the list after a run.
+package mcpresources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/report"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+)
+
+const (
+	runPrefix   = "stats://runs/"
+	topicPrefix = "stats://topics/"
+
+	// maxRuns and maxTopics bound the listed resources; older ones can still
+	// be read by URI
+	maxRuns   = 20
+	maxTopics = 50
+)
+
+// Resources are the resources of a server
+type Resources struct {
+	server  *mcp.Server
+	reports *report.Store    // Nil unless REPORT_DIR is set
+	stats   *statstore.Store // Nil unless STATS_STORE_FILE is set
+	logger  *slog.Logger
+
+	mu     sync.Mutex
+	listed map[string]bool // URIs of the listed resources
+}
+
+// Register adds the resource templates of the stores that are set to
+// server and lists their recent runs and topics
+func Register(server *mcp.Server, reports *report.Store, stats *statstore.Store, logger *slog.Logger) *Resources {
+	r := &Resources{server: server, reports: reports, stats: stats, logger: logger, listed: make(map[string]bool)}
+	if reports != nil {
+		server.AddResourceTemplate(&mcp.ResourceTemplate{
+			URITemplate: runPrefix + "{id}",
+			Name:        "run",
+			Title:       "Previous run",
+			Description: "Verification report of a previous run: its verified statistics with their sources and excerpts, and the rejected candidates",
+			MIMEType:    "text/markdown",
+		}, r.readRun)
+	}
+	if stats != nil {
+		server.AddResourceTemplate(&mcp.ResourceTemplate{
+			URITemplate: topicPrefix + "{topic}",
+			Name:        "topic",
+			Title:       "Stored statistics of a topic",
+			Description: "Statistics verified by previous runs on a topic, most recently verified first",
+			MIMEType:    "application/json",
+		}, r.readTopic)
+	}
+	r.Refresh()
+	return r
+}
+
+// RunURI returns the URI of a run's report
+func RunURI(id string) string {
+	return runPrefix + id
+}
+
+// TopicURI returns the URI of a topic's stored statistics
+func TopicURI(topic string) string {
+	return topicPrefix + url.PathEscape(topic)
+}
+
+// Refresh lists the most recent runs and topics, replacing the ones listed
+// before
+func (r *Resources) Refresh() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var resources []*mcp.Resource
+	if r.reports != nil {
+		ids, err := r.reports.List(maxRuns)
+		if err != nil {
+			r.logger.Warn("failed to list runs", "error", err)
+		}
+		for _, id := range ids {
+			resources = append(resources, &mcp.Resource{
+				URI:      RunURI(id),
+				Name:     id,
+				Title:    runTitle(id),
+				MIMEType: "text/markdown",
+			})
+		}
+	}
+	if r.stats != nil {
+		topics, err := r.stats.Topics()
+		if err != nil {
+			r.logger.Warn("failed to list stored topics", "error", err)
+		}
+		for _, topic := range topics[:min(len(topics), maxTopics)] {
+			resources = append(resources, &mcp.Resource{
+				URI:      TopicURI(topic),
+				Name:     topic,
+				Title:    "Stored statistics: " + topic,
+				MIMEType: "application/json",
+			})
+		}
+	}
+
+	listed := make(map[string]bool, len(resources))
+	for _, res := range resources {
+		listed[res.URI] = true
+		if !r.listed[res.URI] {
+			r.server.AddResource(res, r.read(res.URI))
+		}
+	}
+	var gone []string
+	for uri := range r.listed {
+		if !listed[uri] {
+			gone = append(gone, uri)
+		}
+	}
+	if len(gone) > 0 {
+		r.server.RemoveResources(gone...)
+	}
+	r.listed = listed
+}
+
+// read returns the handler of a listed resource
+func (r *Resources) read(uri string) mcp.ResourceHandler {
+	if strings.HasPrefix(uri, runPrefix) {
+		return r.readRun
+	}
+	return r.readTopic
+}
+
+// readRun returns the Markdown report of a run
+func (r *Resources) readRun(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	data, err := r.reports.Read(ctx, strings.TrimPrefix(uri, runPrefix), report.FormatMarkdown)
+	if errors.Is(err, report.ErrNotFound) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "text/markdown", Text: string(data)}}}, nil
+}
+
+// readTopic returns the stored statistics of a topic, as GET
+// /statistics/search?topic= does
+func (r *Resources) readTopic(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	topic, err := url.PathUnescape(strings.TrimPrefix(uri, topicPrefix))
+	if err != nil || strings.TrimSpace(topic) == "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	matches, mode, err := r.stats.Search(ctx, statstore.Query{Topic: topic})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search stored statistics: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	resp := models.StatisticSearchResponse{SchemaVersion: models.SchemaVersion, Topic: topic, Mode: mode}
+	resp.Results, resp.Page = models.Paginate(matches, 0, 0)
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: string(data)}}}, nil
+}
+
+// runTitle describes a run by the topic and time in its report ID, such as
+// "renewable energy (2026-10-16 15:30 UTC)"
+func runTitle(id string) string {
+	stamp, rest, _ := strings.Cut(id, "-")
+	clock, rest, _ := strings.Cut(rest, "-")
+	saved, err := time.Parse("20060102150405", stamp+clock)
+	if err != nil {
+		return id
+	}
+	// The slug of the topic is followed by a random suffix
+	slug := rest[:max(strings.LastIndexByte(rest, '-'), 0)]
+	if slug == "" {
+		return saved.Format("2006-01-02 15:04 UTC")
+	}
+	return fmt.Sprintf("%s (%s)", strings.ReplaceAll(slug, "-", " "), saved.Format("2006-01-02 15:04 UTC"))
+}
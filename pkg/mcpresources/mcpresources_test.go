@@ -0,0 +1,152 @@
+package mcpresources
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/report"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+var evStat = models.Statistic{
+	Name:      "Global electric vehicle sales in 2023",
+	Value:     14,
+	Unit:      "million",
+	Source:    "IEA",
+	SourceURL: "https://www.iea.org/reports/global-ev-outlook-2024",
+	Excerpt:   "Almost 14 million new electric cars were registered globally in 2023.",
+	Verified:  true,
+}
+
+// connect serves server to a new client session
+func connect(t *testing.T, server *mcp.Server) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func listed(t *testing.T, cs *mcp.ClientSession) []string {
+	t.Helper()
+	res, err := cs.ListResources(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, r := range res.Resources {
+		uris = append(uris, r.URI)
+	}
+	return uris
+}
+
+func TestResources(t *testing.T) {
+	ctx := context.Background()
+	reports, err := report.NewStore(&config.Config{ReportDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := statstore.New(filepath.Join(t.TempDir(), "statistics.json"), nil, testLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &models.OrchestrationResponse{Topic: "electric vehicles", VerifiedCount: 1, Statistics: []models.Statistic{evStat}}
+	reports.Attach(context.Background(), resp, testLogger)
+	if err := stats.Record(ctx, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "stats", Version: "1"}, nil)
+	r := Register(server, reports, stats, testLogger)
+	cs := connect(t, server)
+
+	if got, want := strings.Join(listed(t, cs), " "), RunURI(resp.ReportID)+" stats://topics/electric%20vehicles"; got != want {
+		t.Errorf("resources = %s; want %s", got, want)
+	}
+	templates, err := cs.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates.ResourceTemplates) != 2 {
+		t.Errorf("templates = %d; want runs and topics", len(templates.ResourceTemplates))
+	}
+
+	run, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: RunURI(resp.ReportID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := run.Contents[0]; c.MIMEType != "text/markdown" || !strings.Contains(c.Text, evStat.Name) {
+		t.Errorf("run = %s %q", c.MIMEType, c.Text)
+	}
+
+	// Topics are matched by their words, as in GET /statistics/search
+	topic, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: TopicURI("Electric Vehicle")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found models.StatisticSearchResponse
+	if err := json.Unmarshal([]byte(topic.Contents[0].Text), &found); err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Results) != 1 || found.Results[0].Name != evStat.Name || found.Topic != "Electric Vehicle" {
+		t.Errorf("topic = %+v", found)
+	}
+
+	for _, uri := range []string{RunURI("missing"), RunURI("..%2Fsecrets"), TopicURI("solar energy"), "stats://other/x"} {
+		if _, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri}); err == nil {
+			t.Errorf("read %s succeeded", uri)
+		}
+	}
+
+	// A later run is listed once refreshed
+	later := &models.OrchestrationResponse{Topic: "solar energy", Statistics: []models.Statistic{{Name: "Solar share", Value: 4, SourceURL: "https://www.eia.gov/solar", Excerpt: "Solar provided 4%.", Verified: true}}}
+	reports.Attach(context.Background(), later, testLogger)
+	if err := stats.Record(ctx, later); err != nil {
+		t.Fatal(err)
+	}
+	r.Refresh()
+	if got := listed(t, cs); len(got) != 4 || !slices.Contains(got, TopicURI("solar energy")) {
+		t.Errorf("resources after refresh = %v", got)
+	}
+}
+
+func TestRegisterWithoutStores(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "stats", Version: "1"}, nil)
+	Register(server, nil, nil, testLogger)
+	cs := connect(t, server)
+	if got := listed(t, cs); len(got) != 0 {
+		t.Errorf("resources = %v; want none", got)
+	}
+}
+
+func TestRunTitle(t *testing.T) {
+	for id, want := range map[string]string{
+		"20261016-153000-renewable-energy-9f2c41d0": "renewable energy (2026-10-16 15:30 UTC)",
+		"20261016-153000-9f2c41d0":                  "2026-10-16 15:30 UTC",
+		"custom":                                    "custom",
+	} {
+		if got := runTitle(id); got != want {
+			t.Errorf("runTitle(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package methodology finds how a statistic was measured: the sample size
+// and population, the collection period, the margin of error, and the
+// collection method stated in the text around it. The extraction LLM
+// reports these as hints; Resolve keeps only the hints the source's text
+// supports and fills the rest from patterns matched near the excerpt, so an
+// invented sample size never reaches a verified statistic.
+package methodology
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+// windowChars is how much text on each side of an excerpt counts as near it
+const windowChars = 600
+
+const (
+	number   = `(\d{1,3}(?:,\d{3})+|\d+)`
+	decimal  = `(\d+(?:\.\d+)?)`
+	subjects = `(?:adults|respondents|participants|interviewees|people|voters|consumers|workers|employees|households|parents|teens|teenagers|students|patients|businesses|companies|firms|organizations|executives|leaders|professionals|decision-makers|users|individuals|residents|citizens|americans|children|women|men|physicians|doctors|nurses|developers)`
+	month    = `(?:jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sept?(?:ember)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\b\.?`
+	date     = month + `(?:\s+\d{1,2}(?:st|nd|rd|th)?\b)?(?:,?\s+\d{4})?`
+	through  = `\s*(?:-|to|through|until|and)\s*`
+)
+
+var (
+	// "n = 1,200", "(n=12)"
+	nEquals = regexp.MustCompile(`(?i)\bn\s*=\s*` + number)
+	// "a survey of 1,200 U.S. adults", "polled more than 75,000 people"
+	sampleOf = regexp.MustCompile(`(?i)\b(?:sample of|survey of|poll of|study of|surveyed|interviewed|polled|questioned)\s+(?:(?:more than|over|nearly|almost|about|approximately|roughly|some)\s+)?` + number + `\s+((?:[\w.'-]+\s+){0,3}?` + subjects + `)\b`)
+	// "12 respondents"
+	respondents = regexp.MustCompile(`(?i)\b` + number + `\s+((?:[\w.'-]+\s+){0,3}?(?:respondents|participants|interviewees))\b`)
+	// "The margin of sampling error is plus or minus 3.1 percentage points"
+	marginOfError = regexp.MustCompile(`(?i)\bmargin of (?:sampling )?error\b[^\d±;]{0,60}?(?:±|\+/-|plus or minus)?\s*` + decimal)
+	// "conducted online from March 1-15, 2024", "fielded in 2023"
+	period = regexp.MustCompile(`(?i)\b(?:conducted|fielded|carried out|collected|gathered|surveyed|interviewed|polled|took place|in the field)\b[^;]{0,60}?\b(?:from|between|during|in|on|over)\s+(` + date + `(?:` + through + `(?:` + date + `|\d{1,2}(?:,?\s+\d{4})?))?|\d{4}(?:` + through + `\d{4})?)`)
+	// "online survey", "telephone interviews", "randomized controlled trial"
+	collection = regexp.MustCompile(`(?i)\b((?:online|web-based|web|internet|telephone|phone|mobile|mail|postal|in-person|face-to-face|household|door-to-door|mixed-mode)\s+(?:surveys?|polls?|panels?|interviews?|questionnaires?)|randomized controlled trials?|administrative (?:records|data)|focus groups?)\b`)
+)
+
+// genericPopulations say nothing beyond the sample size
+var genericPopulations = map[string]bool{"respondents": true, "participants": true, "interviewees": true, "people": true, "individuals": true}
+
+// Resolve returns the methodology of the statistic quoted by excerpt in a
+// page's visible text, or nil when none is stated. Hint fields the text does
+// not support are dropped; missing fields are filled from the text near the
+// excerpt.
+func Resolve(pageText, excerpt string, hint *models.Methodology) *models.Methodology {
+	text := textmatch.Fold(pageText)
+	m := Ground(hint, text)
+	found := Find(Near(text, excerpt))
+	if m.SampleSize == 0 {
+		m.SampleSize = found.SampleSize
+	}
+	if m.Population == "" && found.SampleSize == m.SampleSize {
+		m.Population = found.Population
+	}
+	if m.Period == "" {
+		m.Period = found.Period
+	}
+	if m.MarginOfError == 0 {
+		m.MarginOfError = found.MarginOfError
+	}
+	if m.CollectionMethod == "" {
+		m.CollectionMethod = found.CollectionMethod
+	}
+	if m.IsZero() {
+		return nil
+	}
+	return m
+}
+
+// Near returns the text within windowChars of excerpt, or the excerpt alone
+// when it is not in the text word for word
+func Near(text, excerpt string) string {
+	excerpt = textmatch.Fold(excerpt)
+	if excerpt == "" {
+		return ""
+	}
+	loc := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(excerpt)).FindStringIndex(text)
+	if loc == nil {
+		return excerpt
+	}
+	start, end := max(0, loc[0]-windowChars), min(len(text), loc[1]+windowChars)
+	return strings.ToValidUTF8(text[start:end], "")
+}
+
+// Find matches methodology statements in text. The first sample size
+// statement wins; "n =" is the most specific.
+func Find(text string) *models.Methodology {
+	m := &models.Methodology{}
+	if match := nEquals.FindStringSubmatch(text); match != nil {
+		m.SampleSize = parseInt(match[1])
+	} else if match := sampleOf.FindStringSubmatch(text); match != nil {
+		m.SampleSize, m.Population = parseInt(match[1]), population(match[2])
+	} else if match := respondents.FindStringSubmatch(text); match != nil {
+		m.SampleSize, m.Population = parseInt(match[1]), population(match[2])
+	}
+	if match := marginOfError.FindStringSubmatch(text); match != nil {
+		if v, err := strconv.ParseFloat(match[1], 32); err == nil && v > 0 && v < 50 {
+			m.MarginOfError = float32(v)
+		}
+	}
+	if match := period.FindStringSubmatch(text); match != nil {
+		m.Period = strings.TrimRight(match[1], " ,.")
+	}
+	if match := collection.FindStringSubmatch(text); match != nil {
+		m.CollectionMethod = strings.ToLower(match[1])
+	}
+	return m
+}
+
+// Ground returns a copy of hint without the fields text does not support:
+// numbers must appear in the text, and phrases must match it as closely as
+// an excerpt must to verify
+func Ground(hint *models.Methodology, text string) *models.Methodology {
+	m := &models.Methodology{}
+	if hint.IsZero() {
+		return m
+	}
+	if hint.SampleSize > 0 && extract.ValueAppears(text, float64(hint.SampleSize)) {
+		m.SampleSize = hint.SampleSize
+	}
+	if hint.MarginOfError > 0 && extract.ValueAppears(text, float64(hint.MarginOfError)) {
+		m.MarginOfError = hint.MarginOfError
+	}
+	supported := func(phrase string) string {
+		if phrase == "" || textmatch.Similarity(text, phrase) < textmatch.DefaultThreshold {
+			return ""
+		}
+		return phrase
+	}
+	m.Population = supported(hint.Population)
+	m.Period = supported(hint.Period)
+	m.CollectionMethod = supported(hint.CollectionMethod)
+	return m
+}
+
+func parseInt(s string) int {
+	n, _ := strconv.Atoi(strings.ReplaceAll(s, ",", ""))
+	return n
+}
+
+// population returns a sampled population phrase unless it is generic
+func population(phrase string) string {
+	phrase = strings.Join(strings.Fields(phrase), " ")
+	if genericPopulations[strings.ToLower(phrase)] {
+		return ""
+	}
+	return phrase
+}
@@ -0,0 +1,72 @@
+package methodology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		text string
+		want models.Methodology
+	}{
+		{
+			text: "The survey of 1,200 U.S. adults was conducted online from March 1-15, 2024. The margin of sampling error is plus or minus 3.1 percentage points.",
+			want: models.Methodology{SampleSize: 1200, Population: "U.S. adults", Period: "March 1-15, 2024", MarginOfError: 3.1},
+		},
+		{
+			text: "75% of 12 respondents said they work remotely (telephone interviews, fielded in 2023).",
+			want: models.Methodology{SampleSize: 12, Period: "2023", CollectionMethod: "telephone interviews"},
+		},
+		{
+			text: "Most firms plan to hire (n = 75,000), according to the online survey; margin of error ±2%.",
+			want: models.Methodology{SampleSize: 75000, MarginOfError: 2, CollectionMethod: "online survey"},
+		},
+		{
+			text: "Unemployment fell to 3.9% in 2024.",
+		},
+	}
+	for _, tt := range tests {
+		if got := Find(tt.text); *got != tt.want {
+			t.Errorf("Find(%q) = %+v; want %+v", tt.text, *got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	page := strings.Repeat("Background on remote work and its history. ", 40) +
+		"In our poll, 61% of workers prefer hybrid schedules. We interviewed 2,048 employees by telephone interviews in May 2024. " +
+		strings.Repeat("Unrelated closing paragraph about other matters. ", 40) +
+		"A separate survey of 90 executives found 40% plan cuts."
+
+	got := Resolve(page, "61% of workers prefer hybrid schedules", nil)
+	want := models.Methodology{SampleSize: 2048, Population: "employees", Period: "May 2024", CollectionMethod: "telephone interviews"}
+	if got == nil || *got != want {
+		t.Errorf("Resolve() = %+v; want %+v", got, want)
+	}
+
+	// Hints the page does not support are dropped; supported ones win
+	hint := &models.Methodology{SampleSize: 5000, Period: "May 2024", CollectionMethod: "focus groups"}
+	got = Resolve(page, "61% of workers prefer hybrid schedules", hint)
+	want = models.Methodology{SampleSize: 2048, Population: "employees", Period: "May 2024", CollectionMethod: "telephone interviews"}
+	if got == nil || *got != want {
+		t.Errorf("Resolve() with hint = %+v; want %+v", got, want)
+	}
+
+	// Methodology far from the excerpt does not apply to it
+	if got := Resolve(page, "Background on remote work", nil); got != nil {
+		t.Errorf("Resolve() far from methodology = %+v; want nil", got)
+	}
+}
+
+func TestMethodologyString(t *testing.T) {
+	m := &models.Methodology{SampleSize: 1200, Population: "U.S. adults", Period: "March 1-15, 2024", MarginOfError: 3.1, CollectionMethod: "online survey"}
+	if got, want := m.String(), "n=1,200 U.S. adults; March 1-15, 2024; ±3.1 pp; online survey"; got != want {
+		t.Errorf("String() = %q; want %q", got, want)
+	}
+	if got := (*models.Methodology)(nil).String(); got != "" {
+		t.Errorf("nil String() = %q", got)
+	}
+}
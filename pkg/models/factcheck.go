@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// FactCheckRequest asks the system to check a natural-language statistical claim,
+// e.g. "Global EV sales grew 35% in 2023"
+type FactCheckRequest struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	Claim         string `json:"claim"`
+	ReputableOnly bool   `json:"reputable_only"`
+	MaxCandidates int    `json:"max_candidates"` // Maximum candidates to research (default 20)
+}
+
+// NumericClaim is the structured form of a claim extracted for searching
+type NumericClaim struct {
+	Subject string   `json:"subject"`          // What is measured, e.g. "global EV sales growth"
+	Value   *float64 `json:"value"`            // Claimed value, nil if the claim states no number
+	Unit    string   `json:"unit,omitempty"`   // e.g. "%", "million"
+	Period  string   `json:"period,omitempty"` // Time period or date, e.g. "2023"
+	Query   string   `json:"query"`            // Search topic used to find sources
+}
+
+// FactCheckVerdict is the overall outcome of a fact check
+type FactCheckVerdict string
+
+const (
+	FactCheckSupported    FactCheckVerdict = "supported"             // Verified statistics agree with the claim
+	FactCheckContradicted FactCheckVerdict = "contradicted"          // Verified statistics disagree with the claim
+	FactCheckMixed        FactCheckVerdict = "mixed"                 // Sources both support and contradict the claim
+	FactCheckInsufficient FactCheckVerdict = "insufficient_evidence" // No verified statistic addresses the claim
+)
+
+// Evidence is a verified statistic judged against a claim
+type Evidence struct {
+	Statistic   Statistic `json:"statistic"`
+	Explanation string    `json:"explanation,omitempty"`
+}
+
+// FactCheckResponse is the result of checking a claim
+type FactCheckResponse struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	Claim             string           `json:"claim"`
+	Parsed            NumericClaim     `json:"parsed"`
+	Verdict           FactCheckVerdict `json:"verdict"`
+	Explanation       string           `json:"explanation"`
+	Supporting        []Evidence       `json:"supporting"`
+	Contradicting     []Evidence       `json:"contradicting"`
+	StatisticsChecked int              `json:"statistics_checked"` // Verified statistics considered
+	Timestamp         time.Time        `json:"timestamp"`
+	CostSummary       *CostSummary     `json:"cost_summary,omitempty"` // LLM token usage, search calls, and estimated cost of the check
+}
+
+// DecideFactCheckVerdict derives the overall verdict from evidence counts
+func DecideFactCheckVerdict(supporting, contradicting int) FactCheckVerdict {
+	switch {
+	case supporting > 0 && contradicting > 0:
+		return FactCheckMixed
+	case supporting > 0:
+		return FactCheckSupported
+	case contradicting > 0:
+		return FactCheckContradicted
+	default:
+		return FactCheckInsufficient
+	}
+}
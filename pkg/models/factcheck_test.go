@@ -0,0 +1,20 @@
+package models
+
+import "testing"
+
+func TestDecideFactCheckVerdict(t *testing.T) {
+	tests := []struct {
+		supporting, contradicting int
+		want                      FactCheckVerdict
+	}{
+		{2, 0, FactCheckSupported},
+		{0, 1, FactCheckContradicted},
+		{1, 1, FactCheckMixed},
+		{0, 0, FactCheckInsufficient},
+	}
+	for _, tt := range tests {
+		if got := DecideFactCheckVerdict(tt.supporting, tt.contradicting); got != tt.want {
+			t.Errorf("DecideFactCheckVerdict(%d, %d) = %q, want %q", tt.supporting, tt.contradicting, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// FetchFailure classifies why a source could not be fetched, telling dead
+// links from network trouble
+type FetchFailure string
+
+const (
+	FetchDNS         FetchFailure = "dns"      // Host name does not resolve
+	FetchNetwork     FetchFailure = "network"  // Connection refused or reset, or DNS server unreachable
+	FetchTLS         FetchFailure = "tls"      // Certificate rejected or handshake failed
+	FetchTimeout     FetchFailure = "timeout"  // Connecting or reading took too long
+	FetchClientError FetchFailure = "http_4xx" // Page missing, gone, or forbidden
+	FetchServerError FetchFailure = "http_5xx" // Site failing, possibly for now
+	FetchRefused     FetchFailure = "content"  // Media type not read, file too large, or address not public
+	FetchOther       FetchFailure = "other"
+)
+
+// DeadLink reports whether the failure says the source is gone, rather
+// than unreachable for now
+func (f FetchFailure) DeadLink() bool {
+	return f == FetchDNS || f == FetchClientError
+}
+
+// DomainFetchFailures counts an agent's failed fetches from one domain since
+// it started
+type DomainFetchFailures struct {
+	Domain  string               `json:"domain"`
+	Total   int                  `json:"total"`
+	ByCause map[FetchFailure]int `json:"by_cause"`
+}
+
+// FetchFailureStats counts an agent's failed fetches since it started, by
+// cause and by domain, so dead links can be told from network trouble
+type FetchFailureStats struct {
+	Since   time.Time             `json:"since"`
+	Total   int                   `json:"total"`
+	ByCause map[FetchFailure]int  `json:"by_cause"`
+	Domains []DomainFetchFailures `json:"domains"` // Most failures first
+}
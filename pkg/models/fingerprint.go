@@ -0,0 +1,20 @@
+package models
+
+// SourceFingerprint identifies the text of a fetched source regardless of
+// markup, whitespace, and typographic variants. The text is split into
+// content-defined chunks, so an edit in one part of the page leaves the
+// hashes of the other chunks unchanged.
+type SourceFingerprint struct {
+	URL    string   `json:"url"`
+	Hash   string   `json:"hash"`   // "sha256:<hex>" of the normalized text
+	Chunks []string `json:"chunks"` // Hashes of the text's chunks, in page order
+}
+
+// SourceSection locates a statistic's excerpt in its source's fingerprint,
+// so a later fetch of the source shows whether the text around it changed
+type SourceSection struct {
+	Fingerprint string   `json:"fingerprint"`           // Hash of the source's normalized text when verified
+	Chunks      []string `json:"chunks"`                // Hashes of the chunks holding the excerpt
+	Anchor      string   `json:"anchor,omitempty"`      // Hash of the chunk before them
+	AnchorText  string   `json:"anchor_text,omitempty"` // Last words of that chunk, for linking to the section
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bounds accepted for Generation fields
+const (
+	MaxTemperature    = 2.0
+	MaxGenerationSize = 65536
+)
+
+// Generation sets how an LLM generates its answer. Nil and zero fields
+// leave the stage's configured value, or else the provider's default.
+type Generation struct {
+	Temperature *float32 `json:"temperature,omitempty"` // 0 to 2; lower is more deterministic
+	TopP        *float32 `json:"top_p,omitempty"`       // Nucleus sampling mass, above 0 and at most 1
+	MaxTokens   int      `json:"max_tokens,omitempty"`  // Cap on the tokens of one answer
+}
+
+// Validate rejects out-of-range settings, which providers either refuse or
+// answer with unusable output. A nil generation is valid.
+func (g *Generation) Validate() error {
+	if g == nil {
+		return nil
+	}
+	if t := g.Temperature; t != nil && (math.IsNaN(float64(*t)) || *t < 0 || *t > MaxTemperature) {
+		return fmt.Errorf("temperature must be between 0 and %g", MaxTemperature)
+	}
+	if p := g.TopP; p != nil && (math.IsNaN(float64(*p)) || *p <= 0 || *p > 1) {
+		return fmt.Errorf("top_p must be above 0 and at most 1")
+	}
+	if g.MaxTokens < 0 || g.MaxTokens > MaxGenerationSize {
+		return fmt.Errorf("max_tokens must be between 1 and %d", MaxGenerationSize)
+	}
+	return nil
+}
+
+// Merge returns g with the fields set in override applied. Either may be nil.
+func (g *Generation) Merge(override *Generation) *Generation {
+	if g == nil {
+		return override
+	}
+	if override == nil {
+		return g
+	}
+	merged := *g
+	if override.Temperature != nil {
+		merged.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		merged.TopP = override.TopP
+	}
+	if override.MaxTokens != 0 {
+		merged.MaxTokens = override.MaxTokens
+	}
+	return &merged
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGenerationValidate(t *testing.T) {
+	f := func(v float32) *float32 { return &v }
+	tests := []struct {
+		name    string
+		g       *Generation
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid", &Generation{Temperature: f(0), TopP: f(1), MaxTokens: 4096}, false},
+		{"temperature too high", &Generation{Temperature: f(2.5)}, true},
+		{"negative temperature", &Generation{Temperature: f(-0.1)}, true},
+		{"temperature NaN", &Generation{Temperature: f(float32(math.NaN()))}, true},
+		{"zero top_p", &Generation{TopP: f(0)}, true},
+		{"negative max_tokens", &Generation{MaxTokens: -1}, true},
+		{"too many tokens", &Generation{MaxTokens: MaxGenerationSize + 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.g.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerationMerge(t *testing.T) {
+	low, high := float32(0.1), float32(0.9)
+	base := &Generation{Temperature: &high, MaxTokens: 1000}
+	got := base.Merge(&Generation{Temperature: &low})
+	if *got.Temperature != low || got.MaxTokens != 1000 || *base.Temperature != high {
+		t.Errorf("Merge() = %+v, base = %+v", got, base)
+	}
+	var none *Generation
+	if none.Merge(nil) != nil || none.Merge(base) != base || base.Merge(nil) != base {
+		t.Error("merging with nil should return the other side")
+	}
+}
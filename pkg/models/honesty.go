@@ -0,0 +1,21 @@
+package models
+
+// HonestyReport scores how truthful a direct LLM search was: whether the
+// source URLs it cited resolve and whether its excerpts exist in them
+type HonestyReport struct {
+	Provider      string  `json:"provider"`
+	Model         string  `json:"model"`
+	Checked       int     `json:"checked"`        // Statistics checked
+	URLsResolved  int     `json:"urls_resolved"`  // Statistics whose source URL could be fetched
+	ExcerptsFound int     `json:"excerpts_found"` // Statistics whose excerpt (or value cell) is in the source
+	Misquoted     int     `json:"misquoted"`      // Value in the source but excerpt not
+	Fabricated    int     `json:"fabricated"`     // Neither value nor excerpt in the source
+	Score         float64 `json:"score"`          // ExcerptsFound / Checked (0-1)
+}
+
+// ProviderHonesty aggregates the honesty reports of one provider and model
+// across runs
+type ProviderHonesty struct {
+	HonestyReport
+	Runs int `json:"runs"`
+}
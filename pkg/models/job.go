@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// JobStatus is the state of a queued orchestration job
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is an orchestration request run by a queue worker (GET /jobs/{id})
+type Job struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	ID         string                 `json:"job_id"`
+	Status     JobStatus              `json:"status"`
+	Tenant     string                 `json:"tenant,omitempty"` // Tenant that submitted the job, charged for its usage
+	Request    OrchestrationRequest   `json:"request"`
+	Response   *OrchestrationResponse `json:"response,omitempty"` // Set once the job succeeded
+	Error      string                 `json:"error,omitempty"`    // Set once the job failed
+	Attempts   int                    `json:"attempts"`           // Times a worker started the job
+	CreatedAt  time.Time              `json:"created_at"`
+	StartedAt  time.Time              `json:"started_at,omitzero"`
+	FinishedAt time.Time              `json:"finished_at,omitzero"`
+}
+
+// Done reports whether the job has finished, successfully or not
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
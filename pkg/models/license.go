@@ -0,0 +1,39 @@
+package models
+
+// LicenseKind is the license or terms a source's content is published under
+type LicenseKind string
+
+const (
+	LicenseCC0          LicenseKind = "cc0"           // Creative Commons public domain dedication
+	LicensePublicDomain LicenseKind = "public_domain" // Public domain notice or mark, such as a US government work
+	LicenseCCBY         LicenseKind = "cc_by"         // Creative Commons Attribution
+	LicenseCCBYSA       LicenseKind = "cc_by_sa"      // Attribution-ShareAlike
+	LicenseCCBYNC       LicenseKind = "cc_by_nc"      // Attribution-NonCommercial
+	LicenseCCBYND       LicenseKind = "cc_by_nd"      // Attribution-NoDerivatives
+	LicenseCCBYNCSA     LicenseKind = "cc_by_nc_sa"   // Attribution-NonCommercial-ShareAlike
+	LicenseCCBYNCND     LicenseKind = "cc_by_nc_nd"   // Attribution-NonCommercial-NoDerivatives
+	LicenseOGL          LicenseKind = "ogl"           // UK Open Government Licence
+	LicenseCopyright    LicenseKind = "copyright"     // Copyright notice without an open license
+	LicenseOther        LicenseKind = "other"         // Declared license or terms not recognized
+)
+
+// License is what a source says about reusing its content: a declared
+// license, or a copyright or public-domain notice. It is a hint read from
+// the page, not legal advice.
+type License struct {
+	Kind       LicenseKind `json:"kind"`
+	URL        string      `json:"url,omitempty"`    // License deed or terms page the source links
+	Notice     string      `json:"notice,omitempty"` // Rights statement or notice as the source words it
+	Permissive bool        `json:"permissive"`       // Excerpts may be republished, with attribution at most
+}
+
+// PermissiveLicense reports whether content under kind may be republished,
+// commercially too, with attribution at most. NonCommercial and
+// NoDerivatives licenses are not permissive.
+func PermissiveLicense(kind LicenseKind) bool {
+	switch kind {
+	case LicenseCC0, LicensePublicDomain, LicenseCCBY, LicenseCCBYSA, LicenseOGL:
+		return true
+	}
+	return false
+}
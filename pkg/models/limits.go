@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bounds accepted for StageLimits fields
+const (
+	MaxPagesLimit               = 100
+	MaxCandidatesPerPageCap     = 200
+	MaxVerificationBufferFactor = 20.0
+	MinVerificationBufferFactor = 1.0
+)
+
+// MaxVerifyCandidates is the most candidates a client may send to the
+// orchestrators' /verify in one request. Each costs page fetches and LLM
+// calls, so a larger batch is split into several requests.
+const MaxVerifyCandidates = 100
+
+// StageLimits bound the work of one research, synthesis, and verification
+// pass. Zero fields fall back to the configured defaults.
+type StageLimits struct {
+	// MaxPages is the number of sources research returns and synthesis
+	// reads per pass
+	MaxPages int `json:"max_pages,omitempty"`
+	// CandidatesPerPageCap is the most candidates synthesis keeps from a
+	// single page, so one large table cannot use up the budget
+	CandidatesPerPageCap int `json:"candidates_per_page_cap,omitempty"`
+	// VerificationBufferFactor is how many candidates are gathered per
+	// statistic still needed, to allow for candidates failing verification
+	VerificationBufferFactor float64 `json:"verification_buffer_factor,omitempty"`
+}
+
+// Validate rejects negative or out-of-range limits. Zero fields are valid
+// and mean "use the default".
+func (l StageLimits) Validate() error {
+	if l.MaxPages < 0 || l.MaxPages > MaxPagesLimit {
+		return fmt.Errorf("max_pages must be between 1 and %d", MaxPagesLimit)
+	}
+	if l.CandidatesPerPageCap < 0 || l.CandidatesPerPageCap > MaxCandidatesPerPageCap {
+		return fmt.Errorf("candidates_per_page_cap must be between 1 and %d", MaxCandidatesPerPageCap)
+	}
+	f := l.VerificationBufferFactor
+	if math.IsNaN(f) || (f != 0 && (f < MinVerificationBufferFactor || f > MaxVerificationBufferFactor)) {
+		return fmt.Errorf("verification_buffer_factor must be between %g and %g",
+			MinVerificationBufferFactor, MaxVerificationBufferFactor)
+	}
+	return nil
+}
+
+// Budget returns the candidates to gather for needed verified statistics:
+// needed scaled by the buffer factor, rounded up. A zero factor adds no
+// buffer.
+func (l StageLimits) Budget(needed int) int {
+	if needed <= 0 {
+		return 0
+	}
+	if l.VerificationBufferFactor <= 0 {
+		return needed
+	}
+	return int(math.Ceil(float64(needed) * l.VerificationBufferFactor))
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStageLimitsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  StageLimits
+		wantErr bool
+	}{
+		{"zero", StageLimits{}, false},
+		{"valid", StageLimits{MaxPages: 10, CandidatesPerPageCap: 20, VerificationBufferFactor: 2.5}, false},
+		{"negative pages", StageLimits{MaxPages: -1}, true},
+		{"too many pages", StageLimits{MaxPages: MaxPagesLimit + 1}, true},
+		{"negative cap", StageLimits{CandidatesPerPageCap: -5}, true},
+		{"factor below one", StageLimits{VerificationBufferFactor: 0.5}, true},
+		{"factor too large", StageLimits{VerificationBufferFactor: 50}, true},
+		{"factor NaN", StageLimits{VerificationBufferFactor: math.NaN()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStageLimitsBudget(t *testing.T) {
+	tests := []struct {
+		factor float64
+		needed int
+		want   int
+	}{
+		{0, 7, 7},
+		{1, 7, 7},
+		{2.5, 3, 8},
+		{5, 10, 50},
+		{5, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := (StageLimits{VerificationBufferFactor: tt.factor}).Budget(tt.needed); got != tt.want {
+			t.Errorf("Budget(%d) with factor %g = %d, want %d", tt.needed, tt.factor, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+// ModelOverride selects the LLM used for a run or a single stage
+type ModelOverride struct {
+	Provider string `json:"provider,omitempty"` // Defaults to the configured LLM_PROVIDER
+	Model    string `json:"model,omitempty"`    // Defaults to the provider's default model
+}
+
+// IsZero reports whether the override selects nothing
+func (o *ModelOverride) IsZero() bool {
+	return o == nil || (o.Provider == "" && o.Model == "")
+}
+
+// RunModel returns the run-level model override, or nil if none was requested
+func (r *OrchestrationRequest) RunModel() *ModelOverride {
+	o := &ModelOverride{Provider: r.LLMProvider, Model: r.LLMModel}
+	if o.IsZero() {
+		return nil
+	}
+	return o
+}
+
+// SynthesisOverride returns the model override for statistic extraction
+func (r *OrchestrationRequest) SynthesisOverride() *ModelOverride {
+	if !r.SynthesisModel.IsZero() {
+		return r.SynthesisModel
+	}
+	return r.RunModel()
+}
+
+// VerificationOverride returns the model override for verification
+func (r *OrchestrationRequest) VerificationOverride() *ModelOverride {
+	if !r.VerificationModel.IsZero() {
+		return r.VerificationModel
+	}
+	return r.RunModel()
+}
+
+// Sampling returns the LLM sampling a reproducible run pins, or nil
+func (r *OrchestrationRequest) Sampling() *Sampling {
+	if !r.Reproducible {
+		return nil
+	}
+	return &Sampling{Temperature: 0, Seed: r.Seed}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Methodology is how a statistic was measured, as stated near it in the
+// source. "75% of 12 respondents" and "75% of 75,000 respondents" differ
+// only here. Every field is optional.
+type Methodology struct {
+	SampleSize       int     `json:"sample_size,omitempty"`       // Number of respondents or observations
+	Population       string  `json:"population,omitempty"`        // Who was sampled (e.g. "U.S. adults")
+	Period           string  `json:"period,omitempty"`            // When the data was collected (e.g. "March 1-15, 2024")
+	MarginOfError    float32 `json:"margin_of_error,omitempty"`   // Plus or minus, in percentage points
+	CollectionMethod string  `json:"collection_method,omitempty"` // How the data was collected (e.g. "online survey")
+}
+
+// IsZero reports whether no methodology was found
+func (m *Methodology) IsZero() bool {
+	return m == nil || *m == Methodology{}
+}
+
+// String summarizes the methodology, e.g.
+// "n=1,200 U.S. adults; March 1-15, 2024; ±3 pp; online survey"
+func (m *Methodology) String() string {
+	if m.IsZero() {
+		return ""
+	}
+	var parts []string
+	switch {
+	case m.SampleSize > 0 && m.Population != "":
+		parts = append(parts, "n="+groupThousands(m.SampleSize)+" "+m.Population)
+	case m.SampleSize > 0:
+		parts = append(parts, "n="+groupThousands(m.SampleSize))
+	case m.Population != "":
+		parts = append(parts, m.Population)
+	}
+	if m.Period != "" {
+		parts = append(parts, m.Period)
+	}
+	if m.MarginOfError > 0 {
+		parts = append(parts, "±"+strconv.FormatFloat(float64(m.MarginOfError), 'f', -1, 32)+" pp")
+	}
+	if m.CollectionMethod != "" {
+		parts = append(parts, m.CollectionMethod)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// groupThousands formats n with comma thousands separators
+func groupThousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+// Package migrate upgrades request and response payloads written by older
+// agents to the current schema, so agents of different versions can talk to
+// each other during a rolling deployment. Payloads from newer agents decode
+// as far as this build understands them: fields it does not know are
+// ignored.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// step upgrades a payload by one schema version. raw is the payload as a
+// JSON object and v the model it will be decoded into.
+type step func(v any, raw map[string]any)
+
+// steps[i] upgrades version i+1 to version i+2
+var steps = []step{upgradeV1}
+
+// Decode reads a JSON payload from r into v, a pointer to a request or
+// response model, upgrading it to the current schema first
+func Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(data, v)
+}
+
+// Unmarshal is Decode for a payload already read
+func Unmarshal(data []byte, v any) error {
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // Keep numbers exact through the round trip
+	if err := dec.Decode(&raw); err != nil || raw == nil {
+		// Not an object; decode as is so the caller gets the usual error
+		return json.Unmarshal(data, v)
+	}
+
+	version := Version(raw)
+	if version >= models.SchemaVersion {
+		return json.Unmarshal(data, v)
+	}
+	for _, upgrade := range steps[version-1:] {
+		upgrade(v, raw)
+	}
+	raw["schema_version"] = models.SchemaVersion
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(upgraded, v)
+}
+
+// Version returns the schema version of a payload: its schema_version, or
+// 1 for payloads that have none or an invalid one
+func Version(raw map[string]any) int {
+	n, ok := raw["schema_version"].(json.Number)
+	if !ok {
+		return 1
+	}
+	v, err := n.Int64()
+	if err != nil || v < 1 {
+		return 1
+	}
+	// Versions newer than this build's are all treated alike
+	return int(min(v, int64(models.SchemaVersion)+1))
+}
+
+// upgradeV1 fills in what unversioned payloads lack: the status of an
+// orchestration response, derived from its partial flag, and the category
+// of failed verification results, derived from the reasons agents gave
+// before categories existed
+func upgradeV1(v any, raw map[string]any) {
+	switch v.(type) {
+	case *models.OrchestrationResponse:
+		if _, ok := raw["status"]; !ok {
+			status := models.StatusComplete
+			if partial, _ := raw["partial"].(bool); partial {
+				status = models.StatusPartial
+			}
+			raw["status"] = status
+		}
+		categorize(raw["rejected"])
+	case *models.VerificationResponse:
+		categorize(raw["results"])
+	}
+}
+
+// Failure reasons given by unversioned verification agents
+var legacyReasons = []struct {
+	prefix   string
+	category models.FailureCategory
+}{
+	{"Failed to fetch source", models.FailureFetch},
+	{"Excerpt not found", models.FailureExcerptNotFound},
+}
+
+// categorize sets the category of each failed verification result in a
+// JSON array that has none and whose reason is a known legacy reason
+func categorize(results any) {
+	list, _ := results.([]any)
+	for _, item := range list {
+		result, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if verified, _ := result["verified"].(bool); verified {
+			continue
+		}
+		if category, _ := result["category"].(string); category != "" {
+			continue
+		}
+		reason, _ := result["reason"].(string)
+		for _, legacy := range legacyReasons {
+			if strings.HasPrefix(reason, legacy.prefix) {
+				result["category"] = legacy.category
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestUnmarshalUpgradesUnversionedOrchestrationResponse(t *testing.T) {
+	payload := `{
+		"topic": "ev sales",
+		"verified_count": 1,
+		"target_count": 5,
+		"partial": true,
+		"rejected": [
+			{"verified": false, "reason": "Failed to fetch source: HTTP 404"},
+			{"verified": false, "reason": "Excerpt not found in source content"},
+			{"verified": false, "reason": "Value differs", "category": "value_mismatch"}
+		]
+	}`
+
+	var resp models.OrchestrationResponse
+	if err := Unmarshal([]byte(payload), &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if resp.SchemaVersion != models.SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", resp.SchemaVersion, models.SchemaVersion)
+	}
+	if resp.Status != models.StatusPartial {
+		t.Errorf("Status = %q, want %q", resp.Status, models.StatusPartial)
+	}
+	want := []models.FailureCategory{models.FailureFetch, models.FailureExcerptNotFound, models.FailureValueMismatch}
+	for i, r := range resp.Rejected {
+		if r.Category != want[i] {
+			t.Errorf("Rejected[%d].Category = %q, want %q", i, r.Category, want[i])
+		}
+	}
+}
+
+func TestUnmarshalLeavesCurrentPayloadsAlone(t *testing.T) {
+	payload := `{"schema_version": 2, "topic": "ev sales", "partial": false}`
+
+	var resp models.OrchestrationResponse
+	if err := Unmarshal([]byte(payload), &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if resp.Status != "" {
+		t.Errorf("Status = %q, want it left empty", resp.Status)
+	}
+}
+
+func TestUnmarshalIgnoresFieldsFromNewerVersions(t *testing.T) {
+	payload := `{"schema_version": 99, "topic": "ev sales", "min_statistics": 3, "some_future_field": {"x": 1}}`
+
+	var req models.ResearchRequest
+	if err := Unmarshal([]byte(payload), &req); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if req.Topic != "ev sales" || req.MinStatistics != 3 || req.SchemaVersion != 99 {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestUnmarshalKeepsTypeErrors(t *testing.T) {
+	var req models.ResearchRequest
+	if err := Unmarshal([]byte(`{"topic": 5}`), &req); err == nil {
+		t.Error("Unmarshal() of a mistyped field succeeded, want an error")
+	}
+	if err := Decode(strings.NewReader(`[1, 2]`), &req); err == nil {
+		t.Error("Decode() of an array succeeded, want an error")
+	}
+}
+
+func TestVersionEncodesCurrentSchema(t *testing.T) {
+	data, err := json.Marshal(models.SynthesisRequest{Topic: "ev sales"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := fmt.Sprintf(`"schema_version":%d`, models.SchemaVersion)
+	if !strings.Contains(string(data), want) {
+		t.Errorf("encoded request %s lacks %s", data, want)
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Page describes the slice of a statistics list returned in one response
+type Page struct {
+	Offset     int `json:"offset"`                // Index of the first statistic returned
+	Limit      int `json:"limit"`                 // Most statistics returned per page; 0 for no limit
+	Total      int `json:"total"`                 // Statistics in the whole list
+	NextOffset int `json:"next_offset,omitempty"` // Offset of the next page; 0 on the last page
+}
+
+// ParsePage reads the offset and limit query parameters. Both default to 0,
+// which returns the whole list.
+func ParsePage(q url.Values) (offset, limit int, err error) {
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"offset", &offset}, {"limit", &limit}} {
+		s := q.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("%s must be a non-negative integer, got %q", p.name, s)
+		}
+		*p.dst = n
+	}
+	return offset, limit, nil
+}
+
+// Paginate returns the statistics (or other items) from offset, at most
+// limit of them (all when limit is 0), and the page describing them
+func Paginate[T any](stats []T, offset, limit int) ([]T, *Page) {
+	page := &Page{Offset: offset, Limit: limit, Total: len(stats)}
+	start := min(offset, len(stats))
+	end := len(stats)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+		page.NextOffset = end
+	}
+	return stats[start:end], page
+}
+
+// Paginate trims the response's statistics to one page and records the
+// page, leaving the response unchanged when neither offset nor limit is set.
+// Counts such as VerifiedCount still describe the whole run.
+func (r *OrchestrationResponse) Paginate(offset, limit int) {
+	if offset == 0 && limit == 0 {
+		return
+	}
+	r.Statistics, r.Page = Paginate(r.Statistics, offset, limit)
+}
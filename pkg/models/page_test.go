@@ -0,0 +1,59 @@
+package models
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	stats := make([]Statistic, 5)
+	for i := range stats {
+		stats[i].Name = string(rune('a' + i))
+	}
+
+	tests := []struct {
+		offset, limit int
+		want          string
+		next          int
+	}{
+		{0, 2, "ab", 2},
+		{2, 2, "cd", 4},
+		{4, 2, "e", 0},
+		{3, 0, "de", 0},
+		{9, 2, "", 0},
+	}
+	for _, tt := range tests {
+		got, page := Paginate(stats, tt.offset, tt.limit)
+		var names string
+		for _, s := range got {
+			names += s.Name
+		}
+		if names != tt.want || page.NextOffset != tt.next || page.Total != len(stats) {
+			t.Errorf("Paginate(%d, %d) = %q, %+v; want %q with next %d", tt.offset, tt.limit, names, page, tt.want, tt.next)
+		}
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	offset, limit, err := ParsePage(url.Values{"offset": {"20"}, "limit": {"10"}})
+	if err != nil || offset != 20 || limit != 10 {
+		t.Errorf("ParsePage = %d, %d, %v", offset, limit, err)
+	}
+	for _, q := range []url.Values{{"limit": {"-1"}}, {"offset": {"ten"}}} {
+		if _, _, err := ParsePage(q); err == nil {
+			t.Errorf("ParsePage(%v) should fail", q)
+		}
+	}
+}
+
+func TestOrchestrationResponsePaginate(t *testing.T) {
+	resp := &OrchestrationResponse{Statistics: make([]Statistic, 3), VerifiedCount: 3}
+	resp.Paginate(0, 0)
+	if resp.Page != nil || len(resp.Statistics) != 3 {
+		t.Fatalf("Paginate(0, 0) changed the response: %+v", resp)
+	}
+	resp.Paginate(0, 2)
+	if len(resp.Statistics) != 2 || resp.Page.Total != 3 || resp.VerifiedCount != 3 {
+		t.Errorf("Paginate(0, 2) = %d statistics, page %+v", len(resp.Statistics), resp.Page)
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+// RunPlan is what an orchestration would do, returned in place of results
+// for a dry_run request: the sources search selected, the providers that
+// would be called, and the LLM usage of reading them
+type RunPlan struct {
+	Sources        []PlannedSource `json:"sources"`
+	SearchProvider string          `json:"search_provider"`
+	Adapters       []string        `json:"adapters,omitempty"`        // Extraction adapters that would read some sources
+	EstimatedUsage *CostSummary    `json:"estimated_usage,omitempty"` // LLM calls, tokens, and cost of one pass, by stage and model
+	MaxPasses      int             `json:"max_passes"`                // Passes a run makes at most while below its target; the estimate covers the first
+}
+
+// How a planned source would be read
+const (
+	ExtractionLLM      = "llm"       // Page text sent to the synthesis model
+	ExtractionDataFile = "data_file" // CSV, JSON, or XLSX parsed without an LLM
+	ExtractionAdapter  = "adapter"   // Read by a site adapter, without an LLM
+)
+
+// PlannedSource is a source a run would read
+type PlannedSource struct {
+	URL        string `json:"url"`
+	Domain     string `json:"domain"`
+	Title      string `json:"title,omitempty"`
+	Extraction string `json:"extraction"`        // llm, data_file, or adapter
+	Adapter    string `json:"adapter,omitempty"` // Adapter name, when Extraction is adapter
+}
@@ -0,0 +1,24 @@
+package models
+
+// Sampling pins how an LLM samples its output. Providers that take no seed
+// (Claude) still sample at the temperature.
+type Sampling struct {
+	Temperature float32 `json:"temperature"`
+	Seed        int32   `json:"seed,omitempty"`
+}
+
+// Reproducibility records what a reproducible run pinned and the
+// intermediate artifacts it stored, in the order they were produced. The
+// model versions that answered are in the cost summary.
+type Reproducibility struct {
+	Sampling
+	Artifacts []Artifact `json:"artifacts"`
+	Stored    bool       `json:"stored"` // False when an artifact was not archived (no ARCHIVE_BACKEND), so only its hash was kept
+}
+
+// Artifact is a request or response exchanged between the agents during a
+// run, stored as JSON in the source archive under its content hash
+type Artifact struct {
+	Name string `json:"name"` // Order and kind, e.g. "03-synthesis-request"
+	Hash string `json:"hash"` // "sha256:<hex>" of the JSON
+}
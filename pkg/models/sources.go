@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Bounds accepted for a request's listed sources
+const (
+	MaxSources       = 100
+	MaxDocumentBytes = 10 << 20 // Largest document sent inline
+	MaxSourcesBytes  = 50 << 20 // Largest total of the documents of one request
+
+	// MaxRequestBytes is the largest orchestration request body: its
+	// documents, base64 in JSON, and room for the rest of the request
+	MaxRequestBytes = MaxSourcesBytes*4/3 + 1<<20
+)
+
+// InputSource is a source a request asks to read instead of searching the
+// web: a page or file at URL, or a document sent inline in Data
+type InputSource struct {
+	URL         string `json:"url,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Filename    string `json:"filename,omitempty"`     // Name of an inline document, shown as its source
+	ContentType string `json:"content_type,omitempty"` // Media type of Data; guessed from Filename when empty
+	Data        []byte `json:"data,omitempty"`         // Document body, base64 in JSON
+}
+
+// IsDocument reports whether the source is sent inline rather than fetched
+func (s InputSource) IsDocument() bool {
+	return len(s.Data) > 0
+}
+
+// ValidateSources checks a request's listed sources: each is either an
+// absolute http(s) URL or an inline document, within the size limits
+func ValidateSources(sources []InputSource) error {
+	if len(sources) > MaxSources {
+		return fmt.Errorf("sources must list at most %d sources", MaxSources)
+	}
+	total := 0
+	for i, s := range sources {
+		switch {
+		case s.URL != "" && s.IsDocument():
+			return fmt.Errorf("sources[%d] must have a url or data, not both", i)
+		case s.IsDocument():
+			if len(s.Data) > MaxDocumentBytes {
+				return fmt.Errorf("sources[%d] is larger than %d MB", i, MaxDocumentBytes>>20)
+			}
+			total += len(s.Data)
+		case s.URL == "":
+			return fmt.Errorf("sources[%d] needs a url or data", i)
+		default:
+			if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("sources[%d] url %q is not an http(s) URL", i, s.URL)
+			}
+		}
+	}
+	if total > MaxSourcesBytes {
+		return fmt.Errorf("sources documents total more than %d MB", MaxSourcesBytes>>20)
+	}
+	return nil
+}
+
+// HasDocuments reports whether any listed source is sent inline
+func (r *OrchestrationRequest) HasDocuments() bool {
+	for _, s := range r.Sources {
+		if s.IsDocument() {
+			return true
+		}
+	}
+	return false
+}
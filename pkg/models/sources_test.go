@@ -0,0 +1,37 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []InputSource
+		wantErr string
+	}{
+		{"none", nil, ""},
+		{"url and document", []InputSource{{URL: "https://example.com/report"}, {Filename: "q3.html", Data: []byte("<p>12%</p>")}}, ""},
+		{"both", []InputSource{{URL: "https://example.com/report", Data: []byte("x")}}, "not both"},
+		{"neither", []InputSource{{Title: "Report"}}, "needs a url or data"},
+		{"not http", []InputSource{{URL: "file:///etc/passwd"}}, "not an http(s) URL"},
+		{"relative", []InputSource{{URL: "/report"}}, "not an http(s) URL"},
+		{"document too large", []InputSource{{Data: make([]byte, MaxDocumentBytes+1)}}, "larger than"},
+		{"too many", make([]InputSource, MaxSources+1), "at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSources(tt.sources)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateSources() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateSources() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 // Statistic represents a verified statistic with its source
 type Statistic struct {
@@ -12,95 +15,459 @@ type Statistic struct {
 	Excerpt   string    `json:"excerpt"`    // Verbatim quote containing the statistic
 	Verified  bool      `json:"verified"`   // Whether this has been verified by verification agent
 	DateFound time.Time `json:"date_found"` // When this statistic was found
+
+	Type StatisticType `json:"type,omitempty"` // Kind of evidence: survey, measured, projection, forecast, or self_reported
+
+	Publisher   string    `json:"publisher,omitempty"`   // Publisher the source page declares (og:site_name, schema.org)
+	PublishedAt time.Time `json:"published_at,omitzero"` // Publication date the source page declares
+	License     *License  `json:"license,omitempty"`     // License or copyright notice the source page declares
+
+	Provenance  *Provenance    `json:"provenance,omitempty"`   // Cell location for statistics read from data files or tables
+	Document    *DocumentPage  `json:"document,omitempty"`     // File and page of statistics read from an uploaded or listed document, or a PDF or Word file
+	ContentHash string         `json:"content_hash,omitempty"` // SHA-256 of the source content that was verified ("sha256:<hex>")
+	Section     *SourceSection `json:"section,omitempty"`      // Where the excerpt sits in the source's text, to detect later changes
+	Methodology *Methodology   `json:"methodology,omitempty"`  // Sample size, collection period, and method stated near the statistic
+	Attribution *Attribution   `json:"attribution,omitempty"`  // Organization the quoting page credits for the figure
+
+	ConflictGroup string `json:"conflict_group,omitempty"` // Shared with the statistics in the same response it contradicts; see conflicts
+
+	OCRDerived bool   `json:"ocr_derived,omitempty"` // Read from a chart or infographic by a vision model; the excerpt is the figure's text
+	ImageURL   string `json:"image_url,omitempty"`   // The figure it was read from
+
+	CorroboratedBy []Corroboration `json:"corroborated_by,omitempty"` // Other sources reporting the same statistic
+
+	SafetyFlags []string `json:"safety_flags,omitempty"` // Safety categories its source or excerpt matches (SAFETY_RULES_FILE)
+}
+
+// FormatValue renders a value with its unit, keeping every significant
+// digit of the value. A percent sign is attached; other units follow a space.
+func FormatValue(value float32, unit string) string {
+	s := strconv.FormatFloat(float64(value), 'f', -1, 32)
+	if unit == "" {
+		return s
+	}
+	if unit == "%" {
+		return s + unit
+	}
+	return s + " " + unit
+}
+
+// Corroboration is another source reporting the same statistic, merged into
+// its best-sourced representative during semantic deduplication
+type Corroboration struct {
+	Name       string  `json:"name"`
+	Source     string  `json:"source"`
+	SourceURL  string  `json:"source_url"`
+	Excerpt    string  `json:"excerpt,omitempty"`
+	Similarity float64 `json:"similarity"` // Cosine similarity of name and excerpt to the representative
+}
+
+// Conflict is a group of statistics in one response that give different
+// values for the same metric and period
+type Conflict struct {
+	ID     string   `json:"id"`     // The conflict_group of its statistics
+	Metric string   `json:"metric"` // Name of the group's first statistic
+	Values []string `json:"values"` // Each statistic's value and source, e.g. "14 million (iea.org)"
+}
+
+// Series is one metric over several years from one source, assembled from
+// the statistics that differ only in their year, ready to chart. Those
+// statistics are still listed individually with their excerpts.
+type Series struct {
+	Metric    string        `json:"metric"` // Statistic name without its year
+	Unit      string        `json:"unit"`
+	Source    string        `json:"source"`
+	SourceURL string        `json:"source_url"`
+	Points    []SeriesPoint `json:"points"` // By year, oldest first
+}
+
+// SeriesPoint is a series' value for one year
+type SeriesPoint struct {
+	Year  int     `json:"year"`
+	Value float32 `json:"value"`
+}
+
+// Narrative is a short prose summary of a response's statistics, written by
+// an LLM for users who want copy-ready text. It is not itself verified: each
+// marker such as "[2]" in Text refers to the statistic in Citations with that
+// marker, whose value and excerpt were verified.
+type Narrative struct {
+	Text      string              `json:"text"`
+	Citations []NarrativeCitation `json:"citations"`
+	Model     string              `json:"model,omitempty"` // Provider and model that wrote the text
+	// Dropped counts the sentences removed because a figure they cite
+	// does not appear in them
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// NarrativeCitation maps a narrative's marker to a statistic of the response
+type NarrativeCitation struct {
+	Marker    int     `json:"marker"`     // The number in brackets in the text
+	Name      string  `json:"name"`       // The cited statistic's name
+	Value     float32 `json:"value"`      // The cited statistic's value
+	Unit      string  `json:"unit"`       // The cited statistic's unit
+	Source    string  `json:"source"`     // The cited statistic's source
+	SourceURL string  `json:"source_url"` // The cited statistic's source URL
+}
+
+// Attribution records that the page a statistic was found on credits the
+// figure to another organization ("according to the WHO ..."). When the
+// statistic was verified against the page the quote links to, that page is
+// the statistic's source and CitedBy is the page that quoted it; otherwise the
+// statistic keeps the quoting page as its source and Reason says why.
+type Attribution struct {
+	Organization string        `json:"organization"`       // Organization the quoting page credits
+	URL          string        `json:"url,omitempty"`      // Link the quoting page gives to it
+	Verified     bool          `json:"verified"`           // Whether the statistic was verified against URL
+	Reason       string        `json:"reason,omitempty"`   // Why it was not
+	CitedBy      *QuotedSource `json:"cited_by,omitempty"` // The page the statistic was found on, when verified against URL
+}
+
+// QuotedSource is a page that quoted a statistic from its primary source
+type QuotedSource struct {
+	Source    string `json:"source"`
+	SourceURL string `json:"source_url"`
+	Excerpt   string `json:"excerpt"`
 }
 
 // CandidateStatistic represents an unverified statistic from research
 type CandidateStatistic struct {
-	Name      string  `json:"name"`
-	Value     float32 `json:"value"`
-	Unit      string  `json:"unit"`
-	Source    string  `json:"source"`
-	SourceURL string  `json:"source_url"`
-	Excerpt   string  `json:"excerpt"`
+	Name       string      `json:"name"`
+	Value      float32     `json:"value"`
+	Unit       string      `json:"unit"`
+	Source     string      `json:"source"`
+	SourceURL  string      `json:"source_url"`
+	Excerpt    string      `json:"excerpt"`
+	Provenance *Provenance `json:"provenance,omitempty"` // Cell location for statistics read from data files or tables
+
+	Document *DocumentPage `json:"document,omitempty"` // File and page of statistics read from an uploaded or listed document, or a PDF or Word file
+
+	Type StatisticType `json:"type,omitempty"` // Kind of evidence: survey, measured, projection, forecast, or self_reported
+
+	Publisher   string    `json:"publisher,omitempty"`   // Publisher the source page declares (og:site_name, schema.org)
+	PublishedAt time.Time `json:"published_at,omitzero"` // Publication date the source page declares
+
+	Methodology *Methodology `json:"methodology,omitempty"` // Sample size, collection period, and method stated near the statistic
+
+	OCRDerived bool   `json:"ocr_derived,omitempty"` // Read from a chart or infographic by a vision model; verified by re-reading the figure
+	ImageURL   string `json:"image_url,omitempty"`   // The figure it was read from
+
+	ContentHash string `json:"content_hash,omitempty"` // SHA-256 of the source content it was extracted from ("sha256:<hex>"), shared through the snapshot archive
+
+	Internal bool `json:"internal,omitempty"` // Read from an internal search result, so its source is fetched with the site credentials; cleared on candidates from requests
+}
+
+// Statistic returns the candidate as a statistic with the given verdict,
+// carrying over its value, unit, source, excerpt, provenance, document page,
+// type, publication, methodology, and figure
+func (c CandidateStatistic) Statistic(verified bool, found time.Time) Statistic {
+	return Statistic{
+		Name:        c.Name,
+		Value:       c.Value,
+		Unit:        c.Unit,
+		Source:      c.Source,
+		SourceURL:   c.SourceURL,
+		Excerpt:     c.Excerpt,
+		Verified:    verified,
+		DateFound:   found,
+		Provenance:  c.Provenance,
+		Document:    c.Document,
+		Type:        c.Type,
+		Publisher:   c.Publisher,
+		PublishedAt: c.PublishedAt,
+		Methodology: c.Methodology,
+		OCRDerived:  c.OCRDerived,
+		ImageURL:    c.ImageURL,
+	}
+}
+
+// Provenance locates a statistic inside a data file (CSV, JSON, XLSX) or an HTML table
+type Provenance struct {
+	Format string `json:"format"`          // Data format: "csv", "json", "xlsx", or "html"
+	Sheet  string `json:"sheet,omitempty"` // Worksheet name (XLSX) or table label such as "table 2" (HTML)
+	Row    int    `json:"row"`             // 1-based row number within the file or sheet
+	Column string `json:"column"`          // Column header the value was read from
+}
+
+// DocumentPage locates a statistic in a document file rather than a web page
+type DocumentPage struct {
+	Filename string `json:"filename"`       // Name of the file, as uploaded or listed, or taken from its URL
+	Page     int    `json:"page,omitempty"` // 1-based page of a PDF or Word document showing the excerpt; 0 when unknown
 }
 
 // VerificationResult represents the result of verifying a statistic
 type VerificationResult struct {
-	Statistic *Statistic `json:"statistic"`
-	Verified  bool       `json:"verified"`
-	Reason    string     `json:"reason,omitempty"` // Why verification failed (if applicable)
+	Statistic *Statistic      `json:"statistic"`
+	Verified  bool            `json:"verified"`
+	Reason    string          `json:"reason,omitempty"`   // Why verification failed (if applicable)
+	Category  FailureCategory `json:"category,omitempty"` // Machine-readable failure category
+	Method    string          `json:"method,omitempty"`   // How the verdict was reached: "exact", "fuzzy", "cell", "llm", or "vision"
+
+	FetchFailure FetchFailure `json:"fetch_failure,omitempty"` // Why the source could not be fetched, with category fetch_failed
 }
 
+// FailureCategory classifies why a statistic failed verification
+type FailureCategory string
+
+const (
+	FailureFetch           FailureCategory = "fetch_failed"      // Source could not be retrieved
+	FailureExcerptNotFound FailureCategory = "excerpt_not_found" // Excerpt absent and no supporting passage
+	FailureValueMismatch   FailureCategory = "value_mismatch"    // Source states a different value
+	FailureContext         FailureCategory = "context_mismatch"  // Value present but describes something else
+	FailureParse           FailureCategory = "parse_error"       // Data file or table could not be parsed
+	FailureLLM             FailureCategory = "llm_error"         // LLM verification call failed
+	FailurePolicy          FailureCategory = "policy_rejected"   // Source verified but not accepted by SOURCE_POLICY
+	FailureSafety          FailureCategory = "safety_blocked"    // Source or excerpt matches a blocking safety category
+	FailureLicense         FailureCategory = "license_excluded"  // Source verified but not permissively licensed, with permissive_license_only
+)
+
 // ResearchRequest represents a request to find statistics
 type ResearchRequest struct {
+	SchemaVersion Version `json:"schema_version"`
+
 	Topic         string `json:"topic"`
-	MinStatistics int    `json:"min_statistics"` // Minimum number of statistics to find
-	MaxStatistics int    `json:"max_statistics"` // Maximum number of statistics to find
-	ReputableOnly bool   `json:"reputable_only"` // Only search reputable sources
+	MinStatistics int    `json:"min_statistics"`         // Minimum number of statistics to find
+	MaxStatistics int    `json:"max_statistics"`         // Maximum number of statistics to find
+	ReputableOnly bool   `json:"reputable_only"`         // Only search reputable sources
+	Offset        int    `json:"offset,omitempty"`       // Skip this many search results (the next_offset of a previous response)
+	Query         string `json:"query,omitempty"`        // Search query to use instead of the topic, e.g. the relaxed query of a previous response
+	Reproducible  bool   `json:"reproducible,omitempty"` // Skip only the configured domains, not those demoted by past runs
 }
 
 // ResearchResponse represents the response from research agent
 type ResearchResponse struct {
-	Topic      string               `json:"topic"`
-	Candidates []CandidateStatistic `json:"candidates"`
-	Timestamp  time.Time            `json:"timestamp"`
+	SchemaVersion Version `json:"schema_version"`
+
+	Topic       string               `json:"topic"`
+	Candidates  []CandidateStatistic `json:"candidates"`
+	Timestamp   time.Time            `json:"timestamp"`
+	NextOffset  int                  `json:"next_offset,omitempty"`  // Offset of the next page of results; omitted when search results are exhausted
+	Query       string               `json:"query,omitempty"`        // Relaxed search query used because the topic found nothing
+	SearchCalls int                  `json:"search_calls,omitempty"` // Web searches run for this request
 }
 
 // VerificationRequest represents a request to verify statistics
 type VerificationRequest struct {
+	SchemaVersion Version `json:"schema_version"`
+
 	Candidates []CandidateStatistic `json:"candidates"`
+	Model      *ModelOverride       `json:"model,omitempty"`      // Per-request LLM override
+	Generation *Generation          `json:"generation,omitempty"` // Per-request generation settings
+	Sampling   *Sampling            `json:"sampling,omitempty"`   // Pinned sampling of a reproducible run
+
+	PermissiveLicenseOnly bool `json:"permissive_license_only,omitempty"` // Reject statistics whose source is not permissively licensed
 }
 
 // VerificationResponse represents the response from verification agent
 type VerificationResponse struct {
+	SchemaVersion Version `json:"schema_version"`
+
 	Results   []VerificationResult `json:"results"`
 	Verified  int                  `json:"verified_count"`
 	Failed    int                  `json:"failed_count"`
 	Timestamp time.Time            `json:"timestamp"`
+	Usage     *CostSummary         `json:"usage,omitempty"`   // LLM usage of this verification pass
+	Timings   *Timings             `json:"timings,omitempty"` // Fetch and verification time of this pass
+	Sources   []SourceFingerprint  `json:"sources,omitempty"` // Fingerprints of the sources fetched
+
+	FetchFailures map[FetchFailure]int `json:"fetch_failures,omitempty"` // Candidates whose source could not be fetched, by cause
+}
+
+// VerificationEvent is a line of a streamed verification response: the
+// result of each candidate as soon as it is final, in the order they
+// finish, and last the whole response
+type VerificationEvent struct {
+	Result   *VerificationResult   `json:"result,omitempty"`
+	Response *VerificationResponse `json:"response,omitempty"`
 }
 
 // OrchestrationRequest represents the main request to the orchestrator
 type OrchestrationRequest struct {
-	Topic            string `json:"topic"`
-	MinVerifiedStats int    `json:"min_verified_stats"` // Minimum verified statistics required
-	MaxCandidates    int    `json:"max_candidates"`     // Maximum candidates to research
-	ReputableOnly    bool   `json:"reputable_only"`
+	SchemaVersion Version `json:"schema_version"`
+
+	Topic            string   `json:"topic"`
+	MinVerifiedStats int      `json:"min_verified_stats"` // Minimum verified statistics required
+	MaxCandidates    int      `json:"max_candidates"`     // Maximum candidates to research
+	ReputableOnly    bool     `json:"reputable_only"`
+	Compare          []string `json:"compare,omitempty"`         // Entities or periods to compare, e.g. ["2010", "2020"] or ["US", "Germany"]
+	DryRun           bool     `json:"dry_run,omitempty"`         // Only search and select sources, returning the plan and its estimated cost
+	IncludeSummary   bool     `json:"include_summary,omitempty"` // Also write a cited summary paragraph of the verified statistics
+
+	// Only return statistics whose source declares a permissive license:
+	// CC0, CC BY, CC BY-SA, the Open Government Licence, or public domain
+	PermissiveLicenseOnly bool `json:"permissive_license_only,omitempty"`
+
+	// Sources to read instead of searching the web: every listed page and
+	// document is read, in passes of max_pages, and Topic only steers
+	// extraction
+	Sources []InputSource `json:"sources,omitempty"`
+
+	// Reproducible runs sample every LLM at temperature 0 with Seed, search
+	// without learned domain demotion, and store every intermediate artifact.
+	// Setting a seed implies reproducible; an unset seed is derived from the topic.
+	Reproducible bool  `json:"reproducible,omitempty"`
+	Seed         int32 `json:"seed,omitempty"`
+
+	// Per-stage limits: max_pages, candidates_per_page_cap, and
+	// verification_buffer_factor
+	StageLimits
+
+	// Kinds of statistic to return: statistic_types and exclude_projections
+	TypeFilter
+
+	// Per-run LLM overrides, validated against LLM_MODEL_ALLOWLIST. LLMProvider
+	// and LLMModel apply to every LLM stage; the stage fields take precedence.
+	LLMProvider       string         `json:"llm_provider,omitempty"`
+	LLMModel          string         `json:"llm_model,omitempty"`
+	SynthesisModel    *ModelOverride `json:"synthesis_model,omitempty"`    // Model for statistic extraction
+	VerificationModel *ModelOverride `json:"verification_model,omitempty"` // Model for LLM-assisted verification
+
+	// Generation settings for the synthesis and verification LLM calls of
+	// this run, over the stages' configured settings
+	Generation *Generation `json:"generation,omitempty"`
 }
 
 // OrchestrationResponse represents the final response
 type OrchestrationResponse struct {
-	Topic           string      `json:"topic"`
-	Statistics      []Statistic `json:"statistics"`
-	TotalCandidates int         `json:"total_candidates"`
-	VerifiedCount   int         `json:"verified_count"`
-	FailedCount     int         `json:"failed_count"`
-	Timestamp       time.Time   `json:"timestamp"`
-	Partial         bool        `json:"partial"`                   // True if target not met
-	TargetCount     int         `json:"target_count"`              // The minimum requested
-	ContinuationID  string      `json:"continuation_id,omitempty"` // ID for continuing the search
+	SchemaVersion Version `json:"schema_version"`
+
+	Topic            string         `json:"topic"`
+	Statistics       []Statistic    `json:"statistics"`
+	TotalCandidates  int            `json:"total_candidates"`
+	VerifiedCount    int            `json:"verified_count"`
+	FailedCount      int            `json:"failed_count"`
+	Timestamp        time.Time      `json:"timestamp"`
+	Status           string         `json:"status,omitempty"`            // Run outcome: complete, partial, or no_results
+	Partial          bool           `json:"partial"`                     // True if target not met
+	TargetCount      int            `json:"target_count"`                // The minimum requested
+	ContinuationID   string         `json:"continuation_id,omitempty"`   // ID for continuing the search
+	Comparison       *Comparison    `json:"comparison,omitempty"`        // Aligned per-entity results when Compare was requested
+	SessionID        string         `json:"session_id,omitempty"`        // Refinement session for follow-up constraints (POST /refine)
+	Constraints      []string       `json:"constraints,omitempty"`       // Refinement constraints applied to these results
+	CostSummary      *CostSummary   `json:"cost_summary,omitempty"`      // LLM token usage and estimated cost of this run
+	Timings          *Timings       `json:"timings,omitempty"`           // Time spent in each stage of this run
+	DuplicatesMerged int            `json:"duplicates_merged,omitempty"` // Near-duplicate statistics folded into corroborations
+	Conflicts        []Conflict     `json:"conflicts,omitempty"`         // Groups of statistics that contradict each other
+	Series           []Series       `json:"series,omitempty"`            // Metrics one source gives for several years, ready to chart
+	Summary          *Narrative     `json:"summary,omitempty"`           // Cited summary paragraph, when include_summary was requested; not itself verified
+	Honesty          *HonestyReport `json:"honesty,omitempty"`           // Source checks of unverified direct-search results
+	Page             *Page          `json:"page,omitempty"`              // The slice of statistics returned, when offset or limit was requested
+	Plan             *RunPlan       `json:"plan,omitempty"`              // What the run would do and cost, for a dry_run request
+
+	Reproducibility *Reproducibility `json:"reproducibility,omitempty"` // Sampling and artifacts of a reproducible run
+
+	Rejected []VerificationResult `json:"rejected,omitempty"`  // Candidates that failed verification, with reasons
+	Sources  []SourceFingerprint  `json:"sources,omitempty"`   // Fingerprints of the sources fetched during verification
+	ReportID string               `json:"report_id,omitempty"` // Saved verification report (GET /reports/{id}) when REPORT_DIR is set
+	Query    string               `json:"query,omitempty"`     // Relaxed search query used because the topic found nothing
+
+	SafetyFlags []string `json:"safety_flags,omitempty"` // Annotating safety categories the topic matches
+}
+
+// Outcomes reported in OrchestrationResponse.Status
+const (
+	StatusComplete  = "complete"   // The verified target was met
+	StatusPartial   = "partial"    // Sources were found but fewer statistics verified than the target
+	StatusNoResults = "no_results" // Search found no sources, even with a relaxed query
+	StatusDryRun    = "dry_run"    // Nothing was extracted or verified; see Plan
+)
+
+// RunStatus returns the outcome of a run that verified some of target
+// statistics. noSources reports that search returned nothing to read.
+func RunStatus(verified, target int, noSources bool) string {
+	switch {
+	case verified == 0 && noSources:
+		return StatusNoResults
+	case verified < target:
+		return StatusPartial
+	}
+	return StatusComplete
+}
+
+// Recount sets VerifiedCount, Status, and Partial from the statistics the
+// response returns, once merging duplicates or the postprocess hook has
+// changed them
+func (r *OrchestrationResponse) Recount() {
+	noSources := r.Status == StatusNoResults
+	r.VerifiedCount = len(r.Statistics)
+	r.Status = RunStatus(r.VerifiedCount, r.TargetCount, noSources)
+	r.Partial = r.VerifiedCount < r.TargetCount
+}
+
+// RefineRequest narrows the results of a previous orchestration with a
+// natural-language constraint, e.g. "only US data" or "exclude surveys"
+type RefineRequest struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	SessionID        string `json:"session_id"`
+	Constraint       string `json:"constraint"`
+	MinVerifiedStats int    `json:"min_verified_stats"` // Defaults to the original request's target
+}
+
+// SessionStatistics is one page of the statistics kept in a refinement
+// session (GET /sessions/{id}/statistics)
+type SessionStatistics struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	SessionID   string      `json:"session_id"`
+	Topic       string      `json:"topic"`
+	Constraints []string    `json:"constraints,omitempty"`
+	Statistics  []Statistic `json:"statistics"`
+	Page        *Page       `json:"page"`
+}
+
+// Comparison aligns the same statistic across entities or periods
+type Comparison struct {
+	Metric  string            `json:"metric"`         // Statistic being compared (the request topic)
+	Unit    string            `json:"unit,omitempty"` // Unit shared by the aligned statistics
+	Entries []ComparisonEntry `json:"entries"`        // One entry per requested entity, in request order
+}
+
+// ComparisonEntry is the aligned statistic for one entity or period
+type ComparisonEntry struct {
+	Entity    string     `json:"entity"`
+	Statistic *Statistic `json:"statistic,omitempty"` // Nil if no comparable statistic was verified
 }
 
 // SearchResult represents a source URL from research agent
 type SearchResult struct {
-	URL      string `json:"url"`
-	Title    string `json:"title"`
-	Snippet  string `json:"snippet"`
-	Domain   string `json:"domain"`
-	Position int    `json:"position,omitempty"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Snippet     string `json:"snippet"`
+	Domain      string `json:"domain"`
+	Position    int    `json:"position,omitempty"`
+	CrawledFrom string `json:"crawled_from,omitempty"` // Landing page this result was linked from
+	Internal    bool   `json:"internal,omitempty"`     // Found by an internal search backend, so fetched with the site credentials
+
+	Publisher   string    `json:"publisher,omitempty"`   // Publisher the page declares, when the research agent read it
+	PublishedAt time.Time `json:"published_at,omitzero"` // Publication date from the search provider or the page
 }
 
 // SynthesisRequest is the request to synthesis agent
 type SynthesisRequest struct {
+	SchemaVersion Version `json:"schema_version"`
+
 	Topic         string         `json:"topic"`
 	SearchResults []SearchResult `json:"search_results"`
 	MinStatistics int            `json:"min_statistics"`
 	MaxStatistics int            `json:"max_statistics"`
+	Model         *ModelOverride `json:"model,omitempty"`      // Per-request LLM override
+	Generation    *Generation    `json:"generation,omitempty"` // Per-request generation settings
+	Sampling      *Sampling      `json:"sampling,omitempty"`   // Pinned sampling of a reproducible run
+
+	// Page and per-page limits; VerificationBufferFactor sizes
+	// MaxStatistics when it is unset
+	StageLimits
 }
 
 // SynthesisResponse is the response from synthesis agent
 type SynthesisResponse struct {
+	SchemaVersion Version `json:"schema_version"`
+
 	Topic           string               `json:"topic"`
 	Candidates      []CandidateStatistic `json:"candidates"`
 	SourcesAnalyzed int                  `json:"sources_analyzed"`
 	Timestamp       time.Time            `json:"timestamp"`
+	Usage           *CostSummary         `json:"usage,omitempty"`   // LLM usage of this synthesis pass
+	Timings         *Timings             `json:"timings,omitempty"` // Fetch and extraction time of this pass
 }
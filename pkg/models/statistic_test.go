@@ -0,0 +1,120 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRunStatus(t *testing.T) {
+	tests := []struct {
+		verified, target int
+		noSources        bool
+		want             string
+	}{
+		{10, 10, false, StatusComplete},
+		{3, 10, false, StatusPartial},
+		{0, 10, false, StatusPartial},
+		{0, 10, true, StatusNoResults},
+	}
+	for _, tt := range tests {
+		if got := RunStatus(tt.verified, tt.target, tt.noSources); got != tt.want {
+			t.Errorf("RunStatus(%d, %d, %v) = %q, want %q", tt.verified, tt.target, tt.noSources, got, tt.want)
+		}
+	}
+}
+
+func TestCandidateStatisticKeepsFields(t *testing.T) {
+	cand := CandidateStatistic{
+		Name:       "Unemployment rate",
+		Value:      3.7,
+		Unit:       "%",
+		Source:     "Bureau of Labor Statistics",
+		SourceURL:  "https://www.bls.gov/data.csv",
+		Excerpt:    "unemployment rate was 3.7%",
+		Provenance: &Provenance{Format: "csv", Row: 4, Column: "rate"},
+	}
+	found := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	stat := cand.Statistic(true, found)
+	want := Statistic{
+		Name:       cand.Name,
+		Value:      cand.Value,
+		Unit:       cand.Unit,
+		Source:     cand.Source,
+		SourceURL:  cand.SourceURL,
+		Excerpt:    cand.Excerpt,
+		Verified:   true,
+		DateFound:  found,
+		Provenance: cand.Provenance,
+	}
+	if !reflect.DeepEqual(stat, want) {
+		t.Errorf("Statistic() = %+v, want %+v", stat, want)
+	}
+}
+
+func TestStatisticJSONRoundTrip(t *testing.T) {
+	in := Statistic{
+		Name:       "Median household income",
+		Value:      74580.5,
+		Unit:       "USD",
+		Source:     "Census Bureau",
+		SourceURL:  "https://www.census.gov/income",
+		Excerpt:    "median household income was $74,580.50",
+		Verified:   true,
+		DateFound:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Provenance: &Provenance{Format: "xlsx", Sheet: "Table A-1", Row: 12, Column: "Median"},
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Statistic
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+
+	var cand CandidateStatistic
+	if err := json.Unmarshal(data, &cand); err != nil {
+		t.Fatal(err)
+	}
+	if got := cand.Statistic(in.Verified, in.DateFound); !reflect.DeepEqual(got, in) {
+		t.Errorf("statistic decoded as candidate = %+v, want %+v", got, in)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value float32
+		unit  string
+		want  string
+	}{
+		{3.7, "%", "3.7%"},
+		{0.004, "", "0.004"},
+		{1.5, "million", "1.5 million"},
+		{74580.5, "USD", "74580.5 USD"},
+	}
+	for _, tt := range tests {
+		if got := FormatValue(tt.value, tt.unit); got != tt.want {
+			t.Errorf("FormatValue(%v, %q) = %q, want %q", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestRecount(t *testing.T) {
+	r := &OrchestrationResponse{Statistics: make([]Statistic, 2), VerifiedCount: 4, TargetCount: 3, Status: StatusComplete}
+	r.Recount()
+	if r.VerifiedCount != 2 || r.Status != StatusPartial || !r.Partial {
+		t.Errorf("Recount() = %d %s partial=%v; want 2 partial", r.VerifiedCount, r.Status, r.Partial)
+	}
+
+	r = &OrchestrationResponse{TargetCount: 3, Status: StatusNoResults}
+	r.Recount()
+	if r.Status != StatusNoResults {
+		t.Errorf("Recount() of a run without sources = %s", r.Status)
+	}
+}
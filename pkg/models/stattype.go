@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StatisticType is the kind of evidence a statistic is, as classified by the
+// extraction LLM. An empty type was not classified.
+type StatisticType string
+
+const (
+	TypeSurvey       StatisticType = "survey"        // Result of a survey or poll of a sample
+	TypeMeasured     StatisticType = "measured"      // Measured, counted, or recorded value (census, sensors, administrative data)
+	TypeProjection   StatisticType = "projection"    // Model projection or scenario ("could reach 2°C by 2100 under ...")
+	TypeForecast     StatisticType = "forecast"      // Prediction of a future value (market or economic forecast)
+	TypeSelfReported StatisticType = "self_reported" // Figure an organization reports about itself (users, revenue, emissions)
+)
+
+// StatisticTypes lists every type, in the order they are documented
+var StatisticTypes = []StatisticType{TypeSurvey, TypeMeasured, TypeProjection, TypeForecast, TypeSelfReported}
+
+// typeAliases are the other ways LLMs write each type
+var typeAliases = map[string]StatisticType{
+	"survey": TypeSurvey, "survey result": TypeSurvey, "poll": TypeSurvey,
+	"measured": TypeMeasured, "observed": TypeMeasured, "measured/observed": TypeMeasured, "measurement": TypeMeasured, "official": TypeMeasured,
+	"projection": TypeProjection, "model projection": TypeProjection, "projected": TypeProjection, "scenario": TypeProjection,
+	"forecast": TypeForecast, "prediction": TypeForecast, "predicted": TypeForecast,
+	"self_reported": TypeSelfReported, "self-reported": TypeSelfReported, "self reported": TypeSelfReported, "company-reported": TypeSelfReported,
+}
+
+// ParseStatisticType returns the type s names, accepting common variants
+// such as "self-reported" and "observed"; ok is false for anything else
+func ParseStatisticType(s string) (t StatisticType, ok bool) {
+	t, ok = typeAliases[strings.ToLower(strings.TrimSpace(s))]
+	return t, ok
+}
+
+// IsProjection reports whether the statistic is about the future rather than
+// the past: a projection or a forecast
+func (t StatisticType) IsProjection() bool {
+	return t == TypeProjection || t == TypeForecast
+}
+
+// TypeFilter selects the kinds of statistic a run returns. Candidates that do
+// not pass are dropped before verification.
+type TypeFilter struct {
+	// StatisticTypes keeps only statistics of these types, and unclassified
+	// ones, whose type extraction could not tell. Empty keeps every type.
+	StatisticTypes []StatisticType `json:"statistic_types,omitempty"`
+	// ExcludeProjections drops projections and forecasts
+	ExcludeProjections bool `json:"exclude_projections,omitempty"`
+}
+
+// Validate rejects unknown statistic types
+func (f TypeFilter) Validate() error {
+	for _, t := range f.StatisticTypes {
+		if !slices.Contains(StatisticTypes, t) {
+			return fmt.Errorf("unknown statistic type %q (want one of survey, measured, projection, forecast, self_reported)", t)
+		}
+	}
+	return nil
+}
+
+// Admits reports whether a statistic of type t passes the filter. An
+// unclassified statistic always does: extraction leaves the type empty when
+// it cannot tell, which says nothing about the kind of evidence.
+func (f TypeFilter) Admits(t StatisticType) bool {
+	if f.ExcludeProjections && t.IsProjection() {
+		return false
+	}
+	return t == "" || len(f.StatisticTypes) == 0 || slices.Contains(f.StatisticTypes, t)
+}
+
+// Filter returns the candidates that pass the filter and how many did not
+func (f TypeFilter) Filter(candidates []CandidateStatistic) ([]CandidateStatistic, int) {
+	if !f.ExcludeProjections && len(f.StatisticTypes) == 0 {
+		return candidates, 0
+	}
+	kept := make([]CandidateStatistic, 0, len(candidates))
+	for _, c := range candidates {
+		if f.Admits(c.Type) {
+			kept = append(kept, c)
+		}
+	}
+	return kept, len(candidates) - len(kept)
+}
@@ -0,0 +1,69 @@
+package models
+
+import "testing"
+
+func TestParseStatisticType(t *testing.T) {
+	for s, want := range map[string]StatisticType{
+		"survey":           TypeSurvey,
+		"Self-Reported":    TypeSelfReported,
+		" observed ":       TypeMeasured,
+		"model projection": TypeProjection,
+		"forecast":         TypeForecast,
+	} {
+		if got, ok := ParseStatisticType(s); !ok || got != want {
+			t.Errorf("ParseStatisticType(%q) = %q, %v; want %q", s, got, ok, want)
+		}
+	}
+	if got, ok := ParseStatisticType("anecdote"); ok {
+		t.Errorf("ParseStatisticType(anecdote) = %q", got)
+	}
+}
+
+func TestTypeFilter(t *testing.T) {
+	candidates := []CandidateStatistic{
+		{Name: "poll", Type: TypeSurvey},
+		{Name: "census", Type: TypeMeasured},
+		{Name: "2050 outlook", Type: TypeProjection},
+		{Name: "market size", Type: TypeForecast},
+		{Name: "unclassified"},
+	}
+	names := func(cs []CandidateStatistic) []string {
+		var out []string
+		for _, c := range cs {
+			out = append(out, c.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		filter  TypeFilter
+		want    []string
+		dropped int
+	}{
+		{"none", TypeFilter{}, []string{"poll", "census", "2050 outlook", "market size", "unclassified"}, 0},
+		{"exclude projections", TypeFilter{ExcludeProjections: true}, []string{"poll", "census", "unclassified"}, 2},
+		{"types", TypeFilter{StatisticTypes: []StatisticType{TypeMeasured, TypeForecast}}, []string{"census", "market size", "unclassified"}, 2},
+		{"both", TypeFilter{StatisticTypes: []StatisticType{TypeMeasured, TypeForecast}, ExcludeProjections: true}, []string{"census", "unclassified"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, dropped := tt.filter.Filter(candidates)
+			if got := names(kept); dropped != tt.dropped || len(got) != len(tt.want) {
+				t.Fatalf("Filter = %v, %d dropped; want %v, %d", got, dropped, tt.want, tt.dropped)
+			}
+			for i, name := range names(kept) {
+				if name != tt.want[i] {
+					t.Errorf("Filter = %v; want %v", names(kept), tt.want)
+				}
+			}
+		})
+	}
+
+	if err := (TypeFilter{StatisticTypes: []StatisticType{"survey", "self_reported"}}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (TypeFilter{StatisticTypes: []StatisticType{"self-reported"}}).Validate(); err == nil {
+		t.Error("Validate accepted an alias in a request")
+	}
+}
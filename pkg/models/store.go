@@ -0,0 +1,114 @@
+package models
+
+import "time"
+
+// StoredStatistic is a statistic verified by a past run, as kept by the
+// statistics store
+type StoredStatistic struct {
+	Statistic
+
+	ID         string    `json:"id"`          // Stable ID derived from the source URL, name, and value
+	Topics     []string  `json:"topics"`      // Topics of the runs that verified it
+	FirstSeen  time.Time `json:"first_seen"`  // When a run first verified it
+	LastSeen   time.Time `json:"last_seen"`   // When a run last verified it
+	Domain     string    `json:"domain"`      // Host of the source, without "www."
+	DomainTier string    `json:"domain_tier"` // "authoritative" (government, academic, intergovernmental), "research", or "other"
+	Confidence float64   `json:"confidence"`  // 0 to 1, from the domain tier and the number of corroborating sources
+
+	Stale        *Staleness `json:"stale,omitempty"`         // Set once the source's text around the statistic changed after it was verified
+	Supersedes   *Revision  `json:"supersedes,omitempty"`    // Earlier value of this statistic from the same source, which it revised
+	SupersededBy string     `json:"superseded_by,omitempty"` // ID of the statistic holding the source's revised value
+}
+
+// Revision is the earlier value of a revised statistic
+type Revision struct {
+	ID        string    `json:"id"`
+	Value     float32   `json:"value"`
+	Unit      string    `json:"unit"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"` // Last time a run verified the earlier value
+}
+
+// RevisedStatistic is a stored statistic whose source revised its value, as
+// listed by the changes report (GET /statistics/changes)
+type RevisedStatistic struct {
+	ID         string    `json:"id"`          // ID of the statistic with the new value
+	PreviousID string    `json:"previous_id"` // ID of the statistic with the old value
+	Name       string    `json:"name"`
+	Source     string    `json:"source"`
+	SourceURL  string    `json:"source_url"`
+	Topics     []string  `json:"topics"`
+	OldValue   float32   `json:"old_value"`
+	OldUnit    string    `json:"old_unit"`
+	NewValue   float32   `json:"new_value"`
+	NewUnit    string    `json:"new_unit"`
+	OldSince   time.Time `json:"old_since"`          // When the old value was first verified
+	OldUntil   time.Time `json:"old_until"`          // When the old value was last verified
+	ChangedAt  time.Time `json:"changed_at"`         // When the new value was first verified
+	Excerpt    string    `json:"excerpt"`            // The source's passage with the new value
+	DiffURL    string    `json:"diff_url,omitempty"` // Source link that scrolls to the changed section, where browsers support text fragments
+}
+
+// StatisticChangesResponse is one page of the changes report, most recent
+// change first
+type StatisticChangesResponse struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	Topic   string             `json:"topic,omitempty"` // Topic the changes were restricted to
+	Changes []RevisedStatistic `json:"changes"`
+	Page    *Page              `json:"page"`
+}
+
+// Staleness records that a stored statistic's source changed around its
+// excerpt. A run that verifies the statistic again clears it.
+type Staleness struct {
+	DetectedAt  time.Time `json:"detected_at"` // When a fetch of the source first showed the change
+	Fingerprint string    `json:"fingerprint"` // Hash of the source's changed text
+	DiffURL     string    `json:"diff_url"`    // Source link that scrolls to the changed section, where browsers support text fragments
+}
+
+// StatisticMatch is a stored statistic matching a search
+type StatisticMatch struct {
+	StoredStatistic
+
+	Score float64 `json:"score"` // Relevance to the query, from 0 to 1
+}
+
+// StatisticSearchResponse is one page of the stored statistics matching a
+// search (GET /statistics/search), most relevant first
+type StatisticSearchResponse struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	Query   string           `json:"query"`
+	Topic   string           `json:"topic,omitempty"` // Topic the results were restricted to
+	Mode    string           `json:"mode"`            // How results were ranked: "keyword" or "hybrid" (keyword and embedding similarity)
+	Results []StatisticMatch `json:"results"`
+	Page    *Page            `json:"page"`
+}
+
+// CandidateImportRequest is a list of candidate statistics sourced outside
+// the pipeline, such as a spreadsheet of the figures in published content,
+// to verify and add to the statistics store (POST /candidates/import)
+type CandidateImportRequest struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	Topic      string               `json:"topic,omitempty"` // Topic the verified statistics are stored under (default "import")
+	Candidates []CandidateStatistic `json:"candidates"`
+	Model      *ModelOverride       `json:"model,omitempty"` // Per-request LLM override
+}
+
+// CandidateImportResponse is the outcome of an import: the candidates that
+// verified, as stored, and those that did not, with reasons
+type CandidateImportResponse struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	Topic         string               `json:"topic"`
+	Statistics    []StoredStatistic    `json:"statistics"` // Verified candidates, with their store IDs
+	Rejected      []VerificationResult `json:"rejected"`   // Candidates that failed verification, with reasons
+	VerifiedCount int                  `json:"verified_count"`
+	FailedCount   int                  `json:"failed_count"`
+	NewCount      int                  `json:"new_count"` // Verified candidates the store did not have yet
+	Stored        bool                 `json:"stored"`    // False when no statistics store is configured
+	Usage         *CostSummary         `json:"usage,omitempty"`
+	Timestamp     time.Time            `json:"timestamp"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// SubscriptionRequest creates a topic monitoring subscription
+type SubscriptionRequest struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	Topic            string `json:"topic"`
+	Cadence          string `json:"cadence"`            // "hourly", "daily", "weekly", or a Go duration such as "6h"
+	WebhookURL       string `json:"webhook_url"`        // Receives a POSTed ChangeNotification
+	Email            string `json:"email"`              // Receives a plain-text summary (requires SMTP settings)
+	MinVerifiedStats int    `json:"min_verified_stats"` // Statistics gathered per run
+	ReputableOnly    bool   `json:"reputable_only"`
+}
+
+// Subscription is a topic that is periodically re-searched
+type Subscription struct {
+	ID               string    `json:"id"`
+	Topic            string    `json:"topic"`
+	Cadence          string    `json:"cadence"`
+	Interval         Duration  `json:"interval"`
+	WebhookURL       string    `json:"webhook_url,omitempty"`
+	Email            string    `json:"email,omitempty"`
+	MinVerifiedStats int       `json:"min_verified_stats"`
+	ReputableOnly    bool      `json:"reputable_only"`
+	CreatedAt        time.Time `json:"created_at"`
+	LastRunAt        time.Time `json:"last_run_at,omitzero"`
+	NextRunAt        time.Time `json:"next_run_at"`
+	LastError        string    `json:"last_error,omitempty"`
+}
+
+// StatisticChange is a statistic whose value changed since the previous run
+type StatisticChange struct {
+	Previous Statistic `json:"previous"`
+	Current  Statistic `json:"current"`
+}
+
+// ChangeNotification reports new or changed statistics for a subscription
+type ChangeNotification struct {
+	SchemaVersion Version `json:"schema_version"`
+
+	SubscriptionID string            `json:"subscription_id"`
+	Topic          string            `json:"topic"`
+	New            []Statistic       `json:"new"`
+	Changed        []StatisticChange `json:"changed"`
+	Timestamp      time.Time         `json:"timestamp"`
+}
+
+// Duration is a time.Duration that encodes as a string such as "24h0m0s"
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
@@ -0,0 +1,39 @@
+package models
+
+// CostSummary aggregates LLM token usage and estimated cost for a run
+type CostSummary struct {
+	Calls            int          `json:"calls"`
+	PromptTokens     int          `json:"prompt_tokens"`
+	CompletionTokens int          `json:"completion_tokens"`
+	TotalTokens      int          `json:"total_tokens"`
+	EstimatedCostUSD float64      `json:"estimated_cost_usd"`
+	Unpriced         bool         `json:"unpriced,omitempty"`     // True if some calls used a model without known pricing
+	SearchCalls      int          `json:"search_calls,omitempty"` // Web searches run by the research agent
+	ByModel          []ModelUsage `json:"by_model,omitempty"`
+}
+
+// ModelUsage is the usage of one model within a run
+type ModelUsage struct {
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	Stage            string  `json:"stage,omitempty"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+	Unpriced         bool    `json:"unpriced,omitempty"`
+
+	Versions []string `json:"versions,omitempty"` // Exact model versions the provider reported answering
+}
+
+// Timings breaks down where a run's time went, in milliseconds. Fetch time
+// is page downloads by the synthesis and verification agents; extraction
+// and verification time exclude it.
+type Timings struct {
+	TotalMS        int64 `json:"total_ms"`
+	SearchMS       int64 `json:"search_ms"`
+	FetchMS        int64 `json:"fetch_ms"`
+	ExtractionMS   int64 `json:"extraction_ms"`
+	VerificationMS int64 `json:"verification_ms"`
+	Retries        int   `json:"retries"` // Research, synthesis, and verification passes after the first
+}
@@ -0,0 +1,23 @@
+package models
+
+import "strconv"
+
+// SchemaVersion is the version of the request and response schema written
+// by this build. Payloads without a schema_version predate versioning and
+// are version 1; pkg/models/migrate upgrades them.
+//
+// Version history:
+//   - 1: unversioned payloads
+//   - 2: schema_version added; orchestration responses always carry a
+//     status, and failed verification results a category
+const SchemaVersion = 2
+
+// Version is the schema version of a payload. It always encodes as the
+// current SchemaVersion, so every payload an agent writes declares the
+// schema it was written with; decoding keeps the version that was sent.
+type Version int
+
+// MarshalJSON implements json.Marshaler
+func (Version) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, SchemaVersion, 10), nil
+}
@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+)
+
+// HandleSubscriptions serves /subscriptions (POST to create, GET to list) and
+// /subscriptions/{id} (GET to fetch, DELETE to cancel)
+func (m *Monitor) HandleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/subscriptions"), "/")
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		var req models.SubscriptionRequest
+		if err := migrate.Decode(r.Body, &req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		sub, err := m.Subscribe(&req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m.writeJSON(w, http.StatusCreated, sub)
+
+	case id == "" && r.Method == http.MethodGet:
+		m.writeJSON(w, http.StatusOK, m.List())
+
+	case id != "" && r.Method == http.MethodGet:
+		sub, err := m.Get(id)
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		m.writeJSON(w, http.StatusOK, sub)
+
+	case id != "" && r.Method == http.MethodDelete:
+		if err := m.Delete(id); errors.Is(err, ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeJSON encodes a JSON response with the given status
+func (m *Monitor) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		m.logger.Error("failed to encode response", "error", err)
+	}
+}
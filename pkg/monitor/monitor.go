@@ -0,0 +1,399 @@
+// Package monitor turns one-shot searches into a monitoring service: topics
+// are re-searched on a cadence and only new or changed statistics are sent to
+// the subscriber's webhook or email address.
+package monitor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+const (
+	// MinInterval is the shortest allowed cadence
+	MinInterval = 15 * time.Minute
+	// tickInterval is how often due subscriptions are checked
+	tickInterval = time.Minute
+)
+
+// ErrNotFound is returned for unknown subscription IDs
+var ErrNotFound = errors.New("subscription not found")
+
+// OrchestrateFunc runs a search for a topic
+type OrchestrateFunc func(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error)
+
+// state is the persisted monitor state
+type state struct {
+	Subscriptions map[string]*models.Subscription `json:"subscriptions"`
+	Baselines     map[string][]models.Statistic   `json:"baselines"` // Statistics seen by the last run, by subscription ID
+}
+
+// Monitor schedules subscriptions and notifies subscribers of changes
+type Monitor struct {
+	orchestrate OrchestrateFunc
+	notifier    *Notifier
+	stateFile   string
+	defaults    config.RequestDefaults
+	logger      *slog.Logger
+
+	mu      sync.Mutex
+	state   state
+	running map[string]bool
+}
+
+// New creates a monitor, loading persisted subscriptions from
+// cfg.MonitorStateFile when set
+func New(cfg *config.Config, orchestrate OrchestrateFunc, logger *slog.Logger) (*Monitor, error) {
+	m := &Monitor{
+		orchestrate: orchestrate,
+		notifier:    NewNotifier(cfg),
+		stateFile:   cfg.MonitorStateFile,
+		defaults:    cfg.Defaults,
+		logger:      logger,
+		state: state{
+			Subscriptions: make(map[string]*models.Subscription),
+			Baselines:     make(map[string][]models.Statistic),
+		},
+		running: make(map[string]bool),
+	}
+
+	if m.stateFile != "" {
+		data, err := os.ReadFile(m.stateFile)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read monitor state: %w", err)
+		default:
+			if err := json.Unmarshal(data, &m.state); err != nil {
+				return nil, fmt.Errorf("failed to parse monitor state: %w", err)
+			}
+			if m.state.Subscriptions == nil {
+				m.state.Subscriptions = make(map[string]*models.Subscription)
+			}
+			if m.state.Baselines == nil {
+				m.state.Baselines = make(map[string][]models.Statistic)
+			}
+			logger.Info("loaded subscriptions", "count", len(m.state.Subscriptions))
+		}
+	}
+
+	return m, nil
+}
+
+// Start checks for due subscriptions every minute until ctx is cancelled
+func (m *Monitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		m.runDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Subscribe validates and registers a subscription. The first run happens on
+// the next scheduler tick and reports every statistic found as new.
+func (m *Monitor) Subscribe(req *models.SubscriptionRequest) (*models.Subscription, error) {
+	if strings.TrimSpace(req.Topic) == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+	if strings.ContainsAny(req.Topic, "\r\n") {
+		return nil, fmt.Errorf("topic must be a single line")
+	}
+	interval, err := ParseCadence(req.Cadence)
+	if err != nil {
+		return nil, err
+	}
+	if req.WebhookURL == "" && req.Email == "" {
+		return nil, fmt.Errorf("webhook_url or email is required")
+	}
+	if req.WebhookURL != "" {
+		if u, err := url.Parse(req.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook_url: %q", req.WebhookURL)
+		}
+	}
+	email := req.Email
+	if email != "" {
+		if !m.notifier.EmailEnabled() {
+			return nil, fmt.Errorf("email notifications require SMTP_HOST and SMTP_FROM to be configured")
+		}
+		addr, err := mail.ParseAddress(email)
+		if err != nil || strings.ContainsAny(email, "\r\n") {
+			return nil, fmt.Errorf("invalid email: %q", email)
+		}
+		email = addr.Address
+	}
+
+	minStats := req.MinVerifiedStats
+	if minStats <= 0 {
+		minStats = m.defaults.MinVerifiedStats
+	}
+
+	now := time.Now()
+	sub := &models.Subscription{
+		ID:               newID(),
+		Topic:            req.Topic,
+		Cadence:          req.Cadence,
+		Interval:         models.Duration(interval),
+		WebhookURL:       req.WebhookURL,
+		Email:            email,
+		MinVerifiedStats: minStats,
+		ReputableOnly:    req.ReputableOnly,
+		CreatedAt:        now,
+		NextRunAt:        now,
+	}
+
+	m.mu.Lock()
+	m.state.Subscriptions[sub.ID] = sub
+	m.saveLocked()
+	copied := *sub
+	m.mu.Unlock()
+
+	m.logger.Info("subscription created", "id", sub.ID, "topic", sub.Topic, "interval", interval)
+	return &copied, nil
+}
+
+// List returns all subscriptions ordered by creation time
+func (m *Monitor) List() []models.Subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	subs := make([]models.Subscription, 0, len(m.state.Subscriptions))
+	for _, sub := range m.state.Subscriptions {
+		subs = append(subs, *sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs
+}
+
+// Get returns a subscription by ID
+func (m *Monitor) Get(id string) (*models.Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub, ok := m.state.Subscriptions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+// Delete removes a subscription and its baseline
+func (m *Monitor) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.state.Subscriptions[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.state.Subscriptions, id)
+	delete(m.state.Baselines, id)
+	m.saveLocked()
+	return nil
+}
+
+// runDue starts a run for every subscription whose next run time has passed
+func (m *Monitor) runDue(ctx context.Context) {
+	now := time.Now()
+
+	m.mu.Lock()
+	var due []models.Subscription
+	for id, sub := range m.state.Subscriptions {
+		if !m.running[id] && !now.Before(sub.NextRunAt) {
+			m.running[id] = true
+			due = append(due, *sub)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, sub := range due {
+		go m.run(ctx, sub)
+	}
+}
+
+// run searches a subscription's topic, diffs against the previous run, and
+// notifies the subscriber if anything is new or changed
+func (m *Monitor) run(ctx context.Context, sub models.Subscription) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.running, sub.ID)
+		m.mu.Unlock()
+	}()
+
+	m.logger.Info("running subscription", "id", sub.ID, "topic", sub.Topic)
+
+	resp, runErr := m.orchestrate(ctx, &models.OrchestrationRequest{
+		Topic:            sub.Topic,
+		MinVerifiedStats: sub.MinVerifiedStats,
+		MaxCandidates:    sub.MinVerifiedStats * 3,
+		ReputableOnly:    sub.ReputableOnly,
+	})
+
+	m.mu.Lock()
+	current, ok := m.state.Subscriptions[sub.ID]
+	if !ok {
+		m.mu.Unlock()
+		return // Deleted while running
+	}
+	current.LastRunAt = time.Now()
+	current.NextRunAt = current.LastRunAt.Add(time.Duration(current.Interval))
+	current.LastError = ""
+
+	var notification *models.ChangeNotification
+	if runErr != nil {
+		current.LastError = runErr.Error()
+	} else {
+		added, changed := Diff(m.state.Baselines[sub.ID], resp.Statistics)
+		m.state.Baselines[sub.ID] = mergeBaseline(m.state.Baselines[sub.ID], resp.Statistics)
+		if len(added) > 0 || len(changed) > 0 {
+			notification = &models.ChangeNotification{
+				SubscriptionID: sub.ID,
+				Topic:          sub.Topic,
+				New:            added,
+				Changed:        changed,
+				Timestamp:      time.Now(),
+			}
+		}
+	}
+	m.saveLocked()
+	m.mu.Unlock()
+
+	if runErr != nil {
+		m.logger.Warn("subscription run failed", "id", sub.ID, "error", runErr)
+		return
+	}
+	if notification == nil {
+		m.logger.Info("no new or changed statistics", "id", sub.ID)
+		return
+	}
+
+	m.logger.Info("notifying subscriber",
+		"id", sub.ID,
+		"new", len(notification.New),
+		"changed", len(notification.Changed))
+	if err := m.notifier.Notify(ctx, &sub, notification); err != nil {
+		m.logger.Warn("failed to notify subscriber", "id", sub.ID, "error", err)
+	}
+}
+
+// Diff compares a run's statistics with the previous baseline. Statistics are
+// matched by source URL and name; a match with a different value or unit is
+// reported as changed.
+func Diff(previous, current []models.Statistic) (added []models.Statistic, changed []models.StatisticChange) {
+	known := make(map[string]models.Statistic, len(previous))
+	for _, stat := range previous {
+		known[statKey(stat)] = stat
+	}
+
+	seen := make(map[string]bool)
+	for _, stat := range current {
+		key := statKey(stat)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		prev, ok := known[key]
+		switch {
+		case !ok:
+			added = append(added, stat)
+		case prev.Value != stat.Value || prev.Unit != stat.Unit:
+			changed = append(changed, models.StatisticChange{Previous: prev, Current: stat})
+		}
+	}
+	return added, changed
+}
+
+// mergeBaseline updates the baseline with the latest statistics. Statistics
+// not found in this run are kept, so a source dropping out of search results
+// and coming back is not reported as new.
+func mergeBaseline(previous, current []models.Statistic) []models.Statistic {
+	index := make(map[string]int, len(previous))
+	merged := append([]models.Statistic(nil), previous...)
+	for i, stat := range merged {
+		index[statKey(stat)] = i
+	}
+	for _, stat := range current {
+		if i, ok := index[statKey(stat)]; ok {
+			merged[i] = stat
+			continue
+		}
+		index[statKey(stat)] = len(merged)
+		merged = append(merged, stat)
+	}
+	return merged
+}
+
+// statKey identifies a statistic across runs
+func statKey(stat models.Statistic) string {
+	return stat.SourceURL + "|" + strings.ToLower(strings.Join(strings.Fields(stat.Name), " "))
+}
+
+// ParseCadence converts "hourly", "daily", "weekly", or a Go duration into an interval
+func ParseCadence(cadence string) (time.Duration, error) {
+	var interval time.Duration
+	switch strings.ToLower(strings.TrimSpace(cadence)) {
+	case "hourly":
+		interval = time.Hour
+	case "", "daily":
+		interval = 24 * time.Hour
+	case "weekly":
+		interval = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(cadence)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cadence %q: use hourly, daily, weekly, or a duration such as 6h", cadence)
+		}
+		interval = d
+	}
+	if interval < MinInterval {
+		return 0, fmt.Errorf("cadence %s is shorter than the minimum of %s", interval, MinInterval)
+	}
+	return interval, nil
+}
+
+// saveLocked persists state to the state file, if configured. The caller must hold m.mu.
+func (m *Monitor) saveLocked() {
+	if m.stateFile == "" {
+		return
+	}
+	data, err := json.MarshalIndent(&m.state, "", "  ")
+	if err != nil {
+		m.logger.Error("failed to marshal monitor state", "error", err)
+		return
+	}
+	tmp := m.stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		m.logger.Error("failed to write monitor state", "error", err)
+		return
+	}
+	if err := os.Rename(tmp, m.stateFile); err != nil {
+		m.logger.Error("failed to replace monitor state", "error", err)
+	}
+}
+
+// newID returns a random subscription ID
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "sub_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,139 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestDiff(t *testing.T) {
+	previous := []models.Statistic{
+		{Name: "EV sales share", Value: 14, Unit: "%", SourceURL: "https://iea.org/ev"},
+		{Name: "Charging points", Value: 2.7, Unit: "million", SourceURL: "https://iea.org/ev"},
+	}
+	current := []models.Statistic{
+		{Name: "EV  sales share", Value: 18, Unit: "%", SourceURL: "https://iea.org/ev"},
+		{Name: "Charging points", Value: 2.7, Unit: "million", SourceURL: "https://iea.org/ev"},
+		{Name: "Battery prices", Value: 139, Unit: "$/kWh", SourceURL: "https://bnef.com/batteries"},
+	}
+
+	added, changed := Diff(previous, current)
+	if len(added) != 1 || added[0].Name != "Battery prices" {
+		t.Errorf("Diff() added = %+v", added)
+	}
+	if len(changed) != 1 || changed[0].Previous.Value != 14 || changed[0].Current.Value != 18 {
+		t.Errorf("Diff() changed = %+v", changed)
+	}
+
+	baseline := mergeBaseline(previous, current[2:])
+	if len(baseline) != 3 {
+		t.Errorf("mergeBaseline() kept %d statistics, want 3", len(baseline))
+	}
+}
+
+func TestParseCadence(t *testing.T) {
+	if d, err := ParseCadence("daily"); err != nil || d != 24*time.Hour {
+		t.Errorf("ParseCadence(daily) = %v, %v", d, err)
+	}
+	if d, err := ParseCadence("6h"); err != nil || d != 6*time.Hour {
+		t.Errorf("ParseCadence(6h) = %v, %v", d, err)
+	}
+	if _, err := ParseCadence("1m"); err == nil {
+		t.Error("ParseCadence(1m) should reject cadences below the minimum")
+	}
+}
+
+func TestRunNotifiesOnlyChanges(t *testing.T) {
+	notifications := make(chan models.ChangeNotification, 2)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n models.ChangeNotification
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &n); err != nil {
+			t.Errorf("invalid webhook payload: %v", err)
+		}
+		notifications <- n
+	}))
+	defer webhook.Close()
+
+	stats := []models.Statistic{{Name: "Unemployment rate", Value: 3.9, Unit: "%", SourceURL: "https://bls.gov/cps"}}
+	orchestrate := func(context.Context, *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+		return &models.OrchestrationResponse{Statistics: stats}, nil
+	}
+
+	m, err := New(&config.Config{}, orchestrate, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sub, err := m.Subscribe(&models.SubscriptionRequest{Topic: "unemployment", Cadence: "daily", WebhookURL: webhook.URL})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	ctx := context.Background()
+	m.run(ctx, *sub)
+	if n := <-notifications; len(n.New) != 1 {
+		t.Errorf("first run notification = %+v, want 1 new statistic", n)
+	}
+
+	m.run(ctx, *sub) // Unchanged: no notification
+
+	stats = []models.Statistic{{Name: "Unemployment rate", Value: 4.1, Unit: "%", SourceURL: "https://bls.gov/cps"}}
+	m.run(ctx, *sub)
+	if n := <-notifications; len(n.New) != 0 || len(n.Changed) != 1 {
+		t.Errorf("third run notification = %+v, want 1 changed statistic", n)
+	}
+
+	if got, _ := m.Get(sub.ID); !got.NextRunAt.After(got.LastRunAt) {
+		t.Errorf("NextRunAt %v not after LastRunAt %v", got.NextRunAt, got.LastRunAt)
+	}
+}
+
+func TestSubscribeRejectsHeaderInjection(t *testing.T) {
+	cfg := &config.Config{SMTPHost: "smtp.example.com", SMTPPort: 587, SMTPFrom: "alerts@example.com"}
+	m, err := New(cfg, nil, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, req := range []models.SubscriptionRequest{
+		{Topic: "jobs\r\nBcc: victim@example.com", Cadence: "daily", Email: "me@example.com"},
+		{Topic: "jobs", Cadence: "daily", Email: "me@example.com\r\nBcc: victim@example.com"},
+		{Topic: "jobs", Cadence: "daily", Email: "not an address"},
+	} {
+		if _, err := m.Subscribe(&req); err == nil {
+			t.Errorf("Subscribe(%q, %q) succeeded; want an error", req.Topic, req.Email)
+		}
+	}
+
+	sub, err := m.Subscribe(&models.SubscriptionRequest{Topic: "jobs", Cadence: "daily", Email: "Me <me@example.com>"})
+	if err != nil || sub.Email != "me@example.com" {
+		t.Errorf("Subscribe() = %+v, %v; want the bare address", sub, err)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	from := &mail.Address{Name: "Stats", Address: "alerts@example.com"}
+	to := &mail.Address{Address: "me@example.com"}
+	msg := string(emailMessage(from, to, &models.ChangeNotification{Topic: "jobs\r\nBcc: victim@example.com"}))
+
+	header, _, _ := strings.Cut(msg, "\r\n\r\n")
+	lines := strings.Split(header, "\r\n")
+	if len(lines) != 4 {
+		t.Fatalf("header = %q; want From, To, Subject, and Content-Type", header)
+	}
+	if lines[0] != `From: "Stats" <alerts@example.com>` || lines[1] != "To: <me@example.com>" {
+		t.Errorf("addresses = %q, %q", lines[0], lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "Subject: =?utf-8?q?") {
+		t.Errorf("subject = %q; want it encoded", lines[2])
+	}
+}
@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Notifier delivers change notifications by webhook and email
+type Notifier struct {
+	client   *http.Client
+	smtpAddr string
+	smtpAuth smtp.Auth
+	from     string
+}
+
+// NewNotifier creates a notifier. Email is available only when SMTP_HOST and
+// SMTP_FROM are configured.
+func NewNotifier(cfg *config.Config) *Notifier {
+	n := &Notifier{
+		client: &http.Client{Timeout: 30 * time.Second},
+		from:   cfg.SMTPFrom,
+	}
+	if cfg.SMTPHost != "" {
+		n.smtpAddr = net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort))
+		if cfg.SMTPUsername != "" {
+			n.smtpAuth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+		}
+	}
+	return n
+}
+
+// EmailEnabled reports whether email notifications can be sent
+func (n *Notifier) EmailEnabled() bool {
+	return n.smtpAddr != "" && n.from != ""
+}
+
+// Notify sends the notification to every destination on the subscription
+func (n *Notifier) Notify(ctx context.Context, sub *models.Subscription, notification *models.ChangeNotification) error {
+	var errs []error
+	if sub.WebhookURL != "" {
+		if err := n.postWebhook(ctx, sub.WebhookURL, notification); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if sub.Email != "" {
+		if err := n.sendEmail(sub.Email, notification); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// postWebhook POSTs the notification as JSON, accepting any 2xx response
+func (n *Notifier) postWebhook(ctx context.Context, webhookURL string, notification *models.ChangeNotification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req) //nolint:gosec // G704: webhook URL is validated at subscription time
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// sendEmail sends a plain-text summary of the notification
+func (n *Notifier) sendEmail(to string, notification *models.ChangeNotification) error {
+	if !n.EmailEnabled() {
+		return fmt.Errorf("SMTP is not configured")
+	}
+	from, err := mail.ParseAddress(n.from)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid email: %w", err)
+	}
+	msg := emailMessage(from, rcpt, notification)
+	return smtp.SendMail(n.smtpAddr, n.smtpAuth, from.Address, []string{rcpt.Address}, msg)
+}
+
+// emailMessage builds the message for a notification. The addresses are
+// formatted by net/mail and the subject, which holds the topic, is encoded
+// as an RFC 2047 word when it needs to be, so neither can end a header.
+func emailMessage(from, to *mail.Address, notification *models.ChangeNotification) []byte {
+	subject := fmt.Sprintf("%d new, %d changed statistics: %s",
+		len(notification.New), len(notification.Changed), notification.Topic)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", from)
+	fmt.Fprintf(&body, "To: %s\r\n", to)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	body.WriteString(FormatText(notification))
+	return []byte(body.String())
+}
+
+// FormatText renders a notification as a plain-text summary
+func FormatText(notification *models.ChangeNotification) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Topic: %s\n\n", notification.Topic)
+
+	if len(notification.New) > 0 {
+		b.WriteString("New statistics:\n")
+		for _, stat := range notification.New {
+			fmt.Fprintf(&b, "- %s: %v %s (%s)\n  %s\n", stat.Name, stat.Value, stat.Unit, stat.Source, stat.SourceURL)
+		}
+		b.WriteString("\n")
+	}
+	if len(notification.Changed) > 0 {
+		b.WriteString("Changed statistics:\n")
+		for _, change := range notification.Changed {
+			fmt.Fprintf(&b, "- %s: %v %s -> %v %s (%s)\n  %s\n",
+				change.Current.Name,
+				change.Previous.Value, change.Previous.Unit,
+				change.Current.Value, change.Current.Unit,
+				change.Current.Source, change.Current.SourceURL)
+		}
+	}
+	return b.String()
+}
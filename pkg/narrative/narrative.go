@@ -0,0 +1,147 @@
+// Package narrative writes the optional summary of a response: a short
+// paragraph of prose weaving the verified statistics together, for users who
+// want copy-ready text. Each figure in the paragraph carries a bracketed
+// marker such as "[2]" naming the statistic it came from. The paragraph is
+// the LLM's own writing and is kept apart from the verified statistics;
+// markers that do not name one of them are removed, and so are sentences
+// that do not state the value of every statistic they cite.
+package narrative
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+// maxStatistics bounds the statistics offered to the LLM, keeping the prompt
+// small for large responses. Statistics come best-sourced first.
+const maxStatistics = 30
+
+// markerPattern matches a citation marker, "[2]" or "[1, 3]", with the space
+// before it
+var markerPattern = regexp.MustCompile(`\s*\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// GenerateFunc sends a single-turn prompt to an LLM and returns its text
+type GenerateFunc func(ctx context.Context, prompt string) (string, error)
+
+// Write asks the LLM for a summary paragraph of stats on topic and maps its
+// markers to the statistics they cite. It fails when there is nothing to
+// summarize or the paragraph cites none of the statistics.
+func Write(ctx context.Context, set *prompts.Set, generate GenerateFunc, topic string, stats []models.Statistic) (*models.Narrative, error) {
+	if len(stats) == 0 {
+		return nil, errors.New("no statistics to summarize")
+	}
+	stats = stats[:min(len(stats), maxStatistics)]
+
+	prompt, err := set.Render(prompts.SummaryNarrative, prompts.NarrativeData{Topic: topic, Statistics: stats})
+	if err != nil {
+		return nil, err
+	}
+	text, err := generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	n := Cite(text, stats)
+	if len(n.Citations) == 0 {
+		return nil, errors.New("summary cites none of the statistics")
+	}
+	return n, nil
+}
+
+// Cite maps the markers in text to stats, where marker n is stats[n-1].
+// Markers naming no statistic are dropped from the text, and the remaining
+// ones are normalized to "[1, 3]". A sentence citing a statistic whose value
+// it does not state, such as "grew 45% [3]" for a 35% statistic, is dropped
+// too, since the LLM misquoted or invented the figure.
+func Cite(text string, stats []models.Statistic) *models.Narrative {
+	text = markerPattern.ReplaceAllStringFunc(strings.TrimSpace(text), func(m string) string {
+		kept := markers(m, len(stats))
+		if len(kept) == 0 {
+			return ""
+		}
+		return " [" + strings.Join(kept, ", ") + "]"
+	})
+
+	var sentences []string
+	var cited []int
+	dropped := 0
+	for _, sentence := range splitSentences(text) {
+		var numbers []int
+		for _, m := range markerPattern.FindAllString(sentence, -1) {
+			for _, s := range markers(m, len(stats)) {
+				n, _ := strconv.Atoi(s)
+				numbers = append(numbers, n)
+			}
+		}
+		if slices.ContainsFunc(numbers, func(n int) bool { return !extract.ValueAppears(sentence, float64(stats[n-1].Value)) }) {
+			dropped++
+			continue
+		}
+		sentences = append(sentences, sentence)
+		for _, n := range numbers {
+			if !slices.Contains(cited, n) {
+				cited = append(cited, n)
+			}
+		}
+	}
+	slices.Sort(cited)
+
+	n := &models.Narrative{Text: strings.Join(sentences, " "), Citations: []models.NarrativeCitation{}, Dropped: dropped}
+	for _, marker := range cited {
+		s := stats[marker-1]
+		n.Citations = append(n.Citations, models.NarrativeCitation{
+			Marker:    marker,
+			Name:      s.Name,
+			Value:     s.Value,
+			Unit:      s.Unit,
+			Source:    s.Source,
+			SourceURL: s.SourceURL,
+		})
+	}
+	return n
+}
+
+// markers returns the statistic numbers in a citation marker that name one
+// of count statistics
+func markers(marker string, count int) []string {
+	var kept []string
+	for _, s := range strings.Split(markerPattern.FindStringSubmatch(marker)[1], ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 1 || n > count {
+			continue
+		}
+		kept = append(kept, strconv.Itoa(n))
+	}
+	return kept
+}
+
+// splitSentences splits text after each ".", "!", or "?" followed by a space
+// and a capital letter or digit, so that abbreviations such as "U.S." and
+// decimals within a sentence do not end it
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(text)
+	for i := 0; i < len(runes)-2; i++ {
+		if !strings.ContainsRune(".!?", runes[i]) || runes[i+1] != ' ' {
+			continue
+		}
+		if next := runes[i+2]; unicode.IsUpper(next) || unicode.IsDigit(next) {
+			sentences = append(sentences, strings.TrimSpace(string(runes[start:i+1])))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
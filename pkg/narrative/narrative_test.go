@@ -0,0 +1,73 @@
+package narrative
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+)
+
+var stats = []models.Statistic{
+	{Name: "Global EV sales in 2023", Value: 14, Unit: "million", Source: "IEA", SourceURL: "https://www.iea.org/ev"},
+	{Name: "EV share of new car sales", Value: 18, Unit: "%", Source: "IEA", SourceURL: "https://www.iea.org/share"},
+	{Name: "EV sales growth", Value: 35, Unit: "%", Source: "BNEF", SourceURL: "https://about.bnef.com/ev"},
+}
+
+func TestCite(t *testing.T) {
+	n := Cite(" Sales reached 14 million [1], 18% of new cars [2,7]. Growth was 35% [3][9].\n", stats)
+
+	want := "Sales reached 14 million [1], 18% of new cars [2]. Growth was 35% [3]."
+	if n.Text != want {
+		t.Errorf("Text = %q; want %q", n.Text, want)
+	}
+	if len(n.Citations) != 3 {
+		t.Fatalf("Citations = %+v", n.Citations)
+	}
+	if c := n.Citations[1]; c.Marker != 2 || c.Name != "EV share of new car sales" || c.SourceURL != "https://www.iea.org/share" {
+		t.Errorf("Citations[1] = %+v", c)
+	}
+}
+
+func TestCiteDropsMisstatedFigures(t *testing.T) {
+	n := Cite("EV sales in the U.S. and Europe helped global sales reach 14 million [1]. "+
+		"That was 25% of new cars [2]! Growth was 35% [3]. EVs are now mainstream.", stats)
+
+	want := "EV sales in the U.S. and Europe helped global sales reach 14 million [1]. Growth was 35% [3]. EVs are now mainstream."
+	if n.Text != want {
+		t.Errorf("Text = %q; want %q", n.Text, want)
+	}
+	if n.Dropped != 1 {
+		t.Errorf("Dropped = %d; want 1", n.Dropped)
+	}
+	if len(n.Citations) != 2 || n.Citations[0].Marker != 1 || n.Citations[1].Marker != 3 {
+		t.Errorf("Citations = %+v", n.Citations)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var prompt string
+	generate := func(_ context.Context, p string) (string, error) {
+		prompt = p
+		return "About 14 million EVs were sold in 2023 [1].", nil
+	}
+	n, err := Write(context.Background(), prompts.Default(), generate, "electric vehicles", stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "[2] EV share of new car sales: 18 %") {
+		t.Errorf("prompt does not list the statistics:\n%s", prompt)
+	}
+	if len(n.Citations) != 1 || n.Citations[0].Marker != 1 {
+		t.Errorf("Citations = %+v", n.Citations)
+	}
+
+	uncited := func(context.Context, string) (string, error) { return "EVs are popular [12].", nil }
+	if _, err := Write(context.Background(), prompts.Default(), uncited, "electric vehicles", stats); err == nil {
+		t.Error("Write accepted a summary citing no statistics")
+	}
+	if _, err := Write(context.Background(), prompts.Default(), generate, "electric vehicles", nil); err == nil {
+		t.Error("Write accepted no statistics")
+	}
+}
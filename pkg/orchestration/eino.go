@@ -3,25 +3,68 @@ package orchestration
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/cloudwego/eino/compose"
+	"google.golang.org/adk/model"
 
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/compare"
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/conflict"
+	"github.com/plexusone/agent-team-stats/pkg/domainyield"
+	"github.com/plexusone/agent-team-stats/pkg/dryrun"
 	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
 	"github.com/plexusone/agent-team-stats/pkg/logging"
 	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/postprocess"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/prompts"
+	"github.com/plexusone/agent-team-stats/pkg/report"
+	"github.com/plexusone/agent-team-stats/pkg/repro"
+	"github.com/plexusone/agent-team-stats/pkg/safety"
+	"github.com/plexusone/agent-team-stats/pkg/semdedup"
+	"github.com/plexusone/agent-team-stats/pkg/series"
+	"github.com/plexusone/agent-team-stats/pkg/sourcelist"
+	"github.com/plexusone/agent-team-stats/pkg/statstore"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+	"github.com/plexusone/agent-team-stats/pkg/timing"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
 )
 
 // EinoOrchestrationAgent uses Eino framework for deterministic orchestration
 type EinoOrchestrationAgent struct {
-	cfg    *config.Config
-	client *http.Client
-	graph  *compose.Graph[*models.OrchestrationRequest, *models.OrchestrationResponse]
-	logger *slog.Logger
+	cfg       *config.Config
+	client    *http.Client
+	graph     *compose.Graph[*models.OrchestrationRequest, *models.OrchestrationResponse]
+	graphErr  error             // Why the graph could not be built; runs fail with it
+	hook      *postprocess.Hook // Transforms the final statistics; nil unless POSTPROCESS_* is set
+	hookErr   error             // Why the hook could not be set up; runs fail with it
+	safety    *safety.Filter    // Screens topics and verified statistics; nil unless SAFETY_RULES_FILE is set
+	safetyErr error             // Why the safety rules could not be loaded; runs fail with it
+	dedup     *semdedup.Deduper
+	reports   *report.Store      // Nil unless REPORT_DIR is set
+	yields    *domainyield.Store // Nil unless DOMAIN_YIELD_FILE is set
+	stats     *statstore.Store   // Nil unless STATS_STORE_FILE is set
+	jobs      *jobqueue.Runner   // Nil unless JOB_QUEUE is set
+	archive   archive.Store      // Stores the artifacts of reproducible runs; nil unless set with UseArchive
+	planner   *dryrun.Planner
+	progress  *progress.Hub
+	prompts   *prompts.Set
+	logger    *slog.Logger
+
+	// LLM for the summaries of include_summary requests; nil when no model
+	// could be created, which leaves summaries out
+	modelFactory *llm.ModelFactory
+	model        model.LLM
 }
 
 // NewEinoOrchestrationAgent creates a new Eino-based orchestration agent
@@ -31,202 +74,148 @@ func NewEinoOrchestrationAgent(cfg *config.Config, logger *slog.Logger) *EinoOrc
 	}
 
 	oa := &EinoOrchestrationAgent{
-		cfg:    cfg,
-		client: &http.Client{Timeout: time.Duration(cfg.HTTPTimeoutSeconds) * time.Second},
-		logger: logger,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: time.Duration(cfg.HTTPTimeoutSeconds) * time.Second},
+		progress: progress.NewHub(),
+		logger:   logger,
 	}
 
-	// Build the deterministic workflow graph
-	oa.graph = oa.buildWorkflowGraph()
-
-	return oa
-}
-
-// buildWorkflowGraph creates a deterministic Eino graph for the workflow
-func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.OrchestrationRequest, *models.OrchestrationResponse] {
-	// Create a new graph with typed input/output
-	g := compose.NewGraph[*models.OrchestrationRequest, *models.OrchestrationResponse]()
-
-	// Node names
-	const (
-		nodeValidateInput  = "validate_input"
-		nodeResearch       = "research"
-		nodeSynthesis      = "synthesis"
-		nodeVerification   = "verification"
-		nodeCheckQuality   = "check_quality"
-		nodeRetryResearch  = "retry_research"
-		nodeFormatResponse = "format_response"
-	)
-
-	// Add Lambda nodes for each step in the workflow
-
-	// 1. Validate Input Node
-	validateInputLambda := compose.InvokableLambda(func(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationRequest, error) {
-		logger := logging.FromContext(ctx)
-		logger.Info("validating input", "topic", req.Topic)
-
-		// Set defaults
-		if req.MinVerifiedStats == 0 {
-			req.MinVerifiedStats = 10
-		}
-		if req.MaxCandidates == 0 {
-			req.MaxCandidates = 30
-		}
-
-		return req, nil
-	})
-	if err := g.AddLambdaNode(nodeValidateInput, validateInputLambda); err != nil {
-		oa.logger.Warn("failed to add validate input node", "error", err)
+	// Semantic dedup is optional; a misconfigured embedder disables it
+	dedup, err := semdedup.FromConfig(logging.WithLogger(context.Background(), logger), cfg, logger)
+	if err != nil {
+		logger.Warn("semantic dedup disabled", "error", err)
 	}
+	oa.dedup = dedup
 
-	// 2. Research Node - calls research agent to find sources (URLs)
-	researchLambda := compose.InvokableLambda(func(ctx context.Context, req *models.OrchestrationRequest) (*ResearchState, error) {
-		logger := logging.FromContext(ctx)
-		logger.Info("executing research", "topic", req.Topic)
-
-		researchReq := &models.ResearchRequest{
-			Topic:         req.Topic,
-			MinStatistics: req.MinVerifiedStats,
-			MaxStatistics: req.MaxCandidates,
-			ReputableOnly: req.ReputableOnly,
-		}
-
-		resp, err := oa.callResearchAgent(ctx, researchReq)
-		if err != nil {
-			return nil, fmt.Errorf("research failed: %w", err)
-		}
-
-		// Convert candidates to search results
-		searchResults := make([]models.SearchResult, 0, len(resp.Candidates))
-		for _, cand := range resp.Candidates {
-			searchResults = append(searchResults, models.SearchResult{
-				URL:     cand.SourceURL,
-				Title:   cand.Name,
-				Snippet: cand.Excerpt,
-				Domain:  cand.Source,
-			})
-		}
-
-		logger.Info("research completed", "sources", len(searchResults))
+	// Invalid prompt overrides fall back to the embedded defaults
+	promptSet, err := prompts.FromConfig(cfg)
+	if err != nil {
+		logger.Warn("using default prompts", "error", err)
+		promptSet = prompts.Default()
+	}
+	oa.prompts = promptSet
+	oa.planner = dryrun.New(cfg, promptSet, oa.callResearchAgent)
+
+	// The graph itself calls no LLM; one is only needed to write summaries
+	ctx := logging.WithLogger(context.Background(), logger)
+	oa.modelFactory = llm.NewModelFactory(ctx, cfg)
+	if m, err := oa.modelFactory.CreateModelFor(ctx, llm.StagePlanning); err != nil {
+		logger.Warn("summaries disabled", "error", err)
+	} else {
+		oa.model = m
+	}
 
-		return &ResearchState{
-			Request:       req,
-			SearchResults: searchResults,
-		}, nil
-	})
-	if err := g.AddLambdaNode(nodeResearch, researchLambda); err != nil {
-		oa.logger.Warn("failed to add research node", "error", err)
+	// An unusable report directory disables saving reports
+	reports, err := report.NewStore(cfg)
+	if err != nil {
+		logger.Warn("verification reports disabled", "error", err)
 	}
+	oa.reports = reports
 
-	// 3. Synthesis Node - calls synthesis agent to extract statistics
-	synthesisLambda := compose.InvokableLambda(func(ctx context.Context, state *ResearchState) (*SynthesisState, error) {
-		logger := logging.FromContext(ctx)
-		logger.Info("synthesizing statistics", "sources", len(state.SearchResults))
+	// An unreadable domain yield file disables learning low-yield domains
+	yields, err := domainyield.NewStore(cfg)
+	if err != nil {
+		logger.Warn("domain yield recording disabled", "error", err)
+	}
+	oa.yields = yields
 
-		synthesisReq := &models.SynthesisRequest{
-			Topic:         state.Request.Topic,
-			SearchResults: state.SearchResults,
-			MinStatistics: state.Request.MinVerifiedStats,
-			MaxStatistics: state.Request.MaxCandidates,
-		}
+	// An unreadable statistics store disables retrieval of past statistics
+	stats, err := statstore.NewStore(logging.WithLogger(context.Background(), logger), cfg, logger)
+	if err != nil {
+		logger.Warn("statistics store disabled", "error", err)
+	}
+	oa.stats = stats
 
-		resp, err := oa.callSynthesisAgent(ctx, synthesisReq)
-		if err != nil {
-			return nil, fmt.Errorf("synthesis failed: %w", err)
-		}
+	// An unusable post-processing hook fails every run rather than
+	// returning statistics it has not transformed
+	oa.hook, err = postprocess.New(ctx, cfg)
+	if err != nil {
+		logger.Error("post-processing hook unusable", "error", err)
+		oa.hookErr = fmt.Errorf("post-processing hook unusable: %w", err)
+	}
 
-		logger.Info("synthesis completed", "candidates", len(resp.Candidates))
+	// Unusable safety rules fail every run rather than letting unscreened
+	// statistics through
+	oa.safety, err = safety.FromConfig(cfg)
+	if err != nil {
+		logger.Error("safety rules unusable", "error", err)
+		oa.safetyErr = fmt.Errorf("safety rules unusable: %w", err)
+	}
 
-		return &SynthesisState{
-			Request:       state.Request,
-			SearchResults: state.SearchResults,
-			Candidates:    resp.Candidates,
-		}, nil
-	})
-	if err := g.AddLambdaNode(nodeSynthesis, synthesisLambda); err != nil {
-		oa.logger.Warn("failed to add synthesis node", "error", err)
+	// Build the deterministic workflow graph. An invalid stage list fails
+	// every run rather than running without a stage.
+	oa.graph, oa.graphErr = oa.buildWorkflowGraph()
+	if oa.graphErr != nil {
+		logger.Error("workflow graph not built", "error", oa.graphErr)
 	}
 
-	// 4. Verification Node - calls verification agent
-	verificationLambda := compose.InvokableLambda(func(ctx context.Context, state *SynthesisState) (*VerificationState, error) {
-		logger := logging.FromContext(ctx)
-		logger.Info("verifying candidates", "count", len(state.Candidates))
+	return oa
+}
 
-		verifyReq := &models.VerificationRequest{
-			Candidates: state.Candidates,
-		}
+// Close flushes the observability data of the agent's LLM calls and
+// releases the post-processing hook
+func (oa *EinoOrchestrationAgent) Close() error {
+	return errors.Join(oa.hook.Close(context.Background()), oa.modelFactory.Close())
+}
 
-		resp, err := oa.callVerificationAgent(ctx, verifyReq)
-		if err != nil {
-			return nil, fmt.Errorf("verification failed: %w", err)
-		}
+// Reload swaps in the LLM credentials of a reloaded configuration
+func (oa *EinoOrchestrationAgent) Reload(ctx context.Context, cfg *config.Config) error {
+	return oa.modelFactory.Reload(ctx, cfg)
+}
 
-		// Extract verified statistics
-		var verifiedStats []models.Statistic
-		for _, result := range resp.Results {
-			if result.Verified {
-				verifiedStats = append(verifiedStats, *result.Statistic)
-			}
-		}
+// Prompts returns the prompt templates used by the agent
+func (oa *EinoOrchestrationAgent) Prompts() *prompts.Set {
+	return oa.prompts
+}
 
-		return &VerificationState{
-			Request:       state.Request,
-			AllCandidates: state.Candidates,
-			Verified:      verifiedStats,
-			Failed:        resp.Failed,
-		}, nil
+// buildWorkflowGraph creates a deterministic Eino graph for the workflow:
+// input validation, then the configured stages in order, then the response
+func (oa *EinoOrchestrationAgent) buildWorkflowGraph() (*compose.Graph[*models.OrchestrationRequest, *models.OrchestrationResponse], error) {
+	stageList, err := pipeline(oa.cfg.OrchestrationStages, map[string]Stage{
+		StageResearch:     StageFunc{StageResearch, oa.research},
+		StageSynthesis:    StageFunc{StageSynthesis, oa.synthesize},
+		StageVerification: StageFunc{StageVerification, oa.verify},
+		StageCheckQuality: StageFunc{StageCheckQuality, oa.checkQuality},
 	})
-	if err := g.AddLambdaNode(nodeVerification, verificationLambda); err != nil {
-		oa.logger.Warn("failed to add verification node", "error", err)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ORCHESTRATION_STAGES: %w", err)
 	}
 
-	// 5. Quality Check Node - deterministic decision
-	qualityCheckLambda := compose.InvokableLambda(func(ctx context.Context, state *VerificationState) (*QualityDecision, error) {
-		logger := logging.FromContext(ctx)
-		verified := len(state.Verified)
-		target := state.Request.MinVerifiedStats
-
-		logger.Info("quality check", "verified", verified, "target", target)
-
-		decision := &QualityDecision{
-			State:     state,
-			NeedMore:  verified < target,
-			Shortfall: target - verified,
-		}
+	// Create a new graph with typed input/output
+	g := compose.NewGraph[*models.OrchestrationRequest, *models.OrchestrationResponse]()
 
-		if decision.NeedMore {
-			logger.Info("need more verified statistics", "shortfall", decision.Shortfall)
-		} else {
-			logger.Info("quality target met")
-		}
+	// 1. Validate Input Node
+	validateInputLambda := compose.InvokableLambda(func(ctx context.Context, req *models.OrchestrationRequest) (*State, error) {
+		logger := logging.FromContext(ctx)
+		logger.Info("validating input",
+			"topic", req.Topic,
+			"min_verified_stats", req.MinVerifiedStats,
+			"max_candidates", req.MaxCandidates)
 
-		return decision, nil
+		return &State{Request: req}, nil
 	})
-	if err := g.AddLambdaNode(nodeCheckQuality, qualityCheckLambda); err != nil {
-		oa.logger.Warn("failed to add quality check node", "error", err)
+	if err := g.AddLambdaNode(nodeValidateInput, validateInputLambda); err != nil {
+		return nil, fmt.Errorf("failed to add validate input node: %w", err)
 	}
 
-	// 6. Retry Research Node (if needed) - NOT IMPLEMENTED YET in 4-agent architecture
-	// TODO: Implement retry logic for 4-agent workflow
-	retryResearchLambda := compose.InvokableLambda(func(ctx context.Context, decision *QualityDecision) (*VerificationState, error) {
-		if !decision.NeedMore {
-			// No retry needed, return existing state
-			return decision.State, nil
+	// 2. A node per stage, each passing the state to the next
+	flow := []string{nodeValidateInput}
+	for _, stage := range stageList {
+		run := func(ctx context.Context, state *State) (*State, error) {
+			next, err := stage.Run(ctx, state)
+			if err == nil && next == nil {
+				err = fmt.Errorf("stage %s returned no state", stage.Name())
+			}
+			return next, err
 		}
-
-		logger := logging.FromContext(ctx)
-		logger.Warn("retry logic not yet implemented for 4-agent architecture", "shortfall", decision.Shortfall)
-
-		// For now, just return the existing state
-		// TODO: Implement: Research → Synthesis → Verification loop
-		return decision.State, nil
-	})
-	if err := g.AddLambdaNode(nodeRetryResearch, retryResearchLambda); err != nil {
-		oa.logger.Warn("failed to add retry research node", "error", err)
+		if err := g.AddLambdaNode(stage.Name(), compose.InvokableLambda(run)); err != nil {
+			return nil, fmt.Errorf("failed to add %s node: %w", stage.Name(), err)
+		}
+		flow = append(flow, stage.Name())
 	}
 
-	// 7. Format Response Node
-	formatResponseLambda := compose.InvokableLambda(func(ctx context.Context, state *VerificationState) (*models.OrchestrationResponse, error) {
+	// 3. Format Response Node
+	formatResponseLambda := compose.InvokableLambda(func(ctx context.Context, state *State) (*models.OrchestrationResponse, error) {
 		logger := logging.FromContext(ctx)
 		verifiedCount := len(state.Verified)
 		targetCount := state.Request.MinVerifiedStats
@@ -241,54 +230,330 @@ func (oa *EinoOrchestrationAgent) buildWorkflowGraph() *compose.Graph[*models.Or
 		return &models.OrchestrationResponse{
 			Topic:           state.Request.Topic,
 			Statistics:      state.Verified,
-			TotalCandidates: len(state.AllCandidates),
+			TotalCandidates: len(state.Candidates),
 			VerifiedCount:   verifiedCount,
 			FailedCount:     state.Failed,
 			Timestamp:       time.Now(),
+			Status:          models.RunStatus(verifiedCount, targetCount, state.NoSources),
 			Partial:         isPartial,
 			TargetCount:     targetCount,
+			Rejected:        state.Rejected,
+			Sources:         state.Sources,
+			Query:           state.Query,
 		}, nil
 	})
 	if err := g.AddLambdaNode(nodeFormatResponse, formatResponseLambda); err != nil {
-		oa.logger.Warn("failed to add format response node", "error", err)
+		return nil, fmt.Errorf("failed to add format response node: %w", err)
 	}
+	flow = append(flow, nodeFormatResponse)
 
 	// Add edges to define the workflow
-	_ = g.AddEdge(compose.START, nodeValidateInput)
-	_ = g.AddEdge(nodeValidateInput, nodeResearch)
-	_ = g.AddEdge(nodeResearch, nodeSynthesis)     // NEW: Research → Synthesis
-	_ = g.AddEdge(nodeSynthesis, nodeVerification) // NEW: Synthesis → Verification
-	_ = g.AddEdge(nodeVerification, nodeCheckQuality)
-
-	// Conditional branching based on quality check
-	_ = g.AddEdge(nodeCheckQuality, nodeRetryResearch)
-	_ = g.AddEdge(nodeRetryResearch, nodeFormatResponse)
+	_ = g.AddEdge(compose.START, flow[0])
+	for i := 1; i < len(flow); i++ {
+		_ = g.AddEdge(flow[i-1], flow[i])
+	}
 	_ = g.AddEdge(nodeFormatResponse, compose.END)
 
-	oa.logger.Info("workflow graph built", "flow", "ValidateInput → Research → Synthesis → Verification → QualityCheck → Format")
+	oa.logger.Info("workflow graph built", "flow", strings.Join(flow, " → "))
+
+	return g, nil
+}
+
+// research finds the sources to read: those the request lists, or those the
+// research agent finds
+func (oa *EinoOrchestrationAgent) research(ctx context.Context, state *State) (*State, error) {
+	logger := logging.FromContext(ctx)
+	req := state.Request
+
+	// Listed sources replace research
+	if len(req.Sources) > 0 {
+		if err := sourcelist.Store(ctx, oa.archive, req.Sources); err != nil {
+			return nil, err
+		}
+		logger.Info("reading listed sources", "count", len(req.Sources))
+		state.SearchResults = sourcelist.Results(req.Sources)
+		return state, nil
+	}
+
+	logger.Info("executing research", "topic", req.Topic)
+
+	researchReq := &models.ResearchRequest{
+		Topic:         req.Topic,
+		MinStatistics: req.MinVerifiedStats,
+		MaxStatistics: req.MaxPages,
+		ReputableOnly: req.ReputableOnly,
+		Reproducible:  req.Reproducible,
+	}
+
+	progress.FromContext(ctx).Stage(progress.StageResearch, 1)
+	searchStart := time.Now()
+	resp, err := oa.callResearchAgent(ctx, researchReq)
+	timing.FromContext(ctx).Since(timing.Search, searchStart)
+	if err != nil {
+		return nil, fmt.Errorf("research failed: %w", err)
+	}
+
+	// Convert candidates to search results
+	searchResults := make([]models.SearchResult, 0, len(resp.Candidates))
+	for _, cand := range resp.Candidates {
+		searchResults = append(searchResults, models.SearchResult{
+			URL:     cand.SourceURL,
+			Title:   cand.Name,
+			Snippet: cand.Excerpt,
+			Domain:  cand.Source,
+		})
+	}
+
+	logger.Info("research completed", "sources", len(searchResults))
+	if resp.Query != "" {
+		logger.Info("research relaxed the search query", "query", resp.Query)
+	}
+
+	state.SearchResults = searchResults
+	state.Query = resp.Query
+	return state, nil
+}
+
+// synthesize calls the synthesis agent to extract candidate statistics from
+// the sources
+func (oa *EinoOrchestrationAgent) synthesize(ctx context.Context, state *State) (*State, error) {
+	logger := logging.FromContext(ctx)
+	if len(state.SearchResults) == 0 {
+		logger.Warn("search found no sources", "topic", state.Request.Topic)
+		state.NoSources = true
+		return state, nil
+	}
+	logger.Info("synthesizing statistics", "sources", len(state.SearchResults))
+	progress.FromContext(ctx).Stage(progress.StageSynthesis, 1)
+
+	synthesisReq := &models.SynthesisRequest{
+		Topic:         state.Request.Topic,
+		SearchResults: state.SearchResults,
+		MinStatistics: state.Request.MinVerifiedStats,
+		MaxStatistics: min(state.Request.Budget(state.Request.MinVerifiedStats), state.Request.MaxCandidates),
+		Model:         state.Request.SynthesisOverride(),
+		Generation:    state.Request.Generation,
+		Sampling:      state.Request.Sampling(),
+		StageLimits:   state.Request.StageLimits,
+	}
+	if len(state.Request.Sources) > 0 {
+		// The one pass reads every listed source, keeping every candidate
+		// within max candidates
+		synthesisReq.MaxPages = len(state.SearchResults)
+		synthesisReq.MaxStatistics = state.Request.MaxCandidates
+	}
+
+	resp, err := oa.callSynthesisAgent(ctx, synthesisReq)
+	if err != nil {
+		return nil, fmt.Errorf("synthesis failed: %w", err)
+	}
+
+	usage.FromContext(ctx).Merge(resp.Usage)
+	timing.FromContext(ctx).Merge(resp.Timings)
+	logger.Info("synthesis completed", "candidates", len(resp.Candidates))
+
+	// Drop the kinds of statistic the request excludes before paying to verify them
+	candidates, excluded := state.Request.TypeFilter.Filter(resp.Candidates)
+	if excluded > 0 {
+		logger.Info("excluded candidates by statistic type", "excluded", excluded, "kept", len(candidates))
+	}
+	progress.FromContext(ctx).Candidates(len(candidates))
+
+	state.Candidates = candidates
+	return state, nil
+}
+
+// verify calls the verification agent on the candidates
+func (oa *EinoOrchestrationAgent) verify(ctx context.Context, state *State) (*State, error) {
+	logger := logging.FromContext(ctx)
+	if state.NoSources {
+		return state, nil
+	}
+	logger.Info("verifying candidates", "count", len(state.Candidates))
+	progress.FromContext(ctx).Stage(progress.StageVerification, 1)
+
+	verifyReq := &models.VerificationRequest{
+		Candidates: state.Candidates,
+		Model:      state.Request.VerificationOverride(),
+		Generation: state.Request.Generation,
+		Sampling:   state.Request.Sampling(),
+
+		PermissiveLicenseOnly: state.Request.PermissiveLicenseOnly,
+	}
+
+	resp, err := oa.callVerificationAgent(ctx, verifyReq)
+	if err != nil {
+		return nil, fmt.Errorf("verification failed: %w", err)
+	}
+	usage.FromContext(ctx).Merge(resp.Usage)
+	timing.FromContext(ctx).Merge(resp.Timings)
+	if blocked := oa.safety.Screen(resp); blocked > 0 {
+		logger.Info("statistics blocked by safety filter", "count", blocked)
+	}
+
+	// Extract verified statistics
+	var verifiedStats []models.Statistic
+	var rejected []models.VerificationResult
+	for _, result := range resp.Results {
+		if result.Verified {
+			verifiedStats = append(verifiedStats, *result.Statistic)
+		} else {
+			rejected = append(rejected, result)
+		}
+	}
+	state.Verified = verifiedStats
+	state.Failed = resp.Failed
+	state.Rejected = rejected
+	state.Sources = resp.Sources
+	return state, nil
+}
+
+// checkQuality compares the verified statistics with the target
+// (deterministic decision)
+func (oa *EinoOrchestrationAgent) checkQuality(ctx context.Context, state *State) (*State, error) {
+	logger := logging.FromContext(ctx)
+	verified := len(state.Verified)
+	target := state.Request.MinVerifiedStats
+
+	logger.Info("quality check", "verified", verified, "target", target)
+
+	if verified >= target {
+		logger.Info("quality target met")
+		return state, nil
+	}
 
-	return g
+	// TODO: Implement retry logic for the 4-agent workflow:
+	// Research → Synthesis → Verification loop
+	logger.Warn("retry logic not yet implemented for 4-agent architecture", "shortfall", target-verified)
+	return state, nil
 }
 
-// Orchestrate executes the deterministic Eino workflow
+// Orchestrate executes the deterministic Eino workflow. Requests with Compare
+// set run the workflow once per entity and align the results; requests with
+// DryRun set only return the plan. Reproducible requests record their
+// intermediate artifacts.
 func (oa *EinoOrchestrationAgent) Orchestrate(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
-	// Inject logger into context for lambda nodes
+	oa.cfg.ApplyDefaults(req)
+	if err := llm.ValidateRequest(oa.cfg, req); err != nil {
+		return nil, err
+	}
+	flags, err := oa.safety.ScreenRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		return oa.planner.Plan(ctx, req)
+	}
+	if req.Reproducible {
+		ctx = repro.Begin(ctx, req, oa.archive, oa.logger)
+	}
+	var resp *models.OrchestrationResponse
+	if len(req.Compare) > 0 {
+		resp, err = compare.Run(ctx, req, oa.runWorkflow)
+	} else {
+		resp, err = oa.runWorkflow(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.SafetyFlags = flags
+	if req.IncludeSummary {
+		oa.summarize(ctx, resp)
+	}
+	resp.Reproducibility = repro.FromContext(ctx).Reproducibility(req.Sampling())
+	oa.reports.Attach(ctx, resp, oa.logger)
+	if err := oa.yields.Record(resp); err != nil {
+		oa.logger.Warn("failed to record domain yield", "error", err)
+	}
+	if err := oa.stats.Record(ctx, resp); err != nil {
+		oa.logger.Warn("failed to store verified statistics", "error", err)
+	}
+	return resp, nil
+}
+
+// Reports returns the store of saved verification reports, or nil when
+// REPORT_DIR is not set
+func (oa *EinoOrchestrationAgent) Reports() *report.Store {
+	return oa.reports
+}
+
+// Statistics returns the store of statistics verified by past runs, or nil
+// when STATS_STORE_FILE is not set
+func (oa *EinoOrchestrationAgent) Statistics() *statstore.Store {
+	return oa.stats
+}
+
+// Progress returns the hub reporting the progress of active runs
+func (oa *EinoOrchestrationAgent) Progress() *progress.Hub {
+	return oa.progress
+}
+
+// UseJobQueue makes HandleOrchestrationRequest queue requests for the job
+// workers instead of running them
+func (oa *EinoOrchestrationAgent) UseJobQueue(jobs *jobqueue.Runner) {
+	oa.jobs = jobs
+}
+
+// UseArchive makes reproducible runs store their intermediate artifacts in
+// store
+func (oa *EinoOrchestrationAgent) UseArchive(store archive.Store) {
+	oa.archive = store
+}
+
+// runWorkflow compiles and invokes the workflow graph for a single topic
+func (oa *EinoOrchestrationAgent) runWorkflow(ctx context.Context, req *models.OrchestrationRequest) (_ *models.OrchestrationResponse, err error) {
+	// Fail before the run is reported, so it never shows as active
+	if oa.graphErr != nil {
+		return nil, oa.graphErr
+	}
+	if oa.hookErr != nil {
+		return nil, oa.hookErr
+	}
+	if oa.safetyErr != nil {
+		return nil, oa.safetyErr
+	}
+
+	// Inject logger, usage tracker, and timing recorder into context for
+	// lambda nodes
 	ctx = logging.WithLogger(ctx, oa.logger)
+	tracker := usage.NewTracker("")
+	ctx = usage.WithTracker(ctx, tracker)
+	defer tenant.ChargeFailed(ctx, tracker.Summary, &err)
+	timer := timing.New()
+	ctx = timing.WithRecorder(ctx, timer)
+	ctx, run := oa.progress.Begin(ctx, req.Topic, req.MinVerifiedStats)
 
 	oa.logger.Info("starting deterministic workflow", "topic", req.Topic)
 
 	// Compile the graph
 	compiledGraph, err := oa.graph.Compile(ctx)
 	if err != nil {
+		run.Finish("", err)
 		return nil, fmt.Errorf("failed to compile graph: %w", err)
 	}
 
 	// Execute the graph
 	result, err := compiledGraph.Invoke(ctx, req)
 	if err != nil {
+		run.Finish("", err)
 		return nil, fmt.Errorf("workflow execution failed: %w", err)
 	}
 
+	result.CostSummary = tracker.Summary()
+	result.Statistics, result.DuplicatesMerged = oa.dedup.Dedupe(ctx, result.Statistics)
+	if result.Statistics, err = oa.hook.Apply(ctx, req.Topic, result.Statistics); err != nil {
+		run.Finish("", err)
+		return nil, err
+	}
+	// The counts and status of format_response describe the statistics
+	// before duplicates were merged and the hook dropped or added some
+	result.Recount()
+	// Withdraw streamed statistics the result does not hold
+	run.Reconcile(result.Statistics)
+	run.Finish(result.Status, nil)
+	result.Conflicts = conflict.Detect(result.Statistics)
+	result.Series = series.Assemble(result.Statistics)
+	result.Timings = timer.Timings()
 	oa.logger.Info("workflow completed successfully")
 	return result, nil
 }
@@ -298,28 +563,78 @@ func (oa *EinoOrchestrationAgent) Orchestrate(ctx context.Context, req *models.O
 func (oa *EinoOrchestrationAgent) callResearchAgent(ctx context.Context, req *models.ResearchRequest) (*models.ResearchResponse, error) {
 	var resp models.ResearchResponse
 	url := fmt.Sprintf("%s/research", oa.cfg.ResearchAgentURL)
+	repro.FromContext(ctx).Record(ctx, "research-request", req)
 	if err := httpclient.PostJSON(ctx, oa.client, url, req, &resp); err != nil {
 		return nil, err
 	}
+	repro.FromContext(ctx).Record(ctx, "research-response", &resp)
+	usage.FromContext(ctx).AddSearches(resp.SearchCalls)
 	return &resp, nil
 }
 
 func (oa *EinoOrchestrationAgent) callSynthesisAgent(ctx context.Context, req *models.SynthesisRequest) (*models.SynthesisResponse, error) {
 	var resp models.SynthesisResponse
 	url := fmt.Sprintf("%s/synthesize", oa.cfg.SynthesisAgentURL)
+	repro.FromContext(ctx).Record(ctx, "synthesis-request", req)
 	if err := httpclient.PostJSON(ctx, oa.client, url, req, &resp); err != nil {
 		return nil, err
 	}
+	repro.FromContext(ctx).Record(ctx, "synthesis-response", &resp)
 	return &resp, nil
 }
 
+// callVerificationAgent verifies candidates, reporting each result to the
+// run's progress as soon as the agent streams it, so statistics reach
+// watchers the moment they pass verification. The results of a call that
+// fails are retracted.
 func (oa *EinoOrchestrationAgent) callVerificationAgent(ctx context.Context, req *models.VerificationRequest) (*models.VerificationResponse, error) {
-	var resp models.VerificationResponse
 	url := fmt.Sprintf("%s/verify", oa.cfg.VerificationAgentURL)
-	if err := httpclient.PostJSON(ctx, oa.client, url, req, &resp); err != nil {
+	repro.FromContext(ctx).Record(ctx, "verification-request", req)
+	var onResult func(models.VerificationResult)
+	run := progress.FromContext(ctx)
+	var streamed []models.Statistic
+	failed := 0
+	if run != nil {
+		onResult = func(result models.VerificationResult) {
+			oa.safety.ScreenResult(&result)
+			run.Result(result)
+			if result.Verified && result.Statistic != nil {
+				streamed = append(streamed, *result.Statistic)
+			} else {
+				failed++
+			}
+		}
+	}
+	resp, err := httpclient.PostVerification(ctx, oa.client, url, req, onResult)
+	if err != nil {
+		// The pass failed, so nothing it streamed is part of the run
+		run.Retract(streamed, failed)
 		return nil, err
 	}
-	return &resp, nil
+	repro.FromContext(ctx).Record(ctx, "verification-response", resp)
+	return resp, nil
+}
+
+// Verify verifies candidates with the verification agent, for the
+// verify_statistics tool
+func (oa *EinoOrchestrationAgent) Verify(ctx context.Context, req *models.VerificationRequest) (*models.VerificationResponse, error) {
+	return oa.callVerificationAgent(ctx, req)
+}
+
+// ValidateRequest checks an orchestration request before it is run or
+// queued: its model overrides, the safety rules, and that the documents it
+// lists can be read
+func (oa *EinoOrchestrationAgent) ValidateRequest(req *models.OrchestrationRequest) error {
+	if err := llm.ValidateRequest(oa.cfg, req); err != nil {
+		return err
+	}
+	if _, err := oa.safety.ScreenRequest(req); err != nil {
+		return err
+	}
+	if req.HasDocuments() && oa.archive == nil {
+		return sourcelist.ErrNoArchive
+	}
+	return nil
 }
 
 // HTTP Handler
@@ -329,9 +644,47 @@ func (oa *EinoOrchestrationAgent) HandleOrchestrationRequest(w http.ResponseWrit
 		return
 	}
 
+	offset, limit, err := models.ParsePage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var req models.OrchestrationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := migrate.Decode(http.MaxBytesReader(w, r.Body, models.MaxRequestBytes), &req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), status)
+		return
+	}
+	if err := oa.ValidateRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A client asking for server-sent events gets each statistic the moment
+	// it passes verification, and then the result. The run stays on this
+	// replica, in queue mode too.
+	if progress.WantsEvents(r) {
+		oa.progress.ServeRun(w, r, func(ctx context.Context) (any, error) {
+			resp, err := oa.Orchestrate(ctx, &req)
+			if err != nil {
+				return nil, err
+			}
+			tenant.Charge(ctx, resp.CostSummary)
+			resp.Paginate(offset, limit)
+			return resp, nil
+		}, oa.logger)
+		return
+	}
+
+	// In queue mode a worker runs the request; the client polls /jobs/{id}.
+	// A dry run only searches, so it is answered directly.
+	if oa.jobs != nil && !req.DryRun {
+		oa.jobs.Accept(w, r, &req)
 		return
 	}
 
@@ -340,34 +693,11 @@ func (oa *EinoOrchestrationAgent) HandleOrchestrationRequest(w http.ResponseWrit
 		http.Error(w, fmt.Sprintf("Orchestration failed: %v", err), http.StatusInternalServerError)
 		return
 	}
+	tenant.Charge(r.Context(), resp.CostSummary)
+	resp.Paginate(offset, limit)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		oa.logger.Error("failed to encode response", "error", err)
 	}
 }
-
-// State types for the workflow
-type ResearchState struct {
-	Request       *models.OrchestrationRequest
-	SearchResults []models.SearchResult
-}
-
-type SynthesisState struct {
-	Request       *models.OrchestrationRequest
-	SearchResults []models.SearchResult
-	Candidates    []models.CandidateStatistic
-}
-
-type VerificationState struct {
-	Request       *models.OrchestrationRequest
-	AllCandidates []models.CandidateStatistic
-	Verified      []models.Statistic
-	Failed        int
-}
-
-type QualityDecision struct {
-	State     *VerificationState
-	NeedMore  bool
-	Shortfall int
-}
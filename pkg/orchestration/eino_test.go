@@ -0,0 +1,29 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+)
+
+func TestRunWorkflowSetupErrorLeavesNoActiveRun(t *testing.T) {
+	unusable := errors.New("unusable")
+	for name, oa := range map[string]*EinoOrchestrationAgent{
+		"graph":  {graphErr: unusable},
+		"hook":   {hookErr: unusable},
+		"safety": {safetyErr: unusable},
+	} {
+		oa.progress = progress.NewHub()
+		oa.logger = slog.New(slog.DiscardHandler)
+		if _, err := oa.runWorkflow(context.Background(), &models.OrchestrationRequest{Topic: "EV sales"}); !errors.Is(err, unusable) {
+			t.Errorf("%s: runWorkflow() error = %v, want %v", name, err, unusable)
+		}
+		if runs := oa.progress.Runs(); len(runs) != 0 {
+			t.Errorf("%s: %d runs left active after a failed setup", name, len(runs))
+		}
+	}
+}
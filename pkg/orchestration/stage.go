@@ -0,0 +1,145 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Stage is a step of the Eino pipeline. Each stage receives the run's state
+// as the previous stage left it and returns the state for the next. A stage
+// may filter or annotate what it is given, such as dropping candidates a
+// company's compliance rules exclude before they are verified; an error
+// fails the run.
+type Stage interface {
+	// Name identifies the stage in ORCHESTRATION_STAGES and logs
+	Name() string
+	Run(ctx context.Context, state *State) (*State, error)
+}
+
+// State is a run's progress through the pipeline
+type State struct {
+	Request       *models.OrchestrationRequest
+	SearchResults []models.SearchResult       // Sources found by research, or listed by the request
+	Query         string                      // Relaxed search query, if research broadened the topic
+	Candidates    []models.CandidateStatistic // Extracted by synthesis, to be verified
+	Verified      []models.Statistic          // Candidates that passed verification
+	Rejected      []models.VerificationResult // Candidates that failed verification
+	Failed        int                         // Candidates that failed verification
+	Sources       []models.SourceFingerprint  // Fingerprints of the sources verification fetched
+	NoSources     bool                        // Search found nothing, so later stages are skipped
+}
+
+// Built-in stage names, in the order they must run
+const (
+	StageResearch     = "research"
+	StageSynthesis    = "synthesis"
+	StageVerification = "verification"
+	StageCheckQuality = "check_quality"
+)
+
+// builtinStages are the pipeline's own stages in order
+var builtinStages = []string{StageResearch, StageSynthesis, StageVerification, StageCheckQuality}
+
+// Graph nodes around the stages, whose names no stage may take
+const (
+	nodeValidateInput  = "validate_input"
+	nodeFormatResponse = "format_response"
+)
+
+// StageFunc adapts a function to a Stage
+type StageFunc struct {
+	StageName string
+	Fn        func(ctx context.Context, state *State) (*State, error)
+}
+
+// Name returns the stage's name
+func (s StageFunc) Name() string { return s.StageName }
+
+// Run calls the function
+func (s StageFunc) Run(ctx context.Context, state *State) (*State, error) { return s.Fn(ctx, state) }
+
+var (
+	stagesMu sync.RWMutex
+	stages   = make(map[string]Stage)
+)
+
+// RegisterStage makes a custom stage available to ORCHESTRATION_STAGES.
+// Call it from an init function of a build of the orchestrator that imports
+// the stage. It panics on an empty name or one already registered, which
+// includes the built-in stage names and the graph's validate_input and
+// format_response nodes.
+func RegisterStage(s Stage) {
+	name := s.Name()
+	stagesMu.Lock()
+	defer stagesMu.Unlock()
+	if name == "" || slices.Contains(builtinStages, name) || name == nodeValidateInput || name == nodeFormatResponse {
+		panic(fmt.Sprintf("orchestration: invalid stage name %q", name))
+	}
+	if _, ok := stages[name]; ok {
+		panic(fmt.Sprintf("orchestration: stage %q registered twice", name))
+	}
+	stages[name] = s
+}
+
+// RegisteredStages returns the names of the registered custom stages
+func RegisteredStages() []string {
+	stagesMu.RLock()
+	defer stagesMu.RUnlock()
+	return registeredLocked()
+}
+
+// pipeline resolves a configured stage list to stages, taking the built-in
+// ones from builtin. An empty list is the built-in stages. A list must name
+// every built-in stage once, in their order, with custom stages anywhere
+// among them; a stage that is missing or unknown fails the pipeline rather
+// than running without it.
+func pipeline(names []string, builtin map[string]Stage) ([]Stage, error) {
+	if len(names) == 0 {
+		names = builtinStages
+	}
+
+	stagesMu.RLock()
+	defer stagesMu.RUnlock()
+
+	var out []Stage
+	seen := make(map[string]bool, len(names))
+	next := 0 // Index of the built-in stage expected next
+	for _, name := range names {
+		if seen[name] {
+			return nil, fmt.Errorf("stage %q is listed twice", name)
+		}
+		seen[name] = true
+
+		if s, ok := builtin[name]; ok {
+			if builtinStages[next] != name {
+				return nil, fmt.Errorf("stage %q is out of order: built-in stages run %v", name, builtinStages)
+			}
+			next++
+			out = append(out, s)
+			continue
+		}
+		s, ok := stages[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown stage %q: built-in stages are %v, registered stages %v", name, builtinStages, registeredLocked())
+		}
+		out = append(out, s)
+	}
+	if next < len(builtinStages) {
+		return nil, fmt.Errorf("stage %q is missing: every built-in stage must run", builtinStages[next])
+	}
+	return out, nil
+}
+
+// registeredLocked is RegisteredStages for callers holding stagesMu
+func registeredLocked() []string {
+	names := make([]string, 0, len(stages))
+	for name := range stages {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
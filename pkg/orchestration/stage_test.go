@@ -0,0 +1,50 @@
+package orchestration
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	noop := func(ctx context.Context, state *State) (*State, error) { return state, nil }
+	builtin := make(map[string]Stage)
+	for _, name := range builtinStages {
+		builtin[name] = StageFunc{name, noop}
+	}
+	RegisterStage(StageFunc{"compliance_filter", noop})
+
+	names := func(stages []Stage) []string {
+		var out []string
+		for _, s := range stages {
+			out = append(out, s.Name())
+		}
+		return out
+	}
+
+	got, err := pipeline(nil, builtin)
+	if err != nil || !slices.Equal(names(got), builtinStages) {
+		t.Errorf("pipeline(nil) = %v, %v, want the built-in stages", names(got), err)
+	}
+
+	list := []string{"research", "synthesis", "compliance_filter", "verification", "check_quality"}
+	got, err = pipeline(list, builtin)
+	if err != nil || !slices.Equal(names(got), list) {
+		t.Errorf("pipeline(%v) = %v, %v", list, names(got), err)
+	}
+
+	for _, tt := range []struct {
+		list []string
+		want string
+	}{
+		{[]string{"research", "synthesis", "verification"}, `"check_quality" is missing`},
+		{[]string{"synthesis", "research", "verification", "check_quality"}, "out of order"},
+		{[]string{"research", "synthesis", "pii_filter", "verification", "check_quality"}, `unknown stage "pii_filter"`},
+		{[]string{"research", "compliance_filter", "synthesis", "compliance_filter", "verification", "check_quality"}, "listed twice"},
+	} {
+		if _, err := pipeline(tt.list, builtin); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("pipeline(%v) error = %v, want %q", tt.list, err, tt.want)
+		}
+	}
+}
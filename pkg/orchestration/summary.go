@@ -0,0 +1,61 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/narrative"
+	"github.com/plexusone/agent-team-stats/pkg/usage"
+)
+
+// summarize writes the cited summary paragraph of resp, adding its LLM usage
+// to the response's cost, whether or not it succeeds. A failure only leaves
+// the summary out, since the statistics themselves are complete.
+func (oa *EinoOrchestrationAgent) summarize(ctx context.Context, resp *models.OrchestrationResponse) {
+	if len(resp.Statistics) == 0 {
+		return
+	}
+	tracker := usage.NewTracker(string(llm.StagePlanning))
+	summary, err := narrative.Write(usage.WithTracker(ctx, tracker), oa.prompts, oa.generate, resp.Topic, resp.Statistics)
+
+	total := usage.NewTracker("")
+	total.Merge(resp.CostSummary)
+	total.Merge(tracker.Summary())
+	resp.CostSummary = total.Summary()
+
+	if err != nil {
+		oa.logger.Warn("failed to write summary", "error", err)
+		return
+	}
+	summary.Model = oa.modelFactory.GetStageInfo(llm.StagePlanning)
+	resp.Summary = summary
+}
+
+// generate sends a single-turn prompt to the LLM and returns the response text
+func (oa *EinoOrchestrationAgent) generate(ctx context.Context, prompt string) (string, error) {
+	if oa.model == nil {
+		return "", errors.New("no LLM is configured")
+	}
+	llmReq := &model.LLMRequest{
+		Contents: genai.Text(prompt),
+	}
+
+	var response string
+	for llmResp, err := range oa.model.GenerateContent(ctx, llmReq, false) {
+		if err != nil {
+			return "", fmt.Errorf("LLM generation failed: %w", err)
+		}
+		if llmResp.Content != nil {
+			for _, part := range llmResp.Content.Parts {
+				response += part.Text
+			}
+		}
+	}
+	return response, nil
+}
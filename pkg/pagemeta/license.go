@@ -0,0 +1,227 @@
+package pagemeta
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// rightsTags are meta tags declaring a page's license or rights, most
+// specific first
+var rightsTags = []string{"dcterms.license", "dc.rights.license", "license", "dcterms.rights", "dc.rights", "rights", "copyright"}
+
+// hasRel reports whether an element's rel attribute lists value
+func hasRel(n *html.Node, value string) bool {
+	for _, rel := range strings.Fields(attr(n, "rel")) {
+		if strings.EqualFold(rel, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOpenLicenseURL reports whether a link points to a Creative Commons or
+// Open Government Licence deed, as license badges in page footers do
+func isOpenLicenseURL(href string) bool {
+	href = strings.ToLower(href)
+	return strings.Contains(href, "creativecommons.org/licenses/") ||
+		strings.Contains(href, "creativecommons.org/publicdomain/") ||
+		strings.Contains(href, "open-government-licence")
+}
+
+// license returns the URL or name of a schema.org license, which may be a
+// string or a CreativeWork
+func license(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]any:
+		for _, key := range []string{"url", "@id", "name"} {
+			if s, ok := v[key].(string); ok && strings.TrimSpace(s) != "" {
+				return strings.TrimSpace(s)
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if s := license(item); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// Notices in page text. Only text as short as a footer line is searched,
+// so an article mentioning the public domain is not taken for a notice.
+var (
+	publicDomainNotice = regexp.MustCompile(`(?i)\b(?:(?:is|are)\s+(?:in|placed\s+in|released\s+(?:in)?to|dedicated\s+to)\s+the\s+public\s+domain|not\s+subject\s+to\s+copyright)\b`)
+	copyrightNotice    = regexp.MustCompile(`(?i)©|\bcopyright\s*(?:\(c\)|\d{4})|\ball\s+rights\s+reserved\b`)
+)
+
+const (
+	maxNoticeText = 300 // Longest text node searched for a notice
+	maxNotice     = 200 // Longest notice kept
+)
+
+// noticeFinder keeps the first public-domain and copyright notices in a
+// page's text
+type noticeFinder struct {
+	publicDomain, copyright string
+}
+
+func (f *noticeFinder) scan(text string) {
+	if f.publicDomain != "" {
+		return
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" || len(text) > maxNoticeText {
+		return
+	}
+	if len(text) > maxNotice {
+		text = strings.ToValidUTF8(text[:maxNotice], "")
+	}
+	switch {
+	case publicDomainNotice.MatchString(text):
+		f.publicDomain = text
+	case f.copyright == "" && copyrightNotice.MatchString(text):
+		f.copyright = text
+	}
+}
+
+// notice returns the page's notice; a public-domain notice outranks a
+// copyright line, which such pages often also carry
+func (f *noticeFinder) notice() string {
+	if f.publicDomain != "" {
+		return f.publicDomain
+	}
+	return f.copyright
+}
+
+// Creative Commons licenses, as deed URLs and as written out
+var (
+	ccDeed = regexp.MustCompile(`creativecommons\.org/licenses/([a-z-]+)`)
+	ccName = regexp.MustCompile(`\bcc[ -]by((?:[ -](?:nc|sa|nd))*)\b`)
+	cc0    = regexp.MustCompile(`\bcc0\b`)
+)
+
+// License classifies what the page declares about reusing its content: its
+// declared license or rights, or else the notice in its text. Relative
+// license links are resolved against pageURL. It returns nil when the page
+// declares nothing.
+func (m Metadata) License(pageURL string) *models.License {
+	var l *models.License
+	switch {
+	case m.Rights != "":
+		l = &models.License{Kind: classifyRights(m.Rights)}
+		if u := resolve(m.Rights, pageURL); u != "" {
+			l.URL = u
+		} else {
+			l.Notice = m.Rights
+		}
+		if l.Notice == "" && (l.Kind == models.LicenseOther || l.Kind == models.LicenseCopyright) {
+			l.Notice = m.Notice
+		}
+	case m.Notice != "":
+		l = &models.License{Kind: classifyRights(m.Notice), Notice: m.Notice}
+	default:
+		return nil
+	}
+	l.Permissive = models.PermissiveLicense(l.Kind)
+	return l
+}
+
+// classifyRights recognizes a license URL or rights statement
+func classifyRights(s string) models.LicenseKind {
+	s = strings.ToLower(s)
+	switch {
+	case strings.Contains(s, "creativecommons.org/publicdomain/zero"), cc0.MatchString(s):
+		return models.LicenseCC0
+	case strings.Contains(s, "creativecommons.org/publicdomain/"), strings.Contains(s, "public domain"),
+		strings.Contains(s, "not subject to copyright"):
+		return models.LicensePublicDomain
+	case strings.Contains(s, "open-government-licence"), strings.Contains(s, "open government licen"):
+		return models.LicenseOGL
+	}
+	if m := ccDeed.FindStringSubmatch(s); m != nil {
+		return ccKind(strings.Split(m[1], "-"))
+	}
+	if m := ccName.FindStringSubmatch(s); m != nil {
+		return ccKind(append([]string{"by"}, strings.FieldsFunc(m[1], func(r rune) bool { return r == ' ' || r == '-' })...))
+	}
+	if strings.Contains(s, "creative commons attribution") {
+		var parts []string
+		for term, part := range map[string]string{"noncommercial": "nc", "non-commercial": "nc", "sharealike": "sa", "share-alike": "sa", "share alike": "sa", "noderiv": "nd", "no deriv": "nd"} {
+			if strings.Contains(s, term) {
+				parts = append(parts, part)
+			}
+		}
+		return ccKind(append([]string{"by"}, parts...))
+	}
+	if copyrightNotice.MatchString(s) || strings.Contains(s, "copyright") {
+		return models.LicenseCopyright
+	}
+	return models.LicenseOther
+}
+
+// ccKind returns the Creative Commons license of a deed code's parts, such
+// as by, nc, and sa, in any order
+func ccKind(parts []string) models.LicenseKind {
+	var by, nc, sa, nd bool
+	for _, p := range parts {
+		switch p {
+		case "by":
+			by = true
+		case "nc":
+			nc = true
+		case "sa":
+			sa = true
+		case "nd":
+			nd = true
+		}
+	}
+	switch {
+	case !by:
+		return models.LicenseOther
+	case nc && nd:
+		return models.LicenseCCBYNCND
+	case nc && sa:
+		return models.LicenseCCBYNCSA
+	case nc:
+		return models.LicenseCCBYNC
+	case nd:
+		return models.LicenseCCBYND
+	case sa:
+		return models.LicenseCCBYSA
+	}
+	return models.LicenseCCBY
+}
+
+// resolve returns rights as an absolute URL when it is a link, resolved
+// against pageURL, or "" when it is a statement
+func resolve(rights, pageURL string) string {
+	if strings.ContainsAny(rights, " \t\n") {
+		return ""
+	}
+	lower := strings.ToLower(rights)
+	switch {
+	case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"):
+		return rights
+	case strings.HasPrefix(lower, "www."), strings.HasPrefix(lower, "creativecommons.org/"):
+		return "https://" + rights
+	case !strings.HasPrefix(rights, "/") && !strings.HasPrefix(rights, "./") && !strings.HasPrefix(rights, "../"):
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil || base.Host == "" {
+		return ""
+	}
+	ref, err := url.Parse(rights)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
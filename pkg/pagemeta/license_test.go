@@ -0,0 +1,80 @@
+package pagemeta
+
+import (
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestLicense(t *testing.T) {
+	const page = "https://stats.example.org/reports/2024"
+	tests := []struct {
+		name       string
+		html       string
+		kind       models.LicenseKind
+		url        string
+		permissive bool
+	}{
+		{
+			name:       "rel license",
+			html:       `<html><head><link rel="license" href="https://creativecommons.org/licenses/by-sa/4.0/"></head></html>`,
+			kind:       models.LicenseCCBYSA,
+			url:        "https://creativecommons.org/licenses/by-sa/4.0/",
+			permissive: true,
+		},
+		{
+			name: "footer badge",
+			html: `<html><body><p>Text</p><footer><a href="//creativecommons.org/licenses/by-nc-nd/4.0/"><img alt="CC"></a>
+				<p>© 2024 Example Institute</p></footer></body></html>`,
+			kind: models.LicenseCCBYNCND,
+			url:  "https://creativecommons.org/licenses/by-nc-nd/4.0/",
+		},
+		{
+			name:       "JSON-LD",
+			html:       `<html><head><script type="application/ld+json">{"@type":"Dataset","license":{"@type":"CreativeWork","name":"CC0 1.0"}}</script></head></html>`,
+			kind:       models.LicenseCC0,
+			permissive: true,
+		},
+		{
+			name:       "Dublin Core rights",
+			html:       `<html><head><meta name="DC.rights" content="Licensed under CC BY 4.0"></head></html>`,
+			kind:       models.LicenseCCBY,
+			permissive: true,
+		},
+		{
+			name:       "Open Government Licence",
+			html:       `<html><body><footer><a rel="license" href="/doc/open-government-licence/version/3/">OGL</a></footer></body></html>`,
+			kind:       models.LicenseOGL,
+			url:        "https://stats.example.org/doc/open-government-licence/version/3/",
+			permissive: true,
+		},
+		{
+			name: "public domain notice",
+			html: `<html><body><p>Unless otherwise noted, information on this site is in the public domain and may be copied.</p>
+				<p>Copyright 2024 Agency</p></body></html>`,
+			kind:       models.LicensePublicDomain,
+			permissive: true,
+		},
+		{
+			name: "copyright footer",
+			html: `<html><body><article>Public domain works entered the public domain in 1990, a long discussion of copyright law follows and continues at length so that this paragraph is far longer than any footer would be, which keeps it from being read as a notice of the page's own terms. More text here to be sure it is long enough to skip.</article>
+				<footer>Copyright © 2024 Example Media. All rights reserved.</footer></body></html>`,
+			kind: models.LicenseCopyright,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := Parse([]byte(tt.html)).License(page)
+			if l == nil {
+				t.Fatal("License = nil")
+			}
+			if l.Kind != tt.kind || l.URL != tt.url || l.Permissive != tt.permissive {
+				t.Errorf("License = %+v; want %s, %q, permissive %v", l, tt.kind, tt.url, tt.permissive)
+			}
+		})
+	}
+
+	if l := Parse([]byte(`<html><body><p>Unemployment fell to 4%.</p></body></html>`)).License(page); l != nil {
+		t.Errorf("License of a page declaring nothing = %+v", l)
+	}
+}
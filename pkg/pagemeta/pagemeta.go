@@ -0,0 +1,259 @@
+// Package pagemeta reads the publisher, publication date, and license an
+// HTML page declares about itself: schema.org JSON-LD, Open Graph and
+// article meta tags, and the citation and Dublin Core tags of scholarly and
+// government sites. Citations then name the publisher and date rather than a
+// bare domain, and users can tell whether excerpts may be republished.
+package pagemeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Metadata is what a page says about its publication. Zero fields were not
+// declared.
+type Metadata struct {
+	Publisher string
+	Published time.Time
+	Rights    string // License URL or rights statement the page declares
+	Notice    string // Copyright or public-domain notice in the page's text
+}
+
+// Meta tag names and properties, most specific first
+var (
+	publisherTags = []string{"og:site_name", "citation_publisher", "dc.publisher", "dcterms.publisher", "publisher"}
+	publishedTags = []string{
+		"article:published_time", "citation_publication_date", "citation_date", "citation_online_date",
+		"dc.date.issued", "dcterms.issued", "dc.date", "dcterms.date", "datepublished",
+		"og:published_time", "pubdate", "publishdate", "publish-date", "date",
+	}
+)
+
+// Parse reads the metadata of an HTML page. JSON-LD takes precedence over
+// meta tags; anything that does not parse is ignored.
+func Parse(body []byte) Metadata {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return Metadata{}
+	}
+
+	tags := make(map[string]string)
+	var ld Metadata
+	var licenseLink, badgeLink string // rel="license" link, and link to an open license without it
+	var notices noticeFinder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode && n.Parent != nil && n.Parent.DataAtom != atom.Script && n.Parent.DataAtom != atom.Style {
+			notices.scan(n.Data)
+		}
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Meta:
+				key := strings.ToLower(attr(n, "property"))
+				if key == "" {
+					key = strings.ToLower(attr(n, "name"))
+				}
+				if key == "" {
+					key = strings.ToLower(attr(n, "itemprop"))
+				}
+				if content := strings.TrimSpace(attr(n, "content")); key != "" && content != "" && tags[key] == "" {
+					tags[key] = content
+				}
+			case atom.Script:
+				if strings.EqualFold(attr(n, "type"), "application/ld+json") && n.FirstChild != nil {
+					fromJSONLD(n.FirstChild.Data, &ld)
+				}
+			case atom.Link, atom.A:
+				href := strings.TrimSpace(attr(n, "href"))
+				switch {
+				case href == "":
+				case hasRel(n, "license"):
+					if licenseLink == "" {
+						licenseLink = href
+					}
+				case badgeLink == "" && isOpenLicenseURL(href):
+					badgeLink = href
+				}
+			case atom.Time:
+				// <time itemprop="datePublished" datetime="...">
+				if strings.EqualFold(attr(n, "itemprop"), "datePublished") && tags["datepublished"] == "" {
+					tags["datepublished"] = attr(n, "datetime")
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	m := ld
+	if m.Publisher == "" {
+		for _, tag := range publisherTags {
+			if v := tags[tag]; v != "" {
+				m.Publisher = v
+				break
+			}
+		}
+	}
+	if m.Published.IsZero() {
+		for _, tag := range publishedTags {
+			if t, ok := ParseDate(tags[tag], time.Time{}); ok {
+				m.Published = t
+				break
+			}
+		}
+	}
+	if m.Rights == "" {
+		m.Rights = licenseLink
+	}
+	if m.Rights == "" {
+		for _, tag := range rightsTags {
+			if v := tags[tag]; v != "" {
+				m.Rights = v
+				break
+			}
+		}
+	}
+	if m.Rights == "" {
+		m.Rights = badgeLink
+	}
+	if m.Notice == "" {
+		m.Notice = notices.notice()
+	}
+	return m
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// fromJSONLD fills the fields of m still unset from a JSON-LD block, which
+// may be one object, an array, or an object with an @graph array
+func fromJSONLD(data string, m *Metadata) {
+	var v any
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return
+	}
+	var visit func(any)
+	visit = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, item := range v {
+				visit(item)
+			}
+		case map[string]any:
+			if graph, ok := v["@graph"]; ok {
+				visit(graph)
+			}
+			if m.Published.IsZero() {
+				if s, ok := v["datePublished"].(string); ok {
+					if t, ok := ParseDate(s, time.Time{}); ok {
+						m.Published = t
+					}
+				}
+			}
+			if m.Publisher == "" {
+				m.Publisher = name(v["publisher"])
+			}
+			if m.Rights == "" {
+				m.Rights = license(v["license"])
+			}
+			if m.Notice == "" {
+				if s, ok := v["copyrightNotice"].(string); ok {
+					m.Notice = strings.TrimSpace(s)
+				}
+			}
+		}
+	}
+	visit(v)
+}
+
+// name returns a schema.org Organization's name, or a plain string
+func name(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case map[string]any:
+		s, _ := v["name"].(string)
+		return strings.TrimSpace(s)
+	case []any:
+		for _, item := range v {
+			if s := name(item); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// dateLayouts are the formats pages and search providers write dates in
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+	"2006/01/02",
+	"2006/1/2",
+	"20060102",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"January 2006",
+	"Jan 2006",
+	"2006-01",
+	time.RFC1123,
+	time.RFC1123Z,
+	"2006",
+}
+
+// relativeDate matches the "3 days ago" dates of search results
+var relativeDate = regexp.MustCompile(`(?i)^(\d+)\s+(minute|hour|day|week|month|year)s?\s+ago$`)
+
+// ParseDate parses a date in any of the common page and search result
+// formats. Relative dates such as "3 days ago" are counted back from now;
+// with a zero now they are rejected.
+func ParseDate(s string, now time.Time) (time.Time, bool) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "."))
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if m := relativeDate.FindStringSubmatch(s); m != nil && !now.IsZero() {
+		n, _ := strconv.Atoi(m[1])
+		switch strings.ToLower(m[2]) {
+		case "minute":
+			return now.Add(-time.Duration(n) * time.Minute), true
+		case "hour":
+			return now.Add(-time.Duration(n) * time.Hour), true
+		case "day":
+			return now.AddDate(0, 0, -n), true
+		case "week":
+			return now.AddDate(0, 0, -7*n), true
+		case "month":
+			return now.AddDate(0, -n, 0), true
+		case "year":
+			return now.AddDate(-n, 0, 0), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,92 @@
+package pagemeta
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		publisher string
+		published string
+	}{
+		{
+			name: "JSON-LD graph",
+			html: `<html><head>
+				<meta property="og:site_name" content="Pew">
+				<script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+					{"@type":"WebSite","name":"pewresearch.org"},
+					{"@type":"NewsArticle","datePublished":"2024-09-17T10:00:00-04:00","publisher":{"@type":"Organization","name":"Pew Research Center"}}
+				]}</script></head></html>`,
+			publisher: "Pew Research Center",
+			published: "2024-09-17",
+		},
+		{
+			name: "Open Graph",
+			html: `<html><head>
+				<meta property="og:site_name" content="IEA">
+				<meta property="article:published_time" content="2024-04-23T00:00:00Z">
+				</head></html>`,
+			publisher: "IEA",
+			published: "2024-04-23",
+		},
+		{
+			name: "citation tags",
+			html: `<html><head>
+				<meta name="citation_publisher" content="Nature Publishing Group">
+				<meta name="citation_publication_date" content="2024/03/05">
+				</head></html>`,
+			publisher: "Nature Publishing Group",
+			published: "2024-03-05",
+		},
+		{
+			name:      "time element",
+			html:      `<html><body><article><time itemprop="datePublished" datetime="2023-11-02">November 2</time></article></body></html>`,
+			published: "2023-11-02",
+		},
+		{
+			name: "nothing declared",
+			html: `<html><head><title>Statistics</title><script type="application/ld+json">not json</script></head></html>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Parse([]byte(tt.html))
+			published := ""
+			if !m.Published.IsZero() {
+				published = m.Published.Format(time.DateOnly)
+			}
+			if m.Publisher != tt.publisher || published != tt.published {
+				t.Errorf("Parse = %q, %q; want %q, %q", m.Publisher, published, tt.publisher, tt.published)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC)
+	for s, want := range map[string]string{
+		"2024-06-01":                    "2024-06-01",
+		"Jun 3, 2024":                   "2024-06-03",
+		"3 June 2024":                   "2024-06-03",
+		"March 2024":                    "2024-03-01",
+		"3 days ago":                    "2024-06-17",
+		"Mon, 03 Jun 2024 08:00:00 GMT": "2024-06-03",
+	} {
+		got, ok := ParseDate(s, now)
+		if !ok || got.Format(time.DateOnly) != want {
+			t.Errorf("ParseDate(%q) = %v, %v; want %s", s, got, ok, want)
+		}
+	}
+
+	for _, s := range []string{"", "soon", "yesterday-ish"} {
+		if _, ok := ParseDate(s, now); ok {
+			t.Errorf("ParseDate(%q) parsed", s)
+		}
+	}
+	if _, ok := ParseDate("3 days ago", time.Time{}); ok {
+		t.Error("relative date parsed without a reference time")
+	}
+}
@@ -0,0 +1,322 @@
+// Package parquet writes flat tables as Apache Parquet files, so exports can
+// be loaded straight into DuckDB, pandas, Spark, and warehouse tools. It is
+// a small writer covering what the exports need: required string, double,
+// int64, and timestamp columns, PLAIN encoded and uncompressed, with rows
+// flushed in row groups as they are written.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// magic starts and ends every Parquet file
+const magic = "PAR1"
+
+// DefaultRowGroupSize is the number of rows buffered before a row group is
+// written
+const DefaultRowGroupSize = 10000
+
+// Type is the type of a column
+type Type int
+
+// Column types and the Go values written to them
+const (
+	String    Type = iota // string
+	Double                // float64
+	Int64                 // int64
+	Timestamp             // time.Time, stored in milliseconds since the Unix epoch
+)
+
+// Column describes one column of the table
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Parquet physical types, converted types, and enum values from parquet.thrift
+const (
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	pageTypeData       = 0
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+)
+
+// Writer writes rows to a Parquet file. Close must be called to write the
+// file footer.
+type Writer struct {
+	w            *countingWriter
+	columns      []Column
+	rowGroupSize int
+
+	rows      [][]any
+	rowGroups []rowGroup
+	numRows   int64
+	closed    bool
+}
+
+// rowGroup records where a written row group's column chunks are
+type rowGroup struct {
+	chunks  []columnChunk
+	size    int64
+	numRows int64
+}
+
+type columnChunk struct {
+	offset int64 // Offset of the data page
+	size   int64 // Page header and data
+	values int64
+}
+
+// NewWriter starts a Parquet file with the given columns on w
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:            &countingWriter{w: w},
+		columns:      columns,
+		rowGroupSize: DefaultRowGroupSize,
+	}
+}
+
+// SetRowGroupSize sets the number of rows per row group
+func (w *Writer) SetRowGroupSize(n int) {
+	w.rowGroupSize = max(n, 1)
+}
+
+// Write adds a row, one value per column of the column's Go type
+func (w *Writer) Write(row []any) error {
+	if w.closed {
+		return errors.New("parquet: write after close")
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: row has %d values for %d columns", len(row), len(w.columns))
+	}
+	for i, c := range w.columns {
+		if err := checkValue(c, row[i]); err != nil {
+			return err
+		}
+	}
+	w.rows = append(w.rows, row)
+	if len(w.rows) >= w.rowGroupSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+func checkValue(c Column, v any) error {
+	ok := false
+	switch c.Type {
+	case String:
+		_, ok = v.(string)
+	case Double:
+		_, ok = v.(float64)
+	case Int64:
+		_, ok = v.(int64)
+	case Timestamp:
+		_, ok = v.(time.Time)
+	}
+	if !ok {
+		return fmt.Errorf("parquet: column %s: unexpected value of type %T", c.Name, v)
+	}
+	return nil
+}
+
+// Flush writes the buffered rows as a row group
+func (w *Writer) Flush() error {
+	if len(w.rows) == 0 {
+		return nil
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	group := rowGroup{numRows: int64(len(w.rows))}
+	for i, c := range w.columns {
+		data := encodeColumn(c, w.rows, i)
+		header := pageHeader(len(data), len(w.rows))
+		chunk := columnChunk{offset: w.w.n, size: int64(len(header) + len(data)), values: int64(len(w.rows))}
+		if _, err := w.w.Write(header); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(data); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+	}
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += group.numRows
+	w.rows = w.rows[:0]
+	return nil
+}
+
+// Close flushes the buffered rows and writes the footer. It does not close
+// the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	footer := w.fileMetaData()
+	if _, err := w.w.Write(footer); err != nil {
+		return err
+	}
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(footer))) //nolint:gosec // G115: footer is far below 4 GiB
+	if _, err := w.w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, magic)
+	return err
+}
+
+// start writes the leading magic bytes once
+func (w *Writer) start() error {
+	if w.w.n > 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.w, magic)
+	return err
+}
+
+// encodeColumn PLAIN encodes column i of rows
+func encodeColumn(c Column, rows [][]any, i int) []byte {
+	var b bytes.Buffer
+	var scratch [8]byte
+	for _, row := range rows {
+		switch c.Type {
+		case String:
+			s := row[i].(string)
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(s))) //nolint:gosec // G115: values are far below 4 GiB
+			b.Write(scratch[:4])
+			b.WriteString(s)
+		case Double:
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(row[i].(float64)))
+			b.Write(scratch[:])
+		case Int64:
+			binary.LittleEndian.PutUint64(scratch[:], uint64(row[i].(int64))) //nolint:gosec // G115: two's complement is the encoding
+			b.Write(scratch[:])
+		case Timestamp:
+			binary.LittleEndian.PutUint64(scratch[:], uint64(row[i].(time.Time).UnixMilli())) //nolint:gosec // G115: two's complement is the encoding
+			b.Write(scratch[:])
+		}
+	}
+	return b.Bytes()
+}
+
+// pageHeader encodes the PageHeader of a data page
+func pageHeader(size, values int) []byte {
+	var e encoder
+	e.i32(1, pageTypeData)
+	e.i32(2, int32(size))   //nolint:gosec // G115: row groups are far below 2 GiB
+	e.i32(3, int32(size))   //nolint:gosec // G115: row groups are far below 2 GiB
+	e.structBegin(5)        // DataPageHeader
+	e.i32(1, int32(values)) //nolint:gosec // G115: row groups are far below 2^31 rows
+	e.i32(2, encodingPlain)
+	e.i32(3, encodingRLE) // Definition levels (none for required columns)
+	e.i32(4, encodingRLE) // Repetition levels (none for flat columns)
+	e.structEnd()
+	e.stop()
+	return e.Bytes()
+}
+
+// fileMetaData encodes the footer's FileMetaData
+func (w *Writer) fileMetaData() []byte {
+	var e encoder
+	e.i32(1, 1) // Format version
+
+	e.listBegin(2, typeStruct, len(w.columns)+1)
+	e.elemBegin()
+	e.binary(4, "schema")
+	e.i32(5, int32(len(w.columns))) //nolint:gosec // G115: a handful of columns
+	e.elemEnd()
+	for _, c := range w.columns {
+		e.elemBegin()
+		e.i32(1, physicalType(c.Type))
+		e.i32(3, repetitionRequired)
+		e.binary(4, c.Name)
+		switch c.Type {
+		case String:
+			e.i32(6, convertedUTF8)
+		case Timestamp:
+			e.i32(6, convertedTimestampMillis)
+		}
+		e.elemEnd()
+	}
+
+	e.i64(3, w.numRows)
+
+	e.listBegin(4, typeStruct, len(w.rowGroups))
+	for _, g := range w.rowGroups {
+		e.elemBegin()
+		e.listBegin(1, typeStruct, len(g.chunks))
+		for i, chunk := range g.chunks {
+			c := w.columns[i]
+			e.elemBegin()
+			e.i64(2, chunk.offset)
+			e.structBegin(3) // ColumnMetaData
+			e.i32(1, physicalType(c.Type))
+			e.listBegin(2, typeI32, 2)
+			e.elemI32(encodingPlain)
+			e.elemI32(encodingRLE)
+			e.listBegin(3, typeBinary, 1)
+			e.elemBinary(c.Name)
+			e.i32(4, codecUncompressed)
+			e.i64(5, chunk.values)
+			e.i64(6, chunk.size)
+			e.i64(7, chunk.size)
+			e.i64(9, chunk.offset)
+			e.structEnd()
+			e.elemEnd()
+		}
+		e.i64(2, g.size)
+		e.i64(3, g.numRows)
+		e.elemEnd()
+	}
+
+	e.binary(6, "stats-agent-team")
+	e.stop()
+	return e.Bytes()
+}
+
+func physicalType(t Type) int32 {
+	switch t {
+	case Double:
+		return physicalDouble
+	case Int64, Timestamp:
+		return physicalInt64
+	default:
+		return physicalByteArray
+	}
+}
+
+// countingWriter tracks the file offset
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,183 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// decoder reads Thrift compact structs into maps from field ID to value:
+// int64 for integers, []byte for binaries, []any for lists, and maps for
+// structs. It covers the types this package writes.
+type decoder struct {
+	b   []byte
+	pos int
+	t   *testing.T
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.b[d.pos:])
+	if n <= 0 {
+		d.t.Fatalf("bad varint at %d", d.pos)
+	}
+	d.pos += n
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v := d.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (d *decoder) value(typ byte) any {
+	switch typ {
+	case typeI32, typeI64:
+		return d.varint()
+	case typeBinary:
+		n := int(d.uvarint())
+		v := d.b[d.pos : d.pos+n]
+		d.pos += n
+		return v
+	case typeList:
+		header := d.b[d.pos]
+		d.pos++
+		n, elem := int(header>>4), header&0x0F
+		if n == 15 {
+			n = int(d.uvarint())
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = d.value(elem)
+		}
+		return list
+	case typeStruct:
+		return d.structure()
+	}
+	d.t.Fatalf("unexpected type %d at %d", typ, d.pos)
+	return nil
+}
+
+func (d *decoder) structure() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		header := d.b[d.pos]
+		d.pos++
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(d.varint())
+		}
+		fields[id] = d.value(header & 0x0F)
+		last = id
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	columns := []Column{
+		{Name: "name", Type: String},
+		{Name: "value", Type: Double},
+		{Name: "sources", Type: Int64},
+		{Name: "seen", Type: Timestamp},
+	}
+	seen := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	rows := [][]any{
+		{"Solar share of US electricity", 3.9, int64(1), seen},
+		{"EV sales, 2023 (million)", 14.0, int64(3), seen.Add(time.Hour)},
+		{"", -0.5, int64(-2), seen.Add(-time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, columns)
+	w.SetRowGroupSize(2)
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Write([]any{"short row"}); err == nil {
+		t.Error("expected an error for a short row")
+	}
+	if err := w.Write([]any{1, 2.0, int64(3), seen}); err == nil {
+		t.Error("expected an error for a value of the wrong type")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file := buf.Bytes()
+	if string(file[:4]) != magic || string(file[len(file)-4:]) != magic {
+		t.Fatal("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	d := &decoder{b: file[len(file)-8-footerLen : len(file)-8], t: t}
+	meta := d.structure()
+	if d.pos != footerLen {
+		t.Fatalf("footer decoded %d of %d bytes", d.pos, footerLen)
+	}
+	if meta[3].(int64) != 3 {
+		t.Errorf("num_rows = %v", meta[3])
+	}
+	schema := meta[2].([]any)
+	if len(schema) != 5 || string(schema[2].(map[int16]any)[4].([]byte)) != "value" {
+		t.Errorf("schema = %v", schema)
+	}
+
+	// Read every column back through its page headers
+	got := make([][]any, len(columns))
+	groups := meta[4].([]any)
+	if len(groups) != 2 {
+		t.Fatalf("row groups = %d; want 2", len(groups))
+	}
+	for _, g := range groups {
+		for i, chunk := range g.(map[int16]any)[1].([]any) {
+			cm := chunk.(map[int16]any)[3].(map[int16]any)
+			page := &decoder{b: file, pos: int(cm[9].(int64)), t: t}
+			header := page.structure()
+			values := int(header[5].(map[int16]any)[1].(int64))
+			data := file[page.pos : page.pos+int(header[2].(int64))]
+			for range values {
+				switch columns[i].Type {
+				case String:
+					n := int(binary.LittleEndian.Uint32(data))
+					got[i] = append(got[i], string(data[4:4+n]))
+					data = data[4+n:]
+				case Double:
+					got[i] = append(got[i], math.Float64frombits(binary.LittleEndian.Uint64(data)))
+					data = data[8:]
+				case Int64:
+					got[i] = append(got[i], int64(binary.LittleEndian.Uint64(data)))
+					data = data[8:]
+				case Timestamp:
+					got[i] = append(got[i], time.UnixMilli(int64(binary.LittleEndian.Uint64(data))).UTC())
+					data = data[8:]
+				}
+			}
+			if len(data) != 0 {
+				t.Errorf("column %s: %d bytes left in page", columns[i].Name, len(data))
+			}
+		}
+	}
+	for r, row := range rows {
+		for c, want := range row {
+			if got[c][r] != want {
+				t.Errorf("row %d column %s = %v; want %v", r, columns[c].Name, got[c][r], want)
+			}
+		}
+	}
+}
+
+func TestEmptyFile(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf, []Column{{Name: "name", Type: String}}).Close(); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	if string(file[:4]) != magic || string(file[len(file)-4:]) != magic {
+		t.Fatalf("empty file = %q", file)
+	}
+}
@@ -0,0 +1,105 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type codes
+const (
+	typeI32    = 5
+	typeI64    = 6
+	typeBinary = 8
+	typeList   = 9
+	typeStruct = 12
+)
+
+// encoder writes Thrift compact protocol structs, the encoding of Parquet
+// page headers and file metadata. Fields must be written in increasing ID
+// order within each struct.
+type encoder struct {
+	bytes.Buffer
+	last  int16   // ID of the last field written in the current struct
+	stack []int16 // last of the enclosing structs
+}
+
+func (e *encoder) field(id int16, typ byte) {
+	if delta := id - e.last; delta > 0 && delta <= 15 {
+		e.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		e.WriteByte(typ)
+		e.varint(int64(id))
+	}
+	e.last = id
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.Write(binary.AppendUvarint(nil, v))
+}
+
+// varint writes a zigzag-encoded integer
+func (e *encoder) varint(v int64) {
+	e.uvarint(uint64(v<<1) ^ uint64(v>>63)) //nolint:gosec // G115: zigzag encoding
+}
+
+func (e *encoder) i32(id int16, v int32) {
+	e.field(id, typeI32)
+	e.varint(int64(v))
+}
+
+func (e *encoder) i64(id int16, v int64) {
+	e.field(id, typeI64)
+	e.varint(v)
+}
+
+func (e *encoder) binary(id int16, s string) {
+	e.field(id, typeBinary)
+	e.elemBinary(s)
+}
+
+// structBegin starts a struct field; structEnd ends it
+func (e *encoder) structBegin(id int16) {
+	e.field(id, typeStruct)
+	e.elemBegin()
+}
+
+func (e *encoder) structEnd() {
+	e.elemEnd()
+}
+
+// stop ends the top-level struct
+func (e *encoder) stop() {
+	e.WriteByte(0)
+}
+
+// listBegin starts a list field of n elements of type elem, which follow as
+// elemI32, elemBinary, or elemBegin and elemEnd around each struct
+func (e *encoder) listBegin(id int16, elem byte, n int) {
+	e.field(id, typeList)
+	if n < 15 {
+		e.WriteByte(byte(n)<<4 | elem)
+		return
+	}
+	e.WriteByte(0xF0 | elem)
+	e.uvarint(uint64(n))
+}
+
+func (e *encoder) elemI32(v int32) {
+	e.varint(int64(v))
+}
+
+func (e *encoder) elemBinary(s string) {
+	e.uvarint(uint64(len(s)))
+	e.WriteString(s)
+}
+
+func (e *encoder) elemBegin() {
+	e.stack = append(e.stack, e.last)
+	e.last = 0
+}
+
+func (e *encoder) elemEnd() {
+	e.WriteByte(0)
+	e.last = e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+}
@@ -0,0 +1,185 @@
+// Package policy decides which sources statistics may come from. Operators
+// write a CEL expression over a source's attributes, such as
+//
+//	tier != "other" && tls && (!has_published || age_days <= 1825)
+//
+// which research applies to search results and verification to the source a
+// statistic is finally verified against. The reputable_only request option
+// is the built-in policy Reputable.
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"cel.dev/cel-go/cel"
+
+	"github.com/plexusone/agent-team-stats/pkg/prioritize"
+)
+
+// Source is what a policy knows about a source
+type Source struct {
+	URL       string
+	Domain    string    // Host without www.
+	Tier      string    // Domain tier: authoritative, research, or other
+	Country   string    // ISO 3166 code of a country-code domain, "US" for .gov, .mil, and .edu; empty for others
+	TLS       bool      // Served over HTTPS
+	Published time.Time // Publication date; zero when unknown
+	Internal  bool      // From the organization's own search, not the web
+	Reputable bool      // On the built-in list of reputable sources
+}
+
+// NewSource derives the attributes of the source at rawURL, published when
+// known
+func NewSource(rawURL string, published time.Time) Source {
+	s := Source{URL: rawURL, Published: published}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return s
+	}
+	s.Domain = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	s.Tier = prioritize.Tier(s.Domain)
+	s.Country = country(s.Domain)
+	s.TLS = u.Scheme == "https"
+	s.Reputable = isReputable(s.Domain)
+	return s
+}
+
+// reputableDomains are the domains reputable_only keeps, matched anywhere in
+// the host
+var reputableDomains = []string{
+	".gov", ".edu", // Government and education
+	"who.int", "un.org", "worldbank.org", // International orgs
+	"pewresearch.org", "gallup.com", // Research organizations
+	"nature.com", "science.org", "nejm.org", // Journals
+}
+
+// isReputable reports whether a domain is on the built-in reputable list
+func isReputable(domain string) bool {
+	domain = strings.ToLower(domain)
+	for _, rep := range reputableDomains {
+		if strings.Contains(domain, rep) {
+			return true
+		}
+	}
+	return false
+}
+
+// countryDomains are top-level domains whose country is not their
+// uppercased name
+var countryDomains = map[string]string{"uk": "GB", "gov": "US", "mil": "US", "edu": "US"}
+
+// country returns the country of a domain's top-level domain, if it has one
+func country(domain string) string {
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+	if c, ok := countryDomains[tld]; ok {
+		return c
+	}
+	if len(tld) == 2 && tld[0] >= 'a' && tld[0] <= 'z' && tld[1] >= 'a' && tld[1] <= 'z' {
+		return strings.ToUpper(tld)
+	}
+	return ""
+}
+
+// Reputable is the policy of reputable_only requests: built-in reputable
+// sources and the organization's own documents
+var Reputable = mustCompile("reputable || internal")
+
+// costLimit bounds the work of one evaluation, so a runaway expression
+// cannot stall a run
+const costLimit = 10000
+
+// env declares the attributes an expression may use
+var env = func() *cel.Env {
+	e, err := cel.NewEnv(
+		cel.Variable("url", cel.StringType),
+		cel.Variable("domain", cel.StringType),
+		cel.Variable("tier", cel.StringType),
+		cel.Variable("country", cel.StringType),
+		cel.Variable("tls", cel.BoolType),
+		cel.Variable("published", cel.TimestampType),
+		cel.Variable("has_published", cel.BoolType),
+		cel.Variable("age_days", cel.IntType),
+		cel.Variable("internal", cel.BoolType),
+		cel.Variable("reputable", cel.BoolType),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}()
+
+// Policy is a compiled source acceptance expression. A nil Policy accepts
+// every source.
+type Policy struct {
+	expr    string
+	program cel.Program
+}
+
+// Compile checks an expression, which must be boolean, and prepares it for
+// evaluation. An empty expression gives a nil Policy.
+func Compile(expr string) (*Policy, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid source policy: %w", issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("invalid source policy: %q is %s, want bool", expr, ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, fmt.Errorf("invalid source policy: %w", err)
+	}
+	return &Policy{expr: expr, program: program}, nil
+}
+
+func mustCompile(expr string) *Policy {
+	p, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Allows reports whether the policy accepts a source. An expression that
+// fails to evaluate rejects the source, with the error.
+func (p *Policy) Allows(s Source) (bool, error) {
+	if p == nil {
+		return true, nil
+	}
+	age := int64(-1)
+	if !s.Published.IsZero() {
+		age = int64(time.Since(s.Published) / (24 * time.Hour))
+	}
+	out, _, err := p.program.Eval(map[string]any{
+		"url":           s.URL,
+		"domain":        s.Domain,
+		"tier":          s.Tier,
+		"country":       s.Country,
+		"tls":           s.TLS,
+		"published":     s.Published,
+		"has_published": !s.Published.IsZero(),
+		"age_days":      age,
+		"internal":      s.Internal,
+		"reputable":     s.Reputable,
+	})
+	if err != nil {
+		return false, fmt.Errorf("source policy failed on %s: %w", s.URL, err)
+	}
+	allowed, _ := out.Value().(bool)
+	return allowed, nil
+}
+
+// String returns the policy's expression
+func (p *Policy) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.expr
+}
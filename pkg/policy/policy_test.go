@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSource(t *testing.T) {
+	published := time.Now().AddDate(0, 0, -10)
+	s := NewSource("https://www.ons.gov.uk/employment", published)
+	if s.Domain != "ons.gov.uk" || s.Tier != "authoritative" || s.Country != "GB" || !s.TLS || !s.Reputable {
+		t.Errorf("NewSource(ons.gov.uk) = %+v", s)
+	}
+	s = NewSource("http://example.com/stats", time.Time{})
+	if s.Domain != "example.com" || s.Tier != "other" || s.Country != "" || s.TLS || s.Reputable {
+		t.Errorf("NewSource(example.com) = %+v", s)
+	}
+	if c := NewSource("https://www.destatis.de/", time.Time{}).Country; c != "DE" {
+		t.Errorf("country of destatis.de = %q, want DE", c)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	recent := time.Now().AddDate(-1, 0, 0)
+	old := time.Now().AddDate(-10, 0, 0)
+	tests := []struct {
+		expr      string
+		url       string
+		published time.Time
+		want      bool
+	}{
+		{`tier != "other" && tls`, "https://www.bls.gov/cps", recent, true},
+		{`tier != "other" && tls`, "http://www.bls.gov/cps", recent, false},
+		{`tier != "other" && tls`, "https://blog.example.com/stats", recent, false},
+		{`has_published && age_days <= 1825`, "https://www.bls.gov/cps", recent, true},
+		{`has_published && age_days <= 1825`, "https://www.bls.gov/cps", old, false},
+		{`has_published && age_days <= 1825`, "https://www.bls.gov/cps", time.Time{}, false},
+		{`country in ["US", "GB"]`, "https://www.ons.gov.uk/", recent, true},
+		{`country in ["US", "GB"]`, "https://www.destatis.de/", recent, false},
+		{`!domain.endsWith("statista.com")`, "https://www.statista.com/statistics/1", recent, false},
+		{`published > timestamp("2020-01-01T00:00:00Z")`, "https://www.bls.gov/cps", recent, true},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.expr)
+		if err != nil {
+			t.Fatalf("Compile(%q) = %v", tt.expr, err)
+		}
+		if got, err := p.Allows(NewSource(tt.url, tt.published)); err != nil || got != tt.want {
+			t.Errorf("%q on %s = %v, %v, want %v", tt.expr, tt.url, got, err, tt.want)
+		}
+	}
+}
+
+func TestReputable(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://www.cdc.gov/nchs":         true,
+		"https://www.who.int/data":         true,
+		"https://www.pewresearch.org/fact": true,
+		"https://blog.example.com/stats":   false,
+	} {
+		if got, _ := Reputable.Allows(NewSource(url, time.Time{})); got != want {
+			t.Errorf("Reputable.Allows(%s) = %v, want %v", url, got, want)
+		}
+	}
+	internal := NewSource("https://intranet.example.com/report", time.Time{})
+	internal.Internal = true
+	if ok, _ := Reputable.Allows(internal); !ok {
+		t.Error("Reputable rejected an internal document")
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if p, err := Compile("  "); p != nil || err != nil {
+		t.Errorf("Compile(empty) = %v, %v, want nil", p, err)
+	}
+	if ok, _ := (*Policy)(nil).Allows(NewSource("http://example.com", time.Time{})); !ok {
+		t.Error("nil Policy rejected a source")
+	}
+	for _, expr := range []string{`tier`, `tier == `, `region == "EU"`} {
+		if _, err := Compile(expr); err == nil || !strings.Contains(err.Error(), "invalid source policy") {
+			t.Errorf("Compile(%q) error = %v", expr, err)
+		}
+	}
+}
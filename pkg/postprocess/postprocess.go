@@ -0,0 +1,201 @@
+// Package postprocess runs a user-supplied transform over a run's final
+// statistics before the orchestrator builds its response: applying house
+// style to units, say, or adding internal IDs. The transform is either a
+// command or a WASI module. Either way it reads a JSON Input on stdin and
+// writes a JSON Output on stdout; a command is run directly, not through a
+// shell.
+package postprocess
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Input is what the transform reads on stdin
+type Input struct {
+	Topic      string             `json:"topic"`
+	Statistics []models.Statistic `json:"statistics"`
+}
+
+// Output is what the transform writes on stdout. Its statistics replace the
+// run's, so a transform may also drop or reorder them.
+type Output struct {
+	Statistics *[]models.Statistic `json:"statistics"`
+}
+
+// Limits on a transform
+const (
+	DefaultTimeout = 30 * time.Second
+	maxOutput      = 32 << 20 // Bytes read of stdout
+	maxStderr      = 1 << 10  // Bytes of stderr quoted in errors
+)
+
+// Hook runs the configured transform. A nil Hook leaves statistics as they
+// are.
+type Hook struct {
+	name    string // Command or module, for logs and errors
+	timeout time.Duration
+	run     func(ctx context.Context, stdin []byte, stdout, stderr io.Writer) error
+
+	runtime wazero.Runtime // Set for a WASI module
+}
+
+// New returns the hook of POSTPROCESS_COMMAND or POSTPROCESS_WASM, or nil
+// when neither is set
+func New(ctx context.Context, cfg *config.Config) (*Hook, error) {
+	timeout := time.Duration(cfg.PostprocessTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	switch {
+	case cfg.PostprocessCommand != "" && cfg.PostprocessWASM != "":
+		return nil, fmt.Errorf("set POSTPROCESS_COMMAND or POSTPROCESS_WASM, not both")
+	case cfg.PostprocessCommand != "":
+		return NewCommand(strings.Fields(cfg.PostprocessCommand), timeout)
+	case cfg.PostprocessWASM != "":
+		return NewWASM(ctx, cfg.PostprocessWASM, timeout)
+	}
+	return nil, nil
+}
+
+// NewCommand returns a hook running a command, its arguments after it
+func NewCommand(args []string, timeout time.Duration) (*Hook, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty post-processing command")
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("post-processing command: %w", err)
+	}
+	return &Hook{
+		name:    args[0],
+		timeout: timeout,
+		run: func(ctx context.Context, stdin []byte, stdout, stderr io.Writer) error {
+			cmd := exec.CommandContext(ctx, path, args[1:]...) //nolint:gosec // G204: the operator configures the command
+			cmd.Stdin = bytes.NewReader(stdin)
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+			cmd.WaitDelay = time.Second
+			return cmd.Run()
+		},
+	}, nil
+}
+
+// NewWASM returns a hook running a WASI module's main function, compiled
+// once. The module sees no files, network, or environment.
+func NewWASM(ctx context.Context, path string, timeout time.Duration) (*Hook, error) {
+	wasm, err := os.ReadFile(path) //nolint:gosec // G304: the operator configures the module
+	if err != nil {
+		return nil, fmt.Errorf("failed to read post-processing module: %w", err)
+	}
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+	compiled, err := runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		runtime.Close(ctx) //nolint:errcheck
+		return nil, fmt.Errorf("failed to compile post-processing module: %w", err)
+	}
+	return &Hook{
+		name:    path,
+		timeout: timeout,
+		runtime: runtime,
+		run: func(ctx context.Context, stdin []byte, stdout, stderr io.Writer) error {
+			config := wazero.NewModuleConfig().
+				WithName(""). // Anonymous, so runs may overlap
+				WithArgs("postprocess").
+				WithStdin(bytes.NewReader(stdin)).
+				WithStdout(stdout).
+				WithStderr(stderr)
+			mod, err := runtime.InstantiateModule(ctx, compiled, config)
+			var exit *sys.ExitError
+			if errors.As(err, &exit) && exit.ExitCode() == 0 {
+				err = nil
+			}
+			if mod != nil {
+				mod.Close(ctx) //nolint:errcheck
+			}
+			return err
+		},
+	}, nil
+}
+
+// Apply runs the transform over statistics and returns the statistics it
+// writes. A transform that fails, times out, or writes anything but an
+// Output with statistics fails the run, rather than letting statistics
+// through untransformed.
+func (h *Hook) Apply(ctx context.Context, topic string, stats []models.Statistic) ([]models.Statistic, error) {
+	if h == nil {
+		return stats, nil
+	}
+	stdin, err := json.Marshal(Input{Topic: topic, Statistics: stats})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statistics for post-processing: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	stdout := &limitedBuffer{max: maxOutput}
+	stderr := &limitedBuffer{max: maxStderr}
+	if err := h.run(ctx, stdin, stdout, stderr); err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s", h.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("post-processing with %s failed: %w", h.name, err)
+	}
+	if stdout.truncated {
+		return nil, fmt.Errorf("post-processing with %s wrote more than %d MB", h.name, maxOutput>>20)
+	}
+
+	var out Output
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("post-processing with %s wrote invalid output: %w", h.name, err)
+	}
+	if out.Statistics == nil {
+		return nil, fmt.Errorf("post-processing with %s wrote no statistics", h.name)
+	}
+	return *out.Statistics, nil
+}
+
+// Close releases a WASI module's runtime
+func (h *Hook) Close(ctx context.Context) error {
+	if h == nil || h.runtime == nil {
+		return nil
+	}
+	return h.runtime.Close(ctx)
+}
+
+// limitedBuffer keeps the first max bytes written to it, noting whether
+// more were written
+type limitedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.max - b.Len(); len(p) > room {
+		p = p[:max(room, 0)]
+		b.truncated = true
+	}
+	b.Buffer.Write(p)
+	return n, nil
+}
@@ -0,0 +1,168 @@
+package postprocess
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// transform is the program run by the hooks under test: it renames units
+// to house style, tags each statistic, and drops those without a value
+const transform = `package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func main() {
+	var in struct {
+		Topic      string           ` + "`json:\"topic\"`" + `
+		Statistics []map[string]any ` + "`json:\"statistics\"`" + `
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
+		os.Stderr.WriteString(err.Error())
+		os.Exit(1)
+	}
+	out := []map[string]any{}
+	for _, s := range in.Statistics {
+		if s["value"] == float64(0) {
+			continue
+		}
+		if s["unit"] == "percent" {
+			s["unit"] = "%"
+		}
+		s["name"] = in.Topic + ": " + s["name"].(string)
+		out = append(out, s)
+	}
+	json.NewEncoder(os.Stdout).Encode(map[string]any{"statistics": out})
+}
+`
+
+// build compiles the transform for the host or, with goos wasip1, as a
+// WASI module
+func build(t *testing.T, goos string) string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil || testing.Short() {
+		t.Skip("builds a Go program")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(transform), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "transform")
+	cmd := exec.Command("go", "build", "-o", out, "main.go")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=off", "CGO_ENABLED=0")
+	if goos != "" {
+		cmd.Env = append(cmd.Env, "GOOS="+goos, "GOARCH=wasm")
+	}
+	if msg, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build transform: %v: %s", err, msg)
+	}
+	return out
+}
+
+func stats() []models.Statistic {
+	return []models.Statistic{
+		{Name: "Unemployment rate", Value: 4.1, Unit: "percent"},
+		{Name: "Placeholder", Value: 0},
+	}
+}
+
+func checkApplied(t *testing.T, hook *Hook) {
+	t.Helper()
+	got, err := hook.Apply(context.Background(), "US", stats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Unit != "%" || got[0].Name != "US: Unemployment rate" || got[0].Value != 4.1 {
+		t.Errorf("Apply() = %+v", got)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	hook, err := NewCommand([]string{build(t, "")}, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkApplied(t, hook)
+}
+
+func TestWASM(t *testing.T) {
+	ctx := context.Background()
+	hook, err := NewWASM(ctx, build(t, "wasip1"), 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hook.Close(ctx)
+	checkApplied(t, hook)
+}
+
+func TestApplyFailures(t *testing.T) {
+	ctx := context.Background()
+	if got, err := (*Hook)(nil).Apply(ctx, "US", stats()); err != nil || len(got) != 2 {
+		t.Errorf("nil Hook Apply() = %v, %v, want statistics unchanged", got, err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		stdout string
+		fail   bool
+		want   string
+	}{
+		{"exit", "", true, "failed: exit status 1: bad input"},
+		{"invalid", "not json", false, "invalid output"},
+		{"missing", `{"other": []}`, false, "wrote no statistics"},
+	} {
+		hook := &Hook{name: tt.name, timeout: time.Second, run: func(ctx context.Context, stdin []byte, stdout, stderr io.Writer) error {
+			var in Input
+			if err := json.Unmarshal(stdin, &in); err != nil || len(in.Statistics) != 2 {
+				t.Errorf("stdin = %s, %v", stdin, err)
+			}
+			stdout.Write([]byte(tt.stdout)) //nolint:errcheck
+			if tt.fail {
+				stderr.Write([]byte("bad input\n")) //nolint:errcheck
+				return errors.New("exit status 1")
+			}
+			return nil
+		}}
+		if _, err := hook.Apply(ctx, "US", stats()); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Apply(%s) error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	timeout := &Hook{name: "slow", timeout: 10 * time.Millisecond, run: func(ctx context.Context, _ []byte, _, _ io.Writer) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	if _, err := timeout.Apply(ctx, "US", stats()); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Apply(slow) error = %v, want a timeout", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	if hook, err := New(ctx, &config.Config{}); hook != nil || err != nil {
+		t.Errorf("New(unset) = %v, %v, want nil", hook, err)
+	}
+	if _, err := New(ctx, &config.Config{PostprocessCommand: "cat", PostprocessWASM: "x.wasm"}); err == nil {
+		t.Error("New() accepted both a command and a module")
+	}
+	if _, err := New(ctx, &config.Config{PostprocessCommand: "no-such-transform-command"}); err == nil {
+		t.Error("New() accepted a missing command")
+	}
+	if _, err := New(ctx, &config.Config{PostprocessWASM: filepath.Join(t.TempDir(), "missing.wasm")}); err == nil {
+		t.Error("New() accepted a missing module")
+	}
+}
@@ -0,0 +1,313 @@
+// Package primary traces statistics that a page quotes from another
+// organization ("according to the WHO ...") to the primary source. Find
+// reads the attribution and the link the quoting page gives for it; Locate
+// finds the passage of the primary source that states the same value, so the
+// statistic can be verified against the organization that published it.
+package primary
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/pagemeta"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+)
+
+// minOverlap is the share of a passage's words that must also describe the
+// quoted statistic for Locate to accept it
+const minOverlap = 0.5
+
+// Citation is the organization a page credits for a figure, and the link it
+// gives to it
+type Citation struct {
+	Organization string
+	URL          string // Empty when the page does not link the source
+}
+
+// org matches a capitalized organization name such as "World Health
+// Organization", "U.S. Census Bureau", or "WHO"
+const org = `((?:[A-Z][\w&.'-]*)(?:\s+(?:(?:of|for|and|on|the|&)\s+)*[A-Z][\w&.'-]*)*)`
+
+// attributions are the phrasings of a secondary citation
+var attributions = []*regexp.Regexp{
+	// "according to the WHO", "according to a 2023 report by the IEA"
+	regexp.MustCompile(`(?i:according to)\s+(?:(?i:an?|the)\s+)?(?:\d{4}\s+)?(?:(?i:new|recent|latest|annual)\s+)?(?:(?i:reports?|survey|study|data|analysis|estimates?|figures|statistics|research)\s+(?i:by|from|of)\s+)?(?:(?i:the)\s+)?` + org),
+	// "data from the Bureau of Labor Statistics", "a study by Gartner"
+	regexp.MustCompile(`(?i:data|figures|estimates|reports?|survey|study|research|analysis|statistics)\s+(?:(?i:published|released|compiled|conducted)\s+)?(?i:from|by)\s+(?:(?i:the)\s+)?` + org),
+	// "Source: Eurostat"
+	regexp.MustCompile(`(?i:sources?):\s*(?:(?i:the)\s+)?` + org),
+	// "The IEA reported", "Gartner estimates"
+	regexp.MustCompile(`(?:(?i:the)\s+)?` + org + `(?:'s?)?\s+(?:(?i:reported|reports|found|finds|estimated|estimates|projected|projects|said|says|noted|notes|calculated|calculates))\b`),
+}
+
+// notOrganizations are capitalized words that open sentences rather than name
+// an organization
+var notOrganizations = map[string]bool{
+	"a": true, "an": true, "the": true, "it": true, "this": true, "that": true, "these": true, "those": true,
+	"they": true, "we": true, "he": true, "she": true, "researchers": true, "experts": true, "analysts": true,
+	"officials": true, "scientists": true, "authors": true, "report": true, "survey": true, "study": true,
+	"data": true, "figures": true, "estimates": true, "analysis": true, "research": true, "one": true,
+}
+
+// blocks are the elements whose text is read as one passage
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Li: true, atom.Td: true, atom.Th: true, atom.Blockquote: true,
+	atom.Figcaption: true, atom.Caption: true, atom.Dd: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+}
+
+// Find returns the organization a page credits for the statistic quoted by
+// excerpt, or nil when the passage containing the excerpt cites no one else.
+// A page attributing a figure to its own publisher is not a citation.
+func Find(body []byte, pageURL, excerpt string) *Citation {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	block := passage(doc, excerpt)
+	if block == nil {
+		return nil
+	}
+
+	name := attribution(extract.NodeText(block))
+	publisher := pagemeta.Parse(body).Publisher
+	if name == "" || sameOrganization(name, base.Hostname()) || (publisher != "" && strings.EqualFold(name, publisher)) {
+		return nil
+	}
+	return &Citation{Organization: name, URL: link(block, base, name)}
+}
+
+// passage returns the innermost block element whose text contains excerpt
+func passage(doc *html.Node, excerpt string) *html.Node {
+	var found *html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && blocks[n.DataAtom] {
+			if ok, _ := textmatch.Contains(extract.NodeText(n), excerpt, textmatch.DefaultThreshold); ok {
+				found = n
+			} else {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return found
+}
+
+// attribution returns the organization a passage credits, or ""
+func attribution(text string) string {
+	text = textmatch.Fold(text)
+	for _, re := range attributions {
+		for _, m := range re.FindAllStringSubmatch(text, -1) {
+			name := trimName(m[1])
+			if name != "" && !notOrganizations[strings.ToLower(name)] {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// trimName cuts a matched name at the end of its sentence ("WHO. In 2023")
+// or at a possessive ("IEA's Global EV Outlook"), keeping abbreviations such
+// as "U.S." whole
+func trimName(name string) string {
+	fields := strings.Fields(name)
+	for i, f := range fields {
+		if j := strings.Index(f, "'s"); j > 0 {
+			fields = fields[:i+1]
+			fields[i] = f[:j]
+			break
+		}
+		if stem := strings.TrimSuffix(f, "."); stem != f && !strings.Contains(stem, ".") && len(stem) > 1 {
+			fields = fields[:i+1]
+			fields[i] = stem
+			break
+		}
+	}
+	return strings.TrimRight(strings.Join(fields, " "), ".,;:'")
+}
+
+// link returns the URL of the passage's link to the cited organization:
+// preferably one whose text or host names it, otherwise the first link to
+// another site
+func link(block *html.Node, base *url.URL, name string) string {
+	var first, named string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			for _, a := range n.Attr {
+				if a.Key != "href" {
+					continue
+				}
+				u, err := base.Parse(strings.TrimSpace(a.Val))
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || sameSite(u.Hostname(), base.Hostname()) {
+					continue
+				}
+				u.Fragment = ""
+				if first == "" {
+					first = u.String()
+				}
+				text := extract.NodeText(n)
+				if named == "" && (sameOrganization(name, u.Hostname()) || strings.Contains(strings.ToLower(text), strings.ToLower(name))) {
+					named = u.String()
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(block)
+	if named != "" {
+		return named
+	}
+	return first
+}
+
+// sameSite reports whether two hosts differ only by a www. prefix
+func sameSite(a, b string) bool {
+	return strings.TrimPrefix(strings.ToLower(a), "www.") == strings.TrimPrefix(strings.ToLower(b), "www.")
+}
+
+// minorWords are left out of an organization's acronym
+var minorWords = map[string]bool{"of": true, "for": true, "and": true, "on": true, "the": true, "&": true}
+
+// sameOrganization reports whether host looks like the site of the named
+// organization: a label of the host is its acronym ("who.int" for the World
+// Health Organization) or starts with its leading word, unless that word is
+// generic ("census.gov" for the U.S. Census Bureau, "pewresearch.org" for the
+// Pew Research Center)
+func sameOrganization(name, host string) bool {
+	var acronym strings.Builder
+	leading, decided := "", false
+	for _, w := range strings.Fields(name) {
+		if minorWords[strings.ToLower(w)] {
+			continue
+		}
+		w = strings.ToLower(strings.Trim(w, ".,'&-"))
+		if w == "" {
+			continue
+		}
+		acronym.WriteByte(w[0])
+		// Abbreviations such as "U.S." are skipped
+		if w = strings.ReplaceAll(w, ".", ""); !decided && len(w) >= 3 {
+			if !genericWords[w] {
+				leading = w
+			}
+			decided = true
+		}
+	}
+	for _, label := range strings.Split(strings.TrimPrefix(strings.ToLower(host), "www."), ".") {
+		if len(label) < 2 {
+			continue
+		}
+		if label == acronym.String() || (leading != "" && strings.HasPrefix(label, leading)) {
+			return true
+		}
+	}
+	return false
+}
+
+// genericWords appear in many organizations' names and say nothing about
+// which site is theirs
+var genericWords = map[string]bool{
+	"bureau": true, "center": true, "centre": true, "institute": true, "organization": true,
+	"organisation": true, "department": true, "agency": true, "office": true, "national": true,
+	"international": true, "research": true, "association": true, "council": true, "foundation": true,
+	"university": true, "group": true, "world": true, "global": true, "statistics": true,
+	"administration": true, "commission": true, "society": true, "fund": true,
+}
+
+// sentenceEnd splits text into sentences after a full stop, question or
+// exclamation mark followed by a space and a capital letter or digit
+var sentenceEnd = regexp.MustCompile(`[.!?]["')\]]?\s+(?:[A-Z0-9"'(])`)
+
+// words matches the words of a passage
+var words = regexp.MustCompile(`[\p{L}\p{N}%]+`)
+
+// stopWords carry no meaning when comparing passages
+var stopWords = map[string]bool{
+	"the": true, "a": true, "an": true, "of": true, "in": true, "on": true, "for": true, "to": true,
+	"and": true, "or": true, "is": true, "are": true, "was": true, "were": true, "by": true, "with": true,
+	"that": true, "this": true, "as": true, "at": true, "from": true, "about": true, "according": true,
+	"its": true, "it": true, "be": true, "has": true, "have": true, "had": true, "which": true,
+}
+
+// Locate returns the passage of a primary source's visible text that states
+// the candidate's value: the candidate's own excerpt when the source has it,
+// otherwise the sentence mentioning the value that shares the most words with
+// the candidate's name and excerpt, when at least half its words do.
+func Locate(text string, candidate models.CandidateStatistic, threshold float64) (string, bool) {
+	if ok, _ := textmatch.Contains(text, candidate.Excerpt, threshold); ok {
+		return candidate.Excerpt, true
+	}
+
+	want := wordSet(candidate.Name + " " + candidate.Excerpt)
+	best, bestScore := "", 0.0
+	for _, sentence := range sentences(textmatch.Fold(text)) {
+		if !extract.ValueAppears(sentence, float64(candidate.Value)) {
+			continue
+		}
+		have := wordSet(sentence)
+		shared := 0
+		for w := range have {
+			if want[w] {
+				shared++
+			}
+		}
+		if len(have) == 0 {
+			continue
+		}
+		score := float64(shared) / float64(min(len(have), len(want)))
+		if score > bestScore {
+			best, bestScore = sentence, score
+		}
+	}
+	if bestScore < minOverlap {
+		return "", false
+	}
+	return best, true
+}
+
+// sentences splits text into sentences
+func sentences(text string) []string {
+	var out []string
+	start := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		end := loc[1] - 1 // Keep the next sentence's first character
+		if s := strings.TrimSpace(text[start:end]); s != "" {
+			out = append(out, s)
+		}
+		start = end
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// wordSet returns the lowercased content words of text
+func wordSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range words.FindAllString(strings.ToLower(text), -1) {
+		if !stopWords[w] {
+			set[w] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,110 @@
+package primary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name    string
+		pageURL string
+		html    string
+		excerpt string
+		want    *Citation
+	}{
+		{
+			name:    "linked organization",
+			pageURL: "https://news.example.com/ev-boom",
+			html: `<article><p>Sales kept climbing.</p><p>Almost 14 million new electric cars were registered globally in 2023,
+				according to the <a href="https://www.iea.org/reports/global-ev-outlook-2024#sales">International Energy Agency</a>.</p></article>`,
+			excerpt: "Almost 14 million new electric cars were registered globally in 2023",
+			want:    &Citation{Organization: "International Energy Agency", URL: "https://www.iea.org/reports/global-ev-outlook-2024"},
+		},
+		{
+			name:    "link naming the organization preferred",
+			pageURL: "https://blog.example.org/health",
+			html: `<p>See <a href="https://other.example.net/">our sponsor</a>. The WHO estimates that 1.3 billion adults are physically inactive
+				(<a href="https://www.who.int/news-room/fact-sheets/detail/physical-activity">fact sheet</a>).</p>`,
+			excerpt: "1.3 billion adults are physically inactive",
+			want:    &Citation{Organization: "WHO", URL: "https://www.who.int/news-room/fact-sheets/detail/physical-activity"},
+		},
+		{
+			name:    "unlinked",
+			pageURL: "https://news.example.com/inactivity",
+			html:    `<p>About 31% of adults do not get enough exercise, according to the WHO. In 2010 the share was 26%.</p>`,
+			excerpt: "About 31% of adults do not get enough exercise",
+			want:    &Citation{Organization: "WHO"},
+		},
+		{
+			name:    "abbreviation kept whole",
+			pageURL: "https://news.example.com/population",
+			html:    `<p>Data from the U.S. Census Bureau show the population reached 334.9 million.</p>`,
+			excerpt: "the population reached 334.9 million",
+			want:    &Citation{Organization: "U.S. Census Bureau"},
+		},
+		{
+			name:    "publisher citing itself",
+			pageURL: "https://www.pewresearch.org/short-reads/2024/teens",
+			html:    `<p>Pew Research Center found that 46% of U.S. teens say they are online almost constantly.</p>`,
+			excerpt: "46% of U.S. teens say they are online almost constantly",
+		},
+		{
+			name:    "no attribution",
+			pageURL: "https://news.example.com/ev-boom",
+			html:    `<p>Almost 14 million new electric cars were registered in 2023. <a href="https://www.iea.org/">IEA</a></p>`,
+			excerpt: "Almost 14 million new electric cars were registered in 2023",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Find([]byte(tt.html), tt.pageURL, tt.excerpt)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("Find = %+v; want nil", got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("Find = %+v; want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	candidate := models.CandidateStatistic{
+		Name:    "Global electric car registrations in 2023",
+		Value:   14,
+		Unit:    "million",
+		Excerpt: "Almost 14 million new electric cars were registered globally in 2023, according to the International Energy Agency.",
+	}
+	text := "Global EV Outlook 2024. Electric car sales neared 14 million in 2023, 95% of which were in China, Europe and the United States. " +
+		"Almost 14 million new electric cars were registered globally in 2023, bringing their total number on the roads to 40 million. " +
+		"The report has 14 chapters."
+
+	excerpt, ok := Locate(text, candidate, 0.9)
+	if !ok || excerpt != "Almost 14 million new electric cars were registered globally in 2023, bringing their total number on the roads to 40 million." {
+		t.Errorf("Locate = %q, %v", excerpt, ok)
+	}
+
+	// The source quoted word for word
+	candidate.Excerpt = "Electric car sales neared 14 million in 2023"
+	if excerpt, ok := Locate(text, candidate, 0.9); !ok || excerpt != candidate.Excerpt {
+		t.Errorf("Locate verbatim = %q, %v", excerpt, ok)
+	}
+
+	// A value stored in full is located where the source uses a magnitude word
+	candidate.Value = 14000000
+	candidate.Unit = "cars"
+	candidate.Excerpt = "Almost 14,000,000 new electric cars were registered globally in 2023."
+	if excerpt, ok := Locate(text, candidate, 0.9); !ok || !strings.HasPrefix(excerpt, "Almost 14 million new electric cars") {
+		t.Errorf("Locate scaled = %q, %v", excerpt, ok)
+	}
+
+	// A different value is not located
+	candidate.Value = 15
+	candidate.Excerpt = "Almost 15 million new electric cars were registered globally in 2023."
+	if excerpt, ok := Locate(text, candidate, 0.9); ok {
+		t.Errorf("Locate found %q for a value the source does not state", excerpt)
+	}
+}
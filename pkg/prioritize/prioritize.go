@@ -0,0 +1,154 @@
+// Package prioritize orders search results by expected statistics yield so
+// that a synthesis run with a small budget fetches the most promising pages
+// first. The score combines the source's domain tier, how densely the title
+// and snippet mention numbers and percentages, and how recent the years they
+// mention are.
+package prioritize
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Weights of the score components
+const (
+	tierWeight    = 1.0
+	densityWeight = 2.0
+	recencyWeight = 1.0
+)
+
+// recencyYears is how many years back a mentioned year still earns credit
+const recencyYears = 10
+
+// authoritativeSuffixes are host suffixes of government, academic, and
+// intergovernmental sources
+var authoritativeSuffixes = []string{".gov", ".edu", ".int", ".mil", ".gov.uk", ".ac.uk", ".europa.eu"}
+
+// researchDomains are statistics publishers and journals outside those
+// suffixes
+var researchDomains = []string{
+	"who.int", "un.org", "worldbank.org", "oecd.org", "imf.org",
+	"pewresearch.org", "gallup.com", "statista.com", "ourworldindata.org",
+	"nature.com", "science.org", "nejm.org", "thelancet.com",
+}
+
+// dataExtensions are file types parsed structurally, which yield many
+// candidates per fetch
+var dataExtensions = map[string]bool{".csv": true, ".json": true, ".xlsx": true, ".xls": true}
+
+var (
+	numberPattern = regexp.MustCompile(`\d[\d,.]*\s*(%|percent\b)?`)
+	yearPattern   = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+)
+
+// Score returns the expected yield of a search result; higher is better
+func Score(r models.SearchResult, now time.Time) float64 {
+	text := r.Title + " " + r.Snippet
+	return tierWeight*domainTier(r) +
+		densityWeight*numberDensity(text) +
+		recencyWeight*recency(text+" "+r.URL, now)
+}
+
+// Sort orders results by descending Score. Ties keep their search order.
+func Sort(results []models.SearchResult, now time.Time) {
+	scores := make(map[string]float64, len(results))
+	for _, r := range results {
+		scores[r.URL] = Score(r, now)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return scores[results[i].URL] > scores[results[j].URL]
+	})
+}
+
+// Domain tiers, from most to least authoritative
+const (
+	TierAuthoritative = "authoritative" // Government, academic, and intergovernmental hosts
+	TierResearch      = "research"      // Known statistics publishers and journals
+	TierOther         = "other"
+)
+
+// Tiers lists the domain tiers, from most to least authoritative
+var Tiers = []string{TierAuthoritative, TierResearch, TierOther}
+
+// Tier returns the domain tier of a host
+func Tier(host string) string {
+	host = strings.ToLower(host)
+	for _, suffix := range authoritativeSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return TierAuthoritative
+		}
+	}
+	for _, domain := range researchDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return TierResearch
+		}
+	}
+	return TierOther
+}
+
+// domainTier rates the source: 2 for government, academic, and
+// intergovernmental hosts, 1.5 for known research publishers, plus 1 for
+// data files
+func domainTier(r models.SearchResult) float64 {
+	host := r.Domain
+	u, err := url.Parse(r.URL)
+	if err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	tier := 0.0
+	switch Tier(host) {
+	case TierAuthoritative:
+		tier = 2
+	case TierResearch:
+		tier = 1.5
+	}
+	if err == nil && dataExtensions[strings.ToLower(path.Ext(u.Path))] {
+		tier++
+	}
+	return tier
+}
+
+// numberDensity returns the share of words in text that are numbers, with
+// percentages counting double, capped at 1. Years are not counted.
+func numberDensity(text string) float64 {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return 0
+	}
+	count := 0.0
+	for _, m := range numberPattern.FindAllStringSubmatch(text, -1) {
+		if tok := strings.TrimSpace(m[0]); m[1] == "" && len(tok) == 4 && yearPattern.MatchString(tok) {
+			continue
+		}
+		count++
+		if m[1] != "" {
+			count++
+		}
+	}
+	return min(count/float64(words)*4, 1)
+}
+
+// recency returns 1 for text mentioning the current year, falling linearly
+// to 0 at recencyYears ago; text without a plausible year scores 0
+func recency(text string, now time.Time) float64 {
+	latest := 0
+	for _, m := range yearPattern.FindAllString(text, -1) {
+		y, err := strconv.Atoi(m)
+		if err == nil && y <= now.Year()+1 && y > latest {
+			latest = y
+		}
+	}
+	if latest == 0 {
+		return 0
+	}
+	age := float64(now.Year() - latest)
+	return max(0, min(1, 1-age/recencyYears))
+}
@@ -0,0 +1,56 @@
+package prioritize
+
+import (
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+var now = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func TestSort(t *testing.T) {
+	results := []models.SearchResult{
+		{URL: "https://blog.example.com/remote-work-thoughts", Title: "Thoughts on remote work", Snippet: "Why I like working from home"},
+		{URL: "https://www.bls.gov/news/remote.htm", Title: "Telework in 2025", Snippet: "22.9% of workers teleworked, up from 19.5% in 2023"},
+		{URL: "https://news.example.com/remote", Title: "Remote work survey 2025", Snippet: "28% of employees are fully remote"},
+		{URL: "https://blog.example.com/other", Title: "More thoughts", Snippet: "Nothing numeric here"},
+	}
+	Sort(results, now)
+
+	want := []string{
+		"https://www.bls.gov/news/remote.htm",
+		"https://news.example.com/remote",
+		"https://blog.example.com/remote-work-thoughts", // ties keep search order
+		"https://blog.example.com/other",
+	}
+	for i, r := range results {
+		if r.URL != want[i] {
+			t.Errorf("results[%d] = %s, want %s", i, r.URL, want[i])
+		}
+	}
+}
+
+func TestComponents(t *testing.T) {
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"gov host", domainTier(models.SearchResult{URL: "https://data.census.gov/table"}), 2},
+		{"research publisher", domainTier(models.SearchResult{URL: "https://www.pewresearch.org/short-reads/"}), 1.5},
+		{"data file", domainTier(models.SearchResult{URL: "https://example.com/data/export.CSV"}), 1},
+		{"domain only", domainTier(models.SearchResult{Domain: "ourworldindata.org"}), 1.5},
+		{"years are not numbers", numberDensity("in 2024 and 2025"), 0},
+		{"percentages count double", numberDensity("rose 5% to 10 million"), 1},
+		{"current year", recency("report 2019 and 2026", now), 1},
+		{"five years ago", recency("figures from 2021", now), 0.5},
+		{"future years ignored", recency("projected for 2040", now), 0},
+		{"old", recency("census 1990", now), 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
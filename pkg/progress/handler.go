@@ -0,0 +1,225 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// heartbeatInterval is how often an idle stream sends a comment, so proxies
+// do not close it
+const heartbeatInterval = 15 * time.Second
+
+// Handler returns the handler of GET /runs, the active runs as JSON, and
+// GET /runs/events, a server-sent event stream of their progress. The
+// stream starts with a started event for each active run, followed by a
+// ready event. Tenants only see their own runs; admins see every run.
+func (h *Hub) Handler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if h == nil {
+			http.Error(w, "run progress is not available", http.StatusNotFound)
+			return
+		}
+		switch r.URL.Path {
+		case "/runs", "/runs/":
+			h.serveRuns(w, r, logger)
+		case "/runs/events":
+			h.serveEvents(w, r, logger)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func (h *Hub) serveRuns(w http.ResponseWriter, r *http.Request, logger *slog.Logger) {
+	runs := []Run{}
+	for _, run := range h.Runs() {
+		if visible(r, run) {
+			runs = append(runs, run)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(runs); err != nil {
+		logger.Error("failed to encode runs", "error", err)
+	}
+}
+
+func (h *Hub) serveEvents(w http.ResponseWriter, r *http.Request, logger *slog.Logger) {
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logger.Debug("failed to clear write deadline", "error", err)
+	}
+
+	// Subscribe before taking the snapshot so no change falls between them
+	events, unsubscribe := h.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(ev Event) error {
+		if !visible(r, ev.Run) {
+			return nil
+		}
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	for _, run := range h.Runs() {
+		if err := send(Event{Type: EventStarted, Run: run}); err != nil {
+			return
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: {\"type\":%q}\n\n", EventReady, EventReady); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-events:
+			if err := send(ev); err != nil {
+				logger.Debug("run event stream closed", "error", err)
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// WantsEvents reports whether a request asks for a server-sent event
+// stream, which ServeRun answers
+func WantsEvents(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// ServeRun answers a request with a server-sent event stream of the run fn
+// starts: its started, progress, and finished events, as GET /runs/events
+// sends them, so each statistic arrives in the progress event that
+// verified it, and then a result event with what fn returned, or an error
+// event. fn runs under the request's context and ends if the client goes
+// away.
+func (h *Hub) ServeRun(w http.ResponseWriter, r *http.Request, fn func(context.Context) (any, error), logger *slog.Logger) {
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logger.Debug("failed to clear write deadline", "error", err)
+	}
+
+	// Subscribe before starting, and learn the run's ID before its first
+	// event, so none of the run's events are missed
+	var events <-chan Event
+	if h != nil {
+		var unsubscribe func()
+		events, unsubscribe = h.Subscribe()
+		defer unsubscribe()
+	}
+	ids := make(chan string, 1)
+	ctx := OnBegin(r.Context(), func(id string) { ids <- id })
+	type outcome struct {
+		result any
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := fn(ctx)
+		done <- outcome{result, err}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(event string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	var id string
+	send := func(ev Event) error {
+		if id == "" {
+			select {
+			case id = <-ids:
+			default:
+			}
+		}
+		if id == "" || ev.Run.ID != id {
+			return nil
+		}
+		return write(ev.Type, ev)
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case ev := <-events:
+			err = send(ev)
+		case out := <-done:
+			// The run's last events may still be waiting
+			for err == nil && len(events) > 0 {
+				err = send(<-events)
+			}
+			switch {
+			case err != nil:
+			case out.err != nil:
+				err = write("error", map[string]string{"error": out.err.Error()})
+			default:
+				err = write("result", out.result)
+			}
+			if err != nil {
+				logger.Debug("run stream closed", "error", err)
+			}
+			return
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(w, ": keep-alive\n\n"); err == nil {
+				err = rc.Flush()
+			}
+		}
+		if err != nil {
+			logger.Debug("run stream closed", "error", err)
+			return
+		}
+	}
+}
+
+// visible reports whether the caller may see a run
+func visible(r *http.Request, run Run) bool {
+	return run.VisibleTo(r.Context())
+}
@@ -0,0 +1,381 @@
+// Package progress publishes the live progress of orchestration runs: the
+// stage each active run is in, its candidate and verification counts, and
+// the statistics it has verified so far, withdrawing any its result will
+// not hold. Orchestrators report to a Hub
+// through the Reporter in a run's context, and the hub streams every change
+// to subscribers as server-sent events for `stats-agent watch`.
+package progress
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+// Stages reported in Run.Stage
+const (
+	StageResearch     = "research"
+	StageSynthesis    = "synthesis"
+	StageVerification = "verification"
+	StageDone         = "done"
+)
+
+// Event types
+const (
+	EventStarted   = "started"
+	EventProgress  = "progress"
+	EventFinished  = "finished"
+	EventRetracted = "retracted" // Statistics reported earlier that the run's result will not hold
+	EventReady     = "ready"     // Sent once a new stream has listed the active runs; carries no run
+)
+
+// recentLimit is how many of its latest verified statistics a run keeps
+const recentLimit = 5
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before events are dropped for it. Every event carries the run's full
+// state, so a later event makes up for a dropped one, though the
+// statistics of a dropped event only survive in Run.Recent.
+const subscriberBuffer = 64
+
+// Run is the state of one orchestration run
+type Run struct {
+	ID         string             `json:"run_id"`
+	Topic      string             `json:"topic"`
+	Tenant     string             `json:"tenant,omitempty"`
+	Stage      string             `json:"stage"`
+	Pass       int                `json:"pass"`
+	Candidates int                `json:"candidates"`
+	Verified   int                `json:"verified"`
+	Failed     int                `json:"failed"`
+	Target     int                `json:"target"`
+	Status     string             `json:"status,omitempty"` // Run outcome, once finished
+	Error      string             `json:"error,omitempty"`
+	Recent     []models.Statistic `json:"recent,omitempty"` // Latest verified statistics, newest first
+	StartedAt  time.Time          `json:"started_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// VisibleTo reports whether the caller of ctx may see the run: tenants
+// only see their own runs, and admins see every run
+func (r Run) VisibleTo(ctx context.Context) bool {
+	t := tenant.FromContext(ctx)
+	return t == nil || t.Admin || t.Name == r.Tenant
+}
+
+// Event is a change in a run's progress, carrying the run's full state
+type Event struct {
+	Type       string             `json:"type"`
+	Run        Run                `json:"run"`
+	Statistics []models.Statistic `json:"statistics,omitempty"` // Statistics verified, or for a retracted event withdrawn, by this change
+}
+
+// Hub tracks active runs and broadcasts their progress. A nil hub discards
+// everything reported to it. It is safe for concurrent use.
+type Hub struct {
+	mu   sync.Mutex
+	runs map[string]*Run
+	subs map[chan Event]struct{}
+}
+
+// NewHub creates a hub with no runs
+func NewHub() *Hub {
+	return &Hub{runs: make(map[string]*Run), subs: make(map[chan Event]struct{})}
+}
+
+// Begin registers a run of topic aiming for target verified statistics and
+// returns a context carrying its Reporter. The run belongs to the tenant of
+// ctx, if any.
+func (h *Hub) Begin(ctx context.Context, topic string, target int) (context.Context, *Reporter) {
+	if h == nil {
+		return ctx, nil
+	}
+	now := time.Now()
+	run := &Run{ID: newID(), Topic: topic, Target: target, StartedAt: now, UpdatedAt: now}
+	if t := tenant.FromContext(ctx); t != nil {
+		run.Tenant = t.Name
+	}
+	if fn, ok := ctx.Value(beginKey{}).(func(string)); ok && fn != nil {
+		fn(run.ID)
+		ctx = context.WithValue(ctx, beginKey{}, nil)
+	}
+
+	h.mu.Lock()
+	h.runs[run.ID] = run
+	h.broadcastLocked(Event{Type: EventStarted, Run: snapshot(run)})
+	h.mu.Unlock()
+
+	r := &Reporter{hub: h, id: run.ID}
+	r.onVerified, _ = ctx.Value(verifiedKey{}).(func([]models.Statistic))
+	r.onRetracted, _ = ctx.Value(retractedKey{}).(func([]models.Statistic))
+	return WithReporter(ctx, r), r
+}
+
+type (
+	beginKey     struct{}
+	verifiedKey  struct{}
+	retractedKey struct{}
+)
+
+// OnBegin returns a context under which the run a hub begins is reported to
+// fn before its first event, so a caller starting a run through an
+// orchestrator can pick that run's events out of a subscription
+func OnBegin(ctx context.Context, fn func(id string)) context.Context {
+	return context.WithValue(ctx, beginKey{}, fn)
+}
+
+// OnVerified returns a context under which the statistics verified by the
+// runs a hub begins are passed to fn as they verify. Unlike a subscriber,
+// fn is called synchronously, before the run can finish, so a caller can
+// deliver every statistic ahead of the run's result.
+func OnVerified(ctx context.Context, fn func(stats []models.Statistic)) context.Context {
+	return context.WithValue(ctx, verifiedKey{}, fn)
+}
+
+// OnRetracted returns a context under which the statistics the runs a hub
+// begins withdraw are passed to fn, synchronously like OnVerified, so a
+// caller that delivered them can take them back
+func OnRetracted(ctx context.Context, fn func(stats []models.Statistic)) context.Context {
+	return context.WithValue(ctx, retractedKey{}, fn)
+}
+
+// Runs returns the state of the active runs, oldest first
+func (h *Hub) Runs() []Run {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	runs := make([]Run, 0, len(h.runs))
+	for _, run := range h.runs {
+		runs = append(runs, snapshot(run))
+	}
+	slices.SortFunc(runs, func(a, b Run) int { return a.StartedAt.Compare(b.StartedAt) })
+	return runs
+}
+
+// Subscribe returns a channel receiving every event from now on, and a
+// function that ends the subscription
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+		})
+	}
+}
+
+// update applies change to a run and broadcasts the result, with the
+// statistics the change verified. Finished runs are removed.
+func (h *Hub) update(id, eventType string, verified []models.Statistic, change func(*Run)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	run, ok := h.runs[id]
+	if !ok {
+		return
+	}
+	change(run)
+	run.UpdatedAt = time.Now()
+	if eventType == EventFinished {
+		delete(h.runs, id)
+	}
+	h.broadcastLocked(Event{Type: eventType, Run: snapshot(run), Statistics: verified})
+}
+
+// broadcastLocked sends an event to every subscriber with room for it. The
+// caller must hold h.mu.
+func (h *Hub) broadcastLocked(ev Event) {
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// snapshot copies a run so it can be read without the hub's lock
+func snapshot(run *Run) Run {
+	s := *run
+	s.Recent = append([]models.Statistic(nil), run.Recent...)
+	return s
+}
+
+// Reporter reports the progress of one run. A nil reporter is a no-op, so
+// code paths without a hub need no checks.
+type Reporter struct {
+	hub         *Hub
+	id          string
+	onVerified  func([]models.Statistic) // Set by OnVerified
+	onRetracted func([]models.Statistic) // Set by OnRetracted
+
+	mu       sync.Mutex
+	reported []models.Statistic // Verified and not retracted
+}
+
+// Stage records that the run entered a stage of a pass, counting from 1
+func (r *Reporter) Stage(stage string, pass int) {
+	if r == nil {
+		return
+	}
+	r.hub.update(r.id, EventProgress, nil, func(run *Run) {
+		run.Stage = stage
+		run.Pass = pass
+	})
+}
+
+// Candidates records candidates extracted by synthesis
+func (r *Reporter) Candidates(n int) {
+	if r == nil || n == 0 {
+		return
+	}
+	r.hub.update(r.id, EventProgress, nil, func(run *Run) { run.Candidates += n })
+}
+
+// Verified records the outcome of a verification pass: the statistics that
+// verified, which its event carries, and the number of candidates that
+// failed
+func (r *Reporter) Verified(stats []models.Statistic, failed int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.reported = append(r.reported, stats...)
+	r.mu.Unlock()
+	r.hub.update(r.id, EventProgress, slices.Clone(stats), func(run *Run) {
+		run.Verified += len(stats)
+		run.Failed += failed
+		for _, stat := range stats {
+			run.Recent = append([]models.Statistic{stat}, run.Recent...)
+		}
+		if len(run.Recent) > recentLimit {
+			run.Recent = run.Recent[:recentLimit]
+		}
+	})
+	if r.onVerified != nil && len(stats) > 0 {
+		r.onVerified(slices.Clone(stats))
+	}
+}
+
+// Result records one candidate's verification result as soon as it is
+// final, so a statistic reaches subscribers the moment it passes
+// verification rather than with the rest of its pass
+func (r *Reporter) Result(result models.VerificationResult) {
+	if result.Verified && result.Statistic != nil {
+		r.Verified([]models.Statistic{*result.Statistic}, 0)
+		return
+	}
+	r.Verified(nil, 1)
+}
+
+// Retract withdraws statistics and failures the run reported that its
+// result will not hold, such as those of a verification pass that failed
+// after streaming them. Statistics the run did not report, or already
+// withdrew, are ignored.
+func (r *Reporter) Retract(stats []models.Statistic, failed int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	var retracted []models.Statistic
+	for _, stat := range stats {
+		if i := slices.IndexFunc(r.reported, sameStatistic(stat)); i >= 0 {
+			r.reported = slices.Delete(r.reported, i, i+1)
+			retracted = append(retracted, stat)
+		}
+	}
+	r.mu.Unlock()
+	if len(retracted) == 0 && failed == 0 {
+		return
+	}
+
+	r.hub.update(r.id, EventRetracted, slices.Clone(retracted), func(run *Run) {
+		run.Verified = max(run.Verified-len(retracted), 0)
+		run.Failed = max(run.Failed-failed, 0)
+		run.Recent = slices.DeleteFunc(run.Recent, func(stat models.Statistic) bool {
+			return slices.ContainsFunc(retracted, sameStatistic(stat))
+		})
+	})
+	if r.onRetracted != nil && len(retracted) > 0 {
+		r.onRetracted(retracted)
+	}
+}
+
+// Reconcile retracts the statistics the run reported that are not in
+// final, its result, because deduplication merged them or the
+// post-processing hook dropped or changed them
+func (r *Reporter) Reconcile(final []models.Statistic) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	remaining := slices.Clone(final)
+	var dropped []models.Statistic
+	for _, stat := range r.reported {
+		if i := slices.IndexFunc(remaining, sameStatistic(stat)); i >= 0 {
+			remaining = slices.Delete(remaining, i, i+1)
+			continue
+		}
+		dropped = append(dropped, stat)
+	}
+	r.mu.Unlock()
+	r.Retract(dropped, 0)
+}
+
+// sameStatistic matches statistics with the same name, value, and source
+func sameStatistic(a models.Statistic) func(models.Statistic) bool {
+	return func(b models.Statistic) bool {
+		return a.Name == b.Name && a.Value == b.Value && a.Unit == b.Unit && a.SourceURL == b.SourceURL
+	}
+}
+
+// Finish records the run's outcome and removes it from the active runs. A
+// run that failed has no result to hold the statistics it reported, so
+// they are retracted first.
+func (r *Reporter) Finish(status string, err error) {
+	if r == nil {
+		return
+	}
+	if err != nil {
+		r.Reconcile(nil)
+	}
+	r.hub.update(r.id, EventFinished, nil, func(run *Run) {
+		run.Stage = StageDone
+		run.Status = status
+		if err != nil {
+			run.Error = err.Error()
+		}
+	})
+}
+
+// reporterKey is the context key for the reporter of a run
+type reporterKey struct{}
+
+// WithReporter returns a context whose run progress is reported to r
+func WithReporter(ctx context.Context, r *Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// FromContext returns the reporter of ctx, or nil if there is none
+func FromContext(ctx context.Context) *Reporter {
+	r, _ := ctx.Value(reporterKey{}).(*Reporter)
+	return r
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
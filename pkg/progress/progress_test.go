@@ -0,0 +1,243 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+func TestHubReportsRunProgress(t *testing.T) {
+	hub := NewHub()
+	events, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	ctx, _ := hub.Begin(context.Background(), "solar", 3)
+	run := FromContext(ctx)
+	run.Stage(StageSynthesis, 1)
+	run.Candidates(4)
+	run.Verified([]models.Statistic{{Name: "a"}, {Name: "b"}}, 2)
+
+	if runs := hub.Runs(); len(runs) != 1 || runs[0].Verified != 2 || runs[0].Recent[0].Name != "b" {
+		t.Fatalf("active runs = %+v; want one run with 2 verified, newest first", runs)
+	}
+	run.Finish(models.StatusPartial, nil)
+	if runs := hub.Runs(); len(runs) != 0 {
+		t.Errorf("finished run still active: %+v", runs)
+	}
+
+	var types []string
+	var verified []string
+	for len(events) > 0 {
+		ev := <-events
+		types = append(types, ev.Type)
+		for _, stat := range ev.Statistics {
+			verified = append(verified, ev.Type+":"+stat.Name)
+		}
+	}
+	want := "started progress progress progress finished"
+	if got := strings.Join(types, " "); got != want {
+		t.Errorf("events = %q; want %q", got, want)
+	}
+	// Only the event of the verification pass carries its statistics
+	if got := strings.Join(verified, " "); got != "progress:a progress:b" {
+		t.Errorf("event statistics = %q; want the pass's two", got)
+	}
+
+	// Without a hub, reporting is a no-op
+	var none *Hub
+	ctx, r := none.Begin(context.Background(), "solar", 3)
+	FromContext(ctx).Stage(StageResearch, 1)
+	r.Finish(models.StatusComplete, nil)
+}
+
+func TestOnBegin(t *testing.T) {
+	hub := NewHub()
+	var ids []string
+	ctx := OnBegin(context.Background(), func(id string) { ids = append(ids, id) })
+	ctx, r := hub.Begin(ctx, "solar", 3)
+	hub.Begin(ctx, "nested", 1)
+	if len(ids) != 1 || ids[0] != r.id {
+		t.Errorf("OnBegin reported %v; want only the first run, %s", ids, r.id)
+	}
+}
+
+func TestOnVerified(t *testing.T) {
+	hub := NewHub()
+	var got []string
+	ctx := OnVerified(context.Background(), func(stats []models.Statistic) {
+		for _, stat := range stats {
+			got = append(got, stat.Name)
+		}
+	})
+	_, r := hub.Begin(ctx, "solar", 3)
+	r.Result(models.VerificationResult{Verified: true, Statistic: &models.Statistic{Name: "a"}})
+	r.Result(models.VerificationResult{Statistic: &models.Statistic{Name: "rejected"}})
+	r.Verified([]models.Statistic{{Name: "b"}}, 0)
+	if strings.Join(got, " ") != "a b" {
+		t.Errorf("OnVerified got %v; want the verified statistics a and b", got)
+	}
+	if runs := hub.Runs(); runs[0].Verified != 2 || runs[0].Failed != 1 {
+		t.Errorf("run = %+v; want 2 verified and 1 failed", runs[0])
+	}
+}
+
+func TestRetract(t *testing.T) {
+	hub := NewHub()
+	events, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+	var got []string
+	ctx := OnRetracted(context.Background(), func(stats []models.Statistic) {
+		for _, stat := range stats {
+			got = append(got, stat.Name)
+		}
+	})
+	_, r := hub.Begin(ctx, "solar", 3)
+	r.Verified([]models.Statistic{{Name: "a"}, {Name: "b"}, {Name: "c"}}, 2)
+
+	// A failed pass withdraws what it streamed; statistics never reported
+	// are ignored
+	r.Retract([]models.Statistic{{Name: "a"}, {Name: "unreported"}}, 1)
+	if runs := hub.Runs(); runs[0].Verified != 2 || runs[0].Failed != 1 || len(runs[0].Recent) != 2 {
+		t.Fatalf("run = %+v; want 2 verified, 1 failed, and a dropped from recent", runs[0])
+	}
+
+	// The result holds c, and a changed copy of b
+	r.Reconcile([]models.Statistic{{Name: "c"}, {Name: "b", Value: 5}})
+	if runs := hub.Runs(); runs[0].Verified != 1 || runs[0].Recent[0].Name != "c" {
+		t.Fatalf("run = %+v; want only c verified", runs[0])
+	}
+	// A failed run withdraws everything left
+	r.Finish("", errors.New("hook failed"))
+	if strings.Join(got, " ") != "a b c" {
+		t.Errorf("OnRetracted got %v; want a, b, then c", got)
+	}
+
+	var retracted []string
+	for len(events) > 0 {
+		ev := <-events
+		if ev.Type != EventRetracted {
+			continue
+		}
+		var names []string
+		for _, stat := range ev.Statistics {
+			names = append(names, stat.Name)
+		}
+		retracted = append(retracted, strings.Join(names, ","))
+	}
+	if got := strings.Join(retracted, " "); got != "a b c" {
+		t.Errorf("retracted events = %q; want one each for a, b, and c", got)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	registry, err := tenant.New([]tenant.Tenant{{Name: "a", Key: "key-a"}, {Name: "b", Key: "key-b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := NewHub()
+	_, running := hub.Begin(registry.WithTenant(context.Background(), "a"), "solar", 2)
+	_, hidden := hub.Begin(registry.WithTenant(context.Background(), "b"), "wind", 2)
+
+	srv := httptest.NewServer(registry.Middleware(hub.Handler(slog.New(slog.DiscardHandler))))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dashboard := NewDashboard()
+	var got []Event
+	err = Stream(ctx, srv.Client(), srv.URL+"/runs/events", "key-a", func(ev Event) {
+		got = append(got, ev)
+		dashboard.Apply(ev)
+		switch len(got) {
+		case 2: // The snapshot and ready
+			hidden.Verified([]models.Statistic{{Name: "gusts"}}, 0)
+			running.Verified([]models.Statistic{{Name: "panels", Value: 12, Unit: "%"}}, 1)
+		case 3:
+			running.Finish(models.StatusPartial, nil)
+		case 4:
+			cancel()
+		}
+	})
+	if ctx.Err() == nil {
+		t.Fatalf("stream ended early: %v", err)
+	}
+
+	var types []string
+	for _, ev := range got {
+		types = append(types, ev.Type+":"+ev.Run.Topic)
+	}
+	want := "started:solar ready: progress:solar finished:solar"
+	if s := strings.Join(types, " "); s != want {
+		t.Errorf("events = %q; want %q (other tenants' runs hidden)", s, want)
+	}
+
+	var buf bytes.Buffer
+	if err := dashboard.Render(&buf, "status", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, w := range []string{"Active runs: 0", "Recently verified", "12 %", "panels", "Finished", "1/2 verified"} {
+		if !strings.Contains(out, w) {
+			t.Errorf("dashboard lacks %q:\n%s", w, out)
+		}
+	}
+}
+
+func TestRunsRequiresGET(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHub().Handler(slog.New(slog.DiscardHandler))(rec, httptest.NewRequest(http.MethodPost, "/runs", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /runs = %d; want 405", rec.Code)
+	}
+}
+
+func TestServeRun(t *testing.T) {
+	hub := NewHub()
+	_, other := hub.Begin(context.Background(), "other", 1)
+	defer other.Finish(models.StatusPartial, nil)
+
+	serve := func(fn func(context.Context) (any, error)) []string {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/orchestrate", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		if !WantsEvents(req) {
+			t.Fatal("WantsEvents() = false")
+		}
+		hub.ServeRun(rec, req, fn, slog.New(slog.DiscardHandler))
+		var lines []string
+		for line := range strings.SplitSeq(rec.Body.String(), "\n") {
+			if event, ok := strings.CutPrefix(line, "event: "); ok {
+				lines = append(lines, event)
+			}
+			if strings.Contains(line, `"statistics":[{"name":"panels"`) {
+				lines = append(lines, "statistic")
+			}
+		}
+		return lines
+	}
+
+	got := serve(func(ctx context.Context) (any, error) {
+		_, run := hub.Begin(ctx, "solar", 1)
+		other.Verified([]models.Statistic{{Name: "gusts"}}, 0)
+		run.Result(models.VerificationResult{Verified: true, Statistic: &models.Statistic{Name: "panels"}})
+		run.Finish(models.StatusComplete, nil)
+		return map[string]string{"status": models.StatusComplete}, nil
+	})
+	if s := strings.Join(got, " "); s != "started progress statistic finished result" {
+		t.Errorf("events = %q; want the run's own events, then its result", s)
+	}
+
+	got = serve(func(context.Context) (any, error) { return nil, errors.New("search failed") })
+	if s := strings.Join(got, " "); s != "error" {
+		t.Errorf("events of a failed run = %q; want an error", s)
+	}
+}
@@ -0,0 +1,192 @@
+package progress
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Dashboard limits
+const (
+	finishedLimit    = 5
+	dashboardRecent  = 10
+	topicWidth       = 32
+	statisticWidth   = 60
+	maxEventLineSize = 1 << 20
+)
+
+// Stream reads the event stream at url, calling fn for each event, until
+// ctx ends or the stream fails. apiKey, if set, is sent as X-API-Key.
+func Stream(ctx context.Context, client *http.Client, url, apiKey string, fn func(Event)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	resp, err := client.Do(req) //nolint:gosec // G704: URL from config, not user input
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxEventLineSize)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				var ev Event
+				if err := json.Unmarshal([]byte(data.String()), &ev); err != nil {
+					return fmt.Errorf("invalid event: %w", err)
+				}
+				fn(ev)
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// recentStatistic is a verified statistic and the topic it was found for
+type recentStatistic struct {
+	topic string
+	stat  models.Statistic
+}
+
+// Dashboard is the state shown by `stats-agent watch`: the active runs, the
+// latest runs to finish, and the latest statistics verified by any run
+type Dashboard struct {
+	active   map[string]Run
+	finished []Run // Newest first
+	recent   []recentStatistic
+}
+
+// NewDashboard creates an empty dashboard
+func NewDashboard() *Dashboard {
+	return &Dashboard{active: make(map[string]Run)}
+}
+
+// Reset forgets the active runs, which a new stream lists again
+func (d *Dashboard) Reset() {
+	clear(d.active)
+}
+
+// Apply updates the dashboard with an event
+func (d *Dashboard) Apply(ev Event) {
+	if ev.Type == EventReady {
+		return
+	}
+	if ev.Type == EventRetracted {
+		d.recent = slices.DeleteFunc(d.recent, func(r recentStatistic) bool {
+			return r.topic == ev.Run.Topic && slices.ContainsFunc(ev.Statistics, sameStatistic(r.stat))
+		})
+	}
+	prev := d.active[ev.Run.ID]
+	if added := ev.Run.Verified - prev.Verified; added > 0 && ev.Type != EventStarted {
+		for _, stat := range slices.Backward(ev.Run.Recent[:min(added, len(ev.Run.Recent))]) {
+			d.recent = append([]recentStatistic{{topic: ev.Run.Topic, stat: stat}}, d.recent...)
+		}
+		d.recent = d.recent[:min(len(d.recent), dashboardRecent)]
+	}
+
+	if ev.Type != EventFinished {
+		d.active[ev.Run.ID] = ev.Run
+		return
+	}
+	delete(d.active, ev.Run.ID)
+	d.finished = append([]Run{ev.Run}, d.finished...)
+	d.finished = d.finished[:min(len(d.finished), finishedLimit)]
+}
+
+// Render writes the dashboard below a status line, with elapsed times as of now
+func (d *Dashboard) Render(w io.Writer, status string, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\n\n", status)
+
+	runs := make([]Run, 0, len(d.active))
+	for _, run := range d.active {
+		runs = append(runs, run)
+	}
+	slices.SortFunc(runs, func(a, b Run) int { return a.StartedAt.Compare(b.StartedAt) })
+
+	fmt.Fprintf(tw, "Active runs: %d\n", len(runs))
+	if len(runs) > 0 {
+		fmt.Fprintln(tw, "RUN\tTOPIC\tSTAGE\tPASS\tCANDIDATES\tVERIFIED\tFAILED\tELAPSED")
+		for _, run := range runs {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d/%d\t%d\t%s\n",
+				shortID(run.ID), truncate(run.Topic, topicWidth), stageOf(run), run.Pass,
+				run.Candidates, run.Verified, run.Target, run.Failed, elapsed(run.StartedAt, now))
+		}
+	}
+
+	if len(d.recent) > 0 {
+		fmt.Fprintln(tw, "\nRecently verified")
+		for _, r := range d.recent {
+			fmt.Fprintf(tw, "  %s\t%v %s\t%s\t%s\n",
+				truncate(r.topic, topicWidth), r.stat.Value, r.stat.Unit,
+				truncate(r.stat.Name, statisticWidth), r.stat.Source)
+		}
+	}
+
+	if len(d.finished) > 0 {
+		fmt.Fprintln(tw, "\nFinished")
+		for _, run := range d.finished {
+			outcome := run.Status
+			if run.Error != "" {
+				outcome = "failed: " + truncate(run.Error, statisticWidth)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%d/%d verified\t%s\t%s\n",
+				shortID(run.ID), truncate(run.Topic, topicWidth), run.Verified, run.Target,
+				elapsed(run.StartedAt, run.UpdatedAt), outcome)
+		}
+	}
+	return tw.Flush()
+}
+
+// stageOf names the stage of a run that has not reported one yet
+func stageOf(run Run) string {
+	if run.Stage == "" {
+		return "starting"
+	}
+	return run.Stage
+}
+
+func shortID(id string) string {
+	return id[:min(len(id), 8)]
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func elapsed(from, to time.Time) string {
+	return to.Sub(from).Round(time.Second).String()
+}
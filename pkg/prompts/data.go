@@ -0,0 +1,106 @@
+package prompts
+
+import "github.com/plexusone/agent-team-stats/pkg/models"
+
+// ExtractData is the data of SynthesisExtract
+type ExtractData struct {
+	Topic   string
+	URL     string
+	Domain  string
+	Tables  string // Pre-rendered table section, possibly empty
+	Content string // Page text, truncated to the prompt budget
+}
+
+// RepairData is the data of SynthesisRepair
+type RepairData struct {
+	Error  string // JSON parse error
+	Output string // Malformed LLM output
+}
+
+// FigureData is the data of SynthesisFigure
+type FigureData struct {
+	Topic   string
+	URL     string // Page the image is on
+	Alt     string
+	Caption string
+}
+
+// JudgeData is the data of VerificationJudge
+type JudgeData struct {
+	Name      string
+	Value     float32
+	Unit      string
+	Excerpt   string
+	SourceURL string
+	Passages  []string // Source passages around the value
+}
+
+// BatchJudgeData is the data of VerificationJudgeBatch
+type BatchJudgeData struct {
+	SourceURL  string
+	Passages   []string    // Source passages around the candidates' values
+	Statistics []JudgeData // Candidates to judge, without passages of their own
+}
+
+// FigureCheckData is the data of VerificationFigure
+type FigureCheckData struct {
+	Name    string
+	Value   float32
+	Unit    string
+	Excerpt string // Text read from the image during extraction
+}
+
+// ClaimData is the data of FactCheckParse
+type ClaimData struct {
+	Claim string
+}
+
+// StanceData is the data of FactCheckJudge
+type StanceData struct {
+	Claim      string
+	Subject    string
+	Period     string
+	Statistics []models.Statistic
+}
+
+// FilterData is the data of RefineFilter
+type FilterData struct {
+	Topic       string
+	Constraints []string
+	Statistics  []models.Statistic
+}
+
+// QueryData is the data of RefineQuery
+type QueryData struct {
+	Topic       string
+	Constraints []string
+}
+
+// DirectSearchData is the data of DirectSearch
+type DirectSearchData struct {
+	Topic         string
+	MinStatistics int
+}
+
+// NarrativeData is the data of SummaryNarrative
+type NarrativeData struct {
+	Topic      string
+	Statistics []models.Statistic
+}
+
+// sampleData holds representative data for checking templates at load time
+var sampleData = map[Name]any{
+	SynthesisExtract:        ExtractData{Topic: "topic", URL: "https://example.com", Domain: "example.com", Content: "content"},
+	SynthesisExtractCompact: ExtractData{Topic: "topic", URL: "https://example.com", Domain: "example.com", Content: "content"},
+	SynthesisRepair:         RepairData{Error: "error", Output: "output"},
+	SynthesisFigure:         FigureData{Topic: "topic", URL: "https://example.com", Alt: "alt", Caption: "caption"},
+	VerificationJudge:       JudgeData{Name: "name", Value: 1, Passages: []string{"passage"}},
+	VerificationJudgeBatch:  BatchJudgeData{SourceURL: "https://example.com", Passages: []string{"passage"}, Statistics: []JudgeData{{Name: "name", Value: 1}}},
+	VerificationFigure:      FigureCheckData{Name: "name", Value: 1, Excerpt: "excerpt"},
+	FactCheckParse:          ClaimData{Claim: "claim"},
+	FactCheckJudge:          StanceData{Claim: "claim", Statistics: []models.Statistic{{Name: "name"}}},
+	RefineFilter:            FilterData{Topic: "topic", Constraints: []string{"constraint"}, Statistics: []models.Statistic{{Name: "name"}}},
+	RefineQuery:             QueryData{Topic: "topic", Constraints: []string{"constraint"}},
+	DirectSearch:            DirectSearchData{Topic: "topic", MinStatistics: 10},
+	SummaryNarrative:        NarrativeData{Topic: "topic", Statistics: []models.Statistic{{Name: "name"}}},
+}
@@ -0,0 +1,54 @@
+package prompts
+
+import "fmt"
+
+// Profile selects the extraction prompt: the full one, which asks for
+// statistic types, methodology, and table cells, or a compact one with a
+// four-field schema that small local models can follow
+type Profile string
+
+const (
+	ProfileAuto    Profile = "auto"    // Compact for small or heavily quantized models
+	ProfileFull    Profile = "full"    // SynthesisExtract
+	ProfileCompact Profile = "compact" // SynthesisExtractCompact, a page chunk at a time
+)
+
+// Compact extraction reads a page in chunks of at most CompactChunkTokens,
+// and at most CompactMaxChunks of them, so a small model never sees more
+// text than it can keep track of
+const (
+	CompactChunkTokens = 1500
+	CompactMaxChunks   = 4
+)
+
+// ParseProfile parses a SYNTHESIS_PROMPT_PROFILE setting; empty is auto
+func ParseProfile(s string) (Profile, error) {
+	switch p := Profile(s); p {
+	case "":
+		return ProfileAuto, nil
+	case ProfileAuto, ProfileFull, ProfileCompact:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown prompt profile %q (want auto, full, or compact)", s)
+	}
+}
+
+// Resolve returns the profile used for a model, choosing for auto by
+// whether the model is small
+func (p Profile) Resolve(small bool) Profile {
+	if p != ProfileAuto && p != "" {
+		return p
+	}
+	if small {
+		return ProfileCompact
+	}
+	return ProfileFull
+}
+
+// Extract returns the extraction prompt of a resolved profile
+func (p Profile) Extract() Name {
+	if p == ProfileCompact {
+		return SynthesisExtractCompact
+	}
+	return SynthesisExtract
+}
@@ -0,0 +1,202 @@
+// Package prompts holds the agents' LLM prompts as versioned text/template
+// files. Defaults are embedded in the binary; a directory of <name>.tmpl files
+// (PROMPTS_DIR) overrides any of them, so extraction and verification behavior
+// can be tuned without recompiling.
+//
+// Each template may start with a {{/* version: X */ -}} comment. The version
+// is logged and recorded so results can be traced to the prompts that
+// produced them; overrides without a version comment report "custom".
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+// Name identifies a prompt template
+type Name string
+
+const (
+	SynthesisSystem         Name = "synthesis_system"          // ADK instruction of the synthesis agent
+	SynthesisExtract        Name = "synthesis_extract"         // Extracts statistics from a page (ExtractData)
+	SynthesisExtractCompact Name = "synthesis_extract_compact" // Extracts statistics from a page chunk for small models (ExtractData)
+	SynthesisRepair         Name = "synthesis_repair"          // Repairs malformed extraction JSON (RepairData)
+	SynthesisFigure         Name = "synthesis_figure"          // Reads statistics from an attached chart image (FigureData)
+	VerificationSystem      Name = "verification_system"       // ADK instruction of the verification agent
+	VerificationJudge       Name = "verification_judge"        // Judges a candidate against source passages (JudgeData)
+	VerificationJudgeBatch  Name = "verification_judge_batch"  // Judges several candidates from one page in a call (BatchJudgeData)
+	VerificationFigure      Name = "verification_figure"       // Re-reads a statistic from an attached chart image (FigureCheckData)
+	ResearchSystem          Name = "research_system"           // ADK instruction of the research agent
+	OrchestrationSystem     Name = "orchestration_system"      // ADK instruction of the ADK orchestrator
+	EinoSystem              Name = "eino_system"               // ADK instruction wrapping the Eino orchestrator over A2A
+	FactCheckParse          Name = "factcheck_parse"           // Parses a claim into subject, value, and query (ClaimData)
+	FactCheckJudge          Name = "factcheck_judge"           // Judges statistics against a claim (StanceData)
+	RefineFilter            Name = "refine_filter"             // Filters statistics by constraints (FilterData)
+	RefineQuery             Name = "refine_query"              // Rewrites a topic to satisfy constraints (QueryData)
+	DirectSearch            Name = "direct_search"             // Direct-mode statistics search (DirectSearchData)
+	SummaryNarrative        Name = "summary_narrative"         // Writes a cited summary paragraph (NarrativeData)
+)
+
+//go:embed templates/*.tmpl
+var defaults embed.FS
+
+// versionPattern matches the version comment at the top of a template
+var versionPattern = regexp.MustCompile(`^\{\{-?\s*/\*\s*version:\s*(\S+)\s*\*/`)
+
+// funcs are available to every template
+var funcs = template.FuncMap{
+	"quote": strconv.Quote,
+	"join":  strings.Join,
+	"inc":   func(i int) int { return i + 1 },
+}
+
+// Set is a loaded collection of prompt templates. It is safe for concurrent use.
+type Set struct {
+	templates map[Name]*template.Template
+	versions  map[Name]string
+	sources   map[Name]string
+}
+
+// Default returns the embedded default prompts
+func Default() *Set {
+	s, err := Load("")
+	if err != nil {
+		panic(fmt.Sprintf("prompts: invalid embedded templates: %v", err))
+	}
+	return s
+}
+
+// FromConfig loads the prompts with overrides from cfg.PromptsDir
+func FromConfig(cfg *config.Config) (*Set, error) {
+	return Load(cfg.PromptsDir)
+}
+
+// Load returns the embedded default prompts with any <name>.tmpl files in
+// dir taking their place. Every template is parsed and executed against
+// sample data, so a typo in an override fails at startup rather than on the
+// first request. An empty dir loads the defaults only.
+func Load(dir string) (*Set, error) {
+	s := &Set{templates: make(map[Name]*template.Template), versions: make(map[Name]string), sources: make(map[Name]string)}
+
+	entries, err := fs.Glob(defaults, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range entries {
+		text, err := defaults.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.add(nameOf(path), string(text), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir != "" {
+		overrides, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range overrides {
+			name := nameOf(path)
+			if _, ok := s.templates[name]; !ok {
+				return nil, fmt.Errorf("prompt override %s does not match a known prompt (known: %s)", path, strings.Join(s.names(), ", "))
+			}
+			text, err := os.ReadFile(path) //nolint:gosec // G304: path from PROMPTS_DIR config
+			if err != nil {
+				return nil, fmt.Errorf("failed to read prompt override: %w", err)
+			}
+			if err := s.add(name, string(text), "custom"); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	}
+
+	for name, tmpl := range s.templates {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, sampleData[name]); err != nil {
+			return nil, fmt.Errorf("prompt %s: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+// add parses a template and records its version, using fallback when the
+// template declares none
+func (s *Set) add(name Name, text, fallback string) error {
+	tmpl, err := template.New(string(name)).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse prompt %s: %w", name, err)
+	}
+	version := fallback
+	if m := versionPattern.FindStringSubmatch(text); m != nil {
+		version = m[1]
+	}
+	s.templates[name] = tmpl
+	s.versions[name] = version
+	s.sources[name] = text
+	return nil
+}
+
+// Render executes a prompt with data
+func (s *Set) Render(name Name, data any) (string, error) {
+	tmpl, ok := s.templates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown prompt: %s", name)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", name, err)
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// Version returns the version of a loaded prompt
+func (s *Set) Version(name Name) string {
+	return s.versions[name]
+}
+
+// Versions returns every prompt's version, e.g. for logging at startup
+func (s *Set) Versions() map[Name]string {
+	versions := make(map[Name]string, len(s.versions))
+	for name, v := range s.versions {
+		versions[name] = v
+	}
+	return versions
+}
+
+// Sources returns every prompt's template text, e.g. to archive the prompts
+// behind a run
+func (s *Set) Sources() map[Name]string {
+	sources := make(map[Name]string, len(s.sources))
+	for name, text := range s.sources {
+		sources[name] = text
+	}
+	return sources
+}
+
+// names returns the loaded prompt names in order
+func (s *Set) names() []string {
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nameOf derives a prompt name from a template file path
+func nameOf(path string) Name {
+	return Name(strings.TrimSuffix(filepath.Base(path), ".tmpl"))
+}
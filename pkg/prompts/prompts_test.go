@@ -0,0 +1,137 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestDefault(t *testing.T) {
+	s := Default()
+	for _, name := range []Name{
+		SynthesisSystem, SynthesisExtract, SynthesisExtractCompact, SynthesisRepair, SynthesisFigure,
+		VerificationSystem, VerificationJudge, VerificationJudgeBatch, VerificationFigure, ResearchSystem,
+		OrchestrationSystem, EinoSystem, FactCheckParse, FactCheckJudge,
+		RefineFilter, RefineQuery, DirectSearch, SummaryNarrative,
+	} {
+		want := "1"
+		if name == SynthesisExtract {
+			want = "3" // Asks for methodology and statistic type
+		}
+		if v := s.Version(name); v != want {
+			t.Errorf("Version(%s) = %q, want %s", name, v, want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := Default()
+
+	got, err := s.Render(FactCheckParse, ClaimData{Claim: `Unemployment is "35%"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Extract the numeric claim from this statement so it can be fact-checked.
+
+Statement: "Unemployment is \"35%\""
+
+Return only a JSON object:
+{"subject": "what is measured", "value": 35, "unit": "%", "period": "2023", "query": "short web search topic for finding official statistics on the subject"}
+
+Use null for "value" if the statement contains no number. Do not include the claimed value in "query".`
+	if got != want {
+		t.Errorf("Render(FactCheckParse) =\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = s.Render(RefineFilter, FilterData{
+		Topic:       "wages",
+		Constraints: []string{"US only", "after 2020"},
+		Statistics: []models.Statistic{
+			{Name: "Median wage", Value: 61900, Unit: "USD", Source: "BLS", SourceURL: "https://bls.gov", Excerpt: "median wage"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range []string{
+		"- US only\n- after 2020",
+		"1. Median wage: 61900 USD (source: BLS, https://bls.gov)\n   excerpt: \"median wage\"",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("Render(RefineFilter) missing %q:\n%s", part, got)
+		}
+	}
+
+	if _, err := s.Render("missing", nil); err == nil {
+		t.Error("Render(missing) succeeded")
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		text        string
+		wantErr     bool
+		wantVersion string
+	}{
+		{"custom", "refine_query.tmpl", "Query for {{.Topic}}", false, "custom"},
+		{"versioned", "refine_query.tmpl", "{{/* version: 2b */ -}}\nQuery for {{.Topic}}", false, "2b"},
+		{"unknown prompt", "refine_typo.tmpl", "Query", true, ""},
+		{"unknown field", "refine_query.tmpl", "Query for {{.Subject}}", true, ""},
+		{"syntax error", "refine_query.tmpl", "Query for {{.Topic", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.text), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := Load(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if v := s.Version(RefineQuery); v != tt.wantVersion {
+				t.Errorf("Version() = %q, want %q", v, tt.wantVersion)
+			}
+			if v := s.Version(RefineFilter); v != "1" {
+				t.Errorf("non-overridden Version() = %q, want 1", v)
+			}
+			got, err := s.Render(RefineQuery, QueryData{Topic: "wages"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != "Query for wages" {
+				t.Errorf("Render() = %q", got)
+			}
+		})
+	}
+}
+
+func TestProfile(t *testing.T) {
+	p, err := ParseProfile("")
+	if err != nil || p != ProfileAuto {
+		t.Errorf("ParseProfile(\"\") = %q, %v; want auto", p, err)
+	}
+	if _, err := ParseProfile("tiny"); err == nil {
+		t.Error("ParseProfile(tiny) succeeded")
+	}
+
+	if got := ProfileAuto.Resolve(true).Extract(); got != SynthesisExtractCompact {
+		t.Errorf("auto for a small model = %s", got)
+	}
+	if got := ProfileAuto.Resolve(false).Extract(); got != SynthesisExtract {
+		t.Errorf("auto for a large model = %s", got)
+	}
+	if got := ProfileFull.Resolve(true); got != ProfileFull {
+		t.Errorf("full for a small model = %s", got)
+	}
+}
@@ -0,0 +1,204 @@
+// Package ratelimit limits inbound API requests per client and outbound page
+// fetches per domain. Counts live in a Store: in process memory by default,
+// or in Redis so that every replica of an autoscaled deployment shares the
+// same limits.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+// Store counts events in fixed time windows
+type Store interface {
+	// Incr adds one to the count of key in the current window of the given
+	// length, returning the new count and the time left in the window
+	Incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error)
+}
+
+// Limiter allows at most limit events per key in each window
+type Limiter struct {
+	store  Store
+	name   string // Namespaces keys, e.g. "api" or "fetch"
+	limit  int64
+	window time.Duration
+}
+
+// New creates a limiter of limit events per window. It returns nil, which
+// allows everything, when limit is not positive.
+func New(store Store, name string, limit int, window time.Duration) *Limiter {
+	if limit <= 0 || store == nil {
+		return nil
+	}
+	return &Limiter{store: store, name: name, limit: int64(limit), window: window}
+}
+
+// Allow counts an event for key and reports whether it is within the limit,
+// and if not, how long until the window resets. A nil limiter allows
+// everything.
+func (l *Limiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	if l == nil {
+		return true, 0, nil
+	}
+	n, ttl, err := l.store.Incr(ctx, l.name+":"+key, l.window)
+	if err != nil {
+		return true, 0, fmt.Errorf("rate limit store: %w", err)
+	}
+	if n > l.limit {
+		return false, ttl, nil
+	}
+	return true, 0, nil
+}
+
+// Wait blocks until an event for key is within the limit or ctx ends. A
+// store error lets the event through rather than stalling the caller.
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	for {
+		ok, retry, err := l.Allow(ctx, key)
+		if ok || err != nil {
+			return nil //nolint:nilerr // An unreachable store must not stop fetching
+		}
+		timer := time.NewTimer(max(retry, 10*time.Millisecond))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// WaitURL is Wait keyed by the host of rawURL, so fetches from one site
+// are spaced regardless of which replica makes them
+func (l *Limiter) WaitURL(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil //nolint:nilerr // The fetch itself reports a bad URL
+	}
+	return l.Wait(ctx, strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."))
+}
+
+// Middleware answers 429 with Retry-After once a client has used up the
+// limit. Clients are told apart by tenant when tenant API keys are
+// configured, and by remote address otherwise, so it must be served behind
+// the tenant middleware. /health and the tenant usage endpoint are never
+// limited. A nil limiter lets every request through.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if p := path.Clean(req.URL.Path); p == "/health" || p == tenant.UsagePath {
+			next.ServeHTTP(w, req)
+			return
+		}
+		ok, retry, err := l.Allow(req.Context(), clientKey(req))
+		if err == nil && !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds()+0.999)))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// clientKey identifies the client of a request for rate limiting
+func clientKey(req *http.Request) string {
+	if t := tenant.FromContext(req.Context()); t != nil {
+		return "tenant:" + t.Name
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// NewStore creates the store selected by cfg.RateLimitBackend
+func NewStore(ctx context.Context, cfg *config.Config) (Store, error) {
+	switch cfg.RateLimitBackend {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "redis":
+		return NewRedisStore(ctx, cfg.RedisURL, cfg.SessionKeyPrefix)
+	default:
+		return nil, fmt.Errorf("unsupported rate limit backend: %s (supported: memory, redis)", cfg.RateLimitBackend)
+	}
+}
+
+// APIFromConfig creates the inbound limiter of API_RATE_LIMIT_RPM requests
+// per client per minute, or nil when it is 0
+func APIFromConfig(ctx context.Context, cfg *config.Config) (*Limiter, error) {
+	if cfg.APIRateLimitRPM <= 0 {
+		return nil, nil
+	}
+	store, err := NewStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return New(store, "api", cfg.APIRateLimitRPM, time.Minute), nil
+}
+
+// FetchFromConfig creates the outbound limiter of FETCH_DOMAIN_RPM page
+// fetches per domain per minute, or nil when it is 0
+func FetchFromConfig(ctx context.Context, cfg *config.Config) (*Limiter, error) {
+	if cfg.FetchDomainRPM <= 0 {
+		return nil, nil
+	}
+	store, err := NewStore(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return New(store, "fetch", cfg.FetchDomainRPM, time.Minute), nil
+}
+
+// MemoryStore is a Store local to one process
+type MemoryStore struct {
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+	now     func() time.Time
+}
+
+type memoryWindow struct {
+	count int64
+	ends  time.Time
+}
+
+// NewMemoryStore creates an empty in-process store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{windows: make(map[string]*memoryWindow), now: time.Now}
+}
+
+// Incr implements Store
+func (s *MemoryStore) Incr(_ context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.ends) {
+		// Drop expired windows as new ones start, so idle keys do not pile up
+		for k, old := range s.windows {
+			if !now.Before(old.ends) {
+				delete(s.windows, k)
+			}
+		}
+		w = &memoryWindow{ends: now.Add(window)}
+		s.windows[key] = w
+	}
+	w.count++
+	return w.count, w.ends.Sub(now), nil
+}
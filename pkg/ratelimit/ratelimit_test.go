@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	l := New(store, "api", 2, time.Minute)
+
+	for i := range 2 {
+		if ok, _, _ := l.Allow(ctx, "a"); !ok {
+			t.Fatalf("request %d refused", i+1)
+		}
+	}
+	ok, retry, _ := l.Allow(ctx, "a")
+	if ok || retry != time.Minute {
+		t.Errorf("third request = %v, %v; want refused for a minute", ok, retry)
+	}
+	if ok, _, _ := l.Allow(ctx, "b"); !ok {
+		t.Error("other key refused")
+	}
+
+	now = now.Add(time.Minute)
+	if ok, _, _ := l.Allow(ctx, "a"); !ok {
+		t.Error("refused in the next window")
+	}
+}
+
+func TestRedisStoreSharedAcrossReplicas(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	newReplica := func() *Limiter {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+		return New(NewRedisStoreFromClient(client, ""), "fetch", 3, time.Minute)
+	}
+	a, b := newReplica(), newReplica()
+
+	for _, l := range []*Limiter{a, b, a} {
+		if ok, _, err := l.Allow(ctx, "example.com"); !ok || err != nil {
+			t.Fatalf("Allow() = %v, %v", ok, err)
+		}
+	}
+	ok, retry, err := b.Allow(ctx, "example.com")
+	if ok || err != nil || retry <= 0 || retry > time.Minute {
+		t.Errorf("fourth fetch across replicas = %v, %v, %v; want refused", ok, retry, err)
+	}
+
+	mr.FastForward(time.Minute)
+	if ok, _, _ := a.Allow(ctx, "example.com"); !ok {
+		t.Error("refused after the window expired")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	l := New(NewMemoryStore(), "api", 1, time.Minute)
+	handler := l.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	serve := func(path, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/orchestrate", "10.0.0.1:5000"); rec.Code != http.StatusOK {
+		t.Fatalf("first request = %d", rec.Code)
+	}
+	rec := serve("/orchestrate", "10.0.0.1:5001")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("second request = %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := serve("/orchestrate", "10.0.0.2:5000"); rec.Code != http.StatusOK {
+		t.Errorf("other client = %d", rec.Code)
+	}
+	for _, path := range []string{"/health", "/usage"} {
+		if rec := serve(path, "10.0.0.1:5000"); rec.Code != http.StatusOK {
+			t.Errorf("%s = %d", path, rec.Code)
+		}
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	if err := l.WaitURL(context.Background(), "https://example.com/a"); err != nil {
+		t.Errorf("WaitURL() = %v", err)
+	}
+	if New(NewMemoryStore(), "api", 0, time.Minute) != nil {
+		t.Error("expected nil limiter for a zero limit")
+	}
+}
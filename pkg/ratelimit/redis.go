@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// incrScript counts an event and starts the window's expiry on its first
+// event, returning the count and the milliseconds left in the window.
+// Running both in one script keeps a key from being left without an expiry.
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {n, ttl}
+`)
+
+// RedisStore is a Store shared by every replica connected to one Redis
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore connects to the Redis server at redisURL (for example
+// redis://localhost:6379/0)
+func NewRedisStore(ctx context.Context, redisURL, prefix string) (*RedisStore, error) {
+	if redisURL == "" {
+		return nil, fmt.Errorf("REDIS_URL is required for the redis rate limit backend")
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return NewRedisStoreFromClient(client, prefix), nil
+}
+
+// NewRedisStoreFromClient creates a store using an existing client
+func NewRedisStoreFromClient(client *redis.Client, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "stats-agent:"
+	}
+	return &RedisStore{client: client, prefix: prefix + "ratelimit:"}
+}
+
+// Close closes the Redis connection
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// Incr implements Store
+func (s *RedisStore) Incr(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	res, err := incrScript.Run(ctx, s.client, []string{s.prefix + key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(res) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rate limit script result %v", res)
+	}
+	return res[0], time.Duration(res[1]) * time.Millisecond, nil
+}
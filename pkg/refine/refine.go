@@ -0,0 +1,162 @@
+// Package refine keeps the state of interactive refinement sessions: the
+// original request, the constraints a client has added ("only US data"), and
+// the pool of verified statistics gathered so far, so refinements re-filter
+// existing results before searching again.
+package refine
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// DefaultTTL is how long an idle session is kept
+const DefaultTTL = time.Hour
+
+// ErrNotFound is returned for unknown or expired sessions
+var ErrNotFound = errors.New("refinement session not found or expired")
+
+// Session is the state of one refinement conversation
+type Session struct {
+	ID          string
+	Request     models.OrchestrationRequest
+	Constraints []string           // Constraints applied so far, in order
+	Pool        []models.Statistic // Every verified statistic found in this session
+	Results     []models.Statistic // Statistics of the latest response, for paging through it
+	UpdatedAt   time.Time
+}
+
+// Store is an in-memory session store with idle expiry
+type Store struct {
+	ttl      time.Duration
+	mu       sync.Mutex
+	sessions map[string]*Session
+	updates  map[string]*update // Held by the refinement updating a session
+}
+
+// update serializes the refinements of one session
+type update struct {
+	mu      sync.Mutex
+	waiters int // Refinements holding or waiting for mu
+}
+
+// NewStore creates a store that expires sessions idle for longer than ttl
+func NewStore(ttl time.Duration) *Store {
+	return &Store{ttl: ttl, sessions: make(map[string]*Session), updates: make(map[string]*update)}
+}
+
+// Lock waits until no other refinement is updating a session and returns
+// the function that releases it. A refinement holds it from Get to Save so
+// concurrent refinements of a session apply one after the other instead of
+// the later Save discarding the earlier one's constraint and statistics.
+func (s *Store) Lock(id string) (unlock func()) {
+	s.mu.Lock()
+	u, ok := s.updates[id]
+	if !ok {
+		u = &update{}
+		s.updates[id] = u
+	}
+	u.waiters++
+	s.mu.Unlock()
+
+	u.mu.Lock()
+	return func() {
+		u.mu.Unlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if u.waiters--; u.waiters == 0 {
+			delete(s.updates, id)
+		}
+	}
+}
+
+// Create starts a session from an orchestration request and its results
+func (s *Store) Create(req models.OrchestrationRequest, stats []models.Statistic) *Session {
+	sess := &Session{
+		ID:        newID(),
+		Request:   req,
+		Pool:      append([]models.Statistic(nil), stats...),
+		Results:   append([]models.Statistic(nil), stats...),
+		UpdatedAt: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+	s.sessions[sess.ID] = sess
+	return sess
+}
+
+// Get returns a copy of a live session
+func (s *Store) Get(id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *sess
+	copied.Constraints = append([]string(nil), sess.Constraints...)
+	copied.Pool = append([]models.Statistic(nil), sess.Pool...)
+	copied.Results = append([]models.Statistic(nil), sess.Results...)
+	return &copied, nil
+}
+
+// Save stores an updated session and refreshes its expiry
+func (s *Store) Save(sess *Session) {
+	sess.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sess.ID] = sess
+}
+
+// pruneLocked drops expired sessions. The caller must hold s.mu.
+func (s *Store) pruneLocked() {
+	cutoff := time.Now().Add(-s.ttl)
+	for id, sess := range s.sessions {
+		if sess.UpdatedAt.Before(cutoff) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// Merge appends statistics not already in the pool, matched by source URL,
+// name, and value, and returns the updated pool and the newly added statistics
+func Merge(pool, found []models.Statistic) (merged, added []models.Statistic) {
+	seen := make(map[string]bool, len(pool))
+	for _, stat := range pool {
+		seen[key(stat)] = true
+	}
+	merged = pool
+	for _, stat := range found {
+		k := key(stat)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		merged = append(merged, stat)
+		added = append(added, stat)
+	}
+	return merged, added
+}
+
+// key identifies a statistic within a pool
+func key(stat models.Statistic) string {
+	return fmt.Sprintf("%s|%s|%v", stat.SourceURL, strings.ToLower(stat.Name), stat.Value)
+}
+
+// newID returns a random session ID
+func newID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "ref_" + hex.EncodeToString(b)
+}
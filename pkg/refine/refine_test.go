@@ -0,0 +1,95 @@
+package refine
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestStoreLifecycle(t *testing.T) {
+	store := NewStore(time.Hour)
+	req := models.OrchestrationRequest{Topic: "EV sales", MinVerifiedStats: 5}
+	stats := []models.Statistic{{Name: "EV sales share", Value: 18, SourceURL: "https://iea.org/ev"}}
+
+	sess := store.Create(req, stats)
+
+	got, err := store.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(got.Results) != 1 {
+		t.Errorf("Create() results = %v, want the statistics it was given", got.Results)
+	}
+	got.Constraints = append(got.Constraints, "only US data")
+	if again, _ := store.Get(sess.ID); len(again.Constraints) != 0 {
+		t.Error("Get() returned a session sharing state with the store")
+	}
+
+	store.Save(got)
+	if again, _ := store.Get(sess.ID); len(again.Constraints) != 1 {
+		t.Errorf("Save() constraints = %v, want 1", again.Constraints)
+	}
+
+	if _, err := store.Get("ref_unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(unknown) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreLockSerializesUpdates(t *testing.T) {
+	store := NewStore(time.Hour)
+	sess := store.Create(models.OrchestrationRequest{Topic: "EV sales"}, nil)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			unlock := store.Lock(sess.ID)
+			defer unlock()
+			got, err := store.Get(sess.ID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			got.Constraints = append(got.Constraints, fmt.Sprintf("constraint %d", i))
+			time.Sleep(time.Millisecond)
+			store.Save(got)
+		})
+	}
+	wg.Wait()
+
+	if got, _ := store.Get(sess.ID); len(got.Constraints) != 20 {
+		t.Errorf("constraints after concurrent updates = %d, want 20", len(got.Constraints))
+	}
+	if len(store.updates) != 0 {
+		t.Errorf("%d session locks left after every update finished", len(store.updates))
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	store := NewStore(time.Minute)
+	sess := store.Create(models.OrchestrationRequest{Topic: "x"}, nil)
+
+	sess.UpdatedAt = time.Now().Add(-2 * time.Minute)
+	store.sessions[sess.ID] = sess
+
+	if _, err := store.Get(sess.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(expired) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	pool := []models.Statistic{{Name: "A", Value: 1, SourceURL: "https://a"}}
+	found := []models.Statistic{
+		{Name: "a", Value: 1, SourceURL: "https://a"}, // Same statistic, different case
+		{Name: "B", Value: 2, SourceURL: "https://b"},
+		{Name: "B", Value: 2, SourceURL: "https://b"},
+	}
+
+	merged, added := Merge(pool, found)
+	if len(merged) != 2 || len(added) != 1 || added[0].Name != "B" {
+		t.Errorf("Merge() = %d merged, added %+v", len(merged), added)
+	}
+}
@@ -0,0 +1,235 @@
+// Package replay records LLM responses as golden fixture files and replays
+// them, so agent pipelines can be tested deterministically without API keys.
+//
+// Fixtures are JSON files named by a hash of the provider, model, and
+// prompt, so switching models records new fixtures. Each stores the
+// prompt as readable text next to the response, so a prompt change shows up
+// in a diff as a new fixture and a changed model answer as an edited one.
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/logging"
+)
+
+// Modes of a replay model
+const (
+	ModeRecord = "record" // Call the provider and save every response
+	ModeReplay = "replay" // Answer from fixtures only; a missing fixture is an error
+)
+
+// keyLen is the number of hex characters of the fixture hash used as file name
+const keyLen = 16
+
+// ErrNoFixture is returned in replay mode when no fixture matches a prompt
+var ErrNoFixture = errors.New("no recorded fixture for prompt")
+
+// Fixture is one recorded LLM call
+type Fixture struct {
+	Key       string     `json:"key"`
+	Provider  string     `json:"provider,omitempty"`
+	Model     string     `json:"model,omitempty"`
+	Prompt    string     `json:"prompt"`
+	Responses []Response `json:"responses"`
+}
+
+// Response is one non-partial response of a recorded call
+type Response struct {
+	Content      *genai.Content                              `json:"content,omitempty"`
+	Usage        *genai.GenerateContentResponseUsageMetadata `json:"usage,omitempty"`
+	FinishReason genai.FinishReason                          `json:"finish_reason,omitempty"`
+}
+
+// Model records or replays the calls of a wrapped model
+type Model struct {
+	llm       model.LLM
+	mode      string
+	dir       string
+	provider  string
+	modelName string
+}
+
+// Wrap returns a model that records the responses of m to dir or, in replay
+// mode, answers from the fixtures in dir. m may be nil in replay mode.
+func Wrap(m model.LLM, mode, dir, provider, modelName string) (*Model, error) {
+	switch mode {
+	case ModeRecord:
+		if m == nil {
+			return nil, errors.New("record mode requires a model")
+		}
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+		}
+	case ModeReplay:
+	default:
+		return nil, fmt.Errorf("invalid replay mode %q (valid: %s, %s)", mode, ModeRecord, ModeReplay)
+	}
+	return &Model{llm: m, mode: mode, dir: dir, provider: provider, modelName: modelName}, nil
+}
+
+// Name implements model.LLM
+func (m *Model) Name() string {
+	if m.llm != nil {
+		return m.llm.Name()
+	}
+	return m.modelName
+}
+
+// GenerateContent implements model.LLM
+func (m *Model) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	prompt := PromptText(req)
+	key := Key(m.provider, m.modelName, prompt)
+
+	if m.mode == ModeReplay {
+		return m.replay(key)
+	}
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		fixture := Fixture{Key: key, Provider: m.provider, Model: m.modelName, Prompt: prompt}
+		complete := true
+		for resp, err := range m.llm.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				complete = false
+			} else if resp != nil && !resp.Partial {
+				fixture.Responses = append(fixture.Responses, Response{
+					Content:      resp.Content,
+					Usage:        resp.UsageMetadata,
+					FinishReason: resp.FinishReason,
+				})
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+		// Failed calls are not recorded so a retry can record a good response
+		if complete {
+			if err := m.save(fixture); err != nil {
+				logging.FromContext(ctx).Warn("failed to record LLM fixture", "key", key, "error", err)
+			}
+		}
+	}
+}
+
+// replay yields the recorded responses of a prompt
+func (m *Model) replay(key string) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		fixture, err := m.load(key)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, r := range fixture.Responses {
+			resp := &model.LLMResponse{
+				Content:       r.Content,
+				UsageMetadata: r.Usage,
+				FinishReason:  r.FinishReason,
+				ModelVersion:  fixture.Model,
+				TurnComplete:  true,
+			}
+			if !yield(resp, nil) {
+				return
+			}
+		}
+	}
+}
+
+// path returns the fixture file of a key
+func (m *Model) path(key string) string {
+	return filepath.Join(m.dir, key+".json")
+}
+
+// load reads the fixture of a key
+func (m *Model) load(key string) (*Fixture, error) {
+	data, err := os.ReadFile(m.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w %s in %s (record it with LLM_REPLAY_MODE=record)", ErrNoFixture, key, m.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", m.path(key), err)
+	}
+	return &fixture, nil
+}
+
+// save writes a fixture atomically so concurrent calls never leave a partial file
+func (m *Model) save(fixture Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(m.dir, fixture.Key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), m.path(fixture.Key))
+}
+
+// Key returns the fixture key of a prompt sent to a provider's model. The
+// parts are NUL-separated so they cannot run into each other.
+func Key(provider, modelName, prompt string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + modelName + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])[:keyLen]
+}
+
+// PromptText renders a request as readable text: the system instruction and
+// each content's role and parts. Function call IDs are left out because
+// they are generated per run and would change the key.
+func PromptText(req *model.LLMRequest) string {
+	var b strings.Builder
+	if req.Config != nil && req.Config.SystemInstruction != nil {
+		writeContent(&b, "system", req.Config.SystemInstruction)
+	}
+	for _, c := range req.Contents {
+		if c != nil {
+			writeContent(&b, c.Role, c)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// writeContent appends one content block to a prompt text
+func writeContent(b *strings.Builder, role string, c *genai.Content) {
+	if role == "" {
+		role = "user"
+	}
+	fmt.Fprintf(b, "[%s]\n", role)
+	for _, part := range c.Parts {
+		switch {
+		case part == nil:
+		case part.Text != "":
+			b.WriteString(part.Text)
+			b.WriteString("\n")
+		case part.FunctionCall != nil:
+			args, _ := json.Marshal(part.FunctionCall.Args)
+			fmt.Fprintf(b, "call %s(%s)\n", part.FunctionCall.Name, args)
+		case part.FunctionResponse != nil:
+			resp, _ := json.Marshal(part.FunctionResponse.Response)
+			fmt.Fprintf(b, "response %s: %s\n", part.FunctionResponse.Name, resp)
+		}
+	}
+}
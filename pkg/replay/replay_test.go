@@ -0,0 +1,120 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// fakeModel answers every prompt with a fixed text and counts its calls
+type fakeModel struct {
+	answer string
+	calls  int
+}
+
+func (f *fakeModel) Name() string { return "fake" }
+
+func (f *fakeModel) GenerateContent(_ context.Context, _ *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	f.calls++
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{
+			Content:       genai.NewContentFromText(f.answer, genai.RoleModel),
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5},
+		}, nil)
+	}
+}
+
+// generate returns the concatenated text of a model's responses
+func generate(t *testing.T, m model.LLM, prompt string) (string, error) {
+	t.Helper()
+	var text string
+	for resp, err := range m.GenerateContent(context.Background(), &model.LLMRequest{Contents: genai.Text(prompt)}, false) {
+		if err != nil {
+			return "", err
+		}
+		for _, part := range resp.Content.Parts {
+			text += part.Text
+		}
+	}
+	return text, nil
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	fake := &fakeModel{answer: `[{"name": "x", "value": 1}]`}
+
+	recorder, err := Wrap(fake, ModeRecord, dir, "gemini", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := generate(t, recorder, "extract statistics"); err != nil || got != fake.answer {
+		t.Fatalf("record: got %q, %v", got, err)
+	}
+
+	data, err := os.ReadFile(recorder.path(Key("gemini", "gemini-2.5-flash", "[user]\nextract statistics")))
+	if err != nil {
+		t.Fatalf("fixture not written: %v", err)
+	}
+	if !strings.Contains(string(data), `"prompt": "[user]\nextract statistics"`) {
+		t.Errorf("fixture does not contain the prompt:\n%s", data)
+	}
+
+	player, err := Wrap(nil, ModeReplay, dir, "gemini", "gemini-2.5-flash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := generate(t, player, "extract statistics"); err != nil || got != fake.answer {
+		t.Errorf("replay: got %q, %v", got, err)
+	}
+	if fake.calls != 1 {
+		t.Errorf("provider called %d times, want 1", fake.calls)
+	}
+
+	if _, err := generate(t, player, "a changed prompt"); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("replay of unknown prompt: err = %v, want ErrNoFixture", err)
+	}
+
+	other, err := Wrap(nil, ModeReplay, dir, "gemini", "gemini-2.5-pro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generate(t, other, "extract statistics"); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("replay with another model: err = %v, want ErrNoFixture", err)
+	}
+}
+
+func TestPromptTextIgnoresCallIDs(t *testing.T) {
+	request := func(id string) *model.LLMRequest {
+		return &model.LLMRequest{
+			Config: &genai.GenerateContentConfig{SystemInstruction: genai.NewContentFromText("be brief", "")},
+			Contents: []*genai.Content{
+				genai.NewContentFromText("find statistics", genai.RoleUser),
+				{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: id, Name: "web_search", Args: map[string]any{"topic": "wages"}}}}},
+			},
+		}
+	}
+
+	a, b := PromptText(request("adk-1")), PromptText(request("adk-2"))
+	if a != b {
+		t.Errorf("prompt text depends on call ID:\n%s\n%s", a, b)
+	}
+	want := "[system]\nbe brief\n[user]\nfind statistics\n[model]\ncall web_search({\"topic\":\"wages\"})"
+	if a != want {
+		t.Errorf("PromptText() =\n%s\nwant\n%s", a, want)
+	}
+}
+
+func TestWrapInvalidMode(t *testing.T) {
+	if _, err := Wrap(&fakeModel{}, "replay-all", t.TempDir(), "", ""); err == nil {
+		t.Error("Wrap() accepted an invalid mode")
+	}
+	if _, err := Wrap(nil, ModeRecord, t.TempDir(), "", ""); err == nil {
+		t.Error("Wrap() accepted record mode without a model")
+	}
+}
@@ -0,0 +1,118 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+)
+
+// Handler returns the /reports HTTP handler:
+//
+//	POST /reports       render a POSTed OrchestrationResponse
+//	GET  /reports/{id}  a report saved under REPORT_DIR (store may be nil)
+//
+// Both accept ?format=html (default) or ?format=markdown. Tenants only read
+// their own saved reports.
+func Handler(store *Store, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/reports"), "/")
+
+		name := r.URL.Query().Get("format")
+		if name == "" {
+			name = string(FormatHTML)
+		}
+		format, err := ParseFormat(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case id == "" && r.Method == http.MethodPost:
+			var resp models.OrchestrationResponse
+			if err := migrate.Decode(r.Body, &resp); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", format.ContentType())
+			if err := Write(w, &resp, format); err != nil {
+				logger.Error("failed to write report", "format", format, "error", err)
+			}
+
+		case id != "" && r.Method == http.MethodGet:
+			if store == nil {
+				http.Error(w, "reports are not saved; set REPORT_DIR", http.StatusNotFound)
+				return
+			}
+			data, err := store.Read(r.Context(), id, format)
+			if errors.Is(err, ErrNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				logger.Error("failed to read report", "id", id, "error", err)
+				http.Error(w, "failed to read report", http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", format.ContentType())
+			if _, err := w.Write(data); err != nil {
+				logger.Error("failed to write report", "id", id, "error", err)
+			}
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// JobHandler returns the handler of GET /jobs/{id}/report, which renders
+// the report of a succeeded job in ?format=html (default) or
+// ?format=markdown. Tenants only see their own jobs.
+func JobHandler(jobs *jobqueue.Runner, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if jobs == nil {
+			http.Error(w, "job queue is not configured; set JOB_QUEUE", http.StatusNotFound)
+			return
+		}
+		name := r.URL.Query().Get("format")
+		if name == "" {
+			name = string(FormatHTML)
+		}
+		format, err := ParseFormat(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id := r.PathValue("id")
+		job, err := jobs.Job(r.Context(), id)
+		if errors.Is(err, jobqueue.ErrNotFound) {
+			http.Error(w, jobqueue.ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.Error("failed to load job", "job_id", id, "error", err)
+			http.Error(w, "failed to load job", http.StatusInternalServerError)
+			return
+		}
+		if job.Response == nil {
+			http.Error(w, fmt.Sprintf("job is %s; the report is available once it succeeds", job.Status), http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		if err := Write(w, job.Response, format); err != nil {
+			logger.Error("failed to write report", "job_id", id, "error", err)
+		}
+	}
+}
@@ -0,0 +1,539 @@
+// Package report renders a self-contained verification report for an
+// orchestration run: methodology, every verified statistic with its source
+// and evidence, and every candidate that failed verification with the
+// reason. Reports are Markdown or single-file HTML (no external assets), for
+// users who need an auditable record of how each number was checked.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Format is a report format
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// Formats lists the supported report formats
+var Formats = []Format{FormatMarkdown, FormatHTML}
+
+// ParseFormat validates a format name; "md" is accepted for Markdown
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("unsupported report format: %q (supported: markdown, html)", s)
+}
+
+// ContentType returns the MIME type for a format
+func (f Format) ContentType() string {
+	if f == FormatHTML {
+		return "text/html; charset=utf-8"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+// Extension returns the file extension for a format
+func (f Format) Extension() string {
+	if f == FormatHTML {
+		return ".html"
+	}
+	return ".md"
+}
+
+// Write renders the report of an orchestration response in the given format
+func Write(w io.Writer, resp *models.OrchestrationResponse, f Format) error {
+	v := newView(resp)
+	switch f {
+	case FormatMarkdown:
+		_, err := io.WriteString(w, v.markdown())
+		return err
+	case FormatHTML:
+		return htmlTemplate.Execute(w, v)
+	default:
+		return fmt.Errorf("unsupported report format: %q", f)
+	}
+}
+
+// view is the content of a report, shared by both formats
+type view struct {
+	Topic       string
+	Generated   string
+	Summary     []row
+	Methodology []string
+	StatsTitle  string // "Verified Statistics", or "Statistics" for unverified direct search
+	Narrative   *models.Narrative
+	Statistics  []statView
+	Series      []models.Series
+	Failures    []failureView
+}
+
+type row struct {
+	Label string
+	Value string
+}
+
+type statView struct {
+	Name         string
+	Value        string
+	Type         string
+	Source       string
+	SourceURL    string
+	Excerpt      string
+	Found        string
+	Location     string // Cell location in a data file or table
+	Figure       string // Image the statistic was read from by a vision model
+	Methodology  string // Sample size, period, and method stated in the source
+	Published    string // Publisher and publication date the page declares
+	QuotedBy     *models.QuotedSource
+	AttributedTo string // Organization the page credits, when not verified there
+	Conflict     string // Conflict group and the values that contradict each other
+	ContentHash  string
+	Corroborated []models.Corroboration
+}
+
+type failureView struct {
+	Name      string
+	Value     string
+	Source    string
+	SourceURL string
+	Excerpt   string
+	Category  string
+	Reason    string
+}
+
+// newView collects the report content of a response
+func newView(resp *models.OrchestrationResponse) *view {
+	v := &view{
+		Topic:     resp.Topic,
+		Generated: time.Now().UTC().Format(time.RFC1123),
+	}
+
+	status := "Complete"
+	switch {
+	case resp.Status == models.StatusNoResults:
+		status = "No results (search found no sources)"
+	case resp.Partial:
+		status = "Partial (target not met)"
+	}
+	completed := "-"
+	if !resp.Timestamp.IsZero() {
+		completed = resp.Timestamp.UTC().Format(time.RFC1123)
+	}
+	v.Summary = []row{
+		{"Topic", resp.Topic},
+		{"Run completed", completed},
+		{"Status", status},
+		{"Verified statistics", strconv.Itoa(resp.VerifiedCount)},
+		{"Failed verification", strconv.Itoa(resp.FailedCount)},
+		{"Candidates extracted", strconv.Itoa(resp.TotalCandidates)},
+	}
+	if resp.TargetCount > 0 {
+		v.Summary = append(v.Summary, row{"Target", strconv.Itoa(resp.TargetCount)})
+	}
+	if resp.Query != "" {
+		v.Summary = append(v.Summary, row{"Relaxed search query", resp.Query})
+	}
+	if resp.DuplicatesMerged > 0 {
+		v.Summary = append(v.Summary, row{"Duplicates merged", strconv.Itoa(resp.DuplicatesMerged)})
+	}
+	if len(resp.Conflicts) > 0 {
+		v.Summary = append(v.Summary, row{"Conflict groups", strconv.Itoa(len(resp.Conflicts))})
+	}
+	if c := resp.CostSummary; c != nil && c.Calls > 0 {
+		v.Summary = append(v.Summary, row{"LLM usage", fmt.Sprintf("%d calls, %d tokens, ~$%.4f", c.Calls, c.TotalTokens, c.EstimatedCostUSD)})
+	}
+	if t := resp.Timings; t != nil {
+		v.Summary = append(v.Summary, row{"Time", fmt.Sprintf("%.1fs total: search %.1fs, fetch %.1fs, extraction %.1fs, verification %.1fs, %d retries",
+			seconds(t.TotalMS), seconds(t.SearchMS), seconds(t.FetchMS), seconds(t.ExtractionMS), seconds(t.VerificationMS), t.Retries)})
+	}
+	if h := resp.Honesty; h != nil {
+		v.Summary = append(v.Summary, row{"Honesty", fmt.Sprintf("%.0f%% (%d/%d excerpts found, %d/%d URLs resolved, %d fabricated)",
+			h.Score*100, h.ExcerptsFound, h.Checked, h.URLsResolved, h.Checked, h.Fabricated)})
+	}
+
+	v.Methodology = methodology(resp)
+	v.Narrative = resp.Summary
+	v.Series = resp.Series
+	v.StatsTitle = "Verified Statistics"
+	if resp.Honesty != nil {
+		v.StatsTitle = "Statistics"
+	}
+
+	conflicts := make(map[string]models.Conflict, len(resp.Conflicts))
+	for _, c := range resp.Conflicts {
+		conflicts[c.ID] = c
+	}
+
+	for _, stat := range resp.Statistics {
+		sv := statView{
+			Name:         stat.Name,
+			Value:        models.FormatValue(stat.Value, stat.Unit),
+			Type:         string(stat.Type),
+			Source:       stat.Source,
+			SourceURL:    stat.SourceURL,
+			Excerpt:      stat.Excerpt,
+			ContentHash:  stat.ContentHash,
+			Corroborated: stat.CorroboratedBy,
+		}
+		if !stat.DateFound.IsZero() {
+			sv.Found = stat.DateFound.UTC().Format("2006-01-02")
+		}
+		if p := stat.Provenance; p != nil {
+			sv.Location = location(p)
+		}
+		if stat.OCRDerived {
+			sv.Figure = stat.ImageURL
+		}
+		sv.Methodology = stat.Methodology.String()
+		sv.Published = published(stat)
+		if a := stat.Attribution; a != nil && a.Verified {
+			sv.QuotedBy = a.CitedBy
+		} else if a != nil {
+			sv.AttributedTo = a.Organization + " (not verified: " + a.Reason + ")"
+		}
+		if c, ok := conflicts[stat.ConflictGroup]; ok {
+			sv.Conflict = c.ID + ": " + strings.Join(c.Values, " vs ")
+		}
+		v.Statistics = append(v.Statistics, sv)
+	}
+
+	for _, r := range resp.Rejected {
+		fv := failureView{Category: string(r.Category), Reason: r.Reason}
+		if s := r.Statistic; s != nil {
+			fv.Name, fv.Value = s.Name, models.FormatValue(s.Value, s.Unit)
+			fv.Source, fv.SourceURL, fv.Excerpt = s.Source, s.SourceURL, s.Excerpt
+		}
+		v.Failures = append(v.Failures, fv)
+	}
+	return v
+}
+
+// methodology describes how the statistics in resp were found and checked
+func methodology(resp *models.OrchestrationResponse) []string {
+	if resp.Honesty != nil {
+		return []string{
+			"Statistics were produced by a direct LLM search, which answers from the model's own knowledge rather than from fetched pages.",
+			"Each cited source URL was then fetched to check that it resolves and that the quoted excerpt appears in it; the honesty score is the share of excerpts found.",
+			"Statistics marked unverified have not been confirmed against their sources and should be checked before use.",
+		}
+	}
+	steps := []string{
+		"Search: a web search provider was queried for pages about the topic.",
+		"Extraction: an LLM read each source page and extracted candidate statistics, each with a verbatim excerpt and the page URL.",
+		"Verification: each source was fetched again and the excerpt and value checked against its text, by exact match, fuzzy match, cell lookup for data files and tables, or an LLM check of the surrounding passage. Only candidates that passed are reported as verified.",
+	}
+	if resp.DuplicatesMerged > 0 {
+		steps = append(steps, "Deduplication: statistics reported by several sources were merged, keeping the other sources as corroboration.")
+	}
+	if slices.ContainsFunc(resp.Statistics, func(s models.Statistic) bool { return s.OCRDerived }) {
+		steps = append(steps, "Figures: statistics marked as read from a figure were read from a chart or infographic image by a vision model; the excerpt is the image's text. Each was verified by checking that the page still shows the image and that a second reading gives exactly the same value.")
+	}
+	if len(resp.Conflicts) > 0 {
+		steps = append(steps, "Conflicts: statistics from different sources that give different values for the same metric and period were flagged with a shared conflict group; check which source is authoritative before using them.")
+	}
+	return steps
+}
+
+// seconds converts milliseconds to seconds
+func seconds(ms int64) float64 {
+	return float64(ms) / 1000
+}
+
+// location describes where in a data file a statistic was read
+func location(p *models.Provenance) string {
+	parts := []string{strings.ToUpper(p.Format)}
+	if p.Sheet != "" {
+		parts = append(parts, p.Sheet)
+	}
+	parts = append(parts, fmt.Sprintf("row %d", p.Row))
+	if p.Column != "" {
+		parts = append(parts, "column "+strconv.Quote(p.Column))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// published describes who published a statistic's source and when, e.g.
+// "U.S. Energy Information Administration, 2024-06-20"
+func published(stat models.Statistic) string {
+	var parts []string
+	if stat.Publisher != "" {
+		parts = append(parts, stat.Publisher)
+	}
+	if !stat.PublishedAt.IsZero() {
+		parts = append(parts, stat.PublishedAt.UTC().Format("2006-01-02"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// markdown renders the view as Markdown
+func (v *view) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Verification Report: %s\n\n", mdText(v.Topic))
+	fmt.Fprintf(&b, "_Generated %s_\n\n", v.Generated)
+
+	b.WriteString("## Summary\n\n| | |\n|---|---|\n")
+	for _, r := range v.Summary {
+		fmt.Fprintf(&b, "| %s | %s |\n", r.Label, mdCell(r.Value))
+	}
+
+	b.WriteString("\n## Methodology\n\n")
+	for i, step := range v.Methodology {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+
+	if n := v.Narrative; n != nil {
+		b.WriteString("\n## Summary\n\n_Written by an LLM from the statistics below; it is not itself verified. Numbers in brackets cite the statistics._\n\n")
+		fmt.Fprintf(&b, "%s\n\n", mdText(n.Text))
+		for _, c := range n.Citations {
+			fmt.Fprintf(&b, "%d. %s: %s, %s\n", c.Marker, mdText(c.Name), models.FormatValue(c.Value, c.Unit), mdLink(c.Source, c.SourceURL))
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## %s (%d)\n\n", v.StatsTitle, len(v.Statistics))
+	if len(v.Statistics) == 0 {
+		b.WriteString("No statistics were verified.\n")
+	}
+	for i, s := range v.Statistics {
+		fmt.Fprintf(&b, "### %d. %s\n\n", i+1, mdText(s.Name))
+		fmt.Fprintf(&b, "- **Value:** %s\n", mdText(s.Value))
+		if s.Type != "" {
+			fmt.Fprintf(&b, "- **Type:** %s\n", mdText(s.Type))
+		}
+		fmt.Fprintf(&b, "- **Source:** %s\n", mdLink(s.Source, s.SourceURL))
+		if s.Location != "" {
+			fmt.Fprintf(&b, "- **Location:** %s\n", mdText(s.Location))
+		}
+		if s.Figure != "" {
+			fmt.Fprintf(&b, "- **Read from figure:** %s\n", mdLink("", s.Figure))
+		}
+		if s.Published != "" {
+			fmt.Fprintf(&b, "- **Published:** %s\n", mdText(s.Published))
+		}
+		if q := s.QuotedBy; q != nil {
+			fmt.Fprintf(&b, "- **Quoted by:** %s\n", mdLink(q.Source, q.SourceURL))
+		}
+		if s.AttributedTo != "" {
+			fmt.Fprintf(&b, "- **Attributed to:** %s\n", mdText(s.AttributedTo))
+		}
+		if s.Conflict != "" {
+			fmt.Fprintf(&b, "- **Conflict:** %s\n", mdText(s.Conflict))
+		}
+		if s.Methodology != "" {
+			fmt.Fprintf(&b, "- **Methodology:** %s\n", mdText(s.Methodology))
+		}
+		if s.ContentHash != "" {
+			fmt.Fprintf(&b, "- **Verified content:** `%s`\n", s.ContentHash)
+		}
+		if s.Found != "" {
+			fmt.Fprintf(&b, "- **Found:** %s\n", s.Found)
+		}
+		for _, c := range s.Corroborated {
+			fmt.Fprintf(&b, "- **Corroborated by:** %s (similarity %.2f)\n", mdLink(c.Source, c.SourceURL), c.Similarity)
+		}
+		if s.Excerpt != "" {
+			fmt.Fprintf(&b, "\n> %s\n", mdText(s.Excerpt))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(v.Series) > 0 {
+		b.WriteString("## Data Series\n\n")
+	}
+	for _, s := range v.Series {
+		fmt.Fprintf(&b, "### %s\n\n%s\n\n| Year | Value |\n|---|---|\n", mdText(s.Metric), mdLink(s.Source, s.SourceURL))
+		for _, p := range s.Points {
+			fmt.Fprintf(&b, "| %d | %s |\n", p.Year, mdCell(models.FormatValue(p.Value, s.Unit)))
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "## Failed Verification (%d)\n\n", len(v.Failures))
+	if len(v.Failures) == 0 {
+		b.WriteString("No candidates failed verification.\n")
+		return b.String()
+	}
+	b.WriteString("| Statistic | Value | Source | Category | Reason |\n|---|---|---|---|---|\n")
+	for _, f := range v.Failures {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			mdCell(f.Name), mdCell(f.Value), mdCell(mdLink(f.Source, f.SourceURL)), mdCell(f.Category), mdCell(f.Reason))
+	}
+	return b.String()
+}
+
+// mdText collapses whitespace so text stays on one Markdown line
+func mdText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// mdCell escapes text for a Markdown table cell
+func mdCell(s string) string {
+	if s = mdText(s); s == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// mdLink renders a Markdown link, falling back to the URL or name alone
+func mdLink(name, rawURL string) string {
+	name = strings.NewReplacer("[", `\[`, "]", `\]`).Replace(mdText(name))
+	switch {
+	case rawURL == "":
+		return name
+	case name == "":
+		return "<" + rawURL + ">"
+	default:
+		return "[" + name + "](<" + rawURL + ">)"
+	}
+}
+
+// htmlTemplate renders the view as a single HTML file with inline styles
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc":         func(i int) int { return i + 1 },
+	"formatValue": models.FormatValue,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Verification Report: {{.Topic}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2rem auto; padding: 0 1rem; color: #1f2328; line-height: 1.5; }
+h1 { font-size: 1.6rem; border-bottom: 1px solid #d0d7de; padding-bottom: .3rem; }
+h2 { font-size: 1.25rem; margin-top: 2rem; border-bottom: 1px solid #d0d7de; padding-bottom: .2rem; }
+table { border-collapse: collapse; width: 100%; font-size: .9rem; }
+th, td { border: 1px solid #d0d7de; padding: .35rem .6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.stat { border: 1px solid #d0d7de; border-radius: 6px; padding: .6rem 1rem; margin: .8rem 0; }
+.stat h3 { font-size: 1rem; margin: .2rem 0 .4rem; }
+.stat dl { display: grid; grid-template-columns: max-content 1fr; gap: .15rem 1rem; margin: 0; font-size: .9rem; }
+.stat dt { font-weight: 600; }
+.stat dd { margin: 0; overflow-wrap: anywhere; }
+blockquote { margin: .6rem 0 .2rem; padding: .2rem .8rem; border-left: 4px solid #2da44e; color: #57606a; }
+code { font-size: .8rem; }
+.muted { color: #57606a; }
+</style>
+</head>
+<body>
+<h1>Verification Report: {{.Topic}}</h1>
+<p class="muted">Generated {{.Generated}}</p>
+
+<h2>Summary</h2>
+<table>
+{{- range .Summary}}
+<tr><th>{{.Label}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Methodology</h2>
+<ol>
+{{- range .Methodology}}
+<li>{{.}}</li>
+{{- end}}
+</ol>
+
+{{- with .Narrative}}
+
+<h2>Summary</h2>
+<p class="muted">Written by an LLM from the statistics below; it is not itself verified. Numbers in brackets cite the statistics.</p>
+<p>{{.Text}}</p>
+<ol>
+{{- range .Citations}}
+<li value="{{.Marker}}">{{.Name}}: {{formatValue .Value .Unit}}, {{if .SourceURL}}<a href="{{.SourceURL}}">{{or .Source .SourceURL}}</a>{{else}}{{.Source}}{{end}}</li>
+{{- end}}
+</ol>
+{{- end}}
+
+<h2>{{.StatsTitle}} ({{len .Statistics}})</h2>
+{{- if not .Statistics}}
+<p>No statistics were verified.</p>
+{{- end}}
+{{- range $i, $s := .Statistics}}
+<div class="stat">
+<h3>{{inc $i}}. {{$s.Name}}</h3>
+<dl>
+<dt>Value</dt><dd>{{$s.Value}}</dd>
+{{- if $s.Type}}
+<dt>Type</dt><dd>{{$s.Type}}</dd>
+{{- end}}
+<dt>Source</dt><dd>{{if $s.SourceURL}}<a href="{{$s.SourceURL}}">{{or $s.Source $s.SourceURL}}</a>{{else}}{{$s.Source}}{{end}}</dd>
+{{- if $s.Location}}
+<dt>Location</dt><dd>{{$s.Location}}</dd>
+{{- end}}
+{{- if $s.Figure}}
+<dt>Read from figure</dt><dd><a href="{{$s.Figure}}">{{$s.Figure}}</a></dd>
+{{- end}}
+{{- if $s.Published}}
+<dt>Published</dt><dd>{{$s.Published}}</dd>
+{{- end}}
+{{- with $s.QuotedBy}}
+<dt>Quoted by</dt><dd>{{if .SourceURL}}<a href="{{.SourceURL}}">{{or .Source .SourceURL}}</a>{{else}}{{.Source}}{{end}}</dd>
+{{- end}}
+{{- if $s.AttributedTo}}
+<dt>Attributed to</dt><dd>{{$s.AttributedTo}}</dd>
+{{- end}}
+{{- if $s.Conflict}}
+<dt>Conflict</dt><dd>{{$s.Conflict}}</dd>
+{{- end}}
+{{- if $s.Methodology}}
+<dt>Methodology</dt><dd>{{$s.Methodology}}</dd>
+{{- end}}
+{{- if $s.ContentHash}}
+<dt>Verified content</dt><dd><code>{{$s.ContentHash}}</code></dd>
+{{- end}}
+{{- if $s.Found}}
+<dt>Found</dt><dd>{{$s.Found}}</dd>
+{{- end}}
+{{- range $s.Corroborated}}
+<dt>Corroborated by</dt><dd><a href="{{.SourceURL}}">{{or .Source .SourceURL}}</a> <span class="muted">(similarity {{printf "%.2f" .Similarity}})</span></dd>
+{{- end}}
+</dl>
+{{- if $s.Excerpt}}
+<blockquote>{{$s.Excerpt}}</blockquote>
+{{- end}}
+</div>
+{{- end}}
+
+{{- if .Series}}
+
+<h2>Data Series</h2>
+{{- range .Series}}
+<h3>{{.Metric}}</h3>
+<p class="muted">{{if .SourceURL}}<a href="{{.SourceURL}}">{{or .Source .SourceURL}}</a>{{else}}{{.Source}}{{end}}</p>
+<table>
+<tr><th>Year</th><th>Value</th></tr>
+{{- $unit := .Unit}}
+{{- range .Points}}
+<tr><td>{{.Year}}</td><td>{{formatValue .Value $unit}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+
+<h2>Failed Verification ({{len .Failures}})</h2>
+{{- if .Failures}}
+<table>
+<tr><th>Statistic</th><th>Value</th><th>Source</th><th>Category</th><th>Reason</th></tr>
+{{- range .Failures}}
+<tr><td>{{.Name}}{{if .Excerpt}}<br><span class="muted">“{{.Excerpt}}”</span>{{end}}</td><td>{{.Value}}</td><td>{{if .SourceURL}}<a href="{{.SourceURL}}">{{or .Source .SourceURL}}</a>{{else}}{{.Source}}{{end}}</td><td>{{.Category}}</td><td>{{.Reason}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No candidates failed verification.</p>
+{{- end}}
+</body>
+</html>
+`))
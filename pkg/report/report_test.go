@@ -0,0 +1,193 @@
+package report
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/jobqueue"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+func testResponse() *models.OrchestrationResponse {
+	return &models.OrchestrationResponse{
+		Topic:           "remote work",
+		VerifiedCount:   1,
+		FailedCount:     1,
+		TotalCandidates: 2,
+		Timestamp:       time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Statistics: []models.Statistic{{
+			Name:          "Share of remote workers",
+			Value:         28,
+			Unit:          "%",
+			Source:        "Pew Research Center",
+			SourceURL:     "https://www.pewresearch.org/remote",
+			Excerpt:       "28% of workers <b>work</b> remotely",
+			ContentHash:   "sha256:abc",
+			Provenance:    &models.Provenance{Format: "csv", Row: 4, Column: "share"},
+			Methodology:   &models.Methodology{SampleSize: 10000, Population: "U.S. adults", CollectionMethod: "online survey"},
+			ConflictGroup: "conflict-1a2b3c4d",
+			OCRDerived:    true,
+			ImageURL:      "https://www.pewresearch.org/chart.png",
+		}},
+		Summary: &models.Narrative{
+			Text:      "28% of workers work remotely [1].",
+			Citations: []models.NarrativeCitation{{Marker: 1, Name: "Share of remote workers", Value: 28, Unit: "%", Source: "Pew Research Center", SourceURL: "https://www.pewresearch.org/remote"}},
+		},
+		Series: []models.Series{{
+			Metric: "Share of remote workers", Unit: "%", Source: "Pew Research Center", SourceURL: "https://www.pewresearch.org/remote",
+			Points: []models.SeriesPoint{{Year: 2019, Value: 6}, {Year: 2022, Value: 28}},
+		}},
+		Conflicts: []models.Conflict{{ID: "conflict-1a2b3c4d", Metric: "Share of remote workers", Values: []string{"28% (pewresearch.org)", "35% (example.com)"}}},
+		Rejected: []models.VerificationResult{{
+			Statistic: &models.Statistic{Name: "Hybrid | office days", Value: 3, SourceURL: "https://example.com/hybrid"},
+			Category:  models.FailureValueMismatch,
+			Reason:    "source states 2 days",
+		}},
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, testResponse(), FormatMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# Verification Report: remote work",
+		"### 1. Share of remote workers",
+		"- **Value:** 28%",
+		"- **Source:** [Pew Research Center](<https://www.pewresearch.org/remote>)",
+		`- **Location:** CSV, row 4, column "share"`,
+		"- **Read from figure:** <https://www.pewresearch.org/chart.png>",
+		"- **Methodology:** n=10,000 U.S. adults; online survey",
+		"- **Conflict:** conflict-1a2b3c4d: 28% (pewresearch.org) vs 35% (example.com)",
+		"| Conflict groups | 1 |",
+		"28% of workers work remotely [1].\n\n1. Share of remote workers: 28%, [Pew Research Center](<https://www.pewresearch.org/remote>)",
+		"## Data Series\n\n### Share of remote workers\n\n[Pew Research Center](<https://www.pewresearch.org/remote>)\n\n| Year | Value |\n|---|---|\n| 2019 | 6% |\n| 2022 | 28% |",
+		"## Failed Verification (1)",
+		`| Hybrid \| office days | 3 | <https://example.com/hybrid> | value_mismatch | source states 2 days |`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b strings.Builder
+	if err := Write(&b, testResponse(), FormatHTML); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.Contains(out, "28% of workers &lt;b&gt;work&lt;/b&gt; remotely") {
+		t.Error("excerpt not escaped")
+	}
+	if !strings.Contains(out, `<li value="1">Share of remote workers: 28%, <a href="https://www.pewresearch.org/remote">Pew Research Center</a></li>`) {
+		t.Error("summary citation missing")
+	}
+	if !strings.Contains(out, "<tr><td>2022</td><td>28%</td></tr>") {
+		t.Error("series table missing")
+	}
+	if !strings.Contains(out, `<a href="https://www.pewresearch.org/remote">Pew Research Center</a>`) || !strings.Contains(out, "source states 2 days") {
+		t.Errorf("unexpected HTML:\n%s", out)
+	}
+}
+
+func TestStoreHandler(t *testing.T) {
+	store, err := NewStore(&config.Config{ReportDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := testResponse()
+	store.Attach(context.Background(), resp, slog.Default())
+	if !strings.Contains(resp.ReportID, "-remote-work-") {
+		t.Fatalf("ReportID = %q", resp.ReportID)
+	}
+
+	h := Handler(store, slog.Default())
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/reports/"+resp.ReportID+"?format=md", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "# Verification Report") {
+		t.Errorf("GET report = %d %q", rec.Code, rec.Body.String())
+	}
+
+	for _, id := range []string{"missing", "..%2Fsecrets"} {
+		rec = httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, "/reports/"+id, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", id, rec.Code)
+		}
+	}
+}
+
+func TestStoreHidesOtherTenantsReports(t *testing.T) {
+	tenants, err := tenant.New([]tenant.Tenant{{Name: "a", Key: "ka"}, {Name: "b", Key: "kb"}, {Name: "ops", Key: "ko", Admin: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewStore(&config.Config{ReportDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := testResponse()
+	store.Attach(tenants.WithTenant(context.Background(), "a"), resp, slog.Default())
+
+	h := Handler(store, slog.Default())
+	for name, want := range map[string]int{"a": http.StatusOK, "b": http.StatusNotFound, "ops": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodGet, "/reports/"+resp.ReportID, nil)
+		rec := httptest.NewRecorder()
+		h(rec, req.WithContext(tenants.WithTenant(req.Context(), name)))
+		if rec.Code != want {
+			t.Errorf("GET as %s = %d, want %d", name, rec.Code, want)
+		}
+	}
+}
+
+func TestJobHandler(t *testing.T) {
+	tenants, err := tenant.New([]tenant.Tenant{{Name: "a", Key: "ka"}, {Name: "b", Key: "kb"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs := jobqueue.NewMemoryStore(0)
+	ctx := context.Background()
+	if err := jobs.Put(ctx, &models.Job{ID: "done", Status: models.JobSucceeded, Tenant: "a", Response: testResponse()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := jobs.Put(ctx, &models.Job{ID: "queued", Status: models.JobQueued, Tenant: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	runner := jobqueue.New(jobqueue.NewMemoryQueue(), jobs, nil, tenants, 0, 1, time.Minute, slog.Default())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs/{id}/report", JobHandler(runner, slog.Default()))
+	get := func(target, name string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req.WithContext(tenants.WithTenant(req.Context(), name)))
+		return rec
+	}
+
+	rec := get("/jobs/done/report?format=markdown", "a")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "# Verification Report") {
+		t.Errorf("GET report = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := get("/jobs/done/report", "a"); rec.Header().Get("Content-Type") != FormatHTML.ContentType() {
+		t.Errorf("default Content-Type = %q", rec.Header().Get("Content-Type"))
+	}
+	if rec := get("/jobs/done/report", "b"); rec.Code != http.StatusNotFound {
+		t.Errorf("another tenant's GET = %d; want 404", rec.Code)
+	}
+	if rec := get("/jobs/queued/report", "a"); rec.Code != http.StatusConflict {
+		t.Errorf("unfinished job GET = %d; want 409", rec.Code)
+	}
+	if rec := get("/jobs/done/report?format=pdf", "a"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad format GET = %d; want 400", rec.Code)
+	}
+}
@@ -0,0 +1,170 @@
+package report
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+// ErrNotFound is returned when no report exists for an ID
+var ErrNotFound = errors.New("report not found")
+
+// validID matches report IDs, which are also file names
+var validID = regexp.MustCompile(`^[a-z0-9-]{1,100}$`)
+
+// Store saves reports as files in a directory, one per format per run, with
+// the name of the tenant that ran it
+type Store struct {
+	dir string
+}
+
+// NewStore creates the store for cfg.ReportDir. It returns a nil store when
+// reports are not saved.
+func NewStore(cfg *config.Config) (*Store, error) {
+	if cfg.ReportDir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(cfg.ReportDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create report directory: %w", err)
+	}
+	return &Store{dir: cfg.ReportDir}, nil
+}
+
+// Save writes the report of resp in every format, owned by the tenant in
+// ctx, and returns its ID
+func (s *Store) Save(ctx context.Context, resp *models.OrchestrationResponse) (string, error) {
+	id := newID(resp.Topic, time.Now())
+	if t := tenant.FromContext(ctx); t != nil {
+		if err := os.WriteFile(s.tenantPath(id), []byte(t.Name), 0o640); err != nil { //nolint:gosec // G306: tenant names are not secret
+			return "", fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	for _, f := range Formats {
+		var b strings.Builder
+		if err := Write(&b, resp, f); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(s.path(id, f), []byte(b.String()), 0o640); err != nil { //nolint:gosec // G306: reports are not secret
+			return "", fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	return id, nil
+}
+
+// Read returns a saved report, or ErrNotFound for another tenant's report
+func (s *Store) Read(ctx context.Context, id string, f Format) ([]byte, error) {
+	if !validID.MatchString(id) || !s.visible(ctx, id) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(id, f))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// List returns the IDs of the saved reports, newest first, at most limit of
+// them (all when limit is 0)
+func (s *Store) List(limit int) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	var ids []string
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), FormatMarkdown.Extension()); ok && !e.IsDir() && validID.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	// IDs start with the time they were saved
+	slices.Sort(ids)
+	slices.Reverse(ids)
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// visible reports whether the caller may read a report. Tenants only read
+// their own; admins read every report.
+func (s *Store) visible(ctx context.Context, id string) bool {
+	t := tenant.FromContext(ctx)
+	if t == nil || t.Admin {
+		return true
+	}
+	owner, err := os.ReadFile(s.tenantPath(id))
+	return err == nil && string(owner) == t.Name
+}
+
+// path returns the file of a report
+func (s *Store) path(id string, f Format) string {
+	return filepath.Join(s.dir, id+f.Extension())
+}
+
+// tenantPath returns the file naming the tenant of a report
+func (s *Store) tenantPath(id string) string {
+	return filepath.Join(s.dir, id+".tenant")
+}
+
+// newID returns a sortable report ID: the UTC time, a slug of the topic, and
+// a random suffix, e.g. "20261016-153000-renewable-energy-9f2c41d0"
+func newID(topic string, now time.Time) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	parts := []string{now.UTC().Format("20060102-150405")}
+	if slug := slugify(topic, 40); slug != "" {
+		parts = append(parts, slug)
+	}
+	return strings.Join(append(parts, hex.EncodeToString(suffix)), "-")
+}
+
+// slugify lowercases s and replaces runs of other characters with hyphens
+func slugify(s string, maxLen int) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+		if b.Len() >= maxLen {
+			break
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// Attach saves the report of resp when s is not nil and records its ID in
+// resp.ReportID. A failure is logged rather than returned, since the run
+// itself succeeded.
+func (s *Store) Attach(ctx context.Context, resp *models.OrchestrationResponse, logger *slog.Logger) {
+	if s == nil {
+		return
+	}
+	id, err := s.Save(ctx, resp)
+	if err != nil {
+		logger.Warn("failed to save verification report", "error", err)
+		return
+	}
+	resp.ReportID = id
+	logger.Info("verification report saved", "id", id, "dir", s.dir)
+}
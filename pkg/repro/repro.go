@@ -0,0 +1,112 @@
+// Package repro records the intermediate artifacts of a reproducible run:
+// every request an orchestrator sends to the research, synthesis, and
+// verification agents and every response it gets back. Each artifact is
+// stored as JSON in the source archive under its content hash and listed in
+// the run's response, so a run can be audited, and its later stages replayed,
+// from exactly the inputs it saw.
+package repro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// ContentType is the content type artifacts are archived with
+const ContentType = "application/json"
+
+// Recorder collects the artifacts of one run. It is safe for concurrent use,
+// and a nil recorder records nothing.
+type Recorder struct {
+	store  archive.Store // Nil keeps only the hashes
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	artifacts []models.Artifact
+	failed    bool // Some artifact could not be stored
+}
+
+// NewRecorder creates a recorder storing artifacts in store, which may be nil
+func NewRecorder(store archive.Store, logger *slog.Logger) *Recorder {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Recorder{store: store, logger: logger}
+}
+
+// Record stores v as the next artifact of the run, named by its position
+// and kind, e.g. "03-synthesis-request". A failure to store it is logged
+// rather than returned, since the run itself can go on.
+func (r *Recorder) Record(ctx context.Context, kind string, v any) {
+	if r == nil {
+		return
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		r.logger.Warn("failed to encode run artifact", "kind", kind, "error", err)
+		return
+	}
+
+	r.mu.Lock()
+	name := fmt.Sprintf("%02d-%s", len(r.artifacts)+1, kind)
+	snap := archive.NewSnapshot("artifact:"+name, ContentType, data)
+	r.artifacts = append(r.artifacts, models.Artifact{Name: name, Hash: snap.Hash})
+	r.mu.Unlock()
+
+	if r.store == nil {
+		return
+	}
+	if _, err := r.store.Put(ctx, snap); err != nil {
+		r.logger.Warn("failed to store run artifact", "name", name, "error", err)
+		r.mu.Lock()
+		r.failed = true
+		r.mu.Unlock()
+	}
+}
+
+// Reproducibility returns what the run pinned and the artifacts it recorded
+func (r *Recorder) Reproducibility(s *models.Sampling) *models.Reproducibility {
+	if r == nil || s == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &models.Reproducibility{
+		Sampling:  *s,
+		Artifacts: append([]models.Artifact{}, r.artifacts...),
+		Stored:    r.store != nil && !r.failed,
+	}
+}
+
+// recorderKey is the context key for the recorder of a run
+type recorderKey struct{}
+
+// WithRecorder returns a context whose agent calls are recorded in r
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder carried by ctx, or nil if none is set
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Begin starts recording a reproducible run: it returns a context carrying a
+// new recorder, with the request as its first artifact, and the run's
+// pinned sampling for the LLM calls the orchestrator makes itself
+func Begin(ctx context.Context, req *models.OrchestrationRequest, store archive.Store, logger *slog.Logger) context.Context {
+	r := NewRecorder(store, logger)
+	ctx = WithRecorder(ctx, r)
+	r.Record(ctx, "request", req)
+	if store == nil {
+		r.logger.Warn("reproducible run without an archive keeps only artifact hashes; set ARCHIVE_BACKEND", "topic", req.Topic)
+	}
+	return llm.WithSampling(ctx, req.Sampling())
+}
@@ -0,0 +1,61 @@
+package repro
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+var discard = slog.New(slog.DiscardHandler)
+
+func TestBegin(t *testing.T) {
+	store, err := archive.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &models.OrchestrationRequest{Topic: "solar", Reproducible: true, Seed: 7}
+	ctx := Begin(context.Background(), req, store, discard)
+
+	if s := llm.SamplingFrom(ctx); s == nil || s.Temperature != 0 || s.Seed != 7 {
+		t.Errorf("sampling = %+v, want temperature 0 and seed 7", s)
+	}
+	FromContext(ctx).Record(ctx, "research-request", &models.ResearchRequest{Topic: "solar", Reproducible: true})
+
+	r := FromContext(ctx).Reproducibility(req.Sampling())
+	if r == nil || !r.Stored || r.Seed != 7 || len(r.Artifacts) != 2 {
+		t.Fatalf("reproducibility = %+v", r)
+	}
+	if r.Artifacts[0].Name != "01-request" || r.Artifacts[1].Name != "02-research-request" {
+		t.Errorf("artifact names = %+v", r.Artifacts)
+	}
+
+	snap, err := store.Get(context.Background(), r.Artifacts[1].Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored models.ResearchRequest
+	if err := json.Unmarshal(snap.Body, &stored); err != nil || !stored.Reproducible {
+		t.Errorf("stored artifact = %s (%v)", snap.Body, err)
+	}
+}
+
+func TestRecorderWithoutStore(t *testing.T) {
+	r := NewRecorder(nil, discard)
+	r.Record(context.Background(), "request", map[string]string{"topic": "solar"})
+	got := r.Reproducibility(&models.Sampling{})
+	if got.Stored || len(got.Artifacts) != 1 || got.Artifacts[0].Hash == "" {
+		t.Errorf("reproducibility = %+v, want one unstored artifact hash", got)
+	}
+
+	// Runs that are not reproducible carry no recorder
+	var none *Recorder
+	none.Record(context.Background(), "request", nil)
+	if FromContext(context.Background()).Reproducibility(&models.Sampling{}) != nil {
+		t.Error("expected no reproducibility without a recorder")
+	}
+}
@@ -0,0 +1,249 @@
+// Package safety screens topics and verified statistics against
+// operator-defined categories, such as sites known for medical
+// misinformation or extremist sources. A category lists domains, matched
+// with their subdomains, and terms, matched as whole words. Its action
+// either annotates what matches it, with the category's name in
+// safety_flags, or blocks it: a blocked topic is refused and a blocked
+// statistic is rejected.
+package safety
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// ErrBlocked is returned for a topic a blocking category matches
+var ErrBlocked = errors.New("blocked by safety filter")
+
+// Action is what happens to a topic or statistic a category matches
+type Action string
+
+const (
+	ActionAnnotate Action = "annotate" // Flag it and let it through
+	ActionBlock    Action = "block"    // Refuse the topic or reject the statistic
+)
+
+// Category is a named filter, as written in SAFETY_RULES_FILE
+type Category struct {
+	Name    string   `json:"name"`
+	Action  Action   `json:"action,omitempty"`  // annotate when empty
+	Domains []string `json:"domains,omitempty"` // Source domains, with their subdomains
+	Terms   []string `json:"terms,omitempty"`   // Words or phrases in a topic, excerpt, or statistic name
+}
+
+// Rules is the content of SAFETY_RULES_FILE
+type Rules struct {
+	Categories []Category `json:"categories"`
+}
+
+// Filter screens against compiled rules. A nil Filter lets everything
+// through unflagged.
+type Filter struct {
+	categories []category
+}
+
+// category is a Category ready to match
+type category struct {
+	name    string
+	action  Action
+	domains []string
+	terms   *regexp.Regexp // nil without terms
+}
+
+// FromConfig returns the filter of SAFETY_RULES_FILE, or nil when it is not
+// set
+func FromConfig(cfg *config.Config) (*Filter, error) {
+	if cfg.SafetyRulesFile == "" {
+		return nil, nil
+	}
+	return Load(cfg.SafetyRulesFile)
+}
+
+// Load reads and compiles a JSON rules file
+func Load(path string) (*Filter, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: the operator configures the file
+	if err != nil {
+		return nil, fmt.Errorf("failed to read safety rules: %w", err)
+	}
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid safety rules %s: %w", path, err)
+	}
+	return New(rules)
+}
+
+// New compiles rules. A category needs a unique name, a known action, and
+// at least one domain or term.
+func New(rules Rules) (*Filter, error) {
+	f := &Filter{}
+	seen := make(map[string]bool, len(rules.Categories))
+	for _, c := range rules.Categories {
+		name := strings.TrimSpace(c.Name)
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("invalid safety rules: category without a name")
+		case seen[name]:
+			return nil, fmt.Errorf("invalid safety rules: category %q listed twice", name)
+		}
+		seen[name] = true
+
+		cat := category{name: name, action: c.Action}
+		switch cat.action {
+		case "":
+			cat.action = ActionAnnotate
+		case ActionAnnotate, ActionBlock:
+		default:
+			return nil, fmt.Errorf("invalid safety rules: category %q has action %q, want annotate or block", name, c.Action)
+		}
+
+		for _, d := range c.Domains {
+			if d = normalizeDomain(d); d != "" {
+				cat.domains = append(cat.domains, d)
+			}
+		}
+		var terms []string
+		for _, t := range c.Terms {
+			if t = strings.TrimSpace(t); t != "" {
+				terms = append(terms, regexp.QuoteMeta(t))
+			}
+		}
+		if len(terms) > 0 {
+			// Terms match as whole words, so "war" does not match "software"
+			cat.terms = regexp.MustCompile(`(?i)(?:^|\W)(?:` + strings.Join(terms, "|") + `)(?:\W|$)`)
+		}
+		if len(cat.domains) == 0 && cat.terms == nil {
+			return nil, fmt.Errorf("invalid safety rules: category %q has no domains or terms", name)
+		}
+		f.categories = append(f.categories, cat)
+	}
+	return f, nil
+}
+
+// normalizeDomain lowercases a domain and strips a scheme, path, and www.
+func normalizeDomain(d string) string {
+	d = strings.ToLower(strings.TrimSpace(d))
+	if i := strings.Index(d, "://"); i >= 0 {
+		d = d[i+3:]
+	}
+	if i := strings.IndexAny(d, "/:"); i >= 0 {
+		d = d[:i]
+	}
+	return strings.TrimPrefix(strings.Trim(d, "."), "www.")
+}
+
+// matchesDomain reports whether host is domain or one of its subdomains
+func matchesDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// ScreenTopic checks a topic against the categories' terms. It returns the
+// annotating categories the topic matches, or an error wrapping ErrBlocked
+// when a blocking one matches.
+func (f *Filter) ScreenTopic(topic string) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var flags []string
+	for _, c := range f.categories {
+		if c.terms == nil || !c.terms.MatchString(topic) {
+			continue
+		}
+		if c.action == ActionBlock {
+			return nil, fmt.Errorf("topic %w (category %q)", ErrBlocked, c.name)
+		}
+		flags = append(flags, c.name)
+	}
+	return flags, nil
+}
+
+// ScreenRequest is ScreenTopic over a request's topic and the entities it
+// compares
+func (f *Filter) ScreenRequest(req *models.OrchestrationRequest) ([]string, error) {
+	var flags []string
+	for _, text := range append([]string{req.Topic}, req.Compare...) {
+		names, err := f.ScreenTopic(text)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !slices.Contains(flags, name) {
+				flags = append(flags, name)
+			}
+		}
+	}
+	return flags, nil
+}
+
+// match returns the categories a statistic matches, by its source's domain
+// or the terms of its excerpt and name, and whether one of them blocks it
+func (f *Filter) match(stat *models.Statistic) (names []string, block string) {
+	host := ""
+	if u, err := url.Parse(stat.SourceURL); err == nil {
+		host = normalizeDomain(u.Hostname())
+	}
+	for _, c := range f.categories {
+		matched := c.terms != nil && (c.terms.MatchString(stat.Excerpt) || c.terms.MatchString(stat.Name))
+		if !matched && host != "" {
+			matched = slices.ContainsFunc(c.domains, func(d string) bool { return matchesDomain(host, d) })
+		}
+		if !matched {
+			continue
+		}
+		names = append(names, c.name)
+		if c.action == ActionBlock && block == "" {
+			block = c.name
+		}
+	}
+	return names, block
+}
+
+// Screen applies the categories to a verification pass's verified results.
+// Every category a statistic matches is added to its safety_flags; one
+// matching a blocking category fails with category safety_blocked and the
+// response's counts are adjusted. It returns the number blocked.
+func (f *Filter) Screen(resp *models.VerificationResponse) int {
+	if f == nil || resp == nil {
+		return 0
+	}
+	blocked := 0
+	for i := range resp.Results {
+		if f.ScreenResult(&resp.Results[i]) {
+			blocked++
+		}
+	}
+	resp.Verified -= blocked
+	resp.Failed += blocked
+	return blocked
+}
+
+// ScreenResult is Screen for one result, as it streams from verification.
+// It reports whether the result was blocked.
+func (f *Filter) ScreenResult(result *models.VerificationResult) bool {
+	if f == nil || !result.Verified || result.Statistic == nil {
+		return false
+	}
+	names, block := f.match(result.Statistic)
+	if len(names) == 0 {
+		return false
+	}
+	stat := *result.Statistic
+	stat.SafetyFlags = names
+	result.Statistic = &stat
+	if block == "" {
+		return false
+	}
+	stat.Verified = false
+	result.Verified = false
+	result.Reason = fmt.Sprintf("Blocked by safety category %q", block)
+	result.Category = models.FailureSafety
+	return true
+}
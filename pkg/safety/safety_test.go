@@ -0,0 +1,134 @@
+package safety
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+var testRules = Rules{Categories: []Category{
+	{Name: "medical_misinformation", Action: ActionBlock, Domains: []string{"https://www.cures-hoax.example/"}},
+	{Name: "extremist", Action: ActionBlock, Terms: []string{"militia recruitment"}},
+	{Name: "self_harm", Terms: []string{"suicide"}},
+}}
+
+func TestScreenTopic(t *testing.T) {
+	f, err := New(testRules)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	tests := []struct {
+		topic   string
+		flags   []string
+		blocked bool
+	}{
+		{"US unemployment", nil, false},
+		{"Suicide rates among teenagers", []string{"self_harm"}, false},
+		{"Militia Recruitment online", nil, true},
+		{"suicides", nil, false}, // Terms match whole words only
+	}
+	for _, tt := range tests {
+		flags, err := f.ScreenTopic(tt.topic)
+		if errors.Is(err, ErrBlocked) != tt.blocked || !slices.Equal(flags, tt.flags) {
+			t.Errorf("ScreenTopic(%q) = %v, %v, want %v, blocked %v", tt.topic, flags, err, tt.flags, tt.blocked)
+		}
+	}
+
+	req := &models.OrchestrationRequest{Topic: "Crime rates", Compare: []string{"2010", "militia recruitment"}}
+	if _, err := f.ScreenRequest(req); !errors.Is(err, ErrBlocked) {
+		t.Errorf("ScreenRequest() with a blocked entity = %v, want ErrBlocked", err)
+	}
+}
+
+func TestScreen(t *testing.T) {
+	f, err := New(testRules)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	verified := func(url, excerpt string) models.VerificationResult {
+		return models.VerificationResult{
+			Statistic: &models.Statistic{Name: "Rate", SourceURL: url, Excerpt: excerpt, Verified: true},
+			Verified:  true,
+		}
+	}
+	resp := &models.VerificationResponse{
+		Results: []models.VerificationResult{
+			verified("https://www.cdc.gov/nchs", "The rate was 4%."),
+			verified("https://news.cures-hoax.example/article", "The rate was 4%."),
+			verified("https://www.cdc.gov/suicide", "The suicide rate was 14 per 100,000."),
+			{Statistic: &models.Statistic{SourceURL: "https://cures-hoax.example/"}, Reason: "not found"},
+		},
+		Verified: 3,
+		Failed:   1,
+	}
+	original := resp.Results[2].Statistic
+
+	if blocked := f.Screen(resp); blocked != 1 {
+		t.Fatalf("Screen() blocked %d, want 1", blocked)
+	}
+	if resp.Verified != 2 || resp.Failed != 2 {
+		t.Errorf("counts = %d verified, %d failed, want 2, 2", resp.Verified, resp.Failed)
+	}
+	if r := resp.Results[0]; !r.Verified || r.Statistic.SafetyFlags != nil {
+		t.Errorf("unmatched result = %+v", r)
+	}
+	if r := resp.Results[1]; r.Verified || r.Statistic.Verified || r.Category != models.FailureSafety ||
+		!strings.Contains(r.Reason, "medical_misinformation") {
+		t.Errorf("blocked result = %+v", r)
+	}
+	if r := resp.Results[2]; !r.Verified || !slices.Equal(r.Statistic.SafetyFlags, []string{"self_harm"}) {
+		t.Errorf("annotated result = %+v", r)
+	}
+	if original.SafetyFlags != nil {
+		t.Error("Screen() modified the caller's statistic")
+	}
+	if r := resp.Results[3]; r.Category != "" || r.Statistic.SafetyFlags != nil {
+		t.Errorf("unverified result was screened: %+v", r)
+	}
+}
+
+func TestNilFilter(t *testing.T) {
+	var f *Filter
+	if flags, err := f.ScreenTopic("militia recruitment"); flags != nil || err != nil {
+		t.Errorf("nil ScreenTopic() = %v, %v", flags, err)
+	}
+	resp := &models.VerificationResponse{Results: []models.VerificationResult{{Statistic: &models.Statistic{}, Verified: true}}, Verified: 1}
+	if f.Screen(resp) != 0 || resp.Verified != 1 {
+		t.Error("nil Filter screened a result")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	f, err := Load(write("ok.json", `{"categories": [{"name": "extremist", "action": "block", "domains": ["hate.example"]}]}`))
+	if err != nil || len(f.categories) != 1 {
+		t.Fatalf("Load(valid) = %v, %v", f, err)
+	}
+	for name, content := range map[string]string{
+		"syntax.json":  `{"categories": [`,
+		"noname.json":  `{"categories": [{"terms": ["x"]}]}`,
+		"twice.json":   `{"categories": [{"name": "a", "terms": ["x"]}, {"name": "a", "terms": ["y"]}]}`,
+		"action.json":  `{"categories": [{"name": "a", "action": "warn", "terms": ["x"]}]}`,
+		"nomatch.json": `{"categories": [{"name": "a", "terms": [" "]}]}`,
+	} {
+		if _, err := Load(write(name, content)); err == nil {
+			t.Errorf("Load(%s) succeeded", name)
+		}
+	}
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Load(missing file) succeeded")
+	}
+}
@@ -0,0 +1,26 @@
+// Command gen writes the JSON Schemas of the models into pkg/schemas/json.
+// Run it with go generate ./pkg/schemas after changing a model.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/plexusone/agent-team-stats/pkg/schemas"
+)
+
+func main() {
+	generated, err := schemas.Generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for name, data := range generated {
+		path := filepath.Join(schemas.Dir, name+".json")
+		if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // G306: schemas are public
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
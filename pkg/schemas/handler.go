@@ -0,0 +1,49 @@
+package schemas
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Index lists the published schemas
+type Index struct {
+	Schemas []string `json:"schemas"` // Paths such as /schemas/orchestration-request.json
+}
+
+// Handler returns the /schemas HTTP handler:
+//
+//	GET /schemas              the list of schemas
+//	GET /schemas/{name}.json  one schema, e.g. /schemas/orchestration-response.json
+func Handler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		file := strings.Trim(strings.TrimPrefix(r.URL.Path, "/schemas"), "/")
+		if file == "" {
+			index := Index{Schemas: make([]string, 0, len(published))}
+			for _, name := range Names() {
+				index.Schemas = append(index.Schemas, "/schemas/"+name+".json")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(index); err != nil {
+				logger.Error("failed to encode schema index", "error", err)
+			}
+			return
+		}
+
+		data, ok := Get(strings.TrimSuffix(file, ".json"))
+		if !ok {
+			http.Error(w, "unknown schema: "+file, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		if _, err := w.Write(data); err != nil {
+			logger.Error("failed to write schema", "schema", file, "error", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package schemas publishes JSON Schemas of the request and response
+// models, so clients in other languages can validate payloads and generate
+// types. The schemas are generated from pkg/models and its doc comments
+// into json/, embedded in the binary, and served at /schemas.
+package schemas
+
+//go:generate go run ./gen
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Dir is the directory, relative to this package, holding the generated schemas
+const Dir = "json"
+
+// modelsDir is pkg/models relative to this package, read for doc comments
+const modelsDir = "../models"
+
+//go:embed json/*.json
+var files embed.FS
+
+// schema names a model published as a schema
+type schema struct {
+	name     string
+	model    any
+	required []string // Fields a valid payload must set; the rest have defaults
+}
+
+// published lists the models with a schema, in the order they are listed
+var published = []schema{
+	{"orchestration-request", models.OrchestrationRequest{}, []string{"topic"}},
+	{"orchestration-response", models.OrchestrationResponse{}, nil},
+	{"research-request", models.ResearchRequest{}, []string{"topic"}},
+	{"research-response", models.ResearchResponse{}, nil},
+	{"synthesis-request", models.SynthesisRequest{}, []string{"topic", "search_results"}},
+	{"synthesis-response", models.SynthesisResponse{}, nil},
+	{"verification-request", models.VerificationRequest{}, []string{"candidates"}},
+	{"verification-response", models.VerificationResponse{}, nil},
+	{"verification-event", models.VerificationEvent{}, nil},
+	{"refine-request", models.RefineRequest{}, []string{"session_id", "constraint"}},
+	{"session-statistics", models.SessionStatistics{}, []string{"session_id", "statistics", "page"}},
+	{"factcheck-request", models.FactCheckRequest{}, []string{"claim"}},
+	{"factcheck-response", models.FactCheckResponse{}, nil},
+	{"subscription-request", models.SubscriptionRequest{}, []string{"topic", "cadence"}},
+	{"change-notification", models.ChangeNotification{}, nil},
+	{"job", models.Job{}, nil},
+	{"statistic-search-response", models.StatisticSearchResponse{}, nil},
+	{"statistic-changes-response", models.StatisticChangesResponse{}, nil},
+	{"candidate-import-request", models.CandidateImportRequest{}, []string{"candidates"}},
+	{"candidate-import-response", models.CandidateImportResponse{}, nil},
+	{"candidate-statistic", models.CandidateStatistic{}, []string{"name", "value", "source_url", "excerpt"}},
+	{"statistic", models.Statistic{}, []string{"name", "value", "source_url"}},
+}
+
+// Names returns the names of the published schemas
+func Names() []string {
+	names := make([]string, 0, len(published))
+	for _, s := range published {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// Get returns the embedded schema with the given name, without its .json
+// extension
+func Get(name string) ([]byte, bool) {
+	if !slices.Contains(Names(), name) {
+		return nil, false
+	}
+	data, err := files.ReadFile(Dir + "/" + name + ".json")
+	return data, err == nil
+}
+
+// Generate builds every schema from the models, reading doc comments from
+// the models source, and returns them by name. It must run from this
+// package's directory, as go generate and go test do.
+func Generate() (map[string][]byte, error) {
+	r := &jsonschema.Reflector{
+		// Payloads from newer agents may carry fields this version lacks
+		AllowAdditionalProperties: true,
+		// Fields are required only where listed in published
+		RequiredFromJSONSchemaTags: true,
+		Mapper: func(t reflect.Type) *jsonschema.Schema {
+			if t != reflect.TypeFor[models.Version]() {
+				return nil
+			}
+			return &jsonschema.Schema{Type: "integer", Minimum: json.Number("1")}
+		},
+		LookupComment: func(_ reflect.Type, field string) string {
+			if field != "SchemaVersion" {
+				return ""
+			}
+			return fmt.Sprintf("Schema version the payload was written with (currently %d); payloads without one are version 1", models.SchemaVersion)
+		},
+	}
+	if err := r.AddGoComments("github.com/plexusone/agent-team-stats/pkg/schemas", modelsDir); err != nil {
+		return nil, fmt.Errorf("failed to read model comments: %w", err)
+	}
+	// Doc comments are wrapped for Go source; descriptions read better unwrapped
+	for k, v := range r.CommentMap {
+		r.CommentMap[k] = strings.Join(strings.Fields(v), " ")
+	}
+
+	out := make(map[string][]byte, len(published))
+	for _, s := range published {
+		schema := r.Reflect(s.model)
+		schema.ID = jsonschema.ID(s.name + ".json")
+		if def, ok := schema.Definitions[reflect.TypeOf(s.model).Name()]; ok {
+			def.Required = s.required
+		}
+
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s schema: %w", s.name, err)
+		}
+		out[s.name] = append(data, '\n')
+	}
+	return out, nil
+}
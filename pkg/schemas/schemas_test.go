@@ -0,0 +1,63 @@
+package schemas
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestEmbeddedSchemasAreCurrent fails when a model changed without the
+// schemas being regenerated
+func TestEmbeddedSchemasAreCurrent(t *testing.T) {
+	generated, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(generated) != len(published) {
+		t.Fatalf("Generate() returned %d schemas, want %d", len(generated), len(published))
+	}
+	for name, data := range generated {
+		if embedded, ok := Get(name); !ok || !bytes.Equal(embedded, data) {
+			t.Errorf("%s.json is out of date; run go generate ./pkg/schemas", name)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := Handler(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/schemas", nil))
+	var index Index
+	if err := json.NewDecoder(rec.Body).Decode(&index); err != nil || len(index.Schemas) != len(published) {
+		t.Fatalf("GET /schemas = %v (%v), want %d schemas", index.Schemas, err, len(published))
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/schemas/orchestration-response.json", nil))
+	var schema map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&schema); err != nil {
+		t.Fatalf("schema is not JSON: %v", err)
+	}
+	if schema["$id"] != "orchestration-response.json" || rec.Header().Get("Content-Type") != "application/schema+json" {
+		t.Errorf("GET orchestration-response.json returned $id %v, content type %q", schema["$id"], rec.Header().Get("Content-Type"))
+	}
+
+	for path, want := range map[string]int{"/schemas/nope.json": http.StatusNotFound, "/schemas/../go.mod": http.StatusNotFound} {
+		rec = httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/schemas", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /schemas = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,399 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omniserp"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+// searcher runs a search on one provider. Web providers' responses are
+// normalized by omniserp; internal providers answer in the same form.
+type searcher interface {
+	SearchNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error)
+}
+
+// Internal search providers, which search an organization's own documents
+// rather than the public web
+const (
+	ProviderElasticsearch = "elasticsearch"
+	ProviderOpenSearch    = "opensearch" // Same search API as Elasticsearch
+	ProviderSharePoint    = "sharepoint" // Microsoft Graph search
+)
+
+// IsInternal reports whether a search provider searches internal documents
+func IsInternal(provider string) bool {
+	switch provider {
+	case ProviderElasticsearch, ProviderOpenSearch, ProviderSharePoint:
+		return true
+	}
+	return false
+}
+
+// internalTimeout bounds a request to an internal search provider
+const internalTimeout = 30 * time.Second
+
+// snippetLength is the most characters of document text used as a snippet
+// when the provider returns no highlight
+const snippetLength = 300
+
+// indexFields names the index fields holding each part of a result
+type indexFields struct {
+	Title, URL, Content, Date string
+}
+
+// parseIndexFields reads "role=field" entries over the defaults, such as
+// "content=body" for an index that keeps document text in "body". Fields
+// of nested objects are written with dots, as in "meta.published".
+func parseIndexFields(entries []string) (indexFields, error) {
+	f := indexFields{Title: "title", URL: "url", Content: "content", Date: "date"}
+	for _, entry := range entries {
+		role, field, ok := strings.Cut(entry, "=")
+		field = strings.TrimSpace(field)
+		if !ok || field == "" {
+			return f, fmt.Errorf("invalid INTERNAL_SEARCH_FIELDS entry %q: want role=field", entry)
+		}
+		switch strings.TrimSpace(role) {
+		case "title":
+			f.Title = field
+		case "url":
+			f.URL = field
+		case "content":
+			f.Content = field
+		case "date":
+			f.Date = field
+		default:
+			return f, fmt.Errorf("invalid INTERNAL_SEARCH_FIELDS role %q: use title, url, content, or date", role)
+		}
+	}
+	return f, nil
+}
+
+// elasticsearch searches an Elasticsearch or OpenSearch index of internal
+// documents
+type elasticsearch struct {
+	endpoint string // The index's _search URL
+	apiKey   string
+	username string
+	password string
+	fields   indexFields
+	client   *http.Client
+}
+
+// newElasticsearch creates an index searcher from the configuration.
+// INTERNAL_SEARCH_URL names the index or alias, e.g.
+// https://search.corp.example.com:9200/reports.
+func newElasticsearch(cfg *config.Config) (*elasticsearch, error) {
+	u, err := url.Parse(cfg.InternalSearchURL)
+	if cfg.InternalSearchURL == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("INTERNAL_SEARCH_URL must be the http(s) URL of an index, e.g. https://search.example.com:9200/reports")
+	}
+	fields, err := parseIndexFields(cfg.InternalSearchFields)
+	if err != nil {
+		return nil, err
+	}
+	return &elasticsearch{
+		endpoint: strings.TrimSuffix(u.String(), "/") + "/_search",
+		apiKey:   cfg.InternalSearchAPIKey,
+		username: cfg.InternalSearchUsername,
+		password: cfg.InternalSearchPassword,
+		fields:   fields,
+		client:   httpclient.New(internalTimeout),
+	}, nil
+}
+
+// SearchNormalized runs a simple_query_string query, which honors quotes
+// and excluded terms as web search does and requires every other term
+func (e *elasticsearch) SearchNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	source := []string{e.fields.Title, e.fields.URL, e.fields.Date}
+	query := map[string]any{
+		"size": params.NumResults,
+		"query": map[string]any{"simple_query_string": map[string]any{
+			"query":            params.Query,
+			"fields":           []string{e.fields.Title + "^2", e.fields.Content},
+			"default_operator": "and",
+		}},
+		"_source": source,
+		"highlight": map[string]any{
+			"pre_tags":  []string{""},
+			"post_tags": []string{""},
+			"fields": map[string]any{e.fields.Content: map[string]any{
+				"fragment_size":       150,
+				"number_of_fragments": 2,
+				"no_match_size":       snippetLength,
+			}},
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case e.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.apiKey)
+	case e.username != "":
+		req.SetBasicAuth(e.username, e.password)
+	}
+
+	var resp struct {
+		Hits struct {
+			Hits []struct {
+				Source    map[string]any      `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := doJSON(e.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", ProviderElasticsearch, err)
+	}
+
+	result := &omniserp.NormalizedSearchResult{SearchMetadata: omniserp.SearchMetadata{Engine: ProviderElasticsearch, Query: params.Query}}
+	for _, hit := range resp.Hits.Hits {
+		link := fieldString(hit.Source, e.fields.URL)
+		if link == "" {
+			continue // A document with no URL cannot be fetched
+		}
+		result.OrganicResults = append(result.OrganicResults, organic(len(result.OrganicResults)+1,
+			fieldString(hit.Source, e.fields.Title), link,
+			strings.Join(hit.Highlight[e.fields.Content], " … "),
+			fieldString(hit.Source, e.fields.Date)))
+	}
+	return result, nil
+}
+
+// fieldString returns a document field as a string, following dots into
+// nested objects
+func fieldString(doc map[string]any, field string) string {
+	if v, ok := doc[field]; ok {
+		s, _ := v.(string)
+		return s
+	}
+	parent, rest, ok := strings.Cut(field, ".")
+	if !ok {
+		return ""
+	}
+	nested, _ := doc[parent].(map[string]any)
+	return fieldString(nested, rest)
+}
+
+// Microsoft endpoints, variables so tests can point them at a fake
+var (
+	graphSearchURL   = "https://graph.microsoft.com/v1.0/search/query"
+	graphDrivesURL   = "https://graph.microsoft.com/v1.0/drives"
+	entraTokenURL    = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+	graphSearchScope = "https://graph.microsoft.com/.default"
+)
+
+// sharePoint searches SharePoint and OneDrive files through Microsoft Graph
+// as an app registration
+type sharePoint struct {
+	region string
+	client *http.Client // Adds an access token, refreshed as it expires
+}
+
+// newSharePoint creates a Graph searcher from the configuration. The app
+// needs the Sites.Read.All and Files.Read.All application permissions.
+func newSharePoint(cfg *config.Config) (*sharePoint, error) {
+	client := NewSharePointClient(cfg, internalTimeout)
+	if client == nil {
+		return nil, fmt.Errorf("SHAREPOINT_TENANT_ID, SHAREPOINT_CLIENT_ID, and SHAREPOINT_CLIENT_SECRET are required when using sharepoint provider")
+	}
+	if cfg.SharePointRegion == "" {
+		return nil, fmt.Errorf("SHAREPOINT_REGION is required when using sharepoint provider, e.g. NAM or EUR")
+	}
+	return &sharePoint{region: cfg.SharePointRegion, client: client}, nil
+}
+
+// NewSharePointClient returns a client that calls Microsoft Graph as the
+// SharePoint app registration, with the given timeout, or nil when none is
+// configured. The sharepoint provider searches with it, and the agents
+// download the files it finds with it, since their links need its token.
+func NewSharePointClient(cfg *config.Config, timeout time.Duration) *http.Client {
+	if cfg.SharePointTenantID == "" || cfg.SharePointClientID == "" || cfg.SharePointClientSecret == "" {
+		return nil
+	}
+	cc := clientcredentials.Config{
+		ClientID:     cfg.SharePointClientID,
+		ClientSecret: cfg.SharePointClientSecret,
+		TokenURL:     fmt.Sprintf(entraTokenURL, url.PathEscape(cfg.SharePointTenantID)),
+		Scopes:       []string{graphSearchScope},
+	}
+	// The token source keeps this context for refreshes
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpclient.New(internalTimeout))
+	graph, _ := url.Parse(graphSearchURL)
+	return &http.Client{
+		Timeout: timeout,
+		Transport: graphTransport{
+			graph:  graph,
+			signed: cc.Client(ctx).Transport,
+			base:   httpclient.FetchTransport(),
+		},
+	}
+}
+
+// graphTransport adds the access token to requests to Graph only. A file
+// download redirects to a pre-authenticated URL on the SharePoint site,
+// which must not get the token.
+type graphTransport struct {
+	graph  *url.URL          // Graph's scheme and host
+	signed http.RoundTripper // Adds the token
+	base   http.RoundTripper
+}
+
+func (t graphTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == t.graph.Scheme && req.URL.Host == t.graph.Host {
+		return t.signed.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// sharePointFileURL returns the Graph URL of a drive item's content
+func sharePointFileURL(driveID, itemID string) string {
+	return graphDrivesURL + "/" + url.PathEscape(driveID) + "/items/" + url.PathEscape(itemID) + "/content"
+}
+
+// IsSharePointFile reports whether link is the Graph content URL of a file
+// the sharepoint provider found, which is downloaded with the client from
+// NewSharePointClient
+func IsSharePointFile(link string) bool {
+	rest, ok := strings.CutPrefix(link, graphDrivesURL+"/")
+	if !ok || strings.ContainsAny(rest, "?#") {
+		return false
+	}
+	parts := strings.Split(rest, "/")
+	return len(parts) == 4 && parts[0] != "" && parts[1] == "items" && parts[2] != "" && parts[3] == "content"
+}
+
+// SearchNormalized runs a KQL query over files. Each result links to the
+// file's content in Graph rather than its SharePoint page, which only a
+// signed-in user can read.
+func (sp *sharePoint) SearchNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	body, err := json.Marshal(map[string]any{"requests": []map[string]any{{
+		"entityTypes": []string{"driveItem"},
+		"query":       map[string]any{"queryString": params.Query},
+		"from":        0,
+		"size":        params.NumResults,
+		"region":      sp.region,
+	}}})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphSearchURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		Value []struct {
+			HitsContainers []struct {
+				Hits []struct {
+					Summary  string `json:"summary"`
+					Resource struct {
+						ID                   string `json:"id"`
+						Name                 string `json:"name"`
+						WebURL               string `json:"webUrl"`
+						LastModifiedDateTime string `json:"lastModifiedDateTime"`
+						ParentReference      struct {
+							DriveID string `json:"driveId"`
+						} `json:"parentReference"`
+						Fields struct {
+							Title string `json:"title"`
+						} `json:"fields"`
+					} `json:"resource"`
+				} `json:"hits"`
+			} `json:"hitsContainers"`
+		} `json:"value"`
+	}
+	if err := doJSON(sp.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", ProviderSharePoint, err)
+	}
+
+	result := &omniserp.NormalizedSearchResult{SearchMetadata: omniserp.SearchMetadata{Engine: ProviderSharePoint, Query: params.Query}}
+	for _, v := range resp.Value {
+		for _, c := range v.HitsContainers {
+			for _, hit := range c.Hits {
+				r := hit.Resource
+				if r.ID == "" || r.ParentReference.DriveID == "" {
+					continue // Not a file, so there is nothing to download
+				}
+				title := r.Fields.Title
+				if title == "" {
+					title = r.Name
+				}
+				o := organic(len(result.OrganicResults)+1, title, sharePointFileURL(r.ParentReference.DriveID, r.ID),
+					graphSummary.Replace(hit.Summary), r.LastModifiedDateTime)
+				// Show the site the file is on rather than Graph
+				if u, err := url.Parse(r.WebURL); err == nil && u.Hostname() != "" {
+					o.Domain = u.Hostname()
+				}
+				result.OrganicResults = append(result.OrganicResults, o)
+			}
+		}
+	}
+	return result, nil
+}
+
+// graphSummary removes the hit highlighting from Graph search summaries
+var graphSummary = strings.NewReplacer("<c0>", "", "</c0>", "", "<ddd/>", "…")
+
+// organic returns a normalized result, with its domain taken from its link
+func organic(position int, title, link, snippet, date string) omniserp.OrganicResult {
+	domain := ""
+	if u, err := url.Parse(link); err == nil {
+		domain = u.Hostname()
+	}
+	if title == "" {
+		title = link
+	}
+	return omniserp.OrganicResult{
+		Position: position,
+		Title:    title,
+		Link:     link,
+		URL:      link,
+		Snippet:  strings.TrimSpace(snippet),
+		Domain:   domain,
+		Date:     date,
+	}
+}
+
+// doJSON sends a request and decodes a JSON response. A failed response is
+// returned as a *statusError, so rate limits and quota errors are told
+// apart by status code.
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req) //nolint:gosec // G704: URL from configuration
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{
+			code:       resp.StatusCode,
+			status:     resp.Status,
+			retryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			body:       string(bytes.TrimSpace(msg)),
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
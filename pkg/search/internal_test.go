@@ -0,0 +1,186 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+func TestElasticsearch(t *testing.T) {
+	var query map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reports/_search" || r.Header.Get("Authorization") != "ApiKey secret" {
+			http.Error(w, "denied", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&query)
+		_, _ = w.Write([]byte(`{"hits": {"hits": [
+			{"_source": {"name": "Headcount report", "link": "https://wiki.corp.example.com/hr/headcount", "meta": {"published": "2024-03-05"}},
+			 "highlight": {"body": ["Headcount grew 12% in 2023", "to 4,210 employees"]}},
+			{"_source": {"name": "Draft with no link"}}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		Config:               &akconfig.Config{SearchProvider: ProviderOpenSearch},
+		InternalSearchURL:    srv.URL + "/reports",
+		InternalSearchAPIKey: "secret",
+		InternalSearchFields: []string{"title=name", "url=link", "content=body", "date=meta.published"},
+	}
+	svc, err := NewService(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := svc.SearchForStatistics(context.Background(), "headcount growth", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqs := query["query"].(map[string]any)["simple_query_string"].(map[string]any)
+	if sqs["query"] != "headcount growth" || sqs["default_operator"] != "and" {
+		t.Errorf("query = %v", sqs)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("results = %+v, want 1", resp.Results)
+	}
+	got := resp.Results[0]
+	want := SearchResult{
+		Title:       "Headcount report",
+		URL:         "https://wiki.corp.example.com/hr/headcount",
+		Snippet:     "Headcount grew 12% in 2023 … to 4,210 employees",
+		DisplayLink: "wiki.corp.example.com",
+		Date:        "2024-03-05",
+	}
+	if got != want {
+		t.Errorf("result = %+v, want %+v", got, want)
+	}
+
+	cfg.InternalSearchURL = srv.URL
+	if _, err := NewService(cfg); err == nil {
+		t.Error("NewService() with no index in INTERNAL_SEARCH_URL should fail")
+	}
+	cfg.InternalSearchURL, cfg.InternalSearchFields = srv.URL+"/reports", []string{"body=text"}
+	if _, err := NewService(cfg); err == nil {
+		t.Error("NewService() with an unknown field role should fail")
+	}
+}
+
+func TestSharePoint(t *testing.T) {
+	// The pre-authenticated URL a download redirects to, on another host
+	download := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("download got the Graph token")
+		}
+		_, _ = w.Write([]byte("Churn fell to 4.1% in Q2"))
+	}))
+	defer download.Close()
+
+	var region string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tenant-1/oauth2/v2.0/token":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("client_secret") != "app-secret" {
+				http.Error(w, "invalid_client", http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "graph-token", "token_type": "Bearer", "expires_in": 3600}`))
+		case "/search/query":
+			if r.Header.Get("Authorization") != "Bearer graph-token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			var body struct {
+				Requests []struct {
+					Region string `json:"region"`
+				} `json:"requests"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			region = body.Requests[0].Region
+			_, _ = w.Write([]byte(`{"value": [{"hitsContainers": [{"hits": [
+				{"summary": "Churn fell to <c0>4.1%</c0> in Q2<ddd/>", "resource": {
+					"id": "item-1",
+					"name": "Q2 metrics.pptx",
+					"webUrl": "https://contoso.sharepoint.com/sites/finance/Q2%20metrics.pptx",
+					"parentReference": {"driveId": "drive-1"},
+					"lastModifiedDateTime": "2024-07-01T09:00:00Z"}},
+				{"summary": "A list item", "resource": {"id": "7", "webUrl": "https://contoso.sharepoint.com/Lists/x/7"}}
+			]}]}]}`))
+		case "/drives/drive-1/items/item-1/content":
+			if r.Header.Get("Authorization") != "Bearer graph-token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			http.Redirect(w, r, download.URL+"/Q2.pptx?tempauth=x", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	oldSearch, oldDrives, oldToken := graphSearchURL, graphDrivesURL, entraTokenURL
+	graphSearchURL, graphDrivesURL, entraTokenURL = srv.URL+"/search/query", srv.URL+"/drives", srv.URL+"/%s/oauth2/v2.0/token"
+	defer func() { graphSearchURL, graphDrivesURL, entraTokenURL = oldSearch, oldDrives, oldToken }()
+
+	cfg := &config.Config{
+		Config:                 &akconfig.Config{SearchProvider: ProviderSharePoint},
+		SharePointTenantID:     "tenant-1",
+		SharePointClientID:     "app-1",
+		SharePointClientSecret: "app-secret",
+	}
+	if _, err := NewService(cfg); err == nil {
+		t.Error("NewService() without SHAREPOINT_REGION should fail")
+	}
+	cfg.SharePointRegion = "EUR"
+	svc, err := NewService(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := svc.Search(context.Background(), "churn", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if region != "EUR" || len(resp.Results) != 1 {
+		t.Fatalf("region = %q, results = %+v", region, resp.Results)
+	}
+	r := resp.Results[0]
+	if r.Title != "Q2 metrics.pptx" || r.Snippet != "Churn fell to 4.1% in Q2…" || r.DisplayLink != "contoso.sharepoint.com" {
+		t.Errorf("result = %+v", r)
+	}
+	if r.URL != srv.URL+"/drives/drive-1/items/item-1/content" || !IsSharePointFile(r.URL) {
+		t.Fatalf("result URL = %q; want the file's Graph content URL", r.URL)
+	}
+
+	// The file downloads with the app's token, which stays on Graph
+	res, err := NewSharePointClient(cfg, 5*time.Second).Get(r.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(body) != "Churn fell to 4.1% in Q2" {
+		t.Errorf("download = %d %q", res.StatusCode, body)
+	}
+
+	for _, link := range []string{
+		srv.URL + "/drives/drive-1/items/item-1",
+		srv.URL + "/drives/drive-1/items/item-1/content?format=pdf",
+		"https://contoso.sharepoint.com/sites/finance/Q2%20metrics.pptx",
+	} {
+		if IsSharePointFile(link) {
+			t.Errorf("IsSharePointFile(%q) = true", link)
+		}
+	}
+	if NewSharePointClient(&config.Config{}, time.Second) != nil {
+		t.Error("NewSharePointClient() without an app registration should be nil")
+	}
+}
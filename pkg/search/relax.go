@@ -0,0 +1,84 @@
+package search
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// relaxedTerms is how many terms the broadest relaxed query keeps
+const relaxedTerms = 3
+
+var (
+	// operator matches search operators such as site:example.com or
+	// intitle:report
+	operator = regexp.MustCompile(`^[a-z]+:\S`)
+	// year matches four-digit years and year ranges such as 2020-2023
+	year = regexp.MustCompile(`^(1[89]|20)\d{2}(-(1[89]|20)?\d{2})?$`)
+)
+
+// fillerWords carry no search meaning once quotes are gone
+var fillerWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "in": true, "on": true,
+	"for": true, "and": true, "or": true, "to": true, "by": true, "with": true,
+	"from": true, "at": true, "per": true, "vs": true, "versus": true,
+	"about": true, "how": true, "many": true, "much": true, "what": true,
+	"is": true, "are": true, "was": true, "were": true, "latest": true,
+	"recent": true, "current": true,
+}
+
+// Relax returns progressively broader forms of a topic to search when the
+// topic finds nothing: first without quotes, search operators, and
+// excluded terms; then also without years and filler words; then only its
+// longest few terms. Forms that repeat an earlier one are left out, so the
+// list is empty for a topic that cannot be broadened.
+func Relax(topic string) []string {
+	var plain []string
+	for _, word := range strings.Fields(strings.ReplaceAll(topic, `"`, " ")) {
+		if strings.HasPrefix(word, "-") || operator.MatchString(strings.ToLower(word)) {
+			continue
+		}
+		plain = append(plain, word)
+	}
+
+	var core []string
+	for _, word := range plain {
+		w := strings.ToLower(strings.Trim(word, ",.;:?!()"))
+		if w == "" || fillerWords[w] || year.MatchString(w) {
+			continue
+		}
+		core = append(core, strings.Trim(word, ",.;:?!()"))
+	}
+
+	// Longer words tend to be the subject rather than its qualifiers
+	key := core
+	if len(core) > relaxedTerms {
+		idx := make([]int, len(core))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.SliceStable(idx, func(a, b int) bool { return len(core[idx[a]]) > len(core[idx[b]]) })
+		idx = idx[:relaxedTerms]
+		sort.Ints(idx)
+		key = make([]string, 0, relaxedTerms)
+		for _, i := range idx {
+			key = append(key, core[i])
+		}
+	}
+
+	seen := map[string]bool{normalizeQuery(topic): true}
+	var queries []string
+	for _, terms := range [][]string{plain, core, key} {
+		q := strings.Join(terms, " ")
+		if n := normalizeQuery(q); n != "" && !seen[n] {
+			seen[n] = true
+			queries = append(queries, q)
+		}
+	}
+	return queries
+}
+
+// normalizeQuery folds case and spacing so equivalent queries compare equal
+func normalizeQuery(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
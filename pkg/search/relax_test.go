@@ -0,0 +1,41 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRelax(t *testing.T) {
+	tests := []struct {
+		topic string
+		want  []string
+	}{
+		{
+			topic: `"household electricity consumption" in rural Kenya 2023`,
+			want: []string{
+				"household electricity consumption in rural Kenya 2023",
+				"household electricity consumption rural Kenya",
+				"household electricity consumption",
+			},
+		},
+		{
+			topic: "remote work adoption site:gov -covid",
+			want:  []string{"remote work adoption"},
+		},
+		{
+			topic: "EV sales",
+			want:  nil,
+		},
+		{
+			topic: "What is the unemployment rate in Spain?",
+			want:  []string{"unemployment rate Spain"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			if got := Relax(tt.topic); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Relax(%q) = %q, want %q", tt.topic, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,16 +2,52 @@ package search
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+	"github.com/plexusone/agent-team-stats/pkg/logging"
+	"github.com/plexusone/agent-team-stats/pkg/pagemeta"
 	"github.com/plexusone/omniserp"
-	"github.com/plexusone/omniserp/client"
 )
 
-// Service provides web search capabilities using metaserp
+// Service provides web search capabilities using metaserp. Searches go to
+// the configured provider, and to SEARCH_FALLBACK_PROVIDER when the first
+// is rate limited or out of quota.
 type Service struct {
-	client *client.Client
+	pool atomic.Pointer[pool] // Swapped by Reload
+
+	mu        sync.Mutex
+	throttles map[string]*throttle // Per provider, kept across reloads
+	remaining map[string]remaining // Cached account lookups
+}
+
+// pool is the providers a search may use, in order of preference
+type pool struct {
+	providers []*provider
+	retries   int  // Rate-limited retries of a provider without a fallback
+	internal  bool // The primary provider searches internal documents
+}
+
+// provider is one configured search provider
+type provider struct {
+	name     string
+	apiKey   string
+	client   searcher
+	throttle *throttle
+}
+
+// remaining is a provider's searches left, as of a time
+type remaining struct {
+	searches int
+	at       time.Time
 }
 
 // SearchResult represents a single search result
@@ -20,57 +56,243 @@ type SearchResult struct {
 	URL         string
 	Snippet     string
 	DisplayLink string
+	Date        string // Publication date as the provider shows it, e.g. "Mar 5, 2024" or "3 days ago"
+
+	// Publication the page itself declares, when the research agent read it
+	pagemeta.Metadata
 }
 
 // SearchResponse contains search results
 type SearchResponse struct {
 	Results []SearchResult
 	Total   int
+	// NextOffset is the offset of the following page, or 0 when the
+	// provider has no further results
+	NextOffset int
 }
 
+// maxResultDepth is the deepest result position the providers return for a query
+const maxResultDepth = 100
+
 // NewService creates a new search service
 func NewService(cfg *config.Config) (*Service, error) {
-	var engineName string
+	s := &Service{throttles: make(map[string]*throttle), remaining: make(map[string]remaining)}
+	if err := s.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
 
-	// Determine which search provider to use and validate API key
-	switch cfg.SearchProvider {
-	case "serper":
-		if cfg.SerperAPIKey == "" {
-			return nil, fmt.Errorf("SERPER_API_KEY is required when using serper provider")
+// Reload replaces the search clients with ones using cfg's providers and
+// API keys. Searches already in progress finish on the previous clients; on
+// error the previous clients are kept. Rate-limit state carries over.
+func (s *Service) Reload(cfg *config.Config) error {
+	names := []string{cfg.SearchProvider}
+	if fb := cfg.SearchFallbackProvider; fb != "" && fb != cfg.SearchProvider {
+		names = append(names, fb)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := &pool{retries: cfg.SearchRateLimitRetries, internal: IsInternal(cfg.SearchProvider)}
+	for _, name := range names {
+		c, apiKey, err := newClient(cfg, name)
+		if err != nil {
+			return err
 		}
-		engineName = "serper"
+		maxBackoff := time.Duration(cfg.SearchMaxBackoffSeconds) * time.Second
+		t, ok := s.throttles[name]
+		if !ok {
+			t = newThrottle(name, maxBackoff)
+			s.throttles[name] = t
+		}
+		t.mu.Lock()
+		t.maxBackoff = max(maxBackoff, initialBackoff)
+		t.mu.Unlock()
+		p.providers = append(p.providers, &provider{name: name, apiKey: apiKey, client: c, throttle: t})
+	}
+	s.pool.Store(p)
+	return nil
+}
+
+// newClient creates a searcher for a web or internal provider, taking the
+// API key from the configuration rather than the environment so keys
+// loaded from config.json or a secrets provider are used. It also returns a
+// web provider's key.
+func newClient(cfg *config.Config, name string) (searcher, string, error) {
+	switch name {
+	case ProviderElasticsearch, ProviderOpenSearch:
+		c, err := newElasticsearch(cfg)
+		return c, "", err
+	case ProviderSharePoint:
+		c, err := newSharePoint(cfg)
+		return c, "", err
+	}
 
+	var apiKey string
+	switch name {
+	case "serper":
+		if apiKey = cfg.SerperAPIKey; apiKey == "" {
+			return nil, "", fmt.Errorf("SERPER_API_KEY is required when using serper provider")
+		}
 	case "serpapi":
-		if cfg.SerpAPIKey == "" {
-			return nil, fmt.Errorf("SERPAPI_API_KEY is required when using serpapi provider")
+		if apiKey = cfg.SerpAPIKey; apiKey == "" {
+			return nil, "", fmt.Errorf("SERPAPI_API_KEY is required when using serpapi provider")
 		}
-		engineName = "serpapi"
-
 	default:
-		return nil, fmt.Errorf("unsupported search provider: %s (use 'serper' or 'serpapi')", cfg.SearchProvider)
+		return nil, "", fmt.Errorf("unsupported search provider: %s (use 'serper', 'serpapi', 'elasticsearch', 'opensearch', or 'sharepoint')", name)
+	}
+	return newWebClient(name, apiKey), apiKey, nil
+}
+
+// searchNormalized runs a query on the first provider able to answer it.
+// A rate-limited provider is passed over for the next one, or, when it is
+// the last, retried after its back-off. A provider out of quota is skipped
+// until its cool-down ends.
+func (s *Service) searchNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	p := s.pool.Load()
+	logger := logging.FromContext(ctx)
+
+	var lastErr error
+	for i, prov := range p.providers {
+		last := i == len(p.providers)-1
+		for attempt := 0; ; attempt++ {
+			wait, limited, err := prov.throttle.delay()
+			if err != nil {
+				lastErr = fmt.Errorf("%s: %w", prov.name, err)
+				break
+			}
+			if limited && !last {
+				lastErr = fmt.Errorf("%s: %w", prov.name, ErrRateLimited)
+				break
+			}
+			if wait > 0 {
+				logger.Debug("throttling search", "provider", prov.name, "wait", wait)
+				if err := sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+			}
+
+			result, err := prov.client.SearchNormalized(ctx, params)
+			limit := prov.throttle.observe(err)
+			if err == nil {
+				return result, nil
+			}
+			if limit == nil {
+				return nil, err
+			}
+			lastErr = fmt.Errorf("%s: %w: %v", prov.name, limit, err)
+			logger.Warn("search provider limit reached", "provider", prov.name, "limit", limit, "attempt", attempt+1)
+			if errors.Is(limit, ErrQuotaExhausted) || !last || attempt >= p.retries {
+				break
+			}
+		}
+		if !last {
+			prov.throttle.failover()
+			logger.Warn("failing over to next search provider", "from", prov.name, "to", p.providers[i+1].name)
+		}
+	}
+	return nil, lastErr
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// serpAPIAccount is SerpAPI's account endpoint, which reports searches left
+var serpAPIAccount = "https://serpapi.com/account.json"
+
+// remainingTTL is how long a provider's searches left are cached
+const remainingTTL = 5 * time.Minute
+
+// Quotas returns the request, rate-limit, and quota state of each
+// configured provider. Searches left are filled in for SerpAPI from its
+// account endpoint; Serper does not report them.
+func (s *Service) Quotas(ctx context.Context) []Quota {
+	p := s.pool.Load()
+	quotas := make([]Quota, 0, len(p.providers))
+	for _, prov := range p.providers {
+		q := prov.throttle.snapshot()
+		if prov.name == "serpapi" {
+			if n, err := s.serpAPIRemaining(ctx, prov.apiKey); err == nil {
+				q.Remaining = &n
+			} else {
+				logging.FromContext(ctx).Debug("failed to read SerpAPI account", "error", err)
+			}
+		}
+		quotas = append(quotas, q)
+	}
+	return quotas
+}
+
+// serpAPIRemaining returns the searches left on a SerpAPI account, cached
+// for remainingTTL. Account lookups do not count against the quota.
+func (s *Service) serpAPIRemaining(ctx context.Context, apiKey string) (int, error) {
+	s.mu.Lock()
+	cached, ok := s.remaining["serpapi"]
+	s.mu.Unlock()
+	if ok && time.Since(cached.at) < remainingTTL {
+		return cached.searches, nil
 	}
 
-	// Create metaserp client with specific engine
-	c, err := client.NewWithEngine(engineName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serpAPIAccount+"?api_key="+url.QueryEscape(apiKey), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := httpclient.New(10 * time.Second).Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create search client: %w", err)
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("account lookup returned %s", resp.Status)
+	}
+	var account struct {
+		TotalSearchesLeft int `json:"total_searches_left"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return 0, fmt.Errorf("failed to decode account: %w", err)
 	}
 
-	return &Service{
-		client: c,
-	}, nil
+	s.mu.Lock()
+	s.remaining["serpapi"] = remaining{searches: account.TotalSearchesLeft, at: time.Now()}
+	s.mu.Unlock()
+	return account.TotalSearchesLeft, nil
 }
 
 // Search performs a web search for the given query
 func (s *Service) Search(ctx context.Context, query string, numResults int) (*SearchResponse, error) {
+	return s.SearchPage(ctx, query, numResults, 0)
+}
+
+// SearchPage returns numResults results starting at offset, so retries can
+// ask for results beyond the ones they have already seen. The providers are
+// not paged through omniserp, so the first offset+numResults results are
+// requested and the leading ones skipped.
+func (s *Service) SearchPage(ctx context.Context, query string, numResults, offset int) (*SearchResponse, error) {
 	if numResults <= 0 {
 		numResults = 10
 	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= maxResultDepth {
+		return &SearchResponse{Results: []SearchResult{}}, nil
+	}
+	depth := min(offset+numResults, maxResultDepth)
 
 	// Perform normalized search using omniserp
-	result, err := s.client.SearchNormalized(ctx, omniserp.SearchParams{
+	result, err := s.searchNormalized(ctx, omniserp.SearchParams{
 		Query:      query,
-		NumResults: numResults,
+		NumResults: depth,
 		Language:   "en",
 		Country:    "us",
 	})
@@ -78,29 +300,64 @@ func (s *Service) Search(ctx context.Context, query string, numResults int) (*Se
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
+	organic := result.OrganicResults
+	if len(organic) > depth {
+		organic = organic[:depth]
+	}
+	if offset < len(organic) {
+		organic = organic[offset:]
+	} else {
+		organic = nil
+	}
+
 	// Convert to our response format
-	searchResults := make([]SearchResult, 0, len(result.OrganicResults))
+	searchResults := make([]SearchResult, 0, len(organic))
 
 	// Extract organic results
-	for _, org := range result.OrganicResults {
+	for _, org := range organic {
 		searchResults = append(searchResults, SearchResult{
 			Title:       org.Title,
 			URL:         org.Link,
 			Snippet:     org.Snippet,
 			DisplayLink: org.Domain,
+			Date:        org.Date,
 		})
 	}
 
+	// A short page means the provider ran out of results
+	nextOffset := 0
+	if offset+len(searchResults) >= depth && depth < maxResultDepth {
+		nextOffset = depth
+	}
+
 	return &SearchResponse{
-		Results: searchResults,
-		Total:   len(searchResults),
+		Results:    searchResults,
+		Total:      len(searchResults),
+		NextOffset: nextOffset,
 	}, nil
 }
 
+// Internal reports whether searches go to an internal provider rather than
+// the public web
+func (s *Service) Internal() bool {
+	return s.pool.Load().internal
+}
+
 // SearchForStatistics performs a search optimized for finding statistics
 func (s *Service) SearchForStatistics(ctx context.Context, topic string, numResults int) (*SearchResponse, error) {
+	return s.SearchForStatisticsPage(ctx, topic, numResults, 0)
+}
+
+// SearchForStatisticsPage is SearchForStatistics starting at a result offset.
+// An internal provider gets the topic alone: its queries require every term,
+// and an organization's reports rarely call themselves a "study".
+func (s *Service) SearchForStatisticsPage(ctx context.Context, topic string, numResults, offset int) (*SearchResponse, error) {
+	if s.Internal() {
+		return s.SearchPage(ctx, topic, numResults, offset)
+	}
+
 	// Enhance query to find statistics from reputable sources
 	enhancedQuery := fmt.Sprintf("%s statistics data research study", topic)
 
-	return s.Search(ctx, enhancedQuery, numResults)
+	return s.SearchPage(ctx, enhancedQuery, numResults, offset)
 }
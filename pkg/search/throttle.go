@@ -0,0 +1,203 @@
+package search
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRateLimited reports a provider refusing requests for sending too
+	// many too quickly
+	ErrRateLimited = errors.New("search provider rate limited")
+	// ErrQuotaExhausted reports a provider account out of searches or
+	// credits
+	ErrQuotaExhausted = errors.New("search provider quota exhausted")
+)
+
+const (
+	// initialBackoff is the wait after a first rate-limit response; it
+	// doubles with each one after that
+	initialBackoff = time.Second
+	// quotaCooldown is how long a provider out of quota is skipped before
+	// it is tried again, in case credits were added, when it does not say
+	// when to retry
+	quotaCooldown = 5 * time.Minute
+	// maxQuotaCooldown caps the wait a provider out of quota asks for
+	maxQuotaCooldown = 30 * time.Minute
+)
+
+// statusError is a provider's failed response
+type statusError struct {
+	code       int
+	status     string        // e.g. "429 Too Many Requests"
+	retryAfter time.Duration // From Retry-After, or 0
+	body       string        // The start of the body
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.status, e.body)
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date,
+// into a wait from now. It returns 0 when the header is absent or invalid.
+func retryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// quotaPhrases mark a refusal as an account out of searches or credits
+// rather than a rate limit. Providers report both with 4xx statuses:
+// Serper an exhausted balance with 400, SerpAPI an exhausted plan with 429.
+var quotaPhrases = []string{
+	"not enough credits", "no credits", "insufficient credits",
+	"run out of searches", "out of searches", "searches for the month",
+	"quota exceeded", "quota exhausted",
+}
+
+// classify returns the wait the provider asked for and ErrQuotaExhausted or
+// ErrRateLimited when err is a provider's limit response. Only a
+// failed HTTP response is one: 402, or a 400, 403, or 429 whose body says
+// the account is out of searches, is the quota; any other 429, or a 503
+// with Retry-After, is the rate limit.
+func classify(err error) (time.Duration, error) {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return 0, nil
+	}
+	body := strings.ToLower(se.body)
+	quota := se.code == http.StatusPaymentRequired
+	switch se.code {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusTooManyRequests:
+		quota = slices.ContainsFunc(quotaPhrases, func(p string) bool { return strings.Contains(body, p) })
+	}
+	switch {
+	case quota:
+		return se.retryAfter, ErrQuotaExhausted
+	case se.code == http.StatusTooManyRequests,
+		se.code == http.StatusServiceUnavailable && se.retryAfter > 0:
+		return se.retryAfter, ErrRateLimited
+	}
+	return 0, nil
+}
+
+// Quota is the request, rate-limit, and quota state of one search provider
+type Quota struct {
+	Provider       string     `json:"provider"`
+	Requests       int        `json:"requests"`
+	RateLimited    int        `json:"rate_limited"`              // Requests refused for the rate limit
+	QuotaErrors    int        `json:"quota_errors"`              // Requests refused for lack of quota
+	Failovers      int        `json:"failovers"`                 // Searches handed to the next provider
+	BackoffSeconds float64    `json:"backoff_seconds"`           // Current spacing between requests
+	ThrottledUntil *time.Time `json:"throttled_until,omitempty"` // No request before this time
+	ExhaustedUntil *time.Time `json:"exhausted_until,omitempty"` // Skipped as out of quota until this time
+	Remaining      *int       `json:"remaining,omitempty"`       // Searches left, for providers that report it
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// throttle spaces the requests to one provider. Each rate-limit response
+// doubles the spacing, or raises it to the provider's Retry-After, up to
+// maxBackoff, and each success halves it, so a provider near its limit is
+// sent requests only as fast as it accepts them.
+type throttle struct {
+	mu             sync.Mutex
+	maxBackoff     time.Duration
+	backoff        time.Duration
+	next           time.Time // No request before this
+	limited        bool      // The last response was a rate limit
+	exhaustedUntil time.Time
+	stats          Quota
+	now            func() time.Time
+}
+
+// newThrottle returns a throttle for the named provider
+func newThrottle(provider string, maxBackoff time.Duration) *throttle {
+	if maxBackoff < initialBackoff {
+		maxBackoff = initialBackoff
+	}
+	return &throttle{maxBackoff: maxBackoff, stats: Quota{Provider: provider}, now: time.Now}
+}
+
+// delay returns how long to wait before the next request and whether the
+// wait is a back-off from a rate limit. It returns ErrQuotaExhausted while
+// the provider is out of quota.
+func (t *throttle) delay() (time.Duration, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if now.Before(t.exhaustedUntil) {
+		return 0, false, ErrQuotaExhausted
+	}
+	return max(t.next.Sub(now), 0), t.limited, nil
+}
+
+// observe records the outcome of a request and returns its limit error,
+// if it was refused for one
+func (t *throttle) observe(err error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.stats.Requests++
+	wait, limit := classify(err)
+
+	switch {
+	case err == nil:
+		t.limited = false
+		t.backoff /= 2
+		if t.backoff < initialBackoff {
+			t.backoff = 0
+		}
+	case errors.Is(limit, ErrRateLimited):
+		t.stats.RateLimited++
+		t.limited = true
+		t.backoff = min(max(2*t.backoff, initialBackoff, wait), t.maxBackoff)
+	case errors.Is(limit, ErrQuotaExhausted):
+		t.stats.QuotaErrors++
+		t.exhaustedUntil = now.Add(min(cmp.Or(wait, quotaCooldown), maxQuotaCooldown))
+	}
+	if err != nil {
+		t.stats.LastError = err.Error()
+	}
+	t.next = now.Add(t.backoff)
+	return limit
+}
+
+// failover counts a search handed to the next provider
+func (t *throttle) failover() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Failovers++
+}
+
+// snapshot returns the provider's current state
+func (t *throttle) snapshot() Quota {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q := t.stats
+	q.BackoffSeconds = t.backoff.Seconds()
+	now := t.now()
+	if t.next.After(now) {
+		next := t.next
+		q.ThrottledUntil = &next
+	}
+	if t.exhaustedUntil.After(now) {
+		until := t.exhaustedUntil
+		q.ExhaustedUntil = &until
+	}
+	return q
+}
@@ -0,0 +1,124 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err      error
+		want     error
+		wantWait time.Duration
+	}{
+		{nil, nil, 0},
+		{&statusError{code: 400, body: `{"message":"Not enough credits","statusCode":400}`}, ErrQuotaExhausted, 0},
+		{&statusError{code: 429, body: `{"error":"Your account has run out of searches."}`}, ErrQuotaExhausted, 0},
+		{&statusError{code: 402, body: `{}`, retryAfter: time.Hour}, ErrQuotaExhausted, time.Hour},
+		{&statusError{code: 429, body: `{"message":"Too many requests"}`, retryAfter: 3 * time.Second}, ErrRateLimited, 3 * time.Second},
+		{&statusError{code: 503, body: `busy`, retryAfter: time.Second}, ErrRateLimited, time.Second},
+		{&statusError{code: 503, body: `busy`}, nil, 0},
+		{&statusError{code: 401, body: `{"message":"Unauthorized"}`}, nil, 0},
+		// Bodies are only read for a limit status, and not for bare numbers
+		{&statusError{code: 400, body: `{"error":"Invalid quota_project parameter"}`}, nil, 0},
+		{errors.New(`decoding result 429 of "quota exceeded" page`), nil, 0},
+		{fmt.Errorf("serper: %w", &statusError{code: 429}), ErrRateLimited, 0},
+	}
+	for _, tt := range tests {
+		if wait, got := classify(tt.err); got != tt.want || wait != tt.wantWait {
+			t.Errorf("classify(%v) = %v, %v, want %v, %v", tt.err, wait, got, tt.wantWait, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"-5":                            0,
+		"Mon, 01 Jan 2024 00:00:30 GMT": 30 * time.Second,
+		"Sun, 31 Dec 2023 23:00:00 GMT": 0,
+		"soon":                          0,
+	}
+	for header, want := range tests {
+		if got := retryAfter(header, now); got != want {
+			t.Errorf("retryAfter(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestThrottleBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := newThrottle("serper", 4*time.Second)
+	th.now = func() time.Time { return now }
+
+	limited := &statusError{code: 429, body: "Too many requests"}
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if err := th.observe(limited); err != ErrRateLimited {
+			t.Fatalf("observe() = %v, want ErrRateLimited", err)
+		}
+		wait, isLimit, err := th.delay()
+		if wait != want || !isLimit || err != nil {
+			t.Errorf("delay() = %v, %v, %v, want %v, true, nil", wait, isLimit, err, want)
+		}
+	}
+
+	// Successes halve the spacing until it drops away
+	for _, want := range []time.Duration{2 * time.Second, time.Second, 0} {
+		th.observe(nil)
+		if wait, isLimit, _ := th.delay(); wait != want || isLimit {
+			t.Errorf("delay() after success = %v, %v, want %v, false", wait, isLimit, want)
+		}
+	}
+
+	q := th.snapshot()
+	if q.Requests != 7 || q.RateLimited != 4 || q.ThrottledUntil != nil {
+		t.Errorf("snapshot() = %+v", q)
+	}
+}
+
+func TestThrottleQuota(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := newThrottle("serpapi", time.Minute)
+	th.now = func() time.Time { return now }
+
+	if err := th.observe(&statusError{code: 429, body: "Your searches for the month are exhausted"}); err != ErrQuotaExhausted {
+		t.Fatalf("observe() = %v, want ErrQuotaExhausted", err)
+	}
+	if _, _, err := th.delay(); err != ErrQuotaExhausted {
+		t.Errorf("delay() = %v, want ErrQuotaExhausted", err)
+	}
+	if q := th.snapshot(); q.QuotaErrors != 1 || q.ExhaustedUntil == nil {
+		t.Errorf("snapshot() = %+v", q)
+	}
+
+	now = now.Add(quotaCooldown)
+	if _, _, err := th.delay(); err != nil {
+		t.Errorf("delay() after cool-down = %v, want nil", err)
+	}
+}
+
+func TestThrottleRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := newThrottle("serper", 10*time.Second)
+	th.now = func() time.Time { return now }
+
+	// A longer Retry-After raises the back-off, up to its cap
+	th.observe(&statusError{code: 429, retryAfter: 5 * time.Second})
+	if wait, _, _ := th.delay(); wait != 5*time.Second {
+		t.Errorf("delay() = %v, want 5s", wait)
+	}
+	th.observe(&statusError{code: 429, retryAfter: time.Hour})
+	if wait, _, _ := th.delay(); wait != 10*time.Second {
+		t.Errorf("delay() = %v, want the 10s cap", wait)
+	}
+
+	// A quota cool-down follows Retry-After, up to its cap
+	th.observe(&statusError{code: 402, retryAfter: 24 * time.Hour})
+	if q := th.snapshot(); q.ExhaustedUntil == nil || !q.ExhaustedUntil.Equal(now.Add(maxQuotaCooldown)) {
+		t.Errorf("ExhaustedUntil = %v, want %v", q.ExhaustedUntil, now.Add(maxQuotaCooldown))
+	}
+}
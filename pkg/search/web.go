@@ -0,0 +1,97 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/plexusone/omniserp"
+
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+// Web search endpoints, variables so tests can point them at a local server
+var (
+	serperSearchURL  = "https://google.serper.dev/search"
+	serpAPISearchURL = "https://serpapi.com/search.json"
+)
+
+// webTimeout bounds a request to a web search provider
+const webTimeout = 30 * time.Second
+
+// webClient searches Serper or SerpAPI. It sends the requests the omniserp
+// engines do and normalizes the responses with omniserp, but makes them
+// itself so a search follows its context and a failed response keeps its
+// status code and Retry-After for the throttle.
+type webClient struct {
+	name   string // "serper" or "serpapi"
+	apiKey string
+	client *http.Client
+}
+
+// newWebClient returns a client for the named web provider
+func newWebClient(name, apiKey string) *webClient {
+	return &webClient{name: name, apiKey: apiKey, client: httpclient.New(webTimeout)}
+}
+
+// SearchNormalized runs a web search and normalizes the results
+func (w *webClient) SearchNormalized(ctx context.Context, params omniserp.SearchParams) (*omniserp.NormalizedSearchResult, error) {
+	req, err := w.request(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := doJSON(w.client, req, &data); err != nil {
+		return nil, err
+	}
+	return omniserp.NewNormalizer(w.name).NormalizeSearch(&omniserp.SearchResult{Data: data}, params.Query)
+}
+
+// request builds the provider's search request
+func (w *webClient) request(ctx context.Context, params omniserp.SearchParams) (*http.Request, error) {
+	fields := map[string]string{"q": params.Query}
+	if params.Location != "" {
+		fields["location"] = params.Location
+	}
+	if params.Language != "" {
+		fields["hl"] = params.Language
+	}
+	if params.Country != "" {
+		fields["gl"] = params.Country
+	}
+	if params.NumResults > 0 {
+		fields["num"] = strconv.Itoa(params.NumResults)
+	}
+
+	if w.name == "serpapi" {
+		q := url.Values{"api_key": {w.apiKey}, "engine": {"google"}}
+		for k, v := range fields {
+			q.Set(k, v)
+		}
+		return http.NewRequestWithContext(ctx, http.MethodGet, serpAPISearchURL+"?"+q.Encode(), nil)
+	}
+
+	body := map[string]any{}
+	for k, v := range fields {
+		body[k] = v
+	}
+	if params.NumResults > 0 {
+		body["num"] = params.NumResults
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, serperSearchURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-KEY", w.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
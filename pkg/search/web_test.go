@@ -0,0 +1,83 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	akconfig "github.com/plexusone/agentkit/config"
+	"github.com/plexusone/omniserp"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+func TestWebClientRateLimit(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("X-API-KEY") != "key" {
+			http.Error(w, "denied", http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"message":"Too many requests"}`, http.StatusTooManyRequests)
+			return
+		}
+		if body["q"] != "unemployment rate" || body["num"] != float64(10) {
+			t.Errorf("request = %v", body)
+		}
+		_, _ = w.Write([]byte(`{"organic": [{"title": "Jobs report", "link": "https://www.bls.gov/news", "snippet": "Unemployment was 3.9%", "position": 1}]}`))
+	}))
+	defer srv.Close()
+	prev := serperSearchURL
+	serperSearchURL = srv.URL
+	defer func() { serperSearchURL = prev }()
+
+	cfg := &config.Config{
+		Config:                 &akconfig.Config{SearchProvider: "serper", SerperAPIKey: "key"},
+		SearchRateLimitRetries: 1,
+	}
+	svc, err := NewService(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := svc.Search(context.Background(), "unemployment rate", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].URL != "https://www.bls.gov/news" {
+		t.Errorf("results = %+v", resp.Results)
+	}
+	if q := svc.Quotas(context.Background())[0]; q.Requests != 2 || q.RateLimited != 1 {
+		t.Errorf("quota = %+v, want 2 requests, 1 rate limited", q)
+	}
+}
+
+func TestWebClientError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "key" || r.URL.Query().Get("engine") != "google" {
+			t.Errorf("query = %v", r.URL.Query())
+		}
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, `{"error":"Your account has run out of searches."}`, http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	prev := serpAPISearchURL
+	serpAPISearchURL = srv.URL
+	defer func() { serpAPISearchURL = prev }()
+
+	_, err := newWebClient("serpapi", "key").SearchNormalized(context.Background(), omniserp.SearchParams{Query: "gdp"})
+	var se *statusError
+	if !errors.As(err, &se) || se.code != http.StatusTooManyRequests || se.retryAfter.Seconds() != 60 {
+		t.Fatalf("SearchNormalized() error = %#v", err)
+	}
+	if _, limit := classify(err); limit != ErrQuotaExhausted {
+		t.Errorf("classify() = %v, want ErrQuotaExhausted", limit)
+	}
+}
@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+// gcpScope is the OAuth scope for Secret Manager access
+const gcpScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// gcpEndpoint is the Secret Manager REST endpoint
+const gcpEndpoint = "https://secretmanager.googleapis.com"
+
+// GCPConfig configures the Google Cloud Secret Manager backend
+type GCPConfig struct {
+	Project  string       // GCP project holding the secrets
+	Endpoint string       // Defaults to the public Secret Manager endpoint
+	Client   *http.Client // Authenticated client; defaults to Application Default Credentials
+}
+
+// GCP reads the latest version of secrets from Google Cloud Secret Manager.
+// Secret IDs may not contain "/", so a path such as
+// "stats-agent/GEMINI_API_KEY" is read from the secret
+// "stats-agent_GEMINI_API_KEY".
+type GCP struct {
+	readOnly
+	cfg GCPConfig
+}
+
+// NewGCP creates a Secret Manager backend. Without a client it
+// authenticates with Application Default Credentials (workload identity,
+// GOOGLE_APPLICATION_CREDENTIALS, or gcloud).
+func NewGCP(ctx context.Context, cfg GCPConfig) (*GCP, error) {
+	if cfg.Project == "" {
+		return nil, fmt.Errorf("GCP project not set - please set GOOGLE_CLOUD_PROJECT")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = gcpEndpoint
+	}
+	if cfg.Client == nil {
+		client, err := google.DefaultClient(ctx, gcpScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find GCP credentials: %w", err)
+		}
+		client.Timeout = 10 * time.Second
+		cfg.Client = client
+	}
+	return &GCP{cfg: cfg}, nil
+}
+
+// Name implements vault.Vault
+func (g *GCP) Name() string {
+	return "gcp-sm"
+}
+
+// Get implements vault.Vault
+func (g *GCP) Get(ctx context.Context, path string) (*vault.Secret, error) {
+	id := SecretID(path)
+	u := fmt.Sprintf("%s/v1/projects/%s/secrets/%s/versions/latest:access",
+		strings.TrimRight(g.cfg.Endpoint, "/"), url.PathEscape(g.cfg.Project), url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	body, err := doRequest(g.cfg.Client, req)
+	if errors.Is(err, vault.ErrSecretNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("secret manager access %s: %w", id, err)
+	}
+
+	var resp struct {
+		Name    string `json:"name"`
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("secret manager access %s: invalid response: %w", id, err)
+	}
+	value, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("secret manager access %s: invalid payload: %w", id, err)
+	}
+
+	s := &vault.Secret{Value: strings.TrimSpace(string(value))}
+	if _, version, ok := cutLast(resp.Name, "/"); ok {
+		s.Metadata.Version = version
+	}
+	return s, nil
+}
+
+// Exists implements vault.Vault
+func (g *GCP) Exists(ctx context.Context, path string) (bool, error) {
+	return exists(ctx, g.Get, path)
+}
+
+// SecretID maps a secret path to a Secret Manager secret ID
+func SecretID(path string) string {
+	return strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
+}
@@ -0,0 +1,176 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+// HashiCorpConfig configures the HashiCorp Vault backend
+type HashiCorpConfig struct {
+	Addr      string       // Vault address, e.g. https://vault.example.com:8200
+	Token     string       // Vault token
+	Namespace string       // Vault Enterprise namespace; empty for none
+	Mount     string       // KV v2 mount path; defaults to "secret"
+	Client    *http.Client // Defaults to a client with a 10 second timeout
+}
+
+// HashiCorp reads secrets from a HashiCorp Vault KV v2 engine. A path such
+// as "stats-agent/GEMINI_API_KEY" is read either as its own secret (using
+// its "value" field, or its only field) or, when that does not exist, as the
+// GEMINI_API_KEY field of the "stats-agent" secret, so keys can be stored
+// one per secret or together. Reads are cached for the life of the backend.
+type HashiCorp struct {
+	readOnly
+	cfg HashiCorpConfig
+
+	mu    sync.Mutex
+	cache map[string]map[string]string // KV data by path; nil for not found
+}
+
+// NewHashiCorp creates a HashiCorp Vault backend
+func NewHashiCorp(cfg HashiCorpConfig) (*HashiCorp, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("vault address not set - please set VAULT_ADDR")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("vault token not set - please set VAULT_TOKEN or VAULT_TOKEN_FILE")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HashiCorp{cfg: cfg, cache: make(map[string]map[string]string)}, nil
+}
+
+// Name implements vault.Vault
+func (h *HashiCorp) Name() string {
+	return "vault"
+}
+
+// Get implements vault.Vault
+func (h *HashiCorp) Get(ctx context.Context, path string) (*vault.Secret, error) {
+	path = strings.Trim(path, "/")
+	data, err := h.read(ctx, path)
+	if err != nil && !errors.Is(err, vault.ErrSecretNotFound) {
+		return nil, err
+	}
+	if data != nil {
+		return newSecret(data), nil
+	}
+
+	// Fall back to a field of the parent secret
+	parent, field, ok := cutLast(path, "/")
+	if !ok {
+		return nil, vault.ErrSecretNotFound
+	}
+	data, err = h.read(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := data[field]
+	if !ok {
+		return nil, vault.ErrSecretNotFound
+	}
+	return &vault.Secret{Value: value}, nil
+}
+
+// Exists implements vault.Vault
+func (h *HashiCorp) Exists(ctx context.Context, path string) (bool, error) {
+	return exists(ctx, h.Get, path)
+}
+
+// read returns the data of the KV v2 secret at path
+func (h *HashiCorp) read(ctx context.Context, path string) (map[string]string, error) {
+	h.mu.Lock()
+	data, cached := h.cache[path]
+	h.mu.Unlock()
+	if cached {
+		if data == nil {
+			return nil, vault.ErrSecretNotFound
+		}
+		return data, nil
+	}
+
+	u := strings.TrimRight(h.cfg.Addr, "/") + "/v1/" + strings.Trim(h.cfg.Mount, "/") + "/data/" + escapePath(path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", h.cfg.Token)
+	if h.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", h.cfg.Namespace)
+	}
+
+	body, err := doRequest(h.cfg.Client, req)
+	if err != nil && !errors.Is(err, vault.ErrSecretNotFound) {
+		return nil, fmt.Errorf("vault read %s: %w", path, err)
+	}
+	if err == nil {
+		var resp struct {
+			Data struct {
+				Data map[string]any `json:"data"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("vault read %s: invalid response: %w", path, err)
+		}
+		data = make(map[string]string, len(resp.Data.Data))
+		for k, v := range resp.Data.Data {
+			if s, ok := v.(string); ok {
+				data[k] = s
+			} else {
+				data[k] = fmt.Sprint(v)
+			}
+		}
+	}
+
+	h.mu.Lock()
+	h.cache[path] = data
+	h.mu.Unlock()
+	if data == nil {
+		return nil, vault.ErrSecretNotFound
+	}
+	return data, nil
+}
+
+// newSecret converts KV data into a secret whose value is the "value"
+// field, or the only field when there is just one
+func newSecret(data map[string]string) *vault.Secret {
+	s := &vault.Secret{Fields: data}
+	if v, ok := data["value"]; ok {
+		s.Value = v
+	} else if len(data) == 1 {
+		for _, v := range data {
+			s.Value = v
+		}
+	}
+	return s
+}
+
+// escapePath escapes each segment of a secret path
+func escapePath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// cutLast splits s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,95 @@
+// Package secrets provides read-only OmniVault backends for HashiCorp Vault
+// (KV v2) and Google Cloud Secret Manager, so API keys can be kept out of
+// Helm values and environment variables on non-AWS infrastructure. Both
+// implement vault.Vault and plug into agentkit's secrets client as a
+// custom vault.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+// maxResponseBytes bounds secret API responses
+const maxResponseBytes = 1 << 20
+
+// readOnly implements the write operations of vault.Vault for backends that
+// only read secrets
+type readOnly struct{}
+
+// Set implements vault.Vault
+func (readOnly) Set(context.Context, string, *vault.Secret) error {
+	return vault.ErrReadOnly
+}
+
+// Delete implements vault.Vault
+func (readOnly) Delete(context.Context, string) error {
+	return vault.ErrReadOnly
+}
+
+// List implements vault.Vault
+func (readOnly) List(context.Context, string) ([]string, error) {
+	return nil, vault.ErrNotSupported
+}
+
+// Capabilities implements vault.Vault
+func (readOnly) Capabilities() vault.Capabilities {
+	return vault.Capabilities{Read: true, MultiField: true}
+}
+
+// Close implements vault.Vault
+func (readOnly) Close() error {
+	return nil
+}
+
+// exists reports whether get finds a secret
+func exists(ctx context.Context, get func(context.Context, string) (*vault.Secret, error), path string) (bool, error) {
+	_, err := get(ctx, path)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, vault.ErrSecretNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// doRequest sends req and returns the response body, mapping 404 to
+// vault.ErrSecretNotFound and 401/403 to vault.ErrAccessDenied
+func doRequest(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req) //nolint:gosec // G704: secrets backend URL from configuration
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vault.ErrConnectionFailed, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, vault.ErrSecretNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("%w: HTTP %d", vault.ErrAccessDenied, resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, truncate(string(body), 200))
+	}
+}
+
+// truncate shortens s to at most n bytes for error messages
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+func TestHashiCorpGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" || r.Header.Get("X-Vault-Namespace") != "team" {
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/stats-agent/GEMINI_API_KEY":
+			_, _ = w.Write([]byte(`{"data":{"data":{"value":"gemini-key"}}}`))
+		case "/v1/kv/data/stats-agent":
+			_, _ = w.Write([]byte(`{"data":{"data":{"SERPER_API_KEY":"serper-key"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	h, err := NewHashiCorp(HashiCorpConfig{Addr: srv.URL, Token: "test-token", Namespace: "team", Mount: "kv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"stats-agent/GEMINI_API_KEY", "gemini-key"}, // one secret per key
+		{"stats-agent/SERPER_API_KEY", "serper-key"}, // field of the parent secret
+	}
+	for _, tt := range tests {
+		s, err := h.Get(ctx, tt.path)
+		if err != nil {
+			t.Errorf("Get(%q) error: %v", tt.path, err)
+			continue
+		}
+		if s.Value != tt.want {
+			t.Errorf("Get(%q) = %q, want %q", tt.path, s.Value, tt.want)
+		}
+	}
+
+	if _, err := h.Get(ctx, "stats-agent/OPENAI_API_KEY"); !errors.Is(err, vault.ErrSecretNotFound) {
+		t.Errorf("missing field error = %v, want ErrSecretNotFound", err)
+	}
+	if ok, err := h.Exists(ctx, "other/KEY"); ok || err != nil {
+		t.Errorf("Exists(missing) = %v, %v", ok, err)
+	}
+
+	bad, _ := NewHashiCorp(HashiCorpConfig{Addr: srv.URL, Token: "wrong"})
+	if _, err := bad.Get(ctx, "stats-agent"); !errors.Is(err, vault.ErrAccessDenied) {
+		t.Errorf("bad token error = %v, want ErrAccessDenied", err)
+	}
+}
+
+func TestGCPGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/my-project/secrets/stats-agent_GEMINI_API_KEY/versions/latest:access" {
+			http.NotFound(w, r)
+			return
+		}
+		// "gemini-key\n" base64-encoded
+		_, _ = w.Write([]byte(`{"name":"projects/123/secrets/stats-agent_GEMINI_API_KEY/versions/4","payload":{"data":"Z2VtaW5pLWtleQo="}}`))
+	}))
+	defer srv.Close()
+
+	g, err := NewGCP(context.Background(), GCPConfig{Project: "my-project", Endpoint: srv.URL, Client: srv.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := g.Get(context.Background(), "stats-agent/GEMINI_API_KEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Value != "gemini-key" || s.Metadata.Version != "4" {
+		t.Errorf("Get() = %q (version %q)", s.Value, s.Metadata.Version)
+	}
+	if _, err := g.Get(context.Background(), "stats-agent/SERPER_API_KEY"); !errors.Is(err, vault.ErrSecretNotFound) {
+		t.Errorf("missing secret error = %v, want ErrSecretNotFound", err)
+	}
+}
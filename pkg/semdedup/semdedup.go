@@ -0,0 +1,209 @@
+// Package semdedup merges statistics that different sources phrase
+// differently but that report the same figure. Near-duplicates are found by
+// embedding similarity of name and excerpt, restricted to equal values for
+// the same year, and folded into the best-sourced representative as
+// corroborations.
+package semdedup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/embed"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// valueTolerance is the relative difference under which two values are equal
+const valueTolerance = 0.005
+
+// years finds the years a statistic is for
+var years = regexp.MustCompile(`\b(19\d{2}|20\d{2}|2100)\b`)
+
+// authoritativeSuffixes are host suffixes of government, academic, and
+// intergovernmental sources
+var authoritativeSuffixes = []string{".gov", ".edu", ".int", ".mil", ".gov.uk", ".ac.uk", ".europa.eu"}
+
+// Deduper clusters near-duplicate statistics
+type Deduper struct {
+	embedder  embed.Embedder
+	threshold float64
+	logger    *slog.Logger
+}
+
+// New creates a deduper. Statistics with equal values for the same year whose
+// embeddings have a cosine similarity of at least threshold are treated as
+// duplicates.
+func New(embedder embed.Embedder, threshold float64, logger *slog.Logger) *Deduper {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Deduper{embedder: embedder, threshold: threshold, logger: logger}
+}
+
+// FromConfig creates the deduper configured by SEMANTIC_DEDUP,
+// EMBEDDING_PROVIDER, and SEMANTIC_DEDUP_THRESHOLD. It returns nil when
+// semantic dedup is disabled.
+func FromConfig(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*Deduper, error) {
+	if !cfg.SemanticDedupEnabled {
+		return nil, nil
+	}
+	embedder, err := embed.New(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	threshold := cfg.SemanticDedupThreshold
+	if threshold <= 0 {
+		threshold = embed.DefaultThreshold(cfg.EmbeddingProvider)
+	}
+	return New(embedder, threshold, logger), nil
+}
+
+// Dedupe returns stats with near-duplicates merged, in their original order,
+// and the number of statistics merged away. A nil Deduper, or a failed
+// embedding call, returns the input unchanged.
+func (d *Deduper) Dedupe(ctx context.Context, stats []models.Statistic) ([]models.Statistic, int) {
+	if d == nil || len(stats) < 2 {
+		return stats, 0
+	}
+
+	texts := make([]string, len(stats))
+	for i, s := range stats {
+		texts[i] = s.Name + ". " + s.Excerpt
+	}
+	vectors, err := d.embedder.Embed(ctx, texts)
+	if err != nil {
+		d.logger.Warn("semantic dedup skipped", "error", err)
+		return stats, 0
+	}
+	if len(vectors) != len(stats) {
+		d.logger.Warn("semantic dedup skipped", "error", fmt.Sprintf("got %d embeddings for %d statistics", len(vectors), len(stats)))
+		return stats, 0
+	}
+
+	// Visit the best-sourced statistics first so each becomes the
+	// representative of its cluster
+	order := make([]int, len(stats))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sourceScore(stats[order[a]]) > sourceScore(stats[order[b]])
+	})
+
+	var reps []int
+	merged := make(map[int]*models.Statistic, len(stats))
+	for _, i := range order {
+		best, bestSim := -1, d.threshold
+		for _, r := range reps {
+			if !sameValue(stats[i], stats[r]) || !samePeriod(stats[i], stats[r]) {
+				continue
+			}
+			if sim := embed.Cosine(vectors[i], vectors[r]); sim >= bestSim {
+				best, bestSim = r, sim
+			}
+		}
+		if best < 0 {
+			rep := stats[i]
+			merged[i] = &rep
+			reps = append(reps, i)
+			continue
+		}
+
+		rep := merged[best]
+		d.logger.Debug("merged duplicate statistic",
+			"name", stats[i].Name,
+			"into", rep.Name,
+			"similarity", bestSim)
+		if stats[i].SourceURL == rep.SourceURL || hasSource(rep.CorroboratedBy, stats[i].SourceURL) {
+			continue // Same page twice is not corroboration
+		}
+		rep.CorroboratedBy = append(rep.CorroboratedBy, models.Corroboration{
+			Name:       stats[i].Name,
+			Source:     stats[i].Source,
+			SourceURL:  stats[i].SourceURL,
+			Excerpt:    stats[i].Excerpt,
+			Similarity: math.Round(bestSim*1000) / 1000,
+		})
+	}
+
+	sort.Ints(reps)
+	deduped := make([]models.Statistic, 0, len(reps))
+	for _, r := range reps {
+		deduped = append(deduped, *merged[r])
+	}
+
+	if dropped := len(stats) - len(deduped); dropped > 0 {
+		d.logger.Info("merged near-duplicate statistics", "merged", dropped, "remaining", len(deduped))
+	}
+	return deduped, len(stats) - len(deduped)
+}
+
+// sameValue reports whether two statistics report the same number in a
+// compatible unit
+func sameValue(a, b models.Statistic) bool {
+	if !strings.EqualFold(strings.TrimSpace(a.Unit), strings.TrimSpace(b.Unit)) && a.Unit != "" && b.Unit != "" {
+		return false
+	}
+	x, y := float64(a.Value), float64(b.Value)
+	if x == y {
+		return true
+	}
+	return math.Abs(x-y) <= valueTolerance*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// samePeriod reports whether two statistics are for the same years: those
+// in their names, or else in their excerpts. The same share of adults in
+// 2022 and 2023 is two statistics, and one without a year cannot be told to
+// be for the other's.
+func samePeriod(a, b models.Statistic) bool {
+	pa, pb := period(a), period(b)
+	return len(pa) == len(pb) && slices.Equal(pa, pb)
+}
+
+// period returns the distinct years a statistic is for, sorted
+func period(s models.Statistic) []string {
+	y := years.FindAllString(s.Name, -1)
+	if len(y) == 0 {
+		y = years.FindAllString(s.Excerpt, -1)
+	}
+	slices.Sort(y)
+	return slices.Compact(y)
+}
+
+// sourceScore ranks how authoritative a statistic's source is: data files
+// and tables over prose, government and academic hosts over others, then
+// longer excerpts
+func sourceScore(s models.Statistic) float64 {
+	score := 0.0
+	if s.Provenance != nil {
+		score += 2
+	}
+	if u, err := url.Parse(s.SourceURL); err == nil {
+		host := strings.ToLower(u.Hostname())
+		for _, suffix := range authoritativeSuffixes {
+			if strings.HasSuffix(host, suffix) {
+				score += 3
+				break
+			}
+		}
+	}
+	return score + math.Min(float64(len(s.Excerpt))/1000, 0.5)
+}
+
+// hasSource reports whether a corroboration list already cites url
+func hasSource(list []models.Corroboration, url string) bool {
+	for _, c := range list {
+		if c.SourceURL == url {
+			return true
+		}
+	}
+	return false
+}
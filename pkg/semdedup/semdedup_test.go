@@ -0,0 +1,87 @@
+package semdedup
+
+import (
+	"context"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/embed"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestDedupeMergesRewordedStatistics(t *testing.T) {
+	stats := []models.Statistic{
+		{
+			Name:      "US unemployment rate in 2023",
+			Value:     3.6,
+			Unit:      "%",
+			Source:    "News Site",
+			SourceURL: "https://news.example.com/jobs",
+			Excerpt:   "The US unemployment rate averaged 3.6% in 2023.",
+		},
+		{
+			Name:      "Global smartphone users",
+			Value:     6.8,
+			Unit:      "billion",
+			Source:    "Statista",
+			SourceURL: "https://statista.example.com/phones",
+			Excerpt:   "There are 6.8 billion smartphone users worldwide.",
+		},
+		{
+			Name:      "Unemployment rate in the US, 2023",
+			Value:     3.6,
+			Unit:      "%",
+			Source:    "BLS",
+			SourceURL: "https://www.bls.gov/cps/",
+			Excerpt:   "In 2023, the unemployment rate in the US averaged 3.6 percent.",
+		},
+		{
+			Name:      "US unemployment rate in 2022",
+			Value:     3.7,
+			Unit:      "%",
+			Source:    "BLS",
+			SourceURL: "https://www.bls.gov/cps/2022",
+			Excerpt:   "The US unemployment rate averaged 3.7% in 2022.",
+		},
+	}
+
+	d := New(embed.NewLocal(), embed.DefaultThreshold("local"), nil)
+	got, merged := d.Dedupe(context.Background(), stats)
+
+	if merged != 1 || len(got) != 3 {
+		t.Fatalf("merged = %d, remaining = %d; want 1 and 3", merged, len(got))
+	}
+	// The .gov source is kept as the representative, in the original order
+	if got[0].Name != "Global smartphone users" || got[1].SourceURL != "https://www.bls.gov/cps/" {
+		t.Errorf("unexpected order or representative: %+v", got)
+	}
+	if len(got[1].CorroboratedBy) != 1 || got[1].CorroboratedBy[0].SourceURL != "https://news.example.com/jobs" {
+		t.Errorf("corroborations = %+v", got[1].CorroboratedBy)
+	}
+	// A different value is never merged, however similar the wording
+	if got[2].Value != 3.7 || len(got[2].CorroboratedBy) != 0 {
+		t.Errorf("different value was merged: %+v", got[2])
+	}
+}
+
+func TestDedupeKeepsUnrelatedStatistics(t *testing.T) {
+	stats := []models.Statistic{
+		{Name: "Solar capacity added in 2023", Value: 32, Unit: "GW", Excerpt: "The US added 32 GW of solar capacity."},
+		{Name: "Wind capacity added in 2023", Value: 32, Unit: "GW", Excerpt: "Offshore wind turbines totaling 32 GW were commissioned in Europe."},
+	}
+	d := New(embed.NewLocal(), embed.DefaultThreshold("local"), nil)
+	if got, merged := d.Dedupe(context.Background(), stats); merged != 0 || len(got) != 2 {
+		t.Errorf("merged = %d, remaining = %d; want 0 and 2", merged, len(got))
+	}
+}
+
+func TestDedupeKeepsOtherYears(t *testing.T) {
+	stats := []models.Statistic{
+		{Name: "US unemployment rate in 2023", Value: 3.6, Unit: "%", SourceURL: "https://www.bls.gov/cps/2023", Excerpt: "The US unemployment rate averaged 3.6% in 2023."},
+		{Name: "US unemployment rate in 2019", Value: 3.6, Unit: "%", SourceURL: "https://www.bls.gov/cps/2019", Excerpt: "The US unemployment rate averaged 3.6% in 2019."},
+		{Name: "US unemployment rate", Value: 3.6, Unit: "%", SourceURL: "https://news.example.com/jobs", Excerpt: "The US unemployment rate averaged 3.6%."},
+	}
+	d := New(embed.NewLocal(), embed.DefaultThreshold("local"), nil)
+	if got, merged := d.Dedupe(context.Background(), stats); merged != 0 || len(got) != 3 {
+		t.Errorf("merged = %d, remaining = %d; want the same value for other years kept apart", merged, len(got))
+	}
+}
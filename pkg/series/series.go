@@ -0,0 +1,103 @@
+// Package series assembles chart-ready data series: when one source gives the
+// same metric for several years, as data files, tables, and World Bank
+// indicators often do, its statistics are collected into a single series of
+// (year, value) points instead of being left as unrelated statistics.
+package series
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// minPoints is the fewest years that make a series worth charting
+const minPoints = 3
+
+var (
+	yearPattern = regexp.MustCompile(`\b(19\d{2}|20\d{2}|2100)\b`)
+	// emptyBrackets, dangling, and separators clean up a name once its year
+	// is removed: "GDP - World ()" and "Population in" both lose their tails
+	emptyBrackets = regexp.MustCompile(`\(\s*\)|\[\s*\]`)
+	dangling      = regexp.MustCompile(`(?i)(\s+(in|for|of|as of|during|by|at end of|year))+\s*$`)
+	separators    = regexp.MustCompile(`^[\s,:;\-–—]+|[\s,:;\-–—]+$`)
+	spaces        = regexp.MustCompile(`\s+`)
+)
+
+// Assemble returns the series in stats, in the order of their first
+// statistic. A series is built from the statistics with the same source URL,
+// unit, and name once their year is removed, where each name holds exactly
+// one year. Groups with fewer than three years are left out, as are groups
+// with two different values for one year, which are breakdowns rather than a
+// time series.
+func Assemble(stats []models.Statistic) []models.Series {
+	type group struct {
+		series models.Series
+		values map[int]float32
+		mixed  bool
+	}
+	groups := make(map[string]*group)
+	var order []string
+
+	for _, s := range stats {
+		year, metric, ok := split(s.Name)
+		if !ok {
+			continue
+		}
+		key := s.SourceURL + "|" + strings.ToLower(strings.TrimSpace(s.Unit)) + "|" + strings.ToLower(metric)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{
+				series: models.Series{Metric: metric, Unit: s.Unit, Source: s.Source, SourceURL: s.SourceURL},
+				values: make(map[int]float32),
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+		if v, seen := g.values[year]; seen {
+			g.mixed = g.mixed || v != s.Value
+			continue
+		}
+		g.values[year] = s.Value
+		g.series.Points = append(g.series.Points, models.SeriesPoint{Year: year, Value: s.Value})
+	}
+
+	var out []models.Series
+	for _, key := range order {
+		g := groups[key]
+		if g.mixed || len(g.series.Points) < minPoints {
+			continue
+		}
+		slices.SortFunc(g.series.Points, func(a, b models.SeriesPoint) int { return cmp.Compare(a.Year, b.Year) })
+		out = append(out, g.series)
+	}
+	return out
+}
+
+// split returns the single year in a statistic's name and the name without
+// it, e.g. 2020 and "GDP growth - World" for "GDP growth - World (2020)"
+func split(name string) (int, string, bool) {
+	years := yearPattern.FindAllString(name, -1)
+	if len(years) == 0 || slices.ContainsFunc(years, func(y string) bool { return y != years[0] }) {
+		return 0, "", false
+	}
+	year, _ := strconv.Atoi(years[0])
+
+	metric := yearPattern.ReplaceAllString(name, "")
+	metric = emptyBrackets.ReplaceAllString(metric, "")
+	metric = spaces.ReplaceAllString(strings.TrimSpace(metric), " ")
+	for {
+		trimmed := separators.ReplaceAllString(dangling.ReplaceAllString(metric, ""), "")
+		if trimmed == metric {
+			break
+		}
+		metric = trimmed
+	}
+	if metric == "" {
+		return 0, "", false
+	}
+	return year, metric, true
+}
@@ -0,0 +1,63 @@
+package series
+
+import (
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestSplit(t *testing.T) {
+	for name, want := range map[string]string{
+		"GDP growth (annual %) - World (2020)": "GDP growth (annual %) - World",
+		"Population - 2021":                    "Population",
+		"Global EV sales in 2023":              "Global EV sales",
+		"2019: Median household income":        "Median household income",
+	} {
+		if _, got, ok := split(name); !ok || got != want {
+			t.Errorf("split(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+	for _, name := range []string{"Growth from 2010 to 2020", "Unemployment rate", "2020"} {
+		if _, got, ok := split(name); ok {
+			t.Errorf("split(%q) = %q; want no year", name, got)
+		}
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	wb := "https://api.worldbank.org/v2/country/WLD/indicator/SP.POP.TOTL"
+	stats := []models.Statistic{
+		{Name: "Population - World (2022)", Value: 7.95, Unit: "billion", Source: "World Bank", SourceURL: wb},
+		{Name: "Unemployment rate", Value: 3.9, Unit: "%", SourceURL: "https://bls.gov"},
+		{Name: "Population - World (2020)", Value: 7.82, Unit: "billion", Source: "World Bank", SourceURL: wb},
+		{Name: "Population - World (2021)", Value: 7.89, Unit: "billion", Source: "World Bank", SourceURL: wb},
+		{Name: "Population - World (2021)", Value: 7.89, Unit: "billion", Source: "World Bank", SourceURL: wb},
+		// Two years only
+		{Name: "EV sales in 2022", Value: 10.5, Unit: "million", SourceURL: "https://iea.org"},
+		{Name: "EV sales in 2023", Value: 14, Unit: "million", SourceURL: "https://iea.org"},
+		// Same years from another page
+		{Name: "Population - World (2023)", Value: 8.0, Unit: "billion", SourceURL: "https://un.org"},
+		// Two values for one year: a breakdown, not a series
+		{Name: "Share of adults online, 2020", Value: 60, Unit: "%", SourceURL: "https://pew.org"},
+		{Name: "Share of adults online, 2020", Value: 80, Unit: "%", SourceURL: "https://pew.org"},
+		{Name: "Share of adults online, 2021", Value: 85, Unit: "%", SourceURL: "https://pew.org"},
+		{Name: "Share of adults online, 2022", Value: 88, Unit: "%", SourceURL: "https://pew.org"},
+	}
+
+	got := Assemble(stats)
+	if len(got) != 1 {
+		t.Fatalf("Assemble = %+v; want one series", got)
+	}
+	s := got[0]
+	if s.Metric != "Population - World" || s.Unit != "billion" || s.SourceURL != wb || len(s.Points) != 3 {
+		t.Fatalf("series = %+v", s)
+	}
+	for i, year := range []int{2020, 2021, 2022} {
+		if s.Points[i].Year != year {
+			t.Errorf("Points = %+v; want years 2020-2022 in order", s.Points)
+		}
+	}
+	if s.Points[0].Value != 7.82 {
+		t.Errorf("Points[0] = %+v", s.Points[0])
+	}
+}
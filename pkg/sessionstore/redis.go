@@ -0,0 +1,367 @@
+package sessionstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/adk/session"
+)
+
+// DefaultKeyPrefix namespaces session keys when no prefix is configured
+const DefaultKeyPrefix = "stats-agent:"
+
+// RedisService is a session.Service backed by Redis. Each session is stored
+// as a metadata key holding its last update time, a hash of session-scoped
+// state, and a list of events; app- and user-scoped state live in shared
+// hashes. Session keys expire after the configured idle TTL.
+type RedisService struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisService connects to the Redis server at redisURL (for example
+// redis://localhost:6379/0). A zero ttl keeps sessions until they are deleted.
+func NewRedisService(ctx context.Context, redisURL, prefix string, ttl time.Duration) (*RedisService, error) {
+	if redisURL == "" {
+		return nil, fmt.Errorf("REDIS_URL is required for the redis session backend")
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return NewRedisServiceFromClient(client, prefix, ttl), nil
+}
+
+// NewRedisServiceFromClient creates a service using an existing client
+func NewRedisServiceFromClient(client *redis.Client, prefix string, ttl time.Duration) *RedisService {
+	if prefix == "" {
+		prefix = DefaultKeyPrefix
+	}
+	return &RedisService{client: client, prefix: prefix, ttl: ttl}
+}
+
+// Close closes the Redis connection
+func (s *RedisService) Close() error {
+	return s.client.Close()
+}
+
+// Create implements session.Service
+func (s *RedisService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = uuid.NewString()
+	}
+
+	now := time.Now()
+	base := s.sessionKey(req.AppName, req.UserID, sessionID)
+	created, err := s.client.SetNX(ctx, base, formatTime(now), s.ttl).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	if !created {
+		return nil, fmt.Errorf("session %s already exists", sessionID)
+	}
+
+	appDelta, userDelta, sessDelta := splitState(req.State)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if err := s.writeState(ctx, pipe, s.appStateKey(req.AppName), appDelta); err != nil {
+			return err
+		}
+		if err := s.writeState(ctx, pipe, s.userStateKey(req.AppName, req.UserID), userDelta); err != nil {
+			return err
+		}
+		if err := s.writeState(ctx, pipe, base+":state", sessDelta); err != nil {
+			return err
+		}
+		pipe.SAdd(ctx, s.indexKey(req.AppName), indexMember(req.UserID, sessionID))
+		s.expire(ctx, pipe, base+":state")
+		return nil
+	})
+	if err != nil {
+		s.client.Del(ctx, base)
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	resp, err := s.Get(ctx, &session.GetRequest{AppName: req.AppName, UserID: req.UserID, SessionID: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	return &session.CreateResponse{Session: resp.Session}, nil
+}
+
+// Get implements session.Service
+func (s *RedisService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+
+	base := s.sessionKey(req.AppName, req.UserID, req.SessionID)
+	start := int64(0)
+	if req.NumRecentEvents > 0 {
+		start = -int64(req.NumRecentEvents)
+	}
+
+	pipe := s.client.Pipeline()
+	meta := pipe.Get(ctx, base)
+	sessState := pipe.HGetAll(ctx, base+":state")
+	appState := pipe.HGetAll(ctx, s.appStateKey(req.AppName))
+	userState := pipe.HGetAll(ctx, s.userStateKey(req.AppName, req.UserID))
+	rawEvents := pipe.LRange(ctx, base+":events", start, -1)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+
+	if errors.Is(meta.Err(), redis.Nil) {
+		return nil, fmt.Errorf("session %+v not found", req.SessionID)
+	}
+	updatedAt, err := parseTime(meta.Val())
+	if err != nil {
+		return nil, fmt.Errorf("corrupt session %s: %w", req.SessionID, err)
+	}
+
+	sess := &storedSession{
+		appName:   req.AppName,
+		userID:    req.UserID,
+		sessionID: req.SessionID,
+		updatedAt: updatedAt,
+	}
+	app, err := decodeState(appState.Val())
+	if err != nil {
+		return nil, err
+	}
+	user, err := decodeState(userState.Val())
+	if err != nil {
+		return nil, err
+	}
+	state, err := decodeState(sessState.Val())
+	if err != nil {
+		return nil, err
+	}
+	sess.state = mergeState(app, user, state)
+
+	for _, raw := range rawEvents.Val() {
+		var event session.Event
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			return nil, fmt.Errorf("corrupt event in session %s: %w", req.SessionID, err)
+		}
+		if !req.After.IsZero() && event.Timestamp.Before(req.After) {
+			continue
+		}
+		sess.events = append(sess.events, &event)
+	}
+
+	return &session.GetResponse{Session: sess}, nil
+}
+
+// List implements session.Service. When UserID is empty, sessions of every
+// user of the app are returned. Index entries of expired sessions are removed.
+func (s *RedisService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+
+	members, err := s.client.SMembers(ctx, s.indexKey(req.AppName)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]session.Session, 0, len(members))
+	for _, member := range members {
+		userID, sessionID, ok := parseIndexMember(member)
+		if !ok || (req.UserID != "" && userID != req.UserID) {
+			continue
+		}
+		resp, err := s.Get(ctx, &session.GetRequest{AppName: req.AppName, UserID: userID, SessionID: sessionID})
+		if err != nil {
+			if exists, _ := s.client.Exists(ctx, s.sessionKey(req.AppName, userID, sessionID)).Result(); exists == 0 {
+				s.client.SRem(ctx, s.indexKey(req.AppName), member)
+				continue
+			}
+			return nil, err
+		}
+		sessions = append(sessions, resp.Session)
+	}
+	return &session.ListResponse{Sessions: sessions}, nil
+}
+
+// Delete implements session.Service
+func (s *RedisService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+	base := s.sessionKey(req.AppName, req.UserID, req.SessionID)
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, base, base+":state", base+":events")
+		pipe.SRem(ctx, s.indexKey(req.AppName), indexMember(req.UserID, req.SessionID))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// AppendEvent implements session.Service. The event is applied to the given
+// session and persisted together with its state changes in one transaction.
+func (s *RedisService) AppendEvent(ctx context.Context, curSession session.Session, event *session.Event) error {
+	if curSession == nil {
+		return fmt.Errorf("session is nil")
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	if event.Partial {
+		return nil
+	}
+	sess, ok := curSession.(*storedSession)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", curSession)
+	}
+
+	base := s.sessionKey(sess.appName, sess.userID, sess.sessionID)
+	exists, err := s.client.Exists(ctx, base).Result()
+	if err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("session %s not found, cannot apply event", sess.sessionID)
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	trimmed := trimTemp(event)
+	data, err := json.Marshal(trimmed)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	appDelta, userDelta, sessDelta := splitState(trimmed.Actions.StateDelta)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if err := s.writeState(ctx, pipe, s.appStateKey(sess.appName), appDelta); err != nil {
+			return err
+		}
+		if err := s.writeState(ctx, pipe, s.userStateKey(sess.appName, sess.userID), userDelta); err != nil {
+			return err
+		}
+		if err := s.writeState(ctx, pipe, base+":state", sessDelta); err != nil {
+			return err
+		}
+		pipe.RPush(ctx, base+":events", data)
+		pipe.Set(ctx, base, formatTime(event.Timestamp), s.ttl)
+		s.expire(ctx, pipe, base+":state", base+":events")
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+
+	sess.apply(event)
+	return nil
+}
+
+// writeState queues an update of a state hash, encoding each value as JSON
+func (s *RedisService) writeState(ctx context.Context, pipe redis.Pipeliner, key string, delta map[string]any) error {
+	if len(delta) == 0 {
+		return nil
+	}
+	fields := make(map[string]any, len(delta))
+	for k, v := range delta {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode state key %q: %w", k, err)
+		}
+		fields[k] = data
+	}
+	pipe.HSet(ctx, key, fields)
+	return nil
+}
+
+// expire queues a TTL refresh of session keys, if a TTL is configured
+func (s *RedisService) expire(ctx context.Context, pipe redis.Pipeliner, keys ...string) {
+	if s.ttl <= 0 {
+		return
+	}
+	for _, key := range keys {
+		pipe.Expire(ctx, key, s.ttl)
+	}
+}
+
+// sessionKey is the metadata key of a session; its state and events use
+// the same key with ":state" and ":events" suffixes
+func (s *RedisService) sessionKey(appName, userID, sessionID string) string {
+	return s.prefix + "session:" + escape(appName) + ":" + escape(userID) + ":" + escape(sessionID)
+}
+
+func (s *RedisService) indexKey(appName string) string {
+	return s.prefix + "sessions:" + escape(appName)
+}
+
+func (s *RedisService) appStateKey(appName string) string {
+	return s.prefix + "appstate:" + escape(appName)
+}
+
+func (s *RedisService) userStateKey(appName, userID string) string {
+	return s.prefix + "userstate:" + escape(appName) + ":" + escape(userID)
+}
+
+// escape makes a name safe to use as a ':'-separated key component
+func escape(name string) string {
+	return url.QueryEscape(name)
+}
+
+func indexMember(userID, sessionID string) string {
+	return escape(userID) + ":" + escape(sessionID)
+}
+
+func parseIndexMember(member string) (userID, sessionID string, ok bool) {
+	rawUser, rawSession, found := strings.Cut(member, ":")
+	if !found {
+		return "", "", false
+	}
+	userID, err1 := url.QueryUnescape(rawUser)
+	sessionID, err2 := url.QueryUnescape(rawSession)
+	return userID, sessionID, err1 == nil && err2 == nil
+}
+
+// decodeState decodes a state hash written by writeState
+func decodeState(fields map[string]string) (map[string]any, error) {
+	state := make(map[string]any, len(fields))
+	for k, raw := range fields {
+		var v any
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("corrupt state key %q: %w", k, err)
+		}
+		state[k] = v
+	}
+	return state, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func parseTime(s string) (time.Time, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, n), nil
+}
@@ -0,0 +1,108 @@
+package sessionstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func newTestService(t *testing.T) (*RedisService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisServiceFromClient(client, "", time.Hour), mr
+}
+
+func TestRedisServiceRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newTestService(t)
+
+	created, err := svc.Create(ctx, &session.CreateRequest{
+		AppName:   "app",
+		UserID:    "user",
+		SessionID: "s1",
+		State:     map[string]any{"topic": "solar", "app:region": "us", "temp:scratch": 1},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := svc.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s1"}); err == nil {
+		t.Error("expected duplicate session error")
+	}
+
+	event := session.NewEvent("inv-1")
+	event.Author = "user"
+	event.Content = genai.NewContentFromText("find solar statistics", genai.RoleUser)
+	event.Actions.StateDelta = map[string]any{"turns": 1, "user:lang": "en", "temp:x": true}
+	if err := svc.AppendEvent(ctx, created.Session, event); err != nil {
+		t.Fatalf("AppendEvent: %v", err)
+	}
+
+	// A fresh Get simulates another replica (or a restart) picking up the session
+	got, err := svc.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	sess := got.Session
+	for key, want := range map[string]any{"topic": "solar", "app:region": "us", "user:lang": "en", "turns": float64(1)} {
+		if v, err := sess.State().Get(key); err != nil || v != want {
+			t.Errorf("state[%q] = %v, %v; want %v", key, v, err, want)
+		}
+	}
+	for _, key := range []string{"temp:scratch", "temp:x"} {
+		if _, err := sess.State().Get(key); err == nil {
+			t.Errorf("temporary key %q was persisted", key)
+		}
+	}
+	if sess.Events().Len() != 1 {
+		t.Fatalf("events = %d, want 1", sess.Events().Len())
+	}
+	if text := sess.Events().At(0).Content.Parts[0].Text; text != "find solar statistics" {
+		t.Errorf("event text = %q", text)
+	}
+
+	// App-scoped state is shared with other users' sessions
+	other, err := svc.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "other"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if v, _ := other.Session.State().Get("app:region"); v != "us" {
+		t.Errorf("app state not shared: %v", v)
+	}
+
+	list, err := svc.List(ctx, &session.ListRequest{AppName: "app", UserID: "user"})
+	if err != nil || len(list.Sessions) != 1 {
+		t.Fatalf("List = %v, %v; want 1 session", list, err)
+	}
+
+	if err := svc.Delete(ctx, &session.DeleteRequest{AppName: "app", UserID: "user", SessionID: "s1"}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := svc.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s1"}); err == nil {
+		t.Error("expected deleted session to be gone")
+	}
+}
+
+func TestRedisServiceExpiry(t *testing.T) {
+	ctx := context.Background()
+	svc, mr := newTestService(t)
+
+	if _, err := svc.Create(ctx, &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s1"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	mr.FastForward(2 * time.Hour)
+
+	if _, err := svc.Get(ctx, &session.GetRequest{AppName: "app", UserID: "user", SessionID: "s1"}); err == nil {
+		t.Error("expected expired session to be gone")
+	}
+	list, err := svc.List(ctx, &session.ListRequest{AppName: "app"})
+	if err != nil || len(list.Sessions) != 0 {
+		t.Errorf("List = %v, %v; want no sessions", list, err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package sessionstore provides the ADK session service used by the A2A
+// servers. The default in-memory service loses multi-turn conversations on
+// restart and cannot be shared between replicas; the Redis backend keeps
+// sessions, their events, and app- and user-scoped state in Redis so any
+// replica can continue a conversation.
+package sessionstore
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/session"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+)
+
+// New creates the session service selected by cfg.SessionBackend
+func New(ctx context.Context, cfg *config.Config) (session.Service, error) {
+	switch cfg.SessionBackend {
+	case "", "memory":
+		return session.InMemoryService(), nil
+	case "redis":
+		ttl := time.Duration(cfg.SessionTTLHours) * time.Hour
+		return NewRedisService(ctx, cfg.RedisURL, cfg.SessionKeyPrefix, ttl)
+	default:
+		return nil, fmt.Errorf("unsupported session backend: %s (supported: memory, redis)", cfg.SessionBackend)
+	}
+}
+
+// splitState separates a state delta into app-scoped, user-scoped, and
+// session-scoped keys, stripping the scope prefixes. Temporary keys are
+// dropped because they only live for a single invocation.
+func splitState(delta map[string]any) (app, user, sess map[string]any) {
+	app, user, sess = make(map[string]any), make(map[string]any), make(map[string]any)
+	for key, value := range delta {
+		if k, ok := strings.CutPrefix(key, session.KeyPrefixApp); ok {
+			app[k] = value
+		} else if k, ok := strings.CutPrefix(key, session.KeyPrefixUser); ok {
+			user[k] = value
+		} else if !strings.HasPrefix(key, session.KeyPrefixTemp) {
+			sess[key] = value
+		}
+	}
+	return app, user, sess
+}
+
+// mergeState combines scoped state into the view a session exposes, with app
+// and user keys carrying their prefixes
+func mergeState(app, user, sess map[string]any) map[string]any {
+	merged := make(map[string]any, len(app)+len(user)+len(sess))
+	maps.Copy(merged, sess)
+	for k, v := range app {
+		merged[session.KeyPrefixApp+k] = v
+	}
+	for k, v := range user {
+		merged[session.KeyPrefixUser+k] = v
+	}
+	return merged
+}
+
+// trimTemp returns the event without temporary state keys, which are not persisted
+func trimTemp(event *session.Event) *session.Event {
+	if len(event.Actions.StateDelta) == 0 {
+		return event
+	}
+	delta := make(map[string]any, len(event.Actions.StateDelta))
+	for k, v := range event.Actions.StateDelta {
+		if !strings.HasPrefix(k, session.KeyPrefixTemp) {
+			delta[k] = v
+		}
+	}
+	trimmed := *event
+	trimmed.Actions.StateDelta = delta
+	return &trimmed
+}
+
+// storedSession is a session loaded from a backend
+type storedSession struct {
+	appName   string
+	userID    string
+	sessionID string
+
+	mu        sync.RWMutex
+	state     map[string]any
+	events    []*session.Event
+	updatedAt time.Time
+}
+
+func (s *storedSession) ID() string      { return s.sessionID }
+func (s *storedSession) AppName() string { return s.appName }
+func (s *storedSession) UserID() string  { return s.userID }
+
+func (s *storedSession) State() session.State { return &sessionState{s: s} }
+
+func (s *storedSession) Events() session.Events {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return eventList(s.events)
+}
+
+func (s *storedSession) LastUpdateTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updatedAt
+}
+
+// apply adds an event to the in-memory copy of the session
+func (s *storedSession) apply(event *session.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range event.Actions.StateDelta {
+		if !strings.HasPrefix(k, session.KeyPrefixTemp) {
+			s.state[k] = v
+		}
+	}
+	s.events = append(s.events, trimTemp(event))
+	s.updatedAt = event.Timestamp
+}
+
+// sessionState implements session.State over a stored session
+type sessionState struct {
+	s *storedSession
+}
+
+func (st *sessionState) Get(key string) (any, error) {
+	st.s.mu.RLock()
+	defer st.s.mu.RUnlock()
+	v, ok := st.s.state[key]
+	if !ok {
+		return nil, session.ErrStateKeyNotExist
+	}
+	return v, nil
+}
+
+func (st *sessionState) Set(key string, value any) error {
+	st.s.mu.Lock()
+	defer st.s.mu.Unlock()
+	st.s.state[key] = value
+	return nil
+}
+
+func (st *sessionState) All() iter.Seq2[string, any] {
+	st.s.mu.RLock()
+	copied := maps.Clone(st.s.state)
+	st.s.mu.RUnlock()
+	return func(yield func(string, any) bool) {
+		for k, v := range copied {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// eventList implements session.Events
+type eventList []*session.Event
+
+func (e eventList) All() iter.Seq[*session.Event] {
+	return func(yield func(*session.Event) bool) {
+		for _, event := range e {
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+func (e eventList) Len() int { return len(e) }
+
+func (e eventList) At(i int) *session.Event {
+	if i >= 0 && i < len(e) {
+		return e[i]
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package sourcelist turns the sources a request lists into the search
+// results synthesis reads, replacing the research stage. Pages are read from
+// their URLs; documents sent inline are stored in the snapshot archive and
+// read from there at their document: URL, so synthesis and verification see
+// the same bytes.
+package sourcelist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/extract"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// ErrNoArchive is returned for documents when no snapshot archive is set
+var ErrNoArchive = errors.New("documents in sources need a snapshot archive (set ARCHIVE_BACKEND)")
+
+// Store puts the documents among sources in the archive, where the agents
+// read them
+func Store(ctx context.Context, store archive.Store, sources []models.InputSource) error {
+	for i, s := range sources {
+		if !s.IsDocument() {
+			continue
+		}
+		if store == nil {
+			return ErrNoArchive
+		}
+		if _, err := store.Put(ctx, archive.NewSnapshot(archive.DocumentURL(archive.Hash(s.Data)), contentType(s), s.Data)); err != nil {
+			return fmt.Errorf("failed to store sources[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Results returns the search results for sources, in order. A document's
+// domain is its filename, shown as the source of its statistics.
+func Results(sources []models.InputSource) []models.SearchResult {
+	results := make([]models.SearchResult, 0, len(sources))
+	for _, s := range sources {
+		r := models.SearchResult{URL: s.URL, Title: s.Title}
+		if s.IsDocument() {
+			r.URL = archive.DocumentURL(archive.Hash(s.Data))
+			r.Domain = s.Filename
+		} else if u, err := url.Parse(s.URL); err == nil {
+			r.Domain = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+		if r.Title == "" {
+			r.Title = s.Filename
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// Page returns the results of the pass starting at offset, at most size of
+// them, and the offset of the next pass, 0 after the last
+func Page(results []models.SearchResult, offset, size int) ([]models.SearchResult, int) {
+	if offset >= len(results) {
+		return nil, 0
+	}
+	end := len(results)
+	if size > 0 {
+		end = min(end, offset+size)
+	}
+	if end == len(results) {
+		return results[offset:], 0
+	}
+	return results[offset:end], end
+}
+
+// Passes returns how many passes of size read every result
+func Passes(results []models.SearchResult, size int) int {
+	if size <= 0 {
+		return 1
+	}
+	return (len(results) + size - 1) / size
+}
+
+// contentType returns a document's declared media type, or one guessed from
+// its filename or, failing that, its first bytes
+func contentType(s models.InputSource) string {
+	if s.ContentType != "" {
+		return s.ContentType
+	}
+	if f := extract.DetectFormat("", s.Filename); f != extract.FormatHTML {
+		return f.MediaType()
+	}
+	if t := mime.TypeByExtension(path.Ext(s.Filename)); t != "" {
+		return t
+	}
+	return http.DetectContentType(s.Data)
+}
@@ -0,0 +1,78 @@
+package sourcelist
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/archive"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestResults(t *testing.T) {
+	sources := []models.InputSource{
+		{URL: "https://www.census.gov/report", Title: "Census report"},
+		{Filename: "q3.csv", Data: []byte("region,share\nnorth,12\n")},
+	}
+	results := Results(sources)
+	if len(results) != 2 {
+		t.Fatalf("Results() = %d results, want 2", len(results))
+	}
+	if r := results[0]; r.URL != sources[0].URL || r.Domain != "census.gov" || r.Title != "Census report" {
+		t.Errorf("Results()[0] = %+v", r)
+	}
+	if r := results[1]; r.URL != archive.DocumentURL(archive.Hash(sources[1].Data)) || r.Domain != "q3.csv" || r.Title != "q3.csv" {
+		t.Errorf("Results()[1] = %+v", r)
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	sources := []models.InputSource{
+		{URL: "https://example.com/report"},
+		{Filename: "q3.html", Data: []byte("<p>Share of the north: 12%</p>")},
+	}
+	if err := Store(ctx, nil, sources[:1]); err != nil {
+		t.Errorf("Store(urls only) without an archive = %v", err)
+	}
+	if err := Store(ctx, nil, sources); !errors.Is(err, ErrNoArchive) {
+		t.Errorf("Store(documents) without an archive = %v, want ErrNoArchive", err)
+	}
+
+	store, err := archive.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Store(ctx, store, sources); err != nil {
+		t.Fatal(err)
+	}
+	hash, _ := archive.DocumentHash(Results(sources)[1].URL)
+	snap, err := store.Get(ctx, hash)
+	if err != nil || snap.ContentType != "text/html; charset=utf-8" || string(snap.Body) != string(sources[1].Data) {
+		t.Errorf("stored document = %+v, %v", snap, err)
+	}
+}
+
+func TestPage(t *testing.T) {
+	results := make([]models.SearchResult, 5)
+	tests := []struct {
+		offset, size   int
+		want, wantNext int
+	}{
+		{0, 2, 2, 2},
+		{2, 2, 2, 4},
+		{4, 2, 1, 0},
+		{0, 5, 5, 0},
+		{0, 0, 5, 0},
+		{5, 2, 0, 0},
+	}
+	for _, tt := range tests {
+		page, next := Page(results, tt.offset, tt.size)
+		if len(page) != tt.want || next != tt.wantNext {
+			t.Errorf("Page(%d, %d) = %d results, next %d, want %d, next %d", tt.offset, tt.size, len(page), next, tt.want, tt.wantNext)
+		}
+	}
+	if got := Passes(results, 2); got != 3 {
+		t.Errorf("Passes(5, 2) = %d, want 3", got)
+	}
+}
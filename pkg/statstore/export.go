@@ -0,0 +1,274 @@
+package statstore
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/parquet"
+	"github.com/plexusone/agent-team-stats/pkg/prioritize"
+)
+
+// ExportFormat is a bulk export format of the stored statistics
+type ExportFormat string
+
+// Bulk export formats
+const (
+	FormatNDJSON  ExportFormat = "ndjson"
+	FormatCSV     ExportFormat = "csv"
+	FormatParquet ExportFormat = "parquet"
+	FormatJSONLD  ExportFormat = "jsonld" // Knowledge graph as schema.org JSON-LD
+	FormatCypher  ExportFormat = "cypher" // Knowledge graph as a Neo4j Cypher script
+)
+
+// ExportFormats lists the supported formats
+var ExportFormats = []ExportFormat{FormatNDJSON, FormatCSV, FormatParquet, FormatJSONLD, FormatCypher}
+
+// ContentType returns the MIME type of the format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	case FormatJSONLD:
+		return "application/ld+json"
+	case FormatCypher:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// columns are the CSV and Parquet columns, one row per statistic. NDJSON
+// lines carry every field instead.
+var columns = []parquet.Column{
+	{Name: "id", Type: parquet.String},
+	{Name: "name", Type: parquet.String},
+	{Name: "value", Type: parquet.Double},
+	{Name: "unit", Type: parquet.String},
+	{Name: "source", Type: parquet.String},
+	{Name: "source_url", Type: parquet.String},
+	{Name: "publisher", Type: parquet.String},
+	{Name: "published_at", Type: parquet.String}, // YYYY-MM-DD, empty when the source is undated
+	{Name: "domain", Type: parquet.String},
+	{Name: "domain_tier", Type: parquet.String},
+	{Name: "confidence", Type: parquet.Double},
+	{Name: "corroborations", Type: parquet.Int64},
+	{Name: "topics", Type: parquet.String},
+	{Name: "excerpt", Type: parquet.String},
+	{Name: "content_hash", Type: parquet.String},
+	{Name: "first_seen", Type: parquet.Timestamp},
+	{Name: "last_seen", Type: parquet.Timestamp},
+}
+
+// topicSeparator joins a statistic's topics into one column
+const topicSeparator = "; "
+
+// row returns the column values of a statistic
+func row(s models.StoredStatistic) []any {
+	return []any{
+		s.ID, s.Name, widen(s.Value), s.Unit, s.Source, s.SourceURL, s.Publisher, date(s.PublishedAt), s.Domain, s.DomainTier,
+		s.Confidence, int64(len(s.CorroboratedBy)), strings.Join(s.Topics, topicSeparator),
+		s.Excerpt, s.ContentHash, s.FirstSeen, s.LastSeen,
+	}
+}
+
+// date formats a publication date, or empty when unknown
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+// widen converts a float32 value to the float64 with the same shortest
+// decimal form, so 3.9 exports as 3.9 rather than 3.9000000953674316
+func widen(v float32) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+	return f
+}
+
+// Export writes stats to w in format f
+func Export(w io.Writer, stats []models.StoredStatistic, f ExportFormat) error {
+	switch f {
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, s := range stats {
+			if err := enc.Encode(s); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		header := make([]string, len(columns))
+		for i, c := range columns {
+			header[i] = c.Name
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, s := range stats {
+			if err := cw.Write(csvRecord(row(s))); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case FormatParquet:
+		pw := parquet.NewWriter(w, columns)
+		for _, s := range stats {
+			if err := pw.Write(row(s)); err != nil {
+				return err
+			}
+		}
+		return pw.Close()
+
+	case FormatJSONLD:
+		return exportJSONLD(w, stats)
+
+	case FormatCypher:
+		return exportCypher(w, stats)
+	}
+	return fmt.Errorf("unsupported export format: %s (supported: %s)", f, supportedFormats())
+}
+
+// supportedFormats lists the export formats for error messages
+func supportedFormats() string {
+	names := make([]string, len(ExportFormats))
+	for i, f := range ExportFormats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// csvRecord formats column values as CSV fields
+func csvRecord(values []any) []string {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case string:
+			record[i] = v
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case time.Time:
+			record[i] = v.UTC().Format(time.RFC3339)
+		}
+	}
+	return record
+}
+
+// ExportHandler returns the handler of GET /statistics/export, which
+// streams the stored statistics for analytics:
+//
+//	format          ndjson (default), csv, parquet, jsonld, or cypher
+//	topic           only statistics verified for this topic
+//	tier            comma-separated domain tiers: authoritative, research, other
+//	from, to        only statistics last verified in this range (RFC 3339 or YYYY-MM-DD, inclusive)
+//	min_confidence  only statistics with at least this confidence (0 to 1)
+//	published_after only statistics whose source declares a publication date on or after this one
+func (s *Store) ExportHandler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if s == nil {
+			http.Error(w, "statistics store is not enabled; set STATS_STORE_FILE", http.StatusNotFound)
+			return
+		}
+
+		params := r.URL.Query()
+		f := FormatNDJSON
+		if v := params.Get("format"); v != "" {
+			f = ExportFormat(strings.ToLower(v))
+		}
+		if !slices.Contains(ExportFormats, f) {
+			http.Error(w, fmt.Sprintf("unsupported format %q (supported: %s)", f, supportedFormats()), http.StatusBadRequest)
+			return
+		}
+		filter, err := ParseFilter(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		stats, err := s.List(filter)
+		if err != nil {
+			logger.Error("failed to list stored statistics", "error", err)
+			http.Error(w, fmt.Sprintf("Export failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", f.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "statistics."+string(f)))
+		if err := Export(w, stats, f); err != nil {
+			// Headers are sent; the client sees a truncated body
+			logger.Error("failed to export statistics", "format", f, "error", err)
+		}
+	}
+}
+
+// ParseFilter reads the topic, tier, from, to, and min_confidence query
+// parameters
+func ParseFilter(params url.Values) (Filter, error) {
+	f := Filter{Topic: strings.TrimSpace(params.Get("topic"))}
+	if v := params.Get("tier"); v != "" {
+		for _, tier := range strings.Split(v, ",") {
+			tier = strings.ToLower(strings.TrimSpace(tier))
+			if !slices.Contains(prioritize.Tiers, tier) {
+				return Filter{}, fmt.Errorf("unknown tier %q (supported: %s)", tier, strings.Join(prioritize.Tiers, ", "))
+			}
+			f.Tiers = append(f.Tiers, tier)
+		}
+	}
+	var err error
+	if f.From, err = ParseTime(params.Get("from"), false); err != nil {
+		return Filter{}, fmt.Errorf("invalid from: %w", err)
+	}
+	if f.To, err = ParseTime(params.Get("to"), true); err != nil {
+		return Filter{}, fmt.Errorf("invalid to: %w", err)
+	}
+	if f.PublishedAfter, err = ParseTime(params.Get("published_after"), false); err != nil {
+		return Filter{}, fmt.Errorf("invalid published_after: %w", err)
+	}
+	if v := params.Get("min_confidence"); v != "" {
+		if f.MinConfidence, err = strconv.ParseFloat(v, 64); err != nil || f.MinConfidence < 0 || f.MinConfidence > 1 {
+			return Filter{}, fmt.Errorf("min_confidence must be a number from 0 to 1, got %q", v)
+		}
+	}
+	return f, nil
+}
+
+// ParseTime parses an RFC 3339 time or a date. A date used as the end of a
+// range covers the whole day.
+func ParseTime(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC 3339 nor YYYY-MM-DD", s)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,196 @@
+package statstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prioritize"
+)
+
+func TestList(t *testing.T) {
+	s := newStore(t, nil)
+
+	all, err := s.List(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("listed %d statistics; want 3", len(all))
+	}
+	for _, stat := range all {
+		if stat.Domain == "" || stat.DomainTier == "" || stat.Confidence <= 0 {
+			t.Errorf("statistic %q not annotated: %+v", stat.Name, stat)
+		}
+	}
+
+	authoritative, _ := s.List(Filter{Tiers: []string{prioritize.TierAuthoritative}})
+	if len(authoritative) != 1 || authoritative[0].Domain != "eia.gov" {
+		t.Errorf("authoritative = %+v", authoritative)
+	}
+	topic, _ := s.List(Filter{Topic: "electric vehicles"})
+	if len(topic) != 2 {
+		t.Errorf("electric vehicles = %d statistics; want 2", len(topic))
+	}
+	future, _ := s.List(Filter{From: time.Now().Add(time.Hour)})
+	if len(future) != 0 {
+		t.Errorf("from the future = %+v", future)
+	}
+	recent, _ := s.List(Filter{PublishedAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if len(recent) != 1 || recent[0].Publisher != "U.S. Energy Information Administration" {
+		t.Errorf("published since 2024 = %+v", recent)
+	}
+	confident, _ := s.List(Filter{MinConfidence: 0.75})
+	if len(confident) != 1 || confident[0].Source != "EIA" {
+		t.Errorf("confidence >= 0.75 = %+v", confident)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := ParseFilter(url.Values{"tier": {"Authoritative, research"}, "from": {"2024-01-01"}, "to": {"2024-01-31"}, "min_confidence": {"0.6"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Tiers) != 2 || f.MinConfidence != 0.6 || !f.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("filter = %+v", f)
+	}
+	if f.To.Before(time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("to = %s; want the end of the day", f.To)
+	}
+
+	for _, bad := range []url.Values{
+		{"tier": {"blogs"}},
+		{"from": {"yesterday"}},
+		{"min_confidence": {"1.5"}},
+		{"published_after": {"last year"}},
+	} {
+		if _, err := ParseFilter(bad); err == nil {
+			t.Errorf("ParseFilter(%v) succeeded", bad)
+		}
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	handler := newStore(t, nil).ExportHandler(testLogger)
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/statistics/export?"+query, nil))
+		return rec
+	}
+
+	rec := get("topic=electric+vehicles")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("ndjson = %d %s: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	lines := 0
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var stat models.StoredStatistic
+		if err := json.Unmarshal(scanner.Bytes(), &stat); err != nil || stat.ID == "" {
+			t.Errorf("line %q: %v", scanner.Text(), err)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("ndjson lines = %d; want 2", lines)
+	}
+
+	rec = get("format=csv&tier=authoritative")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Content-Disposition"), "statistics.csv") {
+		t.Fatalf("csv = %d %v", rec.Code, rec.Header())
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0][0] != "id" || records[1][2] != "3.9" || records[1][7] != "2024-06-20" || records[1][9] != prioritize.TierAuthoritative {
+		t.Errorf("csv = %q", records)
+	}
+
+	rec = get("format=parquet")
+	body := rec.Body.Bytes()
+	if rec.Code != http.StatusOK || !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+		t.Errorf("parquet = %d, %d bytes", rec.Code, len(body))
+	}
+
+	if rec := get("format=xml"); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown format = %d; want 400", rec.Code)
+	}
+	if rec := get("to=tomorrow"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad date = %d; want 400", rec.Code)
+	}
+
+	var disabled *Store
+	rec = httptest.NewRecorder()
+	disabled.ExportHandler(testLogger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/statistics/export", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("disabled store = %d; want 404", rec.Code)
+	}
+}
+
+func TestExportGraph(t *testing.T) {
+	stats, err := newStore(t, nil).List(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats[0].Excerpt = "It's 38%."
+
+	var buf bytes.Buffer
+	if err := Export(&buf, stats, FormatJSONLD); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Graph []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("jsonld does not parse: %v\n%s", err, buf.String())
+	}
+	types := make(map[string]int)
+	ids := make(map[string]bool)
+	for _, node := range doc.Graph {
+		types[node["@type"].(string)]++
+		id := node["@id"].(string)
+		if ids[id] {
+			t.Errorf("node %s written twice", id)
+		}
+		ids[id] = true
+	}
+	// Three statistics and metrics, two topics, two pages sharing the IEA publisher
+	want := map[string]int{"Observation": 3, "StatisticalVariable": 3, "DefinedTerm": 2, "WebPage": 2, "Organization": 2}
+	for typ, n := range want {
+		if types[typ] != n {
+			t.Errorf("%d %s nodes; want %d (%v)", types[typ], typ, n, types)
+		}
+	}
+	if !ids["stats:topic/electric-vehicles"] || !ids["stats:publisher/u-s-energy-information-administration"] {
+		t.Errorf("node IDs = %v", ids)
+	}
+
+	buf.Reset()
+	if err := Export(&buf, stats, FormatCypher); err != nil {
+		t.Fatal(err)
+	}
+	script := buf.String()
+	if n := strings.Count(script, "MERGE (s:Statistic"); n != 3 {
+		t.Errorf("%d statistic statements; want 3", n)
+	}
+	for _, want := range []string{
+		`excerpt: 'It\'s 38%.'`,
+		"MERGE (s)-[:MEASURES]->(m)",
+		"MERGE (src)-[:PUBLISHED_BY]->(p)",
+		"MERGE (topic:Topic {key: t.key})",
+		"published_at: date('2024-06-20')",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("cypher lacks %q:\n%s", want, script)
+		}
+	}
+}
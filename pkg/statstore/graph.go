@@ -0,0 +1,274 @@
+package statstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Knowledge graph exports link each statistic to the metric it measures,
+// the topics it was verified for, the source page it was verified on, and
+// that page's publisher. Metrics, topics, and publishers are identified by
+// their normalized names, so statistics sharing one share its node.
+
+// idPrefix is the IRI prefix of the nodes of a JSON-LD export
+const idPrefix = "urn:agent-team-stats:"
+
+// jsonLDContext maps terms to schema.org, and the stats: prefix to the
+// store's own node IDs and the properties schema.org lacks
+var jsonLDContext = []any{"https://schema.org/", map[string]string{"stats": idPrefix}}
+
+// exportJSONLD writes stats as one JSON-LD document: a schema.org
+// Observation per statistic, with StatisticalVariable, DefinedTerm (topic),
+// WebPage (source), and Organization (publisher) nodes, each written once
+func exportJSONLD(w io.Writer, stats []models.StoredStatistic) error {
+	bw := bufio.NewWriter(w)
+	ctx, err := json.Marshal(jsonLDContext)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(bw, `{"@context":%s,"@graph":[`, ctx)
+
+	seen := make(map[string]bool)
+	first := true
+	emit := func(node map[string]any) error {
+		id := node["@id"].(string)
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+		data, err := json.Marshal(node)
+		if err != nil {
+			return err
+		}
+		if !first {
+			bw.WriteByte(',')
+		}
+		first = false
+		bw.WriteString("\n")
+		_, err = bw.Write(data)
+		return err
+	}
+	ref := func(id string) map[string]string { return map[string]string{"@id": id} }
+	var corroborating []models.Corroboration
+
+	for _, s := range stats {
+		obs := map[string]any{
+			"@id":              "stats:statistic/" + s.ID,
+			"@type":            "Observation",
+			"name":             s.Name,
+			"value":            widen(s.Value),
+			"variableMeasured": ref(metricID(s.Name)),
+			"stats:confidence": s.Confidence,
+			"stats:firstSeen":  s.FirstSeen.Format(time.RFC3339),
+			"stats:lastSeen":   s.LastSeen.Format(time.RFC3339),
+		}
+		if s.Unit != "" {
+			obs["unitText"] = s.Unit
+		}
+		if s.Excerpt != "" {
+			obs["description"] = s.Excerpt
+		}
+		if s.Type != "" {
+			obs["measurementMethod"] = string(s.Type)
+		}
+		if !s.PublishedAt.IsZero() {
+			obs["observationDate"] = date(s.PublishedAt)
+		}
+		if s.ContentHash != "" {
+			obs["stats:contentHash"] = s.ContentHash
+		}
+		var about []map[string]string
+		for _, topic := range s.Topics {
+			about = append(about, ref(topicID(topic)))
+		}
+		if len(about) > 0 {
+			obs["observationAbout"] = about
+		}
+		if s.SourceURL != "" {
+			obs["isBasedOn"] = ref(s.SourceURL)
+		}
+		var corroborations []map[string]string
+		for _, c := range s.CorroboratedBy {
+			if c.SourceURL != "" {
+				corroborations = append(corroborations, ref(c.SourceURL))
+			}
+		}
+		if len(corroborations) > 0 {
+			obs["stats:corroboratedBy"] = corroborations
+		}
+		if err := emit(obs); err != nil {
+			return err
+		}
+
+		if err := emit(map[string]any{"@id": metricID(s.Name), "@type": "StatisticalVariable", "name": s.Name}); err != nil {
+			return err
+		}
+		for _, topic := range s.Topics {
+			if err := emit(map[string]any{"@id": topicID(topic), "@type": "DefinedTerm", "name": topic}); err != nil {
+				return err
+			}
+		}
+		if s.SourceURL != "" {
+			page := map[string]any{"@id": s.SourceURL, "@type": "WebPage", "url": s.SourceURL, "stats:domainTier": s.DomainTier}
+			if s.Source != "" {
+				page["name"] = s.Source
+			}
+			publisher := publisherName(s)
+			if publisher != "" {
+				page["publisher"] = ref(publisherID(publisher))
+			}
+			if !s.PublishedAt.IsZero() {
+				page["datePublished"] = date(s.PublishedAt)
+			}
+			if s.License != nil {
+				if s.License.URL != "" {
+					page["license"] = s.License.URL
+				} else {
+					page["license"] = string(s.License.Kind)
+				}
+			}
+			if err := emit(page); err != nil {
+				return err
+			}
+			if publisher != "" {
+				if err := emit(map[string]any{"@id": publisherID(publisher), "@type": "Organization", "name": publisher}); err != nil {
+					return err
+				}
+			}
+		}
+		for _, c := range s.CorroboratedBy {
+			if c.SourceURL != "" {
+				corroborating = append(corroborating, c)
+			}
+		}
+	}
+
+	// Pages known only as corroborations come last, so a page that is also
+	// a statistic's source is written with everything known about it
+	for _, c := range corroborating {
+		page := map[string]any{"@id": c.SourceURL, "@type": "WebPage", "url": c.SourceURL}
+		if c.Source != "" {
+			page["name"] = c.Source
+		}
+		if err := emit(page); err != nil {
+			return err
+		}
+	}
+
+	bw.WriteString("\n]}\n")
+	return bw.Flush()
+}
+
+// cypherConstraints make repeated loads merge into the same nodes
+var cypherConstraints = []string{
+	"CREATE CONSTRAINT statistic_id IF NOT EXISTS FOR (n:Statistic) REQUIRE n.id IS UNIQUE;",
+	"CREATE CONSTRAINT metric_key IF NOT EXISTS FOR (n:Metric) REQUIRE n.key IS UNIQUE;",
+	"CREATE CONSTRAINT topic_key IF NOT EXISTS FOR (n:Topic) REQUIRE n.key IS UNIQUE;",
+	"CREATE CONSTRAINT source_url IF NOT EXISTS FOR (n:Source) REQUIRE n.url IS UNIQUE;",
+	"CREATE CONSTRAINT publisher_key IF NOT EXISTS FOR (n:Publisher) REQUIRE n.key IS UNIQUE;",
+}
+
+// exportCypher writes stats as a Cypher script for cypher-shell: one
+// statement per statistic, merging its Statistic node and its MEASURES,
+// ABOUT, FROM, CORROBORATED_BY, and PUBLISHED_BY relationships. Loading it
+// again, or a later export, updates the graph rather than duplicating it.
+func exportCypher(w io.Writer, stats []models.StoredStatistic) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("// Verified statistics; load with: cypher-shell -f statistics.cypher\n")
+	for _, c := range cypherConstraints {
+		bw.WriteString(c + "\n")
+	}
+
+	for _, s := range stats {
+		props := []string{
+			"name: " + cypherString(s.Name),
+			"value: " + strconv.FormatFloat(widen(s.Value), 'g', -1, 64),
+			"unit: " + cypherString(s.Unit),
+			"excerpt: " + cypherString(s.Excerpt),
+			"confidence: " + strconv.FormatFloat(s.Confidence, 'g', -1, 64),
+			"first_seen: datetime(" + cypherString(s.FirstSeen.Format(time.RFC3339)) + ")",
+			"last_seen: datetime(" + cypherString(s.LastSeen.Format(time.RFC3339)) + ")",
+		}
+		if s.Type != "" {
+			props = append(props, "type: "+cypherString(string(s.Type)))
+		}
+		if !s.PublishedAt.IsZero() {
+			props = append(props, "published_at: date("+cypherString(date(s.PublishedAt))+")")
+		}
+		if s.ContentHash != "" {
+			props = append(props, "content_hash: "+cypherString(s.ContentHash))
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "MERGE (s:Statistic {id: %s}) SET s += {%s}\n", cypherString(s.ID), strings.Join(props, ", "))
+		fmt.Fprintf(&b, "MERGE (m:Metric {key: %s}) SET m.name = %s MERGE (s)-[:MEASURES]->(m)\n", cypherString(nodeKey(s.Name)), cypherString(s.Name))
+		if s.SourceURL != "" {
+			fmt.Fprintf(&b, "MERGE (src:Source {url: %s}) SET src.name = %s, src.domain = %s, src.tier = %s",
+				cypherString(s.SourceURL), cypherString(s.Source), cypherString(s.Domain), cypherString(s.DomainTier))
+			if s.License != nil {
+				fmt.Fprintf(&b, ", src.license = %s, src.permissive = %t", cypherString(string(s.License.Kind)), s.License.Permissive)
+			}
+			b.WriteString(" MERGE (s)-[:FROM]->(src)\n")
+			if publisher := publisherName(s); publisher != "" {
+				fmt.Fprintf(&b, "MERGE (p:Publisher {key: %s}) SET p.name = %s MERGE (src)-[:PUBLISHED_BY]->(p)\n",
+					cypherString(nodeKey(publisher)), cypherString(publisher))
+			}
+		}
+		if len(s.Topics) > 0 {
+			topics := make([]string, len(s.Topics))
+			for i, topic := range s.Topics {
+				topics[i] = fmt.Sprintf("{key: %s, name: %s}", cypherString(nodeKey(topic)), cypherString(topic))
+			}
+			fmt.Fprintf(&b, "FOREACH (t IN [%s] | MERGE (topic:Topic {key: t.key}) SET topic.name = t.name MERGE (s)-[:ABOUT]->(topic))\n",
+				strings.Join(topics, ", "))
+		}
+		var corroborations []string
+		for _, c := range s.CorroboratedBy {
+			if c.SourceURL != "" {
+				corroborations = append(corroborations, fmt.Sprintf("{url: %s, name: %s}", cypherString(c.SourceURL), cypherString(c.Source)))
+			}
+		}
+		if len(corroborations) > 0 {
+			fmt.Fprintf(&b, "FOREACH (c IN [%s] | MERGE (other:Source {url: c.url}) ON CREATE SET other.name = c.name MERGE (s)-[:CORROBORATED_BY]->(other))\n",
+				strings.Join(corroborations, ", "))
+		}
+		bw.WriteString(strings.TrimSuffix(b.String(), "\n") + ";\n")
+	}
+	return bw.Flush()
+}
+
+// cypherEscaper escapes a Cypher string literal's content
+var cypherEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// cypherString quotes s as a Cypher string literal
+func cypherString(s string) string {
+	return "'" + cypherEscaper.Replace(s) + "'"
+}
+
+// publisherName returns the publisher a source declares, or its domain
+func publisherName(s models.StoredStatistic) string {
+	if s.Publisher != "" {
+		return s.Publisher
+	}
+	return s.Domain
+}
+
+// nodeKey normalizes a name so spellings differing in case, spacing, or
+// punctuation identify the same node
+func nodeKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), "-")
+}
+
+func metricID(name string) string    { return "stats:metric/" + nodeKey(name) }
+func topicID(topic string) string    { return "stats:topic/" + nodeKey(topic) }
+func publisherID(name string) string { return "stats:publisher/" + nodeKey(name) }
@@ -0,0 +1,121 @@
+package statstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// defaultLimit is the page size of a search without a limit parameter
+const defaultLimit = 10
+
+// Handler returns the handler of GET /statistics/search, which searches the
+// statistics verified by past runs:
+//
+//	q                words to match against name, excerpt, source, and topic
+//	topic            only statistics verified for this topic
+//	published_after  only statistics whose source declares a publication date on or after this one
+//	offset           index of the first result (default 0)
+//	limit            results per page (default 10)
+//
+// At least one of q and topic is required.
+func (s *Store) Handler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if s == nil {
+			http.Error(w, "statistics store is not enabled; set STATS_STORE_FILE", http.StatusNotFound)
+			return
+		}
+
+		params := r.URL.Query()
+		q := Query{Text: strings.TrimSpace(params.Get("q")), Topic: strings.TrimSpace(params.Get("topic"))}
+		if q.Text == "" && q.Topic == "" {
+			http.Error(w, "q or topic is required", http.StatusBadRequest)
+			return
+		}
+		offset, limit, err := models.ParsePage(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !params.Has("limit") {
+			limit = defaultLimit
+		}
+		if q.PublishedAfter, err = ParseTime(params.Get("published_after"), false); err != nil {
+			http.Error(w, fmt.Sprintf("invalid published_after: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		matches, mode, err := s.Search(r.Context(), q)
+		if err != nil {
+			logger.Error("statistics search failed", "error", err)
+			http.Error(w, fmt.Sprintf("Search failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		resp := models.StatisticSearchResponse{Query: q.Text, Topic: q.Topic, Mode: mode}
+		resp.Results, resp.Page = models.Paginate(matches, offset, limit)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.Error("failed to encode statistics search response", "error", err)
+		}
+	}
+}
+
+// ChangesHandler returns the handler of GET /statistics/changes, the report
+// of stored statistics whose sources revised their values, most recent
+// first:
+//
+//	topic           only statistics verified for this topic
+//	tier            comma-separated domain tiers: authoritative, research, other
+//	from, to        only values that changed in this range (RFC 3339 or YYYY-MM-DD, inclusive)
+//	min_confidence  only statistics with at least this confidence (0 to 1)
+//	offset, limit   page of changes (default limit 10)
+func (s *Store) ChangesHandler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if s == nil {
+			http.Error(w, "statistics store is not enabled; set STATS_STORE_FILE", http.StatusNotFound)
+			return
+		}
+
+		params := r.URL.Query()
+		filter, err := ParseFilter(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset, limit, err := models.ParsePage(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !params.Has("limit") {
+			limit = defaultLimit
+		}
+
+		changes, err := s.Changes(filter)
+		if err != nil {
+			logger.Error("failed to list statistic changes", "error", err)
+			http.Error(w, fmt.Sprintf("Changes report failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		resp := models.StatisticChangesResponse{Topic: filter.Topic}
+		resp.Changes, resp.Page = models.Paginate(changes, offset, limit)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.Error("failed to encode changes report", "error", err)
+		}
+	}
+}
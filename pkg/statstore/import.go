@@ -0,0 +1,215 @@
+package statstore
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+	"github.com/plexusone/agent-team-stats/pkg/toolspec"
+)
+
+// DefaultImportTopic is the topic imported statistics are stored under when
+// the request names none
+const DefaultImportTopic = "import"
+
+// MaxImportCandidates bounds the candidates of one import, and so the
+// verification cost of one request
+const MaxImportCandidates = 500
+
+// ImportHandler returns the handler of POST /candidates/import, which sends
+// candidate statistics sourced outside the pipeline straight to
+// verification and merges the verified ones into the store. The body is a
+// models.CandidateImportRequest, or a CSV spreadsheet (Content-Type
+// text/csv) with a header row naming the name, value, unit, source,
+// source_url, and excerpt columns and the topic in the topic query
+// parameter. Without a store the candidates are still verified, and the
+// response says they were not stored.
+func (s *Store) ImportHandler(cfg *config.Config, verify toolspec.VerifyFunc, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		req, err := decodeImport(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := llm.ValidateOverride(cfg, req.Model); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		toolspec.FromRequest(req.Candidates)
+
+		vresp, err := verify(r.Context(), &models.VerificationRequest{Candidates: req.Candidates, Model: req.Model})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Verification failed: %v", err), http.StatusBadGateway)
+			return
+		}
+		tenant.Charge(r.Context(), vresp.Usage)
+
+		resp := models.CandidateImportResponse{
+			Topic:      req.Topic,
+			Statistics: []models.StoredStatistic{},
+			Rejected:   []models.VerificationResult{},
+			Stored:     s != nil,
+			Usage:      vresp.Usage,
+			Timestamp:  time.Now(),
+		}
+		var verified []models.Statistic
+		for _, result := range vresp.Results {
+			if result.Verified && result.Statistic != nil {
+				verified = append(verified, *result.Statistic)
+			} else {
+				resp.Rejected = append(resp.Rejected, result)
+			}
+		}
+		resp.VerifiedCount, resp.FailedCount = len(verified), len(resp.Rejected)
+
+		if s == nil {
+			for _, stat := range verified {
+				stored := models.StoredStatistic{Statistic: stat, ID: ID(stat), Topics: []string{req.Topic}}
+				annotate(&stored)
+				resp.Statistics = append(resp.Statistics, stored)
+			}
+		} else if stored, added, err := s.Merge(r.Context(), req.Topic, verified, vresp.Sources); err != nil {
+			// The verification is paid for; return it rather than an error
+			logger.Error("failed to store imported statistics", "error", err)
+			resp.Stored = false
+			for _, stat := range verified {
+				resp.Statistics = append(resp.Statistics, models.StoredStatistic{Statistic: stat, ID: ID(stat)})
+			}
+		} else {
+			resp.Statistics = append(resp.Statistics, stored...)
+			resp.NewCount = added
+		}
+
+		logger.Info("imported candidates",
+			"topic", req.Topic,
+			"candidates", len(req.Candidates),
+			"verified", resp.VerifiedCount,
+			"new", resp.NewCount)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.Error("failed to encode import response", "error", err)
+		}
+	}
+}
+
+// decodeImport reads and checks an import request from a JSON or CSV body
+func decodeImport(r *http.Request) (*models.CandidateImportRequest, error) {
+	var req models.CandidateImportRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		candidates, err := ParseCandidatesCSV(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Topic = r.URL.Query().Get("topic")
+		req.Candidates = candidates
+	} else if err := migrate.Decode(r.Body, &req); err != nil {
+		return nil, err
+	}
+
+	req.Topic = strings.TrimSpace(req.Topic)
+	if req.Topic == "" {
+		req.Topic = DefaultImportTopic
+	}
+	if len(req.Candidates) == 0 {
+		return nil, errors.New("candidates is required")
+	}
+	if len(req.Candidates) > MaxImportCandidates {
+		return nil, fmt.Errorf("%d candidates exceed the limit of %d per import", len(req.Candidates), MaxImportCandidates)
+	}
+	for i, c := range req.Candidates {
+		if strings.TrimSpace(c.Name) == "" || strings.TrimSpace(c.SourceURL) == "" {
+			return nil, fmt.Errorf("candidate %d: name and source_url are required", i+1)
+		}
+	}
+	return &req, nil
+}
+
+// csvColumns are the columns a CSV import may have, by header name. Headers
+// are matched case-insensitively and with spaces for underscores, in any
+// order; others are ignored.
+var csvColumns = []string{"name", "value", "unit", "source", "source_url", "excerpt"}
+
+// ParseCandidatesCSV reads candidate statistics from a spreadsheet exported
+// as CSV. The header row must name at least the name, value, and source_url
+// columns. Values may use thousands separators.
+func ParseCandidatesCSV(r io.Reader) ([]models.CandidateStatistic, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		// Excel starts UTF-8 CSV files with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.ReplaceAll(name, " ", "_")
+		if slices.Contains(csvColumns, name) {
+			index[name] = i
+		}
+	}
+	for _, required := range []string{"name", "value", "source_url"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("CSV header has no %s column", required)
+		}
+	}
+
+	var candidates []models.CandidateStatistic
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return candidates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue // Blank spreadsheet row
+		}
+		value, err := strconv.ParseFloat(strings.ReplaceAll(field("value"), ",", ""), 32)
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("CSV line %d: value %q is not a number", line, field("value"))
+		}
+		candidates = append(candidates, models.CandidateStatistic{
+			Name:      field("name"),
+			Value:     float32(value),
+			Unit:      field("unit"),
+			Source:    field("source"),
+			SourceURL: field("source_url"),
+			Excerpt:   field("excerpt"),
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package statstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// fakeVerify verifies the candidates whose excerpt contains their value
+func fakeVerify(_ context.Context, req *models.VerificationRequest) (*models.VerificationResponse, error) {
+	resp := &models.VerificationResponse{}
+	for _, c := range req.Candidates {
+		verified := strings.Contains(c.Excerpt, models.FormatValue(c.Value, ""))
+		stat := c.Statistic(verified, resp.Timestamp)
+		result := models.VerificationResult{Statistic: &stat, Verified: verified}
+		if !verified {
+			result.Category = models.FailureValueMismatch
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+func TestParseCandidatesCSV(t *testing.T) {
+	csv := "\ufeffName,Value,Unit,Source,Source URL,Excerpt,Notes\n" +
+		"US population,\"334,900,000\",people,Census Bureau,https://www.census.gov/popclock,\"The U.S. population is 334,900,000.\",checked\n" +
+		",,,,,,\n"
+	candidates, err := ParseCandidatesCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].Value != 334900000 || candidates[0].SourceURL != "https://www.census.gov/popclock" || candidates[0].Source != "Census Bureau" {
+		t.Errorf("candidates = %+v", candidates)
+	}
+
+	if _, err := ParseCandidatesCSV(strings.NewReader("name,value\nx,1\n")); err == nil {
+		t.Error("CSV without source_url parsed")
+	}
+	_, err = ParseCandidatesCSV(strings.NewReader("name,value,source_url\nx,1,https://a.gov\ny,lots,https://a.gov\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("bad value error = %v; want line 3", err)
+	}
+}
+
+func TestImportHandler(t *testing.T) {
+	s := newStore(t, nil)
+	handler := s.ImportHandler(&config.Config{}, fakeVerify, testLogger)
+	post := func(contentType, query, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/candidates/import"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// One candidate already stored, one new, one that fails verification
+	body, _ := json.Marshal(models.CandidateImportRequest{
+		Topic: "legacy audit",
+		Candidates: []models.CandidateStatistic{
+			{Name: solarStat.Name, Value: solarStat.Value, Unit: solarStat.Unit, Source: solarStat.Source, SourceURL: solarStat.SourceURL, Excerpt: solarStat.Excerpt},
+			{Name: "Wind share of US electricity generation", Value: 10.2, Unit: "%", SourceURL: "https://www.eia.gov/energyexplained/wind", Excerpt: "Wind provided 10.2% of generation."},
+			{Name: "Hydro share of US electricity generation", Value: 9, Unit: "%", SourceURL: "https://www.eia.gov/energyexplained/hydropower", Excerpt: "Hydropower provided about 5.7%."},
+		},
+	})
+	rec := post("application/json", "", string(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import = %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.CandidateImportResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Stored || resp.VerifiedCount != 2 || resp.FailedCount != 1 || resp.NewCount != 1 || len(resp.Rejected) != 1 {
+		t.Fatalf("response = %+v", resp)
+	}
+	if resp.Statistics[0].ID != ID(solarStat) || len(resp.Statistics[0].Topics) != 2 {
+		t.Errorf("merged statistic = %+v", resp.Statistics[0])
+	}
+	audited, _ := s.List(Filter{Topic: "legacy audit"})
+	if len(audited) != 2 {
+		t.Errorf("stored under the import topic: %d; want 2", len(audited))
+	}
+
+	rec = post("text/csv; charset=utf-8", "?topic=csv", "name,value,source_url,excerpt\nCoal share,16.2,https://www.eia.gov/coal,Coal provided 16.2%.\n")
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Topic != "csv" || resp.NewCount != 1 {
+		t.Errorf("CSV import = %d %+v (%v)", rec.Code, resp, err)
+	}
+
+	if rec := post("application/json", "", `{"candidates": []}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty import = %d; want 400", rec.Code)
+	}
+	if rec := post("application/json", "", `{"candidates": [{"name": "x", "value": 1}]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("candidate without source_url = %d; want 400", rec.Code)
+	}
+
+	// Without a store, candidates are verified but not kept
+	var disabled *Store
+	rec = httptest.NewRecorder()
+	disabled.ImportHandler(&config.Config{}, fakeVerify, testLogger).ServeHTTP(rec,
+		httptest.NewRequest(http.MethodPost, "/candidates/import", strings.NewReader(string(body))))
+	resp = models.CandidateImportResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Stored || resp.VerifiedCount != 2 || resp.Statistics[1].DomainTier == "" {
+		t.Errorf("import without a store = %d %+v (%v)", rec.Code, resp, err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package statstore
+
+// lockFile does nothing where flock is unavailable, so only one process
+// should write the store there
+func lockFile(string) (func(), error) {
+	return func() {}, nil
+}
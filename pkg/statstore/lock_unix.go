@@ -0,0 +1,28 @@
+//go:build unix
+
+package statstore
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on the lock file beside path, waiting
+// for another process holding it, and returns its release. Replicas sharing
+// the store hold it from reading the file to replacing it.
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock statistics store: %w", err)
+	}
+	fd := int(f.Fd()) //nolint:gosec // G115: file descriptors fit in an int
+	if err := syscall.Flock(fd, syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock statistics store: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(fd, syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
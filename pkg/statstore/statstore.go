@@ -0,0 +1,691 @@
+// Package statstore keeps the statistics verified by past runs, so RAG
+// pipelines and other clients can cite already-verified numbers instead of
+// starting a new orchestration run. Statistics are recorded by the
+// orchestrators after each run and searched by keyword and, optionally,
+// embedding similarity.
+package statstore
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/domainyield"
+	"github.com/plexusone/agent-team-stats/pkg/embed"
+	"github.com/plexusone/agent-team-stats/pkg/fingerprint"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/prioritize"
+	"github.com/plexusone/agent-team-stats/pkg/textmatch"
+	"github.com/plexusone/agent-team-stats/pkg/toolspec"
+	"github.com/plexusone/agent-team-stats/pkg/urlnorm"
+)
+
+// Search modes, reported in the response
+const (
+	ModeKeyword = "keyword"
+	ModeHybrid  = "hybrid"
+)
+
+// similarityFloor is the cosine similarity at which a statistic matches a
+// query that shares none of its words
+const similarityFloor = 0.5
+
+// stopWords are left out of keyword matching
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "by": true,
+	"for": true, "from": true, "how": true, "in": true, "is": true, "many": true, "much": true,
+	"of": true, "on": true, "or": true, "the": true, "to": true, "what": true, "with": true,
+}
+
+// entry is a stored statistic with the embedding of its name and excerpt
+type entry struct {
+	models.StoredStatistic
+
+	Embedding []float32 `json:"embedding,omitempty"`
+}
+
+// Store persists verified statistics in a JSON file shared by the
+// orchestrator replicas
+type Store struct {
+	path     string
+	embedder embed.Embedder // Nil for keyword-only search
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	modTime time.Time
+}
+
+// NewStore opens the store at cfg.StatisticsStoreFile, embedding statistics
+// with EMBEDDING_PROVIDER when cfg.StatisticsSearchEmbeddings is set. It
+// returns nil when no file is configured.
+func NewStore(ctx context.Context, cfg *config.Config, logger *slog.Logger) (*Store, error) {
+	if cfg.StatisticsStoreFile == "" {
+		return nil, nil
+	}
+	var embedder embed.Embedder
+	if cfg.StatisticsSearchEmbeddings {
+		var err error
+		if embedder, err = embed.New(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
+	return New(cfg.StatisticsStoreFile, embedder, logger)
+}
+
+// New opens the store at path. embedder may be nil.
+func New(path string, embedder embed.Embedder, logger *slog.Logger) (*Store, error) {
+	s := &Store{path: path, embedder: embedder, logger: logger, entries: make(map[string]*entry)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refreshLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ID returns the stable ID of a statistic: the same figure from the same
+// page keeps its ID across runs
+func ID(stat models.Statistic) string {
+	sum := sha256.Sum256([]byte(sourceKey(stat.SourceURL) + "\x00" + textmatch.Normalize(stat.Name) + "\x00" + models.FormatValue(stat.Value, stat.Unit)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Record adds the verified statistics of a run, or updates those already
+// stored with the run's topic and time
+func (s *Store) Record(ctx context.Context, resp *models.OrchestrationResponse) error {
+	if resp == nil {
+		return nil
+	}
+	_, _, err := s.Merge(ctx, resp.Topic, resp.Statistics, resp.Sources)
+	return err
+}
+
+// Merge adds the verified statistics among stats under topic, or updates
+// those already stored with the topic and time. It returns them as stored
+// and how many were new. Stored statistics not among stats whose section
+// of a source changed in sources, the fingerprints of the sources fetched
+// while verifying stats, are marked stale.
+func (s *Store) Merge(ctx context.Context, topic string, stats []models.Statistic, sources []models.SourceFingerprint) ([]models.StoredStatistic, int, error) {
+	if s == nil || (len(stats) == 0 && len(sources) == 0) {
+		return nil, 0, nil
+	}
+
+	vectors := s.embedNew(ctx, stats)
+
+	// The file lock keeps other replicas from writing between the reload
+	// and the save, which would lose their statistics
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer unlock()
+	// Modification times are too coarse to tell two quick writes apart
+	if err := s.loadLocked(); err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now().UTC()
+	var added, merged []*entry
+	for _, stat := range stats {
+		if !stat.Verified {
+			continue
+		}
+		id := ID(stat)
+		e := s.entries[id]
+		if e == nil {
+			e = &entry{StoredStatistic: models.StoredStatistic{ID: id, FirstSeen: now}, Embedding: vectors[id]}
+			s.entries[id] = e
+			added = append(added, e)
+		}
+		e.Statistic = stat
+		e.ConflictGroup = "" // Only meaningful within the response that flagged it
+		e.LastSeen = now
+		e.Stale = nil
+		if !slices.ContainsFunc(e.Topics, func(t string) bool { return strings.EqualFold(t, topic) }) {
+			e.Topics = append(e.Topics, topic)
+		}
+		annotate(&e.StoredStatistic)
+		merged = append(merged, e)
+	}
+	s.markStaleLocked(sources, merged, now)
+	s.linkRevisionsLocked(added, merged, sources, now)
+	if err := s.saveLocked(); err != nil {
+		return nil, 0, err
+	}
+
+	stored := make([]models.StoredStatistic, len(merged))
+	for i, e := range merged {
+		stored[i] = e.StoredStatistic
+	}
+	return stored, len(added), nil
+}
+
+// CheckSources marks the stored statistics whose section of a source
+// changed in sources, without storing anything new
+func (s *Store) CheckSources(ctx context.Context, sources []models.SourceFingerprint) error {
+	_, _, err := s.Merge(ctx, "", nil, sources)
+	return err
+}
+
+// WatchSources wraps verify so the sources it fetches are checked against
+// the stored statistics, as for POST /verify, whose candidates are not
+// stored. A nil store returns verify unchanged.
+func (s *Store) WatchSources(verify toolspec.VerifyFunc) toolspec.VerifyFunc {
+	if s == nil {
+		return verify
+	}
+	return func(ctx context.Context, req *models.VerificationRequest) (*models.VerificationResponse, error) {
+		resp, err := verify(ctx, req)
+		if err == nil {
+			if err := s.CheckSources(ctx, resp.Sources); err != nil {
+				s.logger.Warn("failed to check sources against stored statistics", "error", err)
+			}
+		}
+		return resp, err
+	}
+}
+
+// markStaleLocked marks the stored statistics whose section of a source
+// changed in sources, skipping those just verified again. A statistic whose
+// source is back to the text it was verified against is no longer stale.
+// The caller must hold s.mu.
+func (s *Store) markStaleLocked(sources []models.SourceFingerprint, verified []*entry, now time.Time) {
+	later := make(map[string]models.SourceFingerprint, len(sources))
+	for _, fp := range sources {
+		later[sourceKey(fp.URL)] = fp
+	}
+	if len(later) == 0 {
+		return
+	}
+
+	for _, e := range s.entries {
+		if e.Section == nil || slices.Contains(verified, e) {
+			continue
+		}
+		fp, ok := later[sourceKey(e.SourceURL)]
+		switch {
+		case !ok:
+		case fp.Hash == e.Section.Fingerprint:
+			e.Stale = nil
+		case fingerprint.Changed(e.Section, fp):
+			if e.Stale == nil {
+				e.Stale = &models.Staleness{DetectedAt: now}
+				s.logger.Info("stored statistic is stale", "id", e.ID, "name", e.Name, "source", e.SourceURL)
+			}
+			e.Stale.Fingerprint = fp.Hash
+			e.Stale.DiffURL = fingerprint.DiffURL(e.SourceURL, e.Section, fp)
+		}
+	}
+}
+
+// linkRevisionsLocked links each added statistic to the stored statistic
+// it revises: the latest one from the same source with the same name, or at
+// the same place in the source's text, and a different value or unit, whose
+// section of the source changed in sources. One verified in the same merge
+// is not revised, since the source still has it, and nor is one whose
+// section is unchanged or unknown, since the source may give both figures,
+// such as the same measure for two years. The caller must hold s.mu.
+func (s *Store) linkRevisionsLocked(added, verified []*entry, sources []models.SourceFingerprint, now time.Time) {
+	later := make(map[string]models.SourceFingerprint, len(sources))
+	for _, fp := range sources {
+		later[sourceKey(fp.URL)] = fp
+	}
+	for _, e := range added {
+		source, name := sourceKey(e.SourceURL), textmatch.Normalize(e.Name)
+		fp, ok := later[source]
+		if !ok {
+			continue
+		}
+		var previous *entry
+		for _, p := range s.entries {
+			if p == e || p.SupersededBy != "" || slices.Contains(verified, p) || sourceKey(p.SourceURL) != source ||
+				(p.Value == e.Value && p.Unit == e.Unit) || p.Section == nil || !fingerprint.Changed(p.Section, fp) {
+				continue
+			}
+			samePlace := e.Section != nil && p.Section != nil && e.Section.Anchor != "" && e.Section.Anchor == p.Section.Anchor
+			if (textmatch.Normalize(p.Name) == name || samePlace) && (previous == nil || p.LastSeen.After(previous.LastSeen)) {
+				previous = p
+			}
+		}
+		if previous == nil {
+			continue
+		}
+
+		e.Supersedes = &models.Revision{
+			ID:        previous.ID,
+			Value:     previous.Value,
+			Unit:      previous.Unit,
+			FirstSeen: previous.FirstSeen,
+			LastSeen:  previous.LastSeen,
+		}
+		previous.SupersededBy = e.ID
+		if previous.Stale == nil {
+			previous.Stale = &models.Staleness{DetectedAt: now, DiffURL: fingerprint.TextLink(e.SourceURL, e.Excerpt)}
+			if e.Section != nil {
+				previous.Stale.Fingerprint = e.Section.Fingerprint
+			}
+		}
+		s.logger.Info("stored statistic revised", "id", e.ID, "previous", previous.ID, "name", e.Name,
+			"old", models.FormatValue(previous.Value, previous.Unit), "new", models.FormatValue(e.Value, e.Unit))
+	}
+}
+
+// Changes returns the revised statistics selected by f, most recently
+// changed first. From and To select by when the new value was first
+// verified.
+func (s *Store) Changes(f Filter) ([]models.RevisedStatistic, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refreshLocked(); err != nil {
+		return nil, err
+	}
+	topicWords := keywords(f.Topic)
+	entries := s.selectLocked(func(e *entry) bool {
+		return e.Supersedes != nil && inTopic(e, topicWords) &&
+			(len(f.Tiers) == 0 || slices.Contains(f.Tiers, e.DomainTier)) &&
+			(f.From.IsZero() || !e.FirstSeen.Before(f.From)) &&
+			(f.To.IsZero() || !e.FirstSeen.After(f.To)) &&
+			e.Confidence >= f.MinConfidence
+	})
+
+	changes := make([]models.RevisedStatistic, len(entries))
+	for i, e := range entries {
+		changes[i] = models.RevisedStatistic{
+			ID:         e.ID,
+			PreviousID: e.Supersedes.ID,
+			Name:       e.Name,
+			Source:     e.Source,
+			SourceURL:  e.SourceURL,
+			Topics:     e.Topics,
+			OldValue:   e.Supersedes.Value,
+			OldUnit:    e.Supersedes.Unit,
+			NewValue:   e.Value,
+			NewUnit:    e.Unit,
+			OldSince:   e.Supersedes.FirstSeen,
+			OldUntil:   e.Supersedes.LastSeen,
+			ChangedAt:  e.FirstSeen,
+			Excerpt:    e.Excerpt,
+		}
+		if previous := s.entries[e.Supersedes.ID]; previous != nil && previous.Stale != nil {
+			changes[i].DiffURL = previous.Stale.DiffURL
+		}
+	}
+	slices.SortFunc(changes, func(a, b models.RevisedStatistic) int {
+		if c := b.ChangedAt.Compare(a.ChangedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return changes, nil
+}
+
+// sourceKey matches a statistic's source URL to a fingerprint's
+func sourceKey(raw string) string {
+	if normalized, err := urlnorm.Normalize(raw); err == nil {
+		return normalized
+	}
+	return raw
+}
+
+// Confidence of a statistic by the tier of its source, before corroboration
+var tierConfidence = map[string]float64{
+	prioritize.TierAuthoritative: 0.8,
+	prioritize.TierResearch:      0.7,
+	prioritize.TierOther:         0.5,
+}
+
+// corroborationConfidence is the confidence added by each other source
+// reporting the same statistic
+const corroborationConfidence = 0.1
+
+// annotate sets the domain, domain tier, and confidence of a statistic
+func annotate(s *models.StoredStatistic) {
+	s.Domain = domainyield.Domain(s.SourceURL)
+	s.DomainTier = prioritize.Tier(s.Domain)
+	s.Confidence = min(tierConfidence[s.DomainTier]+corroborationConfidence*float64(len(s.CorroboratedBy)), 1)
+}
+
+// embedNew embeds the verified statistics among stats not yet stored, by
+// ID. The embedding provider is called without holding s.mu, so a slow one
+// does not hold up searches and other merges. A failure is logged; those
+// statistics are then found by keyword only.
+func (s *Store) embedNew(ctx context.Context, stats []models.Statistic) map[string][]float32 {
+	if s.embedder == nil {
+		return nil
+	}
+	s.mu.Lock()
+	err := s.refreshLocked()
+	var ids, texts []string
+	for _, stat := range stats {
+		id := ID(stat)
+		if stat.Verified && s.entries[id] == nil && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+			texts = append(texts, stat.Name+". "+stat.Excerpt)
+		}
+	}
+	s.mu.Unlock()
+	if err != nil || len(ids) == 0 {
+		return nil // A read error is returned by the merge
+	}
+
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err == nil && len(vectors) != len(ids) {
+		err = fmt.Errorf("got %d embeddings for %d statistics", len(vectors), len(ids))
+	}
+	if err != nil {
+		s.logger.Warn("failed to embed stored statistics", "error", err)
+		return nil
+	}
+	embeddings := make(map[string][]float32, len(ids))
+	for i, id := range ids {
+		embeddings[id] = vectors[i]
+	}
+	return embeddings
+}
+
+// Query is a search of the store
+type Query struct {
+	Text  string // Words to match; empty lists every statistic of Topic
+	Topic string // Restricts results to statistics verified for this topic
+
+	PublishedAfter time.Time // Restricts results to sources published since, like Filter.PublishedAfter
+}
+
+// Search returns the stored statistics matching q, most relevant first, and
+// the mode they were ranked with
+func (s *Store) Search(ctx context.Context, q Query) ([]models.StatisticMatch, string, error) {
+	s.mu.Lock()
+	if err := s.refreshLocked(); err != nil {
+		s.mu.Unlock()
+		return nil, "", err
+	}
+	topicWords := keywords(q.Topic)
+	entries := s.selectLocked(func(e *entry) bool { return inTopic(e, topicWords) && publishedAfter(e, q.PublishedAfter) })
+	s.mu.Unlock()
+
+	terms := keywords(q.Text)
+	mode := ModeKeyword
+	var query []float32
+	if s.embedder != nil && strings.TrimSpace(q.Text) != "" {
+		vectors, err := s.embedder.Embed(ctx, []string{q.Text})
+		if err == nil && len(vectors) == 1 {
+			query, mode = vectors[0], ModeHybrid
+		} else {
+			s.logger.Warn("statistics search fell back to keywords", "error", err)
+		}
+	}
+
+	matches := []models.StatisticMatch{}
+	for _, e := range entries {
+		score := 1.0
+		if strings.TrimSpace(q.Text) != "" {
+			kw := keywordScore(e, terms)
+			score = kw
+			if query != nil {
+				sim := max(embed.Cosine(query, e.Embedding), 0)
+				if kw == 0 && sim < similarityFloor {
+					continue
+				}
+				score = (kw + sim) / 2
+			}
+			if score == 0 {
+				continue
+			}
+		}
+		matches = append(matches, models.StatisticMatch{StoredStatistic: e.StoredStatistic, Score: score})
+	}
+	slices.SortFunc(matches, func(a, b models.StatisticMatch) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		if c := b.LastSeen.Compare(a.LastSeen); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return matches, mode, nil
+}
+
+// Filter selects stored statistics for List. Zero fields select everything.
+type Filter struct {
+	Topic         string    // Only statistics verified for a topic with these words
+	Tiers         []string  // Only sources of these domain tiers
+	From, To      time.Time // Only statistics last verified in this range, inclusive
+	MinConfidence float64
+	// Only statistics whose source was published at or after this time;
+	// undated sources are left out
+	PublishedAfter time.Time
+}
+
+// List returns the stored statistics selected by f, in the order they were
+// first verified
+func (s *Store) List(f Filter) ([]models.StoredStatistic, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refreshLocked(); err != nil {
+		return nil, err
+	}
+	topicWords := keywords(f.Topic)
+	entries := s.selectLocked(func(e *entry) bool {
+		return inTopic(e, topicWords) &&
+			(len(f.Tiers) == 0 || slices.Contains(f.Tiers, e.DomainTier)) &&
+			(f.From.IsZero() || !e.LastSeen.Before(f.From)) &&
+			(f.To.IsZero() || !e.LastSeen.After(f.To)) &&
+			publishedAfter(e, f.PublishedAfter) &&
+			e.Confidence >= f.MinConfidence
+	})
+
+	stats := make([]models.StoredStatistic, len(entries))
+	for i, e := range entries {
+		stats[i] = e.StoredStatistic
+	}
+	slices.SortFunc(stats, func(a, b models.StoredStatistic) int {
+		if c := a.FirstSeen.Compare(b.FirstSeen); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return stats, nil
+}
+
+// Topics returns the topics of the runs that verified the stored
+// statistics, the most recently verified first
+func (s *Store) Topics() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refreshLocked(); err != nil {
+		return nil, err
+	}
+	lastSeen := make(map[string]time.Time)
+	for _, e := range s.entries {
+		for _, topic := range e.Topics {
+			if t, ok := lastSeen[topic]; !ok || e.LastSeen.After(t) {
+				lastSeen[topic] = e.LastSeen
+			}
+		}
+	}
+	topics := slices.Collect(maps.Keys(lastSeen))
+	slices.SortFunc(topics, func(a, b string) int {
+		if c := lastSeen[b].Compare(lastSeen[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return topics, nil
+}
+
+// publishedAfter reports whether e's source was published at or after t,
+// or t is zero
+func publishedAfter(e *entry, t time.Time) bool {
+	return t.IsZero() || (!e.PublishedAt.IsZero() && !e.PublishedAt.Before(t))
+}
+
+// selectLocked returns the entries match accepts. The caller must hold s.mu.
+func (s *Store) selectLocked(match func(*entry) bool) []*entry {
+	entries := make([]*entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// keywordScore is the share of terms found in the statistic, counting a
+// term in its name fully and one only in its excerpt, source, or topics half
+func keywordScore(e *entry, terms []string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	name := wordSet(e.Name)
+	other := wordSet(strings.Join(append([]string{e.Excerpt, e.Source}, e.Topics...), " "))
+	var score float64
+	for _, t := range terms {
+		switch {
+		case name[t]:
+			score++
+		case other[t]:
+			score += 0.5
+		}
+	}
+	return score / float64(len(terms))
+}
+
+// inTopic reports whether one of the statistic's topics has every topic word
+func inTopic(e *entry, topicWords []string) bool {
+	if len(topicWords) == 0 {
+		return true
+	}
+	for _, topic := range e.Topics {
+		words := wordSet(topic)
+		if !slices.ContainsFunc(topicWords, func(w string) bool { return !words[w] }) {
+			return true
+		}
+	}
+	return false
+}
+
+// keywords returns the distinct words of s, without stop words
+func keywords(s string) []string {
+	var out []string
+	for w := range wordSet(s) {
+		if !stopWords[w] {
+			out = append(out, w)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// wordSet returns the normalized words of s, with a plural "s" removed so
+// "vehicles" matches "vehicle"
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, f := range strings.Fields(textmatch.Normalize(s)) {
+		f = strings.TrimFunc(f, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%'
+		})
+		if len(f) > 3 && strings.HasSuffix(f, "s") && !strings.HasSuffix(f, "ss") {
+			f = f[:len(f)-1]
+		}
+		if f != "" {
+			set[f] = true
+		}
+	}
+	return set
+}
+
+// refreshLocked reloads the file when another process has changed it. The
+// caller must hold s.mu.
+func (s *Store) refreshLocked() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read statistics store: %w", err)
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+	return s.loadLocked()
+}
+
+// loadLocked reads the file. The caller must hold s.mu.
+func (s *Store) loadLocked() error {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read statistics store: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read statistics store: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read statistics store: %w", err)
+	}
+	entries := make(map[string]*entry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse statistics store %s: %w", s.path, err)
+	}
+	for _, e := range entries {
+		annotate(&e.StoredStatistic)
+	}
+	s.entries = entries
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// saveLocked writes the statistics to the file, through a temporary file
+// of its own so concurrent writers never replace the file with a partial
+// one. The caller must hold s.mu and the file lock.
+func (s *Store) saveLocked() error {
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write statistics store: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write statistics store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write statistics store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace statistics store: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
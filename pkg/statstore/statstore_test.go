@@ -0,0 +1,381 @@
+package statstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/embed"
+	"github.com/plexusone/agent-team-stats/pkg/fingerprint"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+var evStats = []models.Statistic{
+	{
+		Name:      "Global electric vehicle sales in 2023",
+		Value:     14,
+		Unit:      "million",
+		Source:    "IEA",
+		SourceURL: "https://www.iea.org/reports/global-ev-outlook-2024",
+		Excerpt:   "Almost 14 million new electric cars were registered globally in 2023.",
+		Verified:  true,
+	},
+	{
+		Name:      "Share of new cars sold in China that were electric",
+		Value:     38,
+		Unit:      "%",
+		Source:    "IEA",
+		SourceURL: "https://www.iea.org/reports/global-ev-outlook-2024",
+		Excerpt:   "Electric cars accounted for 38% of new car sales in China.",
+		Verified:  true,
+	},
+}
+
+var solarStat = models.Statistic{
+	Name:      "Solar share of US electricity generation",
+	Value:     3.9,
+	Unit:      "%",
+	Source:    "EIA",
+	SourceURL: "https://www.eia.gov/energyexplained/solar",
+	Excerpt:   "Solar energy provided about 3.9% of U.S. electricity generation in 2023.",
+	Verified:  true,
+
+	Publisher:   "U.S. Energy Information Administration",
+	PublishedAt: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC),
+}
+
+func newStore(t *testing.T, embedder embed.Embedder) *Store {
+	t.Helper()
+	s, err := New(filepath.Join(t.TempDir(), "statistics.json"), embedder, testLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := s.Record(ctx, &models.OrchestrationResponse{Topic: "electric vehicles", Statistics: evStats}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Record(ctx, &models.OrchestrationResponse{Topic: "solar energy", Statistics: []models.Statistic{solarStat}}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestRecordMergesRepeatedStatistics(t *testing.T) {
+	s := newStore(t, nil)
+	again := evStats[0]
+	again.SourceURL = "https://iea.org/reports/global-ev-outlook-2024/" // Same page, written differently
+	if err := s.Record(context.Background(), &models.OrchestrationResponse{Topic: "EV sales", Statistics: []models.Statistic{again}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second process reads what the first recorded
+	reader, err := New(s.path, nil, testLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, _, err := reader.Search(context.Background(), Query{Topic: "electric vehicles"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reader.entries) != 3 || len(matches) != 2 {
+		t.Fatalf("stored %d statistics, %d for electric vehicles; want 3 and 2", len(reader.entries), len(matches))
+	}
+	e := reader.entries[ID(evStats[0])]
+	if e == nil || len(e.Topics) != 2 || e.Topics[1] != "EV sales" || e.LastSeen.Before(e.FirstSeen) {
+		t.Errorf("merged statistic = %+v", e)
+	}
+}
+
+func TestConcurrentReplicasKeepEveryStatistic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "statistics.json")
+	var replicas [2]*Store
+	for i := range replicas {
+		s, err := New(path, nil, testLogger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replicas[i] = s
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			stat := solarStat
+			stat.Name = fmt.Sprintf("Solar share of generation in region %d", i)
+			if _, _, err := replicas[i%2].Merge(context.Background(), "solar energy", []models.Statistic{stat}, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	reader, err := New(path, nil, testLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reader.entries) != 20 {
+		t.Errorf("stored %d statistics, want 20", len(reader.entries))
+	}
+	if tmp, _ := filepath.Glob(path + ".*.tmp"); len(tmp) != 0 {
+		t.Errorf("temporary files left behind: %v", tmp)
+	}
+}
+
+func TestSearchRanksByKeyword(t *testing.T) {
+	s := newStore(t, nil)
+
+	matches, mode, err := s.Search(context.Background(), Query{Text: "electric car sales in China"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode != ModeKeyword || len(matches) != 2 {
+		t.Fatalf("mode %s, %d matches; want keyword and 2", mode, len(matches))
+	}
+	if matches[0].Value != 38 || matches[0].Score <= matches[1].Score {
+		t.Errorf("best match = %+v (score %.2f, next %.2f)", matches[0].Statistic, matches[0].Score, matches[1].Score)
+	}
+
+	matches, _, _ = s.Search(context.Background(), Query{Text: "electricity", Topic: "electric vehicles"})
+	if len(matches) != 0 {
+		t.Errorf("topic filter let through %+v", matches)
+	}
+	matches, _, _ = s.Search(context.Background(), Query{Text: "solar electricity generation"})
+	if len(matches) != 1 || matches[0].Source != "EIA" {
+		t.Errorf("solar matches = %+v", matches)
+	}
+}
+
+func TestSearchWithEmbeddings(t *testing.T) {
+	s := newStore(t, embed.NewLocal())
+	for _, e := range s.entries {
+		if len(e.Embedding) == 0 {
+			t.Fatalf("statistic %q stored without an embedding", e.Name)
+		}
+	}
+
+	matches, mode, err := s.Search(context.Background(), Query{Text: "solar share of electricity"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode != ModeHybrid || len(matches) == 0 || matches[0].Source != "EIA" {
+		t.Errorf("mode %s, matches %+v", mode, matches)
+	}
+}
+
+// blockingEmbedder embeds once release is closed, reporting each call on
+// started
+type blockingEmbedder struct {
+	started, release chan struct{}
+}
+
+func (b blockingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return embed.NewLocal().Embed(ctx, texts)
+}
+
+func TestMergeEmbedsWithoutBlockingSearch(t *testing.T) {
+	s := newStore(t, nil)
+	b := blockingEmbedder{started: make(chan struct{}, 1), release: make(chan struct{})}
+	s.embedder = b
+
+	done := make(chan error)
+	go func() {
+		stat := solarStat
+		stat.Name = "Solar share of California electricity generation"
+		_, _, err := s.Merge(context.Background(), "solar energy", []models.Statistic{stat}, nil)
+		done <- err
+	}()
+	<-b.started
+
+	// Searches go ahead while the new statistic is being embedded
+	searched := make(chan error)
+	go func() {
+		_, err := s.List(Filter{})
+		searched <- err
+	}()
+	select {
+	case err := <-searched:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("listing waited for the embedding provider")
+	}
+
+	close(b.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range s.entries {
+		if strings.Contains(e.Name, "California") && len(e.Embedding) == 0 {
+			t.Error("new statistic stored without an embedding")
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := newStore(t, nil).Handler(testLogger)
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/statistics/search?"+query, nil))
+		return rec
+	}
+
+	rec := get("q=electric+cars&limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("search = %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.StatisticSearchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || resp.Page.Total != 2 || resp.Page.NextOffset != 1 || resp.Results[0].ID == "" {
+		t.Errorf("response = %+v, page %+v", resp.Results, resp.Page)
+	}
+
+	rec = get("topic=solar+energy&published_after=2025-01-01")
+	resp = models.StatisticSearchResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || len(resp.Results) != 0 {
+		t.Errorf("published after 2025 = %d %+v %v", rec.Code, resp.Results, err)
+	}
+
+	if rec := get(""); rec.Code != http.StatusBadRequest {
+		t.Errorf("search without q or topic = %d; want 400", rec.Code)
+	}
+	if rec := get("q=x&limit=-1"); rec.Code != http.StatusBadRequest {
+		t.Errorf("negative limit = %d; want 400", rec.Code)
+	}
+
+	var disabled *Store
+	rec = httptest.NewRecorder()
+	disabled.Handler(testLogger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/statistics/search?q=x", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("disabled store = %d; want 404", rec.Code)
+	}
+}
+
+// solarPage is solarStat's source page with the solar share given
+func solarPage(share string) []byte {
+	var b strings.Builder
+	for i := range 8 {
+		fmt.Fprintf(&b, "<p>Section %d covers generation from another energy source, its capacity, and its %d%% growth.</p>", i, i*3)
+	}
+	b.WriteString("<p>Solar energy provided about " + share + " of U.S. electricity generation in 2023.</p>")
+	b.WriteString("<p>Wind, hydropower, and geothermal are covered on their own pages.</p>")
+	return []byte(b.String())
+}
+
+func TestMergeMarksChangedSourcesStale(t *testing.T) {
+	s := newStore(t, nil)
+	ctx := context.Background()
+
+	// Verified against the original page
+	before := fingerprint.New(solarStat.SourceURL, solarPage("3.9%"))
+	stat := solarStat
+	stat.Section = before.Section(solarStat.Excerpt)
+	if _, _, err := s.Merge(ctx, "solar energy", []models.Statistic{stat}, []models.SourceFingerprint{before.Fingerprint()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A later run fetches the page after the figure was revised
+	revised := fingerprint.New("https://eia.gov/energyexplained/solar/", solarPage("4.1%")).Fingerprint()
+	if _, _, err := s.Merge(ctx, "renewables", nil, []models.SourceFingerprint{revised}); err != nil {
+		t.Fatal(err)
+	}
+	stale := s.entries[ID(solarStat)].Stale
+	if stale == nil || stale.Fingerprint != revised.Hash || !strings.Contains(stale.DiffURL, "#:~:text=") {
+		t.Fatalf("stale = %+v", stale)
+	}
+	for _, id := range []string{ID(evStats[0]), ID(evStats[1])} {
+		if s.entries[id].Stale != nil {
+			t.Errorf("statistic from another source marked stale: %+v", s.entries[id])
+		}
+	}
+
+	// Verifying the statistic again clears it
+	if _, _, err := s.Merge(ctx, "solar energy", []models.Statistic{stat}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if s.entries[ID(solarStat)].Stale != nil {
+		t.Error("re-verified statistic still stale")
+	}
+}
+
+func TestChangesReportsRevisedValues(t *testing.T) {
+	s := newStore(t, nil)
+	ctx := context.Background()
+
+	before := fingerprint.New(solarStat.SourceURL, solarPage("3.9%"))
+	original := solarStat
+	original.Section = before.Section(solarStat.Excerpt)
+	if _, _, err := s.Merge(ctx, "solar energy", []models.Statistic{original}, []models.SourceFingerprint{before.Fingerprint()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same name with another value on the unchanged page, such as a
+	// figure for another year, does not revise it
+	otherYear := solarStat
+	otherYear.Value = 3.4
+	otherYear.Excerpt = "Solar energy provided about 3.4% of U.S. electricity generation in 2022."
+	if _, _, err := s.Merge(ctx, "solar energy", []models.Statistic{otherYear}, []models.SourceFingerprint{before.Fingerprint()}); err != nil {
+		t.Fatal(err)
+	}
+	if e := s.entries[ID(otherYear)]; e.Supersedes != nil || s.entries[ID(solarStat)].SupersededBy != "" {
+		t.Fatalf("statistic from an unchanged page linked as a revision: %+v", e.StoredStatistic)
+	}
+
+	after := fingerprint.New(solarStat.SourceURL, solarPage("4.1%"))
+	revised := solarStat
+	revised.Value = 4.1
+	revised.Excerpt = "Solar energy provided about 4.1% of U.S. electricity generation in 2023."
+	revised.Section = after.Section(revised.Excerpt)
+	if _, _, err := s.Merge(ctx, "solar energy", []models.Statistic{revised}, []models.SourceFingerprint{after.Fingerprint()}); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := s.entries[ID(solarStat)]
+	if previous.SupersededBy != ID(revised) || previous.Stale == nil || !strings.Contains(previous.Stale.DiffURL, "#:~:text=") {
+		t.Fatalf("previous = %+v, stale %+v", previous.StoredStatistic, previous.Stale)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ChangesHandler(testLogger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/statistics/changes?topic=solar&from=2020-01-01", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("changes = %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.StatisticChangesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Changes) != 1 || resp.Page.Total != 1 {
+		t.Fatalf("changes = %+v", resp.Changes)
+	}
+	c := resp.Changes[0]
+	if c.OldValue != 3.9 || c.NewValue != 4.1 || c.PreviousID != ID(solarStat) || c.OldUntil.After(c.ChangedAt) || c.DiffURL == "" {
+		t.Errorf("change = %+v", c)
+	}
+
+	// The EV statistics share a source but have different names
+	for _, stat := range evStats {
+		if e := s.entries[ID(stat)]; e.Supersedes != nil || e.SupersededBy != "" {
+			t.Errorf("unrelated statistic linked: %+v", e.StoredStatistic)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	s.ChangesHandler(testLogger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/statistics/changes?topic=electric+vehicles", nil))
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || len(resp.Changes) != 0 {
+		t.Errorf("electric vehicle changes = %+v (%v)", resp.Changes, err)
+	}
+}
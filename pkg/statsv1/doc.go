@@ -0,0 +1,6 @@
+// Package statsv1 holds the Go types and Connect bindings of the
+// orchestrator's RPC API, generated from proto/stats/v1. The API is served
+// by pkg/connectapi.
+package statsv1
+
+//go:generate go run ./gen
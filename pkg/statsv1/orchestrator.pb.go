@@ -0,0 +1,1919 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: stats/v1/orchestrator.proto
+
+package statsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED EventType = 0
+	EventType_EVENT_TYPE_STARTED     EventType = 1
+	EventType_EVENT_TYPE_PROGRESS    EventType = 2
+	EventType_EVENT_TYPE_FINISHED    EventType = 3
+	// Every run active when the stream started has been sent
+	EventType_EVENT_TYPE_READY EventType = 4
+	// Statistics reported earlier that the run's result will not hold
+	EventType_EVENT_TYPE_RETRACTED EventType = 5
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_STARTED",
+		2: "EVENT_TYPE_PROGRESS",
+		3: "EVENT_TYPE_FINISHED",
+		4: "EVENT_TYPE_READY",
+		5: "EVENT_TYPE_RETRACTED",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED": 0,
+		"EVENT_TYPE_STARTED":     1,
+		"EVENT_TYPE_PROGRESS":    2,
+		"EVENT_TYPE_FINISHED":    3,
+		"EVENT_TYPE_READY":       4,
+		"EVENT_TYPE_RETRACTED":   5,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_stats_v1_orchestrator_proto_enumTypes[0].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_stats_v1_orchestrator_proto_enumTypes[0]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{0}
+}
+
+// RunOptions are the options of an orchestration request; unset options
+// take the configured defaults
+type RunOptions struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Topic            string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	MinVerifiedStats int32                  `protobuf:"varint,2,opt,name=min_verified_stats,json=minVerifiedStats,proto3" json:"min_verified_stats,omitempty"`
+	MaxCandidates    int32                  `protobuf:"varint,3,opt,name=max_candidates,json=maxCandidates,proto3" json:"max_candidates,omitempty"`
+	ReputableOnly    bool                   `protobuf:"varint,4,opt,name=reputable_only,json=reputableOnly,proto3" json:"reputable_only,omitempty"`
+	// Entities or periods to compare, e.g. ["2010", "2020"]
+	Compare []string `protobuf:"bytes,5,rep,name=compare,proto3" json:"compare,omitempty"`
+	// Only search and select sources, returning the plan in the result
+	DryRun                bool `protobuf:"varint,6,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	IncludeSummary        bool `protobuf:"varint,7,opt,name=include_summary,json=includeSummary,proto3" json:"include_summary,omitempty"`
+	PermissiveLicenseOnly bool `protobuf:"varint,8,opt,name=permissive_license_only,json=permissiveLicenseOnly,proto3" json:"permissive_license_only,omitempty"`
+	Reproducible          bool `protobuf:"varint,9,opt,name=reproducible,proto3" json:"reproducible,omitempty"`
+	// survey, measured, projection, forecast, or self_reported
+	StatisticTypes     []string `protobuf:"bytes,10,rep,name=statistic_types,json=statisticTypes,proto3" json:"statistic_types,omitempty"`
+	ExcludeProjections bool     `protobuf:"varint,11,opt,name=exclude_projections,json=excludeProjections,proto3" json:"exclude_projections,omitempty"`
+	LlmProvider        string   `protobuf:"bytes,12,opt,name=llm_provider,json=llmProvider,proto3" json:"llm_provider,omitempty"`
+	LlmModel           string   `protobuf:"bytes,13,opt,name=llm_model,json=llmModel,proto3" json:"llm_model,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *RunOptions) Reset() {
+	*x = RunOptions{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunOptions) ProtoMessage() {}
+
+func (x *RunOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunOptions.ProtoReflect.Descriptor instead.
+func (*RunOptions) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RunOptions) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *RunOptions) GetMinVerifiedStats() int32 {
+	if x != nil {
+		return x.MinVerifiedStats
+	}
+	return 0
+}
+
+func (x *RunOptions) GetMaxCandidates() int32 {
+	if x != nil {
+		return x.MaxCandidates
+	}
+	return 0
+}
+
+func (x *RunOptions) GetReputableOnly() bool {
+	if x != nil {
+		return x.ReputableOnly
+	}
+	return false
+}
+
+func (x *RunOptions) GetCompare() []string {
+	if x != nil {
+		return x.Compare
+	}
+	return nil
+}
+
+func (x *RunOptions) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *RunOptions) GetIncludeSummary() bool {
+	if x != nil {
+		return x.IncludeSummary
+	}
+	return false
+}
+
+func (x *RunOptions) GetPermissiveLicenseOnly() bool {
+	if x != nil {
+		return x.PermissiveLicenseOnly
+	}
+	return false
+}
+
+func (x *RunOptions) GetReproducible() bool {
+	if x != nil {
+		return x.Reproducible
+	}
+	return false
+}
+
+func (x *RunOptions) GetStatisticTypes() []string {
+	if x != nil {
+		return x.StatisticTypes
+	}
+	return nil
+}
+
+func (x *RunOptions) GetExcludeProjections() bool {
+	if x != nil {
+		return x.ExcludeProjections
+	}
+	return false
+}
+
+func (x *RunOptions) GetLlmProvider() string {
+	if x != nil {
+		return x.LlmProvider
+	}
+	return ""
+}
+
+func (x *RunOptions) GetLlmModel() string {
+	if x != nil {
+		return x.LlmModel
+	}
+	return ""
+}
+
+type OrchestrateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Options       *RunOptions            `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrchestrateRequest) Reset() {
+	*x = OrchestrateRequest{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrchestrateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrchestrateRequest) ProtoMessage() {}
+
+func (x *OrchestrateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrchestrateRequest.ProtoReflect.Descriptor instead.
+func (*OrchestrateRequest) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OrchestrateRequest) GetOptions() *RunOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type OrchestrateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*OrchestrateResponse_Result
+	//	*OrchestrateResponse_Job
+	Outcome       isOrchestrateResponse_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrchestrateResponse) Reset() {
+	*x = OrchestrateResponse{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrchestrateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrchestrateResponse) ProtoMessage() {}
+
+func (x *OrchestrateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrchestrateResponse.ProtoReflect.Descriptor instead.
+func (*OrchestrateResponse) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OrchestrateResponse) GetOutcome() isOrchestrateResponse_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *OrchestrateResponse) GetResult() *Result {
+	if x != nil {
+		if x, ok := x.Outcome.(*OrchestrateResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *OrchestrateResponse) GetJob() *Job {
+	if x != nil {
+		if x, ok := x.Outcome.(*OrchestrateResponse_Job); ok {
+			return x.Job
+		}
+	}
+	return nil
+}
+
+type isOrchestrateResponse_Outcome interface {
+	isOrchestrateResponse_Outcome()
+}
+
+type OrchestrateResponse_Result struct {
+	Result *Result `protobuf:"bytes,1,opt,name=result,proto3,oneof"`
+}
+
+type OrchestrateResponse_Job struct {
+	Job *Job `protobuf:"bytes,2,opt,name=job,proto3,oneof"`
+}
+
+func (*OrchestrateResponse_Result) isOrchestrateResponse_Outcome() {}
+
+func (*OrchestrateResponse_Job) isOrchestrateResponse_Outcome() {}
+
+type OrchestrateStreamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Options       *RunOptions            `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrchestrateStreamRequest) Reset() {
+	*x = OrchestrateStreamRequest{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrchestrateStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrchestrateStreamRequest) ProtoMessage() {}
+
+func (x *OrchestrateStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrchestrateStreamRequest.ProtoReflect.Descriptor instead.
+func (*OrchestrateStreamRequest) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OrchestrateStreamRequest) GetOptions() *RunOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type OrchestrateStreamResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*OrchestrateStreamResponse_Progress
+	//	*OrchestrateStreamResponse_Result
+	//	*OrchestrateStreamResponse_Statistic
+	//	*OrchestrateStreamResponse_Retracted
+	Event         isOrchestrateStreamResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrchestrateStreamResponse) Reset() {
+	*x = OrchestrateStreamResponse{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrchestrateStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrchestrateStreamResponse) ProtoMessage() {}
+
+func (x *OrchestrateStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrchestrateStreamResponse.ProtoReflect.Descriptor instead.
+func (*OrchestrateStreamResponse) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrchestrateStreamResponse) GetEvent() isOrchestrateStreamResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *OrchestrateStreamResponse) GetProgress() *Run {
+	if x != nil {
+		if x, ok := x.Event.(*OrchestrateStreamResponse_Progress); ok {
+			return x.Progress
+		}
+	}
+	return nil
+}
+
+func (x *OrchestrateStreamResponse) GetResult() *Result {
+	if x != nil {
+		if x, ok := x.Event.(*OrchestrateStreamResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *OrchestrateStreamResponse) GetStatistic() *Statistic {
+	if x != nil {
+		if x, ok := x.Event.(*OrchestrateStreamResponse_Statistic); ok {
+			return x.Statistic
+		}
+	}
+	return nil
+}
+
+func (x *OrchestrateStreamResponse) GetRetracted() *Statistic {
+	if x != nil {
+		if x, ok := x.Event.(*OrchestrateStreamResponse_Retracted); ok {
+			return x.Retracted
+		}
+	}
+	return nil
+}
+
+type isOrchestrateStreamResponse_Event interface {
+	isOrchestrateStreamResponse_Event()
+}
+
+type OrchestrateStreamResponse_Progress struct {
+	// The run's state, each time it changes
+	Progress *Run `protobuf:"bytes,1,opt,name=progress,proto3,oneof"`
+}
+
+type OrchestrateStreamResponse_Result struct {
+	// The run's result; the last message of the stream
+	Result *Result `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+type OrchestrateStreamResponse_Statistic struct {
+	// A statistic the run verified, sent as soon as it passed
+	// verification, after the progress message counting it
+	Statistic *Statistic `protobuf:"bytes,3,opt,name=statistic,proto3,oneof"`
+}
+
+type OrchestrateStreamResponse_Retracted struct {
+	// A statistic sent earlier that the result will not hold, because its
+	// verification pass failed, or deduplication or post-processing
+	// removed it; sent after the progress message no longer counting it
+	Retracted *Statistic `protobuf:"bytes,4,opt,name=retracted,proto3,oneof"`
+}
+
+func (*OrchestrateStreamResponse_Progress) isOrchestrateStreamResponse_Event() {}
+
+func (*OrchestrateStreamResponse_Result) isOrchestrateStreamResponse_Event() {}
+
+func (*OrchestrateStreamResponse_Statistic) isOrchestrateStreamResponse_Event() {}
+
+func (*OrchestrateStreamResponse_Retracted) isOrchestrateStreamResponse_Event() {}
+
+type WatchRunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRunsRequest) Reset() {
+	*x = WatchRunsRequest{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRunsRequest) ProtoMessage() {}
+
+func (x *WatchRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRunsRequest.ProtoReflect.Descriptor instead.
+func (*WatchRunsRequest) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{5}
+}
+
+type WatchRunsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  EventType              `protobuf:"varint,1,opt,name=type,proto3,enum=stats.v1.EventType" json:"type,omitempty"`
+	// Unset for READY
+	Run *Run `protobuf:"bytes,2,opt,name=run,proto3" json:"run,omitempty"`
+	// Statistics a PROGRESS event verified or a RETRACTED event withdrew
+	Statistics    []*Statistic `protobuf:"bytes,3,rep,name=statistics,proto3" json:"statistics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRunsResponse) Reset() {
+	*x = WatchRunsResponse{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRunsResponse) ProtoMessage() {}
+
+func (x *WatchRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRunsResponse.ProtoReflect.Descriptor instead.
+func (*WatchRunsResponse) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchRunsResponse) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *WatchRunsResponse) GetRun() *Run {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+func (x *WatchRunsResponse) GetStatistics() []*Statistic {
+	if x != nil {
+		return x.Statistics
+	}
+	return nil
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *Job                   `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobResponse) Reset() {
+	*x = GetJobResponse{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobResponse) ProtoMessage() {}
+
+func (x *GetJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobResponse.ProtoReflect.Descriptor instead.
+func (*GetJobResponse) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetJobResponse) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type SearchStatisticsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Words to match against name, excerpt, source, and topic
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Only statistics verified for this topic
+	Topic string `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	// Only statistics whose source was published at or after this time
+	PublishedAfter *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=published_after,json=publishedAfter,proto3" json:"published_after,omitempty"`
+	Offset         int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Results per page; 0 for 10
+	Limit         int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchStatisticsRequest) Reset() {
+	*x = SearchStatisticsRequest{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchStatisticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchStatisticsRequest) ProtoMessage() {}
+
+func (x *SearchStatisticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchStatisticsRequest.ProtoReflect.Descriptor instead.
+func (*SearchStatisticsRequest) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SearchStatisticsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchStatisticsRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *SearchStatisticsRequest) GetPublishedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAfter
+	}
+	return nil
+}
+
+func (x *SearchStatisticsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SearchStatisticsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchStatisticsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How results were ranked: "keyword" or "hybrid"
+	Mode    string             `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	Results []*StoredStatistic `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	// Matches in all pages
+	Total int32 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	// Offset of the next page; 0 on the last page
+	NextOffset    int32 `protobuf:"varint,4,opt,name=next_offset,json=nextOffset,proto3" json:"next_offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchStatisticsResponse) Reset() {
+	*x = SearchStatisticsResponse{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchStatisticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchStatisticsResponse) ProtoMessage() {}
+
+func (x *SearchStatisticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchStatisticsResponse.ProtoReflect.Descriptor instead.
+func (*SearchStatisticsResponse) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SearchStatisticsResponse) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *SearchStatisticsResponse) GetResults() []*StoredStatistic {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchStatisticsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchStatisticsResponse) GetNextOffset() int32 {
+	if x != nil {
+		return x.NextOffset
+	}
+	return 0
+}
+
+// Run is the live state of an orchestration run
+type Run struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Topic string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	// research, synthesis, verification, or done
+	Stage      string `protobuf:"bytes,3,opt,name=stage,proto3" json:"stage,omitempty"`
+	Pass       int32  `protobuf:"varint,4,opt,name=pass,proto3" json:"pass,omitempty"`
+	Candidates int32  `protobuf:"varint,5,opt,name=candidates,proto3" json:"candidates,omitempty"`
+	Verified   int32  `protobuf:"varint,6,opt,name=verified,proto3" json:"verified,omitempty"`
+	Failed     int32  `protobuf:"varint,7,opt,name=failed,proto3" json:"failed,omitempty"`
+	Target     int32  `protobuf:"varint,8,opt,name=target,proto3" json:"target,omitempty"`
+	// Run outcome, once finished
+	Status string `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	Error  string `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	// Latest verified statistics, newest first
+	Recent        []*Statistic           `protobuf:"bytes,11,rep,name=recent,proto3" json:"recent,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Run) Reset() {
+	*x = Run{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Run) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Run) ProtoMessage() {}
+
+func (x *Run) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Run.ProtoReflect.Descriptor instead.
+func (*Run) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Run) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Run) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Run) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *Run) GetPass() int32 {
+	if x != nil {
+		return x.Pass
+	}
+	return 0
+}
+
+func (x *Run) GetCandidates() int32 {
+	if x != nil {
+		return x.Candidates
+	}
+	return 0
+}
+
+func (x *Run) GetVerified() int32 {
+	if x != nil {
+		return x.Verified
+	}
+	return 0
+}
+
+func (x *Run) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *Run) GetTarget() int32 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *Run) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Run) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Run) GetRecent() []*Statistic {
+	if x != nil {
+		return x.Recent
+	}
+	return nil
+}
+
+func (x *Run) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Run) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// Result is the outcome of a finished run
+type Result struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Topic string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// complete, partial, no_results, or dry_run
+	Status          string       `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Partial         bool         `protobuf:"varint,3,opt,name=partial,proto3" json:"partial,omitempty"`
+	Statistics      []*Statistic `protobuf:"bytes,4,rep,name=statistics,proto3" json:"statistics,omitempty"`
+	TotalCandidates int32        `protobuf:"varint,5,opt,name=total_candidates,json=totalCandidates,proto3" json:"total_candidates,omitempty"`
+	VerifiedCount   int32        `protobuf:"varint,6,opt,name=verified_count,json=verifiedCount,proto3" json:"verified_count,omitempty"`
+	FailedCount     int32        `protobuf:"varint,7,opt,name=failed_count,json=failedCount,proto3" json:"failed_count,omitempty"`
+	TargetCount     int32        `protobuf:"varint,8,opt,name=target_count,json=targetCount,proto3" json:"target_count,omitempty"`
+	// Refinement session for POST /refine (ADK orchestrator)
+	SessionId string `protobuf:"bytes,9,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Saved verification report, when REPORT_DIR is set
+	ReportId         string                 `protobuf:"bytes,10,opt,name=report_id,json=reportId,proto3" json:"report_id,omitempty"`
+	EstimatedCostUsd float64                `protobuf:"fixed64,11,opt,name=estimated_cost_usd,json=estimatedCostUsd,proto3" json:"estimated_cost_usd,omitempty"`
+	Timestamp        *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Result) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Result) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Result) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *Result) GetStatistics() []*Statistic {
+	if x != nil {
+		return x.Statistics
+	}
+	return nil
+}
+
+func (x *Result) GetTotalCandidates() int32 {
+	if x != nil {
+		return x.TotalCandidates
+	}
+	return 0
+}
+
+func (x *Result) GetVerifiedCount() int32 {
+	if x != nil {
+		return x.VerifiedCount
+	}
+	return 0
+}
+
+func (x *Result) GetFailedCount() int32 {
+	if x != nil {
+		return x.FailedCount
+	}
+	return 0
+}
+
+func (x *Result) GetTargetCount() int32 {
+	if x != nil {
+		return x.TargetCount
+	}
+	return 0
+}
+
+func (x *Result) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Result) GetReportId() string {
+	if x != nil {
+		return x.ReportId
+	}
+	return ""
+}
+
+func (x *Result) GetEstimatedCostUsd() float64 {
+	if x != nil {
+		return x.EstimatedCostUsd
+	}
+	return 0
+}
+
+func (x *Result) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// Job is a queued run
+type Job struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// queued, running, succeeded, or failed
+	Status   string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Topic    string `protobuf:"bytes,3,opt,name=topic,proto3" json:"topic,omitempty"`
+	Attempts int32  `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Error    string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// Set once the job succeeded
+	Result        *Result                `protobuf:"bytes,6,opt,name=result,proto3" json:"result,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Job) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Job) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Job) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+// Statistic is a statistic and its source
+type Statistic struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Name      string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value     float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	Unit      string                 `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`
+	Source    string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	SourceUrl string                 `protobuf:"bytes,5,opt,name=source_url,json=sourceUrl,proto3" json:"source_url,omitempty"`
+	// Verbatim quote containing the statistic
+	Excerpt        string                 `protobuf:"bytes,6,opt,name=excerpt,proto3" json:"excerpt,omitempty"`
+	Verified       bool                   `protobuf:"varint,7,opt,name=verified,proto3" json:"verified,omitempty"`
+	Type           string                 `protobuf:"bytes,8,opt,name=type,proto3" json:"type,omitempty"`
+	Publisher      string                 `protobuf:"bytes,9,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	PublishedAt    *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	License        *License               `protobuf:"bytes,11,opt,name=license,proto3" json:"license,omitempty"`
+	ContentHash    string                 `protobuf:"bytes,12,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"`
+	CorroboratedBy []*Corroboration       `protobuf:"bytes,13,rep,name=corroborated_by,json=corroboratedBy,proto3" json:"corroborated_by,omitempty"`
+	SafetyFlags    []string               `protobuf:"bytes,14,rep,name=safety_flags,json=safetyFlags,proto3" json:"safety_flags,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Statistic) Reset() {
+	*x = Statistic{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Statistic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Statistic) ProtoMessage() {}
+
+func (x *Statistic) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Statistic.ProtoReflect.Descriptor instead.
+func (*Statistic) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Statistic) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Statistic) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Statistic) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *Statistic) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Statistic) GetSourceUrl() string {
+	if x != nil {
+		return x.SourceUrl
+	}
+	return ""
+}
+
+func (x *Statistic) GetExcerpt() string {
+	if x != nil {
+		return x.Excerpt
+	}
+	return ""
+}
+
+func (x *Statistic) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *Statistic) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Statistic) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+func (x *Statistic) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+func (x *Statistic) GetLicense() *License {
+	if x != nil {
+		return x.License
+	}
+	return nil
+}
+
+func (x *Statistic) GetContentHash() string {
+	if x != nil {
+		return x.ContentHash
+	}
+	return ""
+}
+
+func (x *Statistic) GetCorroboratedBy() []*Corroboration {
+	if x != nil {
+		return x.CorroboratedBy
+	}
+	return nil
+}
+
+func (x *Statistic) GetSafetyFlags() []string {
+	if x != nil {
+		return x.SafetyFlags
+	}
+	return nil
+}
+
+// License is what a source declares about reusing its content
+type License struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Notice        string                 `protobuf:"bytes,3,opt,name=notice,proto3" json:"notice,omitempty"`
+	Permissive    bool                   `protobuf:"varint,4,opt,name=permissive,proto3" json:"permissive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *License) Reset() {
+	*x = License{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *License) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*License) ProtoMessage() {}
+
+func (x *License) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use License.ProtoReflect.Descriptor instead.
+func (*License) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *License) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *License) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *License) GetNotice() string {
+	if x != nil {
+		return x.Notice
+	}
+	return ""
+}
+
+func (x *License) GetPermissive() bool {
+	if x != nil {
+		return x.Permissive
+	}
+	return false
+}
+
+// Corroboration is another source reporting the same statistic
+type Corroboration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	SourceUrl     string                 `protobuf:"bytes,3,opt,name=source_url,json=sourceUrl,proto3" json:"source_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Corroboration) Reset() {
+	*x = Corroboration{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Corroboration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Corroboration) ProtoMessage() {}
+
+func (x *Corroboration) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Corroboration.ProtoReflect.Descriptor instead.
+func (*Corroboration) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Corroboration) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Corroboration) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Corroboration) GetSourceUrl() string {
+	if x != nil {
+		return x.SourceUrl
+	}
+	return ""
+}
+
+// StoredStatistic is a statistic verified by a past run
+type StoredStatistic struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Statistic  *Statistic             `protobuf:"bytes,2,opt,name=statistic,proto3" json:"statistic,omitempty"`
+	Topics     []string               `protobuf:"bytes,3,rep,name=topics,proto3" json:"topics,omitempty"`
+	FirstSeen  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Domain     string                 `protobuf:"bytes,6,opt,name=domain,proto3" json:"domain,omitempty"`
+	DomainTier string                 `protobuf:"bytes,7,opt,name=domain_tier,json=domainTier,proto3" json:"domain_tier,omitempty"`
+	Confidence float64                `protobuf:"fixed64,8,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// Relevance to the query, from 0 to 1
+	Score         float64 `protobuf:"fixed64,9,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoredStatistic) Reset() {
+	*x = StoredStatistic{}
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoredStatistic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredStatistic) ProtoMessage() {}
+
+func (x *StoredStatistic) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_v1_orchestrator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredStatistic.ProtoReflect.Descriptor instead.
+func (*StoredStatistic) Descriptor() ([]byte, []int) {
+	return file_stats_v1_orchestrator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StoredStatistic) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *StoredStatistic) GetStatistic() *Statistic {
+	if x != nil {
+		return x.Statistic
+	}
+	return nil
+}
+
+func (x *StoredStatistic) GetTopics() []string {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *StoredStatistic) GetFirstSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return nil
+}
+
+func (x *StoredStatistic) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *StoredStatistic) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *StoredStatistic) GetDomainTier() string {
+	if x != nil {
+		return x.DomainTier
+	}
+	return ""
+}
+
+func (x *StoredStatistic) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *StoredStatistic) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+var File_stats_v1_orchestrator_proto protoreflect.FileDescriptor
+
+const file_stats_v1_orchestrator_proto_rawDesc = "" +
+	"\n" +
+	"\x1bstats/v1/orchestrator.proto\x12\bstats.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf0\x03\n" +
+	"\n" +
+	"RunOptions\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12,\n" +
+	"\x12min_verified_stats\x18\x02 \x01(\x05R\x10minVerifiedStats\x12%\n" +
+	"\x0emax_candidates\x18\x03 \x01(\x05R\rmaxCandidates\x12%\n" +
+	"\x0ereputable_only\x18\x04 \x01(\bR\rreputableOnly\x12\x18\n" +
+	"\acompare\x18\x05 \x03(\tR\acompare\x12\x17\n" +
+	"\adry_run\x18\x06 \x01(\bR\x06dryRun\x12'\n" +
+	"\x0finclude_summary\x18\a \x01(\bR\x0eincludeSummary\x126\n" +
+	"\x17permissive_license_only\x18\b \x01(\bR\x15permissiveLicenseOnly\x12\"\n" +
+	"\freproducible\x18\t \x01(\bR\freproducible\x12'\n" +
+	"\x0fstatistic_types\x18\n" +
+	" \x03(\tR\x0estatisticTypes\x12/\n" +
+	"\x13exclude_projections\x18\v \x01(\bR\x12excludeProjections\x12!\n" +
+	"\fllm_provider\x18\f \x01(\tR\vllmProvider\x12\x1b\n" +
+	"\tllm_model\x18\r \x01(\tR\bllmModel\"D\n" +
+	"\x12OrchestrateRequest\x12.\n" +
+	"\aoptions\x18\x01 \x01(\v2\x14.stats.v1.RunOptionsR\aoptions\"o\n" +
+	"\x13OrchestrateResponse\x12*\n" +
+	"\x06result\x18\x01 \x01(\v2\x10.stats.v1.ResultH\x00R\x06result\x12!\n" +
+	"\x03job\x18\x02 \x01(\v2\r.stats.v1.JobH\x00R\x03jobB\t\n" +
+	"\aoutcome\"J\n" +
+	"\x18OrchestrateStreamRequest\x12.\n" +
+	"\aoptions\x18\x01 \x01(\v2\x14.stats.v1.RunOptionsR\aoptions\"\xe7\x01\n" +
+	"\x19OrchestrateStreamResponse\x12+\n" +
+	"\bprogress\x18\x01 \x01(\v2\r.stats.v1.RunH\x00R\bprogress\x12*\n" +
+	"\x06result\x18\x02 \x01(\v2\x10.stats.v1.ResultH\x00R\x06result\x123\n" +
+	"\tstatistic\x18\x03 \x01(\v2\x13.stats.v1.StatisticH\x00R\tstatistic\x123\n" +
+	"\tretracted\x18\x04 \x01(\v2\x13.stats.v1.StatisticH\x00R\tretractedB\a\n" +
+	"\x05event\"\x12\n" +
+	"\x10WatchRunsRequest\"\x92\x01\n" +
+	"\x11WatchRunsResponse\x12'\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x13.stats.v1.EventTypeR\x04type\x12\x1f\n" +
+	"\x03run\x18\x02 \x01(\v2\r.stats.v1.RunR\x03run\x123\n" +
+	"\n" +
+	"statistics\x18\x03 \x03(\v2\x13.stats.v1.StatisticR\n" +
+	"statistics\"\x1f\n" +
+	"\rGetJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"1\n" +
+	"\x0eGetJobResponse\x12\x1f\n" +
+	"\x03job\x18\x01 \x01(\v2\r.stats.v1.JobR\x03job\"\xb8\x01\n" +
+	"\x17SearchStatisticsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12C\n" +
+	"\x0fpublished_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x0epublishedAfter\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"\x9a\x01\n" +
+	"\x18SearchStatisticsResponse\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x123\n" +
+	"\aresults\x18\x02 \x03(\v2\x19.stats.v1.StoredStatisticR\aresults\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12\x1f\n" +
+	"\vnext_offset\x18\x04 \x01(\x05R\n" +
+	"nextOffset\"\x92\x03\n" +
+	"\x03Run\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x14\n" +
+	"\x05stage\x18\x03 \x01(\tR\x05stage\x12\x12\n" +
+	"\x04pass\x18\x04 \x01(\x05R\x04pass\x12\x1e\n" +
+	"\n" +
+	"candidates\x18\x05 \x01(\x05R\n" +
+	"candidates\x12\x1a\n" +
+	"\bverified\x18\x06 \x01(\x05R\bverified\x12\x16\n" +
+	"\x06failed\x18\a \x01(\x05R\x06failed\x12\x16\n" +
+	"\x06target\x18\b \x01(\x05R\x06target\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\x12+\n" +
+	"\x06recent\x18\v \x03(\v2\x13.stats.v1.StatisticR\x06recent\x129\n" +
+	"\n" +
+	"started_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xc1\x03\n" +
+	"\x06Result\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x18\n" +
+	"\apartial\x18\x03 \x01(\bR\apartial\x123\n" +
+	"\n" +
+	"statistics\x18\x04 \x03(\v2\x13.stats.v1.StatisticR\n" +
+	"statistics\x12)\n" +
+	"\x10total_candidates\x18\x05 \x01(\x05R\x0ftotalCandidates\x12%\n" +
+	"\x0everified_count\x18\x06 \x01(\x05R\rverifiedCount\x12!\n" +
+	"\ffailed_count\x18\a \x01(\x05R\vfailedCount\x12!\n" +
+	"\ftarget_count\x18\b \x01(\x05R\vtargetCount\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\t \x01(\tR\tsessionId\x12\x1b\n" +
+	"\treport_id\x18\n" +
+	" \x01(\tR\breportId\x12,\n" +
+	"\x12estimated_cost_usd\x18\v \x01(\x01R\x10estimatedCostUsd\x128\n" +
+	"\ttimestamp\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\"\xd2\x02\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05topic\x18\x03 \x01(\tR\x05topic\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\x05R\battempts\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12(\n" +
+	"\x06result\x18\x06 \x01(\v2\x10.stats.v1.ResultR\x06result\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"\xdc\x03\n" +
+	"\tStatistic\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"source_url\x18\x05 \x01(\tR\tsourceUrl\x12\x18\n" +
+	"\aexcerpt\x18\x06 \x01(\tR\aexcerpt\x12\x1a\n" +
+	"\bverified\x18\a \x01(\bR\bverified\x12\x12\n" +
+	"\x04type\x18\b \x01(\tR\x04type\x12\x1c\n" +
+	"\tpublisher\x18\t \x01(\tR\tpublisher\x12=\n" +
+	"\fpublished_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x12+\n" +
+	"\alicense\x18\v \x01(\v2\x11.stats.v1.LicenseR\alicense\x12!\n" +
+	"\fcontent_hash\x18\f \x01(\tR\vcontentHash\x12@\n" +
+	"\x0fcorroborated_by\x18\r \x03(\v2\x17.stats.v1.CorroborationR\x0ecorroboratedBy\x12!\n" +
+	"\fsafety_flags\x18\x0e \x03(\tR\vsafetyFlags\"g\n" +
+	"\aLicense\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06notice\x18\x03 \x01(\tR\x06notice\x12\x1e\n" +
+	"\n" +
+	"permissive\x18\x04 \x01(\bR\n" +
+	"permissive\"Z\n" +
+	"\rCorroboration\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1d\n" +
+	"\n" +
+	"source_url\x18\x03 \x01(\tR\tsourceUrl\"\xcf\x02\n" +
+	"\x0fStoredStatistic\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x121\n" +
+	"\tstatistic\x18\x02 \x01(\v2\x13.stats.v1.StatisticR\tstatistic\x12\x16\n" +
+	"\x06topics\x18\x03 \x03(\tR\x06topics\x129\n" +
+	"\n" +
+	"first_seen\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tfirstSeen\x127\n" +
+	"\tlast_seen\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x16\n" +
+	"\x06domain\x18\x06 \x01(\tR\x06domain\x12\x1f\n" +
+	"\vdomain_tier\x18\a \x01(\tR\n" +
+	"domainTier\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\b \x01(\x01R\n" +
+	"confidence\x12\x14\n" +
+	"\x05score\x18\t \x01(\x01R\x05score*\xa1\x01\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12EVENT_TYPE_STARTED\x10\x01\x12\x17\n" +
+	"\x13EVENT_TYPE_PROGRESS\x10\x02\x12\x17\n" +
+	"\x13EVENT_TYPE_FINISHED\x10\x03\x12\x14\n" +
+	"\x10EVENT_TYPE_READY\x10\x04\x12\x18\n" +
+	"\x14EVENT_TYPE_RETRACTED\x10\x052\xa1\x03\n" +
+	"\x13OrchestratorService\x12J\n" +
+	"\vOrchestrate\x12\x1c.stats.v1.OrchestrateRequest\x1a\x1d.stats.v1.OrchestrateResponse\x12^\n" +
+	"\x11OrchestrateStream\x12\".stats.v1.OrchestrateStreamRequest\x1a#.stats.v1.OrchestrateStreamResponse0\x01\x12F\n" +
+	"\tWatchRuns\x12\x1a.stats.v1.WatchRunsRequest\x1a\x1b.stats.v1.WatchRunsResponse0\x01\x12;\n" +
+	"\x06GetJob\x12\x17.stats.v1.GetJobRequest\x1a\x18.stats.v1.GetJobResponse\x12Y\n" +
+	"\x10SearchStatistics\x12!.stats.v1.SearchStatisticsRequest\x1a\".stats.v1.SearchStatisticsResponseB;Z9github.com/plexusone/agent-team-stats/pkg/statsv1;statsv1b\x06proto3"
+
+var (
+	file_stats_v1_orchestrator_proto_rawDescOnce sync.Once
+	file_stats_v1_orchestrator_proto_rawDescData []byte
+)
+
+func file_stats_v1_orchestrator_proto_rawDescGZIP() []byte {
+	file_stats_v1_orchestrator_proto_rawDescOnce.Do(func() {
+		file_stats_v1_orchestrator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_stats_v1_orchestrator_proto_rawDesc), len(file_stats_v1_orchestrator_proto_rawDesc)))
+	})
+	return file_stats_v1_orchestrator_proto_rawDescData
+}
+
+var file_stats_v1_orchestrator_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_stats_v1_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_stats_v1_orchestrator_proto_goTypes = []any{
+	(EventType)(0),                    // 0: stats.v1.EventType
+	(*RunOptions)(nil),                // 1: stats.v1.RunOptions
+	(*OrchestrateRequest)(nil),        // 2: stats.v1.OrchestrateRequest
+	(*OrchestrateResponse)(nil),       // 3: stats.v1.OrchestrateResponse
+	(*OrchestrateStreamRequest)(nil),  // 4: stats.v1.OrchestrateStreamRequest
+	(*OrchestrateStreamResponse)(nil), // 5: stats.v1.OrchestrateStreamResponse
+	(*WatchRunsRequest)(nil),          // 6: stats.v1.WatchRunsRequest
+	(*WatchRunsResponse)(nil),         // 7: stats.v1.WatchRunsResponse
+	(*GetJobRequest)(nil),             // 8: stats.v1.GetJobRequest
+	(*GetJobResponse)(nil),            // 9: stats.v1.GetJobResponse
+	(*SearchStatisticsRequest)(nil),   // 10: stats.v1.SearchStatisticsRequest
+	(*SearchStatisticsResponse)(nil),  // 11: stats.v1.SearchStatisticsResponse
+	(*Run)(nil),                       // 12: stats.v1.Run
+	(*Result)(nil),                    // 13: stats.v1.Result
+	(*Job)(nil),                       // 14: stats.v1.Job
+	(*Statistic)(nil),                 // 15: stats.v1.Statistic
+	(*License)(nil),                   // 16: stats.v1.License
+	(*Corroboration)(nil),             // 17: stats.v1.Corroboration
+	(*StoredStatistic)(nil),           // 18: stats.v1.StoredStatistic
+	(*timestamppb.Timestamp)(nil),     // 19: google.protobuf.Timestamp
+}
+var file_stats_v1_orchestrator_proto_depIdxs = []int32{
+	1,  // 0: stats.v1.OrchestrateRequest.options:type_name -> stats.v1.RunOptions
+	13, // 1: stats.v1.OrchestrateResponse.result:type_name -> stats.v1.Result
+	14, // 2: stats.v1.OrchestrateResponse.job:type_name -> stats.v1.Job
+	1,  // 3: stats.v1.OrchestrateStreamRequest.options:type_name -> stats.v1.RunOptions
+	12, // 4: stats.v1.OrchestrateStreamResponse.progress:type_name -> stats.v1.Run
+	13, // 5: stats.v1.OrchestrateStreamResponse.result:type_name -> stats.v1.Result
+	15, // 6: stats.v1.OrchestrateStreamResponse.statistic:type_name -> stats.v1.Statistic
+	15, // 7: stats.v1.OrchestrateStreamResponse.retracted:type_name -> stats.v1.Statistic
+	0,  // 8: stats.v1.WatchRunsResponse.type:type_name -> stats.v1.EventType
+	12, // 9: stats.v1.WatchRunsResponse.run:type_name -> stats.v1.Run
+	15, // 10: stats.v1.WatchRunsResponse.statistics:type_name -> stats.v1.Statistic
+	14, // 11: stats.v1.GetJobResponse.job:type_name -> stats.v1.Job
+	19, // 12: stats.v1.SearchStatisticsRequest.published_after:type_name -> google.protobuf.Timestamp
+	18, // 13: stats.v1.SearchStatisticsResponse.results:type_name -> stats.v1.StoredStatistic
+	15, // 14: stats.v1.Run.recent:type_name -> stats.v1.Statistic
+	19, // 15: stats.v1.Run.started_at:type_name -> google.protobuf.Timestamp
+	19, // 16: stats.v1.Run.updated_at:type_name -> google.protobuf.Timestamp
+	15, // 17: stats.v1.Result.statistics:type_name -> stats.v1.Statistic
+	19, // 18: stats.v1.Result.timestamp:type_name -> google.protobuf.Timestamp
+	13, // 19: stats.v1.Job.result:type_name -> stats.v1.Result
+	19, // 20: stats.v1.Job.created_at:type_name -> google.protobuf.Timestamp
+	19, // 21: stats.v1.Job.started_at:type_name -> google.protobuf.Timestamp
+	19, // 22: stats.v1.Job.finished_at:type_name -> google.protobuf.Timestamp
+	19, // 23: stats.v1.Statistic.published_at:type_name -> google.protobuf.Timestamp
+	16, // 24: stats.v1.Statistic.license:type_name -> stats.v1.License
+	17, // 25: stats.v1.Statistic.corroborated_by:type_name -> stats.v1.Corroboration
+	15, // 26: stats.v1.StoredStatistic.statistic:type_name -> stats.v1.Statistic
+	19, // 27: stats.v1.StoredStatistic.first_seen:type_name -> google.protobuf.Timestamp
+	19, // 28: stats.v1.StoredStatistic.last_seen:type_name -> google.protobuf.Timestamp
+	2,  // 29: stats.v1.OrchestratorService.Orchestrate:input_type -> stats.v1.OrchestrateRequest
+	4,  // 30: stats.v1.OrchestratorService.OrchestrateStream:input_type -> stats.v1.OrchestrateStreamRequest
+	6,  // 31: stats.v1.OrchestratorService.WatchRuns:input_type -> stats.v1.WatchRunsRequest
+	8,  // 32: stats.v1.OrchestratorService.GetJob:input_type -> stats.v1.GetJobRequest
+	10, // 33: stats.v1.OrchestratorService.SearchStatistics:input_type -> stats.v1.SearchStatisticsRequest
+	3,  // 34: stats.v1.OrchestratorService.Orchestrate:output_type -> stats.v1.OrchestrateResponse
+	5,  // 35: stats.v1.OrchestratorService.OrchestrateStream:output_type -> stats.v1.OrchestrateStreamResponse
+	7,  // 36: stats.v1.OrchestratorService.WatchRuns:output_type -> stats.v1.WatchRunsResponse
+	9,  // 37: stats.v1.OrchestratorService.GetJob:output_type -> stats.v1.GetJobResponse
+	11, // 38: stats.v1.OrchestratorService.SearchStatistics:output_type -> stats.v1.SearchStatisticsResponse
+	34, // [34:39] is the sub-list for method output_type
+	29, // [29:34] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
+}
+
+func init() { file_stats_v1_orchestrator_proto_init() }
+func file_stats_v1_orchestrator_proto_init() {
+	if File_stats_v1_orchestrator_proto != nil {
+		return
+	}
+	file_stats_v1_orchestrator_proto_msgTypes[2].OneofWrappers = []any{
+		(*OrchestrateResponse_Result)(nil),
+		(*OrchestrateResponse_Job)(nil),
+	}
+	file_stats_v1_orchestrator_proto_msgTypes[4].OneofWrappers = []any{
+		(*OrchestrateStreamResponse_Progress)(nil),
+		(*OrchestrateStreamResponse_Result)(nil),
+		(*OrchestrateStreamResponse_Statistic)(nil),
+		(*OrchestrateStreamResponse_Retracted)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stats_v1_orchestrator_proto_rawDesc), len(file_stats_v1_orchestrator_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_stats_v1_orchestrator_proto_goTypes,
+		DependencyIndexes: file_stats_v1_orchestrator_proto_depIdxs,
+		EnumInfos:         file_stats_v1_orchestrator_proto_enumTypes,
+		MessageInfos:      file_stats_v1_orchestrator_proto_msgTypes,
+	}.Build()
+	File_stats_v1_orchestrator_proto = out.File
+	file_stats_v1_orchestrator_proto_goTypes = nil
+	file_stats_v1_orchestrator_proto_depIdxs = nil
+}
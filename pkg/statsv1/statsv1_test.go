@@ -0,0 +1,28 @@
+package statsv1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+)
+
+// TestGeneratedCodeIsCurrent fails when proto/stats/v1 changed without
+// go generate ./pkg/statsv1
+func TestGeneratedCodeIsCurrent(t *testing.T) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{"../../proto"}}),
+	}
+	files, err := compiler.Compile(context.Background(), "stats/v1/orchestrator.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := protodesc.ToFileDescriptorProto(files[0])
+	got := protodesc.ToFileDescriptorProto(File_stats_v1_orchestrator_proto)
+	got.SourceCodeInfo, want.SourceCodeInfo = nil, nil
+	if !proto.Equal(got, want) {
+		t.Error("pkg/statsv1 is out of date; run go generate ./pkg/statsv1")
+	}
+}
@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: stats/v1/orchestrator.proto
+
+package statsv1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	statsv1 "github.com/plexusone/agent-team-stats/pkg/statsv1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// OrchestratorServiceName is the fully-qualified name of the OrchestratorService service.
+	OrchestratorServiceName = "stats.v1.OrchestratorService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// OrchestratorServiceOrchestrateProcedure is the fully-qualified name of the OrchestratorService's
+	// Orchestrate RPC.
+	OrchestratorServiceOrchestrateProcedure = "/stats.v1.OrchestratorService/Orchestrate"
+	// OrchestratorServiceOrchestrateStreamProcedure is the fully-qualified name of the
+	// OrchestratorService's OrchestrateStream RPC.
+	OrchestratorServiceOrchestrateStreamProcedure = "/stats.v1.OrchestratorService/OrchestrateStream"
+	// OrchestratorServiceWatchRunsProcedure is the fully-qualified name of the OrchestratorService's
+	// WatchRuns RPC.
+	OrchestratorServiceWatchRunsProcedure = "/stats.v1.OrchestratorService/WatchRuns"
+	// OrchestratorServiceGetJobProcedure is the fully-qualified name of the OrchestratorService's
+	// GetJob RPC.
+	OrchestratorServiceGetJobProcedure = "/stats.v1.OrchestratorService/GetJob"
+	// OrchestratorServiceSearchStatisticsProcedure is the fully-qualified name of the
+	// OrchestratorService's SearchStatistics RPC.
+	OrchestratorServiceSearchStatisticsProcedure = "/stats.v1.OrchestratorService/SearchStatistics"
+)
+
+// OrchestratorServiceClient is a client for the stats.v1.OrchestratorService service.
+type OrchestratorServiceClient interface {
+	// Orchestrate runs a request and returns its result. In queue mode it
+	// returns the queued job instead, to poll with GetJob.
+	Orchestrate(context.Context, *connect.Request[statsv1.OrchestrateRequest]) (*connect.Response[statsv1.OrchestrateResponse], error)
+	// OrchestrateStream runs a request, streaming its progress as it changes,
+	// each statistic the moment it passes verification, any it retracts,
+	// and then its result. It runs on the replica serving the stream, in
+	// queue mode too.
+	OrchestrateStream(context.Context, *connect.Request[statsv1.OrchestrateStreamRequest]) (*connect.ServerStreamForClient[statsv1.OrchestrateStreamResponse], error)
+	// WatchRuns streams the progress of every active run the caller may see:
+	// a STARTED event for each run already active, READY, and then every
+	// change.
+	WatchRuns(context.Context, *connect.Request[statsv1.WatchRunsRequest]) (*connect.ServerStreamForClient[statsv1.WatchRunsResponse], error)
+	// GetJob returns a queued run
+	GetJob(context.Context, *connect.Request[statsv1.GetJobRequest]) (*connect.Response[statsv1.GetJobResponse], error)
+	// SearchStatistics searches the statistics verified by past runs
+	SearchStatistics(context.Context, *connect.Request[statsv1.SearchStatisticsRequest]) (*connect.Response[statsv1.SearchStatisticsResponse], error)
+}
+
+// NewOrchestratorServiceClient constructs a client for the stats.v1.OrchestratorService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewOrchestratorServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) OrchestratorServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	orchestratorServiceMethods := statsv1.File_stats_v1_orchestrator_proto.Services().ByName("OrchestratorService").Methods()
+	return &orchestratorServiceClient{
+		orchestrate: connect.NewClient[statsv1.OrchestrateRequest, statsv1.OrchestrateResponse](
+			httpClient,
+			baseURL+OrchestratorServiceOrchestrateProcedure,
+			connect.WithSchema(orchestratorServiceMethods.ByName("Orchestrate")),
+			connect.WithClientOptions(opts...),
+		),
+		orchestrateStream: connect.NewClient[statsv1.OrchestrateStreamRequest, statsv1.OrchestrateStreamResponse](
+			httpClient,
+			baseURL+OrchestratorServiceOrchestrateStreamProcedure,
+			connect.WithSchema(orchestratorServiceMethods.ByName("OrchestrateStream")),
+			connect.WithClientOptions(opts...),
+		),
+		watchRuns: connect.NewClient[statsv1.WatchRunsRequest, statsv1.WatchRunsResponse](
+			httpClient,
+			baseURL+OrchestratorServiceWatchRunsProcedure,
+			connect.WithSchema(orchestratorServiceMethods.ByName("WatchRuns")),
+			connect.WithClientOptions(opts...),
+		),
+		getJob: connect.NewClient[statsv1.GetJobRequest, statsv1.GetJobResponse](
+			httpClient,
+			baseURL+OrchestratorServiceGetJobProcedure,
+			connect.WithSchema(orchestratorServiceMethods.ByName("GetJob")),
+			connect.WithClientOptions(opts...),
+		),
+		searchStatistics: connect.NewClient[statsv1.SearchStatisticsRequest, statsv1.SearchStatisticsResponse](
+			httpClient,
+			baseURL+OrchestratorServiceSearchStatisticsProcedure,
+			connect.WithSchema(orchestratorServiceMethods.ByName("SearchStatistics")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// orchestratorServiceClient implements OrchestratorServiceClient.
+type orchestratorServiceClient struct {
+	orchestrate       *connect.Client[statsv1.OrchestrateRequest, statsv1.OrchestrateResponse]
+	orchestrateStream *connect.Client[statsv1.OrchestrateStreamRequest, statsv1.OrchestrateStreamResponse]
+	watchRuns         *connect.Client[statsv1.WatchRunsRequest, statsv1.WatchRunsResponse]
+	getJob            *connect.Client[statsv1.GetJobRequest, statsv1.GetJobResponse]
+	searchStatistics  *connect.Client[statsv1.SearchStatisticsRequest, statsv1.SearchStatisticsResponse]
+}
+
+// Orchestrate calls stats.v1.OrchestratorService.Orchestrate.
+func (c *orchestratorServiceClient) Orchestrate(ctx context.Context, req *connect.Request[statsv1.OrchestrateRequest]) (*connect.Response[statsv1.OrchestrateResponse], error) {
+	return c.orchestrate.CallUnary(ctx, req)
+}
+
+// OrchestrateStream calls stats.v1.OrchestratorService.OrchestrateStream.
+func (c *orchestratorServiceClient) OrchestrateStream(ctx context.Context, req *connect.Request[statsv1.OrchestrateStreamRequest]) (*connect.ServerStreamForClient[statsv1.OrchestrateStreamResponse], error) {
+	return c.orchestrateStream.CallServerStream(ctx, req)
+}
+
+// WatchRuns calls stats.v1.OrchestratorService.WatchRuns.
+func (c *orchestratorServiceClient) WatchRuns(ctx context.Context, req *connect.Request[statsv1.WatchRunsRequest]) (*connect.ServerStreamForClient[statsv1.WatchRunsResponse], error) {
+	return c.watchRuns.CallServerStream(ctx, req)
+}
+
+// GetJob calls stats.v1.OrchestratorService.GetJob.
+func (c *orchestratorServiceClient) GetJob(ctx context.Context, req *connect.Request[statsv1.GetJobRequest]) (*connect.Response[statsv1.GetJobResponse], error) {
+	return c.getJob.CallUnary(ctx, req)
+}
+
+// SearchStatistics calls stats.v1.OrchestratorService.SearchStatistics.
+func (c *orchestratorServiceClient) SearchStatistics(ctx context.Context, req *connect.Request[statsv1.SearchStatisticsRequest]) (*connect.Response[statsv1.SearchStatisticsResponse], error) {
+	return c.searchStatistics.CallUnary(ctx, req)
+}
+
+// OrchestratorServiceHandler is an implementation of the stats.v1.OrchestratorService service.
+type OrchestratorServiceHandler interface {
+	// Orchestrate runs a request and returns its result. In queue mode it
+	// returns the queued job instead, to poll with GetJob.
+	Orchestrate(context.Context, *connect.Request[statsv1.OrchestrateRequest]) (*connect.Response[statsv1.OrchestrateResponse], error)
+	// OrchestrateStream runs a request, streaming its progress as it changes,
+	// each statistic the moment it passes verification, any it retracts,
+	// and then its result. It runs on the replica serving the stream, in
+	// queue mode too.
+	OrchestrateStream(context.Context, *connect.Request[statsv1.OrchestrateStreamRequest], *connect.ServerStream[statsv1.OrchestrateStreamResponse]) error
+	// WatchRuns streams the progress of every active run the caller may see:
+	// a STARTED event for each run already active, READY, and then every
+	// change.
+	WatchRuns(context.Context, *connect.Request[statsv1.WatchRunsRequest], *connect.ServerStream[statsv1.WatchRunsResponse]) error
+	// GetJob returns a queued run
+	GetJob(context.Context, *connect.Request[statsv1.GetJobRequest]) (*connect.Response[statsv1.GetJobResponse], error)
+	// SearchStatistics searches the statistics verified by past runs
+	SearchStatistics(context.Context, *connect.Request[statsv1.SearchStatisticsRequest]) (*connect.Response[statsv1.SearchStatisticsResponse], error)
+}
+
+// NewOrchestratorServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewOrchestratorServiceHandler(svc OrchestratorServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	orchestratorServiceMethods := statsv1.File_stats_v1_orchestrator_proto.Services().ByName("OrchestratorService").Methods()
+	orchestratorServiceOrchestrateHandler := connect.NewUnaryHandler(
+		OrchestratorServiceOrchestrateProcedure,
+		svc.Orchestrate,
+		connect.WithSchema(orchestratorServiceMethods.ByName("Orchestrate")),
+		connect.WithHandlerOptions(opts...),
+	)
+	orchestratorServiceOrchestrateStreamHandler := connect.NewServerStreamHandler(
+		OrchestratorServiceOrchestrateStreamProcedure,
+		svc.OrchestrateStream,
+		connect.WithSchema(orchestratorServiceMethods.ByName("OrchestrateStream")),
+		connect.WithHandlerOptions(opts...),
+	)
+	orchestratorServiceWatchRunsHandler := connect.NewServerStreamHandler(
+		OrchestratorServiceWatchRunsProcedure,
+		svc.WatchRuns,
+		connect.WithSchema(orchestratorServiceMethods.ByName("WatchRuns")),
+		connect.WithHandlerOptions(opts...),
+	)
+	orchestratorServiceGetJobHandler := connect.NewUnaryHandler(
+		OrchestratorServiceGetJobProcedure,
+		svc.GetJob,
+		connect.WithSchema(orchestratorServiceMethods.ByName("GetJob")),
+		connect.WithHandlerOptions(opts...),
+	)
+	orchestratorServiceSearchStatisticsHandler := connect.NewUnaryHandler(
+		OrchestratorServiceSearchStatisticsProcedure,
+		svc.SearchStatistics,
+		connect.WithSchema(orchestratorServiceMethods.ByName("SearchStatistics")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/stats.v1.OrchestratorService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case OrchestratorServiceOrchestrateProcedure:
+			orchestratorServiceOrchestrateHandler.ServeHTTP(w, r)
+		case OrchestratorServiceOrchestrateStreamProcedure:
+			orchestratorServiceOrchestrateStreamHandler.ServeHTTP(w, r)
+		case OrchestratorServiceWatchRunsProcedure:
+			orchestratorServiceWatchRunsHandler.ServeHTTP(w, r)
+		case OrchestratorServiceGetJobProcedure:
+			orchestratorServiceGetJobHandler.ServeHTTP(w, r)
+		case OrchestratorServiceSearchStatisticsProcedure:
+			orchestratorServiceSearchStatisticsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedOrchestratorServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedOrchestratorServiceHandler struct{}
+
+func (UnimplementedOrchestratorServiceHandler) Orchestrate(context.Context, *connect.Request[statsv1.OrchestrateRequest]) (*connect.Response[statsv1.OrchestrateResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("stats.v1.OrchestratorService.Orchestrate is not implemented"))
+}
+
+func (UnimplementedOrchestratorServiceHandler) OrchestrateStream(context.Context, *connect.Request[statsv1.OrchestrateStreamRequest], *connect.ServerStream[statsv1.OrchestrateStreamResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("stats.v1.OrchestratorService.OrchestrateStream is not implemented"))
+}
+
+func (UnimplementedOrchestratorServiceHandler) WatchRuns(context.Context, *connect.Request[statsv1.WatchRunsRequest], *connect.ServerStream[statsv1.WatchRunsResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("stats.v1.OrchestratorService.WatchRuns is not implemented"))
+}
+
+func (UnimplementedOrchestratorServiceHandler) GetJob(context.Context, *connect.Request[statsv1.GetJobRequest]) (*connect.Response[statsv1.GetJobResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("stats.v1.OrchestratorService.GetJob is not implemented"))
+}
+
+func (UnimplementedOrchestratorServiceHandler) SearchStatistics(context.Context, *connect.Request[statsv1.SearchStatisticsRequest]) (*connect.Response[statsv1.SearchStatisticsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("stats.v1.OrchestratorService.SearchStatistics is not implemented"))
+}
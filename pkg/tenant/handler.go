@@ -0,0 +1,140 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// publicPaths are served without an API key: health checks, the direct
+// agent's OpenAPI documentation, the orchestrators' tool documents, which
+// frameworks load before they have a key, and their web UI, whose page
+// sends the key itself on each API call
+var publicPaths = map[string]bool{
+	"/health":                     true,
+	"/docs":                       true,
+	"/openapi.json":               true,
+	"/openapi.yaml":               true,
+	"/tool-spec":                  true,
+	"/tools/openapi.json":         true,
+	"/.well-known/ai-plugin.json": true,
+	"/ui":                         true,
+}
+
+// publicPrefixes are the path prefixes served without an API key
+var publicPrefixes = []string{"/ui/"}
+
+// UsagePath is the path of the usage endpoint, which needs a key but is
+// neither counted nor refused for a quota or rate limit, so a tenant can
+// always see what it has used
+const UsagePath = "/usage"
+
+// Middleware requires a tenant API key, sent as X-API-Key or as an
+// Authorization bearer token, on every request but those in publicPaths and publicPrefixes.
+// Browsers cannot set headers on a WebSocket handshake, so it may send the
+// key in the api_key query parameter instead. It
+// answers 401 for a missing or unknown key and 429 once the tenant has
+// used up a daily quota, except on UsagePath, and otherwise passes the
+// tenant on in the request context for Charge. A nil registry lets every
+// request through.
+func (r *Registry) Middleware(next http.Handler) http.Handler {
+	if r == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if isPublic(req.URL.Path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		t, ok := r.Authenticate(apiKey(req))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="stats-agent-team"`)
+			http.Error(w, "missing or invalid API key", http.StatusUnauthorized)
+			return
+		}
+		if path.Clean(req.URL.Path) == UsagePath {
+			next.ServeHTTP(w, req.WithContext(withAccount(req.Context(), r, t)))
+			return
+		}
+		if err := r.Admit(t); err != nil {
+			if errors.Is(err, ErrQuotaExceeded) {
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(withAccount(req.Context(), r, t)))
+	})
+}
+
+// Handler returns the GET /usage handler, which reports today's usage of
+// the calling tenant, or of every tenant for an admin. It must be served
+// behind Middleware.
+func (r *Registry) Handler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r == nil {
+			http.Error(w, "tenants are not configured; set TENANTS_FILE", http.StatusNotFound)
+			return
+		}
+		caller := FromContext(req.Context())
+		if caller == nil {
+			http.Error(w, "missing or invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		usage := r.Usage()
+		if !caller.Admin {
+			for _, u := range usage {
+				if u.Tenant == caller.Name {
+					usage = []Usage{u}
+					break
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(struct {
+			Tenants []Usage `json:"tenants"`
+		}{usage}); err != nil {
+			logger.Error("failed to encode usage", "error", err)
+		}
+	}
+}
+
+// apiKey returns the key sent in X-API-Key, an Authorization bearer token,
+// or, on a WebSocket handshake, the api_key query parameter
+func apiKey(req *http.Request) string {
+	if key := req.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return req.URL.Query().Get("api_key")
+	}
+	return ""
+}
+
+// isPublic reports whether p is served without an API key. p is cleaned
+// first, so dot segments cannot climb out of a public prefix.
+func isPublic(p string) bool {
+	p = path.Clean(p)
+	if publicPaths[p] {
+		return true
+	}
+	for _, prefix := range publicPrefixes {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+// Package tenant authenticates requests to the orchestration and direct
+// services with tenant-scoped API keys and enforces each tenant's daily
+// LLM-cost and search-call quotas, for teams exposing the services
+// internally. Usage is kept in memory per UTC day, so it restarts at zero
+// when the process does.
+package tenant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// ErrQuotaExceeded is returned when a tenant has used up a daily quota
+var ErrQuotaExceeded = errors.New("daily quota exceeded")
+
+// Tenant is one team or service allowed to call the API
+type Tenant struct {
+	Name             string  `json:"name"`
+	Key              string  `json:"key"`                          // API key sent in X-API-Key or Authorization: Bearer
+	DailyCostUSD     float64 `json:"daily_cost_usd,omitempty"`     // Estimated LLM cost allowed per UTC day; 0 for no limit
+	DailySearchCalls int     `json:"daily_search_calls,omitempty"` // Web searches allowed per UTC day; 0 for no limit
+	Admin            bool    `json:"admin,omitempty"`              // May read every tenant's usage
+}
+
+// Usage is a tenant's consumption on one UTC day
+type Usage struct {
+	Tenant           string  `json:"tenant"`
+	Day              string  `json:"day"` // UTC date, YYYY-MM-DD
+	Requests         int     `json:"requests"`
+	Rejected         int     `json:"rejected"` // Requests refused for an exceeded quota
+	LLMCalls         int     `json:"llm_calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd"`
+	SearchCalls      int     `json:"search_calls"`
+	DailyCostUSD     float64 `json:"daily_cost_usd,omitempty"`     // The tenant's cost quota
+	DailySearchCalls int     `json:"daily_search_calls,omitempty"` // The tenant's search quota
+}
+
+// file is the layout of TENANTS_FILE
+type file struct {
+	Tenants []Tenant `json:"tenants"`
+}
+
+// Registry holds the tenants and their usage. It is safe for concurrent use.
+type Registry struct {
+	tenants []*Tenant
+	byKey   map[[sha256.Size]byte]*Tenant
+
+	mu    sync.Mutex
+	usage map[string]*Usage // Today's usage by tenant name
+	now   func() time.Time
+}
+
+// Load reads the tenants in path. It returns a nil registry, which lets
+// every request through, when path is empty.
+func Load(path string) (*Registry, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path from operator config
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid tenants file %s: %w", path, err)
+	}
+	return New(f.Tenants)
+}
+
+// New creates a registry of tenants, which need unique names and keys
+func New(tenants []Tenant) (*Registry, error) {
+	if len(tenants) == 0 {
+		return nil, errors.New("no tenants configured")
+	}
+	r := &Registry{
+		byKey: make(map[[sha256.Size]byte]*Tenant, len(tenants)),
+		usage: make(map[string]*Usage, len(tenants)),
+		now:   time.Now,
+	}
+	names := make(map[string]bool, len(tenants))
+	for i := range tenants {
+		t := tenants[i]
+		if t.Name == "" || t.Key == "" {
+			return nil, fmt.Errorf("tenant %d: name and key are required", i+1)
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("duplicate tenant name %q", t.Name)
+		}
+		hash := sha256.Sum256([]byte(t.Key))
+		if _, ok := r.byKey[hash]; ok {
+			return nil, fmt.Errorf("tenant %q reuses another tenant's key", t.Name)
+		}
+		names[t.Name] = true
+		r.byKey[hash] = &t
+		r.tenants = append(r.tenants, &t)
+	}
+	return r, nil
+}
+
+// Authenticate returns the tenant owning key. Keys are compared by hash, so
+// the lookup time does not depend on how much of a key matches.
+func (r *Registry) Authenticate(key string) (*Tenant, bool) {
+	if key == "" {
+		return nil, false
+	}
+	t, ok := r.byKey[sha256.Sum256([]byte(key))]
+	return t, ok
+}
+
+// Admit counts a request by t, or refuses it with ErrQuotaExceeded when the
+// tenant has used up its cost or search quota for the day. A request that
+// crosses a quota is allowed to finish, as its cost is only known afterwards.
+func (r *Registry) Admit(t *Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.todayLocked(t)
+	switch {
+	case t.DailyCostUSD > 0 && u.EstimatedCostUSD >= t.DailyCostUSD:
+		u.Rejected++
+		return fmt.Errorf("%w: estimated LLM cost $%.2f of $%.2f", ErrQuotaExceeded, u.EstimatedCostUSD, t.DailyCostUSD)
+	case t.DailySearchCalls > 0 && u.SearchCalls >= t.DailySearchCalls:
+		u.Rejected++
+		return fmt.Errorf("%w: %d of %d search calls", ErrQuotaExceeded, u.SearchCalls, t.DailySearchCalls)
+	}
+	u.Requests++
+	return nil
+}
+
+// Record adds the LLM usage and search calls of a finished request to t's usage
+func (r *Registry) Record(t *Tenant, s *models.CostSummary) {
+	if s == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.todayLocked(t)
+	u.LLMCalls += s.Calls
+	u.PromptTokens += s.PromptTokens
+	u.CompletionTokens += s.CompletionTokens
+	u.EstimatedCostUSD += s.EstimatedCostUSD
+	u.SearchCalls += s.SearchCalls
+}
+
+// Usage returns today's usage of every tenant, by name
+func (r *Registry) Usage() []Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Usage, 0, len(r.tenants))
+	for _, t := range r.tenants {
+		out = append(out, *r.todayLocked(t))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tenant < out[j].Tenant })
+	return out
+}
+
+// todayLocked returns t's usage for the current UTC day, starting a new day
+// when the date has changed. The caller must hold r.mu.
+func (r *Registry) todayLocked(t *Tenant) *Usage {
+	day := r.now().UTC().Format(time.DateOnly)
+	u, ok := r.usage[t.Name]
+	if !ok || u.Day != day {
+		u = &Usage{Tenant: t.Name, Day: day, DailyCostUSD: t.DailyCostUSD, DailySearchCalls: t.DailySearchCalls}
+		r.usage[t.Name] = u
+	}
+	return u
+}
+
+// account is the tenant of a request, carried in its context
+type account struct {
+	registry *Registry
+	tenant   *Tenant
+}
+
+// accountKey is the context key for the tenant of a request
+type accountKey struct{}
+
+// withAccount returns a context carrying the tenant of a request
+func withAccount(ctx context.Context, r *Registry, t *Tenant) context.Context {
+	return context.WithValue(ctx, accountKey{}, &account{registry: r, tenant: t})
+}
+
+// WithTenant returns a context charging the named tenant, for work done
+// outside the request that was admitted, such as a queued job. The context
+// is returned unchanged when r is nil or has no such tenant.
+func (r *Registry) WithTenant(ctx context.Context, name string) context.Context {
+	if r == nil || name == "" {
+		return ctx
+	}
+	for _, t := range r.tenants {
+		if t.Name == name {
+			return withAccount(ctx, r, t)
+		}
+	}
+	return ctx
+}
+
+// FromContext returns the tenant of a request, or nil when tenants are not
+// configured
+func FromContext(ctx context.Context) *Tenant {
+	if a, ok := ctx.Value(accountKey{}).(*account); ok {
+		return a.tenant
+	}
+	return nil
+}
+
+// Charge records the cost summary of a finished request against the tenant
+// that made it. A request that fails charges the usage tracked up to the
+// failure, as its summary never reaches the handler. It is a no-op when
+// tenants are not configured.
+func Charge(ctx context.Context, s *models.CostSummary) {
+	if a, ok := ctx.Value(accountKey{}).(*account); ok {
+		a.registry.Record(a.tenant, s)
+	}
+}
+
+// ChargeFailed charges the usage reported by summary when *err is set. It
+// is deferred by functions tracking a request's usage, whose cost summary
+// is lost when they fail.
+func ChargeFailed(ctx context.Context, summary func() *models.CostSummary, err *error) {
+	if *err != nil {
+		Charge(ctx, summary())
+	}
+}
@@ -0,0 +1,183 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func newRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r, err := New([]Tenant{
+		{Name: "search-team", Key: "key-a", DailyCostUSD: 1.00, DailySearchCalls: 10},
+		{Name: "ops", Key: "key-b", Admin: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestNewRejectsInvalidTenants(t *testing.T) {
+	tests := [][]Tenant{
+		nil,
+		{{Name: "a"}},
+		{{Name: "a", Key: "k1"}, {Name: "a", Key: "k2"}},
+		{{Name: "a", Key: "k"}, {Name: "b", Key: "k"}},
+	}
+	for i, tenants := range tests {
+		if _, err := New(tenants); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestAdmitEnforcesDailyQuotas(t *testing.T) {
+	r := newRegistry(t)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	team, _ := r.Authenticate("key-a")
+
+	if err := r.Admit(team); err != nil {
+		t.Fatalf("Admit() = %v", err)
+	}
+	r.Record(team, &models.CostSummary{Calls: 3, EstimatedCostUSD: 0.60, SearchCalls: 4})
+	if err := r.Admit(team); err != nil {
+		t.Fatalf("Admit() under quota = %v", err)
+	}
+	r.Record(team, &models.CostSummary{Calls: 2, EstimatedCostUSD: 0.50, SearchCalls: 1})
+	if err := r.Admit(team); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Admit() over cost quota = %v; want ErrQuotaExceeded", err)
+	}
+
+	u := r.Usage()[1]
+	if u.Tenant != "search-team" || u.Requests != 2 || u.Rejected != 1 || u.LLMCalls != 5 || u.SearchCalls != 5 {
+		t.Errorf("usage = %+v", u)
+	}
+
+	// A new UTC day starts from zero
+	now = now.Add(24 * time.Hour)
+	if err := r.Admit(team); err != nil {
+		t.Errorf("Admit() next day = %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	r := newRegistry(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orchestrate", func(w http.ResponseWriter, req *http.Request) {
+		Charge(req.Context(), &models.CostSummary{Calls: 1, EstimatedCostUSD: 0.25, SearchCalls: 2})
+	})
+	mux.HandleFunc("/usage", r.Handler(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	mux.HandleFunc("/health", func(http.ResponseWriter, *http.Request) {})
+	mux.HandleFunc("/ui/", func(http.ResponseWriter, *http.Request) {})
+	handler := r.Middleware(mux)
+
+	serve := func(path, header, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set(header, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/health", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("/health without key = %d", rec.Code)
+	}
+	if rec := serve("/ui/app.js", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("/ui/app.js without key = %d", rec.Code)
+	}
+	if rec := serve("/tool-spec", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("/tool-spec without key = %d; want it passed on", rec.Code)
+	}
+	if rec := serve("/ui/../orchestrate", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("/ui/../orchestrate without key = %d; want 401", rec.Code)
+	}
+	if rec := serve("/orchestrate", "X-API-Key", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unknown key = %d; want 401", rec.Code)
+	}
+	if rec := serve("/orchestrate", "Authorization", "Bearer key-a"); rec.Code != http.StatusOK {
+		t.Errorf("bearer key = %d", rec.Code)
+	}
+
+	var got struct {
+		Tenants []Usage `json:"tenants"`
+	}
+	rec := serve("/usage", "X-API-Key", "key-a")
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	// Reading usage is not counted as a request
+	if len(got.Tenants) != 1 || got.Tenants[0].SearchCalls != 2 || got.Tenants[0].Requests != 1 {
+		t.Errorf("tenant usage = %+v", got.Tenants)
+	}
+
+	rec = serve("/usage", "X-API-Key", "key-b")
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tenants) != 2 {
+		t.Errorf("admin usage = %+v", got.Tenants)
+	}
+
+	// Only WebSocket handshakes may send the key as a query parameter
+	if rec := serve("/orchestrate?api_key=key-a", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("query key = %d; want 401", rec.Code)
+	}
+	if rec := serve("/orchestrate?api_key=key-a", "Upgrade", "websocket"); rec.Code != http.StatusOK {
+		t.Errorf("query key on WebSocket handshake = %d", rec.Code)
+	}
+
+	// A tenant over its quota is refused runs but can still read its usage
+	for range 2 {
+		serve("/orchestrate", "X-API-Key", "key-a")
+	}
+	if rec := serve("/orchestrate", "X-API-Key", "key-a"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("over quota = %d; want 429", rec.Code)
+	}
+	if rec := serve("/usage", "X-API-Key", "key-a"); rec.Code != http.StatusOK {
+		t.Errorf("usage over quota = %d; want 200", rec.Code)
+	}
+}
+
+func TestChargeFailed(t *testing.T) {
+	r := newRegistry(t)
+	team, _ := r.Authenticate("key-a")
+	ctx := withAccount(context.Background(), r, team)
+	summary := func() *models.CostSummary { return &models.CostSummary{Calls: 3, EstimatedCostUSD: 0.1} }
+
+	var err error
+	ChargeFailed(ctx, summary, &err)
+	if u := r.Usage()[1]; u.LLMCalls != 0 {
+		t.Errorf("usage after success = %+v; want it left to the handler", u)
+	}
+	err = errors.New("verification agent unavailable")
+	ChargeFailed(ctx, summary, &err)
+	if u := r.Usage()[1]; u.LLMCalls != 3 || u.EstimatedCostUSD != 0.1 {
+		t.Errorf("usage after failure = %+v", u)
+	}
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	rec := httptest.NewRecorder()
+	r.Middleware(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orchestrate", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("nil registry = %d", rec.Code)
+	}
+	if FromContext(context.Background()) != nil {
+		t.Error("expected no tenant")
+	}
+	Charge(context.Background(), &models.CostSummary{Calls: 1})
+}
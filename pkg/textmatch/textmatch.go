@@ -0,0 +1,156 @@
+// Package textmatch provides normalized, whitespace- and entity-insensitive
+// excerpt matching used when verifying statistics against re-fetched sources.
+package textmatch
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultThreshold is the minimum similarity for an excerpt to count as found
+const DefaultThreshold = 0.9
+
+// windowSlack allows for a few inserted or dropped tokens around a match
+const windowSlack = 3
+
+// replacer folds typographic variants that NFKC leaves distinct
+var replacer = strings.NewReplacer(
+	"\u00ad", "", // soft hyphen
+	"\u200b", "", // zero-width space
+	"\u200c", "", // zero-width non-joiner
+	"\u200d", "", // zero-width joiner
+	"\ufeff", "", // byte order mark
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u201b", "'",
+	"\u201c", `"`, "\u201d", `"`, "\u201e", `"`, "\u201f", `"`,
+	"\u2010", "-", "\u2011", "-", "\u2012", "-", "\u2013", "-", "\u2014", "-", "\u2212", "-",
+)
+
+// Normalize decodes HTML entities, applies Unicode NFKC, folds smart quotes,
+// dashes, and invisible characters, lowercases, and collapses whitespace.
+func Normalize(s string) string {
+	return strings.ToLower(Fold(s))
+}
+
+// Fold normalizes like Normalize but keeps case, for text that is shown
+func Fold(s string) string {
+	s = html.UnescapeString(s)
+	s = norm.NFKC.String(s)
+	s = replacer.Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Contains reports whether needle occurs in haystack after normalization with
+// a similarity of at least threshold, returning the best similarity found.
+func Contains(haystack, needle string, threshold float64) (bool, float64) {
+	score := Similarity(haystack, needle)
+	return score >= threshold, score
+}
+
+// Similarity returns the best match score (0..1) of needle within haystack.
+// An exact normalized substring scores 1. Otherwise the needle's tokens are
+// aligned against windows of the haystack anchored on the needle's numbers;
+// a window only scores if it contains every number in the needle, so a fuzzy
+// match can never accept a different value.
+func Similarity(haystack, needle string) float64 {
+	h := Normalize(haystack)
+	n := Normalize(needle)
+	if n == "" {
+		return 0
+	}
+	if strings.Contains(h, n) {
+		return 1
+	}
+
+	needleTokens := tokens(n)
+	hayTokens := tokens(h)
+	if len(needleTokens) == 0 || len(hayTokens) == 0 {
+		return 0
+	}
+
+	// Anchor on the first numeric token, or the first token if there are none
+	anchor := 0
+	var numbers []string
+	for i, tok := range needleTokens {
+		if isNumeric(tok) {
+			if numbers == nil {
+				anchor = i
+			}
+			numbers = append(numbers, tok)
+		}
+	}
+
+	best := 0.0
+	for pos, tok := range hayTokens {
+		if tok != needleTokens[anchor] {
+			continue
+		}
+		start := max(0, pos-anchor-windowSlack)
+		end := min(len(hayTokens), pos-anchor+len(needleTokens)+windowSlack)
+		window := hayTokens[start:end]
+		if !containsAll(window, numbers) {
+			continue
+		}
+		score := float64(lcs(needleTokens, window)) / float64(len(needleTokens))
+		if score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// tokens splits normalized text into words, trimming surrounding punctuation
+func tokens(s string) []string {
+	fields := strings.Fields(s)
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimFunc(f, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%'
+		})
+		if f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// isNumeric reports whether a token contains a digit
+func isNumeric(tok string) bool {
+	return strings.IndexFunc(tok, unicode.IsDigit) >= 0
+}
+
+// containsAll reports whether every want token appears in window
+func containsAll(window, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, tok := range window {
+			if tok == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// lcs returns the length of the longest common token subsequence of a and b
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				curr[j] = prev[j-1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j-1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
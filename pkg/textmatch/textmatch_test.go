@@ -0,0 +1,51 @@
+package textmatch
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	in := "Nearly half&nbsp;of “adults” use­social  media – 48%"
+	want := `nearly half of "adults" usesocial media - 48%`
+	if got := Normalize(in); got != want {
+		t.Errorf("Normalize() = %q, want %q", got, want)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	page := `Survey results. According to the report, nearly half of “U.S. adults” (48%) say they
+		get news from social media often or sometimes, up from 2022.`
+
+	tests := []struct {
+		name    string
+		excerpt string
+		wantMin float64
+		wantMax float64
+	}{
+		{"exact after normalization", `nearly half of "U.S. adults" (48%) say they get news`, 1, 1},
+		{"punctuation differences", "nearly half of U.S. adults 48% say they get news from social media", 1, 1},
+		{"extra word", "nearly half of all U.S. adults (48%) say they get news", 0.9, 0.99},
+		{"different number", "nearly half of U.S. adults (46%) say they get news", 0, 0},
+		{"absent", "two thirds of teens use TikTok daily", 0, 0},
+		{"empty", "", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(page, tt.excerpt)
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("Similarity() = %v, want in [%v, %v]", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestContains_Threshold(t *testing.T) {
+	page := "Global EV sales grew 35 percent in 2023 to nearly 14 million vehicles."
+	excerpt := "EV sales grew by 35 percent in 2023 to 14 million"
+
+	if ok, score := Contains(page, excerpt, DefaultThreshold); !ok {
+		t.Errorf("expected match at default threshold, got score %v", score)
+	}
+	if ok, score := Contains(page, excerpt, 0.95); ok {
+		t.Errorf("expected no match at 0.95 threshold, got score %v", score)
+	}
+}
@@ -0,0 +1,131 @@
+// Package timing records how long each pipeline stage takes, for the
+// per-stage latency breakdown reported with every run.
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Stage is a part of the pipeline whose time is reported separately
+type Stage string
+
+// Stages reported in models.Timings
+const (
+	Search       Stage = "search"
+	Fetch        Stage = "fetch"
+	Extraction   Stage = "extraction"
+	Verification Stage = "verification"
+)
+
+// Recorder sums the time spent in each stage of a run. It is safe for
+// concurrent use, and a nil Recorder records nothing.
+type Recorder struct {
+	start time.Time
+	now   func() time.Time
+
+	mu      sync.Mutex
+	stages  map[Stage]time.Duration
+	retries int
+}
+
+// New returns a recorder whose total time starts now
+func New() *Recorder {
+	return &Recorder{start: time.Now(), now: time.Now, stages: make(map[Stage]time.Duration)}
+}
+
+// Add records d as spent in stage
+func (r *Recorder) Add(stage Stage, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stages[stage] += d
+}
+
+// Since records the time from start until now as spent in stage. Deferred
+// with time.Now() as start, it times the rest of a function.
+func (r *Recorder) Since(stage Stage, start time.Time) {
+	if r == nil {
+		return
+	}
+	r.Add(stage, r.now().Sub(start))
+}
+
+// Rest records the time since the recorder was created that no stage has
+// yet been given as spent in stage. Agents call it at the end of a pass,
+// so a synthesis pass reports its fetches as fetch time and everything
+// else as extraction time.
+func (r *Recorder) Rest(stage Stage) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rest := r.now().Sub(r.start)
+	for _, d := range r.stages {
+		rest -= d
+	}
+	r.stages[stage] += max(rest, 0)
+}
+
+// Retry counts a pass of the pipeline after the first
+func (r *Recorder) Retry() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retries++
+}
+
+// Merge adds the stage times and retries reported by another agent. Its
+// total is not added, as the time was already spent within this run.
+func (r *Recorder) Merge(t *models.Timings) {
+	if r == nil || t == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stages[Search] += time.Duration(t.SearchMS) * time.Millisecond
+	r.stages[Fetch] += time.Duration(t.FetchMS) * time.Millisecond
+	r.stages[Extraction] += time.Duration(t.ExtractionMS) * time.Millisecond
+	r.stages[Verification] += time.Duration(t.VerificationMS) * time.Millisecond
+	r.retries += t.Retries
+}
+
+// Timings returns the time recorded so far, with the total time since the
+// recorder was created
+func (r *Recorder) Timings() *models.Timings {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &models.Timings{
+		TotalMS:        r.now().Sub(r.start).Milliseconds(),
+		SearchMS:       r.stages[Search].Milliseconds(),
+		FetchMS:        r.stages[Fetch].Milliseconds(),
+		ExtractionMS:   r.stages[Extraction].Milliseconds(),
+		VerificationMS: r.stages[Verification].Milliseconds(),
+		Retries:        r.retries,
+	}
+}
+
+// recorderKey is the context key for the recorder of a run
+type recorderKey struct{}
+
+// WithRecorder returns a context whose stage times are recorded in r
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder carried by ctx, or nil if none is set
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
@@ -0,0 +1,67 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// fakeClock returns a recorder started at t0 and a function advancing its clock
+func fakeClock() (*Recorder, func(time.Duration)) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := New()
+	r.start = now
+	r.now = func() time.Time { return now }
+	return r, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRestAttributesUnrecordedTime(t *testing.T) {
+	r, advance := fakeClock()
+
+	start := r.now()
+	advance(300 * time.Millisecond)
+	r.Since(Fetch, start)
+	advance(700 * time.Millisecond)
+	r.Rest(Extraction)
+
+	got := r.Timings()
+	want := models.Timings{TotalMS: 1000, FetchMS: 300, ExtractionMS: 700}
+	if *got != want {
+		t.Errorf("Timings() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestMergeAddsStagesButNotTotal(t *testing.T) {
+	r, advance := fakeClock()
+	r.Add(Search, 2*time.Second)
+	r.Merge(&models.Timings{TotalMS: 5000, FetchMS: 1000, ExtractionMS: 4000})
+	r.Merge(&models.Timings{TotalMS: 900, FetchMS: 400, VerificationMS: 500})
+	r.Merge(nil)
+	r.Retry()
+	advance(8 * time.Second)
+
+	got := r.Timings()
+	want := models.Timings{TotalMS: 8000, SearchMS: 2000, FetchMS: 1400, ExtractionMS: 4000, VerificationMS: 500, Retries: 1}
+	if *got != want {
+		t.Errorf("Timings() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	r := FromContext(context.Background())
+	r.Add(Fetch, time.Second)
+	r.Since(Search, time.Now())
+	r.Rest(Extraction)
+	r.Retry()
+	r.Merge(&models.Timings{FetchMS: 1})
+	if got := r.Timings(); got != nil {
+		t.Errorf("nil recorder Timings() = %+v, want nil", got)
+	}
+
+	rec := New()
+	if FromContext(WithRecorder(context.Background(), rec)) != rec {
+		t.Error("FromContext did not return the recorder set by WithRecorder")
+	}
+}
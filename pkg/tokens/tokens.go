@@ -0,0 +1,265 @@
+// Package tokens estimates how many tokens a text takes and how large a
+// model's context window is, so prompts are sized to the model that reads
+// them rather than to a fixed number of characters.
+//
+// Counts approximate the providers' BPE and SentencePiece tokenizers by
+// splitting text the way they pre-tokenize it: words, digit groups,
+// punctuation, and whitespace. They err on the high side, so text trimmed to
+// a budget fits it.
+package tokens
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// windows lists known context windows in tokens, matched by longest
+// model-name prefix like usage prices
+var windows = map[string]int{
+	// Gemini
+	"gemini-2.5":       1_048_576,
+	"gemini-2.0":       1_048_576,
+	"gemini-1.5-pro":   2_097_152,
+	"gemini-1.5-flash": 1_048_576,
+
+	// Claude
+	"claude": 200_000,
+
+	// OpenAI
+	"gpt-4o":        128_000,
+	"gpt-4.1":       1_047_576,
+	"gpt-4-turbo":   128_000,
+	"gpt-3.5-turbo": 16_385,
+	"o3-mini":       200_000,
+
+	// xAI
+	"grok-3": 131_072,
+	"grok-4": 256_000,
+
+	// Groq
+	"llama-3.3-70b-versatile": 131_072,
+	"llama-3.1-8b-instant":    131_072,
+
+	// Mistral
+	"mistral-large":  131_072,
+	"mistral-medium": 131_072,
+	"mistral-small":  32_768,
+
+	// DeepSeek
+	"deepseek-chat":     65_536,
+	"deepseek-reasoner": 65_536,
+}
+
+// providerWindows are the context windows assumed for models missing from
+// the table. Ollama serves every model with its own context length, 4096
+// tokens unless the server is configured otherwise, and silently drops the
+// start of longer prompts, so its models never use the table.
+var providerWindows = map[string]int{
+	"gemini":   1_048_576,
+	"claude":   200_000,
+	"openai":   128_000,
+	"xai":      131_072,
+	"groq":     131_072,
+	"mistral":  32_768,
+	"deepseek": 65_536,
+	"ollama":   4096,
+}
+
+// DefaultWindow is the context window assumed for an unknown provider
+const DefaultWindow = 8192
+
+// Windows maps model-name prefixes to context windows in tokens, taking
+// precedence over the built-in table
+type Windows map[string]int
+
+// ParseWindows parses "model=tokens" entries, such as "llama3.2=8192"
+func ParseWindows(entries []string) (Windows, error) {
+	w := make(Windows, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || name == "" || err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid context window %q: want model=tokens", entry)
+		}
+		w[name] = n
+	}
+	return w, nil
+}
+
+// Lookup returns the context window of a model: the longest matching
+// prefix in w, then in the built-in table, then the provider's default
+func (w Windows) Lookup(provider, modelName string) int {
+	if n, ok := longestPrefix(w, modelName); ok {
+		return n
+	}
+	if provider != "ollama" {
+		if n, ok := longestPrefix(windows, modelName); ok {
+			return n
+		}
+	}
+	if n, ok := providerWindows[provider]; ok {
+		return n
+	}
+	return DefaultWindow
+}
+
+// longestPrefix returns the value of the longest key of m that prefixes name
+func longestPrefix(m map[string]int, name string) (int, bool) {
+	best := ""
+	for prefix := range m {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return 0, false
+	}
+	return m[best], true
+}
+
+// Budget divides a model's context window between a prompt and the
+// completion it asks for
+type Budget struct {
+	Provider string
+	Model    string
+	Window   int // Context window in tokens
+	Output   int // Tokens reserved for the completion
+}
+
+// margin is the share of the prompt room held back for the error of the
+// estimates, in percent
+const margin = 10
+
+// Prompt returns the tokens available to a prompt
+func (b Budget) Prompt() int {
+	return max(0, (b.Window-b.Output)*(100-margin)/100)
+}
+
+// Room returns the tokens left for content added to prompt
+func (b Budget) Room(prompt string) int {
+	return max(0, b.Prompt()-Count(b.Provider, prompt))
+}
+
+// Count estimates the tokens text takes for a provider's models
+func Count(provider, text string) int {
+	n := 0
+	scan(provider, text, func(_, tokens int) bool {
+		n += tokens
+		return true
+	})
+	return n
+}
+
+// Trim returns the longest prefix of text that takes at most limit tokens,
+// cut between pieces so no word or character is split
+func Trim(provider, text string, limit int) string {
+	n, cut := 0, len(text)
+	scan(provider, text, func(start, tokens int) bool {
+		if n+tokens > limit {
+			cut = start
+			return false
+		}
+		n += tokens
+		return true
+	})
+	return text[:cut]
+}
+
+// Split divides text into consecutive chunks of at most size tokens,
+// ending each at its last line break when one falls in its second half
+func Split(provider, text string, size int) []string {
+	var chunks []string
+	for text != "" {
+		chunk := Trim(provider, text, size)
+		if chunk == "" {
+			break // A single piece is larger than size
+		}
+		if len(chunk) < len(text) {
+			if i := strings.LastIndexByte(chunk, '\n'); i >= len(chunk)/2 {
+				chunk = chunk[:i+1]
+			}
+		}
+		chunks = append(chunks, chunk)
+		text = text[len(chunk):]
+	}
+	return chunks
+}
+
+// scan splits text into pre-tokenizer pieces, calling yield with the byte
+// offset and estimated tokens of each until it returns false
+func scan(provider, text string, yield func(start, tokens int) bool) {
+	// Gemini's SentencePiece vocabulary splits numbers into single digits;
+	// BPE vocabularies merge up to three
+	digitsPerToken := 3
+	if provider == "gemini" || provider == "" {
+		digitsPerToken = 1
+	}
+
+	for i := 0; i < len(text); {
+		start := i
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		// A single space joins the word that follows it
+		if r == ' ' && i+1 < len(text) {
+			if next, _ := utf8.DecodeRuneInString(text[i+1:]); unicode.IsLetter(next) || unicode.IsDigit(next) {
+				i++
+				r, size = next, utf8.RuneLen(next)
+			}
+		}
+
+		var tokens int
+		switch {
+		case wide(r):
+			// Ideographic and syllabic scripts take about a token per character
+			i, tokens = i+size, 1
+		case unicode.IsLetter(r):
+			end, runes, ascii := run(text, i, func(r rune) bool { return unicode.IsLetter(r) && !wide(r) })
+			i = end
+			if ascii {
+				tokens = 1 + runes/8
+			} else {
+				tokens = (runes + 1) / 2
+			}
+		case unicode.IsDigit(r):
+			end, runes, _ := run(text, i, unicode.IsDigit)
+			i, tokens = end, (runes+digitsPerToken-1)/digitsPerToken
+		case unicode.IsSpace(r):
+			end, _, _ := run(text, i, unicode.IsSpace)
+			i, tokens = end, 1
+		default:
+			end, runes, _ := run(text, i, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+			})
+			i, tokens = end, (runes+1)/2
+		}
+		if !yield(start, tokens) {
+			return
+		}
+	}
+}
+
+// run returns the end of the run of runes matching in starting at i, its
+// length in runes, and whether it is all ASCII
+func run(text string, i int, in func(rune) bool) (end, runes int, ascii bool) {
+	ascii = true
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !in(r) {
+			break
+		}
+		ascii = ascii && r < utf8.RuneSelf
+		i += size
+		runes++
+	}
+	return i, runes, ascii
+}
+
+// wide reports whether r belongs to a CJK or similar script, whose
+// characters are tokenized one by one
+func wide(r rune) bool {
+	return r >= 0x2E80 && unicode.IsLetter(r)
+}
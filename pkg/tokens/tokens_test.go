@@ -0,0 +1,110 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		provider string
+		text     string
+		want     int
+	}{
+		{"openai", "", 0},
+		{"openai", "GDP grew by 2.5 percent", 7},
+		{"openai", "1234567", 3},
+		{"gemini", "1234567", 7},
+		{"openai", "internationalization", 3},
+		{"openai", "<td>", 3},
+		{"openai", "統計データ", 5},
+	}
+	for _, tt := range tests {
+		if got := Count(tt.provider, tt.text); got != tt.want {
+			t.Errorf("Count(%q, %q) = %d, want %d", tt.provider, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	text := strings.Repeat("The unemployment rate was 4.1 percent. ", 100)
+	limit := 50
+	got := Trim("openai", text, limit)
+	if n := Count("openai", got); n > limit || n < limit-5 {
+		t.Errorf("Trim() kept %d tokens, want close to %d", n, limit)
+	}
+	if !strings.HasPrefix(text, got) {
+		t.Errorf("Trim() = %q, not a prefix", got)
+	}
+	if got := Trim("openai", "short text", 100); got != "short text" {
+		t.Errorf("Trim() of fitting text = %q", got)
+	}
+	if got := Trim("openai", "統計データ", 2); got != "統計" {
+		t.Errorf("Trim() of CJK = %q, want whole characters", got)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	text := strings.Repeat("Inflation was 3.2 percent in the year to March.\n", 40)
+	chunks := Split("openai", text, 100)
+	if len(chunks) < 4 {
+		t.Fatalf("Split() = %d chunks, want at least 4", len(chunks))
+	}
+	if joined := strings.Join(chunks, ""); joined != text {
+		t.Error("Split() chunks do not add up to the text")
+	}
+	for i, c := range chunks {
+		if n := Count("openai", c); n > 100 {
+			t.Errorf("chunk %d has %d tokens", i, n)
+		}
+		if !strings.HasSuffix(c, "\n") {
+			t.Errorf("chunk %d does not end at a line break: %q", i, c)
+		}
+	}
+	if got := Split("openai", "", 100); len(got) != 0 {
+		t.Errorf("Split() of empty text = %q", got)
+	}
+}
+
+func TestWindowsLookup(t *testing.T) {
+	w, err := ParseWindows([]string{"llama3.2=8192", "gpt-4o-mini=64000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		provider, model string
+		want            int
+	}{
+		{"openai", "gpt-4o-mini", 64000},
+		{"openai", "gpt-4o-2024-08-06", 128_000},
+		{"claude", "claude-sonnet-4-20250514", 200_000},
+		{"ollama", "llama3.2", 8192},
+		{"ollama", "mistral-small:24b", 4096},
+		{"openai", "some-new-model", 128_000},
+		{"other", "model", DefaultWindow},
+	}
+	for _, tt := range tests {
+		if got := w.Lookup(tt.provider, tt.model); got != tt.want {
+			t.Errorf("Lookup(%q, %q) = %d, want %d", tt.provider, tt.model, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"llama3.2", "llama3.2=0", "=8192", "llama3.2=big"} {
+		if _, err := ParseWindows([]string{bad}); err == nil {
+			t.Errorf("ParseWindows(%q) = nil error", bad)
+		}
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := Budget{Provider: "ollama", Window: 4096, Output: 1024}
+	if got := b.Prompt(); got != 2764 {
+		t.Errorf("Prompt() = %d, want 2764", got)
+	}
+	if got := b.Room("Extract statistics"); got != 2761 {
+		t.Errorf("Room() = %d, want 2761", got)
+	}
+	if got := (Budget{Window: 1000, Output: 2000}).Prompt(); got != 0 {
+		t.Errorf("Prompt() of overcommitted budget = %d, want 0", got)
+	}
+}
@@ -0,0 +1,124 @@
+package toolspec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/llm"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/models/migrate"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+// Handler returns the handler of the tool documents:
+//
+//	GET /tool-spec                   the minimal tool spec
+//	GET /tools/openapi.json          the OpenAPI document of the tools
+//	GET /.well-known/ai-plugin.json  the plugin manifest
+//
+// URLs in the documents are built from the request's host, so they name
+// the orchestrator as the caller reached it. withAPIKey, set when tenant
+// API keys are configured, declares the X-API-Key requirement.
+func Handler(cfg *config.Config, withAPIKey bool, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		base := baseURL(r)
+		var body any
+		switch r.URL.Path {
+		case SpecPath:
+			body = Document(cfg, base, withAPIKey).ToSpec()
+		case OpenAPIPath:
+			body = Document(cfg, base, withAPIKey)
+		case ManifestPath:
+			body = NewManifest(base, withAPIKey)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		// Clients on other origins fetch the documents to register the tools
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(body); err != nil {
+			logger.Error("failed to encode tool document", "path", r.URL.Path, "error", err)
+		}
+	}
+}
+
+// baseURL is the scheme and host the request was sent to, honoring
+// X-Forwarded-Proto from a TLS-terminating proxy
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// VerifyFunc verifies candidates, typically by calling the verification agent
+type VerifyFunc func(ctx context.Context, req *models.VerificationRequest) (*models.VerificationResponse, error)
+
+// FromRequest clears what a caller cannot claim about candidates it sends:
+// that they were read from internal search results, whose sources are
+// fetched with the site credentials
+func FromRequest(candidates []models.CandidateStatistic) {
+	for i := range candidates {
+		candidates[i].Internal = false
+	}
+}
+
+// VerifyHandler returns the orchestrators' POST /verify handler, the
+// verify_statistics tool. It passes the request on to verify, so tool
+// clients need only the orchestrator's URL, and charges the tenant for the
+// verification's LLM usage. Requests with more than MaxVerifyCandidates
+// candidates are rejected.
+func VerifyHandler(cfg *config.Config, verify VerifyFunc, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req models.VerificationRequest
+		if err := migrate.Decode(r.Body, &req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(req.Candidates) == 0 {
+			http.Error(w, "Invalid request: candidates is required", http.StatusBadRequest)
+			return
+		}
+		if len(req.Candidates) > models.MaxVerifyCandidates {
+			http.Error(w, fmt.Sprintf("Invalid request: at most %d candidates per request", models.MaxVerifyCandidates), http.StatusBadRequest)
+			return
+		}
+		FromRequest(req.Candidates)
+		if err := llm.ValidateOverride(cfg, req.Model); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp, err := verify(r.Context(), &req)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Verification failed: %v", err), http.StatusBadGateway)
+			return
+		}
+		tenant.Charge(r.Context(), resp.Usage)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.Error("failed to encode verification response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+// Package toolspec describes the orchestrators' search_statistics and
+// verify_statistics endpoints as tools, for agent frameworks without MCP
+// support. LangChain and LlamaIndex load the OpenAPI document (directly or
+// through the plugin manifest); simpler frameworks read the minimal tool
+// spec, which is derived from the same document.
+package toolspec
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Tool names, shared with the MCP server. lookup_statistics is only offered
+// over MCP; HTTP clients call GET /statistics/search.
+const (
+	SearchStatistics = "search_statistics"
+	VerifyStatistics = "verify_statistics"
+	LookupStatistics = "lookup_statistics"
+)
+
+// Paths of the documents served by Handler
+const (
+	SpecPath     = "/tool-spec"
+	OpenAPIPath  = "/tools/openapi.json"
+	ManifestPath = "/.well-known/ai-plugin.json"
+)
+
+// apiKeyScheme names the API key security scheme in the OpenAPI document
+const apiKeyScheme = "apiKey"
+
+// Descriptions of the tools, written for the model choosing between them
+const (
+	SearchDescription = "Search for verified statistics on a given topic using a multi-agent system. " +
+		"The system uses research and verification agents to find and validate statistics from " +
+		"reputable sources (government agencies, academic institutions, research organizations). " +
+		"Returns verified statistics with their sources, URLs, and verbatim excerpts."
+	VerifyDescription = "Verify that statistics actually appear in their claimed sources. Each candidate's " +
+		"source URL is fetched and checked for the excerpt and value. Returns a verdict per candidate, " +
+		"with the reason and failure category for those that fail."
+	LookupDescription = "Look up statistics that previous searches already verified, by topic and/or keywords. " +
+		"Answers instantly from the local statistics store without searching the web, so try it before " +
+		"search_statistics, and search only when nothing suitable is found or newer data is needed. " +
+		"Returns the stored statistics with their sources, URLs, verbatim excerpts, confidence, and when they were last verified."
+)
+
+// OpenAPI is the subset of an OpenAPI 3.1 document used to describe the tools
+type OpenAPI struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components *Components           `json:"components,omitempty"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// Server is a base URL of the API
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem holds the operation on a path; every tool is a POST
+type PathItem struct {
+	Post *Operation `json:"post,omitempty"`
+}
+
+// Operation is one tool: its operationId is the tool name
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Description string              `json:"description"`
+	RequestBody RequestBody         `json:"requestBody"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// RequestBody holds the tool's parameters as a JSON Schema
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes one status code of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a JSON body
+type MediaType struct {
+	Schema map[string]any `json:"schema"`
+}
+
+// Components holds the security schemes
+type Components struct {
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme is an API key sent in a header
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// Spec is the minimal tool spec: each tool with the URL to POST its
+// parameters to as JSON
+type Spec struct {
+	Tools      []SpecTool `json:"tools"`
+	OpenAPIURL string     `json:"openapi_url"`
+	APIKey     *APIKey    `json:"api_key,omitempty"` // Set when the orchestrator requires one
+}
+
+// SpecTool is one tool of the minimal spec
+type SpecTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	Parameters  map[string]any `json:"parameters"` // JSON Schema of the request body
+}
+
+// APIKey tells clients how to send a tenant API key
+type APIKey struct {
+	Header string `json:"header"`
+}
+
+// Manifest is the plugin manifest (ai-plugin.json) pointing at the OpenAPI
+// document, as loaded by LangChain's AIPluginTool
+type Manifest struct {
+	SchemaVersion       string       `json:"schema_version"`
+	NameForHuman        string       `json:"name_for_human"`
+	NameForModel        string       `json:"name_for_model"`
+	DescriptionForHuman string       `json:"description_for_human"`
+	DescriptionForModel string       `json:"description_for_model"`
+	Auth                ManifestAuth `json:"auth"`
+	API                 ManifestAPI  `json:"api"`
+}
+
+// ManifestAuth is the authentication of a plugin manifest
+type ManifestAuth struct {
+	Type              string `json:"type"`                         // "none" or "service_http"
+	AuthorizationType string `json:"authorization_type,omitempty"` // "bearer" when Type is service_http
+}
+
+// ManifestAPI points a plugin manifest at its OpenAPI document
+type ManifestAPI struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// SearchParameters returns the JSON Schema of search_statistics' parameters,
+// a subset of the orchestration request, with cfg's defaults in the
+// descriptions
+func SearchParameters(cfg *config.Config) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"topic": map[string]any{
+				"type":        "string",
+				"description": "The topic to search statistics for (e.g., 'climate change', 'AI adoption rates', 'cybersecurity threats')",
+			},
+			"min_verified_stats": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Minimum number of verified statistics to return (default: %d)", cfg.Defaults.MinVerifiedStats),
+			},
+			"max_candidates": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of candidate statistics to gather (default: %d)", cfg.Defaults.MaxCandidates),
+			},
+			"reputable_only": map[string]any{
+				"type":        "boolean",
+				"description": fmt.Sprintf("Only use reputable sources like government, academic, and research organizations (default: %t)", cfg.Defaults.ReputableOnly),
+			},
+			"permissive_license_only": map[string]any{
+				"type":        "boolean",
+				"description": "Only return statistics whose source declares a license allowing republication: CC0, CC BY, CC BY-SA, the Open Government Licence, or public domain",
+			},
+			"statistic_types": map[string]any{
+				"type":        "array",
+				"description": "Only return statistics of these kinds, and unclassified ones",
+				"items":       map[string]any{"type": "string", "enum": models.StatisticTypes},
+			},
+			"exclude_projections": map[string]any{
+				"type":        "boolean",
+				"description": "Leave out projections and forecasts, returning only statistics about the past",
+			},
+			"include_summary": map[string]any{
+				"type":        "boolean",
+				"description": "Also write a short summary paragraph of the verified statistics, with [n] markers citing them",
+			},
+			"reproducible": map[string]any{
+				"type":        "boolean",
+				"description": "Sample every LLM at temperature 0 with a fixed seed and store every intermediate artifact, so the run can be audited and replayed",
+			},
+			"seed": map[string]any{
+				"type":        "integer",
+				"description": "Seed for a reproducible run, sent to providers that accept one; implies reproducible (default: derived from the topic)",
+			},
+			"llm_provider": map[string]any{
+				"type":        "string",
+				"description": "LLM provider override for this run (gemini, claude, openai, xai, ollama, groq, mistral, deepseek); must be allowed by LLM_MODEL_ALLOWLIST",
+			},
+			"llm_model": map[string]any{
+				"type":        "string",
+				"description": "LLM model override for this run; must be allowed by LLM_MODEL_ALLOWLIST",
+			},
+		},
+		"required": []string{"topic"},
+	}
+}
+
+// LookupParameters returns the JSON Schema of lookup_statistics'
+// parameters, a search of the statistics store
+func LookupParameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Words to match against the statistics' names, excerpts, sources, and topics (e.g., 'electric car sales'); query or topic is required",
+			},
+			"topic": map[string]any{
+				"type":        "string",
+				"description": "Only statistics verified for a topic containing these words (e.g., 'electric vehicles')",
+			},
+			"published_after": map[string]any{
+				"type":        "string",
+				"description": "Only statistics whose source was published on or after this date (YYYY-MM-DD)",
+			},
+			"min_confidence": map[string]any{
+				"type":        "number",
+				"description": "Only statistics with at least this confidence, from 0 to 1, based on the source's domain tier and corroborating sources",
+			},
+			"limit": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of statistics to return (default: 10)",
+			},
+		},
+	}
+}
+
+// VerifyParameters returns the JSON Schema of verify_statistics' parameters,
+// a verification request
+func VerifyParameters() map[string]any {
+	str := func(description string) map[string]any {
+		return map[string]any{"type": "string", "description": description}
+	}
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"candidates": map[string]any{
+				"type":        "array",
+				"description": fmt.Sprintf("The statistics to verify, at most %d", models.MaxVerifyCandidates),
+				"minItems":    1,
+				"maxItems":    models.MaxVerifyCandidates,
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"name":       str("What the statistic measures"),
+						"value":      map[string]any{"type": "number", "description": "The numeric value, without its unit"},
+						"unit":       str("Unit of the value (e.g., '%', 'million', 'USD')"),
+						"source":     str("Name of the source organization or publication"),
+						"source_url": str("URL of the page stating the statistic"),
+						"excerpt":    str("Verbatim passage from the source containing the value"),
+					},
+					"required": []string{"name", "value", "source_url", "excerpt"},
+				},
+			},
+		},
+		"required": []string{"candidates"},
+	}
+}
+
+// Document returns the OpenAPI document of the tools, served from baseURL.
+// withAPIKey adds the tenant API key requirement.
+func Document(cfg *config.Config, baseURL string, withAPIKey bool) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:       "Statistics Agent Tools",
+			Description: "Find and verify statistics from reputable sources. Response schemas are published at /schemas.",
+			Version:     "1.0.0",
+		},
+		Servers: []Server{{URL: baseURL}},
+		Paths: map[string]PathItem{
+			"/orchestrate": {Post: operation(SearchStatistics, "Search for verified statistics on a topic",
+				SearchDescription, SearchParameters(cfg), map[string]Response{
+					"200": jsonResponse("Verified statistics with their sources (schema: /schemas/orchestration-response.json)"),
+					"202": jsonResponse("Queued; poll GET /jobs/{job_id} for the result (schema: /schemas/job.json)"),
+				})},
+			"/verify": {Post: operation(VerifyStatistics, "Verify statistics against their sources",
+				VerifyDescription, VerifyParameters(), map[string]Response{
+					"200": jsonResponse("A verdict per candidate (schema: /schemas/verification-response.json)"),
+				})},
+		},
+	}
+	if withAPIKey {
+		doc.Components = &Components{SecuritySchemes: map[string]SecurityScheme{
+			apiKeyScheme: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		}}
+		doc.Security = []map[string][]string{{apiKeyScheme: {}}}
+	}
+	return doc
+}
+
+func operation(name, summary, description string, parameters map[string]any, responses map[string]Response) *Operation {
+	responses["400"] = Response{Description: "Invalid parameters"}
+	responses["429"] = Response{Description: "Too many requests or tenant quota used up; retry later"}
+	return &Operation{
+		OperationID: name,
+		Summary:     summary,
+		Description: description,
+		RequestBody: RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: parameters}},
+		},
+		Responses: responses,
+	}
+}
+
+func jsonResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: map[string]any{"type": "object"}}},
+	}
+}
+
+// ToSpec derives the minimal tool spec from an OpenAPI document, one tool
+// per operation, ordered by name
+func (doc *OpenAPI) ToSpec() *Spec {
+	base := ""
+	if len(doc.Servers) > 0 {
+		base = doc.Servers[0].URL
+	}
+	spec := &Spec{Tools: []SpecTool{}, OpenAPIURL: base + OpenAPIPath}
+	for path, item := range doc.Paths {
+		if item.Post == nil {
+			continue
+		}
+		spec.Tools = append(spec.Tools, SpecTool{
+			Name:        item.Post.OperationID,
+			Description: item.Post.Description,
+			Method:      "POST",
+			URL:         base + path,
+			Parameters:  item.Post.RequestBody.Content["application/json"].Schema,
+		})
+	}
+	slices.SortFunc(spec.Tools, func(a, b SpecTool) int { return cmp.Compare(a.Name, b.Name) })
+	if doc.Components != nil {
+		if scheme, ok := doc.Components.SecuritySchemes[apiKeyScheme]; ok {
+			spec.APIKey = &APIKey{Header: scheme.Name}
+		}
+	}
+	return spec
+}
+
+// NewManifest returns the plugin manifest for the OpenAPI document at
+// baseURL. withAPIKey asks clients for a bearer token, which the tenant
+// middleware accepts as the API key.
+func NewManifest(baseURL string, withAPIKey bool) *Manifest {
+	m := &Manifest{
+		SchemaVersion:       "v1",
+		NameForHuman:        "Statistics Agent",
+		NameForModel:        "statistics_agent",
+		DescriptionForHuman: "Find and verify statistics from reputable sources.",
+		DescriptionForModel: "Use search_statistics to find verified statistics with sources and verbatim excerpts " +
+			"on a topic, and verify_statistics to check statistics against the pages they cite.",
+		Auth: ManifestAuth{Type: "none"},
+		API:  ManifestAPI{Type: "openapi", URL: baseURL + OpenAPIPath},
+	}
+	if withAPIKey {
+		m.Auth = ManifestAuth{Type: "service_http", AuthorizationType: "bearer"}
+	}
+	return m
+}
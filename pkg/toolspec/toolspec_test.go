@@ -0,0 +1,146 @@
+package toolspec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	akconfig "github.com/plexusone/agentkit/config"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+func testConfig() *config.Config {
+	cfg := &config.Config{Config: &akconfig.Config{}}
+	cfg.Defaults.MinVerifiedStats = 10
+	return cfg
+}
+
+func TestSpecDerivesToolsFromDocument(t *testing.T) {
+	spec := Document(testConfig(), "https://stats.example.com", true).ToSpec()
+
+	if len(spec.Tools) != 2 {
+		t.Fatalf("tools = %+v", spec.Tools)
+	}
+	search, verify := spec.Tools[0], spec.Tools[1]
+	if search.Name != SearchStatistics || search.URL != "https://stats.example.com/orchestrate" || search.Method != "POST" {
+		t.Errorf("search tool = %+v", search)
+	}
+	if verify.Name != VerifyStatistics || verify.URL != "https://stats.example.com/verify" {
+		t.Errorf("verify tool = %+v", verify)
+	}
+	if !strings.Contains(search.Parameters["properties"].(map[string]any)["min_verified_stats"].(map[string]any)["description"].(string), "default: 10") {
+		t.Errorf("search parameters do not carry the defaults: %v", search.Parameters)
+	}
+	if spec.OpenAPIURL != "https://stats.example.com/tools/openapi.json" {
+		t.Errorf("openapi_url = %q", spec.OpenAPIURL)
+	}
+	if spec.APIKey == nil || spec.APIKey.Header != "X-API-Key" {
+		t.Errorf("api_key = %+v", spec.APIKey)
+	}
+
+	if spec := Document(testConfig(), "http://localhost:8000", false).ToSpec(); spec.APIKey != nil {
+		t.Errorf("api_key without tenants = %+v", spec.APIKey)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := Handler(testConfig(), true, testLogger)
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://stats.example.com"+path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var doc OpenAPI
+	rec := get(OpenAPIPath, http.Header{"X-Forwarded-Proto": {"https"}})
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Servers[0].URL != "https://stats.example.com" {
+		t.Errorf("servers = %+v", doc.Servers)
+	}
+	if op := doc.Paths["/verify"].Post; op == nil || op.OperationID != VerifyStatistics {
+		t.Errorf("/verify operation = %+v", op)
+	}
+	if len(doc.Security) != 1 {
+		t.Errorf("security = %+v", doc.Security)
+	}
+
+	var manifest Manifest
+	rec = get(ManifestPath, nil)
+	if err := json.NewDecoder(rec.Body).Decode(&manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.API.URL != "http://stats.example.com/tools/openapi.json" || manifest.Auth.Type != "service_http" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	var spec Spec
+	rec = get(SpecPath, nil)
+	if err := json.NewDecoder(rec.Body).Decode(&spec); err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Tools) != 2 {
+		t.Errorf("spec tools = %+v", spec.Tools)
+	}
+
+	if rec := get("/tools/other.json", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown document = %d; want 404", rec.Code)
+	}
+}
+
+func TestVerifyHandler(t *testing.T) {
+	var got *models.VerificationRequest
+	verify := func(_ context.Context, req *models.VerificationRequest) (*models.VerificationResponse, error) {
+		got = req
+		if req.Candidates[0].SourceURL == "" {
+			return nil, errors.New("verification agent unavailable")
+		}
+		return &models.VerificationResponse{Verified: 1}, nil
+	}
+	handler := VerifyHandler(testConfig(), verify, testLogger)
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(body)))
+		return rec
+	}
+
+	// A caller cannot have its URL fetched with the site credentials
+	rec := post(`{"candidates":[{"name":"Adults using social media","value":48,"unit":"%","source_url":"https://example.com","excerpt":"48% of adults","internal":true}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /verify = %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.VerificationResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Verified != 1 || got == nil || got.Candidates[0].Name != "Adults using social media" || got.Candidates[0].Internal {
+		t.Errorf("response = %+v, request = %+v", resp, got)
+	}
+
+	if rec := post(`{"candidates":[]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("no candidates = %d; want 400", rec.Code)
+	}
+	candidate := `{"name":"x","value":1,"source_url":"https://example.com","excerpt":"1"}`
+	tooMany := `{"candidates":[` + strings.Repeat(candidate+",", models.MaxVerifyCandidates) + candidate + `]}`
+	got = nil
+	if rec := post(tooMany); rec.Code != http.StatusBadRequest || got != nil {
+		t.Errorf("%d candidates = %d, verified: %t; want 400 without verifying", models.MaxVerifyCandidates+1, rec.Code, got != nil)
+	}
+	if rec := post(`{"candidates":[{"name":"x","value":1,"excerpt":"1"}]}`); rec.Code != http.StatusBadGateway {
+		t.Errorf("failed verification = %d; want 502", rec.Code)
+	}
+}
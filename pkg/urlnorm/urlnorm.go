@@ -0,0 +1,252 @@
+// Package urlnorm normalizes and canonicalizes source URLs so search results
+// pointing at the same article (tracking-parameter variants, AMP pages,
+// pages declaring a canonical link on their own site) can be recognized as
+// duplicates.
+package urlnorm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+// maxHeadBytes bounds how much of a page is read looking for a canonical link
+const maxHeadBytes = 256 << 10
+
+// trackingParams are query parameters that never change the page content
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"mc_cid": true, "mc_eid": true, "igshid": true, "_ga": true, "_gl": true,
+	"ref_src": true, "cmpid": true, "ncid": true, "ocid": true,
+	"amp": true, "outputtype": true,
+}
+
+// Normalize returns a normalized form of an http(s) URL: lowercase scheme and
+// host, no "www."/"m."/"amp." host prefix, no default port, fragment, tracking
+// parameters, AMP path markers, or trailing slash, and sorted query
+// parameters. It is meant for comparing URLs, not for fetching them.
+func Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported URL scheme: %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("URL has no host: %q", raw)
+	}
+
+	// Both schemes are treated alike; the same article is often indexed under each
+	u.Scheme = "https"
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "amp."} {
+		if rest, ok := strings.CutPrefix(host, prefix); ok && strings.Contains(rest, ".") {
+			host = rest
+		}
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	u.Path = stripAMPPath(u.Path)
+	u.RawPath = ""
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = encodeSorted(query)
+
+	return u.String(), nil
+}
+
+// stripAMPPath removes AMP markers such as /amp, /amp/, and .amp from a path
+// and drops a trailing slash
+func stripAMPPath(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	switch {
+	case strings.HasSuffix(path, "/amp"):
+		path = strings.TrimSuffix(path, "/amp")
+	case strings.HasSuffix(path, ".amp"):
+		path = strings.TrimSuffix(path, ".amp")
+	case strings.HasSuffix(path, ".amp.html"):
+		path = strings.TrimSuffix(path, ".amp.html") + ".html"
+	case strings.HasPrefix(path, "/amp/"):
+		path = strings.TrimPrefix(path, "/amp")
+	}
+	return strings.TrimSuffix(path, "/")
+}
+
+// encodeSorted encodes query parameters in key order
+func encodeSorted(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range query[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k))
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(v))
+		}
+	}
+	return b.String()
+}
+
+// CanonicalLink returns the href of the page's <link rel="canonical">,
+// resolved against base, or "" if the page declares none
+func CanonicalLink(body []byte, base *url.URL) string {
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.EndTagToken:
+			if name, _ := z.TagName(); atom.Lookup(name) == atom.Head {
+				return ""
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if atom.Lookup(name) != atom.Link || !hasAttr {
+				continue
+			}
+			var rel, href string
+			for more := true; more; {
+				var key, val []byte
+				key, val, more = z.TagAttr()
+				switch string(key) {
+				case "rel":
+					rel = strings.ToLower(string(val))
+				case "href":
+					href = strings.TrimSpace(string(val))
+				}
+			}
+			if href == "" || !hasToken(rel, "canonical") {
+				continue
+			}
+			ref, err := url.Parse(href)
+			if err != nil {
+				return ""
+			}
+			if base != nil {
+				ref = base.ResolveReference(ref)
+			}
+			return ref.String()
+		}
+	}
+}
+
+// hasToken reports whether a space-separated attribute contains token
+func hasToken(attr, token string) bool {
+	for _, f := range strings.Fields(attr) {
+		if f == token {
+			return true
+		}
+	}
+	return false
+}
+
+// sameSite reports whether two URLs are on the same registrable domain
+// (www.census.gov and data.census.gov, but not census.gov.example.com). A
+// page can declare any URL canonical, so only a link on the page's own site
+// is trusted to name the same article. IP addresses and hosts without a
+// public suffix must match exactly.
+func sameSite(a, b *url.URL) bool {
+	ha, hb := strings.ToLower(a.Hostname()), strings.ToLower(b.Hostname())
+	if ha == hb {
+		return true
+	}
+	if net.ParseIP(ha) != nil || net.ParseIP(hb) != nil {
+		return false
+	}
+	da, errA := publicsuffix.EffectiveTLDPlusOne(ha)
+	db, errB := publicsuffix.EffectiveTLDPlusOne(hb)
+	return errA == nil && errB == nil && da == db
+}
+
+// Resolver finds the canonical URL of a page by following redirects and
+// reading its canonical link when it is on the same site
+type Resolver struct {
+	client   *http.Client
+	identity *httpclient.Identity
+}
+
+// NewResolver creates a resolver using client for fetches, which present
+// identity to sites
+func NewResolver(client *http.Client, identity *httpclient.Identity) *Resolver {
+	return &Resolver{client: client, identity: identity}
+}
+
+// Resolve returns the canonical URL of raw. On any fetch failure the
+// original URL is returned along with the error, so callers can fall back to
+// normalization alone.
+func (r *Resolver) Resolve(ctx context.Context, raw string) (string, error) {
+	canonical, _, err := r.ResolveHead(ctx, raw)
+	return canonical, err
+}
+
+// ResolveHead is Resolve that also returns the start of an HTML page, up to
+// maxHeadBytes, for reading other metadata without a second fetch. The head
+// is nil when the page is not HTML or could not be read.
+func (r *Resolver) ResolveHead(ctx context.Context, raw string) (string, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, raw, nil)
+	if err != nil {
+		return raw, nil, err
+	}
+	r.identity.Apply(req)
+
+	resp, err := r.client.Do(req) //nolint:gosec // G704: URL comes from search results
+	if err != nil {
+		return raw, nil, err
+	}
+	defer resp.Body.Close()
+
+	final := resp.Request.URL // After redirects
+	if resp.StatusCode != http.StatusOK {
+		return final.String(), nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		return final.String(), nil, nil
+	}
+
+	head, err := io.ReadAll(io.LimitReader(resp.Body, maxHeadBytes))
+	if err != nil {
+		return final.String(), nil, err
+	}
+	if canonical := CanonicalLink(head, final); canonical != "" {
+		if u, err := url.Parse(canonical); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && sameSite(u, final) {
+			return canonical, head, nil
+		}
+	}
+	return final.String(), head, nil
+}
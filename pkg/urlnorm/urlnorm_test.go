@@ -0,0 +1,114 @@
+package urlnorm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"https://www.example.com/report/", "http://example.com/report"},
+		{"https://example.com/report?utm_source=x&utm_medium=y&id=3", "https://example.com/report?id=3"},
+		{"https://example.com/report?b=2&a=1", "https://example.com/report?a=1&b=2"},
+		{"https://example.com/news/story/amp", "https://example.com/news/story"},
+		{"https://example.com/news/story.amp", "https://example.com/news/story"},
+		{"https://example.com/amp/news/story", "https://example.com/news/story"},
+		{"https://amp.example.com/news/story?amp=1", "https://example.com/news/story"},
+		{"https://m.example.com/page#section", "https://example.com/page"},
+		{"https://EXAMPLE.com:443/Page?fbclid=abc", "https://example.com/Page"},
+	}
+	for _, tt := range tests {
+		a, err := Normalize(tt.a)
+		if err != nil {
+			t.Fatalf("Normalize(%q): %v", tt.a, err)
+		}
+		b, err := Normalize(tt.b)
+		if err != nil {
+			t.Fatalf("Normalize(%q): %v", tt.b, err)
+		}
+		if a != b {
+			t.Errorf("Normalize(%q) = %q, Normalize(%q) = %q; want equal", tt.a, a, tt.b, b)
+		}
+	}
+
+	// Meaningful differences are kept
+	a, _ := Normalize("https://example.com/report?id=3")
+	b, _ := Normalize("https://example.com/report?id=4")
+	if a == b {
+		t.Errorf("distinct query values normalized to the same URL %q", a)
+	}
+	if _, err := Normalize("ftp://example.com/file"); err == nil {
+		t.Error("expected error for non-http scheme")
+	}
+}
+
+func TestCanonicalLink(t *testing.T) {
+	base, _ := url.Parse("https://mirror.example.org/articles/123")
+	body := []byte(`<html><head><title>x</title>
+<link rel="stylesheet" href="/style.css">
+<link rel="canonical" href="https://news.example.com/2024/report">
+</head><body><link rel="canonical" href="https://wrong.example.com"></body></html>`)
+
+	if got := CanonicalLink(body, base); got != "https://news.example.com/2024/report" {
+		t.Errorf("CanonicalLink = %q", got)
+	}
+
+	relative := []byte(`<head><link href="/original" rel="Canonical"></head>`)
+	if got := CanonicalLink(relative, base); got != "https://mirror.example.org/original" {
+		t.Errorf("CanonicalLink (relative) = %q", got)
+	}
+
+	bodyOnly := []byte(`<head></head><body><link rel="canonical" href="https://wrong.example.com"></body>`)
+	if got := CanonicalLink(bodyOnly, base); got != "" {
+		t.Errorf("CanonicalLink outside head = %q, want empty", got)
+	}
+}
+
+func TestSameSite(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://www.census.gov/data", "https://data.census.gov/table", true},
+		{"https://news.example.com/a", "https://example.com/b", true},
+		{"https://example.com/a", "https://example.com.evil.io/b", false},
+		{"https://mirror.example.org/a", "https://news.example.com/b", false},
+		{"https://alice.github.io/a", "https://bob.github.io/b", false}, // Public suffix
+		{"https://www.bbc.co.uk/a", "https://news.bbc.co.uk/b", true},
+		{"http://127.0.0.1:8080/a", "http://127.0.0.1:9090/b", true},
+		{"http://127.0.0.1/a", "http://10.0.0.1/b", false},
+	}
+	for _, tt := range tests {
+		a, _ := url.Parse(tt.a)
+		b, _ := url.Parse(tt.b)
+		if got := sameSite(a, b); got != tt.want {
+			t.Errorf("sameSite(%s, %s) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestResolveHeadIgnoresOffSiteCanonical(t *testing.T) {
+	var canonical string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><head><link rel="canonical" href="%s"></head></html>`, canonical)
+	}))
+	defer srv.Close()
+	resolver := NewResolver(srv.Client(), nil)
+
+	canonical = srv.URL + "/original"
+	if got, err := resolver.Resolve(context.Background(), srv.URL+"/copy"); err != nil || got != canonical {
+		t.Errorf("Resolve(same site) = %q, %v; want %q", got, err, canonical)
+	}
+
+	canonical = "https://www.census.gov/original"
+	if got, err := resolver.Resolve(context.Background(), srv.URL+"/copy"); err != nil || got != srv.URL+"/copy" {
+		t.Errorf("Resolve(off-site canonical) = %q, %v; want the page's own URL", got, err)
+	}
+}
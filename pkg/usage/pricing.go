@@ -0,0 +1,86 @@
+package usage
+
+import "strings"
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// prices lists known model prices, matched by longest model-name prefix so
+// dated snapshots (e.g. "claude-3-5-sonnet-20241022") share an entry.
+// Ollama models run locally and are always free.
+var prices = map[string]Price{
+	// Gemini
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10.00},
+	"gemini-2.5-flash":      {Input: 0.30, Output: 2.50},
+	"gemini-2.5-flash-lite": {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash-lite": {Input: 0.075, Output: 0.30},
+	"gemini-1.5-pro":        {Input: 1.25, Output: 5.00},
+	"gemini-1.5-flash":      {Input: 0.075, Output: 0.30},
+
+	// Claude
+	"claude-opus-4":     {Input: 15.00, Output: 75.00},
+	"claude-sonnet-4":   {Input: 3.00, Output: 15.00},
+	"claude-3-7-sonnet": {Input: 3.00, Output: 15.00},
+	"claude-3-5-sonnet": {Input: 3.00, Output: 15.00},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4.00},
+	"claude-3-opus":     {Input: 15.00, Output: 75.00},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+
+	// OpenAI
+	"gpt-4o":       {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+	"o3-mini":      {Input: 1.10, Output: 4.40},
+
+	// xAI
+	"grok-3":      {Input: 3.00, Output: 15.00},
+	"grok-3-mini": {Input: 0.30, Output: 0.50},
+	"grok-4":      {Input: 3.00, Output: 15.00},
+
+	// Groq
+	"llama-3.3-70b-versatile": {Input: 0.59, Output: 0.79},
+	"llama-3.1-8b-instant":    {Input: 0.05, Output: 0.08},
+
+	// Mistral
+	"mistral-large":  {Input: 2.00, Output: 6.00},
+	"mistral-medium": {Input: 0.40, Output: 2.00},
+	"mistral-small":  {Input: 0.10, Output: 0.30},
+
+	// DeepSeek
+	"deepseek-chat":     {Input: 0.27, Output: 1.10},
+	"deepseek-reasoner": {Input: 0.55, Output: 2.19},
+}
+
+// Lookup returns the price of a model. The second result is false if the
+// model has no known price.
+func Lookup(provider, modelName string) (Price, bool) {
+	if provider == "ollama" {
+		return Price{}, true
+	}
+	best := ""
+	for prefix := range prices {
+		if strings.HasPrefix(modelName, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return prices[best], true
+}
+
+// Cost returns the estimated cost in USD of a call. The second result is
+// false if the model has no known price, in which case the cost is zero.
+func Cost(provider, modelName string, promptTokens, completionTokens int) (float64, bool) {
+	p, ok := Lookup(provider, modelName)
+	if !ok {
+		return 0, false
+	}
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1e6, true
+}
@@ -0,0 +1,216 @@
+// Package usage records LLM token usage and estimated cost per call and
+// aggregates it into the cost summaries reported by the agents.
+package usage
+
+import (
+	"context"
+	"iter"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+// Usage is the token usage of a single LLM call
+type Usage struct {
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	CostUSD          float64
+	Priced           bool   // False if the model has no known price
+	Version          string // Exact model version the provider reported, if any
+}
+
+// Callback receives the usage of every LLM call made through a wrapped model
+type Callback func(ctx context.Context, u Usage)
+
+// Tracker aggregates usage per provider and model. It is safe for
+// concurrent use.
+type Tracker struct {
+	stage string
+
+	mu       sync.Mutex
+	byModel  map[string]*models.ModelUsage
+	order    []string
+	searches int
+}
+
+// NewTracker creates a tracker labeling its usage with a pipeline stage
+func NewTracker(stage string) *Tracker {
+	return &Tracker{stage: stage, byModel: make(map[string]*models.ModelUsage)}
+}
+
+// Add records the usage of one call. Adding to a nil tracker is a no-op.
+func (t *Tracker) Add(u Usage) {
+	if t == nil {
+		return
+	}
+	t.merge(models.ModelUsage{
+		Provider:         u.Provider,
+		Model:            u.Model,
+		Stage:            t.stage,
+		Calls:            1,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		EstimatedCostUSD: u.CostUSD,
+		Unpriced:         !u.Priced,
+		Versions:         versions(u.Version),
+	})
+}
+
+// AddSearches records web searches run for the request. Adding to a nil
+// tracker is a no-op.
+func (t *Tracker) AddSearches(n int) {
+	if t == nil || n == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.searches += n
+}
+
+// Merge adds a summary reported by another agent, keeping its stage labels
+func (t *Tracker) Merge(s *models.CostSummary) {
+	if t == nil || s == nil {
+		return
+	}
+	for _, mu := range s.ByModel {
+		t.merge(mu)
+	}
+	t.AddSearches(s.SearchCalls)
+}
+
+func (t *Tracker) merge(u models.ModelUsage) {
+	key := u.Stage + "|" + u.Provider + "|" + u.Model
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.byModel[key]
+	if !ok {
+		entry = &models.ModelUsage{Provider: u.Provider, Model: u.Model, Stage: u.Stage}
+		t.byModel[key] = entry
+		t.order = append(t.order, key)
+	}
+	entry.Calls += u.Calls
+	entry.PromptTokens += u.PromptTokens
+	entry.CompletionTokens += u.CompletionTokens
+	entry.EstimatedCostUSD += u.EstimatedCostUSD
+	entry.Unpriced = entry.Unpriced || u.Unpriced
+	for _, v := range u.Versions {
+		if !slices.Contains(entry.Versions, v) {
+			entry.Versions = append(entry.Versions, v)
+		}
+	}
+}
+
+// versions returns the version of a call as a list, empty when unknown
+func versions(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return []string{v}
+}
+
+// Summary returns the aggregated usage, or nil if nothing was recorded
+func (t *Tracker) Summary() *models.CostSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.order) == 0 && t.searches == 0 {
+		return nil
+	}
+
+	summary := &models.CostSummary{SearchCalls: t.searches, ByModel: make([]models.ModelUsage, 0, len(t.order))}
+	for _, key := range t.order {
+		entry := *t.byModel[key]
+		entry.Versions = slices.Clone(entry.Versions)
+		entry.EstimatedCostUSD = roundCost(entry.EstimatedCostUSD)
+		summary.Calls += entry.Calls
+		summary.PromptTokens += entry.PromptTokens
+		summary.CompletionTokens += entry.CompletionTokens
+		summary.EstimatedCostUSD += entry.EstimatedCostUSD
+		summary.Unpriced = summary.Unpriced || entry.Unpriced
+		summary.ByModel = append(summary.ByModel, entry)
+	}
+	summary.TotalTokens = summary.PromptTokens + summary.CompletionTokens
+	summary.EstimatedCostUSD = roundCost(summary.EstimatedCostUSD)
+	sort.SliceStable(summary.ByModel, func(i, j int) bool {
+		return summary.ByModel[i].EstimatedCostUSD > summary.ByModel[j].EstimatedCostUSD
+	})
+	return summary
+}
+
+// roundCost rounds to a millionth of a dollar to keep JSON output readable
+func roundCost(c float64) float64 {
+	return math.Round(c*1e6) / 1e6
+}
+
+// trackerKey is the context key for the usage tracker of a request
+type trackerKey struct{}
+
+// WithTracker returns a context whose LLM calls are recorded in t
+func WithTracker(ctx context.Context, t *Tracker) context.Context {
+	return context.WithValue(ctx, trackerKey{}, t)
+}
+
+// FromContext returns the tracker carried by ctx, or nil if none is set
+func FromContext(ctx context.Context) *Tracker {
+	t, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return t
+}
+
+// Record is the default Callback: it adds the usage to the tracker carried
+// by ctx, if any
+func Record(ctx context.Context, u Usage) {
+	FromContext(ctx).Add(u)
+}
+
+// trackedModel wraps a model.LLM and reports the usage of every response
+type trackedModel struct {
+	model.LLM
+	provider  string
+	modelName string
+	callback  Callback
+}
+
+// Wrap returns a model that reports the token usage of every call to
+// callback. Usage is read from the responses' UsageMetadata, which the Gemini
+// model and the OmniLLM adapter both fill in.
+func Wrap(m model.LLM, provider, modelName string, callback Callback) model.LLM {
+	if callback == nil {
+		callback = Record
+	}
+	return &trackedModel{LLM: m, provider: provider, modelName: modelName, callback: callback}
+}
+
+// GenerateContent implements model.LLM
+func (m *trackedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for resp, err := range m.LLM.GenerateContent(ctx, req, stream) {
+			// Streaming responses carry the call's usage on the final, non-partial chunk
+			if err == nil && resp != nil && resp.UsageMetadata != nil && !resp.Partial {
+				m.report(ctx, resp.UsageMetadata, resp.ModelVersion)
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+// report converts usage metadata into a Usage and passes it to the callback
+func (m *trackedModel) report(ctx context.Context, md *genai.GenerateContentResponseUsageMetadata, version string) {
+	u := Usage{
+		Provider:         m.provider,
+		Model:            m.modelName,
+		PromptTokens:     int(md.PromptTokenCount),
+		CompletionTokens: int(md.CandidatesTokenCount),
+		Version:          version,
+	}
+	u.CostUSD, u.Priced = Cost(u.Provider, u.Model, u.PromptTokens, u.CompletionTokens)
+	m.callback(ctx, u)
+}
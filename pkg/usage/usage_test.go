@@ -0,0 +1,112 @@
+package usage
+
+import (
+	"context"
+	"iter"
+	"math"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+)
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		provider, model string
+		want            float64
+		priced          bool
+	}{
+		{"openai", "gpt-4o-mini", 0.15 + 0.60, true},
+		{"openai", "gpt-4o", 2.50 + 10.00, true},
+		{"claude", "claude-3-5-sonnet-20241022", 3.00 + 15.00, true},
+		{"ollama", "llama3.2", 0, true},
+		{"openai", "unknown-model", 0, false},
+	}
+	for _, tt := range tests {
+		got, priced := Cost(tt.provider, tt.model, 1_000_000, 1_000_000)
+		if math.Abs(got-tt.want) > 1e-9 || priced != tt.priced {
+			t.Errorf("Cost(%s, %s) = %v, %v; want %v, %v", tt.provider, tt.model, got, priced, tt.want, tt.priced)
+		}
+	}
+}
+
+// fakeModel returns one response with fixed usage metadata
+type fakeModel struct{}
+
+func (fakeModel) Name() string { return "fake" }
+
+func (fakeModel) GenerateContent(context.Context, *model.LLMRequest, bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		yield(&model.LLMResponse{
+			Content: genai.NewContentFromText("ok", genai.RoleModel),
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:     1000,
+				CandidatesTokenCount: 200,
+			},
+			ModelVersion: "gpt-4o-mini-2024-07-18",
+		}, nil)
+	}
+}
+
+func TestWrapRecordsIntoTracker(t *testing.T) {
+	m := Wrap(fakeModel{}, "openai", "gpt-4o-mini", Record)
+	tracker := NewTracker("synthesis")
+	ctx := WithTracker(context.Background(), tracker)
+
+	for range 3 {
+		for _, err := range m.GenerateContent(ctx, &model.LLMRequest{}, false) {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// Calls without a tracker are not recorded
+	for range m.GenerateContent(context.Background(), &model.LLMRequest{}, false) {
+	}
+
+	s := tracker.Summary()
+	if s.Calls != 3 || s.PromptTokens != 3000 || s.CompletionTokens != 600 || s.TotalTokens != 3600 {
+		t.Errorf("summary = %+v", s)
+	}
+	if want := 0.000810; math.Abs(s.EstimatedCostUSD-want) > 1e-9 {
+		t.Errorf("cost = %v, want %v", s.EstimatedCostUSD, want)
+	}
+	if len(s.ByModel) != 1 || s.ByModel[0].Stage != "synthesis" || s.ByModel[0].Model != "gpt-4o-mini" {
+		t.Errorf("by_model = %+v", s.ByModel)
+	}
+	if v := s.ByModel[0].Versions; len(v) != 1 || v[0] != "gpt-4o-mini-2024-07-18" {
+		t.Errorf("versions = %v, want the reported version once", v)
+	}
+}
+
+func TestTrackerMerge(t *testing.T) {
+	synth := NewTracker("synthesis")
+	synth.Add(Usage{Provider: "gemini", Model: "gemini-2.0-flash", PromptTokens: 100, CompletionTokens: 10, CostUSD: 0.001, Priced: true})
+	verify := NewTracker("verification")
+	verify.Add(Usage{Provider: "custom", Model: "in-house", PromptTokens: 50, CompletionTokens: 5})
+
+	run := NewTracker("planning")
+	run.Merge(synth.Summary())
+	run.Merge(verify.Summary())
+	run.Merge(nil)
+
+	s := run.Summary()
+	if s.Calls != 2 || s.TotalTokens != 165 || !s.Unpriced || len(s.ByModel) != 2 {
+		t.Errorf("summary = %+v", s)
+	}
+	if s.ByModel[0].Stage != "synthesis" {
+		t.Errorf("expected costliest stage first, got %+v", s.ByModel)
+	}
+	if NewTracker("").Summary() != nil {
+		t.Error("expected nil summary for empty tracker")
+	}
+
+	searches := NewTracker("planning")
+	searches.AddSearches(2)
+	searches.Merge(&models.CostSummary{SearchCalls: 3})
+	if s := searches.Summary(); s == nil || s.SearchCalls != 5 {
+		t.Errorf("search-only summary = %+v", s)
+	}
+}
@@ -0,0 +1,304 @@
+// Statistics Agent web UI. Talks to the orchestrator's HTTP API from the
+// browser: POST /orchestrate (polling /jobs/{id} in queue mode), the
+// /runs/events progress stream, and /export and /reports for downloads.
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+const keyStorage = "stats-agent-api-key";
+const jobPollMs = 2000;
+const reconnectMs = 3000;
+
+let lastResponse = null;
+let stream = null; // AbortController of the progress stream
+const runs = new Map();
+
+// el creates an element with attributes and children; strings become text
+// nodes, so API data is never parsed as HTML
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    node.setAttribute(name, value);
+  }
+  for (const child of children) {
+    if (child !== null && child !== undefined) {
+      node.append(child instanceof Node ? child : String(child));
+    }
+  }
+  return node;
+}
+
+// sourceLink links to a source, showing plain text for non-web URLs
+function sourceLink(url, text) {
+  if (!/^https?:\/\//i.test(url || "")) {
+    return el("span", {}, text || url || "");
+  }
+  return el("a", { href: url, target: "_blank", rel: "noopener noreferrer" }, text || url);
+}
+
+function formatValue(value, unit) {
+  if (!unit) return String(value);
+  return unit === "%" ? `${value}%` : `${value} ${unit}`;
+}
+
+function elapsed(from) {
+  const s = Math.max(0, Math.round((Date.now() - new Date(from).getTime()) / 1000));
+  return s < 60 ? `${s}s` : `${Math.floor(s / 60)}m${String(s % 60).padStart(2, "0")}s`;
+}
+
+function setStatus(text, isError) {
+  const status = $("request-status");
+  status.textContent = text;
+  status.classList.toggle("error", Boolean(isError));
+}
+
+function headers(extra) {
+  const h = Object.assign({}, extra);
+  const key = $("api-key").value.trim();
+  if (key) h["X-API-Key"] = key;
+  return h;
+}
+
+async function api(method, path, body) {
+  const resp = await fetch(path, {
+    method,
+    headers: headers(body === undefined ? {} : { "Content-Type": "application/json" }),
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (!resp.ok && resp.status !== 202) {
+    const text = (await resp.text()).trim();
+    throw new Error(`${resp.status} ${resp.statusText}${text ? ": " + text : ""}`);
+  }
+  return resp;
+}
+
+const sleep = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+// Searching
+
+function buildRequest() {
+  const req = { topic: $("topic").value.trim() };
+  const minStats = parseInt($("min-stats").value, 10);
+  const maxCandidates = parseInt($("max-candidates").value, 10);
+  if (minStats > 0) req.min_verified_stats = minStats;
+  if (maxCandidates > 0) req.max_candidates = maxCandidates;
+  if ($("reputable-only").checked) req.reputable_only = true;
+  if ($("dry-run").checked) req.dry_run = true;
+  const compare = $("compare").value.split(",").map((s) => s.trim()).filter(Boolean);
+  if (compare.length > 0) req.compare = compare;
+  return req;
+}
+
+async function search(event) {
+  event.preventDefault();
+  const req = buildRequest();
+  $("submit").disabled = true;
+  setStatus(req.dry_run ? "Planning…" : "Searching… progress appears under Active runs.");
+  try {
+    const resp = await api("POST", "/orchestrate", req);
+    let result = await resp.json();
+    if (resp.status === 202) {
+      result = await waitForJob(result.job_id);
+    }
+    showResults(result);
+    setStatus("");
+  } catch (err) {
+    setStatus(`Search failed: ${err.message}`, true);
+  } finally {
+    $("submit").disabled = false;
+  }
+}
+
+// waitForJob polls a queued job until it finishes
+async function waitForJob(id) {
+  for (;;) {
+    const job = await (await api("GET", `/jobs/${encodeURIComponent(id)}`)).json();
+    if (job.status === "succeeded") return job.response;
+    if (job.status === "failed") throw new Error(job.error || "job failed");
+    setStatus(`Job ${id} is ${job.status}…`);
+    await sleep(jobPollMs);
+  }
+}
+
+// Results
+
+function showResults(resp) {
+  lastResponse = resp;
+  $("results").classList.remove("hidden");
+
+  const parts = [`${resp.verified_count} verified of ${resp.total_candidates} candidates for “${resp.topic}”`];
+  if (resp.status) parts.push(`status: ${resp.status.replace("_", " ")}`);
+  if (resp.query) parts.push(`search relaxed to “${resp.query}”`);
+  if (resp.cost_summary) {
+    parts.push(`~$${resp.cost_summary.estimated_cost_usd.toFixed(4)} LLM cost`);
+    if (resp.cost_summary.search_calls) parts.push(`${resp.cost_summary.search_calls} searches`);
+  }
+  $("summary").textContent = parts.join(" · ");
+
+  showPlan(resp.plan);
+
+  const list = $("statistics");
+  list.replaceChildren(...(resp.statistics || []).map(statisticItem));
+
+  const rejected = resp.rejected || [];
+  $("rejected-section").classList.toggle("hidden", rejected.length === 0);
+  $("rejected-summary").textContent = `${rejected.length} candidates failed verification`;
+  $("rejected").tBodies[0].replaceChildren(...rejected.map((r) => el("tr", {},
+    el("td", {}, r.statistic ? `${r.statistic.name}: ${formatValue(r.statistic.value, r.statistic.unit)}` : ""),
+    el("td", {}, r.statistic ? sourceLink(r.statistic.source_url, r.statistic.source) : ""),
+    el("td", {}, r.category || ""),
+    el("td", {}, r.reason || ""))));
+}
+
+function statisticItem(stat) {
+  const evidence = el("dl", { class: "evidence" },
+    el("dt", {}, "Excerpt"), el("dd", {}, el("blockquote", {}, stat.excerpt)),
+    el("dt", {}, "Source"), el("dd", {}, sourceLink(stat.source_url, stat.source)));
+  if (stat.provenance) {
+    const p = stat.provenance;
+    evidence.append(el("dt", {}, "Location"),
+      el("dd", {}, [p.format.toUpperCase(), p.sheet, `row ${p.row}`, p.column && `column “${p.column}”`].filter(Boolean).join(", ")));
+  }
+  if (stat.content_hash) {
+    evidence.append(el("dt", {}, "Content hash"), el("dd", {}, el("code", {}, stat.content_hash)));
+  }
+  for (const c of stat.corroborated_by || []) {
+    evidence.append(el("dt", {}, "Corroborated by"),
+      el("dd", {}, sourceLink(c.source_url, c.source), ` — ${c.name} (similarity ${c.similarity.toFixed(2)})`));
+  }
+
+  return el("li", {},
+    el("span", { class: "value" }, formatValue(stat.value, stat.unit)), " ",
+    stat.name, " ",
+    stat.verified ? el("span", { class: "verified", title: "Verified in source" }, "✓") : null,
+    el("details", {}, el("summary", {}, "Evidence"), evidence));
+}
+
+function showPlan(plan) {
+  const node = $("plan");
+  node.classList.toggle("hidden", !plan);
+  if (!plan) {
+    node.replaceChildren();
+    return;
+  }
+  const usage = plan.estimated_usage;
+  const lines = [el("p", {}, `Search provider: ${plan.search_provider}`)];
+  if ((plan.adapters || []).length > 0) {
+    lines.push(el("p", {}, `Extraction adapters: ${plan.adapters.join(", ")}`));
+  }
+  if (usage) {
+    for (const m of usage.by_model || []) {
+      lines.push(el("p", {}, `LLM (${m.stage}): ${m.provider}/${m.model}, ${m.calls} calls, ~${m.prompt_tokens + m.completion_tokens} tokens`));
+    }
+    lines.push(el("p", {}, `Estimated cost per pass: ~$${usage.estimated_cost_usd.toFixed(4)}, up to ${plan.max_passes} passes`));
+  }
+  lines.push(el("ol", {}, ...(plan.sources || []).map((s) =>
+    el("li", {}, sourceLink(s.url, s.title || s.url), ` (${s.adapter ? "adapter: " + s.adapter : s.extraction})`))));
+  node.replaceChildren(...lines);
+}
+
+// Exports
+
+function download(blob, name) {
+  const url = URL.createObjectURL(blob);
+  const a = el("a", { href: url, download: name });
+  document.body.append(a);
+  a.click();
+  a.remove();
+  setTimeout(() => URL.revokeObjectURL(url), 1000);
+}
+
+const extensions = { "csl-json": "json", bibtex: "bib", apa: "txt", mla: "txt", csv: "csv", html: "html", markdown: "md" };
+
+async function exportResults(event) {
+  const button = event.target.closest("button");
+  if (!button || !lastResponse) return;
+  const slug = lastResponse.topic.toLowerCase().replace(/[^a-z0-9]+/g, "-").replace(/^-|-$/g, "") || "statistics";
+  try {
+    if (button.dataset.export === "json") {
+      download(new Blob([JSON.stringify(lastResponse, null, 2)], { type: "application/json" }), `${slug}.json`);
+      return;
+    }
+    const [path, format] = button.dataset.report
+      ? ["/reports", button.dataset.report]
+      : ["/export", button.dataset.export];
+    const resp = await api("POST", `${path}?format=${encodeURIComponent(format)}`, lastResponse);
+    download(await resp.blob(), `${slug}.${extensions[format]}`);
+  } catch (err) {
+    setStatus(`Export failed: ${err.message}`, true);
+  }
+}
+
+// Run progress
+
+function renderRuns() {
+  const active = [...runs.values()].sort((a, b) => new Date(a.started_at) - new Date(b.started_at));
+  $("runs").classList.toggle("hidden", active.length === 0);
+  $("no-runs").classList.toggle("hidden", active.length > 0);
+  $("runs").tBodies[0].replaceChildren(...active.map((run) => el("tr", {},
+    el("td", {}, run.topic),
+    el("td", {}, run.stage || "starting"),
+    el("td", {}, run.pass),
+    el("td", {}, run.candidates),
+    el("td", {}, `${run.verified}/${run.target}`),
+    el("td", {}, run.failed),
+    el("td", {}, elapsed(run.started_at)))));
+}
+
+function applyEvent(ev) {
+  if (ev.type === "ready") {
+    $("stream-status").textContent = "(live)";
+  } else if (ev.type === "finished") {
+    runs.delete(ev.run.run_id);
+  } else {
+    runs.set(ev.run.run_id, ev.run);
+  }
+  renderRuns();
+}
+
+// watchRuns reads the progress stream with fetch rather than EventSource,
+// which cannot send the API key header, reconnecting when it drops
+async function watchRuns() {
+  for (;;) {
+    try {
+      stream = new AbortController();
+      const resp = await fetch("/runs/events", {
+        headers: headers({ Accept: "text/event-stream" }),
+        signal: stream.signal,
+      });
+      if (!resp.ok) throw new Error(`${resp.status} ${resp.statusText}`);
+      runs.clear();
+      const reader = resp.body.pipeThrough(new TextDecoderStream()).getReader();
+      let buffer = "";
+      for (;;) {
+        const { value, done } = await reader.read();
+        if (done) break;
+        buffer += value;
+        let end;
+        while ((end = buffer.indexOf("\n\n")) >= 0) {
+          const data = buffer.slice(0, end).split("\n")
+            .filter((line) => line.startsWith("data:"))
+            .map((line) => line.slice(5).trimStart())
+            .join("\n");
+          buffer = buffer.slice(end + 2);
+          if (data) applyEvent(JSON.parse(data));
+        }
+      }
+      $("stream-status").textContent = "(reconnecting)";
+    } catch (err) {
+      $("stream-status").textContent = err.name === "AbortError" ? "(reconnecting)" : `(unavailable: ${err.message})`;
+    }
+    await sleep(reconnectMs);
+  }
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  $("api-key").value = localStorage.getItem(keyStorage) || "";
+  $("api-key").addEventListener("change", () => {
+    localStorage.setItem(keyStorage, $("api-key").value.trim());
+    if (stream) stream.abort(); // Reconnect with the new key
+  });
+  $("search-form").addEventListener("submit", search);
+  $("exports").addEventListener("click", exportResults);
+  setInterval(renderRuns, 1000);
+  watchRuns();
+});
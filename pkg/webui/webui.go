@@ -0,0 +1,43 @@
+// Package webui serves a small single-page UI from the orchestrators at /ui,
+// so people without the CLI or an MCP client can submit topics, watch runs,
+// inspect the evidence behind each statistic, and export results. The page
+// is static; it calls the orchestrator's HTTP API from the browser with the
+// API key the user enters.
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// contentSecurityPolicy confines the page to its own scripts, styles, and API
+const contentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; " +
+	"connect-src 'self'; img-src 'self' data:; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// Handler returns the handler of /ui and the files under /ui/
+func Handler() http.HandlerFunc {
+	root, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // The embedded directory always exists
+	}
+	files := http.StripPrefix("/ui/", http.FileServerFS(root))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == "/ui" {
+			http.Redirect(w, r, "/ui/", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		files.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,48 @@
+package webui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	handler := Handler()
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/ui/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /ui/ = %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `<script src="app.js" defer></script>`) {
+		t.Errorf("GET /ui/ did not serve index.html:\n%s", rec.Body.String())
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") {
+		t.Errorf("Content-Security-Policy = %q", csp)
+	}
+
+	for path, contentType := range map[string]string{
+		"/ui/app.js":  "text/javascript",
+		"/ui/app.css": "text/css",
+	} {
+		rec := serve(http.MethodGet, path)
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), contentType) {
+			t.Errorf("GET %s = %d, %q", path, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	if rec := serve(http.MethodGet, "/ui"); rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/ui/" {
+		t.Errorf("GET /ui = %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := serve(http.MethodGet, "/ui/missing.js"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /ui/missing.js = %d; want 404", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "/ui/"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /ui/ = %d; want 405", rec.Code)
+	}
+}
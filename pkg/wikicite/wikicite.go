@@ -0,0 +1,337 @@
+// Package wikicite follows the citations of a topic's Wikipedia article to
+// the sources they name. Wikipedia articles on popular topics gather the
+// key numbers and cite where each came from, so their references are a
+// short path to primary sources. The article itself is not returned: a
+// statistic is verified against the page that published it, not against
+// the encyclopedia quoting it.
+package wikicite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/plexusone/agent-team-stats/pkg/config"
+	"github.com/plexusone/agent-team-stats/pkg/httpclient"
+)
+
+// maxArticleBytes bounds how much of an article is read
+const maxArticleBytes = 8 << 20
+
+// Reference is a source cited by an article sentence that states a number
+type Reference struct {
+	URL       string
+	Title     string // Title of the cited work, from the reference list
+	Statement string // The article sentence citing it
+}
+
+// Follower finds a topic's Wikipedia article and its cited sources
+type Follower struct {
+	client   *http.Client
+	identity *httpclient.Identity // Nil sends the default User-Agent, which the API asks for
+	baseURL  string               // e.g. https://en.wikipedia.org
+	max      int
+	logger   *slog.Logger
+}
+
+var (
+	// number matches a figure in a sentence, not a lone footnote digit
+	number = regexp.MustCompile(`\d[\d,.]*\s*(%|percent|per cent|million|billion|trillion)|\d{1,3}(,\d{3})+|\d+\.\d+|\b\d{3,}\b`)
+	// sentenceEnd splits article text into sentences
+	sentenceEnd = regexp.MustCompile(`[.!?]["')\]]?\s+`)
+	// bracketNote is an inline marker such as "[1]" or "[citation needed]"
+	bracketNote = regexp.MustCompile(`\[[^\]]{0,40}\]`)
+)
+
+// skipHosts are links in references that do not lead to the cited work
+var skipHosts = []string{
+	"wikipedia.org", "wikimedia.org", "wikidata.org", "wikisource.org",
+	"web.archive.org", "archive.org", "archive.today", "archive.ph",
+	"worldcat.org", "books.google.com", "scholar.google.com",
+}
+
+// New creates a follower for the Wikipedia at baseURL that returns at most
+// maxRefs references per article, presenting identity to the API and
+// articles
+func New(client *http.Client, identity *httpclient.Identity, baseURL string, maxRefs int, logger *slog.Logger) *Follower {
+	if client == nil {
+		client = httpclient.New(15 * time.Second)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Follower{
+		client:   client,
+		identity: identity,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		max:      max(maxRefs, 1),
+		logger:   logger,
+	}
+}
+
+// FromConfig creates the follower configured by WIKIPEDIA_URL and
+// WIKIPEDIA_MAX_REFERENCES. It returns nil when WIKIPEDIA_CITATIONS is off.
+func FromConfig(cfg *config.Config, logger *slog.Logger) *Follower {
+	if !cfg.WikipediaCitations {
+		return nil
+	}
+	return New(nil, cfg.FetchIdentity, cfg.WikipediaURL, cfg.WikipediaMaxReferences, logger)
+}
+
+// References finds the article that best matches topic and returns the
+// sources its numeric sentences cite, in article order, along with the
+// article's URL. A nil follower returns nothing.
+func (f *Follower) References(ctx context.Context, topic string) (string, []Reference, error) {
+	if f == nil {
+		return "", nil, nil
+	}
+	title, err := f.search(ctx, topic)
+	if err != nil || title == "" {
+		return "", nil, err
+	}
+
+	article := f.baseURL + "/wiki/" + url.PathEscape(strings.ReplaceAll(title, " ", "_"))
+	body, err := f.get(ctx, f.baseURL+"/w/index.php?"+url.Values{"title": {title}, "action": {"render"}}.Encode())
+	if err != nil {
+		return article, nil, err
+	}
+	refs := Parse(body, f.max)
+	f.logger.Debug("followed Wikipedia citations", "article", article, "references", len(refs))
+	return article, refs, nil
+}
+
+// search returns the title of the article best matching topic, or "" when
+// none does
+func (f *Follower) search(ctx context.Context, topic string) (string, error) {
+	body, err := f.get(ctx, f.baseURL+"/w/api.php?"+url.Values{
+		"action":   {"query"},
+		"list":     {"search"},
+		"srsearch": {topic},
+		"srlimit":  {"1"},
+		"format":   {"json"},
+	}.Encode())
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Query struct {
+			Search []struct {
+				Title string `json:"title"`
+			} `json:"search"`
+		} `json:"query"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("invalid search response: %w", err)
+	}
+	if len(resp.Query.Search) == 0 {
+		return "", nil
+	}
+	return resp.Query.Search[0].Title, nil
+}
+
+func (f *Follower) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	f.identity.Apply(req)
+	resp, err := f.client.Do(req) //nolint:gosec // G704: URL built from the configured Wikipedia
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxArticleBytes))
+}
+
+// Parse returns up to limit sources cited by the sentences of a rendered
+// article that state a number, in the order they are first cited. Sources
+// without an external link, such as books cited by ISBN, are skipped.
+func Parse(body []byte, limit int) []Reference {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	// Sentences citing each note, from the article's prose
+	var order []string
+	statements := make(map[string]string)
+	var walkProse func(n *html.Node, text *strings.Builder)
+	walkProse = func(n *html.Node, text *strings.Builder) {
+		switch {
+		case n.Type == html.TextNode:
+			text.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.DataAtom == atom.Sup && hasClass(n, "reference"):
+			if id := noteID(n); id != "" {
+				sentence := lastSentence(text.String())
+				if _, seen := statements[id]; !seen && number.MatchString(sentence) {
+					statements[id] = sentence
+					order = append(order, id)
+				}
+			}
+			return
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Style || n.DataAtom == atom.Script):
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkProse(c, text)
+		}
+	}
+	for _, block := range findAll(doc, func(n *html.Node) bool {
+		return n.DataAtom == atom.P || n.DataAtom == atom.Li && !hasID(n, "cite_note-") || n.DataAtom == atom.Td
+	}) {
+		var text strings.Builder
+		walkProse(block, &text)
+	}
+
+	// The cited work of each note, from the reference list
+	notes := make(map[string]*html.Node)
+	for _, li := range findAll(doc, func(n *html.Node) bool { return n.DataAtom == atom.Li && hasID(n, "cite_note-") }) {
+		notes[attr(li, "id")] = li
+	}
+
+	var refs []Reference
+	seen := make(map[string]bool)
+	for _, id := range order {
+		if len(refs) >= limit {
+			break
+		}
+		li, ok := notes[id]
+		if !ok {
+			continue
+		}
+		link, title := citedLink(li)
+		if link == "" || seen[link] {
+			continue
+		}
+		seen[link] = true
+		refs = append(refs, Reference{URL: link, Title: title, Statement: statements[id]})
+	}
+	return refs
+}
+
+// noteID returns the reference list entry a footnote marker links to
+func noteID(sup *html.Node) string {
+	for _, a := range findAll(sup, func(n *html.Node) bool { return n.DataAtom == atom.A }) {
+		if href := attr(a, "href"); strings.Contains(href, "#cite_note-") {
+			return href[strings.Index(href, "#")+1:]
+		}
+	}
+	return ""
+}
+
+// lastSentence returns the sentence at the end of text, without footnotes
+func lastSentence(text string) string {
+	text = strings.Join(strings.Fields(bracketNote.ReplaceAllString(text, "")), " ")
+	start := 0
+	for _, m := range sentenceEnd.FindAllStringIndex(text, -1) {
+		if m[1] < len(text) && isBoundary(text[:m[0]], text[m[1]]) {
+			start = m[1]
+		}
+	}
+	return strings.TrimSpace(text[start:])
+}
+
+// isBoundary reports whether a sentence ends after before, where next is
+// the first character after the space. Abbreviations such as "U.S." and
+// "Dr." do not end a sentence.
+func isBoundary(before string, next byte) bool {
+	if !(next >= 'A' && next <= 'Z' || next >= '0' && next <= '9') {
+		return false
+	}
+	word := before[strings.LastIndexAny(before, " (")+1:]
+	return len(word) > 1 && !strings.Contains(word, ".") && !abbreviations[word]
+}
+
+// abbreviations end with a period inside sentences
+var abbreviations = map[string]bool{
+	"Mr": true, "Mrs": true, "Ms": true, "Dr": true, "St": true, "Inc": true,
+	"Ltd": true, "Co": true, "Corp": true, "No": true, "vs": true, "approx": true,
+	"Jan": true, "Feb": true, "Mar": true, "Apr": true, "Jun": true, "Jul": true,
+	"Aug": true, "Sep": true, "Sept": true, "Oct": true, "Nov": true, "Dec": true,
+}
+
+// citedLink returns the first external link of a reference list entry that
+// leads to the cited work, and the work's title
+func citedLink(li *html.Node) (string, string) {
+	for _, a := range findAll(li, func(n *html.Node) bool { return n.DataAtom == atom.A && hasClass(n, "external") }) {
+		u, err := url.Parse(attr(a, "href"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || skipped(u.Hostname()) {
+			continue
+		}
+		return u.String(), strings.Trim(strings.Join(strings.Fields(text(a)), " "), `"“”`)
+	}
+	return "", ""
+}
+
+func skipped(host string) bool {
+	host = strings.ToLower(host)
+	for _, s := range skipHosts {
+		if host == s || strings.HasSuffix(host, "."+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// findAll returns the element nodes under n, in document order, that match
+func findAll(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && match(n) {
+			found = append(found, n)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return found
+}
+
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func hasClass(n *html.Node, class string) bool {
+	return strings.Contains(" "+attr(n, "class")+" ", " "+class+" ")
+}
+
+func hasID(n *html.Node, prefix string) bool {
+	return strings.HasPrefix(attr(n, "id"), prefix)
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package wikicite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const article = `<div class="mw-parser-output">
+<p>Remote work is work done away from an office.<sup class="reference"><a href="#cite_note-1">[1]</a></sup>
+In 2023, 28% of U.S. workers worked remotely at least part of the week.<sup class="reference"><a href="#cite_note-pew-2">[2]</a></sup><sup class="reference"><a href="#cite_note-book-3">[3]</a></sup>
+About 14,000,000 people worked fully remotely.<sup class="reference"><a href="#cite_note-pew-2">[2]</a></sup> Archives report 5.4 million.<sup class="reference"><a href="#cite_note-4">[4]</a></sup></p>
+<table><tr><td>Remote share in Germany: 24.1%<sup class="reference"><a href="#cite_note-5">[5]</a></sup></td></tr></table>
+<ol class="references">
+<li id="cite_note-1"><cite><a class="external text" href="https://example.com/definition">Definition</a></cite></li>
+<li id="cite_note-pew-2"><cite>Parker, Kim. <a class="external text" href="https://www.pewresearch.org/remote-work">"Remote work facts"</a>.</cite> <a class="external text" href="https://web.archive.org/web/2024/https://www.pewresearch.org/remote-work">Archived</a></li>
+<li id="cite_note-book-3"><cite>Smith, J. Working From Home. <a href="/wiki/Special:BookSources/123">ISBN 123</a></cite></li>
+<li id="cite_note-4"><a class="external text" href="https://web.archive.org/web/2020/https://gone.example">Archived copy</a></li>
+<li id="cite_note-5"><a class="external text" href="https://www.destatis.de/remote">Destatis</a></li>
+</ol></div>`
+
+func TestParse(t *testing.T) {
+	refs := Parse([]byte(article), 10)
+	want := []Reference{
+		{URL: "https://www.pewresearch.org/remote-work", Title: "Remote work facts", Statement: "In 2023, 28% of U.S. workers worked remotely at least part of the week."},
+		{URL: "https://www.destatis.de/remote", Title: "Destatis", Statement: "Remote share in Germany: 24.1%"},
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("Parse() = %+v, want %+v", refs, want)
+	}
+	for i := range want {
+		if refs[i] != want[i] {
+			t.Errorf("refs[%d] = %+v, want %+v", i, refs[i], want[i])
+		}
+	}
+
+	if refs := Parse([]byte(article), 1); len(refs) != 1 {
+		t.Errorf("Parse(limit 1) = %d references", len(refs))
+	}
+}
+
+func TestReferences(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/w/api.php", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("srsearch") != "remote work statistics" || r.Header.Get("User-Agent") == "" {
+			t.Errorf("unexpected search %s", r.URL)
+		}
+		fmt.Fprint(w, `{"query":{"search":[{"title":"Remote work"}]}}`)
+	})
+	mux.HandleFunc("/w/index.php", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("title") != "Remote work" || r.URL.Query().Get("action") != "render" {
+			t.Errorf("unexpected article request %s", r.URL)
+		}
+		fmt.Fprint(w, article)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	page, refs, err := New(srv.Client(), nil, srv.URL+"/", 10, nil).References(context.Background(), "remote work statistics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != srv.URL+"/wiki/Remote_work" || len(refs) != 2 {
+		t.Errorf("References() = %s, %+v", page, refs)
+	}
+
+	var none *Follower
+	if _, refs, err := none.References(context.Background(), "topic"); refs != nil || err != nil {
+		t.Errorf("nil follower returned %v, %v", refs, err)
+	}
+}
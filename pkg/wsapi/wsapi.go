@@ -0,0 +1,322 @@
+// Package wsapi serves the orchestrator's run channel, a WebSocket at /ws
+// on which reactive UIs subscribe to runs by ID and receive their progress,
+// and the statistics each run verifies as they pass verification, without
+// polling. A client may also start a run over the socket and is then
+// subscribed to it.
+package wsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/plexusone/agent-team-stats/pkg/admission"
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+	"github.com/plexusone/agent-team-stats/pkg/tenant"
+)
+
+// Message types. Clients send subscribe, unsubscribe, and start; the
+// server sends the rest, and forwards each event of a followed run as a
+// message of the event's type: progress.EventStarted, EventProgress,
+// EventRetracted, or EventFinished, which ends the subscription.
+const (
+	TypeSubscribe    = "subscribe"    // Client: follow RunID
+	TypeUnsubscribe  = "unsubscribe"  // Client: stop following RunID
+	TypeStart        = "start"        // Client: run Request on this replica and follow it
+	TypeSubscribed   = "subscribed"   // Server: RunID is followed; carries its current state
+	TypeUnsubscribed = "unsubscribed" // Server: RunID is no longer followed
+	TypeResult       = "result"       // Server: a started run's result
+	TypeError        = "error"        // Server: a message failed; RunID is set if it named a run
+)
+
+const (
+	// pingInterval is how often the server pings an open socket
+	pingInterval = 15 * time.Second
+	// pongWait is how long the server waits for any message, pongs
+	// included, before closing the socket
+	pongWait = 2 * pingInterval
+	// writeWait bounds a single write to the socket
+	writeWait = 10 * time.Second
+	// maxMessageSize bounds a client message
+	maxMessageSize = 1 << 20
+)
+
+// Message is a message on the run channel, in either direction
+type Message struct {
+	Type       string                        `json:"type"`
+	RunID      string                        `json:"run_id,omitempty"`
+	Request    *models.OrchestrationRequest  `json:"request,omitempty"`    // start
+	Run        *progress.Run                 `json:"run,omitempty"`        // subscribed and progress events
+	Statistics []models.Statistic            `json:"statistics,omitempty"` // Statistics a progress event verified
+	Result     *models.OrchestrationResponse `json:"result,omitempty"`
+	Error      string                        `json:"error,omitempty"`
+}
+
+// Backend is the orchestrator the channel serves. Without Run, start
+// messages fail.
+type Backend struct {
+	Progress  *progress.Hub
+	Admission *admission.Controller // Limits the runs started over the channel
+	Validate  func(*models.OrchestrationRequest) error
+	Run       func(context.Context, *models.OrchestrationRequest) (*models.OrchestrationResponse, error)
+}
+
+// Server serves the run channel over a backend
+type Server struct {
+	backend  Backend
+	upgrader websocket.Upgrader
+	logger   *slog.Logger
+}
+
+// New creates the run channel over b. Browsers may only open it from the
+// orchestrator's own origin, such as the web UI.
+func New(b Backend, logger *slog.Logger) *Server {
+	return &Server{backend: b, logger: logger}
+}
+
+// Handler returns the GET /ws handler. A run_id query parameter subscribes
+// the socket to that run as it opens.
+func (s *Server) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if s.backend.Progress == nil {
+			http.Error(w, "run progress is not available", http.StatusNotFound)
+			return
+		}
+		conn, err := s.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The upgrader has answered the request
+			s.logger.Debug("websocket upgrade failed", "error", err)
+			return
+		}
+		defer conn.Close()
+
+		// The socket outlives the server's read and write timeouts
+		if err := conn.NetConn().SetDeadline(time.Time{}); err != nil {
+			s.logger.Debug("failed to clear connection deadlines", "error", err)
+		}
+		c := &client{
+			Server:  s,
+			conn:    conn,
+			subs:    make(map[string]bool),
+			begun:   make(chan string, 1),
+			results: make(chan Message),
+		}
+		c.serve(r.Context(), r.URL.Query().Get("run_id"))
+	}
+}
+
+// client is one open socket. Only its serve loop writes to the socket.
+type client struct {
+	*Server
+	conn    *websocket.Conn
+	subs    map[string]bool // Run IDs followed
+	begun   chan string     // IDs of runs started over the socket, as they begin
+	results chan Message    // Results of runs started over the socket
+}
+
+func (c *client) serve(ctx context.Context, runID string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Subscribe to the hub before any run can be followed, so none of a
+	// followed run's events are missed
+	events, unsubscribe := c.backend.Progress.Subscribe()
+	defer unsubscribe()
+
+	incoming := make(chan Message)
+	go c.read(ctx, cancel, incoming)
+
+	if runID != "" {
+		if err := c.handle(ctx, Message{Type: TypeSubscribe, RunID: runID}); err != nil {
+			return
+		}
+	}
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-c.begun:
+			c.subs[id] = true
+		case msg := <-incoming:
+			err = c.handle(ctx, msg)
+		case ev := <-events:
+			err = c.forward(ev)
+		case result := <-c.results:
+			// The run's last events may still be waiting
+			for err == nil && len(events) > 0 {
+				err = c.forward(<-events)
+			}
+			if err == nil {
+				err = c.send(result)
+			}
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+		}
+		if err != nil {
+			c.logger.Debug("run channel closed", "error", err)
+			return
+		}
+	}
+}
+
+// read passes the socket's messages to the serve loop until the socket
+// fails or closes, then cancels ctx
+func (c *client) read(ctx context.Context, cancel context.CancelFunc, incoming chan<- Message) {
+	defer cancel()
+	c.conn.SetReadLimit(maxMessageSize)
+	extend := func() error { return c.conn.SetReadDeadline(time.Now().Add(pongWait)) }
+	if err := extend(); err != nil {
+		return
+	}
+	c.conn.SetPongHandler(func(string) error { return extend() })
+	for {
+		var msg Message
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.logger.Debug("failed to read run channel message", "error", err)
+			}
+			return
+		}
+		if err := extend(); err != nil {
+			return
+		}
+		select {
+		case incoming <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handle answers a client message
+func (c *client) handle(ctx context.Context, msg Message) error {
+	switch msg.Type {
+	case TypeSubscribe:
+		run, ok := c.active(ctx, msg.RunID)
+		if !ok {
+			return c.send(Message{Type: TypeError, RunID: msg.RunID, Error: "run is not active"})
+		}
+		c.subs[run.ID] = true
+		return c.send(Message{Type: TypeSubscribed, RunID: run.ID, Run: &run})
+	case TypeUnsubscribe:
+		delete(c.subs, msg.RunID)
+		return c.send(Message{Type: TypeUnsubscribed, RunID: msg.RunID})
+	case TypeStart:
+		if err := c.start(ctx, msg.Request); err != nil {
+			return c.send(Message{Type: TypeError, Error: err.Error()})
+		}
+		return nil
+	default:
+		return c.send(Message{Type: TypeError, Error: fmt.Sprintf("unknown message type %q", msg.Type)})
+	}
+}
+
+// active returns an active run the client may see
+func (c *client) active(ctx context.Context, id string) (progress.Run, bool) {
+	for _, run := range c.backend.Progress.Runs() {
+		if run.ID == id && run.VisibleTo(ctx) {
+			return run, true
+		}
+	}
+	return progress.Run{}, false
+}
+
+// start validates a request and runs it in the background, following the
+// run once it begins. Its result, or error, arrives on c.results. The run
+// is canceled if the socket closes first.
+func (c *client) start(ctx context.Context, req *models.OrchestrationRequest) error {
+	if c.backend.Run == nil {
+		return errors.New("runs cannot be started here")
+	}
+	if req == nil {
+		return errors.New("request is required")
+	}
+	if c.backend.Validate != nil {
+		if err := c.backend.Validate(req); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		id := make(chan string, 1)
+		runCtx := progress.OnBegin(ctx, func(runID string) {
+			id <- runID
+			select {
+			case c.begun <- runID:
+			case <-ctx.Done():
+			}
+		})
+		resp, err := c.run(runCtx, req)
+		msg := Message{Type: TypeResult, Result: resp}
+		if err != nil {
+			msg = Message{Type: TypeError, Error: err.Error()}
+		}
+		select {
+		case msg.RunID = <-id:
+		default:
+		}
+		select {
+		case c.results <- msg:
+		case <-ctx.Done():
+		}
+	}()
+	return nil
+}
+
+// run runs a request under the admission limit, charging its tenant
+func (c *client) run(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+	release, ok, retry := c.backend.Admission.Acquire(ctx)
+	if !ok {
+		return nil, fmt.Errorf("orchestrator is busy; retry in %d seconds", int(math.Ceil(retry.Seconds())))
+	}
+	defer release()
+	resp, err := c.backend.Run(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("orchestration failed: %w", err)
+	}
+	tenant.Charge(ctx, resp.CostSummary)
+	return resp, nil
+}
+
+// forward sends a hub event of a followed run, ending the subscription
+// once the run finishes
+func (c *client) forward(ev progress.Event) error {
+	if !c.subs[ev.Run.ID] {
+		// A run started over the socket may have begun since the last
+		// check
+		select {
+		case id := <-c.begun:
+			c.subs[id] = true
+		default:
+		}
+		if !c.subs[ev.Run.ID] {
+			return nil
+		}
+	}
+	if ev.Type == progress.EventFinished {
+		delete(c.subs, ev.Run.ID)
+	}
+	return c.send(Message{Type: ev.Type, RunID: ev.Run.ID, Run: &ev.Run, Statistics: ev.Statistics})
+}
+
+func (c *client) send(msg Message) error {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return c.conn.WriteJSON(msg)
+}
@@ -0,0 +1,153 @@
+package wsapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/plexusone/agent-team-stats/pkg/models"
+	"github.com/plexusone/agent-team-stats/pkg/progress"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+// dial opens the run channel of b, with query appended to its URL
+func dial(t *testing.T, b Backend, query string) *websocket.Conn {
+	t.Helper()
+	srv := httptest.NewServer(New(b, testLogger).Handler())
+	t.Cleanup(srv.Close)
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func receive(t *testing.T, conn *websocket.Conn) Message {
+	t.Helper()
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var msg Message
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestSubscribe(t *testing.T) {
+	hub := progress.NewHub()
+	var id string
+	_, followed := hub.Begin(progress.OnBegin(context.Background(), func(runID string) { id = runID }), "solar", 2)
+	_, other := hub.Begin(context.Background(), "wind", 2)
+	conn := dial(t, Backend{Progress: hub}, "?run_id="+id)
+
+	if msg := receive(t, conn); msg.Type != TypeSubscribed || msg.RunID != id || msg.Run == nil || msg.Run.Topic != "solar" {
+		t.Fatalf("first message = %+v; want subscribed to %s", msg, id)
+	}
+	if err := conn.WriteJSON(Message{Type: TypeSubscribe, RunID: "missing"}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receive(t, conn); msg.Type != TypeError || msg.RunID != "missing" {
+		t.Errorf("subscribe to missing run = %+v; want an error", msg)
+	}
+
+	other.Verified([]models.Statistic{{Name: "b"}}, 0)
+	followed.Verified([]models.Statistic{{Name: "a"}}, 1)
+	followed.Finish(models.StatusPartial, nil)
+	msg := receive(t, conn)
+	if msg.Type != progress.EventProgress || msg.RunID != id || len(msg.Statistics) != 1 || msg.Statistics[0].Name != "a" ||
+		msg.Run.Verified != 1 || msg.Run.Failed != 1 {
+		t.Errorf("progress message = %+v; want the followed run's verified statistic", msg)
+	}
+	if msg := receive(t, conn); msg.Type != progress.EventFinished || msg.Run.Status != models.StatusPartial {
+		t.Errorf("last message = %+v; want finished", msg)
+	}
+}
+
+func TestStart(t *testing.T) {
+	hub := progress.NewHub()
+	conn := dial(t, Backend{
+		Progress: hub,
+		Validate: func(req *models.OrchestrationRequest) error {
+			if req.Topic == "blocked" {
+				return errors.New("topic is blocked")
+			}
+			return nil
+		},
+		Run: func(ctx context.Context, req *models.OrchestrationRequest) (*models.OrchestrationResponse, error) {
+			_, run := hub.Begin(ctx, req.Topic, 1)
+			run.Verified([]models.Statistic{{Name: "a"}}, 0)
+			run.Finish(models.StatusComplete, nil)
+			return &models.OrchestrationResponse{Topic: req.Topic, Status: models.StatusComplete, VerifiedCount: 1}, nil
+		},
+	}, "")
+
+	if err := conn.WriteJSON(Message{Type: TypeStart, Request: &models.OrchestrationRequest{Topic: "blocked"}}); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receive(t, conn); msg.Type != TypeError || msg.Error != "topic is blocked" {
+		t.Errorf("start of blocked topic = %+v; want an error", msg)
+	}
+
+	if err := conn.WriteJSON(Message{Type: TypeStart, Request: &models.OrchestrationRequest{Topic: "solar"}}); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	var id string
+	for {
+		msg := receive(t, conn)
+		types = append(types, msg.Type)
+		if msg.Type == progress.EventStarted {
+			id = msg.RunID
+		}
+		if msg.Type == TypeResult {
+			if msg.RunID == "" || msg.RunID != id || msg.Result == nil || msg.Result.VerifiedCount != 1 {
+				t.Errorf("result = %+v; want run %s's result", msg, id)
+			}
+			break
+		}
+	}
+	if got := strings.Join(types, " "); got != "started progress finished result" {
+		t.Errorf("messages = %q", got)
+	}
+}
+
+func TestUnknownMessage(t *testing.T) {
+	conn := dial(t, Backend{Progress: progress.NewHub()}, "")
+	for _, msg := range []Message{{Type: "bogus"}, {Type: TypeStart, Request: &models.OrchestrationRequest{Topic: "solar"}}} {
+		if err := conn.WriteJSON(msg); err != nil {
+			t.Fatal(err)
+		}
+		if got := receive(t, conn); got.Type != TypeError {
+			t.Errorf("%s answered %+v; want an error", msg.Type, got)
+		}
+	}
+}
+
+func TestHandlerRejectsBadRequests(t *testing.T) {
+	for _, tt := range []struct {
+		method string
+		b      Backend
+		want   int
+	}{
+		{http.MethodPost, Backend{Progress: progress.NewHub()}, http.StatusMethodNotAllowed},
+		{http.MethodGet, Backend{}, http.StatusNotFound},
+		{http.MethodGet, Backend{Progress: progress.NewHub()}, http.StatusBadRequest}, // Not a WebSocket handshake
+	} {
+		rec := httptest.NewRecorder()
+		New(tt.b, testLogger).Handler()(rec, httptest.NewRequest(tt.method, "/ws", nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.method, rec.Code, tt.want)
+		}
+	}
+}